@@ -56,6 +56,11 @@ type BackupSpec struct {
 
 	// Hooks represent custom behaviors that should be executed at different phases of the backup.
 	Hooks BackupHooks `json:"hooks"`
+
+	// StorageLocation is the name of the BackupStorageLocation the backup is stored in.
+	// If empty, the default location configured by the Config's BackupStorageProvider
+	// is used.
+	StorageLocation string `json:"storageLocation"`
 }
 
 // BackupHooks contains custom behaviors that should be executed at different phases of the backup.
@@ -81,8 +86,15 @@ type BackupResourceHookSpec struct {
 	ExcludedResources []string `json:"excludedResources"`
 	// LabelSelector, if specified, filters the resources to which this hook spec applies.
 	LabelSelector *metav1.LabelSelector `json:"labelSelector"`
-	// Hooks is a list of BackupResourceHooks to execute.
+	// Hooks is a list of BackupResourceHooks to execute. DEPRECATED. Replaced by PreHooks.
 	Hooks []BackupResourceHook `json:"hooks"`
+	// PreHooks is a list of BackupResourceHooks to execute prior to storing the item in the backup.
+	// These are executed before any "additional items" from item actions are processed.
+	PreHooks []BackupResourceHook `json:"pre,omitempty"`
+	// PostHooks is a list of BackupResourceHooks to execute after storing the item in the backup.
+	// These are executed after all "additional items" from item actions are processed, e.g. after
+	// the volumes used by a pod have been snapshotted.
+	PostHooks []BackupResourceHook `json:"post,omitempty"`
 }
 
 // BackupResourceHook defines a hook for a resource.
@@ -117,6 +129,14 @@ const (
 	HookErrorModeFail HookErrorMode = "Fail"
 )
 
+// HookStatus summarizes the hooks that were executed during a backup or restore.
+type HookStatus struct {
+	// HooksAttempted is the total number of hooks that were attempted.
+	HooksAttempted int `json:"hooksAttempted"`
+	// HooksFailed is the total number of hooks that returned an error.
+	HooksFailed int `json:"hooksFailed"`
+}
+
 // BackupPhase is a string representation of the lifecycle phase
 // of an Ark backup.
 type BackupPhase string
@@ -137,6 +157,11 @@ const (
 	// errors.
 	BackupPhaseCompleted BackupPhase = "Completed"
 
+	// BackupPhasePartiallyFailed means the backup has run to completion
+	// but encountered errors backing up some individual items. The errors
+	// are listed in the backup's results file in object storage.
+	BackupPhasePartiallyFailed BackupPhase = "PartiallyFailed"
+
 	// BackupPhaseFailed mean the backup ran but encountered an error that
 	// prevented it from completing successfully.
 	BackupPhaseFailed BackupPhase = "Failed"
@@ -158,9 +183,96 @@ type BackupStatus struct {
 	// provider API.
 	VolumeBackups map[string]*VolumeBackupInfo `json:"volumeBackups"`
 
+	// PodVolumeBackups is a map of "<namespace>/<pod>/<volume>" to the
+	// status of the file-level backup of that pod volume, for pods that
+	// opted in to having their volumes backed up.
+	PodVolumeBackups map[string]*PodVolumeBackupInfo `json:"podVolumeBackups,omitempty"`
+
 	// ValidationErrors is a slice of all validation errors (if
 	// applicable).
 	ValidationErrors []string `json:"validationErrors"`
+
+	// HookStatus summarizes the hooks that were executed during
+	// the backup, if any.
+	HookStatus *HookStatus `json:"hookStatus,omitempty"`
+
+	// EncryptionKeyID is the ID of the key that the backup's data key was
+	// encrypted with, if the backup is encrypted.
+	EncryptionKeyID string `json:"encryptionKeyID,omitempty"`
+
+	// StartTimestamp records the time the backup was started.
+	StartTimestamp metav1.Time `json:"startTimestamp"`
+
+	// CompletionTimestamp records the time the backup finished, whether
+	// it succeeded or failed.
+	CompletionTimestamp metav1.Time `json:"completionTimestamp"`
+
+	// Progress contains information about the backup's execution progress.
+	// It's updated periodically while the backup is running.
+	Progress *BackupProgress `json:"progress,omitempty"`
+
+	// Warnings is a count of all warning messages that were generated during
+	// execution of the backup. The actual warnings are in the backup's log
+	// file in object storage.
+	Warnings int `json:"warnings"`
+
+	// Errors is a count of all error messages that were generated during
+	// execution of the backup. The actual errors are in the backup's log
+	// file in object storage.
+	Errors int `json:"errors"`
+}
+
+// BackupResult is a collection of messages that were generated
+// during execution of a backup. This will typically store either
+// warning or error messages.
+type BackupResult struct {
+	// Ark is a slice of messages related to the operation of Ark
+	// itself (for example, messages related to listing resources,
+	// running hooks, etc.)
+	Ark []string `json:"ark"`
+
+	// Cluster is a slice of messages related to backing up cluster-
+	// scoped resources.
+	Cluster []string `json:"cluster"`
+
+	// Namespaces is a map of namespace name to slice of messages
+	// related to backing up namespace-scoped resources.
+	Namespaces map[string][]string `json:"namespaces"`
+}
+
+// BackupContentsIndex lists the items in a backup's tarball.
+type BackupContentsIndex struct {
+	// Items is the list of items in the backup, in the order they
+	// were backed up.
+	Items []BackupContentsIndexItem `json:"items"`
+}
+
+// BackupContentsIndexItem identifies a single item in a backup.
+type BackupContentsIndexItem struct {
+	// Resource is the group-qualified name of the item's resource, e.g.
+	// "deployments.apps".
+	Resource string `json:"resource"`
+
+	// Namespace is the item's namespace. It's empty for cluster-scoped
+	// items.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name is the name of the item.
+	Name string `json:"name"`
+
+	// APIVersion is the API version the item was backed up as.
+	APIVersion string `json:"apiVersion"`
+}
+
+// BackupProgress stores information about the progress of a Backup's execution.
+type BackupProgress struct {
+	// TotalItems is the total number of items to be backed up. This number may
+	// change while the backup runs, as resources are listed and as items
+	// that are already backed up, or that are excluded, are skipped.
+	TotalItems int `json:"totalItems"`
+
+	// ItemsBackedUp is the number of items that have been backed up so far.
+	ItemsBackedUp int `json:"itemsBackedUp"`
 }
 
 // VolumeBackupInfo captures the required information about
@@ -184,6 +296,21 @@ type VolumeBackupInfo struct {
 	Iops *int64 `json:"iops,omitempty"`
 }
 
+// PodVolumeBackupInfo captures the outcome of the file-level
+// backup of a pod volume.
+type PodVolumeBackupInfo struct {
+	// Phase is the final state of the volume's PodVolumeBackup.
+	Phase PodVolumeBackupPhase `json:"phase"`
+
+	// SnapshotID identifies the volume's backed-up contents in
+	// object storage, if the backup completed.
+	SnapshotID string `json:"snapshotID,omitempty"`
+
+	// Message is a description of why the volume's backup failed,
+	// if it did.
+	Message string `json:"message,omitempty"`
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
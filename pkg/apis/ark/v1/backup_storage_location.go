@@ -0,0 +1,62 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// DefaultBackupStorageLocation is the name of the backup storage location configured by
+// the Config's BackupStorageProvider. Backups that don't specify a StorageLocation are
+// stored here.
+const DefaultBackupStorageLocation = "default"
+
+// BackupStorageLocationSpec defines an object storage bucket that backups can be stored in.
+type BackupStorageLocationSpec struct {
+	// Provider is the name of the object storage provider (ObjectStore plugin) that
+	// hosts the bucket.
+	Provider string `json:"provider"`
+
+	// Config is provider-specific configuration for connecting to the object storage.
+	Config map[string]string `json:"config"`
+
+	// Bucket is the object storage bucket backups are stored in.
+	Bucket string `json:"bucket"`
+
+	// Prefix is the directory within the bucket that backups are stored under. If empty,
+	// backups are stored at the root of the bucket.
+	Prefix string `json:"prefix"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BackupStorageLocation is a named location in object storage that backups can be stored in,
+// in addition to the default location configured by the Config's BackupStorageProvider.
+type BackupStorageLocation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec BackupStorageLocationSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BackupStorageLocationList is a list of BackupStorageLocations.
+type BackupStorageLocationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []BackupStorageLocation `json:"items"`
+}
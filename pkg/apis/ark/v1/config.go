@@ -60,12 +60,85 @@ type Config struct {
 
 	// ResourcePriorities is an ordered slice of resources specifying the desired
 	// order of resource restores. Any resources not in the list will be restored
-	// alphabetically after the prioritized resources.
+	// alphabetically after the prioritized resources, up to RestoreWorkers at once.
+	// CustomResourceDefinitions are always restored first.
 	ResourcePriorities []string `json:"resourcePriorities"`
 
 	// RestoreOnlyMode is whether Ark should run in a mode where only restores
 	// are allowed; backups, schedules, and garbage-collection are all disabled.
 	RestoreOnlyMode bool `json:"restoreOnlyMode"`
+
+	// Encryption is the configuration for encrypting the backup tarballs, logs,
+	// and restore results that Ark stores in object storage. If not set, they're
+	// stored unencrypted.
+	Encryption *EncryptionConfig `json:"encryption,omitempty"`
+
+	// PodVolumeRestoreHelperImage is the image of the init container that's added to
+	// restored pods whose volumes are being restored from file-level backups, to hold
+	// the pods' containers until their volumes are ready. It must contain /bin/sh.
+	PodVolumeRestoreHelperImage string `json:"podVolumeRestoreHelperImage"`
+
+	// BackupWorkers is the number of backups that can run at the same time. Backups
+	// whose namespaces and resources overlap are never run at the same time; a
+	// backup that overlaps one that's running waits for it to finish. Defaults to 1.
+	BackupWorkers int `json:"backupWorkers,omitempty"`
+
+	// BackupItemWorkers is the number of items of each resource that a backup lists or
+	// backs up (running hooks, actions and snapshots) at the same time. Items are still
+	// written to the backup's tarball one at a time, in a deterministic order. Defaults to 1.
+	BackupItemWorkers int `json:"backupItemWorkers,omitempty"`
+
+	// RestoreWorkers is the number of items of each resource that a restore creates at
+	// the same time, and the number of resources that aren't in ResourcePriorities that
+	// it restores at the same time, so up to RestoreWorkers² items can be created at once.
+	// Prioritized resources are always restored one after another, in order, before any
+	// other resources. Defaults to 1.
+	RestoreWorkers int `json:"restoreWorkers,omitempty"`
+
+	// RestoreClientQPS is the maximum rate, in requests per second, of the API requests
+	// that restores make for the items they restore, shared by all of them. If it's not
+	// set, there's no overall limit: each API group version's client is limited separately,
+	// to client-go's default of 5 requests per second with a burst of 10.
+	RestoreClientQPS float32 `json:"restoreClientQPS,omitempty"`
+
+	// RestoreClientBurst is the maximum burst of the API requests that restores make for
+	// the items they restore, if RestoreClientQPS is set.
+	RestoreClientBurst int `json:"restoreClientBurst,omitempty"`
+
+	// RestoreResourceReadiness configures how restores wait for the items they create of
+	// particular resources to become ready before restoring the next resources, in addition
+	// to any waiting done by the resources' restorers. CustomResourceDefinitions are waited
+	// for until they're established, for up to a minute, unless they're configured here.
+	RestoreResourceReadiness []ResourceReadiness `json:"restoreResourceReadiness,omitempty"`
+}
+
+// ResourceReadiness is how a restore waits for the items of a resource it creates to
+// become ready.
+type ResourceReadiness struct {
+	// Resource is the fully-qualified name of the resource, in <resource>.<group> form,
+	// e.g. customresourcedefinitions.apiextensions.k8s.io. Resources in the core group
+	// are just <resource>.
+	Resource string `json:"resource"`
+
+	// Conditions are the types of the status conditions that must be "True" for an
+	// item to be ready. If there aren't any, an item is ready once it's been created and
+	// is ready according to its resource's restorer.
+	Conditions []string `json:"conditions,omitempty"`
+
+	// Timeout is how long to wait, at most, between an item of the resource becoming
+	// ready and the next one doing so, before giving up on the rest. Defaults to 30s.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// EncryptionConfig is configuration information about the key provider used
+// to encrypt the files Ark stores in object storage.
+type EncryptionConfig struct {
+	// Provider is the name of the key provider. Currently only "local" is
+	// supported.
+	Provider string `json:"provider"`
+
+	// Config is provider-specific configuration.
+	Config map[string]string `json:"config"`
 }
 
 // CloudProviderConfig is configuration information about how to connect
@@ -86,4 +159,8 @@ type ObjectStorageProviderConfig struct {
 	// Bucket is the name of the bucket in object storage where Ark backups
 	// are stored.
 	Bucket string `json:"bucket"`
+
+	// Prefix is the directory within the bucket that Ark backups are
+	// stored under. If empty, backups are stored at the root of the bucket.
+	Prefix string `json:"prefix"`
 }
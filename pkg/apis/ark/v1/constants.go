@@ -37,4 +37,17 @@ const (
 	// NamespaceScopedDir is the name of the directory containing namespace-scoped
 	// resource within an Ark backup.
 	NamespaceScopedDir = "namespaces"
+
+	// BackupNameLabel is the label key used by a DeleteBackupRequest to identify
+	// the backup it's for.
+	BackupNameLabel = "ark.heptio.com/backup-name"
+
+	// ScheduleNameLabel is the label key applied to backups created by a
+	// schedule. The value will be the schedule's name.
+	ScheduleNameLabel = "ark-schedule"
+
+	// ScheduleTriggerAnnotation is the annotation key set on a schedule to request
+	// a backup from it now. The schedule controller removes the annotation, then
+	// creates the backup, subject to the schedule's concurrency policy.
+	ScheduleTriggerAnnotation = "ark.heptio.com/trigger"
 )
@@ -28,10 +28,13 @@ type DownloadRequestSpec struct {
 type DownloadTargetKind string
 
 const (
-	DownloadTargetKindBackupLog      DownloadTargetKind = "BackupLog"
-	DownloadTargetKindBackupContents DownloadTargetKind = "BackupContents"
-	DownloadTargetKindRestoreLog     DownloadTargetKind = "RestoreLog"
-	DownloadTargetKindRestoreResults DownloadTargetKind = "RestoreResults"
+	DownloadTargetKindBackupLog           DownloadTargetKind = "BackupLog"
+	DownloadTargetKindBackupContents      DownloadTargetKind = "BackupContents"
+	DownloadTargetKindBackupResults       DownloadTargetKind = "BackupResults"
+	DownloadTargetKindBackupContentsIndex DownloadTargetKind = "BackupContentsIndex"
+	DownloadTargetKindRestoreLog          DownloadTargetKind = "RestoreLog"
+	DownloadTargetKindRestoreResults      DownloadTargetKind = "RestoreResults"
+	DownloadTargetKindRestoreDryRunReport DownloadTargetKind = "RestoreDryRunReport"
 )
 
 // DownloadTarget is the specification for what kind of file to download, and the name of the
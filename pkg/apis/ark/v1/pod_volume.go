@@ -0,0 +1,164 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodVolumeBackupSpec is the specification for a file-level backup of one of a pod's volumes.
+type PodVolumeBackupSpec struct {
+	// Node is the name of the node that the pod is running on. Only the node agent
+	// running on this node processes the PodVolumeBackup.
+	Node string `json:"node"`
+
+	// Pod is a reference to the pod whose volume is being backed up.
+	Pod corev1api.ObjectReference `json:"pod"`
+
+	// Volume is the name of the volume within the pod to back up.
+	Volume string `json:"volume"`
+
+	// BackupName is the name of the backup the volume is being backed up for.
+	BackupName string `json:"backupName"`
+
+	// StorageLocation is the name of the backup storage location that the volume's
+	// data is stored in. An empty value means the default location.
+	StorageLocation string `json:"storageLocation"`
+}
+
+// PodVolumeBackupPhase represents the lifecycle phase of a PodVolumeBackup.
+type PodVolumeBackupPhase string
+
+const (
+	// PodVolumeBackupPhaseNew means the PodVolumeBackup has not been processed yet.
+	PodVolumeBackupPhaseNew PodVolumeBackupPhase = "New"
+
+	// PodVolumeBackupPhaseInProgress means the volume is being backed up.
+	PodVolumeBackupPhaseInProgress PodVolumeBackupPhase = "InProgress"
+
+	// PodVolumeBackupPhaseCompleted means the volume was backed up successfully.
+	PodVolumeBackupPhaseCompleted PodVolumeBackupPhase = "Completed"
+
+	// PodVolumeBackupPhaseFailed means the volume could not be backed up.
+	PodVolumeBackupPhaseFailed PodVolumeBackupPhase = "Failed"
+)
+
+// PodVolumeBackupStatus is the current status of a PodVolumeBackup.
+type PodVolumeBackupStatus struct {
+	// Phase is the current state of the PodVolumeBackup.
+	Phase PodVolumeBackupPhase `json:"phase"`
+
+	// SnapshotID identifies the volume's backed-up contents in object storage. It's set
+	// once the PodVolumeBackup has completed.
+	SnapshotID string `json:"snapshotID"`
+
+	// Message is a description of why the PodVolumeBackup failed, if it did.
+	Message string `json:"message"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodVolumeBackup is a request to a node agent to back up the contents of a pod's volume.
+type PodVolumeBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec   PodVolumeBackupSpec   `json:"spec"`
+	Status PodVolumeBackupStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodVolumeBackupList is a list of PodVolumeBackups.
+type PodVolumeBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []PodVolumeBackup `json:"items"`
+}
+
+// PodVolumeRestoreSpec is the specification for restoring the contents of one of a pod's
+// volumes from a file-level backup.
+type PodVolumeRestoreSpec struct {
+	// Pod is a reference to the restored pod whose volume is being repopulated.
+	Pod corev1api.ObjectReference `json:"pod"`
+
+	// Volume is the name of the volume within the pod to restore.
+	Volume string `json:"volume"`
+
+	// SnapshotID identifies the backed-up contents to restore the volume from.
+	SnapshotID string `json:"snapshotID"`
+
+	// BackupName is the name of the backup the volume's contents were backed up in.
+	BackupName string `json:"backupName"`
+
+	// StorageLocation is the name of the backup storage location that the backup is
+	// stored in. An empty value means the default location.
+	StorageLocation string `json:"storageLocation"`
+
+	// RestoreUID is the UID of the restore the volume is being restored for. It's used
+	// to signal the pod's restore helper init container that the volume is ready.
+	RestoreUID string `json:"restoreUID"`
+}
+
+// PodVolumeRestorePhase represents the lifecycle phase of a PodVolumeRestore.
+type PodVolumeRestorePhase string
+
+const (
+	// PodVolumeRestorePhaseNew means the PodVolumeRestore has not been processed yet.
+	PodVolumeRestorePhaseNew PodVolumeRestorePhase = "New"
+
+	// PodVolumeRestorePhaseInProgress means the volume is being restored.
+	PodVolumeRestorePhaseInProgress PodVolumeRestorePhase = "InProgress"
+
+	// PodVolumeRestorePhaseCompleted means the volume was restored successfully.
+	PodVolumeRestorePhaseCompleted PodVolumeRestorePhase = "Completed"
+
+	// PodVolumeRestorePhaseFailed means the volume could not be restored.
+	PodVolumeRestorePhaseFailed PodVolumeRestorePhase = "Failed"
+)
+
+// PodVolumeRestoreStatus is the current status of a PodVolumeRestore.
+type PodVolumeRestoreStatus struct {
+	// Phase is the current state of the PodVolumeRestore.
+	Phase PodVolumeRestorePhase `json:"phase"`
+
+	// Message is a description of why the PodVolumeRestore failed, if it did.
+	Message string `json:"message"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodVolumeRestore is a request to a node agent to restore the contents of a pod's volume.
+type PodVolumeRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec   PodVolumeRestoreSpec   `json:"spec"`
+	Status PodVolumeRestoreStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodVolumeRestoreList is a list of PodVolumeRestores.
+type PodVolumeRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []PodVolumeRestore `json:"items"`
+}
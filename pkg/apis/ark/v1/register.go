@@ -53,6 +53,14 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ConfigList{},
 		&DownloadRequest{},
 		&DownloadRequestList{},
+		&DeleteBackupRequest{},
+		&DeleteBackupRequestList{},
+		&BackupStorageLocation{},
+		&BackupStorageLocationList{},
+		&PodVolumeBackup{},
+		&PodVolumeBackupList{},
+		&PodVolumeRestore{},
+		&PodVolumeRestoreList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -16,7 +16,10 @@ limitations under the License.
 
 package v1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // RestoreSpec defines the specification for an Ark restore.
 type RestoreSpec struct {
@@ -59,6 +62,99 @@ type RestoreSpec struct {
 	// should be included for consideration in the restore. If null, defaults
 	// to true.
 	IncludeClusterResources *bool `json:"includeClusterResources"`
+
+	// Hooks represent custom behaviors that should be executed for
+	// pods during and after the restore.
+	Hooks RestoreHooks `json:"hooks"`
+
+	// ExistingResourcePolicy specifies what to do with items in the
+	// backup that already exist in the cluster. If empty, defaults to
+	// none.
+	ExistingResourcePolicy ExistingResourcePolicy `json:"existingResourcePolicy,omitempty"`
+
+	// DryRun specifies whether the restore should only report what it
+	// would do, without changing anything in the cluster. The report is
+	// stored in object storage alongside the restore's log.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// ExistingResourcePolicy specifies what a restore does with items that
+// already exist in the cluster.
+type ExistingResourcePolicy string
+
+const (
+	// ExistingResourcePolicyNone means items that already exist are left
+	// as they are, and a warning is reported for each one.
+	ExistingResourcePolicyNone ExistingResourcePolicy = "none"
+
+	// ExistingResourcePolicyUpdate means items that already exist are
+	// patched to match the backed-up version. Fields that aren't set in
+	// the backed-up version are removed, except for the server-managed
+	// metadata, the status, and fields the cluster assigns, such as a
+	// service's cluster IP and node ports, which are left as they are.
+	ExistingResourcePolicyUpdate ExistingResourcePolicy = "update"
+
+	// ExistingResourcePolicyRecreate means items that already exist are
+	// deleted and created again from the backed-up version. Persistent
+	// volumes and claims are never recreated, since deleting them can
+	// delete their data; they're left as they are.
+	ExistingResourcePolicyRecreate ExistingResourcePolicy = "recreate"
+)
+
+// RestoreHooks contains custom behaviors that should be executed during or after the restore.
+type RestoreHooks struct {
+	// Resources are hooks that should be executed for individual restored pods.
+	Resources []RestoreResourceHookSpec `json:"resources"`
+}
+
+// RestoreResourceHookSpec defines one or more RestoreResourceHooks that should be executed for
+// restored pods based on the rules defined for namespaces and label selector.
+type RestoreResourceHookSpec struct {
+	// Name is the name of this hook.
+	Name string `json:"name"`
+	// IncludedNamespaces specifies the namespaces to which this hook spec applies. If empty, it applies
+	// to all namespaces. Namespaces are matched after any namespace mapping has been applied.
+	IncludedNamespaces []string `json:"includedNamespaces"`
+	// ExcludedNamespaces specifies the namespaces to which this hook spec does not apply.
+	ExcludedNamespaces []string `json:"excludedNamespaces"`
+	// LabelSelector, if specified, filters the pods to which this hook spec applies.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector"`
+	// PostHooks is a list of RestoreResourceHooks to execute for a restored pod.
+	PostHooks []RestoreResourceHook `json:"postHooks"`
+}
+
+// RestoreResourceHook defines a restore hook for a pod. Exactly one of Exec or Init
+// should be specified.
+type RestoreResourceHook struct {
+	// Exec defines a hook that's executed in a container of the restored pod once it's running.
+	Exec *ExecRestoreHook `json:"exec,omitempty"`
+	// Init defines init containers that are added to the restored pod.
+	Init *InitRestoreHook `json:"init,omitempty"`
+}
+
+// ExecRestoreHook is a hook that uses the pod exec API to execute a command in a container
+// in a restored pod.
+type ExecRestoreHook struct {
+	// Container is the container in the pod where the command should be executed. If not specified,
+	// the pod's first container is used.
+	Container string `json:"container"`
+	// Command is the command and arguments to execute.
+	Command []string `json:"command"`
+	// OnError specifies how Ark should behave if it encounters an error executing this hook.
+	OnError HookErrorMode `json:"onError"`
+	// ExecTimeout defines the maximum amount of time Ark should wait for the hook to complete before
+	// considering the execution a failure.
+	ExecTimeout metav1.Duration `json:"execTimeout"`
+	// WaitTimeout defines the maximum amount of time Ark should wait for the pod to be running
+	// before considering the execution a failure.
+	WaitTimeout metav1.Duration `json:"waitTimeout"`
+}
+
+// InitRestoreHook is a hook that adds init containers to a restored pod.
+type InitRestoreHook struct {
+	// InitContainers are added to the restored pod ahead of any init containers it
+	// already has.
+	InitContainers []corev1.Container `json:"initContainers"`
 }
 
 // RestorePhase is a string representation of the lifecycle phase
@@ -98,6 +194,10 @@ type RestoreStatus struct {
 	// Errors is a count of all error messages that were generated during
 	// execution of the restore. The actual errors are stored in object storage.
 	Errors int `json:"errors"`
+
+	// HookStatus summarizes the hooks that were executed during
+	// the restore, if any.
+	HookStatus *HookStatus `json:"hookStatus,omitempty"`
 }
 
 // RestoreResult is a collection of messages that were generated
@@ -118,6 +218,72 @@ type RestoreResult struct {
 	Namespaces map[string][]string `json:"namespaces"`
 }
 
+// RestoreDryRunAction is what a restore would do with an item in the
+// backup.
+type RestoreDryRunAction string
+
+const (
+	// RestoreDryRunActionCreate means the item doesn't exist in the
+	// cluster and would be created.
+	RestoreDryRunActionCreate RestoreDryRunAction = "Create"
+
+	// RestoreDryRunActionExists means the item already exists in the
+	// cluster. What would happen to it depends on the restore's
+	// ExistingResourcePolicy.
+	RestoreDryRunActionExists RestoreDryRunAction = "Exists"
+
+	// RestoreDryRunActionSkip means the item would not be restored.
+	RestoreDryRunActionSkip RestoreDryRunAction = "Skip"
+)
+
+// RestoreDryRunReport lists what a dry-run restore would have done with
+// each of the items in the backup that it considered.
+type RestoreDryRunReport struct {
+	// Items is the list of items the restore considered, in the order
+	// they would have been restored.
+	Items []RestoreDryRunItem `json:"items"`
+}
+
+// RestoreDryRunItem describes what a restore would do with a single item.
+type RestoreDryRunItem struct {
+	// Resource is the group-qualified name of the item's resource, e.g.
+	// "deployments.apps".
+	Resource string `json:"resource"`
+
+	// Namespace is the namespace the item would be restored into, after
+	// any namespace mapping. It's empty for cluster-scoped items.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name is the name of the item.
+	Name string `json:"name"`
+
+	// Action is what the restore would do with the item.
+	Action RestoreDryRunAction `json:"action"`
+
+	// Message explains the action, e.g. why the item would be skipped
+	// or what would happen to an existing item.
+	Message string `json:"message,omitempty"`
+
+	// Diff lists the fields set in the backed-up version of an existing
+	// item whose values differ from those in the cluster.
+	Diff []RestoreDryRunFieldDiff `json:"diff,omitempty"`
+}
+
+// RestoreDryRunFieldDiff is a field whose value differs between the
+// item in the backup and the item in the cluster.
+type RestoreDryRunFieldDiff struct {
+	// Path is the dot-separated path of the field, e.g. "spec.replicas".
+	Path string `json:"path"`
+
+	// Backup is the JSON-encoded value of the field that would be
+	// restored, or empty if the field isn't set in the backup.
+	Backup string `json:"backup,omitempty"`
+
+	// Live is the JSON-encoded value of the field in the cluster, or
+	// empty if the field isn't set in the cluster.
+	Live string `json:"live,omitempty"`
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -27,6 +27,109 @@ type ScheduleSpec struct {
 	// Schedule is a Cron expression defining when to run
 	// the Backup.
 	Schedule string `json:"schedule"`
+
+	// Retention, if set, determines which of the Schedule's
+	// Backups are kept, in place of their TTLs.
+	Retention *ScheduleRetention `json:"retention,omitempty"`
+
+	// Paused stops the Schedule from triggering Backups until
+	// it's set back to false. If a run was missed while the
+	// Schedule was paused, a Backup is triggered when it's
+	// resumed.
+	Paused bool `json:"paused,omitempty"`
+
+	// ConcurrencyPolicy determines what happens when the Schedule
+	// is due while one of its Backups hasn't finished. Defaults
+	// to Allow.
+	ConcurrencyPolicy ScheduleConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// StartingDeadline, if set, is how long after it was due a
+	// run can still start. Runs that were missed by more than
+	// this, e.g. because the Ark server wasn't running, are
+	// recorded as missed rather than started late.
+	StartingDeadline *metav1.Duration `json:"startingDeadline,omitempty"`
+
+	// TimeZone is the IANA name of the time zone that Schedule and
+	// BlackoutWindows are evaluated in, e.g. "America/New_York".
+	// Defaults to the Ark server's local time zone.
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// BlackoutWindows are recurring periods during which runs
+	// that are due are deferred or skipped.
+	BlackoutWindows []ScheduleBlackoutWindow `json:"blackoutWindows,omitempty"`
+}
+
+// ScheduleBlackoutAction is a string representation of what happens
+// to a Schedule's runs that are due during a blackout window.
+type ScheduleBlackoutAction string
+
+const (
+	// ScheduleBlackoutActionDefer means runs that are due during the
+	// window start when it ends.
+	ScheduleBlackoutActionDefer ScheduleBlackoutAction = "Defer"
+
+	// ScheduleBlackoutActionSkip means runs that are due during the
+	// window are skipped.
+	ScheduleBlackoutActionSkip ScheduleBlackoutAction = "Skip"
+)
+
+// ScheduleBlackoutWindow is a recurring period during which a
+// Schedule's runs are deferred or skipped, e.g. peak traffic hours
+// or a release freeze.
+type ScheduleBlackoutWindow struct {
+	// Name describes the window.
+	Name string `json:"name,omitempty"`
+
+	// Start is a Cron expression defining when the window
+	// starts.
+	Start string `json:"start"`
+
+	// Duration is how long the window lasts.
+	Duration metav1.Duration `json:"duration"`
+
+	// Action is what happens to runs that are due during the
+	// window. Defaults to Defer.
+	Action ScheduleBlackoutAction `json:"action,omitempty"`
+}
+
+// ScheduleConcurrencyPolicy is a string representation of how a
+// Schedule handles a run that's due while one of its Backups
+// hasn't finished.
+type ScheduleConcurrencyPolicy string
+
+const (
+	// ScheduleConcurrencyPolicyAllow means a Backup is created
+	// for every run, even if earlier ones haven't finished.
+	ScheduleConcurrencyPolicyAllow ScheduleConcurrencyPolicy = "Allow"
+
+	// ScheduleConcurrencyPolicyForbid means runs are skipped while
+	// one of the Schedule's Backups hasn't finished.
+	ScheduleConcurrencyPolicyForbid ScheduleConcurrencyPolicy = "Forbid"
+
+	// ScheduleConcurrencyPolicyReplace means the Schedule's Backups
+	// that haven't started yet are deleted and replaced by the new
+	// run's Backup. Backups that are in progress can't be stopped,
+	// so they're left to finish.
+	ScheduleConcurrencyPolicyReplace ScheduleConcurrencyPolicy = "Replace"
+)
+
+// ScheduleRetention defines how many of a Schedule's Backups to keep.
+// Backups are kept if they're selected by any of the rules, e.g. with
+// KeepLast 3 and KeepDaily 7, the last 3 Backups are kept, along with the
+// last Backup of each of the last 7 days that have Backups. The newest
+// completed Backup is always kept.
+type ScheduleRetention struct {
+	// KeepLast is the number of most recent Backups to keep.
+	KeepLast int `json:"keepLast,omitempty"`
+
+	// KeepDaily is the number of days to keep the last Backup of.
+	KeepDaily int `json:"keepDaily,omitempty"`
+
+	// KeepWeekly is the number of weeks to keep the last Backup of.
+	KeepWeekly int `json:"keepWeekly,omitempty"`
+
+	// KeepMonthly is the number of months to keep the last Backup of.
+	KeepMonthly int `json:"keepMonthly,omitempty"`
 }
 
 // SchedulePhase is a string representation of the lifecycle phase
@@ -59,6 +162,73 @@ type ScheduleStatus struct {
 	// ValidationErrors is a slice of all validation errors (if
 	// applicable)
 	ValidationErrors []string `json:"validationErrors"`
+
+	// NextBackup is the next time the Schedule is due to run. It's
+	// not set while the Schedule is paused.
+	NextBackup metav1.Time `json:"nextBackup"`
+
+	// LastSuccessfulBackup is the Schedule's most recent Backup that
+	// completed.
+	LastSuccessfulBackup *ScheduleBackupReference `json:"lastSuccessfulBackup,omitempty"`
+
+	// LastFailedBackup is the Schedule's most recent Backup that
+	// failed or partially failed.
+	LastFailedBackup *ScheduleBackupReference `json:"lastFailedBackup,omitempty"`
+
+	// History is the Schedule's most recent runs, newest first.
+	History []ScheduleRun `json:"history,omitempty"`
+}
+
+// ScheduleBackupReference identifies one of a Schedule's Backups.
+type ScheduleBackupReference struct {
+	// Name is the name of the Backup.
+	Name string `json:"name"`
+
+	// Timestamp is when the Backup finished.
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// ScheduleRunResult is a string representation of the outcome of
+// one of a Schedule's runs.
+type ScheduleRunResult string
+
+const (
+	// ScheduleRunResultSubmitted means a Backup was created for the run.
+	ScheduleRunResultSubmitted ScheduleRunResult = "Submitted"
+
+	// ScheduleRunResultSkipped means no Backup was created because
+	// the Schedule's ConcurrencyPolicy is Forbid and one of its
+	// Backups hadn't finished.
+	ScheduleRunResultSkipped ScheduleRunResult = "Skipped"
+
+	// ScheduleRunResultMissed means no Backup was created because
+	// the run was missed by more than the Schedule's StartingDeadline.
+	ScheduleRunResultMissed ScheduleRunResult = "Missed"
+)
+
+// ScheduleRun records one of a Schedule's runs.
+type ScheduleRun struct {
+	// ScheduledTime is the time the run was due.
+	ScheduledTime metav1.Time `json:"scheduledTime"`
+
+	// Result is the outcome of the run.
+	Result ScheduleRunResult `json:"result"`
+
+	// Backup is the name of the Backup created for the run, if any.
+	Backup string `json:"backup,omitempty"`
+
+	// BackupPhase is the phase of the Backup, as last seen by the
+	// Schedule controller.
+	BackupPhase BackupPhase `json:"backupPhase,omitempty"`
+
+	// Message explains the run's Result, e.g. which Backup was still
+	// in progress, or how many earlier runs were missed.
+	Message string `json:"message,omitempty"`
+
+	// Triggered is whether the run was requested with the trigger
+	// annotation rather than being due by the Schedule's cron expression.
+	// Triggered runs don't affect when the Schedule is next due.
+	Triggered bool `json:"triggered,omitempty"`
 }
 
 // +genclient
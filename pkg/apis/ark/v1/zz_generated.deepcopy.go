@@ -21,6 +21,7 @@ limitations under the License.
 package v1
 
 import (
+	core_v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -36,6 +37,14 @@ func GetGeneratedDeepCopyFuncs() []conversion.GeneratedDeepCopyFunc {
 			in.(*Backup).DeepCopyInto(out.(*Backup))
 			return nil
 		}, InType: reflect.TypeOf(&Backup{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*BackupContentsIndex).DeepCopyInto(out.(*BackupContentsIndex))
+			return nil
+		}, InType: reflect.TypeOf(&BackupContentsIndex{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*BackupContentsIndexItem).DeepCopyInto(out.(*BackupContentsIndexItem))
+			return nil
+		}, InType: reflect.TypeOf(&BackupContentsIndexItem{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*BackupHooks).DeepCopyInto(out.(*BackupHooks))
 			return nil
@@ -44,6 +53,10 @@ func GetGeneratedDeepCopyFuncs() []conversion.GeneratedDeepCopyFunc {
 			in.(*BackupList).DeepCopyInto(out.(*BackupList))
 			return nil
 		}, InType: reflect.TypeOf(&BackupList{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*BackupProgress).DeepCopyInto(out.(*BackupProgress))
+			return nil
+		}, InType: reflect.TypeOf(&BackupProgress{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*BackupResourceHook).DeepCopyInto(out.(*BackupResourceHook))
 			return nil
@@ -52,6 +65,10 @@ func GetGeneratedDeepCopyFuncs() []conversion.GeneratedDeepCopyFunc {
 			in.(*BackupResourceHookSpec).DeepCopyInto(out.(*BackupResourceHookSpec))
 			return nil
 		}, InType: reflect.TypeOf(&BackupResourceHookSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*BackupResult).DeepCopyInto(out.(*BackupResult))
+			return nil
+		}, InType: reflect.TypeOf(&BackupResult{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*BackupSpec).DeepCopyInto(out.(*BackupSpec))
 			return nil
@@ -60,6 +77,18 @@ func GetGeneratedDeepCopyFuncs() []conversion.GeneratedDeepCopyFunc {
 			in.(*BackupStatus).DeepCopyInto(out.(*BackupStatus))
 			return nil
 		}, InType: reflect.TypeOf(&BackupStatus{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*BackupStorageLocation).DeepCopyInto(out.(*BackupStorageLocation))
+			return nil
+		}, InType: reflect.TypeOf(&BackupStorageLocation{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*BackupStorageLocationList).DeepCopyInto(out.(*BackupStorageLocationList))
+			return nil
+		}, InType: reflect.TypeOf(&BackupStorageLocationList{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*BackupStorageLocationSpec).DeepCopyInto(out.(*BackupStorageLocationSpec))
+			return nil
+		}, InType: reflect.TypeOf(&BackupStorageLocationSpec{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*CloudProviderConfig).DeepCopyInto(out.(*CloudProviderConfig))
 			return nil
@@ -72,6 +101,22 @@ func GetGeneratedDeepCopyFuncs() []conversion.GeneratedDeepCopyFunc {
 			in.(*ConfigList).DeepCopyInto(out.(*ConfigList))
 			return nil
 		}, InType: reflect.TypeOf(&ConfigList{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*DeleteBackupRequest).DeepCopyInto(out.(*DeleteBackupRequest))
+			return nil
+		}, InType: reflect.TypeOf(&DeleteBackupRequest{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*DeleteBackupRequestList).DeepCopyInto(out.(*DeleteBackupRequestList))
+			return nil
+		}, InType: reflect.TypeOf(&DeleteBackupRequestList{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*DeleteBackupRequestSpec).DeepCopyInto(out.(*DeleteBackupRequestSpec))
+			return nil
+		}, InType: reflect.TypeOf(&DeleteBackupRequestSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*DeleteBackupRequestStatus).DeepCopyInto(out.(*DeleteBackupRequestStatus))
+			return nil
+		}, InType: reflect.TypeOf(&DeleteBackupRequestStatus{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*DownloadRequest).DeepCopyInto(out.(*DownloadRequest))
 			return nil
@@ -92,22 +137,102 @@ func GetGeneratedDeepCopyFuncs() []conversion.GeneratedDeepCopyFunc {
 			in.(*DownloadTarget).DeepCopyInto(out.(*DownloadTarget))
 			return nil
 		}, InType: reflect.TypeOf(&DownloadTarget{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*EncryptionConfig).DeepCopyInto(out.(*EncryptionConfig))
+			return nil
+		}, InType: reflect.TypeOf(&EncryptionConfig{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*ExecHook).DeepCopyInto(out.(*ExecHook))
 			return nil
 		}, InType: reflect.TypeOf(&ExecHook{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ExecRestoreHook).DeepCopyInto(out.(*ExecRestoreHook))
+			return nil
+		}, InType: reflect.TypeOf(&ExecRestoreHook{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*HookStatus).DeepCopyInto(out.(*HookStatus))
+			return nil
+		}, InType: reflect.TypeOf(&HookStatus{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*InitRestoreHook).DeepCopyInto(out.(*InitRestoreHook))
+			return nil
+		}, InType: reflect.TypeOf(&InitRestoreHook{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*ObjectStorageProviderConfig).DeepCopyInto(out.(*ObjectStorageProviderConfig))
 			return nil
 		}, InType: reflect.TypeOf(&ObjectStorageProviderConfig{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PodVolumeBackup).DeepCopyInto(out.(*PodVolumeBackup))
+			return nil
+		}, InType: reflect.TypeOf(&PodVolumeBackup{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PodVolumeBackupInfo).DeepCopyInto(out.(*PodVolumeBackupInfo))
+			return nil
+		}, InType: reflect.TypeOf(&PodVolumeBackupInfo{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PodVolumeBackupList).DeepCopyInto(out.(*PodVolumeBackupList))
+			return nil
+		}, InType: reflect.TypeOf(&PodVolumeBackupList{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PodVolumeBackupSpec).DeepCopyInto(out.(*PodVolumeBackupSpec))
+			return nil
+		}, InType: reflect.TypeOf(&PodVolumeBackupSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PodVolumeBackupStatus).DeepCopyInto(out.(*PodVolumeBackupStatus))
+			return nil
+		}, InType: reflect.TypeOf(&PodVolumeBackupStatus{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PodVolumeRestore).DeepCopyInto(out.(*PodVolumeRestore))
+			return nil
+		}, InType: reflect.TypeOf(&PodVolumeRestore{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PodVolumeRestoreList).DeepCopyInto(out.(*PodVolumeRestoreList))
+			return nil
+		}, InType: reflect.TypeOf(&PodVolumeRestoreList{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PodVolumeRestoreSpec).DeepCopyInto(out.(*PodVolumeRestoreSpec))
+			return nil
+		}, InType: reflect.TypeOf(&PodVolumeRestoreSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PodVolumeRestoreStatus).DeepCopyInto(out.(*PodVolumeRestoreStatus))
+			return nil
+		}, InType: reflect.TypeOf(&PodVolumeRestoreStatus{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ResourceReadiness).DeepCopyInto(out.(*ResourceReadiness))
+			return nil
+		}, InType: reflect.TypeOf(&ResourceReadiness{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*Restore).DeepCopyInto(out.(*Restore))
 			return nil
 		}, InType: reflect.TypeOf(&Restore{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RestoreDryRunFieldDiff).DeepCopyInto(out.(*RestoreDryRunFieldDiff))
+			return nil
+		}, InType: reflect.TypeOf(&RestoreDryRunFieldDiff{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RestoreDryRunItem).DeepCopyInto(out.(*RestoreDryRunItem))
+			return nil
+		}, InType: reflect.TypeOf(&RestoreDryRunItem{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RestoreDryRunReport).DeepCopyInto(out.(*RestoreDryRunReport))
+			return nil
+		}, InType: reflect.TypeOf(&RestoreDryRunReport{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RestoreHooks).DeepCopyInto(out.(*RestoreHooks))
+			return nil
+		}, InType: reflect.TypeOf(&RestoreHooks{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*RestoreList).DeepCopyInto(out.(*RestoreList))
 			return nil
 		}, InType: reflect.TypeOf(&RestoreList{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RestoreResourceHook).DeepCopyInto(out.(*RestoreResourceHook))
+			return nil
+		}, InType: reflect.TypeOf(&RestoreResourceHook{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RestoreResourceHookSpec).DeepCopyInto(out.(*RestoreResourceHookSpec))
+			return nil
+		}, InType: reflect.TypeOf(&RestoreResourceHookSpec{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*RestoreResult).DeepCopyInto(out.(*RestoreResult))
 			return nil
@@ -124,10 +249,26 @@ func GetGeneratedDeepCopyFuncs() []conversion.GeneratedDeepCopyFunc {
 			in.(*Schedule).DeepCopyInto(out.(*Schedule))
 			return nil
 		}, InType: reflect.TypeOf(&Schedule{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ScheduleBackupReference).DeepCopyInto(out.(*ScheduleBackupReference))
+			return nil
+		}, InType: reflect.TypeOf(&ScheduleBackupReference{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ScheduleBlackoutWindow).DeepCopyInto(out.(*ScheduleBlackoutWindow))
+			return nil
+		}, InType: reflect.TypeOf(&ScheduleBlackoutWindow{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*ScheduleList).DeepCopyInto(out.(*ScheduleList))
 			return nil
 		}, InType: reflect.TypeOf(&ScheduleList{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ScheduleRetention).DeepCopyInto(out.(*ScheduleRetention))
+			return nil
+		}, InType: reflect.TypeOf(&ScheduleRetention{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ScheduleRun).DeepCopyInto(out.(*ScheduleRun))
+			return nil
+		}, InType: reflect.TypeOf(&ScheduleRun{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*ScheduleSpec).DeepCopyInto(out.(*ScheduleSpec))
 			return nil
@@ -172,6 +313,43 @@ func (in *Backup) DeepCopyObject() runtime.Object {
 	}
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupContentsIndex) DeepCopyInto(out *BackupContentsIndex) {
+	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BackupContentsIndexItem, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupContentsIndex.
+func (in *BackupContentsIndex) DeepCopy() *BackupContentsIndex {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupContentsIndex)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupContentsIndexItem) DeepCopyInto(out *BackupContentsIndexItem) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupContentsIndexItem.
+func (in *BackupContentsIndexItem) DeepCopy() *BackupContentsIndexItem {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupContentsIndexItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupHooks) DeepCopyInto(out *BackupHooks) {
 	*out = *in
@@ -229,6 +407,22 @@ func (in *BackupList) DeepCopyObject() runtime.Object {
 	}
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupProgress) DeepCopyInto(out *BackupProgress) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupProgress.
+func (in *BackupProgress) DeepCopy() *BackupProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupResourceHook) DeepCopyInto(out *BackupResourceHook) {
 	*out = *in
@@ -293,6 +487,20 @@ func (in *BackupResourceHookSpec) DeepCopyInto(out *BackupResourceHookSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PreHooks != nil {
+		in, out := &in.PreHooks, &out.PreHooks
+		*out = make([]BackupResourceHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PostHooks != nil {
+		in, out := &in.PostHooks, &out.PostHooks
+		*out = make([]BackupResourceHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -306,6 +514,44 @@ func (in *BackupResourceHookSpec) DeepCopy() *BackupResourceHookSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupResult) DeepCopyInto(out *BackupResult) {
+	*out = *in
+	if in.Ark != nil {
+		in, out := &in.Ark, &out.Ark
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Cluster != nil {
+		in, out := &in.Cluster, &out.Cluster
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				(*out)[key] = make([]string, len(val))
+				copy((*out)[key], val)
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupResult.
+func (in *BackupResult) DeepCopy() *BackupResult {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
 	*out = *in
@@ -387,11 +633,43 @@ func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
 			}
 		}
 	}
+	if in.PodVolumeBackups != nil {
+		in, out := &in.PodVolumeBackups, &out.PodVolumeBackups
+		*out = make(map[string]*PodVolumeBackupInfo, len(*in))
+		for key, val := range *in {
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				(*out)[key] = new(PodVolumeBackupInfo)
+				val.DeepCopyInto((*out)[key])
+			}
+		}
+	}
 	if in.ValidationErrors != nil {
 		in, out := &in.ValidationErrors, &out.ValidationErrors
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.HookStatus != nil {
+		in, out := &in.HookStatus, &out.HookStatus
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(HookStatus)
+			**out = **in
+		}
+	}
+	in.StartTimestamp.DeepCopyInto(&out.StartTimestamp)
+	in.CompletionTimestamp.DeepCopyInto(&out.CompletionTimestamp)
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(BackupProgress)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -405,6 +683,91 @@ func (in *BackupStatus) DeepCopy() *BackupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStorageLocation) DeepCopyInto(out *BackupStorageLocation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageLocation.
+func (in *BackupStorageLocation) DeepCopy() *BackupStorageLocation {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStorageLocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BackupStorageLocation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStorageLocationList) DeepCopyInto(out *BackupStorageLocationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BackupStorageLocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageLocationList.
+func (in *BackupStorageLocationList) DeepCopy() *BackupStorageLocationList {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStorageLocationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BackupStorageLocationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStorageLocationSpec) DeepCopyInto(out *BackupStorageLocationSpec) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageLocationSpec.
+func (in *BackupStorageLocationSpec) DeepCopy() *BackupStorageLocationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStorageLocationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudProviderConfig) DeepCopyInto(out *CloudProviderConfig) {
 	*out = *in
@@ -451,6 +814,22 @@ func (in *Config) DeepCopyInto(out *Config) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(EncryptionConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.RestoreResourceReadiness != nil {
+		in, out := &in.RestoreResourceReadiness, &out.RestoreResourceReadiness
+		*out = make([]ResourceReadiness, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -508,7 +887,7 @@ func (in *ConfigList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DownloadRequest) DeepCopyInto(out *DownloadRequest) {
+func (in *DeleteBackupRequest) DeepCopyInto(out *DeleteBackupRequest) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -517,18 +896,467 @@ func (in *DownloadRequest) DeepCopyInto(out *DownloadRequest) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownloadRequest.
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeleteBackupRequest.
+func (in *DeleteBackupRequest) DeepCopy() *DeleteBackupRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(DeleteBackupRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeleteBackupRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeleteBackupRequestList) DeepCopyInto(out *DeleteBackupRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeleteBackupRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeleteBackupRequestList.
+func (in *DeleteBackupRequestList) DeepCopy() *DeleteBackupRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeleteBackupRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeleteBackupRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeleteBackupRequestSpec) DeepCopyInto(out *DeleteBackupRequestSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeleteBackupRequestSpec.
+func (in *DeleteBackupRequestSpec) DeepCopy() *DeleteBackupRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeleteBackupRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeleteBackupRequestStatus) DeepCopyInto(out *DeleteBackupRequestStatus) {
+	*out = *in
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeleteBackupRequestStatus.
+func (in *DeleteBackupRequestStatus) DeepCopy() *DeleteBackupRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeleteBackupRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DownloadRequest) DeepCopyInto(out *DownloadRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownloadRequest.
 func (in *DownloadRequest) DeepCopy() *DownloadRequest {
 	if in == nil {
 		return nil
 	}
-	out := new(DownloadRequest)
+	out := new(DownloadRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DownloadRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DownloadRequestList) DeepCopyInto(out *DownloadRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DownloadRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownloadRequestList.
+func (in *DownloadRequestList) DeepCopy() *DownloadRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(DownloadRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DownloadRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DownloadRequestSpec) DeepCopyInto(out *DownloadRequestSpec) {
+	*out = *in
+	out.Target = in.Target
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownloadRequestSpec.
+func (in *DownloadRequestSpec) DeepCopy() *DownloadRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DownloadRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DownloadRequestStatus) DeepCopyInto(out *DownloadRequestStatus) {
+	*out = *in
+	in.Expiration.DeepCopyInto(&out.Expiration)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownloadRequestStatus.
+func (in *DownloadRequestStatus) DeepCopy() *DownloadRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DownloadRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DownloadTarget) DeepCopyInto(out *DownloadTarget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownloadTarget.
+func (in *DownloadTarget) DeepCopy() *DownloadTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(DownloadTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionConfig) DeepCopyInto(out *EncryptionConfig) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionConfig.
+func (in *EncryptionConfig) DeepCopy() *EncryptionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecHook) DeepCopyInto(out *ExecHook) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Timeout = in.Timeout
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecHook.
+func (in *ExecHook) DeepCopy() *ExecHook {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecRestoreHook) DeepCopyInto(out *ExecRestoreHook) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.ExecTimeout = in.ExecTimeout
+	out.WaitTimeout = in.WaitTimeout
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecRestoreHook.
+func (in *ExecRestoreHook) DeepCopy() *ExecRestoreHook {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecRestoreHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookStatus) DeepCopyInto(out *HookStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookStatus.
+func (in *HookStatus) DeepCopy() *HookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitRestoreHook) DeepCopyInto(out *InitRestoreHook) {
+	*out = *in
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]core_v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitRestoreHook.
+func (in *InitRestoreHook) DeepCopy() *InitRestoreHook {
+	if in == nil {
+		return nil
+	}
+	out := new(InitRestoreHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStorageProviderConfig) DeepCopyInto(out *ObjectStorageProviderConfig) {
+	*out = *in
+	in.CloudProviderConfig.DeepCopyInto(&out.CloudProviderConfig)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageProviderConfig.
+func (in *ObjectStorageProviderConfig) DeepCopy() *ObjectStorageProviderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStorageProviderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodVolumeBackup) DeepCopyInto(out *PodVolumeBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodVolumeBackup.
+func (in *PodVolumeBackup) DeepCopy() *PodVolumeBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(PodVolumeBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodVolumeBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodVolumeBackupInfo) DeepCopyInto(out *PodVolumeBackupInfo) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodVolumeBackupInfo.
+func (in *PodVolumeBackupInfo) DeepCopy() *PodVolumeBackupInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(PodVolumeBackupInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodVolumeBackupList) DeepCopyInto(out *PodVolumeBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PodVolumeBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodVolumeBackupList.
+func (in *PodVolumeBackupList) DeepCopy() *PodVolumeBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(PodVolumeBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodVolumeBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodVolumeBackupSpec) DeepCopyInto(out *PodVolumeBackupSpec) {
+	*out = *in
+	out.Pod = in.Pod
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodVolumeBackupSpec.
+func (in *PodVolumeBackupSpec) DeepCopy() *PodVolumeBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodVolumeBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodVolumeBackupStatus) DeepCopyInto(out *PodVolumeBackupStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodVolumeBackupStatus.
+func (in *PodVolumeBackupStatus) DeepCopy() *PodVolumeBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PodVolumeBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodVolumeRestore) DeepCopyInto(out *PodVolumeRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodVolumeRestore.
+func (in *PodVolumeRestore) DeepCopy() *PodVolumeRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(PodVolumeRestore)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DownloadRequest) DeepCopyObject() runtime.Object {
+func (in *PodVolumeRestore) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	} else {
@@ -537,13 +1365,13 @@ func (in *DownloadRequest) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DownloadRequestList) DeepCopyInto(out *DownloadRequestList) {
+func (in *PodVolumeRestoreList) DeepCopyInto(out *PodVolumeRestoreList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]DownloadRequest, len(*in))
+		*out = make([]PodVolumeRestore, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -551,18 +1379,18 @@ func (in *DownloadRequestList) DeepCopyInto(out *DownloadRequestList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownloadRequestList.
-func (in *DownloadRequestList) DeepCopy() *DownloadRequestList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodVolumeRestoreList.
+func (in *PodVolumeRestoreList) DeepCopy() *PodVolumeRestoreList {
 	if in == nil {
 		return nil
 	}
-	out := new(DownloadRequestList)
+	out := new(PodVolumeRestoreList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DownloadRequestList) DeepCopyObject() runtime.Object {
+func (in *PodVolumeRestoreList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	} else {
@@ -571,121 +1399,170 @@ func (in *DownloadRequestList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DownloadRequestSpec) DeepCopyInto(out *DownloadRequestSpec) {
+func (in *PodVolumeRestoreSpec) DeepCopyInto(out *PodVolumeRestoreSpec) {
 	*out = *in
-	out.Target = in.Target
+	out.Pod = in.Pod
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownloadRequestSpec.
-func (in *DownloadRequestSpec) DeepCopy() *DownloadRequestSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodVolumeRestoreSpec.
+func (in *PodVolumeRestoreSpec) DeepCopy() *PodVolumeRestoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DownloadRequestSpec)
+	out := new(PodVolumeRestoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DownloadRequestStatus) DeepCopyInto(out *DownloadRequestStatus) {
+func (in *PodVolumeRestoreStatus) DeepCopyInto(out *PodVolumeRestoreStatus) {
 	*out = *in
-	in.Expiration.DeepCopyInto(&out.Expiration)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownloadRequestStatus.
-func (in *DownloadRequestStatus) DeepCopy() *DownloadRequestStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodVolumeRestoreStatus.
+func (in *PodVolumeRestoreStatus) DeepCopy() *PodVolumeRestoreStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DownloadRequestStatus)
+	out := new(PodVolumeRestoreStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DownloadTarget) DeepCopyInto(out *DownloadTarget) {
+func (in *ResourceReadiness) DeepCopyInto(out *ResourceReadiness) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Timeout = in.Timeout
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownloadTarget.
-func (in *DownloadTarget) DeepCopy() *DownloadTarget {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceReadiness.
+func (in *ResourceReadiness) DeepCopy() *ResourceReadiness {
 	if in == nil {
 		return nil
 	}
-	out := new(DownloadTarget)
+	out := new(ResourceReadiness)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExecHook) DeepCopyInto(out *ExecHook) {
+func (in *Restore) DeepCopyInto(out *Restore) {
 	*out = *in
-	if in.Command != nil {
-		in, out := &in.Command, &out.Command
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Restore.
+func (in *Restore) DeepCopy() *Restore {
+	if in == nil {
+		return nil
 	}
-	out.Timeout = in.Timeout
+	out := new(Restore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Restore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreDryRunFieldDiff) DeepCopyInto(out *RestoreDryRunFieldDiff) {
+	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecHook.
-func (in *ExecHook) DeepCopy() *ExecHook {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreDryRunFieldDiff.
+func (in *RestoreDryRunFieldDiff) DeepCopy() *RestoreDryRunFieldDiff {
 	if in == nil {
 		return nil
 	}
-	out := new(ExecHook)
+	out := new(RestoreDryRunFieldDiff)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectStorageProviderConfig) DeepCopyInto(out *ObjectStorageProviderConfig) {
+func (in *RestoreDryRunItem) DeepCopyInto(out *RestoreDryRunItem) {
 	*out = *in
-	in.CloudProviderConfig.DeepCopyInto(&out.CloudProviderConfig)
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = make([]RestoreDryRunFieldDiff, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageProviderConfig.
-func (in *ObjectStorageProviderConfig) DeepCopy() *ObjectStorageProviderConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreDryRunItem.
+func (in *RestoreDryRunItem) DeepCopy() *RestoreDryRunItem {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectStorageProviderConfig)
+	out := new(RestoreDryRunItem)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Restore) DeepCopyInto(out *Restore) {
+func (in *RestoreDryRunReport) DeepCopyInto(out *RestoreDryRunReport) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RestoreDryRunItem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Restore.
-func (in *Restore) DeepCopy() *Restore {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreDryRunReport.
+func (in *RestoreDryRunReport) DeepCopy() *RestoreDryRunReport {
 	if in == nil {
 		return nil
 	}
-	out := new(Restore)
+	out := new(RestoreDryRunReport)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Restore) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	} else {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreHooks) DeepCopyInto(out *RestoreHooks) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]RestoreResourceHookSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreHooks.
+func (in *RestoreHooks) DeepCopy() *RestoreHooks {
+	if in == nil {
 		return nil
 	}
+	out := new(RestoreHooks)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -722,6 +1599,82 @@ func (in *RestoreList) DeepCopyObject() runtime.Object {
 	}
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreResourceHook) DeepCopyInto(out *RestoreResourceHook) {
+	*out = *in
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ExecRestoreHook)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Init != nil {
+		in, out := &in.Init, &out.Init
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(InitRestoreHook)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreResourceHook.
+func (in *RestoreResourceHook) DeepCopy() *RestoreResourceHook {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreResourceHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreResourceHookSpec) DeepCopyInto(out *RestoreResourceHookSpec) {
+	*out = *in
+	if in.IncludedNamespaces != nil {
+		in, out := &in.IncludedNamespaces, &out.IncludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedNamespaces != nil {
+		in, out := &in.ExcludedNamespaces, &out.ExcludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.PostHooks != nil {
+		in, out := &in.PostHooks, &out.PostHooks
+		*out = make([]RestoreResourceHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreResourceHookSpec.
+func (in *RestoreResourceHookSpec) DeepCopy() *RestoreResourceHookSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreResourceHookSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestoreResult) DeepCopyInto(out *RestoreResult) {
 	*out = *in
@@ -817,6 +1770,7 @@ func (in *RestoreSpec) DeepCopyInto(out *RestoreSpec) {
 			**out = **in
 		}
 	}
+	in.Hooks.DeepCopyInto(&out.Hooks)
 	return
 }
 
@@ -838,6 +1792,15 @@ func (in *RestoreStatus) DeepCopyInto(out *RestoreStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.HookStatus != nil {
+		in, out := &in.HookStatus, &out.HookStatus
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(HookStatus)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -880,6 +1843,40 @@ func (in *Schedule) DeepCopyObject() runtime.Object {
 	}
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleBackupReference) DeepCopyInto(out *ScheduleBackupReference) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleBackupReference.
+func (in *ScheduleBackupReference) DeepCopy() *ScheduleBackupReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleBackupReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleBlackoutWindow) DeepCopyInto(out *ScheduleBlackoutWindow) {
+	*out = *in
+	out.Duration = in.Duration
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleBlackoutWindow.
+func (in *ScheduleBlackoutWindow) DeepCopy() *ScheduleBlackoutWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleBlackoutWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScheduleList) DeepCopyInto(out *ScheduleList) {
 	*out = *in
@@ -914,10 +1911,66 @@ func (in *ScheduleList) DeepCopyObject() runtime.Object {
 	}
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleRetention) DeepCopyInto(out *ScheduleRetention) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleRetention.
+func (in *ScheduleRetention) DeepCopy() *ScheduleRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleRun) DeepCopyInto(out *ScheduleRun) {
+	*out = *in
+	in.ScheduledTime.DeepCopyInto(&out.ScheduledTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleRun.
+func (in *ScheduleRun) DeepCopy() *ScheduleRun {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScheduleSpec) DeepCopyInto(out *ScheduleSpec) {
 	*out = *in
 	in.Template.DeepCopyInto(&out.Template)
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ScheduleRetention)
+			**out = **in
+		}
+	}
+	if in.StartingDeadline != nil {
+		in, out := &in.StartingDeadline, &out.StartingDeadline
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	if in.BlackoutWindows != nil {
+		in, out := &in.BlackoutWindows, &out.BlackoutWindows
+		*out = make([]ScheduleBlackoutWindow, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -940,6 +1993,32 @@ func (in *ScheduleStatus) DeepCopyInto(out *ScheduleStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	in.NextBackup.DeepCopyInto(&out.NextBackup)
+	if in.LastSuccessfulBackup != nil {
+		in, out := &in.LastSuccessfulBackup, &out.LastSuccessfulBackup
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ScheduleBackupReference)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.LastFailedBackup != nil {
+		in, out := &in.LastFailedBackup, &out.LastFailedBackup
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ScheduleBackupReference)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]ScheduleRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
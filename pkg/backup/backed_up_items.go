@@ -0,0 +1,131 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// backedUpItemSet is the set of items that have been, or are being, backed up. It's safe
+// for concurrent use by the workers backing up items.
+type backedUpItemSet struct {
+	lock  sync.Mutex
+	items map[itemKey]*backedUpItem
+}
+
+func newBackedUpItemSet() *backedUpItemSet {
+	return &backedUpItemSet{
+		items: make(map[itemKey]*backedUpItem),
+	}
+}
+
+// add adds the item with the given key to the set, unless it's already in it. It returns
+// the item's entry in the set, and whether it was added. If it was, the caller is responsible
+// for backing the item up and then calling finish on the entry.
+func (s *backedUpItemSet) add(key itemKey) (*backedUpItem, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if item, found := s.items[key]; found {
+		return item, false
+	}
+
+	item := &backedUpItem{
+		key:  key,
+		done: make(chan struct{}),
+	}
+	s.items[key] = item
+
+	return item, true
+}
+
+// has returns true if the item with the given key is in the set.
+func (s *backedUpItemSet) has(key itemKey) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, found := s.items[key]
+	return found
+}
+
+// backedUpItem is an item in a backedUpItemSet. Once it's finished, it holds the item's
+// tarball entry, if the item was backed up successfully, and the additional items returned
+// for it by ItemActions, including ones that had already been backed up for other items.
+type backedUpItem struct {
+	key        itemKey
+	header     *tar.Header
+	data       []byte
+	additional []*backedUpItem
+
+	done chan struct{}
+	// written is only accessed by the tar-writing stage.
+	written bool
+}
+
+// finish records that the worker backing up the item is done with it.
+func (i *backedUpItem) finish() {
+	close(i.done)
+}
+
+// writeItem writes the item's additional items, followed by the item itself, to the tarball,
+// waiting for each of them to be finished first. Items that have already been written are
+// skipped, so each item is written once, in a position that depends only on the order items
+// are written in and not on which of the workers happened to back it up. Errors writing
+// items are logged.
+func writeItem(log logrus.FieldLogger, tw tarWriter, item *backedUpItem) {
+	if item.written {
+		return
+	}
+	item.written = true
+
+	<-item.done
+
+	for _, additional := range item.additional {
+		writeItem(log, tw, additional)
+	}
+
+	if item.header == nil {
+		return
+	}
+
+	if err := writeTarEntry(tw, item.header, item.data); err != nil {
+		log.WithError(err).WithFields(logrus.Fields{
+			"groupResource": item.key.resource,
+			"namespace":     item.key.namespace,
+			"name":          item.key.name,
+		}).Error("Error backing up item")
+	}
+
+	// the item's contents are no longer needed once they're in the tarball
+	item.header, item.data = nil, nil
+}
+
+func writeTarEntry(tw tarWriter, header *tar.Header, data []byte) error {
+	if err := tw.WriteHeader(header); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if _, err := tw.Write(data); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
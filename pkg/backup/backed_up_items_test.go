@@ -0,0 +1,126 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	testlogger "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestBackedUpItemSetAdd(t *testing.T) {
+	s := newBackedUpItemSet()
+	key := itemKey{resource: "pods", namespace: "ns", name: "pod"}
+
+	assert.False(t, s.has(key))
+
+	item, added := s.add(key)
+	require.True(t, added)
+	assert.Equal(t, key, item.key)
+	assert.True(t, s.has(key))
+
+	existing, added := s.add(key)
+	assert.False(t, added)
+	assert.True(t, item == existing)
+}
+
+// finishedItem returns a finished item in a new set with a tarball entry named after it.
+func finishedItem(name string, additional ...*backedUpItem) *backedUpItem {
+	item, _ := newBackedUpItemSet().add(itemKey{resource: "pods", namespace: "ns", name: name})
+	item.header = &tar.Header{Name: name}
+	item.data = []byte(name)
+	item.additional = additional
+	item.finish()
+
+	return item
+}
+
+func TestWriteItemWritesAdditionalItemsFirstAndOnlyOnce(t *testing.T) {
+	var (
+		shared = finishedItem("shared")
+		a      = finishedItem("a", finishedItem("a-1", shared), shared)
+		b      = finishedItem("b", shared)
+		w      = &fakeTarWriter{}
+	)
+
+	writeItem(arktest.NewLogger(), w, a)
+	writeItem(arktest.NewLogger(), w, b)
+	writeItem(arktest.NewLogger(), w, shared)
+
+	var names []string
+	for _, header := range w.headers {
+		names = append(names, header.Name)
+	}
+	assert.Equal(t, []string{"shared", "a-1", "a", "b"}, names)
+
+	for i := range w.data {
+		assert.Equal(t, names[i], string(w.data[i]))
+	}
+}
+
+func TestWriteItemSkipsItemsThatWerentBackedUp(t *testing.T) {
+	failed := finishedItem("failed", finishedItem("additional"))
+	failed.header, failed.data = nil, nil
+
+	w := &fakeTarWriter{}
+	writeItem(arktest.NewLogger(), w, failed)
+
+	require.Len(t, w.headers, 1)
+	assert.Equal(t, "additional", w.headers[0].Name)
+}
+
+func TestWriteItemLogsErrors(t *testing.T) {
+	tests := []struct {
+		name             string
+		writeHeaderError error
+		writeError       error
+	}{
+		{
+			name:             "tar header write error",
+			writeHeaderError: errors.New("error"),
+		},
+		{
+			name:       "tar write error",
+			writeError: errors.New("error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := &fakeTarWriter{
+				writeHeaderError: test.writeHeaderError,
+				writeError:       test.writeError,
+			}
+			logger, hook := testlogger.NewNullLogger()
+
+			writeItem(logger, w, finishedItem("pod"))
+
+			require.Len(t, hook.Entries, 1)
+			assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+			assert.Equal(t, "Error backing up item", hook.LastEntry().Message)
+			assert.Equal(t, "pod", hook.LastEntry().Data["name"])
+			assert.EqualError(t, hook.LastEntry().Data[logrus.ErrorKey].(error), "error")
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"compress/gzip"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -28,12 +29,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	kuberrs "k8s.io/apimachinery/pkg/util/errors"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/metrics"
+	"github.com/heptio/ark/pkg/podexec"
+	"github.com/heptio/ark/pkg/podvolume"
 	"github.com/heptio/ark/pkg/util/collections"
 	kubeutil "github.com/heptio/ark/pkg/util/kube"
 	"github.com/heptio/ark/pkg/util/logging"
@@ -42,17 +45,25 @@ import (
 // Backupper performs backups.
 type Backupper interface {
 	// Backup takes a backup using the specification in the api.Backup and writes backup and log data
-	// to the given writers.
-	Backup(backup *api.Backup, backupFile, logFile io.Writer, actions []ItemAction) error
+	// to the given writers. If progressReporter is non-nil, it's notified periodically of the
+	// backup's progress. Problems backing up individual items don't stop the backup; they're
+	// returned as warnings and errors. The index of the items in the backup is also returned. A
+	// non-nil error means the backup as a whole failed.
+	Backup(backup *api.Backup, backupFile, logFile io.Writer, actions []ItemAction, progressReporter ProgressReporter) (warnings, errors api.BackupResult, contents *api.BackupContentsIndex, err error)
 }
 
 // kubernetesBackupper implements Backupper.
 type kubernetesBackupper struct {
 	dynamicFactory        client.DynamicFactory
 	discoveryHelper       discovery.Helper
-	podCommandExecutor    podCommandExecutor
+	podCommandExecutor    podexec.PodCommandExecutor
 	groupBackupperFactory groupBackupperFactory
 	snapshotService       cloudprovider.SnapshotService
+	podVolumeBackupper    podvolume.Backupper
+	metrics               *metrics.ServerMetrics
+	itemWorkers           int
+
+	progressReportInterval time.Duration
 }
 
 type itemKey struct {
@@ -79,12 +90,16 @@ func (i *itemKey) String() string {
 	return fmt.Sprintf("resource=%s,namespace=%s,name=%s", i.resource, i.namespace, i.name)
 }
 
-// NewKubernetesBackupper creates a new kubernetesBackupper.
+// NewKubernetesBackupper creates a new kubernetesBackupper. Up to itemWorkers items of each
+// resource are listed or backed up at once.
 func NewKubernetesBackupper(
 	discoveryHelper discovery.Helper,
 	dynamicFactory client.DynamicFactory,
-	podCommandExecutor podCommandExecutor,
+	podCommandExecutor podexec.PodCommandExecutor,
 	snapshotService cloudprovider.SnapshotService,
+	podVolumeBackupper podvolume.Backupper,
+	metrics *metrics.ServerMetrics,
+	itemWorkers int,
 ) (Backupper, error) {
 	return &kubernetesBackupper{
 		discoveryHelper:       discoveryHelper,
@@ -92,6 +107,11 @@ func NewKubernetesBackupper(
 		podCommandExecutor:    podCommandExecutor,
 		groupBackupperFactory: &defaultGroupBackupperFactory{},
 		snapshotService:       snapshotService,
+		podVolumeBackupper:    podVolumeBackupper,
+		metrics:               metrics,
+		itemWorkers:           itemWorkers,
+
+		progressReportInterval: defaultProgressReportInterval,
 	}, nil
 }
 
@@ -162,7 +182,9 @@ func getResourceHooks(hookSpecs []api.BackupResourceHookSpec, discoveryHelper di
 			name:       r.Name,
 			namespaces: collections.NewIncludesExcludes().Includes(r.IncludedNamespaces...).Excludes(r.ExcludedNamespaces...),
 			resources:  getResourceIncludesExcludes(discoveryHelper, r.IncludedResources, r.ExcludedResources),
-			hooks:      r.Hooks,
+			// Hooks is deprecated in favor of PreHooks; both are run before the item is backed up.
+			pre:  append(append([]api.BackupResourceHook{}, r.Hooks...), r.PreHooks...),
+			post: r.PostHooks,
 		}
 
 		if r.LabelSelector != nil {
@@ -180,8 +202,9 @@ func getResourceHooks(hookSpecs []api.BackupResourceHookSpec, discoveryHelper di
 }
 
 // Backup backs up the items specified in the Backup, placing them in a gzip-compressed tar file
-// written to backupFile. The finalized api.Backup is written to metadata.
-func (kb *kubernetesBackupper) Backup(backup *api.Backup, backupFile, logFile io.Writer, actions []ItemAction) error {
+// written to backupFile, and returns an index of the items in it. The backup's progress, and the
+// number of warnings and errors logged, are recorded in its status.
+func (kb *kubernetesBackupper) Backup(backup *api.Backup, backupFile, logFile io.Writer, actions []ItemAction, progressReporter ProgressReporter) (api.BackupResult, api.BackupResult, *api.BackupContentsIndex, error) {
 	gzippedData := gzip.NewWriter(backupFile)
 	defer gzippedData.Close()
 
@@ -191,10 +214,15 @@ func (kb *kubernetesBackupper) Backup(backup *api.Backup, backupFile, logFile io
 	gzippedLog := gzip.NewWriter(logFile)
 	defer gzippedLog.Close()
 
+	logCounter := logging.NewLogCounterHook()
+	results := &resultsHook{}
+
 	logger := logrus.New()
 	logger.Out = gzippedLog
 	logger.Hooks.Add(&logging.ErrorLocationHook{})
 	logger.Hooks.Add(&logging.LogLocationHook{})
+	logger.Hooks.Add(logCounter)
+	logger.Hooks.Add(results)
 	log := logger.WithField("backup", kubeutil.NamespaceAndName(backup))
 	log.Info("Starting backup")
 
@@ -208,7 +236,7 @@ func (kb *kubernetesBackupper) Backup(backup *api.Backup, backupFile, logFile io
 
 	resourceHooks, err := getResourceHooks(backup.Spec.Hooks.Resources, kb.discoveryHelper)
 	if err != nil {
-		return err
+		return api.BackupResult{}, api.BackupResult{}, nil, err
 	}
 
 	var labelSelector string
@@ -216,8 +244,7 @@ func (kb *kubernetesBackupper) Backup(backup *api.Backup, backupFile, logFile io
 		labelSelector = metav1.FormatLabelSelector(backup.Spec.LabelSelector)
 	}
 
-	backedUpItems := make(map[itemKey]struct{})
-	var errs []error
+	backedUpItems := newBackedUpItemSet()
 
 	cohabitatingResources := map[string]*cohabitatingResource{
 		"deployments":     newCohabitatingResource("deployments", "extensions", "apps"),
@@ -226,7 +253,31 @@ func (kb *kubernetesBackupper) Backup(backup *api.Backup, backupFile, logFile io
 
 	resolvedActions, err := resolveActions(actions, kb.discoveryHelper)
 	if err != nil {
-		return err
+		return api.BackupResult{}, api.BackupResult{}, nil, err
+	}
+
+	// The item hook handlers record hook results here; it's cleared below if no hooks ran.
+	backup.Status.HookStatus = &api.HookStatus{}
+
+	snapshotService := kb.snapshotService
+	if snapshotService != nil && kb.metrics != nil {
+		snapshotService = &instrumentedSnapshotService{
+			SnapshotService: kb.snapshotService,
+			metrics:         kb.metrics,
+			schedule:        backup.Labels[api.ScheduleNameLabel],
+		}
+	}
+
+	contents := newContentsTarWriter(tw)
+
+	progress := &progressTracker{}
+	if progressReporter != nil {
+		stopReporting := make(chan struct{})
+		reportingDone := reportProgress(progressReporter, progress, logCounter, kb.progressReportInterval, stopReporting)
+		defer func() {
+			close(stopReporting)
+			<-reportingDone
+		}()
 	}
 
 	gb := kb.groupBackupperFactory.newGroupBackupper(
@@ -241,25 +292,43 @@ func (kb *kubernetesBackupper) Backup(backup *api.Backup, backupFile, logFile io
 		cohabitatingResources,
 		resolvedActions,
 		kb.podCommandExecutor,
-		tw,
+		&progressTarWriter{tarWriter: contents, progress: progress},
 		resourceHooks,
-		kb.snapshotService,
+		snapshotService,
+		kb.podVolumeBackupper,
+		progress,
+		kb.itemWorkers,
 	)
 
 	for _, group := range kb.discoveryHelper.Resources() {
 		if err := gb.backupGroup(group); err != nil {
-			errs = append(errs, err)
+			log.WithError(err).Errorf("Error backing up group %s", group.GroupVersion)
 		}
 	}
 
-	err = kuberrs.NewAggregate(errs)
-	if err == nil {
+	if backup.Status.HookStatus.HooksAttempted == 0 {
+		backup.Status.HookStatus = nil
+	} else {
+		log.Infof("Hooks attempted: %d, hooks failed: %d", backup.Status.HookStatus.HooksAttempted, backup.Status.HookStatus.HooksFailed)
+	}
+
+	// now that the backup's done, everything that's going to be backed up has been, so
+	// items that were listed but skipped aren't left in the total
+	backup.Status.Progress = &api.BackupProgress{
+		TotalItems:    progress.get().ItemsBackedUp,
+		ItemsBackedUp: progress.get().ItemsBackedUp,
+	}
+	backup.Status.Warnings = logCounter.GetCount(logrus.WarnLevel)
+	backup.Status.Errors = logCounter.GetCount(logrus.ErrorLevel)
+
+	if backup.Status.Errors == 0 {
 		log.Infof("Backup completed successfully")
 	} else {
-		log.Infof("Backup completed with errors: %v", err)
+		log.Infof("Backup completed with %d errors", backup.Status.Errors)
 	}
 
-	return err
+	warnings, errs := results.results()
+	return warnings, errs, contents.index, nil
 }
 
 type tarWriter interface {
@@ -43,6 +43,8 @@ import (
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/podexec"
+	"github.com/heptio/ark/pkg/podvolume"
 	"github.com/heptio/ark/pkg/util/collections"
 	kubeutil "github.com/heptio/ark/pkg/util/kube"
 	arktest "github.com/heptio/ark/pkg/util/test"
@@ -379,7 +381,7 @@ func TestBackup(t *testing.T) {
 		expectedLabelSelector string
 		expectedHooks         []resourceHook
 		backupGroupErrors     map[*metav1.APIResourceList]error
-		expectedError         error
+		expectedErrors        v1.BackupResult
 	}{
 		{
 			name: "happy path, no actions, no label selector, no hooks, no errors",
@@ -432,7 +434,12 @@ func TestBackup(t *testing.T) {
 				certificatesGroup: nil,
 				rbacGroup:         errors.New("rbac error"),
 			},
-			expectedError: errors.New("[v1 error, rbac error]"),
+			expectedErrors: v1.BackupResult{
+				Ark: []string{
+					"Error backing up group v1: v1 error",
+					"Error backing up group rbac.authorization.k8s.io/v1beta1: rbac error",
+				},
+			},
 		},
 		{
 			name: "hooks",
@@ -456,6 +463,20 @@ func TestBackup(t *testing.T) {
 										},
 									},
 								},
+								PreHooks: []v1.BackupResourceHook{
+									{
+										Exec: &v1.ExecHook{
+											Command: []string{"fsfreeze", "--freeze", "/data"},
+										},
+									},
+								},
+								PostHooks: []v1.BackupResourceHook{
+									{
+										Exec: &v1.ExecHook{
+											Command: []string{"fsfreeze", "--unfreeze", "/data"},
+										},
+									},
+								},
 							},
 						},
 					},
@@ -469,12 +490,24 @@ func TestBackup(t *testing.T) {
 					namespaces:    collections.NewIncludesExcludes().Includes("a").Excludes("b"),
 					resources:     collections.NewIncludesExcludes().Includes("configmaps").Excludes("roles.rbac.authorization.k8s.io"),
 					labelSelector: parseLabelSelectorOrDie("1=2"),
-					hooks: []v1.BackupResourceHook{
+					pre: []v1.BackupResourceHook{
 						{
 							Exec: &v1.ExecHook{
 								Command: []string{"ls", "/tmp"},
 							},
 						},
+						{
+							Exec: &v1.ExecHook{
+								Command: []string{"fsfreeze", "--freeze", "/data"},
+							},
+						},
+					},
+					post: []v1.BackupResourceHook{
+						{
+							Exec: &v1.ExecHook{
+								Command: []string{"fsfreeze", "--unfreeze", "/data"},
+							},
+						},
 					},
 				},
 			},
@@ -505,7 +538,7 @@ func TestBackup(t *testing.T) {
 
 			dynamicFactory := &arktest.FakeDynamicFactory{}
 
-			podCommandExecutor := &mockPodCommandExecutor{}
+			podCommandExecutor := &arktest.MockPodCommandExecutor{}
 			defer podCommandExecutor.AssertExpectations(t)
 
 			b, err := NewKubernetesBackupper(
@@ -513,6 +546,9 @@ func TestBackup(t *testing.T) {
 				dynamicFactory,
 				podCommandExecutor,
 				nil,
+				nil,
+				nil,
+				1,
 			)
 			require.NoError(t, err)
 			kb := b.(*kubernetesBackupper)
@@ -537,13 +573,16 @@ func TestBackup(t *testing.T) {
 				test.expectedLabelSelector,
 				dynamicFactory,
 				discoveryHelper,
-				map[itemKey]struct{}{}, // backedUpItems
+				newBackedUpItemSet(), // backedUpItems
 				cohabitatingResources,
 				mock.Anything,
 				kb.podCommandExecutor,
 				mock.Anything, // tarWriter
 				test.expectedHooks,
 				mock.Anything,
+				mock.Anything,
+				mock.Anything, // progress
+				1,             // itemWorkers
 			).Return(groupBackupper)
 
 			for group, err := range test.backupGroupErrors {
@@ -552,7 +591,7 @@ func TestBackup(t *testing.T) {
 
 			var backupFile, logFile bytes.Buffer
 
-			err = b.Backup(test.backup, &backupFile, &logFile, nil)
+			warnings, errs, contents, err := b.Backup(test.backup, &backupFile, &logFile, nil, nil)
 			defer func() {
 				// print log if anything failed
 				if t.Failed() {
@@ -567,11 +606,11 @@ func TestBackup(t *testing.T) {
 				}
 			}()
 
-			if test.expectedError != nil {
-				assert.EqualError(t, err, test.expectedError.Error())
-				return
-			}
-			assert.NoError(t, err)
+			require.NoError(t, err)
+			assert.Equal(t, &v1.BackupContentsIndex{Items: []v1.BackupContentsIndexItem{}}, contents)
+			assert.Equal(t, v1.BackupResult{}, warnings)
+			assert.Equal(t, test.expectedErrors, errs)
+			assert.Equal(t, len(test.expectedErrors.Ark), test.backup.Status.Errors)
 		})
 	}
 }
@@ -587,13 +626,16 @@ func (f *mockGroupBackupperFactory) newGroupBackupper(
 	labelSelector string,
 	dynamicFactory client.DynamicFactory,
 	discoveryHelper discovery.Helper,
-	backedUpItems map[itemKey]struct{},
+	backedUpItems *backedUpItemSet,
 	cohabitatingResources map[string]*cohabitatingResource,
 	actions []resolvedAction,
-	podCommandExecutor podCommandExecutor,
+	podCommandExecutor podexec.PodCommandExecutor,
 	tarWriter tarWriter,
 	resourceHooks []resourceHook,
 	snapshotService cloudprovider.SnapshotService,
+	podVolumeBackupper podvolume.Backupper,
+	progress *progressTracker,
+	itemWorkers int,
 ) groupBackupper {
 	args := f.Called(
 		log,
@@ -610,6 +652,9 @@ func (f *mockGroupBackupperFactory) newGroupBackupper(
 		tarWriter,
 		resourceHooks,
 		snapshotService,
+		podVolumeBackupper,
+		progress,
+		itemWorkers,
 	)
 	return args.Get(0).(groupBackupper)
 }
@@ -0,0 +1,105 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"path"
+	"strings"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// contentsTarWriter is a tarWriter that adds each item written to the backup's tarball to the
+// backup's contents index.
+type contentsTarWriter struct {
+	tarWriter
+	index *api.BackupContentsIndex
+
+	// pending is the item whose header was just written; it's added to the index, along with
+	// the API version from its data, when the data's written.
+	pending *api.BackupContentsIndexItem
+}
+
+func newContentsTarWriter(w tarWriter) *contentsTarWriter {
+	return &contentsTarWriter{
+		tarWriter: w,
+		index:     &api.BackupContentsIndex{Items: []api.BackupContentsIndexItem{}},
+	}
+}
+
+func (w *contentsTarWriter) WriteHeader(hdr *tar.Header) error {
+	w.pending = nil
+
+	if err := w.tarWriter.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	if hdr.Typeflag == tar.TypeReg {
+		w.pending = parseItemPath(hdr.Name)
+	}
+
+	return nil
+}
+
+func (w *contentsTarWriter) Write(data []byte) (int, error) {
+	n, err := w.tarWriter.Write(data)
+	if err != nil || w.pending == nil {
+		return n, err
+	}
+
+	// the item has already been written, so an item that can't be decoded is still listed,
+	// just without its API version
+	var typeMeta struct {
+		APIVersion string `json:"apiVersion"`
+	}
+	if err := json.Unmarshal(data, &typeMeta); err == nil {
+		w.pending.APIVersion = typeMeta.APIVersion
+	}
+
+	w.index.Items = append(w.index.Items, *w.pending)
+	w.pending = nil
+
+	return n, nil
+}
+
+// parseItemPath returns the index item for the file in a backup's tarball at filePath, which is
+// resources/<resource>/namespaces/<namespace>/<name>.json for namespaced items, or
+// resources/<resource>/cluster/<name>.json for cluster-scoped items. It returns nil if filePath
+// isn't an item.
+func parseItemPath(filePath string) *api.BackupContentsIndexItem {
+	parts := strings.Split(filePath, "/")
+	if len(parts) < 4 || parts[0] != api.ResourcesDir || path.Ext(filePath) != ".json" {
+		return nil
+	}
+
+	item := &api.BackupContentsIndexItem{
+		Resource: parts[1],
+		Name:     strings.TrimSuffix(parts[len(parts)-1], ".json"),
+	}
+
+	switch {
+	case len(parts) == 4 && parts[2] == api.ClusterScopedDir:
+	case len(parts) == 5 && parts[2] == api.NamespaceScopedDir:
+		item.Namespace = parts[3]
+	default:
+		return nil
+	}
+
+	return item
+}
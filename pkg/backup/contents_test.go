@@ -0,0 +1,62 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+func TestContentsTarWriter(t *testing.T) {
+	fakeWriter := &fakeTarWriter{}
+	w := newContentsTarWriter(fakeWriter)
+
+	write := func(name string, data string) error {
+		if err := w.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg}); err != nil {
+			return err
+		}
+		_, err := w.Write([]byte(data))
+		return err
+	}
+
+	require.NoError(t, w.WriteHeader(&tar.Header{Name: "resources/pods", Typeflag: tar.TypeDir}))
+	require.NoError(t, write("resources/pods/namespaces/ns-1/pod-1.json", `{"apiVersion":"v1","kind":"Pod"}`))
+	require.NoError(t, write("resources/deployments.apps/namespaces/ns-1/deploy-1.json", `{"apiVersion":"apps/v1beta1"}`))
+	require.NoError(t, write("resources/persistentvolumes/cluster/pv-1.json", `{"apiVersion":"v1"}`))
+	// items that can't be decoded are listed without an API version
+	require.NoError(t, write("resources/configmaps/namespaces/ns-2/cm-1.json", `not json`))
+	// files that aren't items aren't listed
+	require.NoError(t, write("metadata/version", "1"))
+
+	// failed writes aren't listed
+	fakeWriter.writeError = errors.New("write error")
+	assert.Error(t, write("resources/pods/namespaces/ns-1/pod-2.json", `{"apiVersion":"v1"}`))
+
+	expected := []v1.BackupContentsIndexItem{
+		{Resource: "pods", Namespace: "ns-1", Name: "pod-1", APIVersion: "v1"},
+		{Resource: "deployments.apps", Namespace: "ns-1", Name: "deploy-1", APIVersion: "apps/v1beta1"},
+		{Resource: "persistentvolumes", Name: "pv-1", APIVersion: "v1"},
+		{Resource: "configmaps", Namespace: "ns-2", Name: "cm-1"},
+	}
+	assert.Equal(t, expected, w.index.Items)
+}
@@ -28,6 +28,8 @@ import (
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/podexec"
+	"github.com/heptio/ark/pkg/podvolume"
 	"github.com/heptio/ark/pkg/util/collections"
 )
 
@@ -39,13 +41,16 @@ type groupBackupperFactory interface {
 		labelSelector string,
 		dynamicFactory client.DynamicFactory,
 		discoveryHelper discovery.Helper,
-		backedUpItems map[itemKey]struct{},
+		backedUpItems *backedUpItemSet,
 		cohabitatingResources map[string]*cohabitatingResource,
 		actions []resolvedAction,
-		podCommandExecutor podCommandExecutor,
+		podCommandExecutor podexec.PodCommandExecutor,
 		tarWriter tarWriter,
 		resourceHooks []resourceHook,
 		snapshotService cloudprovider.SnapshotService,
+		podVolumeBackupper podvolume.Backupper,
+		progress *progressTracker,
+		itemWorkers int,
 	) groupBackupper
 }
 
@@ -58,13 +63,16 @@ func (f *defaultGroupBackupperFactory) newGroupBackupper(
 	labelSelector string,
 	dynamicFactory client.DynamicFactory,
 	discoveryHelper discovery.Helper,
-	backedUpItems map[itemKey]struct{},
+	backedUpItems *backedUpItemSet,
 	cohabitatingResources map[string]*cohabitatingResource,
 	actions []resolvedAction,
-	podCommandExecutor podCommandExecutor,
+	podCommandExecutor podexec.PodCommandExecutor,
 	tarWriter tarWriter,
 	resourceHooks []resourceHook,
 	snapshotService cloudprovider.SnapshotService,
+	podVolumeBackupper podvolume.Backupper,
+	progress *progressTracker,
+	itemWorkers int,
 ) groupBackupper {
 	return &defaultGroupBackupper{
 		log:                      log,
@@ -81,6 +89,9 @@ func (f *defaultGroupBackupperFactory) newGroupBackupper(
 		tarWriter:                tarWriter,
 		resourceHooks:            resourceHooks,
 		snapshotService:          snapshotService,
+		podVolumeBackupper:       podVolumeBackupper,
+		progress:                 progress,
+		itemWorkers:              itemWorkers,
 		resourceBackupperFactory: &defaultResourceBackupperFactory{},
 	}
 }
@@ -96,13 +107,16 @@ type defaultGroupBackupper struct {
 	labelSelector            string
 	dynamicFactory           client.DynamicFactory
 	discoveryHelper          discovery.Helper
-	backedUpItems            map[itemKey]struct{}
+	backedUpItems            *backedUpItemSet
 	cohabitatingResources    map[string]*cohabitatingResource
 	actions                  []resolvedAction
-	podCommandExecutor       podCommandExecutor
+	podCommandExecutor       podexec.PodCommandExecutor
 	tarWriter                tarWriter
 	resourceHooks            []resourceHook
 	snapshotService          cloudprovider.SnapshotService
+	podVolumeBackupper       podvolume.Backupper
+	progress                 *progressTracker
+	itemWorkers              int
 	resourceBackupperFactory resourceBackupperFactory
 }
 
@@ -127,6 +141,9 @@ func (gb *defaultGroupBackupper) backupGroup(group *metav1.APIResourceList) erro
 			gb.tarWriter,
 			gb.resourceHooks,
 			gb.snapshotService,
+			gb.podVolumeBackupper,
+			gb.progress,
+			gb.itemWorkers,
 		)
 	)
 
@@ -23,6 +23,8 @@ import (
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/podexec"
+	"github.com/heptio/ark/pkg/podvolume"
 	"github.com/heptio/ark/pkg/util/collections"
 	arktest "github.com/heptio/ark/pkg/util/test"
 	"github.com/sirupsen/logrus"
@@ -45,9 +47,8 @@ func TestBackupGroup(t *testing.T) {
 
 	discoveryHelper := arktest.NewFakeDiscoveryHelper(true, nil)
 
-	backedUpItems := map[itemKey]struct{}{
-		{resource: "a", namespace: "b", name: "c"}: {},
-	}
+	backedUpItems := newBackedUpItemSet()
+	backedUpItems.add(itemKey{resource: "a", namespace: "b", name: "c"})
 
 	cohabitatingResources := map[string]*cohabitatingResource{
 		"a": {
@@ -64,7 +65,7 @@ func TestBackupGroup(t *testing.T) {
 		},
 	}
 
-	podCommandExecutor := &mockPodCommandExecutor{}
+	podCommandExecutor := &arktest.MockPodCommandExecutor{}
 	defer podCommandExecutor.AssertExpectations(t)
 
 	tarWriter := &fakeTarWriter{}
@@ -73,6 +74,8 @@ func TestBackupGroup(t *testing.T) {
 		{name: "myhook"},
 	}
 
+	progress := &progressTracker{}
+
 	gb := (&defaultGroupBackupperFactory{}).newGroupBackupper(
 		arktest.NewLogger(),
 		backup,
@@ -88,6 +91,9 @@ func TestBackupGroup(t *testing.T) {
 		tarWriter,
 		resourceHooks,
 		nil,
+		nil,
+		progress,
+		2,
 	).(*defaultGroupBackupper)
 
 	resourceBackupperFactory := &mockResourceBackupperFactory{}
@@ -112,6 +118,9 @@ func TestBackupGroup(t *testing.T) {
 		tarWriter,
 		resourceHooks,
 		nil,
+		nil,
+		progress,
+		2,
 	).Return(resourceBackupper)
 
 	group := &metav1.APIResourceList{
@@ -153,13 +162,16 @@ func (rbf *mockResourceBackupperFactory) newResourceBackupper(
 	labelSelector string,
 	dynamicFactory client.DynamicFactory,
 	discoveryHelper discovery.Helper,
-	backedUpItems map[itemKey]struct{},
+	backedUpItems *backedUpItemSet,
 	cohabitatingResources map[string]*cohabitatingResource,
 	actions []resolvedAction,
-	podCommandExecutor podCommandExecutor,
+	podCommandExecutor podexec.PodCommandExecutor,
 	tarWriter tarWriter,
 	resourceHooks []resourceHook,
 	snapshotService cloudprovider.SnapshotService,
+	podVolumeBackupper podvolume.Backupper,
+	progress *progressTracker,
+	itemWorkers int,
 ) resourceBackupper {
 	args := rbf.Called(
 		log,
@@ -176,6 +188,9 @@ func (rbf *mockResourceBackupperFactory) newResourceBackupper(
 		tarWriter,
 		resourceHooks,
 		snapshotService,
+		podVolumeBackupper,
+		progress,
+		itemWorkers,
 	)
 	return args.Get(0).(resourceBackupper)
 }
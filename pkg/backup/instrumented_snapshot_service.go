@@ -0,0 +1,42 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/metrics"
+)
+
+// instrumentedSnapshotService wraps a SnapshotService and records volume snapshot
+// attempts and failures for the backup's schedule.
+type instrumentedSnapshotService struct {
+	cloudprovider.SnapshotService
+
+	metrics  *metrics.ServerMetrics
+	schedule string
+}
+
+func (s *instrumentedSnapshotService) CreateSnapshot(volumeID, volumeAZ string) (string, error) {
+	s.metrics.RegisterVolumeSnapshotAttempt(s.schedule)
+
+	snapshotID, err := s.SnapshotService.CreateSnapshot(volumeID, volumeAZ)
+	if err != nil {
+		s.metrics.RegisterVolumeSnapshotFailed(s.schedule)
+	}
+
+	return snapshotID, err
+}
@@ -20,21 +20,27 @@ import (
 	"archive/tar"
 	"encoding/json"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	corev1api "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	unstructuredconverter "k8s.io/apimachinery/pkg/conversion/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	kuberrs "k8s.io/apimachinery/pkg/util/errors"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/podexec"
+	"github.com/heptio/ark/pkg/podvolume"
 	"github.com/heptio/ark/pkg/util/collections"
 	kubeutil "github.com/heptio/ark/pkg/util/kube"
 )
@@ -43,14 +49,14 @@ type itemBackupperFactory interface {
 	newItemBackupper(
 		backup *api.Backup,
 		namespaces, resources *collections.IncludesExcludes,
-		backedUpItems map[itemKey]struct{},
+		backedUpItems *backedUpItemSet,
 		actions []resolvedAction,
-		podCommandExecutor podCommandExecutor,
-		tarWriter tarWriter,
+		podCommandExecutor podexec.PodCommandExecutor,
 		resourceHooks []resourceHook,
 		dynamicFactory client.DynamicFactory,
 		discoveryHelper discovery.Helper,
 		snapshotService cloudprovider.SnapshotService,
+		podVolumeBackupper podvolume.Backupper,
 	) ItemBackupper
 }
 
@@ -59,28 +65,30 @@ type defaultItemBackupperFactory struct{}
 func (f *defaultItemBackupperFactory) newItemBackupper(
 	backup *api.Backup,
 	namespaces, resources *collections.IncludesExcludes,
-	backedUpItems map[itemKey]struct{},
+	backedUpItems *backedUpItemSet,
 	actions []resolvedAction,
-	podCommandExecutor podCommandExecutor,
-	tarWriter tarWriter,
+	podCommandExecutor podexec.PodCommandExecutor,
 	resourceHooks []resourceHook,
 	dynamicFactory client.DynamicFactory,
 	discoveryHelper discovery.Helper,
 	snapshotService cloudprovider.SnapshotService,
+	podVolumeBackupper podvolume.Backupper,
 ) ItemBackupper {
 	ib := &defaultItemBackupper{
-		backup:          backup,
-		namespaces:      namespaces,
-		resources:       resources,
-		backedUpItems:   backedUpItems,
-		actions:         actions,
-		tarWriter:       tarWriter,
-		resourceHooks:   resourceHooks,
-		dynamicFactory:  dynamicFactory,
-		discoveryHelper: discoveryHelper,
-		snapshotService: snapshotService,
+		backup:             backup,
+		namespaces:         namespaces,
+		resources:          resources,
+		backedUpItems:      backedUpItems,
+		actions:            actions,
+		actionBackup:       backup.DeepCopy(),
+		resourceHooks:      resourceHooks,
+		dynamicFactory:     dynamicFactory,
+		discoveryHelper:    discoveryHelper,
+		snapshotService:    snapshotService,
+		podVolumeBackupper: podVolumeBackupper,
 		itemHookHandler: &defaultItemHookHandler{
 			podCommandExecutor: podCommandExecutor,
+			hookStatus:         backup.Status.HookStatus,
 		},
 	}
 
@@ -91,34 +99,47 @@ func (f *defaultItemBackupperFactory) newItemBackupper(
 }
 
 type ItemBackupper interface {
-	backupItem(logger logrus.FieldLogger, obj runtime.Unstructured, groupResource schema.GroupResource) error
+	backupItem(logger logrus.FieldLogger, obj runtime.Unstructured, groupResource schema.GroupResource) (*backedUpItem, error)
 }
 
+// defaultItemBackupper is the default ItemBackupper. It's safe for concurrent use.
 type defaultItemBackupper struct {
-	backup          *api.Backup
-	namespaces      *collections.IncludesExcludes
-	resources       *collections.IncludesExcludes
-	backedUpItems   map[itemKey]struct{}
-	actions         []resolvedAction
-	tarWriter       tarWriter
-	resourceHooks   []resourceHook
-	dynamicFactory  client.DynamicFactory
-	discoveryHelper discovery.Helper
-	snapshotService cloudprovider.SnapshotService
+	backup             *api.Backup
+	namespaces         *collections.IncludesExcludes
+	resources          *collections.IncludesExcludes
+	backedUpItems      *backedUpItemSet
+	actions            []resolvedAction
+	resourceHooks      []resourceHook
+	dynamicFactory     client.DynamicFactory
+	discoveryHelper    discovery.Helper
+	snapshotService    cloudprovider.SnapshotService
+	podVolumeBackupper podvolume.Backupper
 
 	itemHookHandler         itemHookHandler
 	additionalItemBackupper ItemBackupper
+
+	// actionBackup is the copy of the backup that's given to actions. The backup's status is
+	// updated as items are backed up, so actions can't be given the backup itself while other
+	// items are being backed up concurrently.
+	actionBackup *api.Backup
+	// actionLock serializes the actions that log to the item's logger.
+	actionLock sync.Mutex
+	// statusLock guards the backup's VolumeBackups.
+	statusLock sync.Mutex
 }
 
 var podsGroupResource = schema.GroupResource{Group: "", Resource: "pods"}
 var namespacesGroupResource = schema.GroupResource{Group: "", Resource: "namespaces"}
 
-// backupItem backs up an individual item to tarWriter. The item may be excluded based on the
-// namespaces IncludesExcludes list.
-func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtime.Unstructured, groupResource schema.GroupResource) error {
+// backupItem backs up an individual item, and any additional items returned for it by
+// ItemActions. It returns the item's entry in the set of backed up items, which holds its
+// tarball entry once it's finished, or nil if it's excluded. If the item had already been
+// backed up, the existing entry is returned. The item may be excluded based on the namespaces
+// IncludesExcludes list.
+func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtime.Unstructured, groupResource schema.GroupResource) (*backedUpItem, error) {
 	metadata, err := meta.Accessor(obj)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	namespace := metadata.GetNamespace()
@@ -133,19 +154,19 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 	// backupItem can be invoked by a custom action.
 	if namespace != "" && !ib.namespaces.ShouldInclude(namespace) {
 		log.Info("Excluding item because namespace is excluded")
-		return nil
+		return nil, nil
 	}
 
 	// NOTE: we specifically allow namespaces to be backed up even if IncludeClusterResources is
 	// false.
 	if namespace == "" && groupResource != namespacesGroupResource && ib.backup.Spec.IncludeClusterResources != nil && !*ib.backup.Spec.IncludeClusterResources {
 		log.Info("Excluding item because resource is cluster-scoped and backup.spec.includeClusterResources is false")
-		return nil
+		return nil, nil
 	}
 
 	if !ib.resources.ShouldInclude(groupResource.String()) {
 		log.Info("Excluding item because resource is excluded")
-		return nil
+		return nil, nil
 	}
 
 	key := itemKey{
@@ -154,21 +175,112 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 		name:      name,
 	}
 
-	if _, exists := ib.backedUpItems[key]; exists {
+	item, added := ib.backedUpItems.add(key)
+	if !added {
 		log.Info("Skipping item because it's already been backed up.")
-		return nil
+		return item, nil
 	}
-	ib.backedUpItems[key] = struct{}{}
+	defer item.finish()
 
 	log.Info("Backing up resource")
 
+	err = ib.backupAddedItem(log, obj, groupResource, metadata, item)
+	return item, err
+}
+
+// backupAddedItem runs the hooks and actions for an item that's been added to the set of
+// backed up items, and records its tarball entry.
+func (ib *defaultItemBackupper) backupAddedItem(log *logrus.Entry, obj runtime.Unstructured, groupResource schema.GroupResource, metadata metav1.Object, item *backedUpItem) error {
+	namespace := metadata.GetNamespace()
+	name := metadata.GetName()
+
+	// the pod's status is needed to back up its volumes, so get it before the status is removed
+	var pod *corev1api.Pod
+	if groupResource == podsGroupResource && ib.podVolumeBackupper != nil && len(podvolume.GetVolumesToBackup(metadata)) > 0 {
+		pod = new(corev1api.Pod)
+		if err := unstructuredconverter.DefaultConverter.FromUnstructured(obj.UnstructuredContent(), pod); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
 	// Never save status
 	delete(obj.UnstructuredContent(), "status")
 
-	if err := ib.itemHookHandler.handleHooks(log, groupResource, obj, ib.resourceHooks); err != nil {
+	log.Debug("Executing pre hooks")
+	if err := ib.itemHookHandler.handleHooks(log, groupResource, obj, ib.resourceHooks, hookPhasePre); err != nil {
 		return err
 	}
 
+	var backupErrs []error
+
+	updatedObj, additional, err := ib.executeActions(log, obj, groupResource, name, namespace, metadata)
+	item.additional = additional
+	if err != nil {
+		backupErrs = append(backupErrs, err)
+
+		// post hooks must always run once the pre hooks have, e.g. to unfreeze a filesystem
+		log.Debug("Executing post hooks")
+		if err := ib.itemHookHandler.handleHooks(log, groupResource, obj, ib.resourceHooks, hookPhasePost); err != nil {
+			backupErrs = append(backupErrs, err)
+		}
+
+		return kuberrs.NewAggregate(backupErrs)
+	}
+	obj = updatedObj
+
+	if groupResource == pvGroupResource {
+		if ib.snapshotService == nil {
+			log.Debug("Skipping Persistent Volume snapshot because they're not enabled.")
+		} else if err := ib.takePVSnapshot(obj, ib.backup, log); err != nil {
+			backupErrs = append(backupErrs, err)
+		}
+	}
+
+	if pod != nil {
+		if err := ib.backupPodVolumes(log, pod, obj); err != nil {
+			backupErrs = append(backupErrs, err)
+		}
+	}
+
+	log.Debug("Executing post hooks")
+	if err := ib.itemHookHandler.handleHooks(log, groupResource, obj, ib.resourceHooks, hookPhasePost); err != nil {
+		backupErrs = append(backupErrs, err)
+	}
+
+	if len(backupErrs) != 0 {
+		return kuberrs.NewAggregate(backupErrs)
+	}
+
+	var filePath string
+	if namespace != "" {
+		filePath = filepath.Join(api.ResourcesDir, groupResource.String(), api.NamespaceScopedDir, namespace, name+".json")
+	} else {
+		filePath = filepath.Join(api.ResourcesDir, groupResource.String(), api.ClusterScopedDir, name+".json")
+	}
+
+	itemBytes, err := json.Marshal(obj.UnstructuredContent())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	item.header = &tar.Header{
+		Name:     filePath,
+		Size:     int64(len(itemBytes)),
+		Typeflag: tar.TypeReg,
+		Mode:     0755,
+		ModTime:  time.Now(),
+	}
+	item.data = itemBytes
+
+	return nil
+}
+
+// executeActions runs all applicable ItemActions for obj, backing up any additional items they
+// return, and returns the (possibly updated) item and the additional items' entries in the set
+// of backed up items.
+func (ib *defaultItemBackupper) executeActions(log logrus.FieldLogger, obj runtime.Unstructured, groupResource schema.GroupResource, name, namespace string, metadata metav1.Object) (runtime.Unstructured, []*backedUpItem, error) {
+	var additional []*backedUpItem
+
 	for _, action := range ib.actions {
 		if !action.resourceIncludesExcludes.ShouldInclude(groupResource.String()) {
 			log.Debug("Skipping action because it does not apply to this resource")
@@ -187,72 +299,73 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 
 		log.Info("Executing custom action")
 
-		if logSetter, ok := action.ItemAction.(LogSetter); ok {
-			logSetter.SetLog(log)
+		updatedItem, additionalItemIdentifiers, err := ib.executeAction(log, action, obj)
+		if err != nil {
+			return nil, additional, errors.Wrap(err, "error executing custom action")
 		}
+		obj = updatedItem
 
-		if updatedItem, additionalItemIdentifiers, err := action.Execute(obj, ib.backup); err == nil {
-			obj = updatedItem
-
-			for _, additionalItem := range additionalItemIdentifiers {
-				gvr, resource, err := ib.discoveryHelper.ResourceFor(additionalItem.GroupResource.WithVersion(""))
-				if err != nil {
-					return err
-				}
-
-				client, err := ib.dynamicFactory.ClientForGroupVersionResource(gvr.GroupVersion(), resource, additionalItem.Namespace)
-				if err != nil {
-					return err
-				}
+		for _, additionalItem := range additionalItemIdentifiers {
+			gvr, resource, err := ib.discoveryHelper.ResourceFor(additionalItem.GroupResource.WithVersion(""))
+			if err != nil {
+				return nil, additional, err
+			}
 
-				additionalItem, err := client.Get(additionalItem.Name, metav1.GetOptions{})
-				if err != nil {
-					return err
-				}
+			client, err := ib.dynamicFactory.ClientForGroupVersionResource(gvr.GroupVersion(), resource, additionalItem.Namespace)
+			if err != nil {
+				return nil, additional, err
+			}
 
-				ib.additionalItemBackupper.backupItem(log, additionalItem, gvr.GroupResource())
+			additionalItem, err := client.Get(additionalItem.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, additional, err
 			}
-		} else {
-			return errors.Wrap(err, "error executing custom action")
-		}
-	}
 
-	if groupResource == pvGroupResource {
-		if ib.snapshotService == nil {
-			log.Debug("Skipping Persistent Volume snapshot because they're not enabled.")
-		} else {
-			if err := ib.takePVSnapshot(obj, ib.backup, log); err != nil {
-				return err
+			if item, _ := ib.additionalItemBackupper.backupItem(log, additionalItem, gvr.GroupResource()); item != nil {
+				additional = append(additional, item)
 			}
 		}
 	}
 
-	var filePath string
-	if namespace != "" {
-		filePath = filepath.Join(api.ResourcesDir, groupResource.String(), api.NamespaceScopedDir, namespace, name+".json")
-	} else {
-		filePath = filepath.Join(api.ResourcesDir, groupResource.String(), api.ClusterScopedDir, name+".json")
+	return obj, additional, nil
+}
+
+// executeAction runs an ItemAction for obj. Actions that log to the item's logger are run one
+// at a time, since the logger is set on the action itself.
+func (ib *defaultItemBackupper) executeAction(log logrus.FieldLogger, action resolvedAction, obj runtime.Unstructured) (runtime.Unstructured, []ResourceIdentifier, error) {
+	logSetter, ok := action.ItemAction.(LogSetter)
+	if !ok {
+		return action.Execute(obj, ib.actionBackup)
 	}
 
-	itemBytes, err := json.Marshal(obj.UnstructuredContent())
-	if err != nil {
-		return errors.WithStack(err)
+	ib.actionLock.Lock()
+	defer ib.actionLock.Unlock()
+
+	logSetter.SetLog(log)
+	return action.Execute(obj, ib.actionBackup)
+}
+
+// backupPodVolumes backs up the contents of the pod's volumes that are listed in its
+// annotation, and records the IDs of the resulting snapshots in the annotations of obj, the
+// pod being backed up, so they can be found at restore time. Volumes that fail to back up are
+// logged as errors, but don't prevent the pod itself from being backed up.
+func (ib *defaultItemBackupper) backupPodVolumes(log logrus.FieldLogger, pod *corev1api.Pod, obj runtime.Unstructured) error {
+	snapshots, errs := ib.podVolumeBackupper.BackupPodVolumes(ib.backup, pod, log)
+	for _, err := range errs {
+		log.WithError(err).Error("Error backing up pod volume")
 	}
 
-	hdr := &tar.Header{
-		Name:     filePath,
-		Size:     int64(len(itemBytes)),
-		Typeflag: tar.TypeReg,
-		Mode:     0755,
-		ModTime:  time.Now(),
+	if len(snapshots) == 0 {
+		return nil
 	}
 
-	if err := ib.tarWriter.WriteHeader(hdr); err != nil {
+	metadata, err := meta.Accessor(obj)
+	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	if _, err := ib.tarWriter.Write(itemBytes); err != nil {
-		return errors.WithStack(err)
+	for volume, snapshotID := range snapshots {
+		podvolume.SetSnapshotAnnotation(metadata, volume, snapshotID)
 	}
 
 	return nil
@@ -288,7 +401,7 @@ func (ib *defaultItemBackupper) takePVSnapshot(pv runtime.Unstructured, backup *
 		log.Infof("label %q is not present on PersistentVolume", zoneLabel)
 	}
 
-	volumeID, err := kubeutil.GetVolumeID(pv.UnstructuredContent())
+	volumeID, err := ib.snapshotService.GetVolumeID(pv)
 	// non-nil error means it's a supported PV source but volume ID can't be found
 	if err != nil {
 		return errors.Wrapf(err, "error getting volume ID for PersistentVolume")
@@ -301,6 +414,17 @@ func (ib *defaultItemBackupper) takePVSnapshot(pv runtime.Unstructured, backup *
 
 	log = log.WithField("volumeID", volumeID)
 
+	// hostPath and local PVs are on a node rather than in a zone, so the node their node
+	// affinity selects is used as their availability zone, which lets the block store check
+	// that it's snapshotting the node's volume
+	node, isHostVolume, err := kubeutil.GetHostVolumeNode(pv.UnstructuredContent())
+	if err != nil {
+		return err
+	}
+	if isHostVolume {
+		pvFailureDomainZone = node
+	}
+
 	log.Info("Snapshotting PersistentVolume")
 	snapshotID, err := ib.snapshotService.CreateSnapshot(volumeID, pvFailureDomainZone)
 	if err != nil {
@@ -315,6 +439,9 @@ func (ib *defaultItemBackupper) takePVSnapshot(pv runtime.Unstructured, backup *
 		return errors.WithMessage(err, "error getting volume info")
 	}
 
+	ib.statusLock.Lock()
+	defer ib.statusLock.Unlock()
+
 	if backup.Status.VolumeBackups == nil {
 		backup.Status.VolumeBackups = make(map[string]*api.VolumeBackupInfo)
 	}
@@ -30,9 +30,11 @@ import (
 	arktest "github.com/heptio/ark/pkg/util/test"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	testlogger "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	corev1api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
@@ -48,7 +50,7 @@ func TestBackupItemSkips(t *testing.T) {
 		namespaces    *collections.IncludesExcludes
 		groupResource schema.GroupResource
 		resources     *collections.IncludesExcludes
-		backedUpItems map[itemKey]struct{}
+		backedUpItems []itemKey
 	}{
 		{
 			testName:   "namespace not in includes list",
@@ -85,23 +87,32 @@ func TestBackupItemSkips(t *testing.T) {
 			groupResource: schema.GroupResource{Group: "foo", Resource: "bar"},
 			namespaces:    collections.NewIncludesExcludes(),
 			resources:     collections.NewIncludesExcludes(),
-			backedUpItems: map[itemKey]struct{}{
-				{resource: "bar.foo", namespace: "ns", name: "foo"}: {},
+			backedUpItems: []itemKey{
+				{resource: "bar.foo", namespace: "ns", name: "foo"},
 			},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.testName, func(t *testing.T) {
+			backedUpItems := newBackedUpItemSet()
+			var existing *backedUpItem
+			for _, key := range test.backedUpItems {
+				existing, _ = backedUpItems.add(key)
+			}
+
 			ib := &defaultItemBackupper{
 				namespaces:    test.namespaces,
 				resources:     test.resources,
-				backedUpItems: test.backedUpItems,
+				backedUpItems: backedUpItems,
 			}
 
 			u := unstructuredOrDie(fmt.Sprintf(`{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"%s","name":"%s"}}`, test.namespace, test.name))
-			err := ib.backupItem(arktest.NewLogger(), u, test.groupResource)
+			item, err := ib.backupItem(arktest.NewLogger(), u, test.groupResource)
 			assert.NoError(t, err)
+			// an item that's already been backed up is returned so it can be referenced
+			// by the caller, but isn't backed up again
+			assert.True(t, item == existing)
 		})
 	}
 }
@@ -119,8 +130,9 @@ func TestBackupItemSkipsClusterScopedResourceWhenIncludeClusterResourcesFalse(t
 	}
 
 	u := unstructuredOrDie(`{"apiVersion":"v1","kind":"Foo","metadata":{"name":"bar"}}`)
-	err := ib.backupItem(arktest.NewLogger(), u, schema.GroupResource{Group: "foo", Resource: "bar"})
+	item, err := ib.backupItem(arktest.NewLogger(), u, schema.GroupResource{Group: "foo", Resource: "bar"})
 	assert.NoError(t, err)
+	assert.Nil(t, item)
 }
 
 func TestBackupItemNoSkips(t *testing.T) {
@@ -131,13 +143,12 @@ func TestBackupItemNoSkips(t *testing.T) {
 		expectError                           bool
 		expectExcluded                        bool
 		expectedTarHeaderName                 string
-		tarWriteError                         bool
-		tarHeaderWriteError                   bool
 		customAction                          bool
 		expectedActionID                      string
 		customActionAdditionalItemIdentifiers []ResourceIdentifier
 		customActionAdditionalItems           []runtime.Unstructured
 		groupResource                         string
+		volumeID                              string
 		snapshottableVolumes                  map[string]api.VolumeBackupInfo
 	}{
 		{
@@ -170,18 +181,6 @@ func TestBackupItemNoSkips(t *testing.T) {
 			expectExcluded:        false,
 			expectedTarHeaderName: "resources/resource.group/cluster/bar.json",
 		},
-		{
-			name:                "tar header write error",
-			item:                `{"metadata":{"name":"bar"},"spec":{"color":"green"},"status":{"foo":"bar"}}`,
-			expectError:         true,
-			tarHeaderWriteError: true,
-		},
-		{
-			name:          "tar write error",
-			item:          `{"metadata":{"name":"bar"},"spec":{"color":"green"},"status":{"foo":"bar"}}`,
-			expectError:   true,
-			tarWriteError: true,
-		},
 		{
 			name: "action invoked - cluster-scoped",
 			namespaceIncludesExcludes: collections.NewIncludesExcludes().Includes("*"),
@@ -245,6 +244,7 @@ func TestBackupItemNoSkips(t *testing.T) {
 			expectExcluded:        false,
 			expectedTarHeaderName: "resources/persistentvolumes/cluster/mypv.json",
 			groupResource:         "persistentvolumes",
+			volumeID:              "vol-abc123",
 			snapshottableVolumes: map[string]api.VolumeBackupInfo{
 				"vol-abc123": {SnapshotID: "snapshot-1", AvailabilityZone: "us-east-1c"},
 			},
@@ -258,7 +258,7 @@ func TestBackupItemNoSkips(t *testing.T) {
 				action        *fakeAction
 				backup        = &v1.Backup{}
 				groupResource = schema.ParseGroupResource("resource.group")
-				backedUpItems = make(map[itemKey]struct{})
+				backedUpItems = newBackedUpItemSet()
 				resources     = collections.NewIncludesExcludes()
 				w             = &fakeTarWriter{}
 			)
@@ -277,13 +277,6 @@ func TestBackupItemNoSkips(t *testing.T) {
 				namespaces = collections.NewIncludesExcludes()
 			}
 
-			if test.tarHeaderWriteError {
-				w.writeHeaderError = errors.New("error")
-			}
-			if test.tarWriteError {
-				w.writeError = errors.New("error")
-			}
-
 			if test.customAction {
 				action = &fakeAction{
 					additionalItems: test.customActionAdditionalItemIdentifiers,
@@ -300,7 +293,7 @@ func TestBackupItemNoSkips(t *testing.T) {
 
 			resourceHooks := []resourceHook{}
 
-			podCommandExecutor := &mockPodCommandExecutor{}
+			podCommandExecutor := &arktest.MockPodCommandExecutor{}
 			defer podCommandExecutor.AssertExpectations(t)
 
 			dynamicFactory := &arktest.FakeDynamicFactory{}
@@ -315,16 +308,19 @@ func TestBackupItemNoSkips(t *testing.T) {
 				backedUpItems,
 				actions,
 				podCommandExecutor,
-				w,
 				resourceHooks,
 				dynamicFactory,
 				discoveryHelper,
 				nil,
+				nil,
 			).(*defaultItemBackupper)
 
 			var snapshotService *arktest.FakeSnapshotService
 			if test.snapshottableVolumes != nil {
-				snapshotService = &arktest.FakeSnapshotService{SnapshottableVolumes: test.snapshottableVolumes}
+				snapshotService = &arktest.FakeSnapshotService{
+					SnapshottableVolumes: test.snapshottableVolumes,
+					VolumeID:             test.volumeID,
+				}
 				b.snapshotService = snapshotService
 			}
 
@@ -340,7 +336,8 @@ func TestBackupItemNoSkips(t *testing.T) {
 			b.additionalItemBackupper = additionalItemBackupper
 
 			obj := &unstructured.Unstructured{Object: item}
-			itemHookHandler.On("handleHooks", mock.Anything, groupResource, obj, resourceHooks).Return(nil)
+			itemHookHandler.On("handleHooks", mock.Anything, groupResource, obj, resourceHooks, hookPhasePre).Return(nil)
+			itemHookHandler.On("handleHooks", mock.Anything, groupResource, obj, resourceHooks, hookPhasePost).Return(nil)
 
 			for i, item := range test.customActionAdditionalItemIdentifiers {
 				itemClient := &arktest.FakeDynamicClient{}
@@ -350,10 +347,10 @@ func TestBackupItemNoSkips(t *testing.T) {
 
 				itemClient.On("Get", item.Name, metav1.GetOptions{}).Return(test.customActionAdditionalItems[i], nil)
 
-				additionalItemBackupper.On("backupItem", mock.AnythingOfType("*logrus.Entry"), test.customActionAdditionalItems[i], item.GroupResource).Return(nil)
+				additionalItemBackupper.On("backupItem", mock.AnythingOfType("*logrus.Entry"), test.customActionAdditionalItems[i], item.GroupResource).Return(nil, nil)
 			}
 
-			err = b.backupItem(arktest.NewLogger(), obj, groupResource)
+			backedUp, err := b.backupItem(arktest.NewLogger(), obj, groupResource)
 			gotError := err != nil
 			if e, a := test.expectError, gotError; e != a {
 				t.Fatalf("error: expected %t, got %t", e, a)
@@ -362,6 +359,8 @@ func TestBackupItemNoSkips(t *testing.T) {
 				return
 			}
 
+			writeItem(arktest.NewLogger(), w, backedUp)
+
 			if test.expectExcluded {
 				if len(w.headers) > 0 {
 					t.Errorf("unexpected header write")
@@ -426,6 +425,106 @@ func TestBackupItemNoSkips(t *testing.T) {
 	}
 }
 
+type erroringAction struct{}
+
+func (a *erroringAction) AppliesTo() (ResourceSelector, error) {
+	return ResourceSelector{}, nil
+}
+
+func (a *erroringAction) Execute(item runtime.Unstructured, backup *v1.Backup) (runtime.Unstructured, []ResourceIdentifier, error) {
+	return nil, nil, errors.New("action failed")
+}
+
+func TestBackupItemRunsPostHooksWhenActionFails(t *testing.T) {
+	groupResource := schema.ParseGroupResource("pods")
+	resourceHooks := []resourceHook{{name: "hook"}}
+
+	ib := &defaultItemBackupper{
+		backup:        &v1.Backup{},
+		namespaces:    collections.NewIncludesExcludes(),
+		resources:     collections.NewIncludesExcludes(),
+		backedUpItems: newBackedUpItemSet(),
+		actions: []resolvedAction{
+			{
+				ItemAction:                &erroringAction{},
+				namespaceIncludesExcludes: collections.NewIncludesExcludes(),
+				resourceIncludesExcludes:  collections.NewIncludesExcludes(),
+				selector:                  labels.Everything(),
+			},
+		},
+		resourceHooks: resourceHooks,
+	}
+
+	itemHookHandler := &mockItemHookHandler{}
+	defer itemHookHandler.AssertExpectations(t)
+	ib.itemHookHandler = itemHookHandler
+
+	obj := unstructuredOrDie(`{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"ns","name":"pod"}}`)
+	itemHookHandler.On("handleHooks", mock.Anything, groupResource, obj, resourceHooks, hookPhasePre).Return(nil)
+	itemHookHandler.On("handleHooks", mock.Anything, groupResource, obj, resourceHooks, hookPhasePost).Return(errors.New("post hook failed"))
+
+	item, err := ib.backupItem(arktest.NewLogger(), obj, groupResource)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "action failed")
+	assert.Contains(t, err.Error(), "post hook failed")
+	require.NotNil(t, item)
+	assert.Nil(t, item.header)
+}
+
+type fakePodVolumeBackupper struct {
+	snapshots map[string]string
+	errs      []error
+	pod       *corev1api.Pod
+}
+
+func (b *fakePodVolumeBackupper) BackupPodVolumes(backup *v1.Backup, pod *corev1api.Pod, log logrus.FieldLogger) (map[string]string, []error) {
+	b.pod = pod
+	return b.snapshots, b.errs
+}
+
+func TestBackupItemBacksUpPodVolumes(t *testing.T) {
+	groupResource := schema.ParseGroupResource("pods")
+
+	w := &fakeTarWriter{}
+	podVolumeBackupper := &fakePodVolumeBackupper{
+		snapshots: map[string]string{"vol-1": "snapshot-1"},
+		errs:      []error{errors.New("backup of volume vol-2 failed")},
+	}
+	ib := &defaultItemBackupper{
+		backup:             &v1.Backup{},
+		namespaces:         collections.NewIncludesExcludes(),
+		resources:          collections.NewIncludesExcludes(),
+		backedUpItems:      newBackedUpItemSet(),
+		podVolumeBackupper: podVolumeBackupper,
+	}
+
+	itemHookHandler := &mockItemHookHandler{}
+	defer itemHookHandler.AssertExpectations(t)
+	ib.itemHookHandler = itemHookHandler
+	itemHookHandler.On("handleHooks", mock.Anything, groupResource, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	obj := unstructuredOrDie(`{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"ns","name":"pod","annotations":{"backup.ark.heptio.com/backup-volumes":"vol-1,vol-2"}},"status":{"phase":"Running"}}`)
+
+	logger, hook := testlogger.NewNullLogger()
+	item, err := ib.backupItem(logger, obj, groupResource)
+	require.NoError(t, err)
+	writeItem(logger, w, item)
+
+	// the pod volume backupper sees the pod's status, but it's not saved
+	require.NotNil(t, podVolumeBackupper.pod)
+	assert.Equal(t, corev1api.PodRunning, podVolumeBackupper.pod.Status.Phase)
+
+	// failed volumes are logged as errors, but the pod is still backed up, annotated with its snapshots
+	assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+	assert.Equal(t, "backup of volume vol-2 failed", hook.LastEntry().Data[logrus.ErrorKey].(error).Error())
+
+	require.Len(t, w.data, 1)
+	var saved unstructured.Unstructured
+	require.NoError(t, json.Unmarshal(w.data[0], &saved.Object))
+	assert.Equal(t, "snapshot-1", saved.GetAnnotations()["snapshot.ark.heptio.com/vol-1"])
+	assert.Nil(t, saved.Object["status"])
+}
+
 func TestTakePVSnapshot(t *testing.T) {
 	iops := int64(1000)
 
@@ -439,6 +538,7 @@ func TestTakePVSnapshot(t *testing.T) {
 		expectedSnapshotsTaken int
 		existingVolumeBackups  map[string]*v1.VolumeBackupInfo
 		volumeInfo             map[string]v1.VolumeBackupInfo
+		volumeIDErr            error
 	}{
 		{
 			name:            "snapshot disabled",
@@ -446,9 +546,10 @@ func TestTakePVSnapshot(t *testing.T) {
 			snapshotEnabled: false,
 		},
 		{
-			name:            "can't find volume id - missing spec",
+			name:            "can't find volume id",
 			snapshotEnabled: true,
-			pv:              `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "mypv"}}`,
+			pv:              `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "mypv"}, "spec": {"awsElasticBlockStore": {}}}`,
+			volumeIDErr:     errors.New("awsElasticBlockStore.volumeID not found"),
 			expectError:     true,
 		},
 		{
@@ -457,18 +558,6 @@ func TestTakePVSnapshot(t *testing.T) {
 			pv:              `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "mypv"}, "spec": {"unsupportedPVSource": {}}}`,
 			expectError:     false,
 		},
-		{
-			name:            "can't find volume id - aws but no volume id",
-			snapshotEnabled: true,
-			pv:              `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "mypv"}, "spec": {"awsElasticBlockStore": {}}}`,
-			expectError:     true,
-		},
-		{
-			name:            "can't find volume id - gce but no volume id",
-			snapshotEnabled: true,
-			pv:              `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "mypv"}, "spec": {"gcePersistentDisk": {}}}`,
-			expectError:     true,
-		},
 		{
 			name:                   "aws - simple volume id",
 			snapshotEnabled:        true,
@@ -545,10 +634,35 @@ func TestTakePVSnapshot(t *testing.T) {
 			},
 		},
 		{
-			name:            "create snapshot error",
-			snapshotEnabled: true,
-			pv:              `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "mypv"}, "spec": {"gcePersistentDisk": {"pdName": "pd-abc123"}}}`,
-			expectError:     true,
+			name:             "create snapshot error",
+			snapshotEnabled:  true,
+			pv:               `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "mypv"}, "spec": {"gcePersistentDisk": {"pdName": "pd-abc123"}}}`,
+			expectedVolumeID: "pd-abc123",
+			expectError:      true,
+		},
+		{
+			name:                   "local volume - node from node affinity is used as availability zone",
+			snapshotEnabled:        true,
+			pv:                     `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "mypv"}, "spec": {"local": {"path": "/mnt/disks/ssd1"}, "nodeAffinity": {"required": {"nodeSelectorTerms": [{"matchExpressions": [{"key": "kubernetes.io/hostname", "operator": "In", "values": ["node-1"]}]}]}}}}`,
+			expectError:            false,
+			expectedSnapshotsTaken: 1,
+			expectedVolumeID:       "/mnt/disks/ssd1",
+			ttl:                    5 * time.Minute,
+			volumeInfo: map[string]v1.VolumeBackupInfo{
+				"/mnt/disks/ssd1": {SnapshotID: "snap-1", AvailabilityZone: "node-1"},
+			},
+		},
+		{
+			name:                   "local volume - node from node affinity annotation is used as availability zone",
+			snapshotEnabled:        true,
+			pv:                     `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "mypv", "annotations": {"volume.alpha.kubernetes.io/node-affinity": "{\"requiredDuringSchedulingIgnoredDuringExecution\": {\"nodeSelectorTerms\": [{\"matchExpressions\": [{\"key\": \"kubernetes.io/hostname\", \"operator\": \"In\", \"values\": [\"node-1\"]}]}]}}"}}, "spec": {"local": {"path": "/mnt/disks/ssd1"}}}`,
+			expectError:            false,
+			expectedSnapshotsTaken: 1,
+			expectedVolumeID:       "/mnt/disks/ssd1",
+			ttl:                    5 * time.Minute,
+			volumeInfo: map[string]v1.VolumeBackupInfo{
+				"/mnt/disks/ssd1": {SnapshotID: "snap-1", AvailabilityZone: "node-1"},
+			},
 		},
 		{
 			name:                   "PV with label metadata but no failureDomainZone",
@@ -580,7 +694,11 @@ func TestTakePVSnapshot(t *testing.T) {
 				},
 			}
 
-			snapshotService := &arktest.FakeSnapshotService{SnapshottableVolumes: test.volumeInfo}
+			snapshotService := &arktest.FakeSnapshotService{
+				SnapshottableVolumes: test.volumeInfo,
+				VolumeID:             test.expectedVolumeID,
+				VolumeIDErr:          test.volumeIDErr,
+			}
 
 			ib := &defaultItemBackupper{snapshotService: snapshotService}
 
@@ -658,7 +776,8 @@ type mockItemBackupper struct {
 	mock.Mock
 }
 
-func (ib *mockItemBackupper) backupItem(logger logrus.FieldLogger, obj runtime.Unstructured, groupResource schema.GroupResource) error {
+func (ib *mockItemBackupper) backupItem(logger logrus.FieldLogger, obj runtime.Unstructured, groupResource schema.GroupResource) (*backedUpItem, error) {
 	args := ib.Called(logger, obj, groupResource)
-	return args.Error(0)
+	item, _ := args.Get(0).(*backedUpItem)
+	return item, args.Error(1)
 }
@@ -18,9 +18,11 @@ package backup
 
 import (
 	"encoding/json"
+	"sync"
 	"time"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/podexec"
 	"github.com/heptio/ark/pkg/util/collections"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -31,18 +33,34 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// hookPhase identifies when a hook is run relative to the backup of an item.
+type hookPhase string
+
+const (
+	// hookPhasePre hooks are run before an item and any additional items returned by its
+	// ItemActions (e.g. the persistent volumes a pod uses) are backed up.
+	hookPhasePre hookPhase = "pre"
+	// hookPhasePost hooks are run after an item and any additional items returned by its
+	// ItemActions have been backed up.
+	hookPhasePost hookPhase = "post"
+)
+
 // itemHookHandler invokes hooks for an item.
 type itemHookHandler interface {
-	// handleHooks invokes hooks for an item. If the item is a pod and the appropriate annotations exist
-	// to specify a hook, that is executed. Otherwise, this looks at the backup context's Backup to
-	// determine if there are any hooks relevant to the item, taking into account the hook spec's
-	// namespaces, resources, and label selector.
-	handleHooks(log *logrus.Entry, groupResource schema.GroupResource, obj runtime.Unstructured, resourceHooks []resourceHook) error
+	// handleHooks invokes hooks for an item for the given phase. If the item is a pod and the
+	// appropriate annotations exist to specify a hook, that is executed. Otherwise, this looks at
+	// the backup context's Backup to determine if there are any hooks relevant to the item, taking
+	// into account the hook spec's namespaces, resources, and label selector.
+	handleHooks(log *logrus.Entry, groupResource schema.GroupResource, obj runtime.Unstructured, resourceHooks []resourceHook, phase hookPhase) error
 }
 
 // defaultItemHookHandler is the default itemHookHandler.
 type defaultItemHookHandler struct {
-	podCommandExecutor podCommandExecutor
+	podCommandExecutor podexec.PodCommandExecutor
+	// hookStatus, if non-nil, is updated with the number of hooks attempted and failed.
+	hookStatus *api.HookStatus
+	// hookStatusLock guards hookStatus, since hooks may be run for several items at once.
+	hookStatusLock sync.Mutex
 }
 
 func (h *defaultItemHookHandler) handleHooks(
@@ -50,6 +68,7 @@ func (h *defaultItemHookHandler) handleHooks(
 	groupResource schema.GroupResource,
 	obj runtime.Unstructured,
 	resourceHooks []resourceHook,
+	phase hookPhase,
 ) error {
 	// We only support hooks on pods right now
 	if groupResource != podsGroupResource {
@@ -65,18 +84,16 @@ func (h *defaultItemHookHandler) handleHooks(
 	name := metadata.GetName()
 
 	// If the pod has the hook specified via annotations, that takes priority.
-	if hookFromAnnotations := getPodExecHookFromAnnotations(metadata.GetAnnotations()); hookFromAnnotations != nil {
+	if hookFromAnnotations := getPodExecHookFromAnnotations(metadata.GetAnnotations(), phase); hookFromAnnotations != nil {
 		hookLog := log.WithFields(
 			logrus.Fields{
 				"hookSource": "annotation",
 				"hookType":   "exec",
+				"hookPhase":  phase,
 			},
 		)
-		if err := h.podCommandExecutor.executePodCommand(hookLog, obj.UnstructuredContent(), namespace, name, "<from-annotation>", hookFromAnnotations); err != nil {
-			hookLog.WithError(err).Error("Error executing hook")
-			if hookFromAnnotations.OnError == api.HookErrorModeFail {
-				return err
-			}
+		if err := h.executeHook(hookLog, obj, namespace, name, "<from-annotation>", hookFromAnnotations); err != nil {
+			return err
 		}
 
 		return nil
@@ -89,21 +106,23 @@ func (h *defaultItemHookHandler) handleHooks(
 			continue
 		}
 
-		for _, hook := range resourceHook.hooks {
+		hooks := resourceHook.pre
+		if phase == hookPhasePost {
+			hooks = resourceHook.post
+		}
+
+		for _, hook := range hooks {
 			if groupResource == podsGroupResource {
 				if hook.Exec != nil {
 					hookLog := log.WithFields(
 						logrus.Fields{
 							"hookSource": "backupSpec",
 							"hookType":   "exec",
+							"hookPhase":  phase,
 						},
 					)
-					err := h.podCommandExecutor.executePodCommand(hookLog, obj.UnstructuredContent(), namespace, name, resourceHook.name, hook.Exec)
-					if err != nil {
-						hookLog.WithError(err).Error("Error executing hook")
-						if hook.Exec.OnError == api.HookErrorModeFail {
-							return err
-						}
+					if err := h.executeHook(hookLog, obj, namespace, name, resourceHook.name, hook.Exec); err != nil {
+						return err
 					}
 				}
 			}
@@ -113,6 +132,41 @@ func (h *defaultItemHookHandler) handleHooks(
 	return nil
 }
 
+// executeHook runs an exec hook in a pod and records the result in the log and hookStatus.
+// An error is only returned if the hook failed and its OnError mode is Fail.
+func (h *defaultItemHookHandler) executeHook(
+	log *logrus.Entry,
+	obj runtime.Unstructured,
+	namespace, name, hookName string,
+	hook *api.ExecHook,
+) error {
+	if h.hookStatus != nil {
+		h.hookStatusLock.Lock()
+		h.hookStatus.HooksAttempted++
+		h.hookStatusLock.Unlock()
+	}
+
+	err := h.podCommandExecutor.ExecutePodCommand(log, obj.UnstructuredContent(), namespace, name, hookName, hook)
+	if err == nil {
+		log.Info("Hook executed successfully")
+		return nil
+	}
+
+	if h.hookStatus != nil {
+		h.hookStatusLock.Lock()
+		h.hookStatus.HooksFailed++
+		h.hookStatusLock.Unlock()
+	}
+
+	log.WithError(err).Error("Error executing hook")
+	// hooks without a valid OnError mode default to Fail
+	if hook.OnError != api.HookErrorModeContinue {
+		return err
+	}
+
+	return nil
+}
+
 const (
 	podBackupHookContainerAnnotationKey = "hook.backup.ark.heptio.com/container"
 	podBackupHookCommandAnnotationKey   = "hook.backup.ark.heptio.com/command"
@@ -122,12 +176,18 @@ const (
 	defaultHookTimeout                  = 30 * time.Second
 )
 
-// getPodExecHookFromAnnotations returns an ExecHook based on the annotations, as long as the
-// 'command' annotation is present. If it is absent, this returns nil.
-func getPodExecHookFromAnnotations(annotations map[string]string) *api.ExecHook {
-	container := annotations[podBackupHookContainerAnnotationKey]
+// getPodExecHookFromAnnotations returns an ExecHook for the given phase based on the annotations,
+// as long as the phase's 'command' annotation is present. If it is absent, this returns nil. The
+// un-prefixed (legacy) annotations are treated as pre hooks.
+func getPodExecHookFromAnnotations(annotations map[string]string, phase hookPhase) *api.ExecHook {
+	prefix := string(phase) + "."
+	if _, ok := annotations[prefix+podBackupHookCommandAnnotationKey]; !ok && phase == hookPhasePre {
+		prefix = ""
+	}
+
+	container := annotations[prefix+podBackupHookContainerAnnotationKey]
 
-	commandValue, ok := annotations[podBackupHookCommandAnnotationKey]
+	commandValue, ok := annotations[prefix+podBackupHookCommandAnnotationKey]
 	if !ok {
 		return nil
 	}
@@ -141,13 +201,13 @@ func getPodExecHookFromAnnotations(annotations map[string]string) *api.ExecHook
 		command = append(command, commandValue)
 	}
 
-	onError := api.HookErrorMode(annotations[podBackupHookOnErrorAnnotationKey])
+	onError := api.HookErrorMode(annotations[prefix+podBackupHookOnErrorAnnotationKey])
 	if onError != api.HookErrorModeContinue && onError != api.HookErrorModeFail {
 		onError = ""
 	}
 
 	var timeout time.Duration
-	timeoutString := annotations[podBackupHookTimeoutAnnotationKey]
+	timeoutString := annotations[prefix+podBackupHookTimeoutAnnotationKey]
 	if timeoutString != "" {
 		if temp, err := time.ParseDuration(timeoutString); err == nil {
 			timeout = temp
@@ -169,7 +229,8 @@ type resourceHook struct {
 	namespaces    *collections.IncludesExcludes
 	resources     *collections.IncludesExcludes
 	labelSelector labels.Selector
-	hooks         []api.BackupResourceHook
+	pre           []api.BackupResourceHook
+	post          []api.BackupResourceHook
 }
 
 func (r resourceHook) applicableTo(groupResource schema.GroupResource, namespace string, labels labels.Set) bool {
@@ -38,8 +38,8 @@ type mockItemHookHandler struct {
 	mock.Mock
 }
 
-func (h *mockItemHookHandler) handleHooks(log *logrus.Entry, groupResource schema.GroupResource, obj runtime.Unstructured, resourceHooks []resourceHook) error {
-	args := h.Called(log, groupResource, obj, resourceHooks)
+func (h *mockItemHookHandler) handleHooks(log *logrus.Entry, groupResource schema.GroupResource, obj runtime.Unstructured, resourceHooks []resourceHook, phase hookPhase) error {
+	args := h.Called(log, groupResource, obj, resourceHooks, phase)
 	return args.Error(0)
 }
 
@@ -102,7 +102,7 @@ func TestHandleHooksSkips(t *testing.T) {
 				},
 				{
 					name: "missing exec hook",
-					hooks: []v1.BackupResourceHook{
+					pre: []v1.BackupResourceHook{
 						{},
 						{},
 					},
@@ -113,7 +113,7 @@ func TestHandleHooksSkips(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			podCommandExecutor := &mockPodCommandExecutor{}
+			podCommandExecutor := &arktest.MockPodCommandExecutor{}
 			defer podCommandExecutor.AssertExpectations(t)
 
 			h := &defaultItemHookHandler{
@@ -121,7 +121,7 @@ func TestHandleHooksSkips(t *testing.T) {
 			}
 
 			groupResource := schema.ParseGroupResource(test.groupResource)
-			err := h.handleHooks(arktest.NewLogger(), groupResource, test.item, test.hooks)
+			err := h.handleHooks(arktest.NewLogger(), groupResource, test.item, test.hooks, hookPhasePre)
 			assert.NoError(t, err)
 		})
 	}
@@ -153,7 +153,7 @@ func TestHandleHooksPodFromPodAnnotation(t *testing.T) {
 			hooks: []resourceHook{
 				{
 					name: "hook1",
-					hooks: []v1.BackupResourceHook{
+					pre: []v1.BackupResourceHook{
 						{
 							Exec: &v1.ExecHook{
 								Container: "1a",
@@ -170,7 +170,7 @@ func TestHandleHooksPodFromPodAnnotation(t *testing.T) {
 				},
 				{
 					name: "hook2",
-					hooks: []v1.BackupResourceHook{
+					pre: []v1.BackupResourceHook{
 						{
 							Exec: &v1.ExecHook{
 								Container: "2a",
@@ -231,7 +231,7 @@ func TestHandleHooksPodFromPodAnnotation(t *testing.T) {
 			hooks: []resourceHook{
 				{
 					name: "hook1",
-					hooks: []v1.BackupResourceHook{
+					pre: []v1.BackupResourceHook{
 						{
 							Exec: &v1.ExecHook{
 								Container: "1a",
@@ -307,7 +307,7 @@ func TestHandleHooksPodFromPodAnnotation(t *testing.T) {
 			hooks: []resourceHook{
 				{
 					name: "hook1",
-					hooks: []v1.BackupResourceHook{
+					pre: []v1.BackupResourceHook{
 						{
 							Exec: &v1.ExecHook{
 								Container: "1a",
@@ -325,7 +325,7 @@ func TestHandleHooksPodFromPodAnnotation(t *testing.T) {
 				},
 				{
 					name: "hook2",
-					hooks: []v1.BackupResourceHook{
+					pre: []v1.BackupResourceHook{
 						{
 							Exec: &v1.ExecHook{
 								Container: "2",
@@ -337,7 +337,7 @@ func TestHandleHooksPodFromPodAnnotation(t *testing.T) {
 				},
 				{
 					name: "hook3",
-					hooks: []v1.BackupResourceHook{
+					pre: []v1.BackupResourceHook{
 						{
 							Exec: &v1.ExecHook{
 								Container: "3",
@@ -357,7 +357,7 @@ func TestHandleHooksPodFromPodAnnotation(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			podCommandExecutor := &mockPodCommandExecutor{}
+			podCommandExecutor := &arktest.MockPodCommandExecutor{}
 			defer podCommandExecutor.AssertExpectations(t)
 
 			h := &defaultItemHookHandler{
@@ -365,13 +365,13 @@ func TestHandleHooksPodFromPodAnnotation(t *testing.T) {
 			}
 
 			if test.expectedPodHook != nil {
-				podCommandExecutor.On("executePodCommand", mock.Anything, test.item.UnstructuredContent(), "ns", "name", "<from-annotation>", test.expectedPodHook).Return(test.expectedPodHookError)
+				podCommandExecutor.On("ExecutePodCommand", mock.Anything, test.item.UnstructuredContent(), "ns", "name", "<from-annotation>", test.expectedPodHook).Return(test.expectedPodHookError)
 			} else {
 			hookLoop:
 				for _, resourceHook := range test.hooks {
-					for _, hook := range resourceHook.hooks {
+					for _, hook := range resourceHook.pre {
 						hookError := test.hookErrorsByContainer[hook.Exec.Container]
-						podCommandExecutor.On("executePodCommand", mock.Anything, test.item.UnstructuredContent(), "ns", "name", resourceHook.name, hook.Exec).Return(hookError)
+						podCommandExecutor.On("ExecutePodCommand", mock.Anything, test.item.UnstructuredContent(), "ns", "name", resourceHook.name, hook.Exec).Return(hookError)
 						if hookError != nil && hook.Exec.OnError == v1.HookErrorModeFail {
 							break hookLoop
 						}
@@ -380,7 +380,7 @@ func TestHandleHooksPodFromPodAnnotation(t *testing.T) {
 			}
 
 			groupResource := schema.ParseGroupResource(test.groupResource)
-			err := h.handleHooks(arktest.NewLogger(), groupResource, test.item, test.hooks)
+			err := h.handleHooks(arktest.NewLogger(), groupResource, test.item, test.hooks, hookPhasePre)
 
 			if test.expectedError != nil {
 				assert.EqualError(t, err, test.expectedError.Error())
@@ -487,12 +487,118 @@ func TestGetPodExecHookFromAnnotations(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			hook := getPodExecHookFromAnnotations(test.annotations)
+			hook := getPodExecHookFromAnnotations(test.annotations, hookPhasePre)
 			assert.Equal(t, test.expectedHook, hook)
 		})
 	}
 }
 
+func TestGetPodExecHookFromPhasedAnnotations(t *testing.T) {
+	tests := []struct {
+		name         string
+		phase        hookPhase
+		annotations  map[string]string
+		expectedHook *v1.ExecHook
+	}{
+		{
+			name:  "pre phase uses pre annotations",
+			phase: hookPhasePre,
+			annotations: map[string]string{
+				"pre." + podBackupHookContainerAnnotationKey: "pre-container",
+				"pre." + podBackupHookCommandAnnotationKey:   "/usr/bin/pre",
+				podBackupHookCommandAnnotationKey:            "/usr/bin/legacy",
+			},
+			expectedHook: &v1.ExecHook{
+				Container: "pre-container",
+				Command:   []string{"/usr/bin/pre"},
+			},
+		},
+		{
+			name:  "pre phase falls back to legacy annotations",
+			phase: hookPhasePre,
+			annotations: map[string]string{
+				podBackupHookContainerAnnotationKey:         "legacy-container",
+				podBackupHookCommandAnnotationKey:           "/usr/bin/legacy",
+				"post." + podBackupHookCommandAnnotationKey: "/usr/bin/post",
+			},
+			expectedHook: &v1.ExecHook{
+				Container: "legacy-container",
+				Command:   []string{"/usr/bin/legacy"},
+			},
+		},
+		{
+			name:  "post phase uses post annotations",
+			phase: hookPhasePost,
+			annotations: map[string]string{
+				"post." + podBackupHookCommandAnnotationKey: `["fsfreeze","--unfreeze","/data"]`,
+				"post." + podBackupHookOnErrorAnnotationKey: string(v1.HookErrorModeContinue),
+				"post." + podBackupHookTimeoutAnnotationKey: "1m",
+			},
+			expectedHook: &v1.ExecHook{
+				Command: []string{"fsfreeze", "--unfreeze", "/data"},
+				OnError: v1.HookErrorModeContinue,
+				Timeout: metav1.Duration{Duration: time.Minute},
+			},
+		},
+		{
+			name:  "post phase ignores legacy annotations",
+			phase: hookPhasePost,
+			annotations: map[string]string{
+				podBackupHookCommandAnnotationKey: "/usr/bin/legacy",
+			},
+			expectedHook: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hook := getPodExecHookFromAnnotations(test.annotations, test.phase)
+			assert.Equal(t, test.expectedHook, hook)
+		})
+	}
+}
+
+func TestHandleHooksPhasesAndStatus(t *testing.T) {
+	pod := unstructuredOrDie(`
+		{
+			"apiVersion": "v1",
+			"kind": "Pod",
+			"metadata": {
+				"namespace": "ns",
+				"name": "name"
+			}
+		}`)
+
+	preHook := &v1.ExecHook{Container: "c", Command: []string{"freeze"}, OnError: v1.HookErrorModeContinue}
+	postHook := &v1.ExecHook{Container: "c", Command: []string{"unfreeze"}, OnError: v1.HookErrorModeFail}
+
+	hooks := []resourceHook{
+		{
+			name: "hook",
+			pre:  []v1.BackupResourceHook{{Exec: preHook}},
+			post: []v1.BackupResourceHook{{Exec: postHook}},
+		},
+	}
+
+	podCommandExecutor := &arktest.MockPodCommandExecutor{}
+	defer podCommandExecutor.AssertExpectations(t)
+
+	status := &v1.HookStatus{}
+	h := &defaultItemHookHandler{
+		podCommandExecutor: podCommandExecutor,
+		hookStatus:         status,
+	}
+
+	podCommandExecutor.On("ExecutePodCommand", mock.Anything, pod.UnstructuredContent(), "ns", "name", "hook", preHook).Return(errors.New("pre failed"))
+	podCommandExecutor.On("ExecutePodCommand", mock.Anything, pod.UnstructuredContent(), "ns", "name", "hook", postHook).Return(nil)
+
+	// pre hook failure is ignored because OnError is Continue
+	require.NoError(t, h.handleHooks(arktest.NewLogger(), podsGroupResource, pod, hooks, hookPhasePre))
+	require.NoError(t, h.handleHooks(arktest.NewLogger(), podsGroupResource, pod, hooks, hookPhasePost))
+
+	assert.Equal(t, v1.HookStatus{HooksAttempted: 2, HooksFailed: 1}, *status)
+}
+
 func TestResourceHookApplicableTo(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -0,0 +1,123 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// maxPendingItemsPerWorker limits how far the workers can get ahead of the tar-writing
+// stage, e.g. while it waits for an item whose hooks are slow, to bound the number of
+// backed-up items held in memory.
+const maxPendingItemsPerWorker = 10
+
+// itemPipeline backs up items using a bounded number of concurrent workers, and writes them
+// to the tarball in a single stage, in the order they were added, so the tarball's contents
+// don't depend on the order in which the workers finish.
+type itemPipeline struct {
+	log           logrus.FieldLogger
+	itemBackupper ItemBackupper
+	groupResource schema.GroupResource
+	tarWriter     tarWriter
+
+	work    chan pipelineItem
+	results chan chan *backedUpItem
+	workers sync.WaitGroup
+	written chan struct{}
+}
+
+type pipelineItem struct {
+	obj      runtime.Unstructured
+	metadata metav1.Object
+	result   chan *backedUpItem
+}
+
+// newItemPipeline starts a pipeline that backs up items of the given group-resource using
+// the given number of workers.
+func newItemPipeline(log logrus.FieldLogger, itemBackupper ItemBackupper, groupResource schema.GroupResource, tarWriter tarWriter, workers int) *itemPipeline {
+	if workers < 1 {
+		workers = 1
+	}
+
+	p := &itemPipeline{
+		log:           log,
+		itemBackupper: itemBackupper,
+		groupResource: groupResource,
+		tarWriter:     tarWriter,
+		work:          make(chan pipelineItem),
+		results:       make(chan chan *backedUpItem, workers*maxPendingItemsPerWorker),
+		written:       make(chan struct{}),
+	}
+
+	p.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.runWorker()
+	}
+
+	go p.writeItems()
+
+	return p
+}
+
+// add queues an item to be backed up. It blocks while all of the workers are busy, or
+// while too many backed-up items are waiting to be written.
+func (p *itemPipeline) add(obj runtime.Unstructured, metadata metav1.Object) {
+	// results are buffered so workers never wait for the tar-writing stage
+	result := make(chan *backedUpItem, 1)
+
+	p.results <- result
+	p.work <- pipelineItem{obj: obj, metadata: metadata, result: result}
+}
+
+// wait waits for all of the items that were added to be backed up and written to the
+// tarball. No items can be added once it's been called.
+func (p *itemPipeline) wait() {
+	close(p.work)
+	close(p.results)
+
+	p.workers.Wait()
+	<-p.written
+}
+
+func (p *itemPipeline) runWorker() {
+	defer p.workers.Done()
+
+	for item := range p.work {
+		backedUp, err := p.itemBackupper.backupItem(p.log, item.obj, p.groupResource)
+		if err != nil {
+			logItemError(p.log, item.metadata, err)
+		}
+
+		item.result <- backedUp
+	}
+}
+
+func (p *itemPipeline) writeItems() {
+	defer close(p.written)
+
+	for result := range p.results {
+		if item := <-result; item != nil {
+			writeItem(p.log, p.tarWriter, item)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	testlogger "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// slowItemBackupper backs up items more slowly the earlier they're named, so concurrent
+// workers finish them in the reverse of the order they were added in.
+type slowItemBackupper struct {
+	backedUpItems *backedUpItemSet
+	count         int
+}
+
+func (ib *slowItemBackupper) backupItem(log logrus.FieldLogger, obj runtime.Unstructured, groupResource schema.GroupResource) (*backedUpItem, error) {
+	metadata := obj.(*unstructured.Unstructured)
+
+	var index int
+	fmt.Sscanf(metadata.GetName(), "item-%d", &index)
+	time.Sleep(time.Duration(ib.count-index) * time.Millisecond)
+
+	if metadata.GetLabels()["fail"] == "true" {
+		return nil, errors.New("backup failed")
+	}
+
+	item, added := ib.backedUpItems.add(itemKey{resource: groupResource.String(), name: metadata.GetName()})
+	if !added {
+		return item, nil
+	}
+	defer item.finish()
+
+	item.header = &tar.Header{Name: metadata.GetName()}
+	return item, nil
+}
+
+func TestItemPipelineWritesItemsInOrderTheyWereAdded(t *testing.T) {
+	const count = 20
+
+	ib := &slowItemBackupper{backedUpItems: newBackedUpItemSet(), count: count}
+	w := &fakeTarWriter{}
+	logger, hook := testlogger.NewNullLogger()
+
+	pipeline := newItemPipeline(logger, ib, schema.GroupResource{Resource: "pods"}, w, 4)
+
+	var expected []string
+	for i := 0; i < count; i++ {
+		obj := &unstructured.Unstructured{}
+		obj.SetName(fmt.Sprintf("item-%d", i))
+		if i == 3 {
+			obj.SetLabels(map[string]string{"fail": "true"})
+		} else {
+			expected = append(expected, obj.GetName())
+		}
+
+		pipeline.add(obj, obj)
+	}
+
+	// an item added again is only written once
+	duplicate := &unstructured.Unstructured{}
+	duplicate.SetName("item-0")
+	pipeline.add(duplicate, duplicate)
+
+	pipeline.wait()
+
+	var actual []string
+	for _, header := range w.headers {
+		actual = append(actual, header.Name)
+	}
+	assert.Equal(t, expected, actual)
+
+	require.Len(t, hook.Entries, 1)
+	assert.Equal(t, "item-3", hook.LastEntry().Data["name"])
+}
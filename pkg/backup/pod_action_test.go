@@ -0,0 +1,123 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestPodActionAppliesTo(t *testing.T) {
+	a := NewPodAction(arktest.NewLogger())
+
+	actual, err := a.AppliesTo()
+	require.NoError(t, err)
+
+	expected := ResourceSelector{
+		IncludedResources: []string{"pods"},
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestPodActionExecute(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      string
+		expected []ResourceIdentifier
+	}{
+		{
+			name: "no spec.volumes",
+			pod: `{
+				"apiVersion": "v1",
+				"kind": "Pod",
+				"metadata": {
+					"namespace": "foo",
+					"name": "bar"
+				}
+			}`,
+		},
+		{
+			name: "persistentVolumeClaim without claimName",
+			pod: `{
+				"apiVersion": "v1",
+				"kind": "Pod",
+				"metadata": {
+					"namespace": "foo",
+					"name": "bar"
+				},
+				"spec": {
+					"volumes": [
+						{
+							"persistentVolumeClaim": {}
+						}
+					]
+				}
+			}`,
+		},
+		{
+			name: "full test, mix of volume types",
+			pod: `{
+				"apiVersion": "v1",
+				"kind": "Pod",
+				"metadata": {
+					"namespace": "foo",
+					"name": "bar"
+				},
+				"spec": {
+					"volumes": [
+						{
+							"persistentVolumeClaim": {}
+						},
+						{
+							"emptyDir": {}
+						},
+						{
+							"persistentVolumeClaim": {"claimName": "claim1"}
+						},
+						{
+							"emptyDir": {}
+						},
+						{
+							"persistentVolumeClaim": {"claimName": "claim2"}
+						}
+					]
+				}
+			}`,
+			expected: []ResourceIdentifier{
+				{GroupResource: pvcGroupResource, Namespace: "foo", Name: "claim1"},
+				{GroupResource: pvcGroupResource, Namespace: "foo", Name: "claim2"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := NewPodAction(arktest.NewLogger())
+
+			pod := unstructuredOrDie(test.pod)
+			actual, additional, err := a.Execute(pod, &v1.Backup{})
+			require.NoError(t, err)
+			assert.Equal(t, pod, actual)
+			assert.Equal(t, test.expected, additional)
+		})
+	}
+}
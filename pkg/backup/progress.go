@@ -0,0 +1,128 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/util/logging"
+)
+
+// ProgressReporter is notified periodically of a backup's progress while it's running.
+type ProgressReporter interface {
+	// ReportProgress is called with the backup's progress so far, and the number of
+	// warnings and errors that have been logged.
+	ReportProgress(progress api.BackupProgress, warnings, errors int)
+}
+
+// defaultProgressReportInterval is how often a running backup's progress is reported.
+const defaultProgressReportInterval = 10 * time.Second
+
+// progressTracker counts the items listed and backed up during a backup.
+type progressTracker struct {
+	mu       sync.Mutex
+	progress api.BackupProgress
+}
+
+// addTotal adds n items to the total number of items to back up.
+func (p *progressTracker) addTotal(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.progress.TotalItems += n
+}
+
+// itemBackedUp records that an item has been backed up. Items that weren't counted when
+// listing resources (e.g. additional items returned by actions) are added to the total.
+func (p *progressTracker) itemBackedUp() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.progress.ItemsBackedUp++
+	if p.progress.ItemsBackedUp > p.progress.TotalItems {
+		p.progress.TotalItems = p.progress.ItemsBackedUp
+	}
+}
+
+func (p *progressTracker) get() api.BackupProgress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.progress
+}
+
+// progressTarWriter is a tarWriter that records an item as backed up each time one is
+// written to the tarball.
+type progressTarWriter struct {
+	tarWriter
+	progress *progressTracker
+}
+
+func (w *progressTarWriter) WriteHeader(hdr *tar.Header) error {
+	if err := w.tarWriter.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	if hdr.Typeflag == tar.TypeReg {
+		w.progress.itemBackedUp()
+	}
+
+	return nil
+}
+
+// reportProgress calls reporter with the backup's progress and log counts every interval,
+// whenever they've changed, until stop is closed. The returned channel is closed once
+// reporting has stopped.
+func reportProgress(reporter ProgressReporter, progress *progressTracker, counter *logging.LogCounterHook, interval time.Duration, stop <-chan struct{}) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var (
+			lastProgress           api.BackupProgress
+			lastWarnings, lastErrs int
+		)
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			current := progress.get()
+			warnings, errs := counter.GetCount(logrus.WarnLevel), counter.GetCount(logrus.ErrorLevel)
+			if current == lastProgress && warnings == lastWarnings && errs == lastErrs {
+				continue
+			}
+
+			reporter.ReportProgress(current, warnings, errs)
+			lastProgress, lastWarnings, lastErrs = current, warnings, errs
+		}
+	}()
+
+	return done
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/util/logging"
+)
+
+func TestProgressTarWriter(t *testing.T) {
+	progress := &progressTracker{}
+	progress.addTotal(2)
+
+	fakeWriter := &fakeTarWriter{}
+	w := &progressTarWriter{tarWriter: fakeWriter, progress: progress}
+
+	require.NoError(t, w.WriteHeader(&tar.Header{Name: "resources/pods", Typeflag: tar.TypeDir}))
+	require.NoError(t, w.WriteHeader(&tar.Header{Name: "resources/pods/namespaces/ns/pod-1.json", Typeflag: tar.TypeReg}))
+	assert.Equal(t, v1.BackupProgress{TotalItems: 2, ItemsBackedUp: 1}, progress.get())
+
+	// failed writes aren't counted
+	fakeWriter.writeHeaderError = errors.New("write error")
+	assert.Error(t, w.WriteHeader(&tar.Header{Name: "resources/pods/namespaces/ns/pod-2.json", Typeflag: tar.TypeReg}))
+	assert.Equal(t, v1.BackupProgress{TotalItems: 2, ItemsBackedUp: 1}, progress.get())
+
+	// items that weren't listed are added to the total
+	fakeWriter.writeHeaderError = nil
+	for i := 0; i < 2; i++ {
+		require.NoError(t, w.WriteHeader(&tar.Header{Name: "resources/persistentvolumes/cluster/pv.json", Typeflag: tar.TypeReg}))
+	}
+	assert.Equal(t, v1.BackupProgress{TotalItems: 3, ItemsBackedUp: 3}, progress.get())
+}
+
+type fakeProgressReporter struct {
+	sync.Mutex
+	reports          []v1.BackupProgress
+	warnings, errors int
+}
+
+func (r *fakeProgressReporter) ReportProgress(progress v1.BackupProgress, warnings, errors int) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.reports = append(r.reports, progress)
+	r.warnings, r.errors = warnings, errors
+}
+
+func (r *fakeProgressReporter) numReports() int {
+	r.Lock()
+	defer r.Unlock()
+
+	return len(r.reports)
+}
+
+func TestReportProgress(t *testing.T) {
+	progress := &progressTracker{}
+	counter := logging.NewLogCounterHook()
+	reporter := &fakeProgressReporter{}
+
+	progress.addTotal(5)
+	progress.itemBackedUp()
+	require.NoError(t, counter.Fire(&logrus.Entry{Level: logrus.WarnLevel}))
+
+	stop := make(chan struct{})
+	done := reportProgress(reporter, progress, counter, time.Millisecond, stop)
+
+	require.True(t, waitFor(func() bool { return reporter.numReports() > 0 }))
+
+	// nothing's changed, so there's nothing more to report
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 1, reporter.numReports())
+
+	progress.itemBackedUp()
+	require.True(t, waitFor(func() bool { return reporter.numReports() > 1 }))
+
+	close(stop)
+	<-done
+
+	assert.Equal(t, []v1.BackupProgress{
+		{TotalItems: 5, ItemsBackedUp: 1},
+		{TotalItems: 5, ItemsBackedUp: 2},
+	}, reporter.reports)
+	assert.Equal(t, 1, reporter.warnings)
+	assert.Equal(t, 0, reporter.errors)
+}
+
+func waitFor(condition func() bool) bool {
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/podexec"
+	"github.com/heptio/ark/pkg/podvolume"
 	"github.com/heptio/ark/pkg/util/collections"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -41,13 +43,16 @@ type resourceBackupperFactory interface {
 		labelSelector string,
 		dynamicFactory client.DynamicFactory,
 		discoveryHelper discovery.Helper,
-		backedUpItems map[itemKey]struct{},
+		backedUpItems *backedUpItemSet,
 		cohabitatingResources map[string]*cohabitatingResource,
 		actions []resolvedAction,
-		podCommandExecutor podCommandExecutor,
+		podCommandExecutor podexec.PodCommandExecutor,
 		tarWriter tarWriter,
 		resourceHooks []resourceHook,
 		snapshotService cloudprovider.SnapshotService,
+		podVolumeBackupper podvolume.Backupper,
+		progress *progressTracker,
+		itemWorkers int,
 	) resourceBackupper
 }
 
@@ -61,13 +66,16 @@ func (f *defaultResourceBackupperFactory) newResourceBackupper(
 	labelSelector string,
 	dynamicFactory client.DynamicFactory,
 	discoveryHelper discovery.Helper,
-	backedUpItems map[itemKey]struct{},
+	backedUpItems *backedUpItemSet,
 	cohabitatingResources map[string]*cohabitatingResource,
 	actions []resolvedAction,
-	podCommandExecutor podCommandExecutor,
+	podCommandExecutor podexec.PodCommandExecutor,
 	tarWriter tarWriter,
 	resourceHooks []resourceHook,
 	snapshotService cloudprovider.SnapshotService,
+	podVolumeBackupper podvolume.Backupper,
+	progress *progressTracker,
+	itemWorkers int,
 ) resourceBackupper {
 	return &defaultResourceBackupper{
 		log:                   log,
@@ -84,6 +92,9 @@ func (f *defaultResourceBackupperFactory) newResourceBackupper(
 		tarWriter:             tarWriter,
 		resourceHooks:         resourceHooks,
 		snapshotService:       snapshotService,
+		podVolumeBackupper:    podVolumeBackupper,
+		progress:              progress,
+		itemWorkers:           itemWorkers,
 		itemBackupperFactory:  &defaultItemBackupperFactory{},
 	}
 }
@@ -100,14 +111,18 @@ type defaultResourceBackupper struct {
 	labelSelector         string
 	dynamicFactory        client.DynamicFactory
 	discoveryHelper       discovery.Helper
-	backedUpItems         map[itemKey]struct{}
+	backedUpItems         *backedUpItemSet
 	cohabitatingResources map[string]*cohabitatingResource
 	actions               []resolvedAction
-	podCommandExecutor    podCommandExecutor
+	podCommandExecutor    podexec.PodCommandExecutor
 	tarWriter             tarWriter
 	resourceHooks         []resourceHook
 	snapshotService       cloudprovider.SnapshotService
-	itemBackupperFactory  itemBackupperFactory
+	podVolumeBackupper    podvolume.Backupper
+	progress              *progressTracker
+	// itemWorkers is the number of items that can be listed or backed up at once.
+	itemWorkers          int
+	itemBackupperFactory itemBackupperFactory
 }
 
 // backupResource backs up all the objects for a given group-version-resource.
@@ -176,13 +191,18 @@ func (rb *defaultResourceBackupper) backupResource(
 		rb.backedUpItems,
 		rb.actions,
 		rb.podCommandExecutor,
-		rb.tarWriter,
 		rb.resourceHooks,
 		rb.dynamicFactory,
 		rb.discoveryHelper,
 		rb.snapshotService,
+		rb.podVolumeBackupper,
 	)
 
+	// items are backed up concurrently, but written to the tarball in the order they're added
+	// to the pipeline, which is the order they're listed in
+	pipeline := newItemPipeline(log, itemBackupper, gr, rb.tarWriter, rb.itemWorkers)
+	defer pipeline.wait()
+
 	namespacesToList := getNamespacesToList(rb.namespaces)
 
 	// Check if we're backing up namespaces, and only certain ones
@@ -201,6 +221,8 @@ func (rb *defaultResourceBackupper) backupResource(
 			}
 		}
 
+		rb.progress.addTotal(len(namespacesToList))
+
 		for _, ns := range namespacesToList {
 			log.WithField("namespace", ns).Info("Getting namespace")
 			unstructured, err := resourceClient.Get(ns, metav1.GetOptions{})
@@ -215,9 +237,7 @@ func (rb *defaultResourceBackupper) backupResource(
 				continue
 			}
 
-			if err := itemBackupper.backupItem(log, unstructured, gr); err != nil {
-				errs = append(errs, err)
-			}
+			pipeline.add(unstructured, unstructured)
 		}
 
 		return kuberrs.NewAggregate(errs)
@@ -228,25 +248,18 @@ func (rb *defaultResourceBackupper) backupResource(
 		namespacesToList = []string{""}
 	}
 
-	for _, namespace := range namespacesToList {
-		resourceClient, err := rb.dynamicFactory.ClientForGroupVersionResource(gv, resource, namespace)
-		if err != nil {
-			return err
-		}
-
-		log.WithField("namespace", namespace).Info("Listing items")
-		unstructuredList, err := resourceClient.List(metav1.ListOptions{LabelSelector: rb.labelSelector})
-		if err != nil {
-			return errors.WithStack(err)
-		}
+	lists := rb.listItems(log, gv, resource, namespacesToList)
 
-		// do the backup
-		items, err := meta.ExtractList(unstructuredList)
-		if err != nil {
-			return errors.WithStack(err)
+	for i, namespace := range namespacesToList {
+		list := <-lists[i]
+		if list.err != nil {
+			return list.err
 		}
+		items := list.items
 
 		log.WithField("namespace", namespace).Infof("Retrieved %d items", len(items))
+		rb.progress.addTotal(len(items))
+
 		for _, item := range items {
 			unstructured, ok := item.(runtime.Unstructured)
 			if !ok {
@@ -265,15 +278,82 @@ func (rb *defaultResourceBackupper) backupResource(
 				continue
 			}
 
-			if err := itemBackupper.backupItem(log, unstructured, gr); err != nil {
-				errs = append(errs, err)
-			}
+			pipeline.add(unstructured, metadata)
 		}
 	}
 
 	return kuberrs.NewAggregate(errs)
 }
 
+// namespaceItems is the result of listing a resource's items in a namespace.
+type namespaceItems struct {
+	items []runtime.Object
+	err   error
+}
+
+// listItems starts listing the resource's items in each of the namespaces, with up to
+// rb.itemWorkers lists in progress at once. It returns a channel for each namespace, in the
+// same order as namespaces, that receives the namespace's items once they've been listed.
+func (rb *defaultResourceBackupper) listItems(log logrus.FieldLogger, gv schema.GroupVersion, resource metav1.APIResource, namespaces []string) []chan namespaceItems {
+	lists := make([]chan namespaceItems, len(namespaces))
+	for i := range lists {
+		lists[i] = make(chan namespaceItems, 1)
+	}
+
+	workers := rb.itemWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	go func() {
+		limit := make(chan struct{}, workers)
+
+		for i, namespace := range namespaces {
+			limit <- struct{}{}
+
+			go func(list chan<- namespaceItems, namespace string) {
+				defer func() { <-limit }()
+
+				items, err := rb.listNamespace(log, gv, resource, namespace)
+				list <- namespaceItems{items: items, err: err}
+			}(lists[i], namespace)
+		}
+	}()
+
+	return lists
+}
+
+// listNamespace lists the resource's items in a namespace, or across all namespaces if
+// namespace is empty.
+func (rb *defaultResourceBackupper) listNamespace(log logrus.FieldLogger, gv schema.GroupVersion, resource metav1.APIResource, namespace string) ([]runtime.Object, error) {
+	resourceClient, err := rb.dynamicFactory.ClientForGroupVersionResource(gv, resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithField("namespace", namespace).Info("Listing items")
+	unstructuredList, err := resourceClient.List(metav1.ListOptions{LabelSelector: rb.labelSelector})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	items, err := meta.ExtractList(unstructuredList)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return items, nil
+}
+
+// logItemError logs an error backing up an item. Errors backing up individual items don't fail
+// the rest of the backup; they're recorded in the backup's results.
+func logItemError(log logrus.FieldLogger, item metav1.Object, err error) {
+	log.WithError(err).WithFields(logrus.Fields{
+		"namespace": item.GetNamespace(),
+		"name":      item.GetName(),
+	}).Error("Error backing up item")
+}
+
 // getNamespacesToList examines ie and resolves the includes and excludes to a full list of
 // namespaces to list. If ie is nil or it includes *, the result is just "" (list across all
 // namespaces). Otherwise, the result is a list of every included namespace minus all excluded ones.
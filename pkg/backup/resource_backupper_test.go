@@ -23,6 +23,8 @@ import (
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/podexec"
+	"github.com/heptio/ark/pkg/podvolume"
 	"github.com/heptio/ark/pkg/util/collections"
 	arktest "github.com/heptio/ark/pkg/util/test"
 	"github.com/stretchr/testify/assert"
@@ -230,9 +232,8 @@ func TestBackupResource(t *testing.T) {
 
 		discoveryHelper := arktest.NewFakeDiscoveryHelper(true, nil)
 
-		backedUpItems := map[itemKey]struct{}{
-			{resource: "foo", namespace: "ns", name: "name"}: {},
-		}
+		backedUpItems := newBackedUpItemSet()
+		backedUpItems.add(itemKey{resource: "foo", namespace: "ns", name: "name"})
 
 		cohabitatingResources := map[string]*cohabitatingResource{
 			"deployments":     newCohabitatingResource("deployments", "extensions", "apps"),
@@ -250,12 +251,13 @@ func TestBackupResource(t *testing.T) {
 			{name: "myhook"},
 		}
 
-		podCommandExecutor := &mockPodCommandExecutor{}
+		podCommandExecutor := &arktest.MockPodCommandExecutor{}
 		defer podCommandExecutor.AssertExpectations(t)
 
 		tarWriter := &fakeTarWriter{}
 
 		t.Run(test.name, func(t *testing.T) {
+			progress := &progressTracker{}
 			rb := (&defaultResourceBackupperFactory{}).newResourceBackupper(
 				arktest.NewLogger(),
 				backup,
@@ -271,6 +273,9 @@ func TestBackupResource(t *testing.T) {
 				tarWriter,
 				resourceHooks,
 				nil,
+				nil,
+				progress,
+				1,
 			).(*defaultResourceBackupper)
 
 			itemBackupperFactory := &mockItemBackupperFactory{}
@@ -288,11 +293,11 @@ func TestBackupResource(t *testing.T) {
 					backedUpItems,
 					actions,
 					podCommandExecutor,
-					tarWriter,
 					resourceHooks,
 					dynamicFactory,
 					discoveryHelper,
 					mock.Anything,
+					mock.Anything,
 				).Return(itemBackupper)
 
 				if len(test.listResponses) > 0 {
@@ -307,7 +312,7 @@ func TestBackupResource(t *testing.T) {
 						}
 						for _, item := range test.listResponses[i] {
 							list.Items = append(list.Items, *item)
-							itemBackupper.On("backupItem", mock.AnythingOfType("*logrus.Entry"), item, test.groupResource).Return(nil)
+							itemBackupper.On("backupItem", mock.AnythingOfType("*logrus.Entry"), item, test.groupResource).Return(nil, nil)
 						}
 						client.On("List", metav1.ListOptions{LabelSelector: labelSelector}).Return(list, nil)
 					}
@@ -322,13 +327,19 @@ func TestBackupResource(t *testing.T) {
 					for i, namespace := range test.expectedListedNamespaces {
 						item := test.getResponses[i]
 						client.On("Get", namespace, metav1.GetOptions{}).Return(item, nil)
-						itemBackupper.On("backupItem", mock.AnythingOfType("*logrus.Entry"), item, test.groupResource).Return(nil)
+						itemBackupper.On("backupItem", mock.AnythingOfType("*logrus.Entry"), item, test.groupResource).Return(nil, nil)
 					}
 				}
 			}
 
 			err := rb.backupResource(test.apiGroup, test.apiResource)
 			require.NoError(t, err)
+
+			expectedTotal := len(test.getResponses)
+			for _, items := range test.listResponses {
+				expectedTotal += len(items)
+			}
+			assert.Equal(t, expectedTotal, progress.get().TotalItems)
 		})
 	}
 }
@@ -390,9 +401,8 @@ func TestBackupResourceCohabitation(t *testing.T) {
 
 			discoveryHelper := arktest.NewFakeDiscoveryHelper(true, nil)
 
-			backedUpItems := map[itemKey]struct{}{
-				{resource: "foo", namespace: "ns", name: "name"}: {},
-			}
+			backedUpItems := newBackedUpItemSet()
+			backedUpItems.add(itemKey{resource: "foo", namespace: "ns", name: "name"})
 
 			cohabitatingResources := map[string]*cohabitatingResource{
 				"deployments":     newCohabitatingResource("deployments", "extensions", "apps"),
@@ -410,7 +420,7 @@ func TestBackupResourceCohabitation(t *testing.T) {
 				{name: "myhook"},
 			}
 
-			podCommandExecutor := &mockPodCommandExecutor{}
+			podCommandExecutor := &arktest.MockPodCommandExecutor{}
 			defer podCommandExecutor.AssertExpectations(t)
 
 			tarWriter := &fakeTarWriter{}
@@ -430,6 +440,9 @@ func TestBackupResourceCohabitation(t *testing.T) {
 				tarWriter,
 				resourceHooks,
 				nil,
+				nil,
+				&progressTracker{},
+				1,
 			).(*defaultResourceBackupper)
 
 			itemBackupperFactory := &mockItemBackupperFactory{}
@@ -446,11 +459,11 @@ func TestBackupResourceCohabitation(t *testing.T) {
 				backedUpItems,
 				actions,
 				podCommandExecutor,
-				tarWriter,
 				resourceHooks,
 				dynamicFactory,
 				discoveryHelper,
 				mock.Anything,
+				mock.Anything,
 			).Return(itemBackupper)
 
 			client := &arktest.FakeDynamicClient{}
@@ -478,7 +491,7 @@ func TestBackupResourceOnlyIncludesSpecifiedNamespaces(t *testing.T) {
 	resources := collections.NewIncludesExcludes().Includes("*")
 
 	labelSelector := "foo=bar"
-	backedUpItems := map[itemKey]struct{}{}
+	backedUpItems := newBackedUpItemSet()
 
 	dynamicFactory := &arktest.FakeDynamicFactory{}
 	defer dynamicFactory.AssertExpectations(t)
@@ -491,7 +504,7 @@ func TestBackupResourceOnlyIncludesSpecifiedNamespaces(t *testing.T) {
 
 	resourceHooks := []resourceHook{}
 
-	podCommandExecutor := &mockPodCommandExecutor{}
+	podCommandExecutor := &arktest.MockPodCommandExecutor{}
 	defer podCommandExecutor.AssertExpectations(t)
 
 	tarWriter := &fakeTarWriter{}
@@ -511,6 +524,9 @@ func TestBackupResourceOnlyIncludesSpecifiedNamespaces(t *testing.T) {
 		tarWriter,
 		resourceHooks,
 		nil,
+		nil,
+		&progressTracker{},
+		1,
 	).(*defaultResourceBackupper)
 
 	itemBackupperFactory := &mockItemBackupperFactory{}
@@ -526,7 +542,6 @@ func TestBackupResourceOnlyIncludesSpecifiedNamespaces(t *testing.T) {
 		resources:       resources,
 		backedUpItems:   backedUpItems,
 		actions:         actions,
-		tarWriter:       tarWriter,
 		resourceHooks:   resourceHooks,
 		dynamicFactory:  dynamicFactory,
 		discoveryHelper: discoveryHelper,
@@ -541,11 +556,11 @@ func TestBackupResourceOnlyIncludesSpecifiedNamespaces(t *testing.T) {
 		backedUpItems,
 		actions,
 		podCommandExecutor,
-		tarWriter,
 		resourceHooks,
 		dynamicFactory,
 		discoveryHelper,
 		mock.Anything,
+		mock.Anything,
 	).Return(itemBackupper)
 
 	client := &arktest.FakeDynamicClient{}
@@ -556,7 +571,8 @@ func TestBackupResourceOnlyIncludesSpecifiedNamespaces(t *testing.T) {
 	ns1 := unstructuredOrDie(`{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"ns-1"}}`)
 	client.On("Get", "ns-1", metav1.GetOptions{}).Return(ns1, nil)
 
-	itemHookHandler.On("handleHooks", mock.Anything, schema.GroupResource{Group: "", Resource: "namespaces"}, ns1, resourceHooks).Return(nil)
+	itemHookHandler.On("handleHooks", mock.Anything, schema.GroupResource{Group: "", Resource: "namespaces"}, ns1, resourceHooks, hookPhasePre).Return(nil)
+	itemHookHandler.On("handleHooks", mock.Anything, schema.GroupResource{Group: "", Resource: "namespaces"}, ns1, resourceHooks, hookPhasePost).Return(nil)
 
 	err := rb.backupResource(v1Group, namespacesResource)
 	require.NoError(t, err)
@@ -572,7 +588,7 @@ func TestBackupResourceListAllNamespacesExcludesCorrectly(t *testing.T) {
 	resources := collections.NewIncludesExcludes().Includes("*")
 
 	labelSelector := "foo=bar"
-	backedUpItems := map[itemKey]struct{}{}
+	backedUpItems := newBackedUpItemSet()
 
 	dynamicFactory := &arktest.FakeDynamicFactory{}
 	defer dynamicFactory.AssertExpectations(t)
@@ -585,7 +601,7 @@ func TestBackupResourceListAllNamespacesExcludesCorrectly(t *testing.T) {
 
 	resourceHooks := []resourceHook{}
 
-	podCommandExecutor := &mockPodCommandExecutor{}
+	podCommandExecutor := &arktest.MockPodCommandExecutor{}
 	defer podCommandExecutor.AssertExpectations(t)
 
 	tarWriter := &fakeTarWriter{}
@@ -605,6 +621,9 @@ func TestBackupResourceListAllNamespacesExcludesCorrectly(t *testing.T) {
 		tarWriter,
 		resourceHooks,
 		nil,
+		nil,
+		&progressTracker{},
+		1,
 	).(*defaultResourceBackupper)
 
 	itemBackupperFactory := &mockItemBackupperFactory{}
@@ -624,11 +643,11 @@ func TestBackupResourceListAllNamespacesExcludesCorrectly(t *testing.T) {
 		backedUpItems,
 		actions,
 		podCommandExecutor,
-		tarWriter,
 		resourceHooks,
 		dynamicFactory,
 		discoveryHelper,
 		mock.Anything,
+		mock.Anything,
 	).Return(itemBackupper)
 
 	client := &arktest.FakeDynamicClient{}
@@ -644,7 +663,7 @@ func TestBackupResourceListAllNamespacesExcludesCorrectly(t *testing.T) {
 	}
 	client.On("List", metav1.ListOptions{LabelSelector: labelSelector}).Return(list, nil)
 
-	itemBackupper.On("backupItem", mock.AnythingOfType("*logrus.Entry"), ns2, namespacesGroupResource).Return(nil)
+	itemBackupper.On("backupItem", mock.AnythingOfType("*logrus.Entry"), ns2, namespacesGroupResource).Return(nil, nil)
 
 	err := rb.backupResource(v1Group, namespacesResource)
 	require.NoError(t, err)
@@ -657,14 +676,14 @@ type mockItemBackupperFactory struct {
 func (ibf *mockItemBackupperFactory) newItemBackupper(
 	backup *v1.Backup,
 	namespaces, resources *collections.IncludesExcludes,
-	backedUpItems map[itemKey]struct{},
+	backedUpItems *backedUpItemSet,
 	actions []resolvedAction,
-	podCommandExecutor podCommandExecutor,
-	tarWriter tarWriter,
+	podCommandExecutor podexec.PodCommandExecutor,
 	resourceHooks []resourceHook,
 	dynamicFactory client.DynamicFactory,
 	discoveryHelper discovery.Helper,
 	snapshotService cloudprovider.SnapshotService,
+	podVolumeBackupper podvolume.Backupper,
 ) ItemBackupper {
 	args := ibf.Called(
 		backup,
@@ -673,11 +692,11 @@ func (ibf *mockItemBackupperFactory) newItemBackupper(
 		backedUpItems,
 		actions,
 		podCommandExecutor,
-		tarWriter,
 		resourceHooks,
 		dynamicFactory,
 		discoveryHelper,
 		snapshotService,
+		podVolumeBackupper,
 	)
 	return args.Get(0).(ItemBackupper)
 }
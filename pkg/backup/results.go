@@ -0,0 +1,85 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// resultsHook is a logrus hook that records the warnings and errors logged during a backup
+// in BackupResults. Messages about items are recorded under the item's namespace, or as
+// cluster messages for cluster-scoped items; all other messages are recorded as Ark messages.
+type resultsHook struct {
+	mu       sync.Mutex
+	warnings api.BackupResult
+	errors   api.BackupResult
+}
+
+func (h *resultsHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.WarnLevel, logrus.ErrorLevel}
+}
+
+func (h *resultsHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	result := &h.errors
+	if entry.Level == logrus.WarnLevel {
+		result = &h.warnings
+	}
+
+	message := entry.Message
+	if err, ok := entry.Data[logrus.ErrorKey]; ok {
+		message = fmt.Sprintf("%s: %v", message, err)
+	}
+
+	name, isItem := entry.Data["name"]
+	if !isItem {
+		result.Ark = append(result.Ark, message)
+		return nil
+	}
+
+	if groupResource, ok := entry.Data["groupResource"]; ok {
+		message = fmt.Sprintf("%v %v: %s", groupResource, name, message)
+	} else {
+		message = fmt.Sprintf("%v: %s", name, message)
+	}
+
+	if namespace, ok := entry.Data["namespace"].(string); ok && namespace != "" {
+		if result.Namespaces == nil {
+			result.Namespaces = make(map[string][]string)
+		}
+		result.Namespaces[namespace] = append(result.Namespaces[namespace], message)
+	} else {
+		result.Cluster = append(result.Cluster, message)
+	}
+
+	return nil
+}
+
+// results returns the warnings and errors recorded so far.
+func (h *resultsHook) results() (api.BackupResult, api.BackupResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return *h.warnings.DeepCopy(), *h.errors.DeepCopy()
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+func TestResultsHook(t *testing.T) {
+	hook := &resultsHook{}
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	logger.Hooks.Add(hook)
+
+	log := logger.WithField("backup", "heptio-ark/backup-1")
+	itemLog := log.WithField("groupResource", "pods")
+
+	log.Info("not recorded")
+	log.WithError(errors.New("list failed")).Error("Error backing up group v1")
+	itemLog.WithFields(logrus.Fields{"namespace": "ns-1", "name": "pod-1"}).WithError(errors.New("hook failed")).Error("Error backing up item")
+	itemLog.WithFields(logrus.Fields{"namespace": "ns-1", "name": "pod-2"}).Warn("Skipping volume")
+	log.WithFields(logrus.Fields{"groupResource": "persistentvolumes", "namespace": "", "name": "pv-1"}).WithError(errors.New("snapshot failed")).Error("Error backing up item")
+
+	warnings, errs := hook.results()
+
+	assert.Equal(t, v1.BackupResult{
+		Namespaces: map[string][]string{
+			"ns-1": {"pods pod-2: Skipping volume"},
+		},
+	}, warnings)
+
+	assert.Equal(t, v1.BackupResult{
+		Ark:     []string{"Error backing up group v1: list failed"},
+		Cluster: []string{"persistentvolumes pv-1: Error backing up item: snapshot failed"},
+		Namespaces: map[string][]string{
+			"ns-1": {"pods pod-1: Error backing up item: hook failed"},
+		},
+	}, errs)
+}
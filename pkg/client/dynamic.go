@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 )
@@ -50,7 +51,7 @@ func (f *dynamicFactory) ClientForGroupVersionResource(gv schema.GroupVersion, r
 	// it only needs the group and version.
 	dynamicClient, err := f.clientPool.ClientForGroupVersionKind(gv.WithKind(""))
 	if err != nil {
-		return nil, errors.Wrapf(err, "error getting client for GroupVersion %s, Resource %s", gv.String, resource.String())
+		return nil, errors.Wrapf(err, "error getting client for GroupVersion %s, Resource %s", gv.String(), resource.String())
 	}
 
 	return &dynamicResourceClient{
@@ -82,12 +83,26 @@ type Getter interface {
 	Get(name string, opts metav1.GetOptions) (*unstructured.Unstructured, error)
 }
 
+// Patcher patches an object.
+type Patcher interface {
+	// Patch applies a patch of the given type to the named object.
+	Patch(name string, pt types.PatchType, data []byte) (*unstructured.Unstructured, error)
+}
+
+// Deleter deletes an object.
+type Deleter interface {
+	// Delete deletes the named object.
+	Delete(name string, opts *metav1.DeleteOptions) error
+}
+
 // Dynamic contains client methods that Ark needs for backing up and restoring resources.
 type Dynamic interface {
 	Creator
 	Lister
 	Watcher
 	Getter
+	Patcher
+	Deleter
 }
 
 // dynamicResourceClient implements Dynamic.
@@ -112,3 +127,11 @@ func (d *dynamicResourceClient) Watch(options metav1.ListOptions) (watch.Interfa
 func (d *dynamicResourceClient) Get(name string, opts metav1.GetOptions) (*unstructured.Unstructured, error) {
 	return d.resourceClient.Get(name, opts)
 }
+
+func (d *dynamicResourceClient) Patch(name string, pt types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	return d.resourceClient.Patch(name, pt, data)
+}
+
+func (d *dynamicResourceClient) Delete(name string, opts *metav1.DeleteOptions) error {
+	return d.resourceClient.Delete(name, opts)
+}
@@ -19,6 +19,7 @@ package aws
 import (
 	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -128,16 +129,19 @@ func (o *objectStore) GetObject(bucket string, key string) (io.ReadCloser, error
 	return res.Body, nil
 }
 
-func (o *objectStore) ListCommonPrefixes(bucket string, delimiter string) ([]string, error) {
+func (o *objectStore) ListCommonPrefixes(bucket, prefix, delimiter string) ([]string, error) {
 	req := &s3.ListObjectsV2Input{
 		Bucket:    &bucket,
+		Prefix:    &prefix,
 		Delimiter: &delimiter,
 	}
 
 	var ret []string
 	err := o.s3.ListObjectsV2Pages(req, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
-		for _, prefix := range page.CommonPrefixes {
-			ret = append(ret, *prefix.Prefix)
+		// S3 returns prefixes inclusive of the last delimiter. We need to strip
+		// it.
+		for _, commonPrefix := range page.CommonPrefixes {
+			ret = append(ret, strings.TrimSuffix(*commonPrefix.Prefix, delimiter))
 		}
 		return !lastPage
 	})
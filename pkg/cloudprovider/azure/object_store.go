@@ -17,6 +17,8 @@ limitations under the License.
 package azure
 
 import (
+	"encoding/base64"
+	"fmt"
 	"io"
 	"strings"
 	"time"
@@ -27,6 +29,11 @@ import (
 	"github.com/heptio/ark/pkg/cloudprovider"
 )
 
+// blockSize is the size of the blocks that objects are uploaded in. Uploading in blocks lets
+// objects be streamed into blob storage without knowing their size or buffering them entirely
+// in memory.
+const blockSize = 4 * 1024 * 1024
+
 type objectStore struct {
 	blobClient *storage.BlobStorageClient
 }
@@ -61,7 +68,29 @@ func (o *objectStore) PutObject(bucket string, key string, body io.Reader) error
 		return err
 	}
 
-	return errors.WithStack(blob.CreateBlockBlobFromReader(body, nil))
+	var (
+		blocks []storage.Block
+		chunk  = make([]byte, blockSize)
+	)
+	for {
+		n, err := io.ReadFull(body, chunk)
+		if n > 0 {
+			// block IDs must all be the same length within a blob
+			blockID := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%08d", len(blocks))))
+			if err := blob.PutBlock(blockID, chunk[0:n], nil); err != nil {
+				return errors.WithStack(err)
+			}
+			blocks = append(blocks, storage.Block{ID: blockID, Status: storage.BlockStatusUncommitted})
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return errors.WithStack(blob.PutBlockList(blocks, nil))
 }
 
 func (o *objectStore) GetObject(bucket string, key string) (io.ReadCloser, error) {
@@ -83,13 +112,14 @@ func (o *objectStore) GetObject(bucket string, key string) (io.ReadCloser, error
 	return res, nil
 }
 
-func (o *objectStore) ListCommonPrefixes(bucket string, delimiter string) ([]string, error) {
+func (o *objectStore) ListCommonPrefixes(bucket, prefix, delimiter string) ([]string, error) {
 	container, err := getContainerReference(o.blobClient, bucket)
 	if err != nil {
 		return nil, err
 	}
 
 	params := storage.ListBlobsParameters{
+		Prefix:    prefix,
 		Delimiter: delimiter,
 	}
 
@@ -101,8 +131,8 @@ func (o *objectStore) ListCommonPrefixes(bucket string, delimiter string) ([]str
 	// Azure returns prefixes inclusive of the last delimiter. We need to strip
 	// it.
 	ret := make([]string, 0, len(res.BlobPrefixes))
-	for _, prefix := range res.BlobPrefixes {
-		ret = append(ret, prefix[0:strings.LastIndex(prefix, delimiter)])
+	for _, commonPrefix := range res.BlobPrefixes {
+		ret = append(ret, commonPrefix[0:strings.LastIndex(commonPrefix, delimiter)])
 	}
 
 	return ret, nil
@@ -37,10 +37,27 @@ import (
 // BackupService contains methods for working with backups in object storage.
 type BackupService interface {
 	BackupGetter
-	// UploadBackup uploads the specified Ark backup of a set of Kubernetes API objects, whose manifests are
-	// stored in the specified file, into object storage in an Ark bucket, tagged with Ark metadata. Returns
-	// an error if a problem is encountered accessing the file or performing the upload via the cloud API.
-	UploadBackup(bucket, name string, metadata, backup, log io.Reader) error
+	// UploadBackupContents uploads the gzipped tarball of an Ark backup's Kubernetes API objects into
+	// object storage in an Ark bucket. contents may be a stream that's still being written, in which case
+	// the upload completes once the stream is closed.
+	UploadBackupContents(bucket, name string, contents io.Reader) error
+
+	// UploadBackupLog uploads an Ark backup's gzipped log file into object storage. Like
+	// UploadBackupContents, log may be a stream that's still being written.
+	UploadBackupLog(bucket, name string, log io.Reader) error
+
+	// UploadBackupResults uploads an Ark backup's gzipped results file, listing the warnings and
+	// errors encountered while backing up individual items, into object storage.
+	UploadBackupResults(bucket, name string, results io.Reader) error
+
+	// UploadBackupContentsIndex uploads an Ark backup's gzipped contents index, listing the items in
+	// its tarball, into object storage.
+	UploadBackupContentsIndex(bucket, name string, index io.Reader) error
+
+	// UploadBackupMetadata uploads an Ark backup's metadata into object storage. Backups are only
+	// considered to exist in object storage once their metadata has been uploaded, so it should be
+	// uploaded after the backup's contents.
+	UploadBackupMetadata(bucket, name string, metadata io.Reader) error
 
 	// DownloadBackup downloads an Ark backup with the specified object key from object storage via the cloud API.
 	// It returns the snapshot metadata and data (separately), or an error if a problem is encountered
@@ -62,6 +79,46 @@ type BackupService interface {
 
 	// UploadRestoreResults uploads the restore's results file to object storage.
 	UploadRestoreResults(bucket, backup, restore string, results io.Reader) error
+
+	// UploadRestoreDryRunReport uploads a dry-run restore's report, listing what the restore would
+	// have done with each item, to object storage.
+	UploadRestoreDryRunReport(bucket, backup, restore string, report io.Reader) error
+
+	// UploadPodVolumeChunk uploads a chunk of pod volume file data, identified by id, into the
+	// bucket's pod volume chunk store. Chunks are shared by all backups in the bucket.
+	UploadPodVolumeChunk(bucket, id string, data io.Reader) error
+
+	// DownloadPodVolumeChunk downloads the pod volume file data chunk identified by id.
+	DownloadPodVolumeChunk(bucket, id string) (io.ReadCloser, error)
+
+	// ListPodVolumeChunks returns the IDs of all of the pod volume file data chunks in the bucket.
+	ListPodVolumeChunks(bucket string) ([]string, error)
+
+	// DeletePodVolumeChunk deletes the pod volume file data chunk identified by id.
+	DeletePodVolumeChunk(bucket, id string) error
+
+	// UploadPodVolumeLock uploads an empty lock object with the given name into the bucket's
+	// pod volume chunk store. Locks coordinate backups and prunes of the chunk store, including
+	// ones run by other clusters sharing the bucket.
+	UploadPodVolumeLock(bucket, name string) error
+
+	// ListPodVolumeLocks returns the names of all of the pod volume lock objects in the bucket.
+	ListPodVolumeLocks(bucket string) ([]string, error)
+
+	// DeletePodVolumeLock deletes the named pod volume lock object.
+	DeletePodVolumeLock(bucket, name string) error
+
+	// UploadPodVolumeManifest uploads the manifest of a pod volume snapshot taken for a backup.
+	// The manifest is stored with the rest of the backup's files, so it's deleted along with them.
+	UploadPodVolumeManifest(bucket, backup, snapshotID string, manifest io.Reader) error
+
+	// DownloadPodVolumeManifest downloads the manifest of a pod volume snapshot taken for a backup.
+	DownloadPodVolumeManifest(bucket, backup, snapshotID string) (io.ReadCloser, error)
+
+	// ListPodVolumeManifests returns the snapshot IDs of the pod volume manifests stored for each
+	// backup directory in the bucket, keyed by backup name. Unlike GetAllBackups, it includes
+	// backups whose metadata is missing or can't be read.
+	ListPodVolumeManifests(bucket string) (map[string][]string, error)
 }
 
 // BackupGetter knows how to list backups in object storage.
@@ -74,32 +131,88 @@ const (
 	metadataFileFormatString       = "%s/ark-backup.json"
 	backupFileFormatString         = "%s/%s.tar.gz"
 	backupLogFileFormatString      = "%s/%s-logs.gz"
+	backupResultsFileFormatString  = "%s/%s-results.gz"
+	contentsIndexFileFormatString  = "%s/%s-contents.gz"
 	restoreLogFileFormatString     = "%s/restore-%s-logs.gz"
 	restoreResultsFileFormatString = "%s/restore-%s-results.gz"
+	restoreDryRunFileFormatString  = "%s/restore-%s-dryrun.gz"
+	podVolumeManifestFormatString  = "%s/podvolumes/%s.gz"
+
+	// PodVolumesDirName is the name of the directory that pod volume file data is stored in.
+	// Without a prefix, it's among the backup directories, so no backup can have this name.
+	PodVolumesDirName = "podvolumes"
 )
 
-func getMetadataKey(backup string) string {
-	return fmt.Sprintf(metadataFileFormatString, backup)
+// getBackupsDir returns the directory that backup directories are stored in for the
+// given prefix. Without a prefix, backups are stored at the root of the bucket.
+func getBackupsDir(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "/backups/"
+}
+
+// getPodVolumesDir returns the directory that pod volume file data chunks are stored in for
+// the given prefix. It sits alongside the backups directory, or among the backup directories
+// if there's no prefix.
+func getPodVolumesDir(prefix string) string {
+	if prefix == "" {
+		return PodVolumesDirName + "/"
+	}
+	return prefix + "/" + PodVolumesDirName + "/"
+}
+
+func getPodVolumeChunkKey(prefix, id string) string {
+	return getPodVolumesDir(prefix) + "chunks/" + id
+}
+
+func getPodVolumeLockKey(prefix, name string) string {
+	return getPodVolumesDir(prefix) + "locks/" + name
+}
+
+func getBackupDir(prefix, backup string) string {
+	return getBackupsDir(prefix) + backup
 }
 
-func getBackupContentsKey(backup string) string {
-	return fmt.Sprintf(backupFileFormatString, backup, backup)
+func getMetadataKey(prefix, backup string) string {
+	return fmt.Sprintf(metadataFileFormatString, getBackupDir(prefix, backup))
 }
 
-func getBackupLogKey(backup string) string {
-	return fmt.Sprintf(backupLogFileFormatString, backup, backup)
+func getBackupContentsKey(prefix, backup string) string {
+	return fmt.Sprintf(backupFileFormatString, getBackupDir(prefix, backup), backup)
 }
 
-func getRestoreLogKey(backup, restore string) string {
-	return fmt.Sprintf(restoreLogFileFormatString, backup, restore)
+func getBackupLogKey(prefix, backup string) string {
+	return fmt.Sprintf(backupLogFileFormatString, getBackupDir(prefix, backup), backup)
 }
 
-func getRestoreResultsKey(backup, restore string) string {
-	return fmt.Sprintf(restoreResultsFileFormatString, backup, restore)
+func getBackupResultsKey(prefix, backup string) string {
+	return fmt.Sprintf(backupResultsFileFormatString, getBackupDir(prefix, backup), backup)
+}
+
+func getBackupContentsIndexKey(prefix, backup string) string {
+	return fmt.Sprintf(contentsIndexFileFormatString, getBackupDir(prefix, backup), backup)
+}
+
+func getPodVolumeManifestKey(prefix, backup, snapshotID string) string {
+	return fmt.Sprintf(podVolumeManifestFormatString, getBackupDir(prefix, backup), snapshotID)
+}
+
+func getRestoreLogKey(prefix, backup, restore string) string {
+	return fmt.Sprintf(restoreLogFileFormatString, getBackupDir(prefix, backup), restore)
+}
+
+func getRestoreResultsKey(prefix, backup, restore string) string {
+	return fmt.Sprintf(restoreResultsFileFormatString, getBackupDir(prefix, backup), restore)
+}
+
+func getRestoreDryRunReportKey(prefix, backup, restore string) string {
+	return fmt.Sprintf(restoreDryRunFileFormatString, getBackupDir(prefix, backup), restore)
 }
 
 type backupService struct {
 	objectStore ObjectStore
+	prefix      string
 	decoder     runtime.Decoder
 	logger      *logrus.Logger
 }
@@ -107,50 +220,46 @@ type backupService struct {
 var _ BackupService = &backupService{}
 var _ BackupGetter = &backupService{}
 
-// NewBackupService creates a backup service using the provided object store
-func NewBackupService(objectStore ObjectStore, logger *logrus.Logger) BackupService {
+// NewBackupService creates a backup service using the provided object store. If prefix is
+// non-empty, all backups are stored under <prefix>/backups/ in the bucket, and anything
+// outside of that directory is ignored.
+func NewBackupService(objectStore ObjectStore, prefix string, logger *logrus.Logger) BackupService {
 	return &backupService{
 		objectStore: objectStore,
+		prefix:      strings.Trim(prefix, "/"),
 		decoder:     scheme.Codecs.UniversalDecoder(api.SchemeGroupVersion),
 		logger:      logger,
 	}
 }
 
-func (br *backupService) UploadBackup(bucket, backupName string, metadata, backup, log io.Reader) error {
-	// upload metadata file
-	metadataKey := getMetadataKey(backupName)
-	if err := br.objectStore.PutObject(bucket, metadataKey, metadata); err != nil {
-		// failure to upload metadata file is a hard-stop
-		return err
-	}
+func (br *backupService) UploadBackupContents(bucket, backupName string, contents io.Reader) error {
+	return br.objectStore.PutObject(bucket, getBackupContentsKey(br.prefix, backupName), contents)
+}
 
-	// upload tar file
-	if err := br.objectStore.PutObject(bucket, getBackupContentsKey(backupName), backup); err != nil {
-		// try to delete the metadata file since the data upload failed
-		deleteErr := br.objectStore.DeleteObject(bucket, metadataKey)
+func (br *backupService) UploadBackupLog(bucket, backupName string, log io.Reader) error {
+	return br.objectStore.PutObject(bucket, getBackupLogKey(br.prefix, backupName), log)
+}
 
-		return kerrors.NewAggregate([]error{err, deleteErr})
-	}
+func (br *backupService) UploadBackupResults(bucket, backupName string, results io.Reader) error {
+	return br.objectStore.PutObject(bucket, getBackupResultsKey(br.prefix, backupName), results)
+}
 
-	// uploading log file is best-effort; if it fails, we log the error but call the overall upload a
-	// success
-	logKey := getBackupLogKey(backupName)
-	if err := br.objectStore.PutObject(bucket, logKey, log); err != nil {
-		br.logger.WithError(err).WithFields(logrus.Fields{
-			"bucket": bucket,
-			"key":    logKey,
-		}).Error("Error uploading log file")
-	}
+func (br *backupService) UploadBackupContentsIndex(bucket, backupName string, index io.Reader) error {
+	return br.objectStore.PutObject(bucket, getBackupContentsIndexKey(br.prefix, backupName), index)
+}
 
-	return nil
+func (br *backupService) UploadBackupMetadata(bucket, backupName string, metadata io.Reader) error {
+	return br.objectStore.PutObject(bucket, getMetadataKey(br.prefix, backupName), metadata)
 }
 
 func (br *backupService) DownloadBackup(bucket, backupName string) (io.ReadCloser, error) {
-	return br.objectStore.GetObject(bucket, getBackupContentsKey(backupName))
+	return br.objectStore.GetObject(bucket, getBackupContentsKey(br.prefix, backupName))
 }
 
 func (br *backupService) GetAllBackups(bucket string) ([]*api.Backup, error) {
-	prefixes, err := br.objectStore.ListCommonPrefixes(bucket, "/")
+	backupsDir := getBackupsDir(br.prefix)
+
+	prefixes, err := br.objectStore.ListCommonPrefixes(bucket, backupsDir, "/")
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +270,12 @@ func (br *backupService) GetAllBackups(bucket string) ([]*api.Backup, error) {
 	output := make([]*api.Backup, 0, len(prefixes))
 
 	for _, backupDir := range prefixes {
-		backup, err := br.GetBackup(bucket, backupDir)
+		// without a prefix, the pod volume chunk store is alongside the backup directories
+		if backupDir+"/" == getPodVolumesDir(br.prefix) {
+			continue
+		}
+
+		backup, err := br.GetBackup(bucket, strings.TrimPrefix(backupDir, backupsDir))
 		if err != nil {
 			br.logger.WithError(err).WithField("dir", backupDir).Error("Error reading backup directory")
 			continue
@@ -174,7 +288,7 @@ func (br *backupService) GetAllBackups(bucket string) ([]*api.Backup, error) {
 }
 
 func (br *backupService) GetBackup(bucket, name string) (*api.Backup, error) {
-	key := fmt.Sprintf(metadataFileFormatString, name)
+	key := getMetadataKey(br.prefix, name)
 
 	res, err := br.objectStore.GetObject(bucket, key)
 	if err != nil {
@@ -201,7 +315,7 @@ func (br *backupService) GetBackup(bucket, name string) (*api.Backup, error) {
 }
 
 func (br *backupService) DeleteBackupDir(bucket, backupName string) error {
-	objects, err := br.objectStore.ListObjects(bucket, backupName+"/")
+	objects, err := br.objectStore.ListObjects(bucket, getBackupDir(br.prefix, backupName)+"/")
 	if err != nil {
 		return err
 	}
@@ -223,15 +337,22 @@ func (br *backupService) DeleteBackupDir(bucket, backupName string) error {
 func (br *backupService) CreateSignedURL(target api.DownloadTarget, bucket string, ttl time.Duration) (string, error) {
 	switch target.Kind {
 	case api.DownloadTargetKindBackupContents:
-		return br.objectStore.CreateSignedURL(bucket, getBackupContentsKey(target.Name), ttl)
+		return br.objectStore.CreateSignedURL(bucket, getBackupContentsKey(br.prefix, target.Name), ttl)
 	case api.DownloadTargetKindBackupLog:
-		return br.objectStore.CreateSignedURL(bucket, getBackupLogKey(target.Name), ttl)
+		return br.objectStore.CreateSignedURL(bucket, getBackupLogKey(br.prefix, target.Name), ttl)
+	case api.DownloadTargetKindBackupResults:
+		return br.objectStore.CreateSignedURL(bucket, getBackupResultsKey(br.prefix, target.Name), ttl)
+	case api.DownloadTargetKindBackupContentsIndex:
+		return br.objectStore.CreateSignedURL(bucket, getBackupContentsIndexKey(br.prefix, target.Name), ttl)
 	case api.DownloadTargetKindRestoreLog:
 		backup := extractBackupName(target.Name)
-		return br.objectStore.CreateSignedURL(bucket, getRestoreLogKey(backup, target.Name), ttl)
+		return br.objectStore.CreateSignedURL(bucket, getRestoreLogKey(br.prefix, backup, target.Name), ttl)
 	case api.DownloadTargetKindRestoreResults:
 		backup := extractBackupName(target.Name)
-		return br.objectStore.CreateSignedURL(bucket, getRestoreResultsKey(backup, target.Name), ttl)
+		return br.objectStore.CreateSignedURL(bucket, getRestoreResultsKey(br.prefix, backup, target.Name), ttl)
+	case api.DownloadTargetKindRestoreDryRunReport:
+		backup := extractBackupName(target.Name)
+		return br.objectStore.CreateSignedURL(bucket, getRestoreDryRunReportKey(br.prefix, backup, target.Name), ttl)
 	default:
 		return "", errors.Errorf("unsupported download target kind %q", target.Kind)
 	}
@@ -247,15 +368,112 @@ func extractBackupName(s string) string {
 }
 
 func (br *backupService) UploadRestoreLog(bucket, backup, restore string, log io.Reader) error {
-	key := getRestoreLogKey(backup, restore)
+	key := getRestoreLogKey(br.prefix, backup, restore)
 	return br.objectStore.PutObject(bucket, key, log)
 }
 
 func (br *backupService) UploadRestoreResults(bucket, backup, restore string, results io.Reader) error {
-	key := getRestoreResultsKey(backup, restore)
+	key := getRestoreResultsKey(br.prefix, backup, restore)
 	return br.objectStore.PutObject(bucket, key, results)
 }
 
+func (br *backupService) UploadRestoreDryRunReport(bucket, backup, restore string, report io.Reader) error {
+	key := getRestoreDryRunReportKey(br.prefix, backup, restore)
+	return br.objectStore.PutObject(bucket, key, report)
+}
+
+func (br *backupService) UploadPodVolumeChunk(bucket, id string, data io.Reader) error {
+	return br.objectStore.PutObject(bucket, getPodVolumeChunkKey(br.prefix, id), data)
+}
+
+func (br *backupService) DownloadPodVolumeChunk(bucket, id string) (io.ReadCloser, error) {
+	return br.objectStore.GetObject(bucket, getPodVolumeChunkKey(br.prefix, id))
+}
+
+func (br *backupService) ListPodVolumeChunks(bucket string) ([]string, error) {
+	chunksDir := getPodVolumeChunkKey(br.prefix, "")
+
+	keys, err := br.objectStore.ListObjects(bucket, chunksDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, strings.TrimPrefix(key, chunksDir))
+	}
+
+	return ids, nil
+}
+
+func (br *backupService) DeletePodVolumeChunk(bucket, id string) error {
+	return br.objectStore.DeleteObject(bucket, getPodVolumeChunkKey(br.prefix, id))
+}
+
+func (br *backupService) UploadPodVolumeLock(bucket, name string) error {
+	return br.objectStore.PutObject(bucket, getPodVolumeLockKey(br.prefix, name), strings.NewReader(""))
+}
+
+func (br *backupService) ListPodVolumeLocks(bucket string) ([]string, error) {
+	locksDir := getPodVolumeLockKey(br.prefix, "")
+
+	keys, err := br.objectStore.ListObjects(bucket, locksDir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		names = append(names, strings.TrimPrefix(key, locksDir))
+	}
+
+	return names, nil
+}
+
+func (br *backupService) DeletePodVolumeLock(bucket, name string) error {
+	return br.objectStore.DeleteObject(bucket, getPodVolumeLockKey(br.prefix, name))
+}
+
+func (br *backupService) UploadPodVolumeManifest(bucket, backup, snapshotID string, manifest io.Reader) error {
+	return br.objectStore.PutObject(bucket, getPodVolumeManifestKey(br.prefix, backup, snapshotID), manifest)
+}
+
+func (br *backupService) DownloadPodVolumeManifest(bucket, backup, snapshotID string) (io.ReadCloser, error) {
+	return br.objectStore.GetObject(bucket, getPodVolumeManifestKey(br.prefix, backup, snapshotID))
+}
+
+func (br *backupService) ListPodVolumeManifests(bucket string) (map[string][]string, error) {
+	backupsDir := getBackupsDir(br.prefix)
+
+	prefixes, err := br.objectStore.ListCommonPrefixes(bucket, backupsDir, "/")
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make(map[string][]string, len(prefixes))
+	for _, backupDir := range prefixes {
+		if backupDir+"/" == getPodVolumesDir(br.prefix) {
+			continue
+		}
+		backup := strings.TrimPrefix(backupDir, backupsDir)
+
+		manifestsDir := getPodVolumeManifestKey(br.prefix, backup, "")
+		manifestsDir = strings.TrimSuffix(manifestsDir, ".gz")
+
+		keys, err := br.objectStore.ListObjects(bucket, manifestsDir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			snapshotID := strings.TrimSuffix(strings.TrimPrefix(key, manifestsDir), ".gz")
+			manifests[backup] = append(manifests[backup], snapshotID)
+		}
+	}
+
+	return manifests, nil
+}
+
 // cachedBackupService wraps a real backup service with a cache for getting cloud backups.
 type cachedBackupService struct {
 	BackupService
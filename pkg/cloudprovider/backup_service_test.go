@@ -29,6 +29,7 @@ import (
 	testutil "github.com/heptio/ark/pkg/util/test"
 	testlogger "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -40,76 +41,68 @@ import (
 
 func TestUploadBackup(t *testing.T) {
 	tests := []struct {
-		name                 string
-		metadata             io.ReadSeeker
-		metadataError        error
-		expectMetadataDelete bool
-		backup               io.ReadSeeker
-		backupError          error
-		log                  io.ReadSeeker
-		logError             error
-		expectedErr          string
+		name        string
+		prefix      string
+		upload      func(BackupService, io.Reader) error
+		expectedKey string
+		putError    error
 	}{
 		{
-			name:     "normal case",
-			metadata: newStringReadSeeker("foo"),
-			backup:   newStringReadSeeker("bar"),
-			log:      newStringReadSeeker("baz"),
+			name: "contents",
+			upload: func(s BackupService, r io.Reader) error {
+				return s.UploadBackupContents("test-bucket", "test-backup", r)
+			},
+			expectedKey: "test-backup/test-backup.tar.gz",
+		},
+		{
+			name:        "log",
+			upload:      func(s BackupService, r io.Reader) error { return s.UploadBackupLog("test-bucket", "test-backup", r) },
+			expectedKey: "test-backup/test-backup-logs.gz",
+		},
+		{
+			name: "results",
+			upload: func(s BackupService, r io.Reader) error {
+				return s.UploadBackupResults("test-bucket", "test-backup", r)
+			},
+			expectedKey: "test-backup/test-backup-results.gz",
 		},
 		{
-			name:          "error on metadata upload does not upload data or log",
-			metadata:      newStringReadSeeker("foo"),
-			metadataError: errors.New("md"),
-			expectedErr:   "md",
+			name: "metadata",
+			upload: func(s BackupService, r io.Reader) error {
+				return s.UploadBackupMetadata("test-bucket", "test-backup", r)
+			},
+			expectedKey: "test-backup/ark-backup.json",
 		},
 		{
-			name:                 "error on data upload deletes metadata",
-			metadata:             newStringReadSeeker("foo"),
-			backup:               newStringReadSeeker("bar"),
-			backupError:          errors.New("backup"),
-			expectMetadataDelete: true,
-			expectedErr:          "backup",
+			name:   "contents with prefix",
+			prefix: "ark",
+			upload: func(s BackupService, r io.Reader) error {
+				return s.UploadBackupContents("test-bucket", "test-backup", r)
+			},
+			expectedKey: "ark/backups/test-backup/test-backup.tar.gz",
 		},
 		{
-			name:     "error on log upload is ok",
-			metadata: newStringReadSeeker("foo"),
-			backup:   newStringReadSeeker("bar"),
-			log:      newStringReadSeeker("baz"),
-			logError: errors.New("log"),
+			name: "error is returned",
+			upload: func(s BackupService, r io.Reader) error {
+				return s.UploadBackupMetadata("test-bucket", "test-backup", r)
+			},
+			expectedKey: "test-backup/ark-backup.json",
+			putError:    errors.New("put"),
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			var (
-				objStore   = &testutil.ObjectStore{}
-				bucket     = "test-bucket"
-				backupName = "test-backup"
-				logger, _  = testlogger.NewNullLogger()
+				objStore  = &testutil.ObjectStore{}
+				body      = newStringReadSeeker("foo")
+				logger, _ = testlogger.NewNullLogger()
 			)
 
-			if test.metadata != nil {
-				objStore.On("PutObject", bucket, backupName+"/ark-backup.json", test.metadata).Return(test.metadataError)
-			}
-			if test.backup != nil {
-				objStore.On("PutObject", bucket, backupName+"/"+backupName+".tar.gz", test.backup).Return(test.backupError)
-			}
-			if test.log != nil {
-				objStore.On("PutObject", bucket, backupName+"/"+backupName+"-logs.gz", test.log).Return(test.logError)
-			}
-			if test.expectMetadataDelete {
-				objStore.On("DeleteObject", bucket, backupName+"/ark-backup.json").Return(nil)
-			}
-
-			backupService := NewBackupService(objStore, logger)
-
-			err := backupService.UploadBackup(bucket, backupName, test.metadata, test.backup, test.log)
+			objStore.On("PutObject", "test-bucket", test.expectedKey, body).Return(test.putError)
 
-			if test.expectedErr != "" {
-				assert.EqualError(t, err, test.expectedErr)
-			} else {
-				assert.NoError(t, err)
-			}
+			err := test.upload(NewBackupService(objStore, test.prefix, logger), body)
+			assert.Equal(t, test.putError, err)
 
 			objStore.AssertExpectations(t)
 		})
@@ -125,7 +118,7 @@ func TestDownloadBackup(t *testing.T) {
 	)
 	o.On("GetObject", bucket, backup+"/"+backup+".tar.gz").Return(ioutil.NopCloser(strings.NewReader("foo")), nil)
 
-	s := NewBackupService(o, logger)
+	s := NewBackupService(o, "", logger)
 	rc, err := s.DownloadBackup(bucket, backup)
 	require.NoError(t, err)
 	require.NotNil(t, rc)
@@ -138,6 +131,7 @@ func TestDownloadBackup(t *testing.T) {
 func TestDeleteBackup(t *testing.T) {
 	tests := []struct {
 		name             string
+		prefix           string
 		listObjectsError error
 		deleteErrors     []error
 		expectedErr      string
@@ -150,6 +144,10 @@ func TestDeleteBackup(t *testing.T) {
 			deleteErrors: []error{errors.New("a"), nil, errors.New("c")},
 			expectedErr:  "[a, c]",
 		},
+		{
+			name:   "with prefix",
+			prefix: "ark",
+		},
 	}
 
 	for _, test := range tests {
@@ -157,12 +155,13 @@ func TestDeleteBackup(t *testing.T) {
 			var (
 				bucket    = "bucket"
 				backup    = "bak"
-				objects   = []string{"bak/ark-backup.json", "bak/bak.tar.gz", "bak/bak.log.gz"}
+				backupDir = getBackupDir(test.prefix, backup)
+				objects   = []string{backupDir + "/ark-backup.json", backupDir + "/bak.tar.gz", backupDir + "/bak.log.gz"}
 				objStore  = &testutil.ObjectStore{}
 				logger, _ = testlogger.NewNullLogger()
 			)
 
-			objStore.On("ListObjects", bucket, backup+"/").Return(objects, test.listObjectsError)
+			objStore.On("ListObjects", bucket, backupDir+"/").Return(objects, test.listObjectsError)
 			for i, o := range objects {
 				var err error
 				if i < len(test.deleteErrors) {
@@ -172,7 +171,7 @@ func TestDeleteBackup(t *testing.T) {
 				objStore.On("DeleteObject", bucket, o).Return(err)
 			}
 
-			backupService := NewBackupService(objStore, logger)
+			backupService := NewBackupService(objStore, test.prefix, logger)
 
 			err := backupService.DeleteBackupDir(bucket, backup)
 
@@ -190,6 +189,7 @@ func TestDeleteBackup(t *testing.T) {
 func TestGetAllBackups(t *testing.T) {
 	tests := []struct {
 		name        string
+		prefix      string
 		storageData map[string][]byte
 		expectedRes []*api.Backup
 		expectedErr string
@@ -224,21 +224,48 @@ func TestGetAllBackups(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "backups are read from under the prefix",
+			prefix: "/ark/cluster-1/",
+			storageData: map[string][]byte{
+				"ark/cluster-1/backups/backup-1/ark-backup.json": encodeToBytes(&api.Backup{ObjectMeta: metav1.ObjectMeta{Name: "backup-1"}}),
+				"ark/cluster-1/backups/backup-2/ark-backup.json": encodeToBytes(&api.Backup{ObjectMeta: metav1.ObjectMeta{Name: "backup-2"}}),
+			},
+			expectedRes: []*api.Backup{
+				{
+					TypeMeta:   metav1.TypeMeta{Kind: "Backup", APIVersion: "ark.heptio.com/v1"},
+					ObjectMeta: metav1.ObjectMeta{Name: "backup-1"},
+				},
+				{
+					TypeMeta:   metav1.TypeMeta{Kind: "Backup", APIVersion: "ark.heptio.com/v1"},
+					ObjectMeta: metav1.ObjectMeta{Name: "backup-2"},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			var (
-				bucket    = "bucket"
-				objStore  = &testutil.ObjectStore{}
-				logger, _ = testlogger.NewNullLogger()
+				bucket     = "bucket"
+				backupsDir = getBackupsDir(strings.Trim(test.prefix, "/"))
+				objStore   = &testutil.ObjectStore{}
+				logger, _  = testlogger.NewNullLogger()
 			)
 
-			objStore.On("ListCommonPrefixes", bucket, "/").Return([]string{"backup-1", "backup-2"}, nil)
-			objStore.On("GetObject", bucket, "backup-1/ark-backup.json").Return(ioutil.NopCloser(bytes.NewReader(test.storageData["backup-1/ark-backup.json"])), nil)
-			objStore.On("GetObject", bucket, "backup-2/ark-backup.json").Return(ioutil.NopCloser(bytes.NewReader(test.storageData["backup-2/ark-backup.json"])), nil)
+			// without a prefix, the pod volume chunk store is listed along with the backups
+			prefixes := []string{backupsDir + "backup-1", backupsDir + "backup-2"}
+			if test.prefix == "" {
+				prefixes = append(prefixes, "podvolumes")
+			}
 
-			backupService := NewBackupService(objStore, logger)
+			objStore.On("ListCommonPrefixes", bucket, backupsDir, "/").Return(prefixes, nil)
+			for _, backup := range []string{"backup-1", "backup-2"} {
+				key := backupsDir + backup + "/ark-backup.json"
+				objStore.On("GetObject", bucket, key).Return(ioutil.NopCloser(bytes.NewReader(test.storageData[key])), nil)
+			}
+
+			backupService := NewBackupService(objStore, test.prefix, logger)
 
 			res, err := backupService.GetAllBackups(bucket)
 
@@ -258,6 +285,7 @@ func TestGetAllBackups(t *testing.T) {
 func TestCreateSignedURL(t *testing.T) {
 	tests := []struct {
 		name        string
+		prefix      string
 		targetKind  api.DownloadTargetKind
 		targetName  string
 		expectedKey string
@@ -274,6 +302,18 @@ func TestCreateSignedURL(t *testing.T) {
 			targetName:  "my-backup",
 			expectedKey: "my-backup/my-backup-logs.gz",
 		},
+		{
+			name:        "backup results",
+			targetKind:  api.DownloadTargetKindBackupResults,
+			targetName:  "my-backup",
+			expectedKey: "my-backup/my-backup-results.gz",
+		},
+		{
+			name:        "backup contents index",
+			targetKind:  api.DownloadTargetKindBackupContentsIndex,
+			targetName:  "my-backup",
+			expectedKey: "my-backup/my-backup-contents.gz",
+		},
 		{
 			name:        "scheduled backup contents",
 			targetKind:  api.DownloadTargetKindBackupContents,
@@ -322,6 +362,26 @@ func TestCreateSignedURL(t *testing.T) {
 			targetName:  "b-cool-20170913154901-20170913154902",
 			expectedKey: "b-cool-20170913154901/restore-b-cool-20170913154901-20170913154902-results.gz",
 		},
+		{
+			name:        "restore dry-run report",
+			targetKind:  api.DownloadTargetKindRestoreDryRunReport,
+			targetName:  "b-cool-20170913154901",
+			expectedKey: "b-cool/restore-b-cool-20170913154901-dryrun.gz",
+		},
+		{
+			name:        "backup contents with prefix",
+			prefix:      "ark",
+			targetKind:  api.DownloadTargetKindBackupContents,
+			targetName:  "my-backup",
+			expectedKey: "ark/backups/my-backup/my-backup.tar.gz",
+		},
+		{
+			name:        "restore log with prefix",
+			prefix:      "ark",
+			targetKind:  api.DownloadTargetKindRestoreLog,
+			targetName:  "b-20170913154901",
+			expectedKey: "ark/backups/b/restore-b-20170913154901-logs.gz",
+		},
 	}
 
 	for _, test := range tests {
@@ -329,7 +389,7 @@ func TestCreateSignedURL(t *testing.T) {
 			var (
 				objectStorage = &testutil.ObjectStore{}
 				logger, _     = testlogger.NewNullLogger()
-				backupService = NewBackupService(objectStorage, logger)
+				backupService = NewBackupService(objectStorage, test.prefix, logger)
 			)
 
 			target := api.DownloadTarget{
@@ -345,6 +405,68 @@ func TestCreateSignedURL(t *testing.T) {
 	}
 }
 
+func TestPodVolumeKeys(t *testing.T) {
+	tests := []struct {
+		name                string
+		prefix              string
+		expectedBackupsDir  string
+		expectedChunksDir   string
+		expectedManifestKey string
+	}{
+		{
+			name:                "no prefix",
+			expectedChunksDir:   "podvolumes/chunks/",
+			expectedManifestKey: "my-backup/podvolumes/snapshot-1.gz",
+		},
+		{
+			name:                "with prefix",
+			prefix:              "ark",
+			expectedBackupsDir:  "ark/backups/",
+			expectedChunksDir:   "ark/podvolumes/chunks/",
+			expectedManifestKey: "ark/backups/my-backup/podvolumes/snapshot-1.gz",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				objStore      = &testutil.ObjectStore{}
+				logger, _     = testlogger.NewNullLogger()
+				backupService = NewBackupService(objStore, test.prefix, logger)
+			)
+
+			objStore.On("PutObject", "bucket", test.expectedChunksDir+"abc", mock.Anything).Return(nil)
+			objStore.On("ListObjects", "bucket", test.expectedChunksDir).Return([]string{test.expectedChunksDir + "abc", test.expectedChunksDir + "def"}, nil)
+			objStore.On("DeleteObject", "bucket", test.expectedChunksDir+"def").Return(nil)
+			objStore.On("PutObject", "bucket", test.expectedManifestKey, mock.Anything).Return(nil)
+
+			backupDirs := []string{test.expectedBackupsDir + "my-backup", test.expectedBackupsDir + "other-backup"}
+			if test.prefix == "" {
+				// without a prefix, the chunk store is listed among the backup directories
+				backupDirs = append(backupDirs, "podvolumes")
+			}
+			objStore.On("ListCommonPrefixes", "bucket", test.expectedBackupsDir, "/").Return(backupDirs, nil)
+			objStore.On("ListObjects", "bucket", test.expectedBackupsDir+"my-backup/podvolumes/").Return([]string{test.expectedManifestKey}, nil)
+			objStore.On("ListObjects", "bucket", test.expectedBackupsDir+"other-backup/podvolumes/").Return([]string{}, nil)
+
+			require.NoError(t, backupService.UploadPodVolumeChunk("bucket", "abc", bytes.NewReader(nil)))
+			require.NoError(t, backupService.UploadPodVolumeManifest("bucket", "my-backup", "snapshot-1", bytes.NewReader(nil)))
+
+			ids, err := backupService.ListPodVolumeChunks("bucket")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"abc", "def"}, ids)
+
+			require.NoError(t, backupService.DeletePodVolumeChunk("bucket", "def"))
+
+			manifests, err := backupService.ListPodVolumeManifests("bucket")
+			require.NoError(t, err)
+			assert.Equal(t, map[string][]string{"my-backup": {"snapshot-1"}}, manifests)
+
+			objStore.AssertExpectations(t)
+		})
+	}
+}
+
 func jsonMarshal(obj interface{}) []byte {
 	res, err := json.Marshal(obj)
 	if err != nil {
@@ -0,0 +1,300 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystem
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/heptio/ark/pkg/cloudprovider"
+)
+
+const (
+	snapshotDirKey = "snapshotDir"
+	volumeDirKey   = "volumeDir"
+	hostRootKey    = "hostRoot"
+	nodeNameKey    = "nodeName"
+
+	// nodeNameEnvVar is the environment variable the node's name is read from if it's not
+	// set in the config.
+	nodeNameEnvVar = "NODE_NAME"
+
+	snapshotIDPrefix = "snap-"
+	volumeIDPrefix   = "vol-"
+
+	// snapshotDataDir and snapshotInfoFile are the directory a snapshot's copy of its
+	// volume is stored in, and the file its metadata is stored in, within the snapshot's
+	// directory. The info file is written last, so snapshots that weren't completed aren't
+	// listed or restored.
+	snapshotDataDir  = "data"
+	snapshotInfoFile = "snapshot.json"
+)
+
+// blockStore snapshots hostPath and local PersistentVolumes, whose volume IDs are the paths
+// of their directories on the host, by copying their directories into the snapshot directory.
+// Files are reflinked rather than copied if the filesystem supports it. Volumes are restored
+// by copying a snapshot into a new directory in the volume directory.
+//
+// All paths are host paths. The host's filesystem, or the part of it containing the volumes,
+// snapshots and restored volumes, must be mounted into the Ark pod at hostRoot.
+//
+// A volume's availability zone is the name of the node it's on, if it's only on one node,
+// and only volumes on the Ark pod's node can be snapshotted.
+type blockStore struct {
+	snapshotDir string
+	volumeDir   string
+	hostRoot    string
+	nodeName    string
+}
+
+// snapshotInfo is the metadata stored with a snapshot.
+type snapshotInfo struct {
+	VolumeID string            `json:"volumeID"`
+	VolumeAZ string            `json:"volumeAZ,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+	Created  time.Time         `json:"created"`
+}
+
+func NewBlockStore() cloudprovider.BlockStore {
+	return &blockStore{}
+}
+
+func (b *blockStore) Init(config map[string]string) error {
+	var (
+		snapshotDir = config[snapshotDirKey]
+		volumeDir   = config[volumeDirKey]
+		hostRoot    = config[hostRootKey]
+		nodeName    = config[nodeNameKey]
+	)
+
+	if nodeName == "" {
+		nodeName = os.Getenv(nodeNameEnvVar)
+	}
+
+	if snapshotDir == "" {
+		return errors.Errorf("missing %s in filesystem configuration", snapshotDirKey)
+	}
+	if volumeDir == "" {
+		return errors.Errorf("missing %s in filesystem configuration", volumeDirKey)
+	}
+	if !filepath.IsAbs(snapshotDir) || !filepath.IsAbs(volumeDir) {
+		return errors.Errorf("%s and %s must be absolute paths", snapshotDirKey, volumeDirKey)
+	}
+
+	b.snapshotDir = filepath.Clean(snapshotDir)
+	b.volumeDir = filepath.Clean(volumeDir)
+	b.hostRoot = hostRoot
+	b.nodeName = nodeName
+
+	for _, dir := range []string{b.snapshotDir, b.volumeDir} {
+		if err := os.MkdirAll(b.localPath(dir), 0755); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+func (b *blockStore) CreateVolumeFromSnapshot(snapshotID, volumeType, volumeAZ string, iops *int64) (string, error) {
+	snapshotPath, err := b.snapshotPath(snapshotID)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := b.getSnapshotInfo(snapshotID); err != nil {
+		return "", err
+	}
+
+	id, err := newID(volumeIDPrefix)
+	if err != nil {
+		return "", err
+	}
+	volumeID := filepath.Join(b.volumeDir, id)
+
+	if err := copyTree(filepath.Join(snapshotPath, snapshotDataDir), b.localPath(volumeID)); err != nil {
+		os.RemoveAll(b.localPath(volumeID))
+		return "", errors.Wrapf(err, "error restoring snapshot %s", snapshotID)
+	}
+
+	return volumeID, nil
+}
+
+func (b *blockStore) GetVolumeInfo(volumeID, volumeAZ string) (string, *int64, error) {
+	if _, err := os.Stat(b.localPath(volumeID)); err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	// directories don't have a type or IOPS
+	return "", nil, nil
+}
+
+func (b *blockStore) IsVolumeReady(volumeID, volumeAZ string) (bool, error) {
+	if _, err := os.Stat(b.localPath(volumeID)); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return true, nil
+}
+
+func (b *blockStore) ListSnapshots(tagFilters map[string]string) ([]string, error) {
+	entries, err := ioutil.ReadDir(b.localPath(b.snapshotDir))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var ret []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), snapshotIDPrefix) {
+			continue
+		}
+
+		info, err := b.getSnapshotInfo(entry.Name())
+		if err != nil {
+			// incomplete snapshot
+			continue
+		}
+
+		if matchesTags(info.Tags, tagFilters) {
+			ret = append(ret, entry.Name())
+		}
+	}
+
+	return ret, nil
+}
+
+func (b *blockStore) CreateSnapshot(volumeID, volumeAZ string, tags map[string]string) (string, error) {
+	if !filepath.IsAbs(volumeID) {
+		return "", errors.Errorf("volume ID %s is not an absolute path", volumeID)
+	}
+
+	// the volume's path on this node may hold a different volume, or nothing at all
+	if volumeAZ != "" && volumeAZ != b.nodeName {
+		if b.nodeName == "" {
+			return "", errors.Errorf("volume %s is on node %s, and the node Ark is running on is unknown; set %s in the filesystem configuration or the %s environment variable", volumeID, volumeAZ, nodeNameKey, nodeNameEnvVar)
+		}
+		return "", errors.Errorf("volume %s is on node %s, not on node %s that Ark is running on", volumeID, volumeAZ, b.nodeName)
+	}
+
+	snapshotID, err := newID(snapshotIDPrefix)
+	if err != nil {
+		return "", err
+	}
+	snapshotPath, _ := b.snapshotPath(snapshotID)
+
+	if err := os.Mkdir(snapshotPath, 0700); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	if err := b.createSnapshot(snapshotPath, volumeID, volumeAZ, tags); err != nil {
+		os.RemoveAll(snapshotPath)
+		return "", err
+	}
+
+	return snapshotID, nil
+}
+
+func (b *blockStore) createSnapshot(snapshotPath, volumeID, volumeAZ string, tags map[string]string) error {
+	if err := copyTree(b.localPath(volumeID), filepath.Join(snapshotPath, snapshotDataDir)); err != nil {
+		return errors.Wrapf(err, "error copying volume %s", volumeID)
+	}
+
+	info, err := json.Marshal(&snapshotInfo{
+		VolumeID: volumeID,
+		VolumeAZ: volumeAZ,
+		Tags:     tags,
+		Created:  time.Now().UTC(),
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(ioutil.WriteFile(filepath.Join(snapshotPath, snapshotInfoFile), info, 0600))
+}
+
+func (b *blockStore) DeleteSnapshot(snapshotID string) error {
+	snapshotPath, err := b.snapshotPath(snapshotID)
+	if err != nil {
+		return err
+	}
+
+	return errors.WithStack(os.RemoveAll(snapshotPath))
+}
+
+func (b *blockStore) getSnapshotInfo(snapshotID string) (*snapshotInfo, error) {
+	snapshotPath, err := b.snapshotPath(snapshotID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(snapshotPath, snapshotInfoFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("snapshot %s not found", snapshotID)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	info := new(snapshotInfo)
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, errors.Wrapf(err, "error reading snapshot %s", snapshotID)
+	}
+
+	return info, nil
+}
+
+// snapshotPath returns the local path of the directory snapshotID is stored in.
+func (b *blockStore) snapshotPath(snapshotID string) (string, error) {
+	if !strings.HasPrefix(snapshotID, snapshotIDPrefix) || strings.ContainsAny(snapshotID, `/\.`) {
+		return "", errors.Errorf("invalid snapshot ID %q", snapshotID)
+	}
+
+	return b.localPath(filepath.Join(b.snapshotDir, snapshotID)), nil
+}
+
+// localPath returns the path that the host path hostPath is mounted at in the Ark pod.
+func (b *blockStore) localPath(hostPath string) string {
+	if b.hostRoot == "" {
+		return hostPath
+	}
+	return filepath.Join(b.hostRoot, hostPath)
+}
+
+func matchesTags(tags, filters map[string]string) bool {
+	for k, v := range filters {
+		if tags[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func newID(prefix string) (string, error) {
+	id := make([]byte, 8)
+	if _, err := io.ReadFull(rand.Reader, id); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return prefix + hex.EncodeToString(id), nil
+}
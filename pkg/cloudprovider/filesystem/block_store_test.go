@@ -0,0 +1,160 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystem
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, path, contents string, mode os.FileMode) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), mode))
+}
+
+func TestBlockStore(t *testing.T) {
+	hostRoot, err := ioutil.TempDir("", "ark-filesystem-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(hostRoot)
+
+	b := NewBlockStore().(*blockStore)
+	require.NoError(t, b.Init(map[string]string{
+		snapshotDirKey: "/ark/snapshots",
+		volumeDirKey:   "/ark/volumes",
+		hostRootKey:    hostRoot,
+	}))
+
+	// the volume ID is the volume's path on the host
+	volumeID := "/data/pv-1"
+	volumeDir := filepath.Join(hostRoot, volumeID)
+	modTime := time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	writeTestFile(t, filepath.Join(volumeDir, "file"), "contents", 0640)
+	writeTestFile(t, filepath.Join(volumeDir, "subdir", "nested"), "nested contents", 0600)
+	require.NoError(t, os.Symlink("subdir/nested", filepath.Join(volumeDir, "link")))
+	require.NoError(t, os.Chtimes(filepath.Join(volumeDir, "file"), modTime, modTime))
+
+	snapshotID, err := b.CreateSnapshot(volumeID, "", map[string]string{"tag-key": "ark-snapshot"})
+	require.NoError(t, err)
+
+	// changes to the volume after the snapshot don't affect it
+	writeTestFile(t, filepath.Join(volumeDir, "file"), "changed", 0640)
+
+	snapshots, err := b.ListSnapshots(map[string]string{"tag-key": "ark-snapshot"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{snapshotID}, snapshots)
+
+	snapshots, err = b.ListSnapshots(map[string]string{"tag-key": "other"})
+	require.NoError(t, err)
+	assert.Empty(t, snapshots)
+
+	restoredID, err := b.CreateVolumeFromSnapshot(snapshotID, "", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/ark/volumes", filepath.Dir(restoredID))
+
+	ready, err := b.IsVolumeReady(restoredID, "")
+	require.NoError(t, err)
+	assert.True(t, ready)
+
+	restoredDir := filepath.Join(hostRoot, restoredID)
+
+	data, err := ioutil.ReadFile(filepath.Join(restoredDir, "file"))
+	require.NoError(t, err)
+	assert.Equal(t, "contents", string(data))
+
+	info, err := os.Stat(filepath.Join(restoredDir, "file"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode())
+	assert.True(t, modTime.Equal(info.ModTime()))
+
+	data, err = ioutil.ReadFile(filepath.Join(restoredDir, "link"))
+	require.NoError(t, err)
+	assert.Equal(t, "nested contents", string(data))
+
+	link, err := os.Readlink(filepath.Join(restoredDir, "link"))
+	require.NoError(t, err)
+	assert.Equal(t, "subdir/nested", link)
+
+	require.NoError(t, b.DeleteSnapshot(snapshotID))
+
+	snapshots, err = b.ListSnapshots(map[string]string{"tag-key": "ark-snapshot"})
+	require.NoError(t, err)
+	assert.Empty(t, snapshots)
+
+	_, err = b.CreateVolumeFromSnapshot(snapshotID, "", "", nil)
+	assert.EqualError(t, err, "snapshot "+snapshotID+" not found")
+}
+
+func TestBlockStoreErrors(t *testing.T) {
+	assert.Error(t, NewBlockStore().Init(map[string]string{volumeDirKey: "/volumes"}))
+	assert.Error(t, NewBlockStore().Init(map[string]string{snapshotDirKey: "/snapshots"}))
+	assert.Error(t, NewBlockStore().Init(map[string]string{snapshotDirKey: "snapshots", volumeDirKey: "volumes"}))
+
+	hostRoot, err := ioutil.TempDir("", "ark-filesystem-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(hostRoot)
+
+	b := NewBlockStore()
+	require.NoError(t, b.Init(map[string]string{
+		snapshotDirKey: "/snapshots",
+		volumeDirKey:   "/volumes",
+		hostRootKey:    hostRoot,
+	}))
+
+	_, err = b.CreateSnapshot("/does/not/exist", "", nil)
+	assert.Error(t, err)
+
+	// the failed snapshot isn't left behind
+	entries, err := ioutil.ReadDir(filepath.Join(hostRoot, "snapshots"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	_, err = b.CreateSnapshot("relative", "", nil)
+	assert.Error(t, err)
+
+	// volumes on other nodes can't be snapshotted, even if the same path exists on this one
+	writeTestFile(t, filepath.Join(hostRoot, "data", "pv-1", "file"), "contents", 0600)
+
+	_, err = b.CreateSnapshot("/data/pv-1", "node-1", nil)
+	assert.EqualError(t, err, "volume /data/pv-1 is on node node-1, and the node Ark is running on is unknown; set nodeName in the filesystem configuration or the NODE_NAME environment variable")
+
+	require.NoError(t, b.Init(map[string]string{
+		snapshotDirKey: "/snapshots",
+		volumeDirKey:   "/volumes",
+		hostRootKey:    hostRoot,
+		nodeNameKey:    "node-2",
+	}))
+
+	_, err = b.CreateSnapshot("/data/pv-1", "node-1", nil)
+	assert.EqualError(t, err, "volume /data/pv-1 is on node node-1, not on node node-2 that Ark is running on")
+
+	snapshotID, err := b.CreateSnapshot("/data/pv-1", "node-2", nil)
+	require.NoError(t, err)
+	require.NoError(t, b.DeleteSnapshot(snapshotID))
+
+	for _, snapshotID := range []string{"", "snap-../../etc", "other"} {
+		_, err = b.CreateVolumeFromSnapshot(snapshotID, "", "", nil)
+		assert.Error(t, err)
+		assert.Error(t, b.DeleteSnapshot(snapshotID))
+	}
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystem
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// cloneFile returns an error, since reflinks aren't supported, so files are copied.
+func cloneFile(dst, src *os.File) error {
+	return errors.New("reflinks are not supported")
+}
+
+// fileOwner returns -1 for the user and group IDs, since file ownership isn't
+// represented by numeric IDs on Windows.
+func fileOwner(info os.FileInfo) (int, int) {
+	return -1, -1
+}
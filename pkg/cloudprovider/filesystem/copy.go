@@ -0,0 +1,142 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystem
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// copyTree copies the directory src to dst, which must not exist, preserving the ownership
+// (when running as root), permissions and modification times of its entries. Regular files
+// are reflinked if the filesystem supports it, and copied otherwise. Symlinks are copied as
+// symlinks, and other file types are skipped.
+func copyTree(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !info.IsDir() {
+		return errors.Errorf("%s is not a directory", src)
+	}
+
+	if _, err := os.Lstat(dst); err == nil {
+		return errors.Errorf("%s already exists", dst)
+	}
+
+	// directories' modification times are set after they've been populated, since adding
+	// entries to a directory changes its modification time
+	var dirs []string
+
+	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			if err := os.Mkdir(target, info.Mode().Perm()); err != nil {
+				return err
+			}
+			dirs = append(dirs, path)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err := os.Symlink(link, target); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			if err := copyFile(path, target, info); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+
+		return setMetadata(target, info)
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		info, err := os.Stat(dirs[i])
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		rel, _ := filepath.Rel(src, dirs[i])
+		if err := os.Chtimes(filepath.Join(dst, rel), info.ModTime(), info.ModTime()); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+func copyFile(src, dst string, info os.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if err := cloneFile(out, in); err != nil {
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+	}
+
+	return out.Close()
+}
+
+// setMetadata sets the ownership, permissions and modification time of path to those
+// described by info.
+func setMetadata(path string, info os.FileInfo) error {
+	if uid, gid := fileOwner(info); uid >= 0 && os.Geteuid() == 0 {
+		if err := os.Lchown(path, uid, gid); err != nil {
+			return err
+		}
+	}
+
+	// permissions and times can't be set on symlinks themselves
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+
+	if err := os.Chmod(path, info.Mode()&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky)); err != nil {
+		return err
+	}
+
+	return os.Chtimes(path, info.ModTime(), info.ModTime())
+}
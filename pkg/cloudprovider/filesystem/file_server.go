@@ -0,0 +1,246 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystem
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// signingKeyFile is the file in the root directory that holds the key URLs are signed
+	// with. It's created the first time the directory is used, so the Ark server and its
+	// plugins, which run in separate processes, share the key without any configuration.
+	signingKeyFile = ".ark-signing-key"
+	signingKeySize = 32
+
+	expiresParam   = "expires"
+	signatureParam = "signature"
+)
+
+var (
+	errInvalidSignature = errors.New("invalid signature")
+	errExpired          = errors.New("URL has expired")
+)
+
+// signer signs and verifies the URLs the file server serves objects at.
+type signer struct {
+	key []byte
+}
+
+// newSigner returns a signer that uses the signing key in rootDir, creating it if it doesn't
+// exist.
+func newSigner(rootDir string) (*signer, error) {
+	keyPath := filepath.Join(rootDir, signingKeyFile)
+
+	key, err := ioutil.ReadFile(keyPath)
+	if os.IsNotExist(err) {
+		key, err = createSigningKey(keyPath)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading URL signing key")
+	}
+	if len(key) != signingKeySize {
+		return nil, errors.Errorf("URL signing key %s must be %d bytes, got %d", keyPath, signingKeySize, len(key))
+	}
+
+	return &signer{key: key}, nil
+}
+
+// createSigningKey writes a new random key to keyPath, unless another process has already
+// created one, and returns the key in keyPath.
+func createSigningKey(keyPath string) ([]byte, error) {
+	key := make([]byte, signingKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(keyPath), tempFilePrefix)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(key); err != nil {
+		file.Close()
+		return nil, errors.WithStack(err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// linking fails if the key already exists, so concurrent callers all end up using the
+	// same key
+	if err := os.Link(file.Name(), keyPath); err != nil && !os.IsExist(err) {
+		return nil, errors.WithStack(err)
+	}
+
+	return ioutil.ReadFile(keyPath)
+}
+
+func (s *signer) sign(bucket, key string, expires time.Time) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(bucket + "/" + key + "\n" + formatExpires(expires)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify returns an error if signature isn't valid for bucket and key, or has expired.
+func (s *signer) verify(bucket, key, expires, signature string) error {
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return errors.New("invalid expiry")
+	}
+	expiresTime := time.Unix(expiresUnix, 0)
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(bucket, key, expiresTime))) {
+		return errInvalidSignature
+	}
+	if time.Now().After(expiresTime) {
+		return errExpired
+	}
+
+	return nil
+}
+
+func formatExpires(expires time.Time) string {
+	return strconv.FormatInt(expires.Unix(), 10)
+}
+
+// fileServer serves the objects in filesystem ObjectStores at the URLs returned by their
+// CreateSignedURL. Each root directory has its own signing key, so a URL is served from the root
+// directory whose key it was signed with.
+type fileServer struct {
+	getConfigs func() []map[string]string
+	logger     logrus.FieldLogger
+
+	lock sync.Mutex
+	// signers caches the signer for each root directory.
+	signers map[string]*signer
+}
+
+// NewFileServer returns an http.Handler that serves GET requests for the URLs created by the
+// filesystem ObjectStores configured with the configs getConfigs returns. It's called for each
+// request, so ObjectStores can be added while the server's running. Requests that aren't signed,
+// or whose signatures have expired, are rejected.
+func NewFileServer(getConfigs func() []map[string]string, logger logrus.FieldLogger) http.Handler {
+	return &fileServer{
+		getConfigs: getConfigs,
+		logger:     logger,
+		signers:    make(map[string]*signer),
+	}
+}
+
+// rootDirFor returns the root directory whose signing key the URL for bucket and key was signed
+// with, or an error if it wasn't signed with any of their keys or has expired.
+func (s *fileServer) rootDirFor(bucket, key, expires, signature string) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	verifyErr := errInvalidSignature
+	for _, config := range s.getConfigs() {
+		rootDir := config[rootDirKey]
+		if rootDir == "" {
+			continue
+		}
+
+		signer, ok := s.signers[rootDir]
+		if !ok {
+			var err error
+			if signer, err = newSigner(rootDir); err != nil {
+				s.logger.WithError(err).WithField(rootDirKey, rootDir).Error("Error loading file server signing key")
+				continue
+			}
+			s.signers[rootDir] = signer
+		}
+
+		switch err := signer.verify(bucket, key, expires, signature); err {
+		case nil:
+			return rootDir, nil
+		case errInvalidSignature:
+		default:
+			verifyErr = err
+		}
+	}
+
+	return "", verifyErr
+}
+
+func (s *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	bucket, key := parts[0], parts[1]
+
+	logContext := s.logger.WithFields(logrus.Fields{"bucket": bucket, "key": key})
+
+	query := r.URL.Query()
+	rootDir, err := s.rootDirFor(bucket, key, query.Get(expiresParam), query.Get(signatureParam))
+	if err != nil {
+		logContext.WithError(err).Info("Rejecting file server request")
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	objectPath, err := objectPath(rootDir, bucket, key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	file, err := os.Open(objectPath)
+	if os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logContext.WithError(errors.WithStack(err)).Error("Error opening file")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		logContext.WithError(errors.WithStack(err)).Error("Error opening file")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, "", info.ModTime(), file)
+}
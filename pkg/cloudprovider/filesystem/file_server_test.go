@@ -0,0 +1,100 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystem
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	testlogger "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileServer(t *testing.T) {
+	o, cleanup := newTestObjectStore(t)
+	defer cleanup()
+	other, otherCleanup := newTestObjectStore(t)
+	defer otherCleanup()
+
+	require.NoError(t, o.PutObject("bucket", "backup-1/backup-1.tar.gz", bytes.NewReader([]byte("contents"))))
+	require.NoError(t, other.PutObject("bucket", "backup-1/backup-1.tar.gz", bytes.NewReader([]byte("other contents"))))
+
+	// the other store's root directory is only served once it's configured
+	configs := []map[string]string{{rootDirKey: o.rootDir}}
+	logger, _ := testlogger.NewNullLogger()
+	handler := NewFileServer(func() []map[string]string { return configs }, logger)
+
+	get := func(url string) (int, string) {
+		url = strings.TrimPrefix(url, o.fileServerURL)
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, url, nil))
+		body, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.Code, string(body)
+	}
+
+	signedURL, err := o.CreateSignedURL("bucket", "backup-1/backup-1.tar.gz", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(signedURL, "http://ark.heptio-ark:8086/bucket/backup-1/backup-1.tar.gz?"))
+
+	code, body := get(signedURL)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "contents", body)
+
+	// the signature doesn't match a different key
+	code, _ = get(strings.Replace(signedURL, "backup-1.tar.gz", "ark-backup.json", 1))
+	assert.Equal(t, http.StatusForbidden, code)
+
+	// or a tampered expiry
+	code, _ = get(strings.Replace(signedURL, "expires=", "expires=1", 1))
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = get("/bucket/backup-1/backup-1.tar.gz")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	expiredURL, err := o.CreateSignedURL("bucket", "backup-1/backup-1.tar.gz", -time.Minute)
+	require.NoError(t, err)
+	code, body = get(expiredURL)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "URL has expired\n", body)
+
+	missingURL, err := o.CreateSignedURL("bucket", "backup-2/backup-2.tar.gz", time.Minute)
+	require.NoError(t, err)
+	code, _ = get(missingURL)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// URLs are served from the root directory whose key signed them
+	otherURL, err := other.CreateSignedURL("bucket", "backup-1/backup-1.tar.gz", time.Minute)
+	require.NoError(t, err)
+	code, _ = get(otherURL)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	configs = append(configs, map[string]string{rootDirKey: other.rootDir})
+	code, body = get(otherURL)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "other contents", body)
+
+	code, body = get(signedURL)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "contents", body)
+}
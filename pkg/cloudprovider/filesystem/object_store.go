@@ -0,0 +1,289 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package filesystem implements an ObjectStore backed by a directory, which may be a mounted
+// NFS share, hostPath or PersistentVolume, and a BlockStore for hostPath and local
+// PersistentVolumes, for running Ark without a cloud provider.
+package filesystem
+
+import (
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/heptio/ark/pkg/cloudprovider"
+)
+
+const (
+	// ProviderName is the name the filesystem ObjectStore is registered under.
+	ProviderName = "filesystem"
+
+	rootDirKey       = "rootDir"
+	fileServerURLKey = "fileServerUrl"
+
+	// tempFilePrefix is the prefix of the files objects are written to before they're
+	// renamed into place, so partially-written objects are never read or listed.
+	tempFilePrefix = ".ark-tmp-"
+)
+
+// objectStore stores each bucket as a directory under rootDir, and each object as a file in
+// its bucket's directory, at the path given by its key.
+type objectStore struct {
+	rootDir       string
+	fileServerURL string
+	signer        *signer
+}
+
+func NewObjectStore() cloudprovider.ObjectStore {
+	return &objectStore{}
+}
+
+func (o *objectStore) Init(config map[string]string) error {
+	rootDir := config[rootDirKey]
+	if rootDir == "" {
+		return errors.Errorf("missing %s in filesystem configuration", rootDirKey)
+	}
+
+	if info, err := os.Stat(rootDir); err != nil {
+		return errors.WithStack(err)
+	} else if !info.IsDir() {
+		return errors.Errorf("%s %s is not a directory", rootDirKey, rootDir)
+	}
+
+	signer, err := newSigner(rootDir)
+	if err != nil {
+		return err
+	}
+
+	o.rootDir = rootDir
+	o.fileServerURL = strings.TrimSuffix(config[fileServerURLKey], "/")
+	o.signer = signer
+
+	return nil
+}
+
+func (o *objectStore) PutObject(bucket string, key string, body io.Reader) error {
+	objectPath, err := objectPath(o.rootDir, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(objectPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// write to a temporary file in the same directory, then rename it into place, so
+	// the object is replaced atomically
+	file, err := ioutil.TempFile(dir, tempFilePrefix)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		return errors.Wrapf(err, "error writing object %s", key)
+	}
+	if err := file.Close(); err != nil {
+		return errors.Wrapf(err, "error writing object %s", key)
+	}
+
+	return errors.WithStack(os.Rename(file.Name(), objectPath))
+}
+
+func (o *objectStore) GetObject(bucket string, key string) (io.ReadCloser, error) {
+	objectPath, err := objectPath(o.rootDir, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(objectPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting object %s", key)
+	}
+
+	return file, nil
+}
+
+func (o *objectStore) ListCommonPrefixes(bucket, prefix, delimiter string) ([]string, error) {
+	prefixes := make(map[string]struct{})
+	err := o.walk(bucket, prefix, func(key string) error {
+		i := strings.Index(key[len(prefix):], delimiter)
+		if i < 0 {
+			return nil
+		}
+		prefixes[key[:len(prefix)+i]] = struct{}{}
+
+		// the rest of this directory is under the same common prefix, so there's no need to
+		// walk it
+		if delimiter == "/" {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]string, 0, len(prefixes))
+	for prefix := range prefixes {
+		ret = append(ret, prefix)
+	}
+	sort.Strings(ret)
+
+	return ret, nil
+}
+
+func (o *objectStore) ListObjects(bucket, prefix string) ([]string, error) {
+	var ret []string
+	err := o.walk(bucket, prefix, func(key string) error {
+		ret = append(ret, key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// walk calls fn with the key of each object in bucket that starts with prefix, in lexical order.
+// Only the directories that can hold such objects are walked, starting with the directory the
+// prefix is in. If fn returns filepath.SkipDir, the rest of the object's directory is skipped.
+func (o *objectStore) walk(bucket, prefix string, fn func(key string) error) error {
+	bucketDir, err := bucketPath(o.rootDir, bucket)
+	if err != nil {
+		return err
+	}
+
+	startDir := bucketDir
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		if startDir, err = objectPath(o.rootDir, bucket, prefix[:i]); err != nil {
+			// no object's key can start with a prefix that isn't a valid path
+			return nil
+		}
+	}
+
+	// a bucket's directory is created when the first object is put in it, so until then
+	// the bucket is empty, and so are directories that don't exist
+	if _, err := os.Stat(startDir); os.IsNotExist(err) {
+		return nil
+	}
+
+	err = filepath.Walk(startDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(bucketDir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			if path == startDir {
+				return nil
+			}
+			if dirKey := key + "/"; !strings.HasPrefix(dirKey, prefix) && !strings.HasPrefix(prefix, dirKey) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if strings.HasPrefix(info.Name(), tempFilePrefix) || !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		return fn(key)
+	})
+
+	return errors.WithStack(err)
+}
+
+func (o *objectStore) DeleteObject(bucket string, key string) error {
+	objectPath, err := objectPath(o.rootDir, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(objectPath); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "error deleting object %s", key)
+	}
+
+	// remove the object's parent directories if they're now empty, like object storage
+	// does with prefixes. os.Remove fails for directories that aren't empty.
+	bucketDir, _ := bucketPath(o.rootDir, bucket)
+	for dir := filepath.Dir(objectPath); dir != bucketDir; dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (o *objectStore) CreateSignedURL(bucket, key string, ttl time.Duration) (string, error) {
+	if o.fileServerURL == "" {
+		return "", errors.Errorf("%s must be set in the filesystem configuration to download files", fileServerURLKey)
+	}
+
+	if _, err := objectPath(o.rootDir, bucket, key); err != nil {
+		return "", err
+	}
+
+	expires := time.Now().Add(ttl)
+
+	query := url.Values{}
+	query.Set(expiresParam, formatExpires(expires))
+	query.Set(signatureParam, o.signer.sign(bucket, key, expires))
+
+	u := url.URL{Path: "/" + bucket + "/" + key}
+
+	return o.fileServerURL + u.EscapedPath() + "?" + query.Encode(), nil
+}
+
+// bucketPath returns the directory that stores bucket.
+func bucketPath(rootDir, bucket string) (string, error) {
+	if bucket == "" || strings.ContainsAny(bucket, `/\`) || strings.HasPrefix(bucket, ".") {
+		return "", errors.Errorf("invalid bucket name %q", bucket)
+	}
+
+	return filepath.Join(rootDir, bucket), nil
+}
+
+// objectPath returns the file that stores the object with key in bucket. Keys that would
+// refer to a file outside of the bucket's directory are rejected.
+func objectPath(rootDir, bucket, key string) (string, error) {
+	bucketDir, err := bucketPath(rootDir, bucket)
+	if err != nil {
+		return "", err
+	}
+
+	if key == "" || strings.HasSuffix(key, "/") || path.Clean("/"+key) != "/"+key {
+		return "", errors.Errorf("invalid object key %q", key)
+	}
+
+	return filepath.Join(bucketDir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystem
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestObjectStore(t *testing.T) (*objectStore, func()) {
+	rootDir, err := ioutil.TempDir("", "ark-filesystem-test")
+	require.NoError(t, err)
+
+	o := NewObjectStore().(*objectStore)
+	require.NoError(t, o.Init(map[string]string{
+		rootDirKey:       rootDir,
+		fileServerURLKey: "http://ark.heptio-ark:8086/",
+	}))
+
+	return o, func() { os.RemoveAll(rootDir) }
+}
+
+func TestObjectStore(t *testing.T) {
+	o, cleanup := newTestObjectStore(t)
+	defer cleanup()
+
+	keys, err := o.ListObjects("bucket", "")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	for _, key := range []string{"backup-1/ark-backup.json", "backup-1/backup-1.tar.gz", "backup-2/ark-backup.json", "podvolumes/chunks/a", "podvolumes/chunks/b", "top-level"} {
+		require.NoError(t, o.PutObject("bucket", key, bytes.NewReader([]byte(key))))
+	}
+
+	// overwrite an existing object
+	require.NoError(t, o.PutObject("bucket", "top-level", bytes.NewReader([]byte("updated"))))
+
+	rc, err := o.GetObject("bucket", "top-level")
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	rc.Close()
+	assert.Equal(t, "updated", string(data))
+
+	_, err = o.GetObject("bucket", "missing")
+	assert.Error(t, err)
+
+	keys, err = o.ListObjects("bucket", "backup-1/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup-1/ark-backup.json", "backup-1/backup-1.tar.gz"}, keys)
+
+	keys, err = o.ListObjects("bucket", "backup-1/backup")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup-1/backup-1.tar.gz"}, keys)
+
+	keys, err = o.ListObjects("bucket", "podvolumes/chunks/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"podvolumes/chunks/a", "podvolumes/chunks/b"}, keys)
+
+	keys, err = o.ListObjects("bucket", "missing/")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	prefixes, err := o.ListCommonPrefixes("bucket", "", "/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup-1", "backup-2", "podvolumes"}, prefixes)
+
+	prefixes, err = o.ListCommonPrefixes("bucket", "backup-", "/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup-1", "backup-2"}, prefixes)
+
+	require.NoError(t, o.DeleteObject("bucket", "backup-2/ark-backup.json"))
+	// deleting an object that doesn't exist isn't an error
+	require.NoError(t, o.DeleteObject("bucket", "backup-2/ark-backup.json"))
+
+	// the now-empty directory is removed, so it's not listed as a prefix
+	_, err = os.Stat(filepath.Join(o.rootDir, "bucket", "backup-2"))
+	assert.True(t, os.IsNotExist(err))
+
+	prefixes, err = o.ListCommonPrefixes("bucket", "", "/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup-1", "podvolumes"}, prefixes)
+}
+
+func TestObjectStoreInvalidKeys(t *testing.T) {
+	o, cleanup := newTestObjectStore(t)
+	defer cleanup()
+
+	tests := []struct {
+		bucket string
+		key    string
+	}{
+		{bucket: "", key: "key"},
+		{bucket: "..", key: "key"},
+		{bucket: ".ark-signing-key", key: "key"},
+		{bucket: "a/b", key: "key"},
+		{bucket: "bucket", key: ""},
+		{bucket: "bucket", key: "dir/"},
+		{bucket: "bucket", key: "../key"},
+		{bucket: "bucket", key: "dir/../../key"},
+		{bucket: "bucket", key: "/key"},
+	}
+
+	for _, test := range tests {
+		err := o.PutObject(test.bucket, test.key, bytes.NewReader(nil))
+		assert.Error(t, err, "bucket %q, key %q", test.bucket, test.key)
+	}
+}
+
+func TestObjectStoreInit(t *testing.T) {
+	assert.Error(t, NewObjectStore().Init(map[string]string{}))
+	assert.Error(t, NewObjectStore().Init(map[string]string{rootDirKey: "/does/not/exist"}))
+
+	o, cleanup := newTestObjectStore(t)
+	defer cleanup()
+
+	// a second store using the same directory uses the same signing key
+	o2 := NewObjectStore().(*objectStore)
+	require.NoError(t, o2.Init(map[string]string{rootDirKey: o.rootDir}))
+	assert.Equal(t, o.signer.key, o2.signer.key)
+
+	_, err := o2.CreateSignedURL("bucket", "key", time.Minute)
+	assert.Error(t, err)
+}
@@ -103,8 +103,8 @@ func (o *objectStore) GetObject(bucket string, key string) (io.ReadCloser, error
 	return res.Body, nil
 }
 
-func (o *objectStore) ListCommonPrefixes(bucket string, delimiter string) ([]string, error) {
-	res, err := o.gcs.Objects.List(bucket).Delimiter(delimiter).Do()
+func (o *objectStore) ListCommonPrefixes(bucket, prefix, delimiter string) ([]string, error) {
+	res, err := o.gcs.Objects.List(bucket).Prefix(prefix).Delimiter(delimiter).Do()
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -112,8 +112,8 @@ func (o *objectStore) ListCommonPrefixes(bucket string, delimiter string) ([]str
 	// GCP returns prefixes inclusive of the last delimiter. We need to strip
 	// it.
 	ret := make([]string, 0, len(res.Prefixes))
-	for _, prefix := range res.Prefixes {
-		ret = append(ret, prefix[0:strings.LastIndex(prefix, delimiter)])
+	for _, commonPrefix := range res.Prefixes {
+		ret = append(ret, commonPrefix[0:strings.LastIndex(commonPrefix, delimiter)])
 	}
 
 	return ret, nil
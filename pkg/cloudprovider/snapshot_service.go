@@ -20,6 +20,11 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/heptio/ark/pkg/util/collections"
+	kubeutil "github.com/heptio/ark/pkg/util/kube"
 )
 
 // SnapshotService exposes Ark-specific operations for snapshotting and restoring block
@@ -46,6 +51,14 @@ type SnapshotService interface {
 
 	// GetVolumeInfo gets the type and IOPS (if applicable) from the cloud API.
 	GetVolumeInfo(volumeID, volumeAZ string) (string, *int64, error)
+
+	// GetVolumeID returns the cloud volume ID of the specified PersistentVolume, or an empty
+	// ID if its source isn't supported by the persistent volume provider.
+	GetVolumeID(pv runtime.Unstructured) (string, error)
+
+	// SetVolumeID sets the cloud volume ID of the specified PersistentVolume, and returns
+	// the updated PersistentVolume.
+	SetVolumeID(pv runtime.Unstructured, volumeID string) (runtime.Unstructured, error)
 }
 
 const (
@@ -57,14 +70,17 @@ const (
 
 type snapshotService struct {
 	blockStore BlockStore
+	provider   string
 }
 
 var _ SnapshotService = &snapshotService{}
 
-// NewSnapshotService creates a snapshot service using the provided block store
-func NewSnapshotService(blockStore BlockStore) SnapshotService {
+// NewSnapshotService creates a snapshot service using the provided block store, which
+// is the named persistent volume provider's.
+func NewSnapshotService(blockStore BlockStore, provider string) SnapshotService {
 	return &snapshotService{
 		blockStore: blockStore,
+		provider:   provider,
 	}
 }
 
@@ -120,3 +136,20 @@ func (sr *snapshotService) DeleteSnapshot(snapshotID string) error {
 func (sr *snapshotService) GetVolumeInfo(volumeID, volumeAZ string) (string, *int64, error) {
 	return sr.blockStore.GetVolumeInfo(volumeID, volumeAZ)
 }
+
+func (sr *snapshotService) GetVolumeID(pv runtime.Unstructured) (string, error) {
+	return kubeutil.GetVolumeID(pv.UnstructuredContent(), sr.provider)
+}
+
+func (sr *snapshotService) SetVolumeID(pv runtime.Unstructured, volumeID string) (runtime.Unstructured, error) {
+	spec, err := collections.GetMap(pv.UnstructuredContent(), "spec")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := kubeutil.SetVolumeID(spec, volumeID, sr.provider); err != nil {
+		return nil, err
+	}
+
+	return pv, nil
+}
@@ -37,10 +37,11 @@ type ObjectStore interface {
 	// bucket in object storage.
 	GetObject(bucket string, key string) (io.ReadCloser, error)
 
-	// ListCommonPrefixes gets a list of all object key prefixes that come
-	// before the provided delimiter (this is often used to simulate a directory
-	// hierarchy in object storage).
-	ListCommonPrefixes(bucket string, delimiter string) ([]string, error)
+	// ListCommonPrefixes gets a list of all object key prefixes that start
+	// with prefix and come before the provided delimiter (this is often used
+	// to simulate a directory hierarchy in object storage). The returned
+	// prefixes include prefix but not the delimiter.
+	ListCommonPrefixes(bucket, prefix, delimiter string) ([]string, error)
 
 	// ListObjects gets a list of all objects in bucket that have the same prefix.
 	ListObjects(bucket, prefix string) ([]string, error)
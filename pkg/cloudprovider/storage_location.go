@@ -0,0 +1,170 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+)
+
+// StorageLocation is an object storage bucket that backups are stored in, along with
+// the BackupService used to access it.
+type StorageLocation struct {
+	// Name is the name of the BackupStorageLocation, or api.DefaultBackupStorageLocation
+	// for the location configured by the Config's BackupStorageProvider.
+	Name string
+
+	// Bucket is the object storage bucket backups are stored in.
+	Bucket string
+
+	// BackupService is the BackupService used to access the bucket.
+	BackupService BackupService
+}
+
+// StorageLocationResolver resolves backup storage location names to StorageLocations.
+type StorageLocationResolver interface {
+	// Get returns the StorageLocation with the given name. An empty name refers to the
+	// default location.
+	Get(name string) (*StorageLocation, error)
+
+	// List returns the default StorageLocation followed by the StorageLocations for all
+	// BackupStorageLocations, sorted by name. If any BackupStorageLocations can't be
+	// resolved, the ones that could are returned along with an error.
+	List() ([]*StorageLocation, error)
+}
+
+// BackupServiceFactory returns a BackupService for accessing a BackupStorageLocation.
+type BackupServiceFactory func(location *api.BackupStorageLocation) (BackupService, error)
+
+type storageLocationResolver struct {
+	defaultLocation  *StorageLocation
+	lister           listers.BackupStorageLocationLister
+	newBackupService BackupServiceFactory
+
+	lock sync.Mutex
+	// locations caches the StorageLocation for each BackupStorageLocation, along with the
+	// resource version it was created for, so BackupServices are only created once per
+	// version of a location.
+	locations map[string]resolvedStorageLocation
+}
+
+type resolvedStorageLocation struct {
+	resourceVersion string
+	location        *StorageLocation
+}
+
+// NewStorageLocationResolver returns a StorageLocationResolver that resolves the default
+// location to defaultLocation, and any other names to the BackupStorageLocations with those
+// names in the Ark namespace, using newBackupService to access them.
+func NewStorageLocationResolver(
+	defaultLocation *StorageLocation,
+	lister listers.BackupStorageLocationLister,
+	newBackupService BackupServiceFactory,
+) StorageLocationResolver {
+	return &storageLocationResolver{
+		defaultLocation:  defaultLocation,
+		lister:           lister,
+		newBackupService: newBackupService,
+		locations:        make(map[string]resolvedStorageLocation),
+	}
+}
+
+func (r *storageLocationResolver) Get(name string) (*StorageLocation, error) {
+	if name == "" || name == api.DefaultBackupStorageLocation {
+		return r.defaultLocation, nil
+	}
+
+	location, err := r.lister.BackupStorageLocations(api.DefaultNamespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil, errors.Errorf("backup storage location %q does not exist", name)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return r.resolve(location)
+}
+
+func (r *storageLocationResolver) List() ([]*StorageLocation, error) {
+	locations, err := r.lister.BackupStorageLocations(api.DefaultNamespace).List(labels.Everything())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sort.Slice(locations, func(i, j int) bool { return locations[i].Name < locations[j].Name })
+
+	var (
+		res  = []*StorageLocation{r.defaultLocation}
+		errs []error
+	)
+
+	for _, location := range locations {
+		// the default location always comes from the Config
+		if location.Name == api.DefaultBackupStorageLocation {
+			continue
+		}
+
+		resolved, err := r.resolve(location)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		res = append(res, resolved)
+	}
+
+	return res, kerrors.NewAggregate(errs)
+}
+
+func (r *storageLocationResolver) resolve(location *api.BackupStorageLocation) (*StorageLocation, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if cached, ok := r.locations[location.Name]; ok && cached.resourceVersion == location.ResourceVersion {
+		return cached.location, nil
+	}
+
+	if location.Spec.Bucket == "" {
+		return nil, errors.Errorf("backup storage location %q must specify a bucket", location.Name)
+	}
+
+	backupService, err := r.newBackupService(location)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error accessing backup storage location %s", location.Name)
+	}
+
+	resolved := &StorageLocation{
+		Name:          location.Name,
+		Bucket:        location.Spec.Bucket,
+		BackupService: backupService,
+	}
+
+	r.locations[location.Name] = resolvedStorageLocation{
+		resourceVersion: location.ResourceVersion,
+		location:        resolved,
+	}
+
+	return resolved, nil
+}
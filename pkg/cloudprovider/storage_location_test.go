@@ -0,0 +1,108 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+)
+
+func newStorageLocation(name, bucket, resourceVersion string) *api.BackupStorageLocation {
+	return &api.BackupStorageLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       api.DefaultNamespace,
+			Name:            name,
+			ResourceVersion: resourceVersion,
+		},
+		Spec: api.BackupStorageLocationSpec{
+			Provider: "provider",
+			Bucket:   bucket,
+		},
+	}
+}
+
+func TestStorageLocationResolver(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+
+	var created []string
+	defaultLocation := &StorageLocation{Name: api.DefaultBackupStorageLocation, Bucket: "default-bucket"}
+	resolver := NewStorageLocationResolver(
+		defaultLocation,
+		listers.NewBackupStorageLocationLister(indexer),
+		func(location *api.BackupStorageLocation) (BackupService, error) {
+			if location.Name == "broken" {
+				return nil, errors.New("bad config")
+			}
+			created = append(created, location.Name)
+			return NewBackupService(nil, "", nil), nil
+		},
+	)
+
+	// the default location is always available
+	for _, name := range []string{"", api.DefaultBackupStorageLocation} {
+		location, err := resolver.Get(name)
+		require.NoError(t, err)
+		assert.Equal(t, defaultLocation, location)
+	}
+
+	_, err := resolver.Get("missing")
+	assert.EqualError(t, err, `backup storage location "missing" does not exist`)
+
+	require.NoError(t, indexer.Add(newStorageLocation("loc-2", "bucket-2", "1")))
+	require.NoError(t, indexer.Add(newStorageLocation("loc-1", "bucket-1", "1")))
+
+	location, err := resolver.Get("loc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "loc-1", location.Name)
+	assert.Equal(t, "bucket-1", location.Bucket)
+
+	// the backup service is reused until the location changes
+	again, err := resolver.Get("loc-1")
+	require.NoError(t, err)
+	assert.True(t, location == again)
+	assert.Equal(t, []string{"loc-1"}, created)
+
+	require.NoError(t, indexer.Update(newStorageLocation("loc-1", "bucket-1-updated", "2")))
+	location, err = resolver.Get("loc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "bucket-1-updated", location.Bucket)
+	assert.Equal(t, []string{"loc-1", "loc-1"}, created)
+
+	// locations that can't be resolved are left out of the list, and a BackupStorageLocation
+	// named "default" can't replace the default location
+	require.NoError(t, indexer.Add(newStorageLocation("broken", "bucket-3", "1")))
+	require.NoError(t, indexer.Add(newStorageLocation(api.DefaultBackupStorageLocation, "bucket-4", "1")))
+
+	locations, err := resolver.List()
+	assert.Error(t, err)
+
+	var names []string
+	for _, location := range locations {
+		names = append(names, location.Name)
+	}
+	assert.Equal(t, []string{api.DefaultBackupStorageLocation, "loc-1", "loc-2"}, names)
+	assert.Equal(t, defaultLocation, locations[0])
+}
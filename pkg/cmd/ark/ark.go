@@ -54,6 +54,7 @@ operations can also be performed as 'ark backup get' and 'ark schedule create'.`
 		schedule.NewCommand(f),
 		restore.NewCommand(f),
 		server.NewCommand(),
+		server.NewNodeAgentCommand(),
 		version.NewCommand(),
 		get.NewCommand(f),
 		describe.NewCommand(f),
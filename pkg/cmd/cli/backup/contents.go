@@ -0,0 +1,135 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/util/downloadrequest"
+	"github.com/heptio/ark/pkg/cmd/util/flag"
+	"github.com/heptio/ark/pkg/cmd/util/output"
+)
+
+func NewContentsCommand(f client.Factory) *cobra.Command {
+	o := NewContentsOptions()
+	c := &cobra.Command{
+		Use:   "contents NAME",
+		Short: "List the items in a backup",
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Validate(c, args))
+			cmd.CheckError(o.Complete(args))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+type ContentsOptions struct {
+	Name       string
+	Resources  flag.StringArray
+	Namespaces flag.StringArray
+	Timeout    time.Duration
+}
+
+func NewContentsOptions() *ContentsOptions {
+	return &ContentsOptions{
+		Timeout: time.Minute,
+	}
+}
+
+func (o *ContentsOptions) BindFlags(flags *pflag.FlagSet) {
+	flags.Var(&o.Resources, "resource", "only list items of these resources, such as deployments or deployments.apps")
+	flags.Var(&o.Namespaces, "namespace", "only list items in these namespaces")
+	flags.DurationVar(&o.Timeout, "timeout", o.Timeout, "maximum time to wait to process download request")
+}
+
+func (o *ContentsOptions) Validate(c *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("backup name is required")
+	}
+
+	return nil
+}
+
+func (o *ContentsOptions) Complete(args []string) error {
+	o.Name = args[0]
+	return nil
+}
+
+func (o *ContentsOptions) Run(c *cobra.Command, f client.Factory) error {
+	arkClient, err := f.Client()
+	if err != nil {
+		return err
+	}
+
+	index, err := output.GetBackupContentsIndex(arkClient, o.Name, o.Timeout, downloadrequest.NewServerKeyProvider(f))
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(output.Describe(func(d *output.Describer) {
+		output.DescribeBackupContents(d, filterContents(index, o.Resources, o.Namespaces))
+	}))
+
+	return nil
+}
+
+// filterContents returns the items in index of any of resources, in any of namespaces. Resources
+// may be given with or without their group. If resources or namespaces is empty, items aren't
+// filtered by it; otherwise, cluster-scoped items are excluded when filtering by namespace.
+func filterContents(index *v1.BackupContentsIndex, resources, namespaces []string) *v1.BackupContentsIndex {
+	filtered := &v1.BackupContentsIndex{Items: []v1.BackupContentsIndexItem{}}
+
+	for _, item := range index.Items {
+		if len(resources) > 0 && !matchesAny(resources, func(resource string) bool {
+			return item.Resource == resource || strings.HasPrefix(item.Resource, resource+".")
+		}) {
+			continue
+		}
+
+		if len(namespaces) > 0 && !matchesAny(namespaces, func(namespace string) bool {
+			return item.Namespace == namespace
+		}) {
+			continue
+		}
+
+		filtered.Items = append(filtered.Items, item)
+	}
+
+	return filtered
+}
+
+func matchesAny(values []string, matches func(string) bool) bool {
+	for _, value := range values {
+		if matches(value) {
+			return true
+		}
+	}
+	return false
+}
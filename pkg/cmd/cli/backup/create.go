@@ -64,6 +64,7 @@ type CreateOptions struct {
 	Labels                  flag.Map
 	Selector                flag.LabelSelector
 	IncludeClusterResources flag.OptionalBool
+	StorageLocation         string
 }
 
 func NewCreateOptions() *CreateOptions {
@@ -84,6 +85,7 @@ func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
 	flags.Var(&o.ExcludeResources, "exclude-resources", "resources to exclude from the backup, formatted as resource.group, such as storageclasses.storage.k8s.io")
 	flags.Var(&o.Labels, "labels", "labels to apply to the backup")
 	flags.VarP(&o.Selector, "selector", "l", "only back up resources matching this label selector")
+	flags.StringVar(&o.StorageLocation, "storage-location", "", "name of the backup storage location to store the backup in (if unset, the server's configured backupStorageProvider is used)")
 	f := flags.VarPF(&o.SnapshotVolumes, "snapshot-volumes", "", "take snapshots of PersistentVolumes as part of the backup")
 	// this allows the user to just specify "--snapshot-volumes" as shorthand for "--snapshot-volumes=true"
 	// like a normal bool flag
@@ -131,6 +133,7 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 			SnapshotVolumes:    o.SnapshotVolumes.Value,
 			TTL:                metav1.Duration{Duration: o.TTL},
 			IncludeClusterResources: o.IncludeClusterResources.Value,
+			StorageLocation:         o.StorageLocation,
 		},
 	}
 
@@ -18,36 +18,179 @@ package backup
 
 import (
 	"fmt"
-	"os"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
-	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
+	arkclientv1 "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
 )
 
 func NewDeleteCommand(f client.Factory) *cobra.Command {
+	o := NewDeleteOptions()
 	c := &cobra.Command{
 		Use:   "delete NAME",
 		Short: "Delete a backup",
+		Long: `Delete a backup, including its files in object storage, its volume snapshots, and any restores
+created from it. The deletion is carried out by the Ark server; use --wait to wait for it to finish.`,
 		Run: func(c *cobra.Command, args []string) {
-			if len(args) != 1 {
-				c.Usage()
-				os.Exit(1)
-			}
+			cmd.CheckError(o.Validate(c, args))
+			cmd.CheckError(o.Complete(args))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+type DeleteOptions struct {
+	Name    string
+	Wait    bool
+	Timeout time.Duration
+}
+
+func NewDeleteOptions() *DeleteOptions {
+	return &DeleteOptions{
+		Timeout: 5 * time.Minute,
+	}
+}
+
+func (o *DeleteOptions) BindFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.Wait, "wait", o.Wait, "wait for the deletion to be processed")
+	flags.DurationVar(&o.Timeout, "timeout", o.Timeout, "maximum time to wait for the deletion to be processed when --wait is set")
+}
 
-			arkClient, err := f.Client()
-			cmd.CheckError(err)
+func (o *DeleteOptions) Validate(c *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("backup name is required")
+	}
+
+	return nil
+}
 
-			backupName := args[0]
+func (o *DeleteOptions) Complete(args []string) error {
+	o.Name = args[0]
+	return nil
+}
 
-			err = arkClient.ArkV1().Backups(api.DefaultNamespace).Delete(backupName, nil)
-			cmd.CheckError(err)
+func (o *DeleteOptions) Run(c *cobra.Command, f client.Factory) error {
+	arkClient, err := f.Client()
+	if err != nil {
+		return err
+	}
 
-			fmt.Printf("Backup %q deleted\n", backupName)
+	req := &v1.DeleteBackupRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    v1.DefaultNamespace,
+			GenerateName: o.Name + "-",
+			Labels: map[string]string{
+				v1.BackupNameLabel: o.Name,
+			},
+		},
+		Spec: v1.DeleteBackupRequestSpec{
+			BackupName: o.Name,
 		},
 	}
 
-	return c
+	req, err = arkClient.ArkV1().DeleteBackupRequests(v1.DefaultNamespace).Create(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if !o.Wait {
+		fmt.Printf("Request to delete backup %q submitted successfully.\nThe backup will be fully deleted after all associated data (disk snapshots, backup files, restores) are removed.\n", o.Name)
+		return nil
+	}
+
+	fmt.Printf("Waiting for backup %q to be deleted...\n", o.Name)
+	req, err = waitForDeleteBackupRequest(arkClient.ArkV1(), req, o.Timeout)
+	if err != nil {
+		return err
+	}
+
+	if len(req.Status.Errors) > 0 {
+		fmt.Printf("Errors deleting backup %q:\n", o.Name)
+		for _, e := range req.Status.Errors {
+			fmt.Printf("\t%s\n", e)
+		}
+		return errors.Errorf("backup %q was not fully deleted", o.Name)
+	}
+
+	fmt.Printf("Backup %q deleted\n", o.Name)
+	return nil
+}
+
+// waitForDeleteBackupRequest watches req until the server has processed it and returns
+// the processed request. If the server closes the watch, it's re-established from the
+// last resource version seen.
+func waitForDeleteBackupRequest(client arkclientv1.DeleteBackupRequestsGetter, req *v1.DeleteBackupRequest, timeout time.Duration) (*v1.DeleteBackupRequest, error) {
+	watchFrom := func(resourceVersion string) (watch.Interface, error) {
+		listOptions := metav1.ListOptions{
+			//TODO: once kube-apiserver http://issue.k8s.io/51046 is fixed, uncomment
+			//FieldSelector:   "metadata.name=" + req.Name
+			ResourceVersion: resourceVersion,
+		}
+		watcher, err := client.DeleteBackupRequests(req.Namespace).Watch(listOptions)
+		return watcher, errors.WithStack(err)
+	}
+
+	resourceVersion := req.ResourceVersion
+	watcher, err := watchFrom(resourceVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { watcher.Stop() }()
+
+	expired := time.NewTimer(timeout)
+	defer expired.Stop()
+
+	for {
+		select {
+		case <-expired.C:
+			return nil, errors.New("timed out waiting for backup deletion to be processed")
+		case e, open := <-watcher.ResultChan():
+			if !open {
+				rewatched, err := watchFrom(resourceVersion)
+				if err != nil {
+					return nil, err
+				}
+				watcher.Stop()
+				watcher = rewatched
+				continue
+			}
+
+			if e.Type == watch.Error {
+				return nil, errors.Errorf("error watching delete backup request: %v", e.Object)
+			}
+
+			updated, ok := e.Object.(*v1.DeleteBackupRequest)
+			if !ok {
+				return nil, errors.Errorf("unexpected type %T", e.Object)
+			}
+
+			resourceVersion = updated.ResourceVersion
+
+			if updated.Name != req.Name {
+				continue
+			}
+
+			switch e.Type {
+			case watch.Deleted:
+				return nil, errors.New("delete backup request was unexpectedly deleted")
+			case watch.Modified:
+				if updated.Status.Phase == v1.DeleteBackupRequestPhaseProcessed {
+					return updated, nil
+				}
+			}
+		}
+	}
 }
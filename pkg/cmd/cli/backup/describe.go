@@ -25,11 +25,15 @@ import (
 	"github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/util/downloadrequest"
 	"github.com/heptio/ark/pkg/cmd/util/output"
 )
 
 func NewDescribeCommand(f client.Factory, use string) *cobra.Command {
-	var listOptions metav1.ListOptions
+	var (
+		listOptions metav1.ListOptions
+		details     bool
+	)
 
 	c := &cobra.Command{
 		Use:   use + " [NAME1] [NAME2] [NAME...]",
@@ -53,7 +57,7 @@ func NewDescribeCommand(f client.Factory, use string) *cobra.Command {
 
 			first := true
 			for _, backup := range backups.Items {
-				s := output.DescribeBackup(&backup)
+				s := output.DescribeBackup(&backup, details, arkClient, downloadrequest.NewServerKeyProvider(f))
 				if first {
 					first = false
 					fmt.Print(s)
@@ -66,6 +70,7 @@ func NewDescribeCommand(f client.Factory, use string) *cobra.Command {
 	}
 
 	c.Flags().StringVarP(&listOptions.LabelSelector, "selector", "l", listOptions.LabelSelector, "only show items matching this label selector")
+	c.Flags().BoolVar(&details, "details", details, "display the warnings and errors encountered while backing up items, and the items in the backup")
 
 	return c
 }
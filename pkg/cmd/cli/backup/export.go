@@ -0,0 +1,166 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/util/downloadrequest"
+	"github.com/heptio/ark/pkg/encryption"
+	"github.com/heptio/ark/pkg/export"
+)
+
+func NewExportCommand(f client.Factory) *cobra.Command {
+	o := NewExportOptions()
+	c := &cobra.Command{
+		Use:   "export [NAME]",
+		Short: "Export a backup as Kubernetes manifests",
+		Long: `Export the items in a backup as YAML manifests, written to
+<output-dir>/namespaces/<namespace>/<resource>/<name>.yaml for namespaced items and
+<output-dir>/cluster/<resource>/<name>.yaml for cluster-scoped items.
+
+The backup is downloaded from the Ark server, or read from a tarball previously downloaded with
+'ark backup download' if --from-file is set. With --sanitize, server-populated fields are removed
+the same way they are when restoring, so the manifests can be applied with 'kubectl apply'.`,
+		Example: `  ark backup export backup-1 --output-dir ./backup-1 --sanitize
+  ark backup export --from-file backup-1-data.tar.gz --output-dir ./backup-1`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Validate(c, args))
+			cmd.CheckError(o.Complete(args))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+type ExportOptions struct {
+	Name      string
+	OutputDir string
+	FromFile  string
+	Sanitize  bool
+	Timeout   time.Duration
+}
+
+func NewExportOptions() *ExportOptions {
+	return &ExportOptions{
+		Timeout: time.Minute,
+	}
+}
+
+func (o *ExportOptions) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.OutputDir, "output-dir", o.OutputDir, "directory to write manifests to. Required")
+	flags.StringVar(&o.FromFile, "from-file", o.FromFile, "path to a backup tarball to export, instead of downloading the backup")
+	flags.BoolVar(&o.Sanitize, "sanitize", o.Sanitize, "remove server-populated fields and skip items owned by controllers, so the manifests can be applied to a cluster")
+	flags.DurationVar(&o.Timeout, "timeout", o.Timeout, "maximum time to wait to process download request")
+}
+
+func (o *ExportOptions) Validate(c *cobra.Command, args []string) error {
+	switch {
+	case o.FromFile == "" && len(args) != 1:
+		return errors.New("backup name is required")
+	case o.FromFile != "" && len(args) != 0:
+		return errors.New("backup name can't be specified with --from-file")
+	}
+
+	if o.OutputDir == "" {
+		return errors.New("--output-dir is required")
+	}
+
+	return nil
+}
+
+func (o *ExportOptions) Complete(args []string) error {
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+
+	return nil
+}
+
+func (o *ExportOptions) Run(c *cobra.Command, f client.Factory) error {
+	logger := logrus.New()
+	logger.Out = os.Stderr
+
+	exporter := export.NewExporter(o.OutputDir, o.Sanitize, logger)
+	keyProvider := downloadrequest.NewServerKeyProvider(f)
+
+	var (
+		count int
+		err   error
+	)
+	if o.FromFile != "" {
+		count, err = o.exportFile(exporter, keyProvider)
+	} else {
+		count, err = o.exportBackup(exporter, f, keyProvider)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d items to %s\n", count, o.OutputDir)
+	return nil
+}
+
+func (o *ExportOptions) exportFile(exporter *export.Exporter, keyProvider encryption.KeyProvider) (int, error) {
+	file, err := os.Open(o.FromFile)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer file.Close()
+
+	// tarballs downloaded with 'ark backup download' are already decrypted, but one copied
+	// directly from object storage may not be
+	r, err := encryption.NewDecryptingReaderIfEncrypted(file, keyProvider)
+	if err != nil {
+		return 0, err
+	}
+
+	return exporter.Export(r)
+}
+
+func (o *ExportOptions) exportBackup(exporter *export.Exporter, f client.Factory, keyProvider encryption.KeyProvider) (int, error) {
+	arkClient, err := f.Client()
+	if err != nil {
+		return 0, err
+	}
+
+	// stream the backup from the download into the exporter, rather than storing it first
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(downloadrequest.Stream(arkClient.ArkV1(), o.Name, v1.DownloadTargetKindBackupContents, pw, o.Timeout, keyProvider))
+	}()
+
+	count, err := exporter.Export(pr)
+	// unblock the download if the export stopped early
+	pr.CloseWithError(errors.New("export stopped"))
+
+	return count, err
+}
@@ -44,7 +44,7 @@ func NewLogsCommand(f client.Factory) *cobra.Command {
 			arkClient, err := f.Client()
 			cmd.CheckError(err)
 
-			err = downloadrequest.Stream(arkClient.ArkV1(), args[0], v1.DownloadTargetKindBackupLog, os.Stdout, timeout)
+			err = downloadrequest.Stream(arkClient.ArkV1(), args[0], v1.DownloadTargetKindBackupLog, os.Stdout, timeout, downloadrequest.NewServerKeyProvider(f))
 			cmd.CheckError(err)
 		},
 	}
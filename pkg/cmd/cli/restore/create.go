@@ -64,6 +64,8 @@ type CreateOptions struct {
 	NamespaceMappings       flag.Map
 	Selector                flag.LabelSelector
 	IncludeClusterResources flag.OptionalBool
+	ExistingResourcePolicy  *flag.Enum
+	DryRun                  bool
 }
 
 func NewCreateOptions() *CreateOptions {
@@ -73,6 +75,12 @@ func NewCreateOptions() *CreateOptions {
 		NamespaceMappings:       flag.NewMap().WithEntryDelimiter(",").WithKeyValueDelimiter(":"),
 		RestoreVolumes:          flag.NewOptionalBool(nil),
 		IncludeClusterResources: flag.NewOptionalBool(nil),
+		ExistingResourcePolicy: flag.NewEnum(
+			string(api.ExistingResourcePolicyNone),
+			string(api.ExistingResourcePolicyNone),
+			string(api.ExistingResourcePolicyUpdate),
+			string(api.ExistingResourcePolicyRecreate),
+		),
 	}
 }
 
@@ -84,6 +92,8 @@ func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
 	flags.Var(&o.IncludeResources, "include-resources", "resources to include in the restore, formatted as resource.group, such as storageclasses.storage.k8s.io (use '*' for all resources)")
 	flags.Var(&o.ExcludeResources, "exclude-resources", "resources to exclude from the restore, formatted as resource.group, such as storageclasses.storage.k8s.io")
 	flags.VarP(&o.Selector, "selector", "l", "only restore resources matching this label selector")
+	flags.Var(o.ExistingResourcePolicy, "existing-resource-policy", "what to do with items in the backup that already exist in the cluster: none (leave them as they are), update (patch them to match the backup), or recreate (delete them and create them from the backup; persistent volumes and claims are never recreated)")
+	flags.BoolVar(&o.DryRun, "dry-run", o.DryRun, "only report what the restore would do, without changing anything in the cluster; see the report with 'ark restore describe'")
 	f := flags.VarPF(&o.RestoreVolumes, "restore-volumes", "", "whether to restore volumes from snapshots")
 	// this allows the user to just specify "--restore-volumes" as shorthand for "--restore-volumes=true"
 	// like a normal bool flag
@@ -132,6 +142,8 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 			LabelSelector:           o.Selector.LabelSelector,
 			RestorePVs:              o.RestoreVolumes.Value,
 			IncludeClusterResources: o.IncludeClusterResources.Value,
+			ExistingResourcePolicy:  api.ExistingResourcePolicy(o.ExistingResourcePolicy.String()),
+			DryRun:                  o.DryRun,
 		},
 	}
 
@@ -25,6 +25,7 @@ import (
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/util/downloadrequest"
 	"github.com/heptio/ark/pkg/cmd/util/output"
 )
 
@@ -53,7 +54,7 @@ func NewDescribeCommand(f client.Factory, use string) *cobra.Command {
 
 			first := true
 			for _, restore := range restores.Items {
-				s := output.DescribeRestore(&restore, arkClient)
+				s := output.DescribeRestore(&restore, arkClient, downloadrequest.NewServerKeyProvider(f))
 				if first {
 					first = false
 					fmt.Print(s)
@@ -18,6 +18,7 @@ package schedule
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -29,7 +30,9 @@ import (
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
 	"github.com/heptio/ark/pkg/cmd/cli/backup"
+	"github.com/heptio/ark/pkg/cmd/util/flag"
 	"github.com/heptio/ark/pkg/cmd/util/output"
+	arkschedule "github.com/heptio/ark/pkg/schedule"
 )
 
 func NewCreateCommand(f client.Factory, use string) *cobra.Command {
@@ -53,8 +56,12 @@ func NewCreateCommand(f client.Factory, use string) *cobra.Command {
 }
 
 type CreateOptions struct {
-	BackupOptions *backup.CreateOptions
-	Schedule      string
+	BackupOptions     *backup.CreateOptions
+	Schedule          string
+	Retention         api.ScheduleRetention
+	ConcurrencyPolicy *flag.Enum
+	StartingDeadline  time.Duration
+	TimeZone          string
 
 	labelSelector *metav1.LabelSelector
 }
@@ -62,12 +69,25 @@ type CreateOptions struct {
 func NewCreateOptions() *CreateOptions {
 	return &CreateOptions{
 		BackupOptions: backup.NewCreateOptions(),
+		ConcurrencyPolicy: flag.NewEnum(
+			string(api.ScheduleConcurrencyPolicyAllow),
+			string(api.ScheduleConcurrencyPolicyAllow),
+			string(api.ScheduleConcurrencyPolicyForbid),
+			string(api.ScheduleConcurrencyPolicyReplace),
+		),
 	}
 }
 
 func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
 	o.BackupOptions.BindFlags(flags)
 	flags.StringVar(&o.Schedule, "schedule", o.Schedule, "a cron expression specifying a recurring schedule for this backup to run")
+	flags.StringVar(&o.TimeZone, "time-zone", o.TimeZone, "the IANA name of the time zone that the schedule is evaluated in, e.g. America/New_York. Defaults to the Ark server's local time zone")
+	flags.IntVar(&o.Retention.KeepLast, "keep-last", o.Retention.KeepLast, "number of most recent backups to keep. If any --keep flags are set, backups are pruned by the retention policy instead of expiring after their TTL")
+	flags.IntVar(&o.Retention.KeepDaily, "keep-daily", o.Retention.KeepDaily, "number of days to keep the last backup of")
+	flags.IntVar(&o.Retention.KeepWeekly, "keep-weekly", o.Retention.KeepWeekly, "number of weeks to keep the last backup of")
+	flags.IntVar(&o.Retention.KeepMonthly, "keep-monthly", o.Retention.KeepMonthly, "number of months to keep the last backup of")
+	flags.Var(o.ConcurrencyPolicy, "concurrency-policy", "what to do when the schedule is due while one of its backups hasn't finished: Allow (create another backup), Forbid (skip the run), or Replace (delete backups that haven't started and create a new one)")
+	flags.DurationVar(&o.StartingDeadline, "starting-deadline", o.StartingDeadline, "how long after it was due a run can still start, e.g. after the Ark server was down. Runs missed by longer are skipped. 0 means runs are never skipped")
 }
 
 func (o *CreateOptions) Validate(c *cobra.Command, args []string) error {
@@ -77,6 +97,17 @@ func (o *CreateOptions) Validate(c *cobra.Command, args []string) error {
 	if len(o.Schedule) == 0 {
 		return errors.New("--schedule is required")
 	}
+	if o.Retention != (api.ScheduleRetention{}) {
+		if errs := arkschedule.ValidateRetention(&o.Retention); len(errs) > 0 {
+			return errors.New(errs[0])
+		}
+	}
+	if _, err := arkschedule.LoadLocation(o.TimeZone); err != nil {
+		return errors.Wrap(err, "invalid --time-zone")
+	}
+	if o.StartingDeadline < 0 {
+		return errors.New("--starting-deadline must not be negative")
+	}
 
 	return o.BackupOptions.Validate(c, args)
 }
@@ -105,11 +136,22 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 				LabelSelector:      o.BackupOptions.Selector.LabelSelector,
 				SnapshotVolumes:    o.BackupOptions.SnapshotVolumes.Value,
 				TTL:                metav1.Duration{Duration: o.BackupOptions.TTL},
+				StorageLocation:    o.BackupOptions.StorageLocation,
 			},
-			Schedule: o.Schedule,
+			Schedule:          o.Schedule,
+			ConcurrencyPolicy: api.ScheduleConcurrencyPolicy(o.ConcurrencyPolicy.String()),
+			TimeZone:          o.TimeZone,
 		},
 	}
 
+	if o.StartingDeadline > 0 {
+		schedule.Spec.StartingDeadline = &metav1.Duration{Duration: o.StartingDeadline}
+	}
+
+	if o.Retention != (api.ScheduleRetention{}) {
+		schedule.Spec.Retention = &o.Retention
+	}
+
 	if printed, err := output.PrintWithFormat(c, schedule); printed || err != nil {
 		return err
 	}
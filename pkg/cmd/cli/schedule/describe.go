@@ -21,11 +21,13 @@ import (
 
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
 	"github.com/heptio/ark/pkg/cmd/util/output"
+	clientset "github.com/heptio/ark/pkg/generated/clientset/versioned"
 )
 
 func NewDescribeCommand(f client.Factory, use string) *cobra.Command {
@@ -53,7 +55,13 @@ func NewDescribeCommand(f client.Factory, use string) *cobra.Command {
 
 			first := true
 			for _, schedule := range schedules.Items {
-				s := output.DescribeSchedule(&schedule)
+				var backups []*v1.Backup
+				if schedule.Spec.Retention != nil {
+					backups, err = getScheduleBackups(arkClient, schedule.Name)
+					cmd.CheckError(err)
+				}
+
+				s := output.DescribeSchedule(&schedule, backups)
 				if first {
 					first = false
 					fmt.Print(s)
@@ -69,3 +77,19 @@ func NewDescribeCommand(f client.Factory, use string) *cobra.Command {
 
 	return c
 }
+
+// getScheduleBackups returns the backups created by the schedule with the given name.
+func getScheduleBackups(arkClient clientset.Interface, name string) ([]*v1.Backup, error) {
+	backupList, err := arkClient.ArkV1().Backups(v1.DefaultNamespace).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{v1.ScheduleNameLabel: name}).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]*v1.Backup, 0, len(backupList.Items))
+	for i := range backupList.Items {
+		backups = append(backups, &backupList.Items[i])
+	}
+	return backups, nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/cmd"
+)
+
+func NewPauseCommand(f client.Factory) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "pause NAME",
+		Short: "Pause a schedule",
+		Long:  "Pause a schedule. A paused schedule doesn't trigger backups until it's resumed, but keeps its last backup time and its backups.",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				c.Usage()
+				os.Exit(1)
+			}
+
+			cmd.CheckError(setPaused(f, args[0], true))
+			fmt.Printf("Schedule %q paused\n", args[0])
+		},
+	}
+
+	return c
+}
+
+func NewResumeCommand(f client.Factory) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "resume NAME",
+		Short: "Resume a paused schedule",
+		Long:  "Resume a paused schedule. If a run was missed while the schedule was paused, a backup is triggered immediately.",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				c.Usage()
+				os.Exit(1)
+			}
+
+			cmd.CheckError(setPaused(f, args[0], false))
+			fmt.Printf("Schedule %q resumed\n", args[0])
+		},
+	}
+
+	return c
+}
+
+func setPaused(f client.Factory, name string, paused bool) error {
+	arkClient, err := f.Client()
+	if err != nil {
+		return err
+	}
+
+	schedule, err := arkClient.ArkV1().Schedules(api.DefaultNamespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if schedule.Spec.Paused == paused {
+		return nil
+	}
+
+	schedule.Spec.Paused = paused
+	_, err = arkClient.ArkV1().Schedules(api.DefaultNamespace).Update(schedule)
+	return err
+}
@@ -34,6 +34,9 @@ func NewCommand(f client.Factory) *cobra.Command {
 		NewGetCommand(f, "get"),
 		NewDescribeCommand(f, "describe"),
 		NewDeleteCommand(f),
+		NewPauseCommand(f),
+		NewResumeCommand(f),
+		NewTriggerCommand(f),
 	)
 
 	return c
@@ -0,0 +1,70 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/cmd"
+)
+
+func NewTriggerCommand(f client.Factory) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "trigger NAME",
+		Short: "Create a backup from a schedule now",
+		Long: `Request a backup from a schedule's template now, regardless of the schedule's cron expression or
+whether it's paused. The Ark server creates the backup, subject to the schedule's concurrency policy,
+and records the run in the schedule's history. The backup is labeled with the schedule's name, so
+it's subject to the schedule's retention policy, but the schedule's last backup time isn't changed.`,
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				c.Usage()
+				os.Exit(1)
+			}
+
+			arkClient, err := f.Client()
+			cmd.CheckError(err)
+
+			schedule, err := arkClient.ArkV1().Schedules(api.DefaultNamespace).Get(args[0], metav1.GetOptions{})
+			cmd.CheckError(err)
+
+			if schedule.Status.Phase == api.SchedulePhaseFailedValidation {
+				cmd.CheckError(fmt.Errorf("schedule %q failed validation: %v", schedule.Name, schedule.Status.ValidationErrors))
+			}
+
+			if _, triggered := schedule.Annotations[api.ScheduleTriggerAnnotation]; !triggered {
+				if schedule.Annotations == nil {
+					schedule.Annotations = make(map[string]string)
+				}
+				schedule.Annotations[api.ScheduleTriggerAnnotation] = "true"
+
+				_, err = arkClient.ArkV1().Schedules(api.DefaultNamespace).Update(schedule)
+				cmd.CheckError(err)
+			}
+
+			fmt.Printf("Backup from schedule %q requested. Use `ark schedule describe %s` to see the result.\n", schedule.Name, schedule.Name)
+		},
+	}
+
+	return c
+}
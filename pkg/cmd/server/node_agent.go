@@ -0,0 +1,169 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/heptio/ark/pkg/buildinfo"
+	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/util/flag"
+	"github.com/heptio/ark/pkg/controller"
+	"github.com/heptio/ark/pkg/util/logging"
+)
+
+const (
+	// nodeNameEnvVar is the environment variable the node agent reads the name of its node
+	// from. It's expected to be set using the downward API.
+	nodeNameEnvVar = "NODE_NAME"
+
+	// defaultHostPodsDir is where the node agent expects the kubelet's pods directory
+	// (usually /var/lib/kubelet/pods) to be mounted.
+	defaultHostPodsDir = "/host_pods"
+)
+
+// NewNodeAgentCommand returns the command that runs the node agent, which backs up and restores
+// the contents of the volumes of pods running on its node.
+func NewNodeAgentCommand() *cobra.Command {
+	var (
+		kubeconfig      string
+		hostPodsDir     = defaultHostPodsDir
+		sortedLogLevels = getSortedLogLevels()
+		logLevelFlag    = flag.NewEnum(logrus.InfoLevel.String(), sortedLogLevels...)
+	)
+
+	var command = &cobra.Command{
+		Use:   "node-agent",
+		Short: "Run the ark node agent",
+		Long: `Run the ark node agent, which backs up and restores the contents of the volumes of
+pods running on its node. It's intended to run as a DaemonSet with the kubelet's pods
+directory mounted, and the name of its node in the NODE_NAME environment variable.`,
+		Run: func(c *cobra.Command, args []string) {
+			logLevel := logrus.InfoLevel
+			if parsed, err := logrus.ParseLevel(logLevelFlag.String()); err == nil {
+				logLevel = parsed
+			} else {
+				logrus.Errorf("log-level flag has invalid value %s", strings.ToUpper(logLevelFlag.String()))
+			}
+
+			logger := newLogger(logLevel, &logging.ErrorLocationHook{}, &logging.LogLocationHook{})
+			logger.Infof("Starting Ark node agent %s", buildinfo.FormattedGitSHA())
+
+			nodeName := os.Getenv(nodeNameEnvVar)
+			if nodeName == "" {
+				cmd.CheckError(errors.Errorf("%s environment variable must be set", nodeNameEnvVar))
+			}
+
+			s, err := newServer(kubeconfig, fmt.Sprintf("%s-%s", c.Parent().Name(), c.Name()), "", "", logger)
+			cmd.CheckError(err)
+
+			cmd.CheckError(s.runNodeAgent(nodeName, hostPodsDir))
+		},
+	}
+
+	command.Flags().Var(logLevelFlag, "log-level", fmt.Sprintf("the level at which to log. Valid values are %s.", strings.Join(sortedLogLevels, ", ")))
+	command.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file to use to talk to the Kubernetes apiserver. If unset, try the environment variable KUBECONFIG, as well as in-cluster configuration")
+	command.Flags().StringVar(&hostPodsDir, "host-pods-dir", hostPodsDir, "the directory the kubelet's pods directory is mounted at")
+
+	return command
+}
+
+// runNodeAgent runs the controllers that process the PodVolumeBackups and PodVolumeRestores for
+// pods on nodeName. It blocks until the server's context is cancelled.
+func (s *server) runNodeAgent(nodeName, hostPodsDir string) error {
+	originalConfig, err := s.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	config := originalConfig.DeepCopy()
+	applyConfigDefaults(config, s.logger)
+
+	s.watchConfig(originalConfig)
+
+	if err := s.initBackupService(config); err != nil {
+		return err
+	}
+
+	if err := s.initEncryption(config); err != nil {
+		return err
+	}
+
+	ctx := s.ctx
+	var wg sync.WaitGroup
+
+	storageLocationInformer := s.sharedInformerFactory.Ark().V1().BackupStorageLocations()
+	storageLocations := s.newStorageLocationResolver(config)
+
+	podVolumeBackupController := controller.NewPodVolumeBackupController(
+		s.arkClient.ArkV1(),
+		s.sharedInformerFactory.Ark().V1().PodVolumeBackups(),
+		s.kubeClient.CoreV1(),
+		s.kubeClient.CoreV1(),
+		storageLocations,
+		s.keyProvider,
+		nodeName,
+		hostPodsDir,
+		s.logger,
+	)
+
+	podVolumeRestoreController := controller.NewPodVolumeRestoreController(
+		s.arkClient.ArkV1(),
+		s.sharedInformerFactory.Ark().V1().PodVolumeRestores(),
+		s.kubeClient.CoreV1(),
+		s.kubeClient.CoreV1(),
+		storageLocations,
+		s.keyProvider,
+		nodeName,
+		hostPodsDir,
+		s.logger,
+	)
+
+	// SHARED INFORMERS HAVE TO BE STARTED AFTER ALL CONTROLLERS
+	s.sharedInformerFactory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), storageLocationInformer.Informer().HasSynced) {
+		return errors.New("timed out waiting for backup storage locations cache to sync")
+	}
+
+	wg.Add(2)
+	go func() {
+		podVolumeBackupController.Run(ctx, 1)
+		wg.Done()
+	}()
+	go func() {
+		podVolumeRestoreController.Run(ctx, 1)
+		wg.Done()
+	}()
+
+	s.logger.WithField("node", nodeName).Info("Node agent started successfully")
+
+	<-ctx.Done()
+
+	s.logger.Info("Waiting for all controllers to shut down gracefully")
+	wg.Wait()
+
+	return nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/cloudprovider/aws"
 	"github.com/heptio/ark/pkg/cloudprovider/azure"
+	"github.com/heptio/ark/pkg/cloudprovider/filesystem"
 	"github.com/heptio/ark/pkg/cloudprovider/gcp"
 	arkplugin "github.com/heptio/ark/pkg/plugin"
 )
@@ -33,19 +34,22 @@ func NewCommand() *cobra.Command {
 	logger := arkplugin.NewPluginLogger()
 
 	objectStores := map[string]cloudprovider.ObjectStore{
-		"aws":   aws.NewObjectStore(),
-		"gcp":   gcp.NewObjectStore(),
-		"azure": azure.NewObjectStore(),
+		"aws":        aws.NewObjectStore(),
+		"gcp":        gcp.NewObjectStore(),
+		"azure":      azure.NewObjectStore(),
+		"filesystem": filesystem.NewObjectStore(),
 	}
 
 	blockStores := map[string]cloudprovider.BlockStore{
-		"aws":   aws.NewBlockStore(),
-		"gcp":   gcp.NewBlockStore(),
-		"azure": azure.NewBlockStore(),
+		"aws":        aws.NewBlockStore(),
+		"gcp":        gcp.NewBlockStore(),
+		"azure":      azure.NewBlockStore(),
+		"filesystem": filesystem.NewBlockStore(),
 	}
 
 	backupActions := map[string]backup.ItemAction{
-		"backup_pv": backup.NewBackupPVAction(logger),
+		"backup_pv":  backup.NewBackupPVAction(logger),
+		"backup_pod": backup.NewPodAction(logger),
 	}
 
 	c := &cobra.Command{
@@ -71,19 +75,19 @@ func NewCommand() *cobra.Command {
 
 			switch kind {
 			case "cloudprovider":
-				objectStore, found := objectStores[name]
-				if !found {
-					logger.Fatalf("Unrecognized plugin name")
+				// a cloud provider may implement an object store, a block store, or both
+				serveConfig.Plugins = map[string]plugin.Plugin{}
+
+				if objectStore, found := objectStores[name]; found {
+					serveConfig.Plugins[string(arkplugin.PluginKindObjectStore)] = arkplugin.NewObjectStorePlugin(objectStore)
 				}
 
-				blockStore, found := blockStores[name]
-				if !found {
-					logger.Fatalf("Unrecognized plugin name")
+				if blockStore, found := blockStores[name]; found {
+					serveConfig.Plugins[string(arkplugin.PluginKindBlockStore)] = arkplugin.NewBlockStorePlugin(blockStore)
 				}
 
-				serveConfig.Plugins = map[string]plugin.Plugin{
-					string(arkplugin.PluginKindObjectStore): arkplugin.NewObjectStorePlugin(objectStore),
-					string(arkplugin.PluginKindBlockStore):  arkplugin.NewBlockStorePlugin(blockStore),
+				if len(serveConfig.Plugins) == 0 {
+					logger.Fatalf("Unrecognized plugin name")
 				}
 			case arkplugin.PluginKindBackupItemAction.String():
 				action, found := backupActions[name]
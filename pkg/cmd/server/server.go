@@ -19,6 +19,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"reflect"
 	"sort"
 	"strings"
@@ -27,12 +28,14 @@ import (
 
 	"github.com/heptio/ark/pkg/buildinfo"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
@@ -40,30 +43,46 @@ import (
 	kcorev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/flowcontrol"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/backup"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/cloudprovider/filesystem"
 	"github.com/heptio/ark/pkg/cmd"
 	"github.com/heptio/ark/pkg/cmd/util/flag"
 	"github.com/heptio/ark/pkg/controller"
 	arkdiscovery "github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/encryption"
 	clientset "github.com/heptio/ark/pkg/generated/clientset/versioned"
 	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
+	"github.com/heptio/ark/pkg/metrics"
 	"github.com/heptio/ark/pkg/plugin"
+	"github.com/heptio/ark/pkg/podexec"
+	"github.com/heptio/ark/pkg/podvolume"
 	"github.com/heptio/ark/pkg/restore"
 	"github.com/heptio/ark/pkg/restore/restorers"
 	"github.com/heptio/ark/pkg/util/kube"
 	"github.com/heptio/ark/pkg/util/logging"
 )
 
+// defaultMetricsAddress is the address the server exposes Prometheus metrics on if
+// --metrics-address isn't specified.
+const defaultMetricsAddress = ":8085"
+
+// defaultFileServerAddress is the address the server serves downloads from storage locations
+// that use the filesystem backup storage provider on if --file-server-address isn't specified.
+const defaultFileServerAddress = ":8086"
+
 func NewCommand() *cobra.Command {
 	var (
-		kubeconfig      string
-		sortedLogLevels = getSortedLogLevels()
-		logLevelFlag    = flag.NewEnum(logrus.InfoLevel.String(), sortedLogLevels...)
+		kubeconfig        string
+		metricsAddress    string
+		fileServerAddress string
+		sortedLogLevels   = getSortedLogLevels()
+		logLevelFlag      = flag.NewEnum(logrus.InfoLevel.String(), sortedLogLevels...)
 	)
 
 	var command = &cobra.Command{
@@ -86,7 +105,7 @@ func NewCommand() *cobra.Command {
 			logger := newLogger(logLevel, &logging.ErrorLocationHook{}, &logging.LogLocationHook{})
 			logger.Infof("Starting Ark server %s", buildinfo.FormattedGitSHA())
 
-			s, err := newServer(kubeconfig, fmt.Sprintf("%s-%s", c.Parent().Name(), c.Name()), logger)
+			s, err := newServer(kubeconfig, fmt.Sprintf("%s-%s", c.Parent().Name(), c.Name()), metricsAddress, fileServerAddress, logger)
 
 			cmd.CheckError(err)
 
@@ -96,6 +115,8 @@ func NewCommand() *cobra.Command {
 
 	command.Flags().Var(logLevelFlag, "log-level", fmt.Sprintf("the level at which to log. Valid values are %s.", strings.Join(sortedLogLevels, ", ")))
 	command.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file to use to talk to the Kubernetes apiserver. If unset, try the environment variable KUBECONFIG, as well as in-cluster configuration")
+	command.Flags().StringVar(&metricsAddress, "metrics-address", defaultMetricsAddress, "the address to expose prometheus metrics")
+	command.Flags().StringVar(&fileServerAddress, "file-server-address", defaultFileServerAddress, "the address to serve file downloads on for storage locations that use the filesystem backup storage provider")
 
 	return command
 }
@@ -137,6 +158,7 @@ type server struct {
 	arkClient             clientset.Interface
 	backupService         cloudprovider.BackupService
 	snapshotService       cloudprovider.SnapshotService
+	keyProvider           encryption.KeyProvider
 	discoveryClient       discovery.DiscoveryInterface
 	clientPool            dynamic.ClientPool
 	sharedInformerFactory informers.SharedInformerFactory
@@ -144,9 +166,13 @@ type server struct {
 	cancelFunc            context.CancelFunc
 	logger                *logrus.Logger
 	pluginManager         plugin.Manager
+	metricsAddress        string
+	fileServerAddress     string
+	fileServerOnce        sync.Once
+	metrics               *metrics.ServerMetrics
 }
 
-func newServer(kubeconfig, baseName string, logger *logrus.Logger) (*server, error) {
+func newServer(kubeconfig, baseName, metricsAddress, fileServerAddress string, logger *logrus.Logger) (*server, error) {
 	clientConfig, err := client.Config(kubeconfig, baseName)
 	if err != nil {
 		return nil, err
@@ -176,10 +202,12 @@ func newServer(kubeconfig, baseName string, logger *logrus.Logger) (*server, err
 		discoveryClient:       arkClient.Discovery(),
 		clientPool:            dynamic.NewDynamicClientPool(clientConfig),
 		sharedInformerFactory: informers.NewSharedInformerFactory(arkClient, 0),
-		ctx:           ctx,
-		cancelFunc:    cancelFunc,
-		logger:        logger,
-		pluginManager: pluginManager,
+		ctx:                   ctx,
+		cancelFunc:            cancelFunc,
+		logger:                logger,
+		pluginManager:         pluginManager,
+		metricsAddress:        metricsAddress,
+		fileServerAddress:     fileServerAddress,
 	}
 
 	return s, nil
@@ -210,6 +238,16 @@ func (s *server) run() error {
 		return err
 	}
 
+	s.initFileServer(config)
+
+	if err := s.initEncryption(config); err != nil {
+		return err
+	}
+
+	s.metrics = metrics.NewServerMetrics()
+	s.metrics.RegisterAllMetrics()
+	go s.serveMetrics()
+
 	if err := s.runControllers(config); err != nil {
 		return err
 	}
@@ -217,6 +255,70 @@ func (s *server) run() error {
 	return nil
 }
 
+// serveMetrics exposes the server's Prometheus metrics over HTTP at /metrics. It blocks
+// until the HTTP server exits.
+func (s *server) serveMetrics() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	s.logger.WithField("address", s.metricsAddress).Info("Starting metrics server")
+	if err := http.ListenAndServe(s.metricsAddress, mux); err != nil {
+		s.logger.WithError(errors.WithStack(err)).Error("Error running metrics server")
+	}
+}
+
+// initFileServer starts serving the download URLs created by the filesystem backup storage
+// provider as soon as the default storage location or any BackupStorageLocation uses it. The
+// URLs of every location that uses it are served.
+func (s *server) initFileServer(config *api.Config) {
+	lister := s.sharedInformerFactory.Ark().V1().BackupStorageLocations().Lister()
+
+	fileServer := filesystem.NewFileServer(func() []map[string]string {
+		var configs []map[string]string
+		if config.BackupStorageProvider.Name == filesystem.ProviderName {
+			configs = append(configs, config.BackupStorageProvider.Config)
+		}
+
+		locations, err := lister.BackupStorageLocations(api.DefaultNamespace).List(labels.Everything())
+		if err != nil {
+			s.logger.WithError(errors.WithStack(err)).Error("Error listing backup storage locations")
+		}
+		for _, location := range locations {
+			if location.Spec.Provider == filesystem.ProviderName {
+				configs = append(configs, location.Spec.Config)
+			}
+		}
+
+		return configs
+	}, s.logger)
+
+	if config.BackupStorageProvider.Name == filesystem.ProviderName {
+		s.serveFiles(fileServer)
+	}
+
+	serveFilesFor := func(obj interface{}) {
+		if location, ok := obj.(*api.BackupStorageLocation); ok && location.Spec.Provider == filesystem.ProviderName {
+			s.serveFiles(fileServer)
+		}
+	}
+	s.sharedInformerFactory.Ark().V1().BackupStorageLocations().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    serveFilesFor,
+		UpdateFunc: func(_, obj interface{}) { serveFilesFor(obj) },
+	})
+}
+
+// serveFiles starts the file server in the background, unless it's already been started.
+func (s *server) serveFiles(handler http.Handler) {
+	s.fileServerOnce.Do(func() {
+		go func() {
+			s.logger.WithField("address", s.fileServerAddress).Info("Starting file server")
+			if err := http.ListenAndServe(s.fileServerAddress, handler); err != nil {
+				s.logger.WithError(errors.WithStack(err)).Error("Error running file server")
+			}
+		}()
+	})
+}
+
 func (s *server) ensureArkNamespace() error {
 	logContext := s.logger.WithField("namespace", api.DefaultNamespace)
 
@@ -263,6 +365,9 @@ const (
 	defaultGCSyncPeriod       = 60 * time.Minute
 	defaultBackupSyncPeriod   = 60 * time.Minute
 	defaultScheduleSyncPeriod = time.Minute
+	defaultBackupWorkers      = 1
+	defaultBackupItemWorkers  = 1
+	defaultRestoreWorkers     = 1
 )
 
 var defaultResourcePriorities = []string{
@@ -292,6 +397,26 @@ func applyConfigDefaults(c *api.Config, logger *logrus.Logger) {
 	} else {
 		logger.WithField("priorities", c.ResourcePriorities).Info("Using resource priorities from config")
 	}
+
+	if c.PodVolumeRestoreHelperImage == "" {
+		c.PodVolumeRestoreHelperImage = podvolume.DefaultRestoreHelperImage
+	}
+
+	if c.BackupWorkers <= 0 {
+		c.BackupWorkers = defaultBackupWorkers
+	}
+
+	if c.BackupItemWorkers <= 0 {
+		c.BackupItemWorkers = defaultBackupItemWorkers
+	}
+
+	if c.RestoreWorkers <= 0 {
+		c.RestoreWorkers = defaultRestoreWorkers
+	}
+
+	if c.RestoreClientBurst <= 0 {
+		c.RestoreClientBurst = rest.DefaultBurst
+	}
 }
 
 // watchConfig adds an update event handler to the Config shared informer, invoking s.cancelFunc
@@ -334,7 +459,23 @@ func (s *server) initBackupService(config *api.Config) error {
 		return err
 	}
 
-	s.backupService = cloudprovider.NewBackupService(objectStore, s.logger)
+	s.backupService = cloudprovider.NewBackupService(objectStore, config.BackupStorageProvider.Prefix, s.logger)
+	return nil
+}
+
+func (s *server) initEncryption(config *api.Config) error {
+	if config.Encryption == nil {
+		s.logger.Info("Encryption config not provided, backups will not be encrypted")
+		return nil
+	}
+
+	s.logger.WithField("provider", config.Encryption.Provider).Info("Configuring encryption key provider")
+	keyProvider, err := encryption.NewKeyProvider(config.Encryption, s.kubeClient.CoreV1())
+	if err != nil {
+		return err
+	}
+
+	s.keyProvider = keyProvider
 	return nil
 }
 
@@ -349,7 +490,7 @@ func (s *server) initSnapshotService(config *api.Config) error {
 	if err != nil {
 		return err
 	}
-	s.snapshotService = cloudprovider.NewSnapshotService(blockStore)
+	s.snapshotService = cloudprovider.NewSnapshotService(blockStore, config.PersistentVolumeProvider.Name)
 	return nil
 }
 
@@ -370,6 +511,23 @@ func getObjectStore(cloudConfig api.CloudProviderConfig, manager plugin.Manager)
 	return objectStore, nil
 }
 
+func getObjectStoreForLocation(location *api.BackupStorageLocation, manager plugin.Manager) (cloudprovider.ObjectStore, error) {
+	if location.Spec.Provider == "" {
+		return nil, errors.Errorf("backup storage location %s must specify a provider", location.Name)
+	}
+
+	objectStore, err := manager.GetObjectStoreForLocation(location.Spec.Provider, location.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := objectStore.Init(location.Spec.Config); err != nil {
+		return nil, err
+	}
+
+	return objectStore, nil
+}
+
 func getBlockStore(cloudConfig api.CloudProviderConfig, manager plugin.Manager) (cloudprovider.BlockStore, error) {
 	if cloudConfig.Name == "" {
 		return nil, errors.New("block storage provider name must not be empty")
@@ -394,6 +552,26 @@ func durationMin(a, b time.Duration) time.Duration {
 	return b
 }
 
+// newStorageLocationResolver returns a StorageLocationResolver for the default storage location
+// in config and the BackupStorageLocations in the shared informer factory.
+func (s *server) newStorageLocationResolver(config *api.Config) cloudprovider.StorageLocationResolver {
+	return cloudprovider.NewStorageLocationResolver(
+		&cloudprovider.StorageLocation{
+			Name:          api.DefaultBackupStorageLocation,
+			Bucket:        config.BackupStorageProvider.Bucket,
+			BackupService: s.backupService,
+		},
+		s.sharedInformerFactory.Ark().V1().BackupStorageLocations().Lister(),
+		func(location *api.BackupStorageLocation) (cloudprovider.BackupService, error) {
+			objectStore, err := getObjectStoreForLocation(location, s.pluginManager)
+			if err != nil {
+				return nil, err
+			}
+			return cloudprovider.NewBackupService(objectStore, location.Spec.Prefix, s.logger), nil
+		},
+	)
+}
+
 func (s *server) runControllers(config *api.Config) error {
 	s.logger.Info("Starting controllers")
 
@@ -409,10 +587,19 @@ func (s *server) runControllers(config *api.Config) error {
 		s.logger,
 	)
 
+	storageLocationInformer := s.sharedInformerFactory.Ark().V1().BackupStorageLocations()
+	storageLocations := s.newStorageLocationResolver(config)
+
+	// Controllers resolve backup storage locations as soon as they start, so the locations
+	// need to be loaded first.
+	s.sharedInformerFactory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), storageLocationInformer.Informer().HasSynced) {
+		return errors.New("timed out waiting for backup storage locations cache to sync")
+	}
+
 	backupSyncController := controller.NewBackupSyncController(
 		s.arkClient.ArkV1(),
-		s.backupService,
-		config.BackupStorageProvider.Bucket,
+		storageLocations,
 		config.BackupSyncPeriod.Duration,
 		s.logger,
 	)
@@ -437,23 +624,25 @@ func (s *server) runControllers(config *api.Config) error {
 	)
 
 	if config.RestoreOnlyMode {
-		s.logger.Info("Restore only mode - not starting the backup, schedule or GC controllers")
+		s.logger.Info("Restore only mode - not starting the backup, schedule, GC or backup deletion controllers")
 	} else {
-		backupper, err := newBackupper(discoveryHelper, s.clientPool, s.backupService, s.snapshotService, s.kubeClientConfig, s.kubeClient.CoreV1())
+		backupper, err := newBackupper(discoveryHelper, s.clientPool, s.backupService, s.snapshotService, s.arkClient.ArkV1(), s.kubeClientConfig, s.kubeClient.CoreV1(), s.metrics, config.BackupItemWorkers)
 		cmd.CheckError(err)
 		backupController := controller.NewBackupController(
 			s.sharedInformerFactory.Ark().V1().Backups(),
 			s.arkClient.ArkV1(),
 			backupper,
-			s.backupService,
-			config.BackupStorageProvider.Bucket,
+			discoveryHelper,
+			storageLocations,
+			s.keyProvider,
 			s.snapshotService != nil,
 			s.logger,
 			s.pluginManager,
+			s.metrics,
 		)
 		wg.Add(1)
 		go func() {
-			backupController.Run(ctx, 1)
+			backupController.Run(ctx, config.BackupWorkers)
 			wg.Done()
 		}()
 
@@ -461,8 +650,10 @@ func (s *server) runControllers(config *api.Config) error {
 			s.arkClient.ArkV1(),
 			s.arkClient.ArkV1(),
 			s.sharedInformerFactory.Ark().V1().Schedules(),
+			s.sharedInformerFactory.Ark().V1().Backups(),
 			config.ScheduleSyncPeriod.Duration,
 			s.logger,
+			s.metrics,
 		)
 		wg.Add(1)
 		go func() {
@@ -471,21 +662,40 @@ func (s *server) runControllers(config *api.Config) error {
 		}()
 
 		gcController := controller.NewGCController(
-			s.backupService,
+			storageLocations,
 			s.snapshotService,
-			config.BackupStorageProvider.Bucket,
 			config.GCSyncPeriod.Duration,
 			s.sharedInformerFactory.Ark().V1().Backups(),
 			s.arkClient.ArkV1(),
 			s.sharedInformerFactory.Ark().V1().Restores(),
 			s.arkClient.ArkV1(),
+			s.sharedInformerFactory.Ark().V1().Schedules(),
+			s.keyProvider,
 			s.logger,
+			s.metrics,
 		)
 		wg.Add(1)
 		go func() {
 			gcController.Run(ctx, 1)
 			wg.Done()
 		}()
+
+		backupDeletionController := controller.NewBackupDeletionController(
+			s.arkClient.ArkV1(),
+			s.sharedInformerFactory.Ark().V1().DeleteBackupRequests(),
+			s.arkClient.ArkV1(),
+			s.sharedInformerFactory.Ark().V1().Backups(),
+			s.arkClient.ArkV1(),
+			s.sharedInformerFactory.Ark().V1().Restores(),
+			storageLocations,
+			s.snapshotService,
+			s.logger,
+		)
+		wg.Add(1)
+		go func() {
+			backupDeletionController.Run(ctx, 1)
+			wg.Done()
+		}()
 	}
 
 	restorer, err := newRestorer(
@@ -496,6 +706,12 @@ func (s *server) runControllers(config *api.Config) error {
 		config.ResourcePriorities,
 		s.arkClient.ArkV1(),
 		s.kubeClient,
+		s.kubeClientConfig,
+		config.PodVolumeRestoreHelperImage,
+		config.RestoreWorkers,
+		config.RestoreClientQPS,
+		config.RestoreClientBurst,
+		config.RestoreResourceReadiness,
 		s.logger,
 	)
 	cmd.CheckError(err)
@@ -505,11 +721,13 @@ func (s *server) runControllers(config *api.Config) error {
 		s.arkClient.ArkV1(),
 		s.arkClient.ArkV1(),
 		restorer,
-		s.backupService,
-		config.BackupStorageProvider.Bucket,
+		storageLocations,
+		s.keyProvider,
 		s.sharedInformerFactory.Ark().V1().Backups(),
 		s.snapshotService != nil,
 		s.logger,
+		s.pluginManager,
+		s.metrics,
 	)
 	wg.Add(1)
 	go func() {
@@ -520,8 +738,9 @@ func (s *server) runControllers(config *api.Config) error {
 	downloadRequestController := controller.NewDownloadRequestController(
 		s.arkClient.ArkV1(),
 		s.sharedInformerFactory.Ark().V1().DownloadRequests(),
-		s.backupService,
-		config.BackupStorageProvider.Bucket,
+		s.sharedInformerFactory.Ark().V1().Backups(),
+		s.sharedInformerFactory.Ark().V1().Restores(),
+		storageLocations,
 		s.logger,
 	)
 	wg.Add(1)
@@ -548,14 +767,20 @@ func newBackupper(
 	clientPool dynamic.ClientPool,
 	backupService cloudprovider.BackupService,
 	snapshotService cloudprovider.SnapshotService,
+	podVolumeBackupClient arkv1client.PodVolumeBackupsGetter,
 	kubeClientConfig *rest.Config,
 	kubeCoreV1Client kcorev1client.CoreV1Interface,
+	metrics *metrics.ServerMetrics,
+	itemWorkers int,
 ) (backup.Backupper, error) {
 	return backup.NewKubernetesBackupper(
 		discoveryHelper,
 		client.NewDynamicFactory(clientPool),
-		backup.NewPodCommandExecutor(kubeClientConfig, kubeCoreV1Client.RESTClient()),
+		podexec.NewPodCommandExecutor(kubeClientConfig, kubeCoreV1Client.RESTClient()),
 		snapshotService,
+		podvolume.NewBackupper(podVolumeBackupClient, podvolume.DefaultTimeout),
+		metrics,
+		itemWorkers,
 	)
 }
 
@@ -565,27 +790,39 @@ func newRestorer(
 	backupService cloudprovider.BackupService,
 	snapshotService cloudprovider.SnapshotService,
 	resourcePriorities []string,
-	backupClient arkv1client.BackupsGetter,
+	arkClient arkv1client.ArkV1Interface,
 	kubeClient kubernetes.Interface,
+	kubeClientConfig *rest.Config,
+	podVolumeRestoreHelperImage string,
+	workers int,
+	clientQPS float32,
+	clientBurst int,
+	resourceReadiness []api.ResourceReadiness,
 	logger *logrus.Logger,
 ) (restore.Restorer, error) {
-	restorers := map[string]restorers.ResourceRestorer{
-		"persistentvolumes":      restorers.NewPersistentVolumeRestorer(snapshotService),
-		"persistentvolumeclaims": restorers.NewPersistentVolumeClaimRestorer(),
-		"services":               restorers.NewServiceRestorer(),
-		"namespaces":             restorers.NewNamespaceRestorer(),
-		"pods":                   restorers.NewPodRestorer(logger),
-		"jobs":                   restorers.NewJobRestorer(logger),
+	// if restores' API requests are rate-limited separately, they get their own client pool
+	// whose clients all share a single rate limiter. Otherwise each of the pool's clients
+	// has its own.
+	if clientQPS > 0 {
+		restoreClientConfig := *kubeClientConfig
+		restoreClientConfig.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(clientQPS, clientBurst)
+		clientPool = dynamic.NewDynamicClientPool(&restoreClientConfig)
 	}
 
 	return restore.NewKubernetesRestorer(
 		discoveryHelper,
 		client.NewDynamicFactory(clientPool),
-		restorers,
+		restorers.NewDefaultRestorers(snapshotService, logger),
 		backupService,
 		resourcePriorities,
-		backupClient,
+		arkClient,
 		kubeClient.CoreV1().Namespaces(),
+		kubeClient.CoreV1(),
+		podexec.NewPodCommandExecutor(kubeClientConfig, kubeClient.CoreV1().RESTClient()),
+		podvolume.NewRestorer(arkClient, podvolume.DefaultTimeout),
+		podVolumeRestoreHelperImage,
+		workers,
+		resourceReadiness,
 		logger,
 	)
 }
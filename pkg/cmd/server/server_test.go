@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/podvolume"
 )
 
 func TestApplyConfigDefaults(t *testing.T) {
@@ -38,16 +39,19 @@ func TestApplyConfigDefaults(t *testing.T) {
 	assert.Equal(t, defaultBackupSyncPeriod, c.BackupSyncPeriod.Duration)
 	assert.Equal(t, defaultScheduleSyncPeriod, c.ScheduleSyncPeriod.Duration)
 	assert.Equal(t, defaultResourcePriorities, c.ResourcePriorities)
+	assert.Equal(t, podvolume.DefaultRestoreHelperImage, c.PodVolumeRestoreHelperImage)
 
 	// make sure defaulting doesn't overwrite real values
 	c.GCSyncPeriod.Duration = 5 * time.Minute
 	c.BackupSyncPeriod.Duration = 4 * time.Minute
 	c.ScheduleSyncPeriod.Duration = 3 * time.Minute
 	c.ResourcePriorities = []string{"a", "b"}
+	c.PodVolumeRestoreHelperImage = "helper"
 
 	applyConfigDefaults(c, logger)
 	assert.Equal(t, 5*time.Minute, c.GCSyncPeriod.Duration)
 	assert.Equal(t, 4*time.Minute, c.BackupSyncPeriod.Duration)
 	assert.Equal(t, 3*time.Minute, c.ScheduleSyncPeriod.Duration)
 	assert.Equal(t, []string{"a", "b"}, c.ResourcePriorities)
+	assert.Equal(t, "helper", c.PodVolumeRestoreHelperImage)
 }
@@ -30,10 +30,19 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/encryption"
 	arkclientv1 "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
 )
 
-func Stream(client arkclientv1.DownloadRequestsGetter, name string, kind v1.DownloadTargetKind, w io.Writer, timeout time.Duration) error {
+// Stream downloads the file identified by name and kind from object storage and writes it to w.
+// Encrypted files are decrypted using keyProvider. A backup's files must be encrypted if the
+// backup recorded an encryption key.
+func Stream(client arkclientv1.ArkV1Interface, name string, kind v1.DownloadTargetKind, w io.Writer, timeout time.Duration, keyProvider encryption.KeyProvider) error {
+	keyID, err := recordedKeyID(client, name, kind)
+	if err != nil {
+		return err
+	}
+
 	req := &v1.DownloadRequest{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: v1.DefaultNamespace,
@@ -47,7 +56,7 @@ func Stream(client arkclientv1.DownloadRequestsGetter, name string, kind v1.Down
 		},
 	}
 
-	req, err := client.DownloadRequests(v1.DefaultNamespace).Create(req)
+	req, err = client.DownloadRequests(v1.DefaultNamespace).Create(req)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -125,10 +134,14 @@ Loop:
 		return errors.Errorf("request failed: %v", string(body))
 	}
 
-	reader := resp.Body
+	reader, err := encryption.NewDecryptingReaderForKeyID(resp.Body, keyProvider, keyID)
+	if err != nil {
+		return err
+	}
+
 	if kind != v1.DownloadTargetKindBackupContents {
 		// need to decompress logs
-		gzipReader, err := gzip.NewReader(resp.Body)
+		gzipReader, err := gzip.NewReader(reader)
 		if err != nil {
 			return err
 		}
@@ -139,3 +152,22 @@ Loop:
 	_, err = io.Copy(w, reader)
 	return err
 }
+
+// recordedKeyID returns the ID of the key that the file identified by name and kind was recorded as
+// being encrypted with, or "" if none was recorded.
+func recordedKeyID(client arkclientv1.BackupsGetter, name string, kind v1.DownloadTargetKind) (string, error) {
+	switch kind {
+	case v1.DownloadTargetKindBackupContents, v1.DownloadTargetKindBackupLog,
+		v1.DownloadTargetKindBackupResults, v1.DownloadTargetKindBackupContentsIndex:
+	default:
+		// restores don't record the key their files were encrypted with
+		return "", nil
+	}
+
+	backup, err := client.Backups(v1.DefaultNamespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return backup.Status.EncryptionKeyID, nil
+}
@@ -21,12 +21,14 @@ import (
 	"compress/gzip"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/encryption"
 	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -49,6 +51,8 @@ func TestStream(t *testing.T) {
 		updateWithURL bool
 		statusCode    int
 		body          string
+		encrypted     bool
+		keyID         string
 		deleteError   error
 		expectedError string
 	}{
@@ -100,13 +104,38 @@ func TestStream(t *testing.T) {
 			body:          "some error",
 			expectedError: "request failed: some error",
 		},
+		{
+			name:          "encrypted log is decrypted",
+			kind:          v1.DownloadTargetKindBackupLog,
+			updateWithURL: true,
+			statusCode:    http.StatusOK,
+			body:          "download body",
+			encrypted:     true,
+			keyID:         "local",
+		},
+		{
+			name:          "plaintext log of an encrypted backup is rejected",
+			kind:          v1.DownloadTargetKindBackupLog,
+			updateWithURL: true,
+			statusCode:    http.StatusOK,
+			body:          "download body",
+			keyID:         "local",
+			expectedError: "file is not encrypted",
+		},
 	}
 
+	keyProvider, err := encryption.NewLocalKeyProviderForKey(bytes.Repeat([]byte("k"), 32))
+	require.NoError(t, err)
+
 	const testTimeout = 30 * time.Second
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			client := fake.NewSimpleClientset()
+			backup := &v1.Backup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: v1.DefaultNamespace, Name: "name"},
+				Status:     v1.BackupStatus{EncryptionKeyID: test.keyID},
+			}
+			client := fake.NewSimpleClientset(backup)
 
 			created := make(chan *v1.DownloadRequest, 1)
 			client.PrependReactor("create", "downloadrequests", func(action core.Action) (bool, runtime.Object, error) {
@@ -136,9 +165,16 @@ func TestStream(t *testing.T) {
 				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 					w.WriteHeader(test.statusCode)
 					if test.statusCode == http.StatusOK {
-						gzipWriter := gzip.NewWriter(w)
+						var body io.WriteCloser = nopWriteCloser{w}
+						if test.encrypted {
+							encryptingWriter, err := encryption.NewEncryptingWriter(w, keyProvider)
+							require.NoError(t, err)
+							body = encryptingWriter
+						}
+						gzipWriter := gzip.NewWriter(body)
 						fmt.Fprintf(gzipWriter, test.body)
 						gzipWriter.Close()
+						body.Close()
 						return
 					}
 					fmt.Fprintf(w, test.body)
@@ -150,7 +186,7 @@ func TestStream(t *testing.T) {
 			output := new(bytes.Buffer)
 			errCh := make(chan error)
 			go func() {
-				err := Stream(client.ArkV1(), "name", test.kind, output, timeout)
+				err := Stream(client.ArkV1(), "name", test.kind, output, timeout, keyProvider)
 				errCh <- err
 			}()
 
@@ -222,3 +258,9 @@ func newDownloadRequest(name string) *downloadRequest {
 		},
 	}
 }
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,108 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloadrequest
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/encryption"
+)
+
+// serverKeyProvider is an encryption.KeyProvider that uses the key provider configured for the
+// Ark server. It's loaded the first time it's used, so files that aren't encrypted can be
+// downloaded without access to the Ark config or the encryption key.
+type serverKeyProvider struct {
+	factory client.Factory
+
+	once        sync.Once
+	keyProvider encryption.KeyProvider
+	err         error
+}
+
+// NewServerKeyProvider returns an encryption.KeyProvider that uses the key provider configured
+// in the Ark server's Config, for decrypting downloaded files.
+func NewServerKeyProvider(f client.Factory) encryption.KeyProvider {
+	return &serverKeyProvider{factory: f}
+}
+
+func (p *serverKeyProvider) load() (encryption.KeyProvider, error) {
+	p.once.Do(func() {
+		arkClient, err := p.factory.Client()
+		if err != nil {
+			p.err = err
+			return
+		}
+
+		config, err := arkClient.ArkV1().Configs(v1.DefaultNamespace).Get("default", metav1.GetOptions{})
+		if err != nil {
+			p.err = errors.Wrap(err, "error getting Ark config")
+			return
+		}
+		if config.Encryption == nil {
+			p.err = errors.New("file is encrypted, but encryption is not configured in the Ark config")
+			return
+		}
+
+		kubeClient, err := p.factory.KubeClient()
+		if err != nil {
+			p.err = err
+			return
+		}
+
+		p.keyProvider, p.err = encryption.NewKeyProvider(config.Encryption, kubeClient.CoreV1())
+	})
+
+	return p.keyProvider, p.err
+}
+
+func (p *serverKeyProvider) KeyID() string {
+	keyProvider, err := p.load()
+	if err != nil {
+		return ""
+	}
+	return keyProvider.KeyID()
+}
+
+func (p *serverKeyProvider) EncryptKey(dataKey []byte) ([]byte, error) {
+	keyProvider, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	return keyProvider.EncryptKey(dataKey)
+}
+
+func (p *serverKeyProvider) DecryptKey(keyID string, encryptedKey []byte) ([]byte, error) {
+	keyProvider, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	return keyProvider.DecryptKey(keyID, encryptedKey)
+}
+
+func (p *serverKeyProvider) DeriveKey(purpose string) ([]byte, error) {
+	keyProvider, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	return keyProvider.DeriveKey(purpose)
+}
@@ -17,14 +17,25 @@ limitations under the License.
 package output
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/cmd/util/downloadrequest"
+	"github.com/heptio/ark/pkg/encryption"
+	clientset "github.com/heptio/ark/pkg/generated/clientset/versioned"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func DescribeBackup(backup *v1.Backup) string {
+// DescribeBackup describes a backup. If details is true, the warnings and errors from the
+// backup's results file, and the items in its contents index, are downloaded and included.
+func DescribeBackup(backup *v1.Backup, details bool, arkClient clientset.Interface, keyProvider encryption.KeyProvider) string {
 	return Describe(func(d *Describer) {
 		d.DescribeMetadata(backup.ObjectMeta)
 
@@ -33,6 +44,14 @@ func DescribeBackup(backup *v1.Backup) string {
 
 		d.Println()
 		DescribeBackupStatus(d, backup.Status)
+
+		if details {
+			d.Println()
+			describeBackupResults(d, backup, arkClient, keyProvider)
+
+			d.Println()
+			describeBackupContents(d, backup, arkClient, keyProvider)
+		}
 	})
 }
 
@@ -83,6 +102,13 @@ func DescribeBackupSpec(d *Describer, spec v1.BackupSpec) {
 	d.Println()
 	d.Printf("TTL:\t%s\n", spec.TTL.Duration)
 
+	d.Println()
+	storageLocation := spec.StorageLocation
+	if storageLocation == "" {
+		storageLocation = v1.DefaultBackupStorageLocation
+	}
+	d.Printf("Storage Location:\t%s\n", storageLocation)
+
 	d.Println()
 	if len(spec.Hooks.Resources) == 0 {
 		d.Printf("Hooks:\t<none>\n")
@@ -128,21 +154,38 @@ func DescribeBackupSpec(d *Describer, spec v1.BackupSpec) {
 			}
 			d.Printf("\t\t\tLabel selector:\t%s\n", s)
 
-			for _, hook := range backupResourceHookSpec.Hooks {
-				if hook.Exec != nil {
-					d.Println()
-					d.Printf("\t\t\tExec Hook:\n")
-					d.Printf("\t\t\t\tContainer:\t%s\n", hook.Exec.Container)
-					d.Printf("\t\t\t\tCommand:\t%s\n", strings.Join(hook.Exec.Command, " "))
-					d.Printf("\t\t\t\tOn Error:\t%s\n", hook.Exec.OnError)
-					d.Printf("\t\t\t\tTimeout:\t%s\n", hook.Exec.Timeout.Duration)
-				}
-			}
+			describeBackupResourceHooks(d, "Pre Exec Hook", backupResourceHookSpec.Hooks)
+			describeBackupResourceHooks(d, "Pre Exec Hook", backupResourceHookSpec.PreHooks)
+			describeBackupResourceHooks(d, "Post Exec Hook", backupResourceHookSpec.PostHooks)
 		}
 	}
 
 }
 
+func describeBackupResourceHooks(d *Describer, name string, hooks []v1.BackupResourceHook) {
+	for _, hook := range hooks {
+		if hook.Exec != nil {
+			d.Println()
+			d.Printf("\t\t\t%s:\n", name)
+			d.Printf("\t\t\t\tContainer:\t%s\n", hook.Exec.Container)
+			d.Printf("\t\t\t\tCommand:\t%s\n", strings.Join(hook.Exec.Command, " "))
+			d.Printf("\t\t\t\tOn Error:\t%s\n", hook.Exec.OnError)
+			d.Printf("\t\t\t\tTimeout:\t%s\n", hook.Exec.Timeout.Duration)
+		}
+	}
+}
+
+// DescribeHookStatus describes the hooks attempted and failed during a backup or restore.
+func DescribeHookStatus(d *Describer, status *v1.HookStatus) {
+	if status == nil {
+		d.Printf("Hooks:\t<none>\n")
+		return
+	}
+	d.Printf("Hooks:\n")
+	d.Printf("\tAttempted:\t%d\n", status.HooksAttempted)
+	d.Printf("\tFailed:\t%d\n", status.HooksFailed)
+}
+
 func DescribeBackupStatus(d *Describer, status v1.BackupStatus) {
 	phase := status.Phase
 	if phase == "" {
@@ -153,9 +196,31 @@ func DescribeBackupStatus(d *Describer, status v1.BackupStatus) {
 	d.Println()
 	d.Printf("Backup Format Version:\t%d\n", status.Version)
 
+	d.Println()
+	d.Printf("Started:\t%s\n", timestampString(status.StartTimestamp))
+	d.Printf("Completed:\t%s\n", timestampString(status.CompletionTimestamp))
+
 	d.Println()
 	d.Printf("Expiration:\t%s\n", status.Expiration.Time)
 
+	d.Println()
+	if status.Progress == nil {
+		d.Printf("Progress:\t<none>\n")
+	} else {
+		d.Printf("Progress:\t%d of %d items backed up\n", status.Progress.ItemsBackedUp, status.Progress.TotalItems)
+	}
+
+	d.Println()
+	d.Printf("Warnings:\t%d\n", status.Warnings)
+	d.Printf("Errors:\t%d\n", status.Errors)
+
+	d.Println()
+	if status.EncryptionKeyID == "" {
+		d.Printf("Encryption:\t<none>\n")
+	} else {
+		d.Printf("Encryption Key ID:\t%s\n", status.EncryptionKeyID)
+	}
+
 	d.Println()
 	d.Printf("Validation errors:")
 	if len(status.ValidationErrors) == 0 {
@@ -166,6 +231,9 @@ func DescribeBackupStatus(d *Describer, status v1.BackupStatus) {
 		}
 	}
 
+	d.Println()
+	DescribeHookStatus(d, status.HookStatus)
+
 	d.Println()
 	if len(status.VolumeBackups) == 0 {
 		d.Printf("Persistent Volumes: <none included>\n")
@@ -184,3 +252,101 @@ func DescribeBackupStatus(d *Describer, status v1.BackupStatus) {
 		}
 	}
 }
+
+func describeBackupResults(d *Describer, backup *v1.Backup, arkClient clientset.Interface, keyProvider encryption.KeyProvider) {
+	if backup.Status.Warnings == 0 && backup.Status.Errors == 0 {
+		d.Printf("Warning details:\t<none>\nError details:\t<none>\n")
+		return
+	}
+
+	var buf bytes.Buffer
+	var resultMap map[string]v1.BackupResult
+
+	if err := downloadrequest.Stream(arkClient.ArkV1(), backup.Name, v1.DownloadTargetKindBackupResults, &buf, 30*time.Second, keyProvider); err != nil {
+		d.Printf("Warning details:\t<error getting warnings: %v>\n\nError details:\t<error getting errors: %v>\n", err, err)
+		return
+	}
+
+	if err := json.NewDecoder(&buf).Decode(&resultMap); err != nil {
+		d.Printf("Warning details:\t<error decoding warnings: %v>\n\nError details:\t<error decoding errors: %v>\n", err, err)
+		return
+	}
+
+	warnings := resultMap["warnings"]
+	describeResult(d, "Warning details", warnings.Ark, warnings.Cluster, warnings.Namespaces)
+	d.Println()
+	errs := resultMap["errors"]
+	describeResult(d, "Error details", errs.Ark, errs.Cluster, errs.Namespaces)
+}
+
+func describeBackupContents(d *Describer, backup *v1.Backup, arkClient clientset.Interface, keyProvider encryption.KeyProvider) {
+	index, err := GetBackupContentsIndex(arkClient, backup.Name, 30*time.Second, keyProvider)
+	if err != nil {
+		d.Printf("Contents:\t<error getting contents: %v>\n", err)
+		return
+	}
+
+	DescribeBackupContents(d, index)
+}
+
+// GetBackupContentsIndex downloads and decodes a backup's contents index.
+func GetBackupContentsIndex(arkClient clientset.Interface, backupName string, timeout time.Duration, keyProvider encryption.KeyProvider) (*v1.BackupContentsIndex, error) {
+	var buf bytes.Buffer
+	if err := downloadrequest.Stream(arkClient.ArkV1(), backupName, v1.DownloadTargetKindBackupContentsIndex, &buf, timeout, keyProvider); err != nil {
+		return nil, err
+	}
+
+	index := new(v1.BackupContentsIndex)
+	if err := json.NewDecoder(&buf).Decode(index); err != nil {
+		return nil, errors.Wrap(err, "error decoding backup contents index")
+	}
+
+	return index, nil
+}
+
+// DescribeBackupContents describes the items in a backup's contents index, grouped by resource,
+// with the number of items of each resource.
+func DescribeBackupContents(d *Describer, index *v1.BackupContentsIndex) {
+	if len(index.Items) == 0 {
+		d.Printf("Contents:\t<none>\n")
+		return
+	}
+
+	itemsByResource := make(map[string][]v1.BackupContentsIndexItem)
+	for _, item := range index.Items {
+		itemsByResource[item.Resource] = append(itemsByResource[item.Resource], item)
+	}
+
+	resources := make([]string, 0, len(itemsByResource))
+	for resource := range itemsByResource {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	d.Printf("Contents (%d items):\n", len(index.Items))
+	for _, resource := range resources {
+		items := itemsByResource[resource]
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].Namespace != items[j].Namespace {
+				return items[i].Namespace < items[j].Namespace
+			}
+			return items[i].Name < items[j].Name
+		})
+
+		d.Printf("\t%s (%d):\n", resource, len(items))
+		for _, item := range items {
+			name := item.Name
+			if item.Namespace != "" {
+				name = item.Namespace + "/" + name
+			}
+			d.Printf("\t\t%s\t%s\n", name, item.APIVersion)
+		}
+	}
+}
+
+func timestampString(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<n/a>"
+	}
+	return timestamp.Time.String()
+}
@@ -30,7 +30,7 @@ import (
 )
 
 var (
-	backupColumns = []string{"NAME", "STATUS", "CREATED", "EXPIRES", "SELECTOR"}
+	backupColumns = []string{"NAME", "STATUS", "PROGRESS", "WARNINGS", "ERRORS", "CREATED", "EXPIRES", "SELECTOR"}
 )
 
 func printBackupList(list *v1.BackupList, w io.Writer, options printers.PrintOptions) error {
@@ -88,7 +88,18 @@ func printBackup(backup *v1.Backup, w io.Writer, options printers.PrintOptions)
 		status = v1.BackupPhaseNew
 	}
 
-	if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s", name, status, backup.CreationTimestamp.Time, humanReadableTimeFromNow(expiration), metav1.FormatLabelSelector(backup.Spec.LabelSelector)); err != nil {
+	if _, err := fmt.Fprintf(
+		w,
+		"%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s",
+		name,
+		status,
+		backupProgress(backup.Status.Progress),
+		backup.Status.Warnings,
+		backup.Status.Errors,
+		backup.CreationTimestamp.Time,
+		humanReadableTimeFromNow(expiration),
+		metav1.FormatLabelSelector(backup.Spec.LabelSelector),
+	); err != nil {
 		return err
 	}
 
@@ -100,6 +111,15 @@ func printBackup(backup *v1.Backup, w io.Writer, options printers.PrintOptions)
 	return err
 }
 
+// backupProgress returns the number of items backed up out of the total, or "n/a"
+// if the backup hasn't reported any progress.
+func backupProgress(progress *v1.BackupProgress) string {
+	if progress == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%d/%d", progress.ItemsBackedUp, progress.TotalItems)
+}
+
 func humanReadableTimeFromNow(when time.Time) string {
 	if when.IsZero() {
 		return "n/a"
@@ -24,11 +24,12 @@ import (
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/cmd/util/downloadrequest"
+	"github.com/heptio/ark/pkg/encryption"
 	clientset "github.com/heptio/ark/pkg/generated/clientset/versioned"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func DescribeRestore(restore *v1.Restore, arkClient clientset.Interface) string {
+func DescribeRestore(restore *v1.Restore, arkClient clientset.Interface, keyProvider encryption.KeyProvider) string {
 	return Describe(func(d *Describer) {
 		d.DescribeMetadata(restore.ObjectMeta)
 
@@ -81,6 +82,19 @@ func DescribeRestore(restore *v1.Restore, arkClient clientset.Interface) string
 		d.Println()
 		d.Printf("Restore PVs:\t%s\n", BoolPointerString(restore.Spec.RestorePVs, "false", "true", "auto"))
 
+		d.Println()
+		s = string(restore.Spec.ExistingResourcePolicy)
+		if s == "" {
+			s = string(v1.ExistingResourcePolicyNone)
+		}
+		d.Printf("Existing resource policy:\t%s\n", s)
+
+		d.Println()
+		d.Printf("Dry run:\t%t\n", restore.Spec.DryRun)
+
+		d.Println()
+		describeRestoreHooks(d, restore.Spec.Hooks)
+
 		d.Println()
 		d.Printf("Phase:\t%s\n", restore.Status.Phase)
 
@@ -95,11 +109,63 @@ func DescribeRestore(restore *v1.Restore, arkClient clientset.Interface) string
 		}
 
 		d.Println()
-		describeRestoreResults(d, restore, arkClient)
+		DescribeHookStatus(d, restore.Status.HookStatus)
+
+		d.Println()
+		describeRestoreResults(d, restore, arkClient, keyProvider)
+
+		if restore.Spec.DryRun && restore.Status.Phase == v1.RestorePhaseCompleted {
+			d.Println()
+			describeDryRunReport(d, restore, arkClient, keyProvider)
+		}
 	})
 }
 
-func describeRestoreResults(d *Describer, restore *v1.Restore, arkClient clientset.Interface) {
+func describeRestoreHooks(d *Describer, hooks v1.RestoreHooks) {
+	if len(hooks.Resources) == 0 {
+		d.Printf("Hooks:\t<none>\n")
+		return
+	}
+
+	d.Printf("Hooks:\n")
+	for _, spec := range hooks.Resources {
+		d.Printf("\t%s:\n", spec.Name)
+
+		s := "*"
+		if len(spec.IncludedNamespaces) > 0 {
+			s = strings.Join(spec.IncludedNamespaces, ", ")
+		}
+		d.Printf("\t\tIncluded namespaces:\t%s\n", s)
+		s = "<none>"
+		if len(spec.ExcludedNamespaces) > 0 {
+			s = strings.Join(spec.ExcludedNamespaces, ", ")
+		}
+		d.Printf("\t\tExcluded namespaces:\t%s\n", s)
+		s = "<none>"
+		if spec.LabelSelector != nil {
+			s = metav1.FormatLabelSelector(spec.LabelSelector)
+		}
+		d.Printf("\t\tLabel selector:\t%s\n", s)
+
+		for _, hook := range spec.PostHooks {
+			if hook.Init != nil {
+				for _, container := range hook.Init.InitContainers {
+					d.Printf("\t\tInit Container:\t%s (%s)\n", container.Name, container.Image)
+				}
+			}
+			if hook.Exec != nil {
+				d.Printf("\t\tExec Hook:\n")
+				d.Printf("\t\t\tContainer:\t%s\n", hook.Exec.Container)
+				d.Printf("\t\t\tCommand:\t%s\n", strings.Join(hook.Exec.Command, " "))
+				d.Printf("\t\t\tOn Error:\t%s\n", hook.Exec.OnError)
+				d.Printf("\t\t\tExec Timeout:\t%s\n", hook.Exec.ExecTimeout.Duration)
+				d.Printf("\t\t\tWait Timeout:\t%s\n", hook.Exec.WaitTimeout.Duration)
+			}
+		}
+	}
+}
+
+func describeRestoreResults(d *Describer, restore *v1.Restore, arkClient clientset.Interface, keyProvider encryption.KeyProvider) {
 	if restore.Status.Warnings == 0 && restore.Status.Errors == 0 {
 		d.Printf("Warnings:\t<none>\nErrors:\t<none>\n")
 		return
@@ -108,7 +174,7 @@ func describeRestoreResults(d *Describer, restore *v1.Restore, arkClient clients
 	var buf bytes.Buffer
 	var resultMap map[string]v1.RestoreResult
 
-	if err := downloadrequest.Stream(arkClient.ArkV1(), restore.Name, v1.DownloadTargetKindRestoreResults, &buf, 30*time.Second); err != nil {
+	if err := downloadrequest.Stream(arkClient.ArkV1(), restore.Name, v1.DownloadTargetKindRestoreResults, &buf, 30*time.Second, keyProvider); err != nil {
 		d.Printf("Warnings:\t<error getting warnings: %v>\n\nErrors:\t<error getting errors: %v>\n", err, err)
 		return
 	}
@@ -118,21 +184,79 @@ func describeRestoreResults(d *Describer, restore *v1.Restore, arkClient clients
 		return
 	}
 
-	describeRestoreResult(d, "Warnings", resultMap["warnings"])
+	warnings := resultMap["warnings"]
+	describeResult(d, "Warnings", warnings.Ark, warnings.Cluster, warnings.Namespaces)
 	d.Println()
-	describeRestoreResult(d, "Errors", resultMap["errors"])
+	errs := resultMap["errors"]
+	describeResult(d, "Errors", errs.Ark, errs.Cluster, errs.Namespaces)
 }
 
-func describeRestoreResult(d *Describer, name string, result v1.RestoreResult) {
+// describeResult describes the messages in a backup or restore's warnings or errors.
+func describeResult(d *Describer, name string, ark, cluster []string, namespaces map[string][]string) {
 	d.Printf("%s:\n", name)
-	d.DescribeSlice(1, "Ark", result.Ark)
-	d.DescribeSlice(1, "Cluster", result.Cluster)
-	if len(result.Namespaces) == 0 {
+	d.DescribeSlice(1, "Ark", ark)
+	d.DescribeSlice(1, "Cluster", cluster)
+	if len(namespaces) == 0 {
 		d.Printf("\tNamespaces: <none>\n")
 	} else {
 		d.Printf("\tNamespaces:\n")
-		for ns, warnings := range result.Namespaces {
-			d.DescribeSlice(2, ns, warnings)
+		for ns, messages := range namespaces {
+			d.DescribeSlice(2, ns, messages)
+		}
+	}
+}
+
+func describeDryRunReport(d *Describer, restore *v1.Restore, arkClient clientset.Interface, keyProvider encryption.KeyProvider) {
+	var buf bytes.Buffer
+	var report v1.RestoreDryRunReport
+
+	if err := downloadrequest.Stream(arkClient.ArkV1(), restore.Name, v1.DownloadTargetKindRestoreDryRunReport, &buf, 30*time.Second, keyProvider); err != nil {
+		d.Printf("Dry-run report:\t<error getting report: %v>\n", err)
+		return
+	}
+
+	if err := json.NewDecoder(&buf).Decode(&report); err != nil {
+		d.Printf("Dry-run report:\t<error decoding report: %v>\n", err)
+		return
+	}
+
+	d.Printf("Dry-run report:\n")
+	for _, action := range []v1.RestoreDryRunAction{v1.RestoreDryRunActionCreate, v1.RestoreDryRunActionExists, v1.RestoreDryRunActionSkip} {
+		var items []v1.RestoreDryRunItem
+		for _, item := range report.Items {
+			if item.Action == action {
+				items = append(items, item)
+			}
+		}
+
+		if len(items) == 0 {
+			d.Printf("\t%s:\t<none>\n", action)
+			continue
+		}
+
+		d.Printf("\t%s:\n", action)
+		for _, item := range items {
+			name := item.Name
+			if item.Namespace != "" {
+				name = item.Namespace + "/" + name
+			}
+
+			if item.Message == "" {
+				d.Printf("\t\t%s %s\n", item.Resource, name)
+			} else {
+				d.Printf("\t\t%s %s:\t%s\n", item.Resource, name, item.Message)
+			}
+
+			for _, diff := range item.Diff {
+				backup, live := diff.Backup, diff.Live
+				if backup == "" {
+					backup = "<unset>"
+				}
+				if live == "" {
+					live = "<unset>"
+				}
+				d.Printf("\t\t\t%s:\t%s (backup), %s (cluster)\n", diff.Path, backup, live)
+			}
 		}
 	}
 }
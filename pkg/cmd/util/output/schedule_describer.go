@@ -18,11 +18,15 @@ package output
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
+	arkschedule "github.com/heptio/ark/pkg/schedule"
 )
 
-func DescribeSchedule(schedule *v1.Schedule) string {
+// DescribeSchedule describes a schedule. If the schedule has a retention policy, backups are the
+// schedule's backups, which are listed with whether the policy keeps or prunes them.
+func DescribeSchedule(schedule *v1.Schedule, backups []*v1.Backup) string {
 	return Describe(func(d *Describer) {
 		d.DescribeMetadata(schedule.ObjectMeta)
 
@@ -31,12 +35,66 @@ func DescribeSchedule(schedule *v1.Schedule) string {
 
 		d.Println()
 		DescribeScheduleStatus(d, schedule.Status)
+
+		if schedule.Spec.Retention != nil {
+			d.Println()
+			describeRetentionPreview(d, schedule.Spec.Retention, backups)
+		}
 	})
 }
 
 func DescribeScheduleSpec(d *Describer, spec v1.ScheduleSpec) {
 	d.Printf("Schedule:\t%s\n", spec.Schedule)
 
+	timeZone := spec.TimeZone
+	if timeZone == "" {
+		timeZone = "<server's local time zone>"
+	}
+	d.Printf("Time zone:\t%s\n", timeZone)
+	d.Printf("Paused:\t%t\n", spec.Paused)
+
+	concurrencyPolicy := spec.ConcurrencyPolicy
+	if concurrencyPolicy == "" {
+		concurrencyPolicy = v1.ScheduleConcurrencyPolicyAllow
+	}
+	d.Printf("Concurrency policy:\t%s\n", concurrencyPolicy)
+
+	startingDeadline := "<none>"
+	if spec.StartingDeadline != nil {
+		startingDeadline = spec.StartingDeadline.Duration.String()
+	}
+	d.Printf("Starting deadline:\t%s\n", startingDeadline)
+
+	d.Println()
+	d.Printf("Blackout windows:")
+	if len(spec.BlackoutWindows) == 0 {
+		d.Printf("\t<none>\n")
+	} else {
+		d.Println()
+		for _, window := range spec.BlackoutWindows {
+			action := window.Action
+			if action == "" {
+				action = v1.ScheduleBlackoutActionDefer
+			}
+			name := window.Name
+			if name == "" {
+				name = "<unnamed>"
+			}
+			d.Printf("\t%s:\tstarts %q, lasts %v, %s runs\n", name, window.Start, window.Duration.Duration, strings.ToLower(string(action)))
+		}
+	}
+
+	d.Println()
+	if spec.Retention == nil {
+		d.Printf("Retention:\t<none> (backups expire after their TTL)\n")
+	} else {
+		d.Printf("Retention:\n")
+		d.Printf("\tKeep last:\t%d\n", spec.Retention.KeepLast)
+		d.Printf("\tKeep daily:\t%d\n", spec.Retention.KeepDaily)
+		d.Printf("\tKeep weekly:\t%d\n", spec.Retention.KeepWeekly)
+		d.Printf("\tKeep monthly:\t%d\n", spec.Retention.KeepMonthly)
+	}
+
 	d.Println()
 	d.Println("Backup Template:")
 	d.Prefix = "\t"
@@ -65,4 +123,58 @@ func DescribeScheduleStatus(d *Describer, status v1.ScheduleStatus) {
 		lastBackup = fmt.Sprintf("%v", status.LastBackup.Time)
 	}
 	d.Printf("Last Backup:\t%s\n", lastBackup)
+
+	nextBackup := "<none>"
+	if !status.NextBackup.Time.IsZero() {
+		nextBackup = fmt.Sprintf("%v", status.NextBackup.Time)
+	}
+	d.Printf("Next Backup:\t%s\n", nextBackup)
+
+	d.Printf("Last Successful Backup:\t%s\n", describeScheduleBackupReference(status.LastSuccessfulBackup))
+	d.Printf("Last Failed Backup:\t%s\n", describeScheduleBackupReference(status.LastFailedBackup))
+
+	d.Println()
+	d.Printf("History:")
+	if len(status.History) == 0 {
+		d.Printf("\t<none>\n")
+		return
+	}
+	d.Println()
+	for _, run := range status.History {
+		result := string(run.Result)
+		if run.Backup != "" {
+			result = fmt.Sprintf("%s %s (%s)", result, run.Backup, run.BackupPhase)
+		}
+		if run.Triggered {
+			result = fmt.Sprintf("%s (triggered)", result)
+		}
+		if run.Message != "" {
+			result = fmt.Sprintf("%s: %s", result, run.Message)
+		}
+		d.Printf("\t%v\t%s\n", run.ScheduledTime.Time, result)
+	}
+}
+
+func describeScheduleBackupReference(ref *v1.ScheduleBackupReference) string {
+	if ref == nil {
+		return "<none>"
+	}
+	return fmt.Sprintf("%s (%v)", ref.Name, ref.Timestamp.Time)
+}
+
+func describeRetentionPreview(d *Describer, retention *v1.ScheduleRetention, backups []*v1.Backup) {
+	d.Printf("Retention preview:")
+	if len(backups) == 0 {
+		d.Printf("\t<no backups>\n")
+		return
+	}
+	d.Println()
+
+	for _, decision := range arkschedule.ApplyRetention(retention, backups) {
+		action := "prune"
+		if decision.Keep {
+			action = "keep"
+		}
+		d.Printf("\t%s\t%s\t%s (%s)\n", decision.Backup.Name, arkschedule.BackupTime(decision.Backup), action, strings.Join(decision.Reasons, ", "))
+	}
 }
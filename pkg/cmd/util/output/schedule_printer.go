@@ -48,9 +48,12 @@ func printSchedule(schedule *v1.Schedule, w io.Writer, options printers.PrintOpt
 		}
 	}
 
-	status := schedule.Status.Phase
+	status := string(schedule.Status.Phase)
 	if status == "" {
-		status = v1.SchedulePhaseNew
+		status = string(v1.SchedulePhaseNew)
+	}
+	if schedule.Spec.Paused && schedule.Status.Phase == v1.SchedulePhaseEnabled {
+		status = "Paused"
 	}
 
 	_, err := fmt.Fprintf(
@@ -20,8 +20,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"io/ioutil"
-	"os"
+	"io"
 	"sync"
 	"time"
 
@@ -38,9 +37,12 @@ import (
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/backup"
 	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/encryption"
 	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions/ark/v1"
 	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+	"github.com/heptio/ark/pkg/metrics"
 	"github.com/heptio/ark/pkg/plugin"
 	"github.com/heptio/ark/pkg/util/collections"
 	"github.com/heptio/ark/pkg/util/encode"
@@ -51,8 +53,8 @@ const backupVersion = 1
 
 type backupController struct {
 	backupper        backup.Backupper
-	backupService    cloudprovider.BackupService
-	bucket           string
+	storageLocations cloudprovider.StorageLocationResolver
+	keyProvider      encryption.KeyProvider
 	pvProviderExists bool
 	lister           listers.BackupLister
 	listerSynced     cache.InformerSynced
@@ -62,22 +64,33 @@ type backupController struct {
 	clock            clock.Clock
 	logger           *logrus.Logger
 	pluginManager    plugin.Manager
+	metrics          *metrics.ServerMetrics
+	discoveryHelper  discovery.Helper
+
+	// runningLock guards running and waiting, which track the backups that are in
+	// progress and the keys of the backups that are waiting for one of them to finish
+	// because their scopes overlap.
+	runningLock sync.Mutex
+	running     map[string]backupScope
+	waiting     map[string]bool
 }
 
 func NewBackupController(
 	backupInformer informers.BackupInformer,
 	client arkv1client.BackupsGetter,
 	backupper backup.Backupper,
-	backupService cloudprovider.BackupService,
-	bucket string,
+	discoveryHelper discovery.Helper,
+	storageLocations cloudprovider.StorageLocationResolver,
+	keyProvider encryption.KeyProvider,
 	pvProviderExists bool,
 	logger *logrus.Logger,
 	pluginManager plugin.Manager,
+	metrics *metrics.ServerMetrics,
 ) Interface {
 	c := &backupController{
 		backupper:        backupper,
-		backupService:    backupService,
-		bucket:           bucket,
+		storageLocations: storageLocations,
+		keyProvider:      keyProvider,
 		pvProviderExists: pvProviderExists,
 		lister:           backupInformer.Lister(),
 		listerSynced:     backupInformer.Informer().HasSynced,
@@ -86,6 +99,10 @@ func NewBackupController(
 		clock:            &clock.RealClock{},
 		logger:           logger,
 		pluginManager:    pluginManager,
+		metrics:          metrics,
+		discoveryHelper:  discoveryHelper,
+		running:          make(map[string]backupScope),
+		waiting:          make(map[string]bool),
 	}
 
 	c.syncHandler = c.processBackup
@@ -238,7 +255,17 @@ func (controller *backupController) processBackup(key string) error {
 	if backup.Status.ValidationErrors = controller.getValidationErrors(backup); len(backup.Status.ValidationErrors) > 0 {
 		backup.Status.Phase = api.BackupPhaseFailedValidation
 	} else {
+		// backups whose scopes overlap could both include the same items, so they're
+		// not run at the same time. The backup stays new until the one it overlaps
+		// finishes, at which point it's re-queued.
+		if running := controller.startBackup(key, backup); running != "" {
+			logContext.WithField("runningBackup", running).Info("Backup overlaps a backup that's in progress, waiting")
+			return nil
+		}
+		defer controller.finishBackup(key)
+
 		backup.Status.Phase = api.BackupPhaseInProgress
+		backup.Status.StartTimestamp = metav1.NewTime(controller.clock.Now())
 	}
 
 	// update status
@@ -252,12 +279,24 @@ func (controller *backupController) processBackup(key string) error {
 		return nil
 	}
 
+	backupScheduleName := backup.GetLabels()[api.ScheduleNameLabel]
+	controller.metrics.RegisterBackupAttempt(backupScheduleName)
+
 	logContext.Debug("Running backup")
 	// execution & upload of backup
-	if err := controller.runBackup(backup, controller.bucket); err != nil {
+	backupStart := controller.clock.Now()
+	if err := controller.runBackup(backup); err != nil {
 		logContext.WithError(err).Error("backup failed")
 		backup.Status.Phase = api.BackupPhaseFailed
+		backup.Status.CompletionTimestamp = metav1.NewTime(controller.clock.Now())
+		controller.metrics.RegisterBackupFailed(backupScheduleName)
+	} else if backup.Status.Phase == api.BackupPhasePartiallyFailed {
+		controller.metrics.RegisterBackupPartialFailure(backupScheduleName)
+	} else {
+		controller.metrics.RegisterBackupSuccess(backupScheduleName)
+		controller.metrics.SetBackupLastSuccessfulTimestamp(backupScheduleName, controller.clock.Now())
 	}
+	controller.metrics.RegisterBackupDuration(backupScheduleName, controller.clock.Since(backupStart).Seconds())
 
 	logContext.Debug("Updating backup's final status")
 	if _, err = controller.client.Backups(ns).Update(backup); err != nil {
@@ -267,9 +306,47 @@ func (controller *backupController) processBackup(key string) error {
 	return nil
 }
 
+// startBackup records the backup with the given key as running, unless its scope
+// overlaps that of a backup that's already running, in which case it records it as
+// waiting and returns the running backup's key.
+func (controller *backupController) startBackup(key string, backup *api.Backup) string {
+	scope := newBackupScope(backup.Spec, controller.discoveryHelper)
+
+	controller.runningLock.Lock()
+	defer controller.runningLock.Unlock()
+
+	for runningKey, runningScope := range controller.running {
+		if scope.overlaps(runningScope) {
+			controller.waiting[key] = true
+			return runningKey
+		}
+	}
+
+	controller.running[key] = scope
+	return ""
+}
+
+// finishBackup removes the backup with the given key from the running backups and
+// re-queues all of the waiting backups.
+func (controller *backupController) finishBackup(key string) {
+	controller.runningLock.Lock()
+	defer controller.runningLock.Unlock()
+
+	delete(controller.running, key)
+
+	for waitingKey := range controller.waiting {
+		controller.queue.Add(waitingKey)
+		delete(controller.waiting, waitingKey)
+	}
+}
+
 func (controller *backupController) getValidationErrors(itm *api.Backup) []string {
 	var validationErrors []string
 
+	if itm.Name == cloudprovider.PodVolumesDirName {
+		validationErrors = append(validationErrors, fmt.Sprintf("Backup name %q is reserved", itm.Name))
+	}
+
 	for _, err := range collections.ValidateIncludesExcludes(itm.Spec.IncludedResources, itm.Spec.ExcludedResources) {
 		validationErrors = append(validationErrors, fmt.Sprintf("Invalid included/excluded resource lists: %v", err))
 	}
@@ -282,74 +359,225 @@ func (controller *backupController) getValidationErrors(itm *api.Backup) []strin
 		validationErrors = append(validationErrors, "Server is not configured for PV snapshots")
 	}
 
+	if _, err := controller.storageLocations.Get(itm.Spec.StorageLocation); err != nil {
+		validationErrors = append(validationErrors, fmt.Sprintf("Invalid storage location: %v", err))
+	}
+
 	return validationErrors
 }
 
-func (controller *backupController) runBackup(backup *api.Backup, bucket string) error {
-	backupFile, err := ioutil.TempFile("", "")
+func (controller *backupController) runBackup(backup *api.Backup) error {
+	location, err := controller.storageLocations.Get(backup.Spec.StorageLocation)
 	if err != nil {
-		return errors.Wrap(err, "error creating temp file for Backup")
+		return err
 	}
 
-	logFile, err := ioutil.TempFile("", "")
+	actions, err := controller.pluginManager.GetBackupItemActions(backup.Name, controller.logger, controller.logger.Level)
 	if err != nil {
-		return errors.Wrap(err, "error creating temp file for Backup log")
+		return err
 	}
+	defer controller.pluginManager.CloseBackupItemActions(backup.Name)
 
-	defer func() {
-		var errs []error
-		// TODO should this be wrapped?
-		errs = append(errs, err)
-
-		if err := backupFile.Close(); err != nil {
-			errs = append(errs, errors.Wrap(err, "error closing Backup temp file"))
+	logContext := controller.logger.WithField("backup", kubeutil.NamespaceAndName(backup))
+	logContext.Info("starting backup")
+
+	// the backup's tarball and log are streamed into object storage as they're written, rather
+	// than being written to local files and uploaded afterwards
+	contentsUpload := newStreamingUpload(func(r io.Reader) error {
+		return location.BackupService.UploadBackupContents(location.Bucket, backup.Name, r)
+	})
+	logUpload := newStreamingUpload(func(r io.Reader) error {
+		return location.BackupService.UploadBackupLog(location.Bucket, backup.Name, r)
+	})
+
+	var backupWriter, logWriter io.Writer = contentsUpload, logUpload
+	var encryptingWriters []io.Closer
+	if controller.keyProvider != nil {
+		encryptedBackup, err := encryption.NewEncryptingWriter(contentsUpload, controller.keyProvider)
+		if err != nil {
+			err = errors.Wrap(err, "error encrypting Backup")
+			return controller.abortBackupUpload(location, backup, err, contentsUpload, logUpload)
 		}
-
-		if err := os.Remove(backupFile.Name()); err != nil {
-			errs = append(errs, errors.Wrap(err, "error removing Backup temp file"))
+		encryptedLog, err := encryption.NewEncryptingWriter(logUpload, controller.keyProvider)
+		if err != nil {
+			err = errors.Wrap(err, "error encrypting Backup log")
+			return controller.abortBackupUpload(location, backup, err, contentsUpload, logUpload)
 		}
 
-		if err := logFile.Close(); err != nil {
-			errs = append(errs, errors.Wrap(err, "error closing Backup log temp file"))
-		}
+		backupWriter, logWriter = encryptedBackup, encryptedLog
+		encryptingWriters = append(encryptingWriters, encryptedBackup, encryptedLog)
+		backup.Status.EncryptionKeyID = controller.keyProvider.KeyID()
+	}
 
-		if err := os.Remove(logFile.Name()); err != nil {
-			errs = append(errs, errors.Wrap(err, "error removing Backup log temp file"))
+	progressUpdater := &backupProgressUpdater{
+		client: controller.client,
+		backup: backup.DeepCopy(),
+		logger: logContext,
+	}
+	warnings, backupErrs, contents, err := controller.backupper.Backup(backup, backupWriter, logWriter, actions, progressUpdater)
+	// progress updates change the backup's resource version, so make sure the final update
+	// isn't rejected as a conflict
+	backup.ResourceVersion = progressUpdater.backup.ResourceVersion
+	if err != nil {
+		return controller.abortBackupUpload(location, backup, err, contentsUpload, logUpload)
+	}
+
+	// closing the encrypting writers writes the final encrypted chunks
+	for _, w := range encryptingWriters {
+		if err := w.Close(); err != nil {
+			err = errors.Wrap(err, "error encrypting Backup")
+			return controller.abortBackupUpload(location, backup, err, contentsUpload, logUpload)
 		}
+	}
 
-		err = kuberrs.NewAggregate(errs)
-	}()
+	if err := contentsUpload.finish(nil); err != nil {
+		err = errors.Wrap(err, "error uploading Backup")
+		return controller.abortBackupUpload(location, backup, err, logUpload)
+	}
 
-	actions, err := controller.pluginManager.GetBackupItemActions(backup.Name, controller.logger, controller.logger.Level)
-	if err != nil {
-		return err
+	// uploading the log file is best-effort; if it fails, we log the error but call the overall
+	// upload a success
+	if err := logUpload.finish(nil); err != nil {
+		logContext.WithError(err).Error("Error uploading log file")
 	}
-	defer controller.pluginManager.CloseBackupItemActions(backup.Name)
 
-	controller.logger.WithField("backup", kubeutil.NamespaceAndName(backup)).Info("starting backup")
+	// likewise for the results file, since the warning and error counts are in the backup's status
+	if err := controller.uploadBackupResults(location, backup.Name, warnings, backupErrs); err != nil {
+		logContext.WithError(err).Error("Error uploading results file")
+	}
 
-	if err := controller.backupper.Backup(backup, backupFile, logFile, actions); err != nil {
-		return err
+	// and for the contents index, which is only used to list what's in the backup
+	if err := controller.uploadBackupContentsIndex(location, backup.Name, contents); err != nil {
+		logContext.WithError(err).Error("Error uploading contents index")
 	}
 
-	controller.logger.WithField("backup", kubeutil.NamespaceAndName(backup)).Info("backup completed")
+	logContext.Info("backup completed")
+
+	controller.metrics.SetBackupTarballSizeBytesGauge(backup.GetLabels()[api.ScheduleNameLabel], contentsUpload.bytes)
 
 	// note: updating this here so the uploaded JSON shows "completed". If
 	// the upload fails, we'll alter the phase in the calling func.
-	backup.Status.Phase = api.BackupPhaseCompleted
+	if backup.Status.Errors > 0 {
+		backup.Status.Phase = api.BackupPhasePartiallyFailed
+	} else {
+		backup.Status.Phase = api.BackupPhaseCompleted
+	}
+	backup.Status.CompletionTimestamp = metav1.NewTime(controller.clock.Now())
 
 	buf := new(bytes.Buffer)
 	if err := encode.EncodeTo(backup, "json", buf); err != nil {
-		return errors.Wrap(err, "error encoding Backup")
+		err = errors.Wrap(err, "error encoding Backup")
+		return controller.abortBackupUpload(location, backup, err)
+	}
+
+	// the metadata is uploaded last, since backups are only synced from object storage once
+	// their metadata exists
+	if err := location.BackupService.UploadBackupMetadata(location.Bucket, backup.Name, buf); err != nil {
+		return controller.abortBackupUpload(location, backup, err)
+	}
+
+	return nil
+}
+
+// uploadBackupResults uploads a gzipped JSON file containing a backup's warnings and errors,
+// encrypted if encryption is configured.
+func (controller *backupController) uploadBackupResults(location *cloudprovider.StorageLocation, backupName string, warnings, errs api.BackupResult) error {
+	results := map[string]api.BackupResult{
+		"warnings": warnings,
+		"errors":   errs,
 	}
 
-	// re-set the files' offset to 0 for reading
-	if _, err = backupFile.Seek(0, 0); err != nil {
-		return errors.Wrap(err, "error resetting Backup file offset")
+	return encodeAndUpload(controller.keyProvider, results, func(r io.Reader) error {
+		return location.BackupService.UploadBackupResults(location.Bucket, backupName, r)
+	})
+}
+
+// uploadBackupContentsIndex uploads a gzipped JSON file listing the items in a backup, encrypted
+// if encryption is configured.
+func (controller *backupController) uploadBackupContentsIndex(location *cloudprovider.StorageLocation, backupName string, contents *api.BackupContentsIndex) error {
+	return encodeAndUpload(controller.keyProvider, contents, func(r io.Reader) error {
+		return location.BackupService.UploadBackupContentsIndex(location.Bucket, backupName, r)
+	})
+}
+
+// abortBackupUpload aborts any in-progress uploads and deletes anything that was already uploaded
+// for a backup that failed, returning err along with any errors cleaning up.
+func (controller *backupController) abortBackupUpload(location *cloudprovider.StorageLocation, backup *api.Backup, err error, uploads ...*streamingUpload) error {
+	errs := []error{err}
+
+	for _, upload := range uploads {
+		// the upload is expected to fail since it's being aborted
+		upload.finish(err)
 	}
-	if _, err = logFile.Seek(0, 0); err != nil {
-		return errors.Wrap(err, "error resetting Backup log file offset")
+
+	if deleteErr := location.BackupService.DeleteBackupDir(location.Bucket, backup.Name); deleteErr != nil {
+		errs = append(errs, errors.Wrap(deleteErr, "error deleting partially-uploaded Backup"))
 	}
 
-	return controller.backupService.UploadBackup(bucket, backup.Name, buf, backupFile, logFile)
+	return kuberrs.NewAggregate(errs)
+}
+
+// backupProgressUpdater is a backup.ProgressReporter that saves a running backup's progress
+// to the Backup API object.
+type backupProgressUpdater struct {
+	client arkv1client.BackupsGetter
+	// backup is the latest version of the Backup
+	backup *api.Backup
+	logger logrus.FieldLogger
+}
+
+func (u *backupProgressUpdater) ReportProgress(progress api.BackupProgress, warnings, errors int) {
+	backup := u.backup.DeepCopy()
+	backup.Status.Progress = &progress
+	backup.Status.Warnings = warnings
+	backup.Status.Errors = errors
+
+	updated, err := u.client.Backups(backup.Namespace).Update(backup)
+	if err != nil {
+		u.logger.WithError(err).Warn("Error updating backup's progress")
+		return
+	}
+	u.backup = updated
+}
+
+// streamingUpload is an io.Writer that uploads everything written to it to object storage as
+// it's written.
+type streamingUpload struct {
+	writer *io.PipeWriter
+	done   chan error
+	// bytes is the number of bytes written
+	bytes int64
+}
+
+// newStreamingUpload starts an upload that reads what's written to the returned streamingUpload
+// using upload.
+func newStreamingUpload(upload func(io.Reader) error) *streamingUpload {
+	reader, writer := io.Pipe()
+
+	u := &streamingUpload{
+		writer: writer,
+		done:   make(chan error, 1),
+	}
+
+	go func() {
+		err := upload(reader)
+		// make sure writes fail instead of blocking if the upload stopped reading early
+		reader.CloseWithError(err)
+		u.done <- err
+	}()
+
+	return u
+}
+
+func (u *streamingUpload) Write(p []byte) (int, error) {
+	n, err := u.writer.Write(p)
+	u.bytes += int64(n)
+	return n, err
+}
+
+// finish ends the upload and waits for it to complete, returning its result. If err is non-nil,
+// the upload is aborted by failing its reads with err, so a partial object isn't stored.
+func (u *streamingUpload) finish(err error) error {
+	u.writer.CloseWithError(err)
+	return <-u.done
 }
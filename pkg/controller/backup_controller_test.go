@@ -17,10 +17,13 @@ limitations under the License.
 package controller
 
 import (
+	"errors"
 	"io"
+	"io/ioutil"
 	"testing"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/clock"
 	core "k8s.io/client-go/testing"
@@ -37,6 +40,8 @@ import (
 	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
 	"github.com/heptio/ark/pkg/generated/clientset/versioned/scheme"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
+	"github.com/heptio/ark/pkg/metrics"
+	"github.com/heptio/ark/pkg/restore"
 	. "github.com/heptio/ark/pkg/util/test"
 )
 
@@ -44,9 +49,9 @@ type fakeBackupper struct {
 	mock.Mock
 }
 
-func (b *fakeBackupper) Backup(backup *v1.Backup, data, log io.Writer, actions []backup.ItemAction) error {
-	args := b.Called(backup, data, log, actions)
-	return args.Error(0)
+func (b *fakeBackupper) Backup(backup *v1.Backup, data, log io.Writer, actions []backup.ItemAction, progressReporter backup.ProgressReporter) (v1.BackupResult, v1.BackupResult, *v1.BackupContentsIndex, error) {
+	args := b.Called(backup, data, log, actions, progressReporter)
+	return args.Get(0).(v1.BackupResult), args.Get(1).(v1.BackupResult), args.Get(2).(*v1.BackupContentsIndex), args.Error(3)
 }
 
 // Manager is an autogenerated mock type for the Manager type
@@ -114,6 +119,29 @@ func (_m *Manager) GetBlockStore(name string) (cloudprovider.BlockStore, error)
 	return r0, r1
 }
 
+// GetObjectStoreForLocation provides a mock function with given fields: name, location
+func (_m *Manager) GetObjectStoreForLocation(name string, location string) (cloudprovider.ObjectStore, error) {
+	ret := _m.Called(name, location)
+
+	var r0 cloudprovider.ObjectStore
+	if rf, ok := ret.Get(0).(func(string, string) cloudprovider.ObjectStore); ok {
+		r0 = rf(name, location)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(cloudprovider.ObjectStore)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(name, location)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetObjectStore provides a mock function with given fields: name
 func (_m *Manager) GetObjectStore(name string) (cloudprovider.ObjectStore, error) {
 	ret := _m.Called(name)
@@ -137,6 +165,43 @@ func (_m *Manager) GetObjectStore(name string) (cloudprovider.ObjectStore, error
 	return r0, r1
 }
 
+// CloseRestoreItemActions provides a mock function with given fields: restoreName
+func (_m *Manager) CloseRestoreItemActions(restoreName string) error {
+	ret := _m.Called(restoreName)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(restoreName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetRestoreItemActions provides a mock function with given fields: restoreName, logger, level
+func (_m *Manager) GetRestoreItemActions(restoreName string, logger logrus.FieldLogger, level logrus.Level) ([]restore.ItemAction, error) {
+	ret := _m.Called(restoreName, logger, level)
+
+	var r0 []restore.ItemAction
+	if rf, ok := ret.Get(0).(func(string, logrus.FieldLogger, logrus.Level) []restore.ItemAction); ok {
+		r0 = rf(restoreName, logger, level)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]restore.ItemAction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, logrus.FieldLogger, logrus.Level) error); ok {
+		r1 = rf(restoreName, logger, level)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 func TestProcessBackup(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -147,6 +212,8 @@ func TestProcessBackup(t *testing.T) {
 		backup           *TestBackup
 		expectBackup     bool
 		allowSnapshots   bool
+		itemErrors       int
+		expectedPhase    v1.BackupPhase
 	}{
 		{
 			name:        "bad key",
@@ -220,6 +287,18 @@ func TestProcessBackup(t *testing.T) {
 			backup:       NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew).WithTTL(10 * time.Minute),
 			expectBackup: true,
 		},
+		{
+			name:         "nonexistent storage location fails validation",
+			key:          "heptio-ark/backup1",
+			backup:       NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew).WithStorageLocation("does-not-exist"),
+			expectBackup: false,
+		},
+		{
+			name:         "backup with reserved name fails validation",
+			key:          "heptio-ark/podvolumes",
+			backup:       NewTestBackup().WithName("podvolumes").WithPhase(v1.BackupPhaseNew),
+			expectBackup: false,
+		},
 		{
 			name:         "backup with SnapshotVolumes when allowSnapshots=false fails validation",
 			key:          "heptio-ark/backup1",
@@ -233,6 +312,14 @@ func TestProcessBackup(t *testing.T) {
 			allowSnapshots: true,
 			expectBackup:   true,
 		},
+		{
+			name:          "backup with errors backing up items is partially failed",
+			key:           "heptio-ark/backup1",
+			backup:        NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew),
+			expectBackup:  true,
+			itemErrors:    2,
+			expectedPhase: v1.BackupPhasePartiallyFailed,
+		},
 	}
 
 	for _, test := range tests {
@@ -250,11 +337,13 @@ func TestProcessBackup(t *testing.T) {
 				sharedInformers.Ark().V1().Backups(),
 				client.ArkV1(),
 				backupper,
-				cloudBackups,
-				"bucket",
+				NewFakeDiscoveryHelper(true, nil),
+				newTestStorageLocations(cloudBackups, "bucket", sharedInformers, nil),
+				nil,
 				test.allowSnapshots,
 				logger,
 				pluginManager,
+				metrics.NewServerMetrics(),
 			).(*backupController)
 			c.clock = clock.NewFakeClock(time.Now())
 
@@ -280,9 +369,22 @@ func TestProcessBackup(t *testing.T) {
 				backup.Status.Phase = v1.BackupPhaseInProgress
 				backup.Status.Expiration.Time = expiration
 				backup.Status.Version = 1
-				backupper.On("Backup", backup, mock.Anything, mock.Anything, mock.Anything).Return(nil)
-
-				cloudBackups.On("UploadBackup", "bucket", backup.Name, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				backup.Status.StartTimestamp.Time = c.clock.Now()
+				backupErrs := v1.BackupResult{Namespaces: map[string][]string{"ns-1": make([]string, test.itemErrors)}}
+				backupper.On("Backup", backup, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Run(func(args mock.Arguments) {
+						args.Get(0).(*v1.Backup).Status.Errors = test.itemErrors
+					}).
+					Return(v1.BackupResult{}, backupErrs, &v1.BackupContentsIndex{}, nil)
+
+				// the streaming uploads are matched by type, since formatting the pipe for mock.Anything
+				// would race with the backup writing to it, and are drained like a real upload
+				drain := func(args mock.Arguments) { ioutil.ReadAll(args.Get(2).(io.Reader)) }
+				cloudBackups.On("UploadBackupContents", "bucket", backup.Name, mock.AnythingOfType("*io.PipeReader")).Run(drain).Return(nil)
+				cloudBackups.On("UploadBackupLog", "bucket", backup.Name, mock.AnythingOfType("*io.PipeReader")).Run(drain).Return(nil)
+				cloudBackups.On("UploadBackupResults", "bucket", backup.Name, mock.Anything).Return(nil)
+				cloudBackups.On("UploadBackupContentsIndex", "bucket", backup.Name, mock.Anything).Return(nil)
+				cloudBackups.On("UploadBackupMetadata", "bucket", backup.Name, mock.Anything).Return(nil)
 
 				pluginManager.On("GetBackupItemActions", backup.Name, logger, logger.Level).Return(nil, nil)
 				pluginManager.On("CloseBackupItemActions", backup.Name).Return(nil)
@@ -315,6 +417,25 @@ func TestProcessBackup(t *testing.T) {
 				return
 			}
 
+			expectedPhase := test.expectedPhase
+			if expectedPhase == "" {
+				expectedPhase = v1.BackupPhaseCompleted
+			}
+			completedBackup := NewTestBackup().
+				WithName(test.backup.Name).
+				WithPhase(expectedPhase).
+				WithIncludedResources(test.expectedIncludes...).
+				WithExcludedResources(test.expectedExcludes...).
+				WithIncludedNamespaces(test.backup.Spec.IncludedNamespaces...).
+				WithTTL(test.backup.Spec.TTL.Duration).
+				WithSnapshotVolumesPointer(test.backup.Spec.SnapshotVolumes).
+				WithExpiration(expiration).
+				WithVersion(1).
+				WithStartTimestamp(c.clock.Now()).
+				WithCompletionTimestamp(c.clock.Now()).
+				Backup
+			completedBackup.Status.Errors = test.itemErrors
+
 			expectedActions := []core.Action{
 				core.NewUpdateAction(
 					v1.SchemeGroupVersion.WithResource("backups"),
@@ -329,23 +450,14 @@ func TestProcessBackup(t *testing.T) {
 						WithSnapshotVolumesPointer(test.backup.Spec.SnapshotVolumes).
 						WithExpiration(expiration).
 						WithVersion(1).
+						WithStartTimestamp(c.clock.Now()).
 						Backup,
 				),
 
 				core.NewUpdateAction(
 					v1.SchemeGroupVersion.WithResource("backups"),
 					v1.DefaultNamespace,
-					NewTestBackup().
-						WithName(test.backup.Name).
-						WithPhase(v1.BackupPhaseCompleted).
-						WithIncludedResources(test.expectedIncludes...).
-						WithExcludedResources(test.expectedExcludes...).
-						WithIncludedNamespaces(test.backup.Spec.IncludedNamespaces...).
-						WithTTL(test.backup.Spec.TTL.Duration).
-						WithSnapshotVolumesPointer(test.backup.Spec.SnapshotVolumes).
-						WithExpiration(expiration).
-						WithVersion(1).
-						Backup,
+					completedBackup,
 				),
 			}
 
@@ -353,3 +465,107 @@ func TestProcessBackup(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessBackupWaitsForOverlappingBackup(t *testing.T) {
+	var (
+		client          = fake.NewSimpleClientset()
+		backupper       = &fakeBackupper{}
+		cloudBackups    = &BackupService{}
+		sharedInformers = informers.NewSharedInformerFactory(client, 0)
+		logger, _       = testlogger.NewNullLogger()
+		pluginManager   = &Manager{}
+		discoveryHelper = NewFakeDiscoveryHelper(true, nil)
+	)
+
+	c := NewBackupController(
+		sharedInformers.Ark().V1().Backups(),
+		client.ArkV1(),
+		backupper,
+		discoveryHelper,
+		newTestStorageLocations(cloudBackups, "bucket", sharedInformers, nil),
+		nil,
+		false,
+		logger,
+		pluginManager,
+		metrics.NewServerMetrics(),
+	).(*backupController)
+
+	// backup1 is already running and includes ns-1
+	running := NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).WithIncludedNamespaces("ns-1").Backup
+	assert.Empty(t, c.startBackup("heptio-ark/backup1", running))
+
+	backup := NewTestBackup().WithName("backup2").WithPhase(v1.BackupPhaseNew).WithIncludedNamespaces("ns-1", "ns-2").Backup
+	sharedInformers.Ark().V1().Backups().Informer().GetStore().Add(backup)
+
+	require.NoError(t, c.processBackup("heptio-ark/backup2"))
+
+	// backup2 overlaps backup1, so it's left as-is until backup1 finishes
+	assert.Empty(t, client.Actions())
+	assert.Empty(t, backupper.Calls)
+	assert.Equal(t, map[string]bool{"heptio-ark/backup2": true}, c.waiting)
+	assert.Equal(t, 0, c.queue.Len())
+
+	c.finishBackup("heptio-ark/backup1")
+
+	assert.Empty(t, c.running)
+	assert.Empty(t, c.waiting)
+	require.Equal(t, 1, c.queue.Len())
+	key, _ := c.queue.Get()
+	assert.Equal(t, "heptio-ark/backup2", key)
+
+	// a backup of a different namespace doesn't overlap, so it can start
+	assert.Empty(t, c.startBackup("heptio-ark/backup1", running))
+	other := NewTestBackup().WithName("backup3").WithPhase(v1.BackupPhaseNew).WithIncludedNamespaces("ns-2").Backup
+	other.Spec.IncludeClusterResources = new(bool)
+	assert.Empty(t, c.startBackup("heptio-ark/backup3", other))
+	assert.Len(t, c.running, 2)
+}
+
+func TestBackupProgressUpdater(t *testing.T) {
+	backup := NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).Backup
+	client := fake.NewSimpleClientset(backup)
+
+	logger, _ := testlogger.NewNullLogger()
+	updater := &backupProgressUpdater{
+		client: client.ArkV1(),
+		backup: backup,
+		logger: logger,
+	}
+
+	updater.ReportProgress(v1.BackupProgress{TotalItems: 10, ItemsBackedUp: 4}, 1, 2)
+
+	updated, err := client.ArkV1().Backups(backup.Namespace).Get(backup.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, &v1.BackupProgress{TotalItems: 10, ItemsBackedUp: 4}, updated.Status.Progress)
+	assert.Equal(t, 1, updated.Status.Warnings)
+	assert.Equal(t, 2, updated.Status.Errors)
+	assert.Equal(t, v1.BackupPhaseInProgress, updated.Status.Phase)
+	assert.Equal(t, updated, updater.backup)
+}
+
+func TestStreamingUpload(t *testing.T) {
+	var uploaded []byte
+	upload := newStreamingUpload(func(r io.Reader) error {
+		var err error
+		uploaded, err = ioutil.ReadAll(r)
+		return err
+	})
+
+	_, err := upload.Write([]byte("some "))
+	require.NoError(t, err)
+	_, err = upload.Write([]byte("data"))
+	require.NoError(t, err)
+
+	require.NoError(t, upload.finish(nil))
+	assert.Equal(t, "some data", string(uploaded))
+	assert.EqualValues(t, len("some data"), upload.bytes)
+
+	// finishing with an error aborts the upload
+	upload = newStreamingUpload(func(r io.Reader) error {
+		_, err := ioutil.ReadAll(r)
+		return err
+	})
+	_, err = upload.Write([]byte("partial"))
+	require.NoError(t, err)
+	assert.EqualError(t, upload.finish(errors.New("backup failed")), "backup failed")
+}
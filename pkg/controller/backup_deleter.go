@@ -0,0 +1,117 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/cloudprovider"
+	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
+	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+	"github.com/heptio/ark/pkg/util/kube"
+)
+
+// backupDeleter removes a backup and everything associated with it: volume snapshots,
+// files in object storage, restore API objects, and the backup API object itself. It's
+// shared by the GC controller and the backup deletion controller.
+type backupDeleter struct {
+	storageLocations cloudprovider.StorageLocationResolver
+	snapshotService  cloudprovider.SnapshotService
+	backupClient     arkv1client.BackupsGetter
+	restoreLister    listers.RestoreLister
+	restoreClient    arkv1client.RestoresGetter
+}
+
+// deleteBackup deletes any associated backup files (if deleteBackupFiles = true), volume snapshots,
+// restore API objects, and the backup API object itself. Errors are logged to log and returned.
+// If any of the backup's cloud resources (files in object storage, snapshots) can't be deleted,
+// the backup API object is left in place so they don't get orphaned.
+func (d *backupDeleter) deleteBackup(backup *api.Backup, deleteBackupFiles bool, log logrus.FieldLogger) []error {
+	// if the backup includes snapshots but we don't currently have a PVProvider, we don't
+	// want to orphan the snapshots so skip deletion entirely.
+	if d.snapshotService == nil && len(backup.Status.VolumeBackups) > 0 {
+		log.Warning("Cannot delete backup because backup includes snapshots and server is not configured with PersistentVolumeProvider")
+		return []error{errors.New("cannot delete backup because it includes snapshots and server is not configured with PersistentVolumeProvider")}
+	}
+
+	var errs []error
+
+	// We primarily need to delete the cloud resources (files in object storage, snapshots).
+	// If we fail to delete any of these, we don't delete the Backup API object or metadata
+	// file in object storage so that we don't orphan the cloud resources.
+	deletionFailure := false
+
+	for _, volumeBackup := range backup.Status.VolumeBackups {
+		log.WithField("snapshotID", volumeBackup.SnapshotID).Info("Removing snapshot associated with backup")
+		if err := d.snapshotService.DeleteSnapshot(volumeBackup.SnapshotID); err != nil {
+			log.WithError(err).WithField("snapshotID", volumeBackup.SnapshotID).Error("Error deleting snapshot")
+			errs = append(errs, errors.Wrapf(err, "error deleting snapshot %s", volumeBackup.SnapshotID))
+			deletionFailure = true
+		}
+	}
+
+	// If applicable, delete everything in the backup dir in object storage *before* deleting the API object
+	// because otherwise the backup sync controller could re-sync the backup from object storage.
+	if deleteBackupFiles {
+		log.Info("Removing backup from object storage")
+		if location, err := d.storageLocations.Get(backup.Spec.StorageLocation); err != nil {
+			log.WithError(err).Error("Error getting backup's storage location")
+			errs = append(errs, errors.WithMessage(err, "error getting backup's storage location"))
+			deletionFailure = true
+		} else if err := location.BackupService.DeleteBackupDir(location.Bucket, backup.Name); err != nil {
+			log.WithError(err).Error("Error deleting backup")
+			errs = append(errs, errors.Wrap(err, "error deleting backup from object storage"))
+			deletionFailure = true
+		}
+	}
+
+	log.Info("Getting restore API objects referencing backup")
+	if restores, err := d.restoreLister.Restores(backup.Namespace).List(labels.Everything()); err != nil {
+		log.WithError(errors.WithStack(err)).Error("Error getting Restore API objects")
+		errs = append(errs, errors.Wrap(err, "error getting Restore API objects"))
+	} else {
+		for _, restore := range restores {
+			if restore.Spec.BackupName == backup.Name {
+				log.WithField("restore", kube.NamespaceAndName(restore)).Info("Removing Restore API object referencing Backup")
+				if err := d.restoreClient.Restores(restore.Namespace).Delete(restore.Name, &metav1.DeleteOptions{}); err != nil {
+					log.WithError(errors.WithStack(err)).WithField("restore", kube.NamespaceAndName(restore)).
+						Error("Error deleting Restore API object")
+					errs = append(errs, errors.Wrapf(err, "error deleting Restore API object %s", kube.NamespaceAndName(restore)))
+				}
+			}
+		}
+	}
+
+	if deletionFailure {
+		log.Warning("Backup will not be deleted due to errors deleting related object storage files(s) and/or volume snapshots")
+		return errs
+	}
+
+	log.Info("Removing Backup API object")
+	if err := d.backupClient.Backups(backup.Namespace).Delete(backup.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		log.WithError(errors.WithStack(err)).Error("Error deleting Backup API object")
+		errs = append(errs, errors.Wrap(err, "error deleting Backup API object"))
+	}
+
+	return errs
+}
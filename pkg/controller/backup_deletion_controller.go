@@ -0,0 +1,325 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/cloudprovider"
+	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
+	informers "github.com/heptio/ark/pkg/generated/informers/externalversions/ark/v1"
+	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+	"github.com/heptio/ark/pkg/util/kube"
+)
+
+// deleteBackupRequestTTL is how long a processed DeleteBackupRequest is kept around
+// so its status can be inspected before it's removed.
+const deleteBackupRequestTTL = 24 * time.Hour
+
+type backupDeletionController struct {
+	deleteBackupRequestClient       arkv1client.DeleteBackupRequestsGetter
+	deleteBackupRequestLister       listers.DeleteBackupRequestLister
+	deleteBackupRequestListerSynced cache.InformerSynced
+	backupLister                    listers.BackupLister
+	backupListerSynced              cache.InformerSynced
+	restoreListerSynced             cache.InformerSynced
+	storageLocations                cloudprovider.StorageLocationResolver
+	deleter                         *backupDeleter
+	syncHandler                     func(key string) error
+	queue                           workqueue.RateLimitingInterface
+	clock                           clock.Clock
+	logger                          *logrus.Logger
+}
+
+// NewBackupDeletionController creates a new backup deletion controller.
+func NewBackupDeletionController(
+	deleteBackupRequestClient arkv1client.DeleteBackupRequestsGetter,
+	deleteBackupRequestInformer informers.DeleteBackupRequestInformer,
+	backupClient arkv1client.BackupsGetter,
+	backupInformer informers.BackupInformer,
+	restoreClient arkv1client.RestoresGetter,
+	restoreInformer informers.RestoreInformer,
+	storageLocations cloudprovider.StorageLocationResolver,
+	snapshotService cloudprovider.SnapshotService,
+	logger *logrus.Logger,
+) Interface {
+	c := &backupDeletionController{
+		deleteBackupRequestClient:       deleteBackupRequestClient,
+		deleteBackupRequestLister:       deleteBackupRequestInformer.Lister(),
+		deleteBackupRequestListerSynced: deleteBackupRequestInformer.Informer().HasSynced,
+		backupLister:                    backupInformer.Lister(),
+		backupListerSynced:              backupInformer.Informer().HasSynced,
+		restoreListerSynced:             restoreInformer.Informer().HasSynced,
+		storageLocations:                storageLocations,
+		deleter: &backupDeleter{
+			storageLocations: storageLocations,
+			snapshotService:  snapshotService,
+			backupClient:     backupClient,
+			restoreLister:    restoreInformer.Lister(),
+			restoreClient:    restoreClient,
+		},
+		queue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "backupdeletion"),
+		clock:  &clock.RealClock{},
+		logger: logger,
+	}
+
+	c.syncHandler = c.processDeleteBackupRequest
+
+	deleteBackupRequestInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				key, err := cache.MetaNamespaceKeyFunc(obj)
+				if err != nil {
+					req := obj.(*v1.DeleteBackupRequest)
+					c.logger.WithError(errors.WithStack(err)).
+						WithField("deleteBackupRequest", req.Name).
+						Error("Error creating queue key, item not added to queue")
+					return
+				}
+				c.queue.Add(key)
+			},
+		},
+	)
+
+	return c
+}
+
+// Run is a blocking function that runs the specified number of worker goroutines
+// to process items in the work queue. It will return when it receives on the
+// ctx.Done() channel.
+func (c *backupDeletionController) Run(ctx context.Context, numWorkers int) error {
+	var wg sync.WaitGroup
+
+	defer func() {
+		c.logger.Info("Waiting for workers to finish their work")
+
+		c.queue.ShutDown()
+
+		// We have to wait here in the deferred function instead of at the bottom of the function body
+		// because we have to shut down the queue in order for the workers to shut down gracefully, and
+		// we want to shut down the queue via defer and not at the end of the body.
+		wg.Wait()
+
+		c.logger.Info("All workers have finished")
+	}()
+
+	c.logger.Info("Starting BackupDeletionController")
+	defer c.logger.Info("Shutting down BackupDeletionController")
+
+	c.logger.Info("Waiting for caches to sync")
+	if !cache.WaitForCacheSync(ctx.Done(), c.deleteBackupRequestListerSynced, c.backupListerSynced, c.restoreListerSynced) {
+		return errors.New("timed out waiting for caches to sync")
+	}
+	c.logger.Info("Caches are synced")
+
+	wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			wait.Until(c.runWorker, time.Second, ctx.Done())
+			wg.Done()
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		wait.Until(c.resync, time.Hour, ctx.Done())
+		wg.Done()
+	}()
+
+	<-ctx.Done()
+
+	return nil
+}
+
+// runWorker runs a worker until the controller's queue indicates it's time to shut down.
+func (c *backupDeletionController) runWorker() {
+	// continually take items off the queue (waits if it's
+	// empty) until we get a shutdown signal from the queue
+	for c.processNextWorkItem() {
+	}
+}
+
+// processNextWorkItem processes a single item from the queue.
+func (c *backupDeletionController) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	// always call done on this item, since if it fails we'll add
+	// it back with rate-limiting below
+	defer c.queue.Done(key)
+
+	err := c.syncHandler(key.(string))
+	if err == nil {
+		// If you had no error, tell the queue to stop tracking history for your key. This will reset
+		// things like failure counts for per-item rate limiting.
+		c.queue.Forget(key)
+		return true
+	}
+
+	c.logger.WithError(err).WithField("key", key).Error("Error in syncHandler, re-adding item to queue")
+
+	// we had an error processing the item so add it back
+	// into the queue for re-processing with rate-limiting
+	c.queue.AddRateLimited(key)
+
+	return true
+}
+
+// processDeleteBackupRequest is the default per-item sync handler. It deletes the backup referenced
+// by a new DeleteBackupRequest, or removes the DeleteBackupRequest if it was processed long enough ago.
+// Requests left in progress (e.g. because the server restarted mid-deletion) are processed again;
+// deleting a backup is idempotent.
+func (c *backupDeletionController) processDeleteBackupRequest(key string) error {
+	logContext := c.logger.WithField("key", key)
+
+	logContext.Debug("Running processDeleteBackupRequest")
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return errors.Wrap(err, "error splitting queue key")
+	}
+
+	req, err := c.deleteBackupRequestLister.DeleteBackupRequests(ns).Get(name)
+	if apierrors.IsNotFound(err) {
+		logContext.Debug("Unable to find DeleteBackupRequest")
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "error getting DeleteBackupRequest")
+	}
+
+	switch req.Status.Phase {
+	case "", v1.DeleteBackupRequestPhaseNew, v1.DeleteBackupRequestPhaseInProgress:
+		return c.deleteBackup(req)
+	case v1.DeleteBackupRequestPhaseProcessed:
+		return c.deleteIfExpired(req)
+	}
+
+	return nil
+}
+
+// deleteBackup deletes the backup referenced by req and records the outcome in req's status.
+func (c *backupDeletionController) deleteBackup(req *v1.DeleteBackupRequest) error {
+	logContext := c.logger.WithFields(logrus.Fields{
+		"deleteBackupRequest": kube.NamespaceAndName(req),
+		"backup":              req.Spec.BackupName,
+	})
+
+	req, err := c.updateRequest(req, func(r *v1.DeleteBackupRequest) {
+		r.Status.Phase = v1.DeleteBackupRequestPhaseInProgress
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, err := range c.runDeletion(req, logContext) {
+		errs = append(errs, err.Error())
+	}
+
+	_, err = c.updateRequest(req, func(r *v1.DeleteBackupRequest) {
+		r.Status.Phase = v1.DeleteBackupRequestPhaseProcessed
+		r.Status.Errors = errs
+	})
+	return err
+}
+
+// runDeletion looks up the backup referenced by req, first in the API and then in object storage,
+// and deletes it along with its associated cloud resources and restores.
+func (c *backupDeletionController) runDeletion(req *v1.DeleteBackupRequest, logContext logrus.FieldLogger) []error {
+	if req.Spec.BackupName == "" {
+		return []error{errors.New("spec.backupName is required")}
+	}
+
+	backup, err := c.backupLister.Backups(req.Namespace).Get(req.Spec.BackupName)
+	if apierrors.IsNotFound(err) {
+		logContext.Debug("Backup not found in API, checking object storage")
+		backup, err = getBackupFromStorage(c.storageLocations, req.Spec.BackupName, logContext)
+		if err != nil {
+			logContext.WithError(err).Error("Error getting backup from object storage")
+			return []error{errors.Errorf("backup %s not found", req.Spec.BackupName)}
+		}
+		// backups read from object storage don't have a namespace set
+		backup.Namespace = req.Namespace
+	} else if err != nil {
+		return []error{errors.Wrap(err, "error getting backup")}
+	}
+
+	if backup.Status.Phase == v1.BackupPhaseInProgress {
+		return []error{errors.New("backup is still in progress")}
+	}
+
+	return c.deleter.deleteBackup(backup, true, logContext)
+}
+
+// updateRequest applies mutate to a copy of req and persists the result.
+func (c *backupDeletionController) updateRequest(req *v1.DeleteBackupRequest, mutate func(*v1.DeleteBackupRequest)) (*v1.DeleteBackupRequest, error) {
+	update := req.DeepCopy()
+	mutate(update)
+
+	res, err := c.deleteBackupRequestClient.DeleteBackupRequests(update.Namespace).Update(update)
+	if err != nil {
+		return nil, errors.Wrap(err, "error updating DeleteBackupRequest")
+	}
+
+	return res, nil
+}
+
+// deleteIfExpired deletes req if it was processed more than deleteBackupRequestTTL ago.
+func (c *backupDeletionController) deleteIfExpired(req *v1.DeleteBackupRequest) error {
+	logContext := c.logger.WithField("key", kube.NamespaceAndName(req))
+	if req.CreationTimestamp.Add(deleteBackupRequestTTL).After(c.clock.Now()) {
+		logContext.Debug("DeleteBackupRequest has not expired")
+		return nil
+	}
+
+	logContext.Debug("DeleteBackupRequest has expired - deleting")
+	return errors.WithStack(c.deleteBackupRequestClient.DeleteBackupRequests(req.Namespace).Delete(req.Name, nil))
+}
+
+// resync requeues all the DeleteBackupRequests in the lister's cache so that old processed
+// requests get cleaned up.
+func (c *backupDeletionController) resync() {
+	list, err := c.deleteBackupRequestLister.List(labels.Everything())
+	if err != nil {
+		c.logger.WithError(errors.WithStack(err)).Error("error listing delete backup requests")
+		return
+	}
+
+	for _, req := range list {
+		key, err := cache.MetaNamespaceKeyFunc(req)
+		if err != nil {
+			c.logger.WithError(errors.WithStack(err)).WithField("deleteBackupRequest", req.Name).Error("error generating key for delete backup request")
+			continue
+		}
+
+		c.queue.Add(key)
+	}
+}
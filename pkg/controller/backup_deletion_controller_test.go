@@ -0,0 +1,241 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	testlogger "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	core "k8s.io/client-go/testing"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
+	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
+	"github.com/heptio/ark/pkg/util/test"
+)
+
+func TestProcessDeleteBackupRequest(t *testing.T) {
+	tests := []struct {
+		name                   string
+		requestPhase           v1.DeleteBackupRequestPhase
+		apiBackup              *v1.Backup
+		objectStorageBackup    *v1.Backup
+		restores               []*v1.Restore
+		snapshots              sets.String
+		expectBackupDirDeleted bool
+		expectedErrors         []string
+		expectedSnapshots      sets.String
+		expectedDeletes        []string
+	}{
+		{
+			name:      "backup in API is deleted along with its snapshots, files and restores",
+			apiBackup: test.NewTestBackup().WithName("backup-1").WithPhase(v1.BackupPhaseCompleted).WithSnapshot("pv-1", "snapshot-1").Backup,
+			restores: []*v1.Restore{
+				test.NewTestRestore(v1.DefaultNamespace, "restore-1", v1.RestorePhaseCompleted).WithBackup("backup-1").Restore,
+				test.NewTestRestore(v1.DefaultNamespace, "restore-2", v1.RestorePhaseCompleted).WithBackup("backup-2").Restore,
+			},
+			snapshots:              sets.NewString("snapshot-1", "snapshot-2"),
+			expectBackupDirDeleted: true,
+			expectedSnapshots:      sets.NewString("snapshot-2"),
+			expectedDeletes:        []string{"restores/restore-1", "backups/backup-1"},
+		},
+		{
+			name:                   "request left in progress is processed again",
+			requestPhase:           v1.DeleteBackupRequestPhaseInProgress,
+			apiBackup:              test.NewTestBackup().WithName("backup-1").WithPhase(v1.BackupPhaseCompleted).Backup,
+			expectBackupDirDeleted: true,
+			expectedDeletes:        []string{"backups/backup-1"},
+		},
+		{
+			name:                   "backup only in object storage is deleted",
+			objectStorageBackup:    test.NewTestBackup().WithName("backup-1").WithPhase(v1.BackupPhaseCompleted).Backup,
+			expectBackupDirDeleted: true,
+			expectedDeletes:        []string{"backups/backup-1"},
+		},
+		{
+			name:           "backup that doesn't exist results in an error",
+			expectedErrors: []string{"backup backup-1 not found"},
+		},
+		{
+			name:           "in-progress backup is not deleted",
+			apiBackup:      test.NewTestBackup().WithName("backup-1").WithPhase(v1.BackupPhaseInProgress).Backup,
+			expectedErrors: []string{"backup is still in progress"},
+		},
+		{
+			name:              "failure to delete a snapshot keeps the backup API object",
+			apiBackup:         test.NewTestBackup().WithName("backup-1").WithPhase(v1.BackupPhaseCompleted).WithSnapshot("pv-1", "snapshot-1").Backup,
+			snapshots:         sets.NewString(),
+			expectedErrors:    []string{"error deleting snapshot snapshot-1: snapshot not found"},
+			expectedSnapshots: sets.NewString(),
+			// object storage is still cleaned up
+			expectBackupDirDeleted: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				client          = fake.NewSimpleClientset()
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+				backupService   = &test.BackupService{}
+				snapshotService = &test.FakeSnapshotService{SnapshotsTaken: tc.snapshots}
+				logger, _       = testlogger.NewNullLogger()
+			)
+			defer backupService.AssertExpectations(t)
+
+			c := NewBackupDeletionController(
+				client.ArkV1(),
+				sharedInformers.Ark().V1().DeleteBackupRequests(),
+				client.ArkV1(),
+				sharedInformers.Ark().V1().Backups(),
+				client.ArkV1(),
+				sharedInformers.Ark().V1().Restores(),
+				newTestStorageLocations(backupService, "bucket", sharedInformers, nil),
+				snapshotService,
+				logger,
+			).(*backupDeletionController)
+
+			req := &v1.DeleteBackupRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: v1.DefaultNamespace,
+					Name:      "backup-1-delete",
+				},
+				Spec: v1.DeleteBackupRequestSpec{
+					BackupName: "backup-1",
+				},
+				Status: v1.DeleteBackupRequestStatus{
+					Phase: tc.requestPhase,
+				},
+			}
+			require.NoError(t, sharedInformers.Ark().V1().DeleteBackupRequests().Informer().GetStore().Add(req))
+
+			if tc.apiBackup != nil {
+				require.NoError(t, sharedInformers.Ark().V1().Backups().Informer().GetStore().Add(tc.apiBackup))
+			} else if tc.objectStorageBackup != nil {
+				backupService.On("GetBackup", "bucket", "backup-1").Return(tc.objectStorageBackup, nil)
+			} else {
+				backupService.On("GetBackup", "bucket", "backup-1").Return(nil, errors.New("not found"))
+			}
+
+			for _, restore := range tc.restores {
+				require.NoError(t, sharedInformers.Ark().V1().Restores().Informer().GetStore().Add(restore))
+			}
+
+			if tc.expectBackupDirDeleted {
+				backupService.On("DeleteBackupDir", "bucket", "backup-1").Return(nil)
+			}
+
+			var phases []v1.DeleteBackupRequestPhase
+			var updated *v1.DeleteBackupRequest
+			client.PrependReactor("update", "deletebackuprequests", func(action core.Action) (bool, runtime.Object, error) {
+				updated = action.(core.UpdateAction).GetObject().(*v1.DeleteBackupRequest)
+				phases = append(phases, updated.Status.Phase)
+				return true, updated, nil
+			})
+
+			var deletes []string
+			client.PrependReactor("delete", "*", func(action core.Action) (bool, runtime.Object, error) {
+				deleteAction := action.(core.DeleteAction)
+				deletes = append(deletes, deleteAction.GetResource().Resource+"/"+deleteAction.GetName())
+				return true, nil, nil
+			})
+
+			// method under test
+			require.NoError(t, c.processDeleteBackupRequest("heptio-ark/backup-1-delete"))
+
+			assert.Equal(t, []v1.DeleteBackupRequestPhase{v1.DeleteBackupRequestPhaseInProgress, v1.DeleteBackupRequestPhaseProcessed}, phases)
+			require.NotNil(t, updated)
+			assert.Equal(t, tc.expectedErrors, updated.Status.Errors)
+			assert.Equal(t, tc.expectedDeletes, deletes)
+			if tc.expectedSnapshots != nil {
+				assert.Equal(t, tc.expectedSnapshots, snapshotService.SnapshotsTaken)
+			}
+		})
+	}
+}
+
+func TestProcessDeleteBackupRequestExpiration(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		created       time.Time
+		expectDeleted bool
+	}{
+		{
+			name:    "recently processed request is kept",
+			created: now.Add(-time.Hour),
+		},
+		{
+			name:          "request processed more than a day ago is deleted",
+			created:       now.Add(-25 * time.Hour),
+			expectDeleted: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				client          = fake.NewSimpleClientset()
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+				logger, _       = testlogger.NewNullLogger()
+			)
+
+			c := NewBackupDeletionController(
+				client.ArkV1(),
+				sharedInformers.Ark().V1().DeleteBackupRequests(),
+				client.ArkV1(),
+				sharedInformers.Ark().V1().Backups(),
+				client.ArkV1(),
+				sharedInformers.Ark().V1().Restores(),
+				newTestStorageLocations(&test.BackupService{}, "bucket", sharedInformers, nil),
+				nil,
+				logger,
+			).(*backupDeletionController)
+			c.clock = clock.NewFakeClock(now)
+
+			req := &v1.DeleteBackupRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         v1.DefaultNamespace,
+					Name:              "backup-1-delete",
+					CreationTimestamp: metav1.NewTime(tc.created),
+				},
+				Spec:   v1.DeleteBackupRequestSpec{BackupName: "backup-1"},
+				Status: v1.DeleteBackupRequestStatus{Phase: v1.DeleteBackupRequestPhaseProcessed},
+			}
+			require.NoError(t, sharedInformers.Ark().V1().DeleteBackupRequests().Informer().GetStore().Add(req))
+
+			var deleted bool
+			client.PrependReactor("delete", "deletebackuprequests", func(action core.Action) (bool, runtime.Object, error) {
+				deleted = true
+				return true, nil, nil
+			})
+
+			require.NoError(t, c.processDeleteBackupRequest("heptio-ark/backup-1-delete"))
+			assert.Equal(t, tc.expectDeleted, deleted)
+		})
+	}
+}
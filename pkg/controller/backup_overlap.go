@@ -0,0 +1,110 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+// backupScope is the set of namespaces and resources that a backup can include.
+// Label selectors aren't taken into account, so two backups whose scopes overlap
+// won't necessarily include the same items.
+type backupScope struct {
+	namespaces *collections.IncludesExcludes
+	resources  *collections.IncludesExcludes
+	// clusterScoped is true if the backup can include cluster-scoped resources.
+	clusterScoped bool
+}
+
+// newBackupScope returns the scope of the provided backup spec, using the discovery
+// helper to resolve its resources to fully-qualified group-resource names the same
+// way the backupper does.
+func newBackupScope(spec api.BackupSpec, helper discovery.Helper) backupScope {
+	namespaces := collections.NewIncludesExcludes().Includes(spec.IncludedNamespaces...).Excludes(spec.ExcludedNamespaces...)
+
+	resources := collections.GenerateIncludesExcludes(
+		spec.IncludedResources,
+		spec.ExcludedResources,
+		func(item string) string {
+			gvr, _, err := helper.ResourceFor(schema.ParseGroupResource(item).WithVersion(""))
+			if err != nil {
+				return ""
+			}
+
+			gr := gvr.GroupResource()
+			return gr.String()
+		},
+	)
+
+	// this mirrors how the backupper decides whether to back up cluster-scoped
+	// resources: if it's not specified, they're only included when all namespaces are.
+	clusterScoped := spec.IncludeClusterResources == nil && namespaces.IncludeEverything()
+	if spec.IncludeClusterResources != nil {
+		clusterScoped = *spec.IncludeClusterResources
+	}
+
+	return backupScope{
+		namespaces:    namespaces,
+		resources:     resources,
+		clusterScoped: clusterScoped,
+	}
+}
+
+// overlaps returns true if the two scopes could both include the same item.
+func (s backupScope) overlaps(other backupScope) bool {
+	if !includesExcludesOverlap(s.resources, other.resources) {
+		return false
+	}
+
+	if s.clusterScoped && other.clusterScoped {
+		return true
+	}
+
+	return includesExcludesOverlap(s.namespaces, other.namespaces)
+}
+
+// includesAll returns true if the includes list is empty or '*', i.e. if everything
+// that isn't excluded is included.
+func includesAll(ie *collections.IncludesExcludes) bool {
+	includes := ie.GetIncludes()
+	return len(includes) == 0 || (len(includes) == 1 && includes[0] == "*")
+}
+
+// includesExcludesOverlap returns true if there could be an item that both a and b
+// include.
+func includesExcludesOverlap(a, b *collections.IncludesExcludes) bool {
+	switch {
+	case includesAll(a) && includesAll(b):
+		// excludes lists are finite, so there's always something that neither excludes
+		return true
+	case includesAll(a):
+		a, b = b, a
+	}
+
+	// a lists the items it includes, so one of them has to be included by b as well
+	for _, item := range a.GetIncludes() {
+		if a.ShouldInclude(item) && b.ShouldInclude(item) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestBackupScopeOverlaps(t *testing.T) {
+	boolptr := func(b bool) *bool { return &b }
+
+	tests := []struct {
+		name     string
+		a        api.BackupSpec
+		b        api.BackupSpec
+		expected bool
+	}{
+		{
+			name:     "full backups overlap",
+			expected: true,
+		},
+		{
+			name:     "different namespaces don't overlap",
+			a:        api.BackupSpec{IncludedNamespaces: []string{"ns-1"}},
+			b:        api.BackupSpec{IncludedNamespaces: []string{"ns-2"}},
+			expected: false,
+		},
+		{
+			name:     "common namespace overlaps",
+			a:        api.BackupSpec{IncludedNamespaces: []string{"ns-1", "ns-2"}},
+			b:        api.BackupSpec{IncludedNamespaces: []string{"ns-2", "ns-3"}},
+			expected: true,
+		},
+		{
+			name:     "namespace excluded by the other doesn't overlap",
+			a:        api.BackupSpec{IncludedNamespaces: []string{"ns-1"}, IncludeClusterResources: boolptr(false)},
+			b:        api.BackupSpec{ExcludedNamespaces: []string{"ns-1"}},
+			expected: false,
+		},
+		{
+			name:     "namespace not excluded by the other overlaps",
+			a:        api.BackupSpec{IncludedNamespaces: []string{"ns-1"}},
+			b:        api.BackupSpec{ExcludedNamespaces: []string{"ns-2"}},
+			expected: true,
+		},
+		{
+			name:     "different resources don't overlap",
+			a:        api.BackupSpec{IncludedResources: []string{"pods"}},
+			b:        api.BackupSpec{IncludedResources: []string{"deployments"}},
+			expected: false,
+		},
+		{
+			name:     "resources are compared after being resolved",
+			a:        api.BackupSpec{IncludedResources: []string{"pods"}},
+			b:        api.BackupSpec{IncludedResources: []string{"po"}},
+			expected: true,
+		},
+		{
+			name:     "resource excluded by the other doesn't overlap",
+			a:        api.BackupSpec{IncludedResources: []string{"pods"}},
+			b:        api.BackupSpec{ExcludedResources: []string{"po"}},
+			expected: false,
+		},
+		{
+			name:     "cluster-scoped backups of different namespaces overlap",
+			a:        api.BackupSpec{IncludedNamespaces: []string{"ns-1"}, IncludeClusterResources: boolptr(true)},
+			b:        api.BackupSpec{IncludedNamespaces: []string{"ns-2"}, IncludeClusterResources: boolptr(true)},
+			expected: true,
+		},
+		{
+			name:     "cluster-scoped backups overlap even if one excludes the other's namespace",
+			a:        api.BackupSpec{ExcludedNamespaces: []string{"ns-1"}, IncludeClusterResources: boolptr(true)},
+			b:        api.BackupSpec{IncludedNamespaces: []string{"ns-1"}, IncludeClusterResources: boolptr(true)},
+			expected: true,
+		},
+		{
+			name:     "cluster resources are only included by default when all namespaces are",
+			a:        api.BackupSpec{ExcludedNamespaces: []string{"ns-1"}},
+			b:        api.BackupSpec{IncludedNamespaces: []string{"ns-1"}, IncludeClusterResources: boolptr(true)},
+			expected: false,
+		},
+	}
+
+	resources := map[schema.GroupVersionResource]schema.GroupVersionResource{
+		{Resource: "pods"}:        {Version: "v1", Resource: "pods"},
+		{Resource: "po"}:          {Version: "v1", Resource: "pods"},
+		{Resource: "deployments"}: {Group: "apps", Version: "v1", Resource: "deployments"},
+	}
+	discoveryHelper := arktest.NewFakeDiscoveryHelper(false, resources)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := newBackupScope(test.a, discoveryHelper)
+			b := newBackupScope(test.b, discoveryHelper)
+
+			assert.Equal(t, test.expected, a.overlaps(b))
+			assert.Equal(t, test.expected, b.overlaps(a))
+		})
+	}
+}
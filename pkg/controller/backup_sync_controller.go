@@ -32,17 +32,15 @@ import (
 )
 
 type backupSyncController struct {
-	client        arkv1client.BackupsGetter
-	backupService cloudprovider.BackupService
-	bucket        string
-	syncPeriod    time.Duration
-	logger        *logrus.Logger
+	client           arkv1client.BackupsGetter
+	storageLocations cloudprovider.StorageLocationResolver
+	syncPeriod       time.Duration
+	logger           *logrus.Logger
 }
 
 func NewBackupSyncController(
 	client arkv1client.BackupsGetter,
-	backupService cloudprovider.BackupService,
-	bucket string,
+	storageLocations cloudprovider.StorageLocationResolver,
 	syncPeriod time.Duration,
 	logger *logrus.Logger,
 ) Interface {
@@ -51,11 +49,10 @@ func NewBackupSyncController(
 		syncPeriod = time.Minute
 	}
 	return &backupSyncController{
-		client:        client,
-		backupService: backupService,
-		bucket:        bucket,
-		syncPeriod:    syncPeriod,
-		logger:        logger,
+		client:           client,
+		storageLocations: storageLocations,
+		syncPeriod:       syncPeriod,
+		logger:           logger,
 	}
 }
 
@@ -69,19 +66,37 @@ func (c *backupSyncController) Run(ctx context.Context, workers int) error {
 }
 
 func (c *backupSyncController) run() {
-	c.logger.Info("Syncing backups from object storage")
-	backups, err := c.backupService.GetAllBackups(c.bucket)
+	locations, err := c.storageLocations.List()
 	if err != nil {
-		c.logger.WithError(err).Error("error listing backups")
+		// keep going with the locations that could be resolved
+		c.logger.WithError(err).Error("error resolving backup storage locations")
+	}
+
+	for _, location := range locations {
+		c.syncLocation(location)
+	}
+}
+
+// syncLocation creates Backup API objects for the backups in location that don't have them.
+func (c *backupSyncController) syncLocation(location *cloudprovider.StorageLocation) {
+	locationLog := c.logger.WithField("storageLocation", location.Name)
+
+	locationLog.Info("Syncing backups from object storage")
+	backups, err := location.BackupService.GetAllBackups(location.Bucket)
+	if err != nil {
+		locationLog.WithError(err).Error("error listing backups")
 		return
 	}
-	c.logger.WithField("backupCount", len(backups)).Info("Got backups from object storage")
+	locationLog.WithField("backupCount", len(backups)).Info("Got backups from object storage")
 
 	for _, cloudBackup := range backups {
-		logContext := c.logger.WithField("backup", kube.NamespaceAndName(cloudBackup))
+		logContext := locationLog.WithField("backup", kube.NamespaceAndName(cloudBackup))
 		logContext.Info("Syncing backup")
 
+		// the backups may be shared with a cache, so don't modify them
+		cloudBackup = cloudBackup.DeepCopy()
 		cloudBackup.ResourceVersion = ""
+		cloudBackup.Spec.StorageLocation = location.Name
 		if _, err := c.client.Backups(cloudBackup.Namespace).Create(cloudBackup); err != nil && !kuberrs.IsAlreadyExists(err) {
 			logContext.WithError(errors.WithStack(err)).Error("Error syncing backup from object storage")
 		}
@@ -27,7 +27,9 @@ import (
 	core "k8s.io/client-go/testing"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
+	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
 	. "github.com/heptio/ark/pkg/util/test"
 )
 
@@ -36,6 +38,7 @@ func TestBackupSyncControllerRun(t *testing.T) {
 		name               string
 		getAllBackupsError error
 		cloudBackups       []*v1.Backup
+		otherCloudBackups  []*v1.Backup
 	}{
 		{
 			name: "no cloud backups",
@@ -52,43 +55,65 @@ func TestBackupSyncControllerRun(t *testing.T) {
 				NewTestBackup().WithNamespace("ns-2").WithName("backup-3").Backup,
 			},
 		},
+		{
+			name: "backups in multiple storage locations",
+			cloudBackups: []*v1.Backup{
+				NewTestBackup().WithNamespace("ns-1").WithName("backup-1").Backup,
+			},
+			otherCloudBackups: []*v1.Backup{
+				NewTestBackup().WithNamespace("ns-1").WithName("backup-2").Backup,
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			var (
-				bs        = &BackupService{}
-				client    = fake.NewSimpleClientset()
-				logger, _ = testlogger.NewNullLogger()
+				bs              = &BackupService{}
+				otherBS         = &BackupService{}
+				client          = fake.NewSimpleClientset()
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+				logger, _       = testlogger.NewNullLogger()
 			)
 
+			sharedInformers.Ark().V1().BackupStorageLocations().Informer().GetStore().Add(newTestStorageLocation("other", "other-bucket"))
+
 			c := NewBackupSyncController(
 				client.ArkV1(),
-				bs,
-				"bucket",
+				newTestStorageLocations(bs, "bucket", sharedInformers, map[string]cloudprovider.BackupService{"other": otherBS}),
 				time.Duration(0),
 				logger,
 			).(*backupSyncController)
 
 			bs.On("GetAllBackups", "bucket").Return(test.cloudBackups, test.getAllBackupsError)
+			otherBS.On("GetAllBackups", "other-bucket").Return(test.otherCloudBackups, nil)
 
 			c.run()
 
 			expectedActions := make([]core.Action, 0)
 
-			// we only expect creates for items within the target bucket
-			for _, cloudBackup := range test.cloudBackups {
-				action := core.NewCreateAction(
-					v1.SchemeGroupVersion.WithResource("backups"),
-					cloudBackup.Namespace,
-					cloudBackup,
-				)
-
-				expectedActions = append(expectedActions, action)
+			// we only expect creates for items within the target buckets, labeled
+			// with the location they were found in
+			addExpectedCreates := func(backups []*v1.Backup, location string) {
+				for _, cloudBackup := range backups {
+					expected := cloudBackup.DeepCopy()
+					expected.Spec.StorageLocation = location
+
+					action := core.NewCreateAction(
+						v1.SchemeGroupVersion.WithResource("backups"),
+						cloudBackup.Namespace,
+						expected,
+					)
+
+					expectedActions = append(expectedActions, action)
+				}
 			}
+			addExpectedCreates(test.cloudBackups, v1.DefaultBackupStorageLocation)
+			addExpectedCreates(test.otherCloudBackups, "other")
 
 			assert.Equal(t, expectedActions, client.Actions())
 			bs.AssertExpectations(t)
+			otherBS.AssertExpectations(t)
 		})
 	}
 }
@@ -44,8 +44,11 @@ type downloadRequestController struct {
 	downloadRequestClient       arkv1client.DownloadRequestsGetter
 	downloadRequestLister       listers.DownloadRequestLister
 	downloadRequestListerSynced cache.InformerSynced
-	backupService               cloudprovider.BackupService
-	bucket                      string
+	backupLister                listers.BackupLister
+	backupListerSynced          cache.InformerSynced
+	restoreLister               listers.RestoreLister
+	restoreListerSynced         cache.InformerSynced
+	storageLocations            cloudprovider.StorageLocationResolver
 	syncHandler                 func(key string) error
 	queue                       workqueue.RateLimitingInterface
 	clock                       clock.Clock
@@ -56,16 +59,20 @@ type downloadRequestController struct {
 func NewDownloadRequestController(
 	downloadRequestClient arkv1client.DownloadRequestsGetter,
 	downloadRequestInformer informers.DownloadRequestInformer,
-	backupService cloudprovider.BackupService,
-	bucket string,
+	backupInformer informers.BackupInformer,
+	restoreInformer informers.RestoreInformer,
+	storageLocations cloudprovider.StorageLocationResolver,
 	logger *logrus.Logger,
 ) Interface {
 	c := &downloadRequestController{
 		downloadRequestClient:       downloadRequestClient,
 		downloadRequestLister:       downloadRequestInformer.Lister(),
 		downloadRequestListerSynced: downloadRequestInformer.Informer().HasSynced,
-		backupService:               backupService,
-		bucket:                      bucket,
+		backupLister:                backupInformer.Lister(),
+		backupListerSynced:          backupInformer.Informer().HasSynced,
+		restoreLister:               restoreInformer.Lister(),
+		restoreListerSynced:         restoreInformer.Informer().HasSynced,
+		storageLocations:            storageLocations,
 		queue:                       workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "downloadrequest"),
 		clock:                       &clock.RealClock{},
 		logger:                      logger,
@@ -115,7 +122,7 @@ func (c *downloadRequestController) Run(ctx context.Context, numWorkers int) err
 	defer c.logger.Info("Shutting down DownloadRequestController")
 
 	c.logger.Info("Waiting for caches to sync")
-	if !cache.WaitForCacheSync(ctx.Done(), c.downloadRequestListerSynced) {
+	if !cache.WaitForCacheSync(ctx.Done(), c.downloadRequestListerSynced, c.backupListerSynced, c.restoreListerSynced) {
 		return errors.New("timed out waiting for caches to sync")
 	}
 	c.logger.Info("Caches are synced")
@@ -211,8 +218,12 @@ const signedURLTTL = 10 * time.Minute
 func (c *downloadRequestController) generatePreSignedURL(downloadRequest *v1.DownloadRequest) error {
 	update := downloadRequest.DeepCopy()
 
-	var err error
-	update.Status.DownloadURL, err = c.backupService.CreateSignedURL(downloadRequest.Spec.Target, c.bucket, signedURLTTL)
+	location, err := c.storageLocations.Get(c.getStorageLocationName(downloadRequest))
+	if err != nil {
+		return err
+	}
+
+	update.Status.DownloadURL, err = location.BackupService.CreateSignedURL(downloadRequest.Spec.Target, location.Bucket, signedURLTTL)
 	if err != nil {
 		return err
 	}
@@ -224,6 +235,32 @@ func (c *downloadRequestController) generatePreSignedURL(downloadRequest *v1.Dow
 	return errors.WithStack(err)
 }
 
+// getStorageLocationName returns the name of the storage location of the backup that
+// downloadRequest's target belongs to. If the backup or restore can't be found in the API,
+// the default location is assumed.
+func (c *downloadRequestController) getStorageLocationName(downloadRequest *v1.DownloadRequest) string {
+	logContext := c.logger.WithField("key", kube.NamespaceAndName(downloadRequest))
+
+	backupName := downloadRequest.Spec.Target.Name
+	switch downloadRequest.Spec.Target.Kind {
+	case v1.DownloadTargetKindRestoreLog, v1.DownloadTargetKindRestoreResults:
+		restore, err := c.restoreLister.Restores(downloadRequest.Namespace).Get(downloadRequest.Spec.Target.Name)
+		if err != nil {
+			logContext.WithError(errors.WithStack(err)).Debug("Unable to get restore, using default storage location")
+			return ""
+		}
+		backupName = restore.Spec.BackupName
+	}
+
+	backup, err := c.backupLister.Backups(downloadRequest.Namespace).Get(backupName)
+	if err != nil {
+		logContext.WithError(errors.WithStack(err)).Debug("Unable to get backup, using default storage location")
+		return ""
+	}
+
+	return backup.Spec.StorageLocation
+}
+
 // deleteIfExpired deletes downloadRequest if it has expired.
 func (c *downloadRequestController) deleteIfExpired(downloadRequest *v1.DownloadRequest) error {
 	logContext := c.logger.WithField("key", kube.NamespaceAndName(downloadRequest))
@@ -29,6 +29,7 @@ import (
 	core "k8s.io/client-go/testing"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
 	"github.com/heptio/ark/pkg/util/test"
@@ -41,6 +42,8 @@ func TestProcessDownloadRequest(t *testing.T) {
 		phase         v1.DownloadRequestPhase
 		targetKind    v1.DownloadTargetKind
 		targetName    string
+		restore       *v1.Restore
+		backup        *v1.Backup
 		expectedError string
 		expectedPhase v1.DownloadRequestPhase
 		expectedURL   string
@@ -90,6 +93,25 @@ func TestProcessDownloadRequest(t *testing.T) {
 			expectedPhase: v1.DownloadRequestPhaseProcessed,
 			expectedURL:   "signedURL",
 		},
+		{
+			name:          "backup contents request for backup in another storage location gets a url from that location",
+			key:           "heptio-ark/dr1",
+			targetKind:    v1.DownloadTargetKindBackupContents,
+			targetName:    "backup1",
+			backup:        test.NewTestBackup().WithName("backup1").WithStorageLocation("other").Backup,
+			expectedPhase: v1.DownloadRequestPhaseProcessed,
+			expectedURL:   "signedURL",
+		},
+		{
+			name:          "restore results request for backup in another storage location gets a url from that location",
+			key:           "heptio-ark/dr1",
+			targetKind:    v1.DownloadTargetKindRestoreResults,
+			targetName:    "restore1",
+			restore:       test.NewTestRestore(v1.DefaultNamespace, "restore1", v1.RestorePhaseCompleted).WithBackup("backup1").Restore,
+			backup:        test.NewTestBackup().WithName("backup1").WithStorageLocation("other").Backup,
+			expectedPhase: v1.DownloadRequestPhaseProcessed,
+			expectedURL:   "signedURL",
+		},
 	}
 
 	for _, tc := range tests {
@@ -99,15 +121,26 @@ func TestProcessDownloadRequest(t *testing.T) {
 				sharedInformers          = informers.NewSharedInformerFactory(client, 0)
 				downloadRequestsInformer = sharedInformers.Ark().V1().DownloadRequests()
 				backupService            = &test.BackupService{}
+				otherBackupService       = &test.BackupService{}
 				logger, _                = testlogger.NewNullLogger()
 			)
 			defer backupService.AssertExpectations(t)
+			defer otherBackupService.AssertExpectations(t)
+
+			sharedInformers.Ark().V1().BackupStorageLocations().Informer().GetStore().Add(newTestStorageLocation("other", "other-bucket"))
+			if tc.backup != nil {
+				sharedInformers.Ark().V1().Backups().Informer().GetStore().Add(tc.backup)
+			}
+			if tc.restore != nil {
+				sharedInformers.Ark().V1().Restores().Informer().GetStore().Add(tc.restore)
+			}
 
 			c := NewDownloadRequestController(
 				client.ArkV1(),
 				downloadRequestsInformer,
-				backupService,
-				"bucket",
+				sharedInformers.Ark().V1().Backups(),
+				sharedInformers.Ark().V1().Restores(),
+				newTestStorageLocations(backupService, "bucket", sharedInformers, map[string]cloudprovider.BackupService{"other": otherBackupService}),
 				logger,
 			).(*downloadRequestController)
 
@@ -129,7 +162,11 @@ func TestProcessDownloadRequest(t *testing.T) {
 					},
 				)
 
-				backupService.On("CreateSignedURL", target, "bucket", 10*time.Minute).Return("signedURL", nil)
+				if tc.backup != nil && tc.backup.Spec.StorageLocation == "other" {
+					otherBackupService.On("CreateSignedURL", target, "other-bucket", 10*time.Minute).Return("signedURL", nil)
+				} else {
+					backupService.On("CreateSignedURL", target, "bucket", 10*time.Minute).Return("signedURL", nil)
+				}
 			}
 
 			var updatedRequest *v1.DownloadRequest
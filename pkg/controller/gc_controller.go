@@ -18,12 +18,12 @@ package controller
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -31,39 +31,59 @@ import (
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/encryption"
 	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions/ark/v1"
 	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+	"github.com/heptio/ark/pkg/metrics"
+	"github.com/heptio/ark/pkg/podvolume"
+	arkschedule "github.com/heptio/ark/pkg/schedule"
 	"github.com/heptio/ark/pkg/util/kube"
 )
 
-// gcController removes expired backup content from object storage.
+// chunkPruner deletes the pod volume data chunks in a storage location that no backup references.
+type chunkPruner interface {
+	Prune() (int, error)
+}
+
+// newChunkPrunerFunc returns the chunkPruner for a storage location.
+type newChunkPrunerFunc func(location *cloudprovider.StorageLocation, keyProvider encryption.KeyProvider, log logrus.FieldLogger) chunkPruner
+
+func newChunkPruner(location *cloudprovider.StorageLocation, keyProvider encryption.KeyProvider, log logrus.FieldLogger) chunkPruner {
+	return podvolume.NewRepository(location.BackupService, location.Bucket, keyProvider, nil, log)
+}
+
+// gcController removes expired backup content from object storage, along with the backups
+// pruned by schedules' retention policies and the pod volume data no backup references.
 type gcController struct {
-	backupService       cloudprovider.BackupService
-	snapshotService     cloudprovider.SnapshotService
-	bucket              string
-	syncPeriod          time.Duration
-	clock               clock.Clock
-	backupLister        listers.BackupLister
-	backupListerSynced  cache.InformerSynced
-	backupClient        arkv1client.BackupsGetter
-	restoreLister       listers.RestoreLister
-	restoreListerSynced cache.InformerSynced
-	restoreClient       arkv1client.RestoresGetter
-	logger              *logrus.Logger
+	storageLocations     cloudprovider.StorageLocationResolver
+	syncPeriod           time.Duration
+	clock                clock.Clock
+	backupLister         listers.BackupLister
+	backupListerSynced   cache.InformerSynced
+	restoreListerSynced  cache.InformerSynced
+	scheduleLister       listers.ScheduleLister
+	scheduleListerSynced cache.InformerSynced
+	deleter              *backupDeleter
+	keyProvider          encryption.KeyProvider
+	newChunkPruner       newChunkPrunerFunc
+	logger               *logrus.Logger
+	metrics              *metrics.ServerMetrics
 }
 
 // NewGCController constructs a new gcController.
 func NewGCController(
-	backupService cloudprovider.BackupService,
+	storageLocations cloudprovider.StorageLocationResolver,
 	snapshotService cloudprovider.SnapshotService,
-	bucket string,
 	syncPeriod time.Duration,
 	backupInformer informers.BackupInformer,
 	backupClient arkv1client.BackupsGetter,
 	restoreInformer informers.RestoreInformer,
 	restoreClient arkv1client.RestoresGetter,
+	scheduleInformer informers.ScheduleInformer,
+	keyProvider encryption.KeyProvider,
 	logger *logrus.Logger,
+	metrics *metrics.ServerMetrics,
 ) Interface {
 	if syncPeriod < time.Minute {
 		logger.WithField("syncPeriod", syncPeriod).Info("Provided GC sync period is too short. Setting to 1 minute")
@@ -71,18 +91,25 @@ func NewGCController(
 	}
 
 	return &gcController{
-		backupService:       backupService,
-		snapshotService:     snapshotService,
-		bucket:              bucket,
-		syncPeriod:          syncPeriod,
-		clock:               clock.RealClock{},
-		backupLister:        backupInformer.Lister(),
-		backupListerSynced:  backupInformer.Informer().HasSynced,
-		backupClient:        backupClient,
-		restoreLister:       restoreInformer.Lister(),
-		restoreListerSynced: restoreInformer.Informer().HasSynced,
-		restoreClient:       restoreClient,
-		logger:              logger,
+		storageLocations:     storageLocations,
+		syncPeriod:           syncPeriod,
+		clock:                clock.RealClock{},
+		backupLister:         backupInformer.Lister(),
+		backupListerSynced:   backupInformer.Informer().HasSynced,
+		restoreListerSynced:  restoreInformer.Informer().HasSynced,
+		scheduleLister:       scheduleInformer.Lister(),
+		scheduleListerSynced: scheduleInformer.Informer().HasSynced,
+		deleter: &backupDeleter{
+			storageLocations: storageLocations,
+			snapshotService:  snapshotService,
+			backupClient:     backupClient,
+			restoreLister:    restoreInformer.Lister(),
+			restoreClient:    restoreClient,
+		},
+		keyProvider:    keyProvider,
+		newChunkPruner: newChunkPruner,
+		logger:         logger,
+		metrics:        metrics,
 	}
 }
 
@@ -93,7 +120,7 @@ var _ Interface = &gcController{}
 // ctx.Done() channel.
 func (c *gcController) Run(ctx context.Context, workers int) error {
 	c.logger.Info("Waiting for caches to sync")
-	if !cache.WaitForCacheSync(ctx.Done(), c.backupListerSynced, c.restoreListerSynced) {
+	if !cache.WaitForCacheSync(ctx.Done(), c.backupListerSynced, c.restoreListerSynced, c.scheduleListerSynced) {
 		return errors.New("timed out waiting for caches to sync")
 	}
 	c.logger.Info("Caches are synced")
@@ -104,74 +131,29 @@ func (c *gcController) Run(ctx context.Context, workers int) error {
 
 func (c *gcController) run() {
 	c.processBackups()
+	c.pruneChunks()
 }
 
 // garbageCollectBackup removes an expired backup by deleting any associated backup files (if
 // deleteBackupFiles = true), volume snapshots, restore API objects, and the backup API object
 // itself.
 func (c *gcController) garbageCollectBackup(backup *api.Backup, deleteBackupFiles bool) {
-	logContext := c.logger.WithField("backup", kube.NamespaceAndName(backup))
-
-	// if the backup includes snapshots but we don't currently have a PVProvider, we don't
-	// want to orphan the snapshots so skip garbage-collection entirely.
-	if c.snapshotService == nil && len(backup.Status.VolumeBackups) > 0 {
-		logContext.Warning("Cannot garbage-collect backup because backup includes snapshots and server is not configured with PersistentVolumeProvider")
-		return
-	}
-
-	// The GC process is primarily intended to delete expired cloud resources (file in object
-	// storage, snapshots). If we fail to delete any of these, we don't delete the Backup API
-	// object or metadata file in object storage so that we don't orphan the cloud resources.
-	deletionFailure := false
-
-	for _, volumeBackup := range backup.Status.VolumeBackups {
-		logContext.WithField("snapshotID", volumeBackup.SnapshotID).Info("Removing snapshot associated with backup")
-		if err := c.snapshotService.DeleteSnapshot(volumeBackup.SnapshotID); err != nil {
-			logContext.WithError(err).WithField("snapshotID", volumeBackup.SnapshotID).Error("Error deleting snapshot")
-			deletionFailure = true
-		}
-	}
-
-	// If applicable, delete everything in the backup dir in object storage *before* deleting the API object
-	// because otherwise the backup sync controller could re-sync the backup from object storage.
-	if deleteBackupFiles {
-		logContext.Info("Removing backup from object storage")
-		if err := c.backupService.DeleteBackupDir(c.bucket, backup.Name); err != nil {
-			logContext.WithError(err).Error("Error deleting backup")
-			deletionFailure = true
-		}
-	}
+	c.metrics.RegisterGCBackupDeletionAttempt()
 
-	logContext.Info("Getting restore API objects referencing backup")
-	if restores, err := c.restoreLister.Restores(backup.Namespace).List(labels.Everything()); err != nil {
-		logContext.WithError(errors.WithStack(err)).Error("Error getting Restore API objects")
-	} else {
-		for _, restore := range restores {
-			if restore.Spec.BackupName == backup.Name {
-				logContext.WithField("restore", kube.NamespaceAndName(restore)).Info("Removing Restore API object referencing Backup")
-				if err := c.restoreClient.Restores(restore.Namespace).Delete(restore.Name, &metav1.DeleteOptions{}); err != nil {
-					logContext.WithError(errors.WithStack(err)).WithField("restore", kube.NamespaceAndName(restore)).
-						Error("Error deleting Restore API object")
-				}
-			}
-		}
-	}
-
-	if deletionFailure {
-		logContext.Warning("Backup will not be deleted due to errors deleting related object storage files(s) and/or volume snapshots")
-		return
-	}
-
-	logContext.Info("Removing Backup API object")
-	if err := c.backupClient.Backups(backup.Namespace).Delete(backup.Name, &metav1.DeleteOptions{}); err != nil {
-		logContext.WithError(errors.WithStack(err)).Error("Error deleting Backup API object")
+	if errs := c.deleter.deleteBackup(backup, deleteBackupFiles, c.logger.WithField("backup", kube.NamespaceAndName(backup))); len(errs) > 0 {
+		c.metrics.RegisterGCBackupDeletionFailed()
 	}
 }
 
 // garbageCollectBackups checks backups for expiration and triggers garbage-collection for the expired
-// ones.
+// ones. Backups of schedules with retention policies don't expire.
 func (c *gcController) garbageCollectBackups(backups []*api.Backup, expiration time.Time, deleteBackupFiles bool) {
 	for _, backup := range backups {
+		if c.getRetention(backup) != nil {
+			c.logger.WithField("backup", kube.NamespaceAndName(backup)).Debug("Backup's schedule has a retention policy, skipping expiration")
+			continue
+		}
+
 		if backup.Status.Expiration.Time.After(expiration) {
 			c.logger.WithField("backup", kube.NamespaceAndName(backup)).Info("Backup has not expired yet, skipping")
 			continue
@@ -189,12 +171,27 @@ func (c *gcController) processBackups() {
 
 	// GC backups in object storage. We do this in addition
 	// to GC'ing API objects to prevent orphan backup files.
-	backups, err := c.backupService.GetAllBackups(c.bucket)
+	locations, err := c.storageLocations.List()
 	if err != nil {
-		c.logger.WithError(err).Error("Error getting all backups from object storage")
-		return
+		// keep going with the locations that could be resolved
+		c.logger.WithError(err).Error("Error resolving backup storage locations")
+	}
+	for _, location := range locations {
+		backups, err := location.BackupService.GetAllBackups(location.Bucket)
+		if err != nil {
+			c.logger.WithError(err).WithField("storageLocation", location.Name).Error("Error getting all backups from object storage")
+			continue
+		}
+
+		// the backups may be shared with a cache, so copy them before recording their location
+		locationBackups := make([]*api.Backup, 0, len(backups))
+		for _, backup := range backups {
+			backup = backup.DeepCopy()
+			backup.Spec.StorageLocation = location.Name
+			locationBackups = append(locationBackups, backup)
+		}
+		c.garbageCollectBackups(locationBackups, now, true)
 	}
-	c.garbageCollectBackups(backups, now, true)
 
 	// GC backups without files in object storage
 	apiBackups, err := c.backupLister.List(labels.Everything())
@@ -203,4 +200,109 @@ func (c *gcController) processBackups() {
 		return
 	}
 	c.garbageCollectBackups(apiBackups, now, false)
+
+	c.pruneScheduledBackups()
+}
+
+// getRetention returns the retention policy of the schedule that created backup, or nil if it
+// wasn't created by a schedule that exists and has a retention policy in effect.
+func (c *gcController) getRetention(backup *api.Backup) *api.ScheduleRetention {
+	scheduleName := backup.Labels[api.ScheduleNameLabel]
+	if scheduleName == "" {
+		return nil
+	}
+
+	schedule, err := c.scheduleLister.Schedules(backup.Namespace).Get(scheduleName)
+	if err != nil {
+		return nil
+	}
+
+	return scheduleRetention(schedule)
+}
+
+// scheduleRetention returns schedule's retention policy, or nil if it doesn't have one in effect.
+// Retention policies only apply to schedules that have passed validation, and a policy without
+// any rules is ignored. The backups of schedules without a policy in effect expire after their TTL.
+func scheduleRetention(schedule *api.Schedule) *api.ScheduleRetention {
+	if schedule.Status.Phase != api.SchedulePhaseEnabled {
+		return nil
+	}
+	if schedule.Spec.Retention == nil || *schedule.Spec.Retention == (api.ScheduleRetention{}) {
+		return nil
+	}
+
+	return schedule.Spec.Retention
+}
+
+// pruneScheduledBackups garbage-collects the backups that aren't kept by their schedules'
+// retention policies.
+func (c *gcController) pruneScheduledBackups() {
+	schedules, err := c.scheduleLister.List(labels.Everything())
+	if err != nil {
+		c.logger.WithError(errors.WithStack(err)).Error("Error getting all Schedule API objects")
+		return
+	}
+
+	for _, schedule := range schedules {
+		retention := scheduleRetention(schedule)
+		if retention == nil {
+			continue
+		}
+
+		logContext := c.logger.WithField("schedule", kube.NamespaceAndName(schedule))
+
+		selector := labels.SelectorFromSet(labels.Set{api.ScheduleNameLabel: schedule.Name})
+		backups, err := c.backupLister.Backups(schedule.Namespace).List(selector)
+		if err != nil {
+			logContext.WithError(errors.WithStack(err)).Error("Error getting schedule's backups")
+			continue
+		}
+
+		for _, decision := range arkschedule.ApplyRetention(retention, backups) {
+			if decision.Keep {
+				continue
+			}
+
+			logContext.WithFields(logrus.Fields{
+				"backup": kube.NamespaceAndName(decision.Backup),
+				"reason": strings.Join(decision.Reasons, ", "),
+			}).Info("Pruning backup not kept by schedule's retention policy")
+			c.garbageCollectBackup(decision.Backup, true)
+		}
+	}
+}
+
+// pruneChunks deletes the pod volume data chunks in each storage location that aren't referenced
+// by any of its backups. A backup's chunks are uploaded before the manifests that reference them,
+// so nothing is pruned while one of this cluster's backups is in progress. Backups taken by other
+// clusters sharing a location are coordinated with through locks in its chunk store.
+func (c *gcController) pruneChunks() {
+	backups, err := c.backupLister.List(labels.Everything())
+	if err != nil {
+		c.logger.WithError(errors.WithStack(err)).Error("Error getting all Backup API objects")
+		return
+	}
+	for _, backup := range backups {
+		if backup.Status.Phase == api.BackupPhaseInProgress {
+			c.logger.WithField("backup", kube.NamespaceAndName(backup)).Info("Backup is in progress, skipping pruning of pod volume data")
+			return
+		}
+	}
+
+	locations, err := c.storageLocations.List()
+	if err != nil {
+		// keep going with the locations that could be resolved
+		c.logger.WithError(err).Error("Error resolving backup storage locations")
+	}
+	for _, location := range locations {
+		logContext := c.logger.WithField("storageLocation", location.Name)
+
+		deleted, err := c.newChunkPruner(location, c.keyProvider, logContext).Prune()
+		if err != nil {
+			logContext.WithError(err).Error("Error pruning unreferenced pod volume data")
+		}
+		if deleted > 0 {
+			logContext.WithField("chunks", deleted).Info("Pruned unreferenced pod volume data")
+		}
+	}
 }
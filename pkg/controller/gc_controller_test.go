@@ -20,6 +20,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	testlogger "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 
@@ -29,8 +31,10 @@ import (
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/encryption"
 	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
+	"github.com/heptio/ark/pkg/metrics"
 	. "github.com/heptio/ark/pkg/util/test"
 )
 
@@ -160,15 +164,17 @@ func TestGarbageCollect(t *testing.T) {
 			}
 
 			controller := NewGCController(
-				backupService,
+				newTestStorageLocations(backupService, bucket, sharedInformers, nil),
 				snapSvc,
-				bucket,
 				1*time.Millisecond,
 				sharedInformers.Ark().V1().Backups(),
 				client.ArkV1(),
 				sharedInformers.Ark().V1().Restores(),
 				client.ArkV1(),
+				sharedInformers.Ark().V1().Schedules(),
+				nil,
 				logger,
+				metrics.NewServerMetrics(),
 			).(*gcController)
 			controller.clock = fakeClock
 
@@ -188,6 +194,131 @@ func TestGarbageCollect(t *testing.T) {
 	}
 }
 
+func TestGarbageCollectContinuesPastFailedStorageLocation(t *testing.T) {
+	var (
+		defaultService  = &BackupService{}
+		otherService    = &BackupService{}
+		client          = fake.NewSimpleClientset()
+		sharedInformers = informers.NewSharedInformerFactory(client, 0)
+		fakeClock       = clock.NewFakeClock(time.Now())
+		logger, _       = testlogger.NewNullLogger()
+	)
+
+	sharedInformers.Ark().V1().BackupStorageLocations().Informer().GetStore().Add(newTestStorageLocation("other", "other-bucket"))
+
+	controller := NewGCController(
+		newTestStorageLocations(defaultService, "bucket", sharedInformers, map[string]cloudprovider.BackupService{"other": otherService}),
+		nil,
+		1*time.Millisecond,
+		sharedInformers.Ark().V1().Backups(),
+		client.ArkV1(),
+		sharedInformers.Ark().V1().Restores(),
+		client.ArkV1(),
+		sharedInformers.Ark().V1().Schedules(),
+		nil,
+		logger,
+		metrics.NewServerMetrics(),
+	).(*gcController)
+	controller.clock = fakeClock
+
+	expired := NewTestBackup().WithName("backup-1").WithExpiration(fakeClock.Now().Add(-1 * time.Second)).Backup
+
+	defaultService.On("GetAllBackups", "bucket").Return(nil, errors.New("bucket unavailable"))
+	otherService.On("GetAllBackups", "other-bucket").Return([]*api.Backup{expired}, nil)
+	otherService.On("DeleteBackupDir", "other-bucket", "backup-1").Return(nil)
+
+	controller.processBackups()
+
+	defaultService.AssertExpectations(t)
+	otherService.AssertExpectations(t)
+}
+
+func TestGarbageCollectScheduleRetention(t *testing.T) {
+	var (
+		backupService   = &BackupService{}
+		client          = fake.NewSimpleClientset()
+		sharedInformers = informers.NewSharedInformerFactory(client, 0)
+		fakeClock       = clock.NewFakeClock(time.Now())
+		logger, _       = testlogger.NewNullLogger()
+		expired         = fakeClock.Now().Add(-1 * time.Minute)
+	)
+
+	controller := NewGCController(
+		newTestStorageLocations(backupService, "bucket", sharedInformers, nil),
+		nil,
+		1*time.Millisecond,
+		sharedInformers.Ark().V1().Backups(),
+		client.ArkV1(),
+		sharedInformers.Ark().V1().Restores(),
+		client.ArkV1(),
+		sharedInformers.Ark().V1().Schedules(),
+		nil,
+		logger,
+		metrics.NewServerMetrics(),
+	).(*gcController)
+	controller.clock = fakeClock
+
+	for _, schedule := range []*api.Schedule{
+		NewTestSchedule(api.DefaultNamespace, "with-retention").WithPhase(api.SchedulePhaseEnabled).
+			WithRetention(api.ScheduleRetention{KeepLast: 2}).Schedule,
+		NewTestSchedule(api.DefaultNamespace, "without-retention").WithPhase(api.SchedulePhaseEnabled).Schedule,
+		// the retention policies of schedules that aren't enabled, or that don't have any rules, aren't applied
+		NewTestSchedule(api.DefaultNamespace, "failed-validation").WithPhase(api.SchedulePhaseFailedValidation).
+			WithRetention(api.ScheduleRetention{KeepLast: 1, KeepDaily: -1}).Schedule,
+		NewTestSchedule(api.DefaultNamespace, "empty-retention").WithPhase(api.SchedulePhaseEnabled).
+			WithRetention(api.ScheduleRetention{}).Schedule,
+	} {
+		sharedInformers.Ark().V1().Schedules().Informer().GetStore().Add(schedule)
+	}
+
+	backups := []*api.Backup{
+		// expired, but kept by the retention policy
+		NewTestBackup().WithName("retention-3").WithLabel(api.ScheduleNameLabel, "with-retention").
+			WithPhase(api.BackupPhaseCompleted).WithStartTimestamp(fakeClock.Now().Add(-1 * time.Hour)).WithExpiration(expired).Backup,
+		NewTestBackup().WithName("retention-2").WithLabel(api.ScheduleNameLabel, "with-retention").
+			WithPhase(api.BackupPhaseCompleted).WithStartTimestamp(fakeClock.Now().Add(-2 * time.Hour)).WithExpiration(expired).Backup,
+		// unexpired, but pruned by the retention policy
+		NewTestBackup().WithName("retention-1").WithLabel(api.ScheduleNameLabel, "with-retention").
+			WithPhase(api.BackupPhaseCompleted).WithStartTimestamp(fakeClock.Now().Add(-3 * time.Hour)).WithExpiration(fakeClock.Now().Add(time.Hour)).Backup,
+		// unexpired, and not pruned by policies that aren't applied
+		NewTestBackup().WithName("failed-validation-2").WithLabel(api.ScheduleNameLabel, "failed-validation").
+			WithPhase(api.BackupPhaseCompleted).WithStartTimestamp(fakeClock.Now().Add(-1 * time.Hour)).WithExpiration(fakeClock.Now().Add(time.Hour)).Backup,
+		NewTestBackup().WithName("failed-validation-1").WithLabel(api.ScheduleNameLabel, "failed-validation").
+			WithPhase(api.BackupPhaseCompleted).WithStartTimestamp(fakeClock.Now().Add(-2 * time.Hour)).WithExpiration(fakeClock.Now().Add(time.Hour)).Backup,
+		NewTestBackup().WithName("empty-retention-2").WithLabel(api.ScheduleNameLabel, "empty-retention").
+			WithPhase(api.BackupPhaseCompleted).WithStartTimestamp(fakeClock.Now().Add(-1 * time.Hour)).WithExpiration(fakeClock.Now().Add(time.Hour)).Backup,
+		NewTestBackup().WithName("empty-retention-1").WithLabel(api.ScheduleNameLabel, "empty-retention").
+			WithPhase(api.BackupPhaseCompleted).WithStartTimestamp(fakeClock.Now().Add(-2 * time.Hour)).WithExpiration(fakeClock.Now().Add(time.Hour)).Backup,
+		// expired, from a schedule whose retention policy isn't applied
+		NewTestBackup().WithName("failed-validation-0").WithLabel(api.ScheduleNameLabel, "failed-validation").
+			WithPhase(api.BackupPhaseCompleted).WithStartTimestamp(fakeClock.Now().Add(-3 * time.Hour)).WithExpiration(expired).Backup,
+		// expired, from a schedule without a retention policy
+		NewTestBackup().WithName("no-retention-1").WithLabel(api.ScheduleNameLabel, "without-retention").
+			WithPhase(api.BackupPhaseCompleted).WithStartTimestamp(fakeClock.Now().Add(-3 * time.Hour)).WithExpiration(expired).Backup,
+	}
+	// the backups expired by their TTL are only in object storage, so they're only deleted once
+	for _, backup := range backups[:7] {
+		sharedInformers.Ark().V1().Backups().Informer().GetStore().Add(backup)
+	}
+
+	backupService.On("GetAllBackups", "bucket").Return(backups, nil)
+	backupService.On("DeleteBackupDir", "bucket", "failed-validation-0").Return(nil)
+	backupService.On("DeleteBackupDir", "bucket", "no-retention-1").Return(nil)
+	backupService.On("DeleteBackupDir", "bucket", "retention-1").Return(nil)
+
+	controller.processBackups()
+
+	var deleted []string
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "delete" && action.GetResource().Resource == "backups" {
+			deleted = append(deleted, action.(core.DeleteAction).GetName())
+		}
+	}
+	assert.Equal(t, []string{"failed-validation-0", "no-retention-1", "retention-1"}, deleted)
+
+	backupService.AssertExpectations(t)
+}
+
 func TestGarbageCollectBackup(t *testing.T) {
 	tests := []struct {
 		name                           string
@@ -239,15 +370,17 @@ func TestGarbageCollectBackup(t *testing.T) {
 				bucket          = "bucket-1"
 				logger, _       = testlogger.NewNullLogger()
 				controller      = NewGCController(
-					backupService,
+					newTestStorageLocations(backupService, bucket, sharedInformers, nil),
 					snapshotService,
-					bucket,
 					1*time.Millisecond,
 					sharedInformers.Ark().V1().Backups(),
 					client.ArkV1(),
 					sharedInformers.Ark().V1().Restores(),
 					client.ArkV1(),
+					sharedInformers.Ark().V1().Schedules(),
+					nil,
 					logger,
+					metrics.NewServerMetrics(),
 				).(*gcController)
 			)
 
@@ -325,15 +458,17 @@ func TestGarbageCollectPicksUpBackupUponExpiration(t *testing.T) {
 	)
 
 	controller := NewGCController(
-		backupService,
+		newTestStorageLocations(backupService, "bucket", sharedInformers, nil),
 		snapshotService,
-		"bucket",
 		1*time.Millisecond,
 		sharedInformers.Ark().V1().Backups(),
 		client.ArkV1(),
 		sharedInformers.Ark().V1().Restores(),
 		client.ArkV1(),
+		sharedInformers.Ark().V1().Schedules(),
+		nil,
 		logger,
+		metrics.NewServerMetrics(),
 	).(*gcController)
 	controller.clock = fakeClock
 
@@ -354,3 +489,70 @@ func TestGarbageCollectPicksUpBackupUponExpiration(t *testing.T) {
 
 	backupService.AssertExpectations(t)
 }
+
+type fakeChunkPruner struct {
+	location string
+	pruned   *[]string
+}
+
+func (p *fakeChunkPruner) Prune() (int, error) {
+	*p.pruned = append(*p.pruned, p.location)
+	return 0, nil
+}
+
+func TestGarbageCollectPrunesChunks(t *testing.T) {
+	tests := []struct {
+		name           string
+		backups        []*api.Backup
+		expectedPruned []string
+	}{
+		{
+			name:           "no backups in progress",
+			backups:        []*api.Backup{NewTestBackup().WithName("backup-1").WithPhase(api.BackupPhaseCompleted).Backup},
+			expectedPruned: []string{api.DefaultBackupStorageLocation},
+		},
+		{
+			name: "backup in progress",
+			backups: []*api.Backup{
+				NewTestBackup().WithName("backup-1").WithPhase(api.BackupPhaseCompleted).Backup,
+				NewTestBackup().WithName("backup-2").WithPhase(api.BackupPhaseInProgress).Backup,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				client          = fake.NewSimpleClientset()
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+				logger, _       = testlogger.NewNullLogger()
+				pruned          []string
+			)
+
+			controller := NewGCController(
+				newTestStorageLocations(&BackupService{}, "bucket", sharedInformers, nil),
+				nil,
+				1*time.Millisecond,
+				sharedInformers.Ark().V1().Backups(),
+				client.ArkV1(),
+				sharedInformers.Ark().V1().Restores(),
+				client.ArkV1(),
+				sharedInformers.Ark().V1().Schedules(),
+				nil,
+				logger,
+				metrics.NewServerMetrics(),
+			).(*gcController)
+			controller.newChunkPruner = func(location *cloudprovider.StorageLocation, keyProvider encryption.KeyProvider, log logrus.FieldLogger) chunkPruner {
+				return &fakeChunkPruner{location: location.Name, pruned: &pruned}
+			}
+
+			for _, backup := range test.backups {
+				sharedInformers.Ark().V1().Backups().Informer().GetStore().Add(backup)
+			}
+
+			controller.pruneChunks()
+
+			assert.Equal(t, test.expectedPruned, pruned)
+		})
+	}
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	corev1api "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/encryption"
+	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
+	informers "github.com/heptio/ark/pkg/generated/informers/externalversions/ark/v1"
+	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+	"github.com/heptio/ark/pkg/podvolume"
+)
+
+// podVolumeRepository backs up and restores the contents of pod volume directories.
+type podVolumeRepository interface {
+	Backup(dir, backupName string) (string, error)
+	Restore(dir, backupName, snapshotID string) error
+}
+
+// newPodVolumeRepositoryFunc returns the podVolumeRepository for a storage location.
+type newPodVolumeRepositoryFunc func(location *cloudprovider.StorageLocation, keyProvider encryption.KeyProvider, log logrus.FieldLogger) podVolumeRepository
+
+func newPodVolumeRepository(location *cloudprovider.StorageLocation, keyProvider encryption.KeyProvider, log logrus.FieldLogger) podVolumeRepository {
+	return podvolume.NewRepository(location.BackupService, location.Bucket, keyProvider, nil, log)
+}
+
+// newIndexedPodVolumeRepositoryFunc returns a newPodVolumeRepositoryFunc whose repositories
+// share index, so each storage location's chunks are listed once per backup.
+func newIndexedPodVolumeRepositoryFunc(index *podvolume.ChunkIndex) newPodVolumeRepositoryFunc {
+	return func(location *cloudprovider.StorageLocation, keyProvider encryption.KeyProvider, log logrus.FieldLogger) podVolumeRepository {
+		return podvolume.NewRepository(location.BackupService, location.Bucket, keyProvider, index, log)
+	}
+}
+
+type podVolumeBackupController struct {
+	podVolumeBackupClient       arkv1client.PodVolumeBackupsGetter
+	podVolumeBackupLister       listers.PodVolumeBackupLister
+	podVolumeBackupListerSynced cache.InformerSynced
+	podClient                   corev1client.PodsGetter
+	pvcClient                   corev1client.PersistentVolumeClaimsGetter
+	storageLocations            cloudprovider.StorageLocationResolver
+	keyProvider                 encryption.KeyProvider
+	newRepository               newPodVolumeRepositoryFunc
+	nodeName                    string
+	hostPodsDir                 string
+	syncHandler                 func(key string) error
+	queue                       workqueue.RateLimitingInterface
+	logger                      *logrus.Logger
+}
+
+// NewPodVolumeBackupController creates a new PodVolumeBackupController, which runs in the node
+// agent and backs up the volumes of pods running on nodeName. hostPodsDir is where the kubelet's
+// pods directory is mounted.
+func NewPodVolumeBackupController(
+	podVolumeBackupClient arkv1client.PodVolumeBackupsGetter,
+	podVolumeBackupInformer informers.PodVolumeBackupInformer,
+	podClient corev1client.PodsGetter,
+	pvcClient corev1client.PersistentVolumeClaimsGetter,
+	storageLocations cloudprovider.StorageLocationResolver,
+	keyProvider encryption.KeyProvider,
+	nodeName string,
+	hostPodsDir string,
+	logger *logrus.Logger,
+) Interface {
+	c := &podVolumeBackupController{
+		podVolumeBackupClient:       podVolumeBackupClient,
+		podVolumeBackupLister:       podVolumeBackupInformer.Lister(),
+		podVolumeBackupListerSynced: podVolumeBackupInformer.Informer().HasSynced,
+		podClient:                   podClient,
+		pvcClient:                   pvcClient,
+		storageLocations:            storageLocations,
+		keyProvider:                 keyProvider,
+		newRepository:               newIndexedPodVolumeRepositoryFunc(podvolume.NewChunkIndex()),
+		nodeName:                    nodeName,
+		hostPodsDir:                 hostPodsDir,
+		queue:                       workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "podvolumebackup"),
+		logger:                      logger,
+	}
+
+	c.syncHandler = c.processPodVolumeBackup
+
+	podVolumeBackupInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				pvb := obj.(*api.PodVolumeBackup)
+
+				if pvb.Spec.Node != c.nodeName {
+					return
+				}
+
+				switch pvb.Status.Phase {
+				case "", api.PodVolumeBackupPhaseNew:
+				default:
+					return
+				}
+
+				key, err := cache.MetaNamespaceKeyFunc(pvb)
+				if err != nil {
+					c.logger.WithError(errors.WithStack(err)).
+						WithField("podVolumeBackup", pvb.Name).
+						Error("Error creating queue key, item not added to queue")
+					return
+				}
+				c.queue.Add(key)
+			},
+		},
+	)
+
+	return c
+}
+
+// Run is a blocking function that runs the specified number of worker goroutines
+// to process items in the work queue. It will return when it receives on the
+// ctx.Done() channel.
+func (c *podVolumeBackupController) Run(ctx context.Context, numWorkers int) error {
+	var wg sync.WaitGroup
+
+	defer func() {
+		c.logger.Info("Waiting for workers to finish their work")
+
+		c.queue.ShutDown()
+
+		// We have to wait here in the deferred function instead of at the bottom of the function body
+		// because we have to shut down the queue in order for the workers to shut down gracefully, and
+		// we want to shut down the queue via defer and not at the end of the body.
+		wg.Wait()
+
+		c.logger.Info("All workers have finished")
+	}()
+
+	c.logger.Info("Starting PodVolumeBackupController")
+	defer c.logger.Info("Shutting down PodVolumeBackupController")
+
+	c.logger.Info("Waiting for caches to sync")
+	if !cache.WaitForCacheSync(ctx.Done(), c.podVolumeBackupListerSynced) {
+		return errors.New("timed out waiting for caches to sync")
+	}
+	c.logger.Info("Caches are synced")
+
+	wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			wait.Until(c.runWorker, time.Second, ctx.Done())
+			wg.Done()
+		}()
+	}
+
+	<-ctx.Done()
+
+	return nil
+}
+
+// runWorker runs a worker until the controller's queue indicates it's time to shut down.
+func (c *podVolumeBackupController) runWorker() {
+	// continually take items off the queue (waits if it's
+	// empty) until we get a shutdown signal from the queue
+	for c.processNextWorkItem() {
+	}
+}
+
+// processNextWorkItem processes a single item from the queue.
+func (c *podVolumeBackupController) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	// always call done on this item, since if it fails we'll add
+	// it back with rate-limiting below
+	defer c.queue.Done(key)
+
+	err := c.syncHandler(key.(string))
+	if err == nil {
+		// If you had no error, tell the queue to stop tracking history for your key. This will reset
+		// things like failure counts for per-item rate limiting.
+		c.queue.Forget(key)
+		return true
+	}
+
+	c.logger.WithError(err).WithField("key", key).Error("Error in syncHandler, re-adding item to queue")
+
+	// we had an error processing the item so add it back
+	// into the queue for re-processing with rate-limiting
+	c.queue.AddRateLimited(key)
+
+	return true
+}
+
+// processPodVolumeBackup is the default per-item sync handler. It backs up the volume of a new
+// PodVolumeBackup for a pod on this node, and records the outcome in its status.
+func (c *podVolumeBackupController) processPodVolumeBackup(key string) error {
+	logContext := c.logger.WithField("key", key)
+
+	logContext.Debug("Running processPodVolumeBackup")
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return errors.Wrap(err, "error splitting queue key")
+	}
+
+	pvb, err := c.podVolumeBackupLister.PodVolumeBackups(ns).Get(name)
+	if apierrors.IsNotFound(err) {
+		logContext.Debug("Unable to find PodVolumeBackup")
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "error getting PodVolumeBackup")
+	}
+
+	switch pvb.Status.Phase {
+	case "", api.PodVolumeBackupPhaseNew:
+	default:
+		return nil
+	}
+	if pvb.Spec.Node != c.nodeName {
+		return nil
+	}
+
+	// don't modify items in the cache
+	pvb = pvb.DeepCopy()
+
+	pvb.Status.Phase = api.PodVolumeBackupPhaseInProgress
+	if pvb, err = c.podVolumeBackupClient.PodVolumeBackups(pvb.Namespace).Update(pvb); err != nil {
+		return errors.Wrapf(err, "error updating PodVolumeBackup %s", key)
+	}
+
+	logContext.WithFields(logrus.Fields{
+		"pod":    pvb.Spec.Pod.Namespace + "/" + pvb.Spec.Pod.Name,
+		"volume": pvb.Spec.Volume,
+	}).Info("Backing up pod volume")
+
+	pvb.Status.SnapshotID, err = c.backupVolume(pvb, logContext)
+	if err != nil {
+		logContext.WithError(err).Error("Error backing up pod volume")
+		pvb.Status.Phase = api.PodVolumeBackupPhaseFailed
+		pvb.Status.Message = err.Error()
+	} else {
+		pvb.Status.Phase = api.PodVolumeBackupPhaseCompleted
+	}
+
+	_, err = c.podVolumeBackupClient.PodVolumeBackups(pvb.Namespace).Update(pvb)
+	return errors.Wrapf(err, "error updating PodVolumeBackup %s", key)
+}
+
+// backupVolume backs up the contents of pvb's volume and returns the ID of the snapshot.
+func (c *podVolumeBackupController) backupVolume(pvb *api.PodVolumeBackup, log logrus.FieldLogger) (string, error) {
+	pod, err := c.podClient.Pods(pvb.Spec.Pod.Namespace).Get(pvb.Spec.Pod.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "error getting pod")
+	}
+	if pvb.Spec.Pod.UID != "" && pod.UID != pvb.Spec.Pod.UID {
+		return "", errors.New("pod has been replaced since the backup started")
+	}
+
+	dir, err := c.getVolumePath(pod, pvb.Spec.Volume)
+	if err != nil {
+		return "", err
+	}
+
+	location, err := c.storageLocations.Get(pvb.Spec.StorageLocation)
+	if err != nil {
+		return "", err
+	}
+
+	return c.newRepository(location, c.keyProvider, log).Backup(dir, pvb.Spec.BackupName)
+}
+
+// getVolumePath returns the path, on this node, of the named volume of pod.
+func (c *podVolumeBackupController) getVolumePath(pod *corev1api.Pod, volume string) (string, error) {
+	volumeDir, err := podvolume.GetVolumeDirectory(pod, volume, c.pvcClient)
+	if err != nil {
+		return "", err
+	}
+
+	return podvolume.FindVolumePath(c.hostPodsDir, pod.UID, volumeDir)
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	testlogger "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1api "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/encryption"
+	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
+	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+// fakePodGetter is a PodsGetter that returns the pods it's been given.
+type fakePodGetter struct {
+	corev1client.PodInterface
+	pods map[string]*corev1api.Pod
+}
+
+func (g *fakePodGetter) Pods(namespace string) corev1client.PodInterface {
+	return g
+}
+
+func (g *fakePodGetter) Get(name string, options metav1.GetOptions) (*corev1api.Pod, error) {
+	pod, ok := g.pods[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, name)
+	}
+	return pod, nil
+}
+
+// fakePodVolumeRepository records the directories it backs up and restores.
+type fakePodVolumeRepository struct {
+	location   *cloudprovider.StorageLocation
+	backedUp   []string
+	restored   []string
+	snapshotID string
+	err        error
+}
+
+func (r *fakePodVolumeRepository) Backup(dir, backupName string) (string, error) {
+	r.backedUp = append(r.backedUp, dir)
+	if r.err != nil {
+		return "", r.err
+	}
+	return r.snapshotID, nil
+}
+
+func (r *fakePodVolumeRepository) Restore(dir, backupName, snapshotID string) error {
+	r.restored = append(r.restored, dir)
+	return r.err
+}
+
+func (r *fakePodVolumeRepository) newRepository(location *cloudprovider.StorageLocation, keyProvider encryption.KeyProvider, log logrus.FieldLogger) podVolumeRepository {
+	r.location = location
+	return r
+}
+
+// newTestVolumePod returns a pod with an emptyDir volume named "data", and creates the volume's
+// directory under hostPodsDir.
+func newTestVolumePod(t *testing.T, hostPodsDir, nodeName string) (*corev1api.Pod, string) {
+	pod := &corev1api.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "pod-1", UID: "uid-1"},
+		Spec: corev1api.PodSpec{
+			NodeName: nodeName,
+			Volumes: []corev1api.Volume{
+				{Name: "data", VolumeSource: corev1api.VolumeSource{EmptyDir: &corev1api.EmptyDirVolumeSource{}}},
+			},
+		},
+	}
+
+	dir := filepath.Join(hostPodsDir, "uid-1", "volumes", "kubernetes.io~empty-dir", "data")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	return pod, dir
+}
+
+func TestProcessPodVolumeBackup(t *testing.T) {
+	tests := []struct {
+		name               string
+		node               string
+		phase              api.PodVolumeBackupPhase
+		podUID             string
+		storageLocation    string
+		repositoryErr      error
+		expectedPhase      api.PodVolumeBackupPhase
+		expectedSnapshotID string
+		expectedMessage    string
+	}{
+		{
+			name:               "new backup on this node is backed up",
+			node:               "node-1",
+			expectedPhase:      api.PodVolumeBackupPhaseCompleted,
+			expectedSnapshotID: "snapshot-1",
+		},
+		{
+			name:               "backup in another storage location uses that location",
+			node:               "node-1",
+			storageLocation:    "other",
+			expectedPhase:      api.PodVolumeBackupPhaseCompleted,
+			expectedSnapshotID: "snapshot-1",
+		},
+		{
+			name:  "backup on another node is ignored",
+			node:  "node-2",
+			phase: api.PodVolumeBackupPhaseNew,
+		},
+		{
+			name:  "backup that's already in progress is ignored",
+			node:  "node-1",
+			phase: api.PodVolumeBackupPhaseInProgress,
+		},
+		{
+			name:            "repository error fails the backup",
+			node:            "node-1",
+			repositoryErr:   errors.New("bucket not found"),
+			expectedPhase:   api.PodVolumeBackupPhaseFailed,
+			expectedMessage: "bucket not found",
+		},
+		{
+			name:            "replaced pod fails the backup",
+			node:            "node-1",
+			podUID:          "uid-2",
+			expectedPhase:   api.PodVolumeBackupPhaseFailed,
+			expectedMessage: "pod has been replaced since the backup started",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hostPodsDir, err := ioutil.TempDir("", "host-pods")
+			require.NoError(t, err)
+			defer os.RemoveAll(hostPodsDir)
+
+			pod, volumeDir := newTestVolumePod(t, hostPodsDir, "node-1")
+			podUID := pod.UID
+			if test.podUID != "" {
+				podUID = types.UID(test.podUID)
+			}
+
+			pvb := &api.PodVolumeBackup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: api.DefaultNamespace, Name: "pvb-1"},
+				Spec: api.PodVolumeBackupSpec{
+					Node:            test.node,
+					Pod:             corev1api.ObjectReference{Namespace: "ns-1", Name: "pod-1", UID: podUID},
+					Volume:          "data",
+					BackupName:      "backup-1",
+					StorageLocation: test.storageLocation,
+				},
+				Status: api.PodVolumeBackupStatus{Phase: test.phase},
+			}
+
+			var (
+				client          = fake.NewSimpleClientset(pvb)
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+				repository      = &fakePodVolumeRepository{snapshotID: "snapshot-1", err: test.repositoryErr}
+				logger, _       = testlogger.NewNullLogger()
+			)
+
+			sharedInformers.Ark().V1().BackupStorageLocations().Informer().GetStore().Add(newTestStorageLocation("other", "other-bucket"))
+			sharedInformers.Ark().V1().PodVolumeBackups().Informer().GetStore().Add(pvb)
+
+			c := NewPodVolumeBackupController(
+				client.ArkV1(),
+				sharedInformers.Ark().V1().PodVolumeBackups(),
+				&fakePodGetter{pods: map[string]*corev1api.Pod{"pod-1": pod}},
+				nil,
+				newTestStorageLocations(&arktest.BackupService{}, "bucket", sharedInformers, map[string]cloudprovider.BackupService{"other": &arktest.BackupService{}}),
+				nil,
+				"node-1",
+				hostPodsDir,
+				logger,
+			).(*podVolumeBackupController)
+			c.newRepository = repository.newRepository
+
+			require.NoError(t, c.processPodVolumeBackup("heptio-ark/pvb-1"))
+
+			updated, err := client.ArkV1().PodVolumeBackups(api.DefaultNamespace).Get("pvb-1", metav1.GetOptions{})
+			require.NoError(t, err)
+
+			if test.expectedPhase == "" {
+				assert.Equal(t, test.phase, updated.Status.Phase)
+				assert.Empty(t, repository.backedUp)
+				return
+			}
+
+			assert.Equal(t, test.expectedPhase, updated.Status.Phase)
+			assert.Equal(t, test.expectedSnapshotID, updated.Status.SnapshotID)
+			assert.Equal(t, test.expectedMessage, updated.Status.Message)
+
+			if test.expectedPhase == api.PodVolumeBackupPhaseCompleted {
+				assert.Equal(t, []string{volumeDir}, repository.backedUp)
+
+				expectedBucket := "bucket"
+				if test.storageLocation != "" {
+					expectedBucket = "other-bucket"
+				}
+				assert.Equal(t, expectedBucket, repository.location.Bucket)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	corev1api "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/encryption"
+	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
+	informers "github.com/heptio/ark/pkg/generated/informers/externalversions/ark/v1"
+	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+	"github.com/heptio/ark/pkg/podvolume"
+)
+
+// podNotReadyRequeueDelay is how long to wait before checking again whether a restored pod's
+// restore helper is running.
+const podNotReadyRequeueDelay = 5 * time.Second
+
+type podVolumeRestoreController struct {
+	podVolumeRestoreClient       arkv1client.PodVolumeRestoresGetter
+	podVolumeRestoreLister       listers.PodVolumeRestoreLister
+	podVolumeRestoreListerSynced cache.InformerSynced
+	podClient                    corev1client.PodsGetter
+	pvcClient                    corev1client.PersistentVolumeClaimsGetter
+	storageLocations             cloudprovider.StorageLocationResolver
+	keyProvider                  encryption.KeyProvider
+	newRepository                newPodVolumeRepositoryFunc
+	nodeName                     string
+	hostPodsDir                  string
+	requeueDelay                 time.Duration
+	syncHandler                  func(key string) error
+	queue                        workqueue.RateLimitingInterface
+	logger                       *logrus.Logger
+}
+
+// NewPodVolumeRestoreController creates a new PodVolumeRestoreController, which runs in the node
+// agent and restores the volumes of restored pods once they've been scheduled to nodeName and
+// their restore helper init containers are running. hostPodsDir is where the kubelet's pods
+// directory is mounted.
+func NewPodVolumeRestoreController(
+	podVolumeRestoreClient arkv1client.PodVolumeRestoresGetter,
+	podVolumeRestoreInformer informers.PodVolumeRestoreInformer,
+	podClient corev1client.PodsGetter,
+	pvcClient corev1client.PersistentVolumeClaimsGetter,
+	storageLocations cloudprovider.StorageLocationResolver,
+	keyProvider encryption.KeyProvider,
+	nodeName string,
+	hostPodsDir string,
+	logger *logrus.Logger,
+) Interface {
+	c := &podVolumeRestoreController{
+		podVolumeRestoreClient:       podVolumeRestoreClient,
+		podVolumeRestoreLister:       podVolumeRestoreInformer.Lister(),
+		podVolumeRestoreListerSynced: podVolumeRestoreInformer.Informer().HasSynced,
+		podClient:                    podClient,
+		pvcClient:                    pvcClient,
+		storageLocations:             storageLocations,
+		keyProvider:                  keyProvider,
+		newRepository:                newPodVolumeRepository,
+		nodeName:                     nodeName,
+		hostPodsDir:                  hostPodsDir,
+		requeueDelay:                 podNotReadyRequeueDelay,
+		queue:                        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "podvolumerestore"),
+		logger:                       logger,
+	}
+
+	c.syncHandler = c.processPodVolumeRestore
+
+	podVolumeRestoreInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				pvr := obj.(*api.PodVolumeRestore)
+
+				switch pvr.Status.Phase {
+				case "", api.PodVolumeRestorePhaseNew:
+				default:
+					return
+				}
+
+				key, err := cache.MetaNamespaceKeyFunc(pvr)
+				if err != nil {
+					c.logger.WithError(errors.WithStack(err)).
+						WithField("podVolumeRestore", pvr.Name).
+						Error("Error creating queue key, item not added to queue")
+					return
+				}
+				c.queue.Add(key)
+			},
+		},
+	)
+
+	return c
+}
+
+// Run is a blocking function that runs the specified number of worker goroutines
+// to process items in the work queue. It will return when it receives on the
+// ctx.Done() channel.
+func (c *podVolumeRestoreController) Run(ctx context.Context, numWorkers int) error {
+	var wg sync.WaitGroup
+
+	defer func() {
+		c.logger.Info("Waiting for workers to finish their work")
+
+		c.queue.ShutDown()
+
+		// We have to wait here in the deferred function instead of at the bottom of the function body
+		// because we have to shut down the queue in order for the workers to shut down gracefully, and
+		// we want to shut down the queue via defer and not at the end of the body.
+		wg.Wait()
+
+		c.logger.Info("All workers have finished")
+	}()
+
+	c.logger.Info("Starting PodVolumeRestoreController")
+	defer c.logger.Info("Shutting down PodVolumeRestoreController")
+
+	c.logger.Info("Waiting for caches to sync")
+	if !cache.WaitForCacheSync(ctx.Done(), c.podVolumeRestoreListerSynced) {
+		return errors.New("timed out waiting for caches to sync")
+	}
+	c.logger.Info("Caches are synced")
+
+	wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			wait.Until(c.runWorker, time.Second, ctx.Done())
+			wg.Done()
+		}()
+	}
+
+	<-ctx.Done()
+
+	return nil
+}
+
+// runWorker runs a worker until the controller's queue indicates it's time to shut down.
+func (c *podVolumeRestoreController) runWorker() {
+	// continually take items off the queue (waits if it's
+	// empty) until we get a shutdown signal from the queue
+	for c.processNextWorkItem() {
+	}
+}
+
+// processNextWorkItem processes a single item from the queue.
+func (c *podVolumeRestoreController) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	// always call done on this item, since if it fails we'll add
+	// it back with rate-limiting below
+	defer c.queue.Done(key)
+
+	err := c.syncHandler(key.(string))
+	if err == nil {
+		// If you had no error, tell the queue to stop tracking history for your key. This will reset
+		// things like failure counts for per-item rate limiting.
+		c.queue.Forget(key)
+		return true
+	}
+
+	c.logger.WithError(err).WithField("key", key).Error("Error in syncHandler, re-adding item to queue")
+
+	// we had an error processing the item so add it back
+	// into the queue for re-processing with rate-limiting
+	c.queue.AddRateLimited(key)
+
+	return true
+}
+
+// processPodVolumeRestore is the default per-item sync handler. Once the PodVolumeRestore's pod
+// is running its restore helper on this node, it restores the volume's contents, signals the
+// restore helper, and records the outcome in the PodVolumeRestore's status.
+func (c *podVolumeRestoreController) processPodVolumeRestore(key string) error {
+	logContext := c.logger.WithField("key", key)
+
+	logContext.Debug("Running processPodVolumeRestore")
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return errors.Wrap(err, "error splitting queue key")
+	}
+
+	pvr, err := c.podVolumeRestoreLister.PodVolumeRestores(ns).Get(name)
+	if apierrors.IsNotFound(err) {
+		logContext.Debug("Unable to find PodVolumeRestore")
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "error getting PodVolumeRestore")
+	}
+
+	switch pvr.Status.Phase {
+	case "", api.PodVolumeRestorePhaseNew:
+	default:
+		return nil
+	}
+
+	pod, err := c.podClient.Pods(pvr.Spec.Pod.Namespace).Get(pvr.Spec.Pod.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "error getting pod")
+	}
+
+	// the pod's volumes are only mounted on its node once it's been scheduled and its
+	// init containers have started
+	if pod.Spec.NodeName != "" && pod.Spec.NodeName != c.nodeName {
+		return nil
+	}
+	if pod.Spec.NodeName == "" || !podvolume.IsRestoreHelperRunning(pod) {
+		logContext.Debug("Restore helper is not running yet, requeuing")
+		c.queue.AddAfter(key, c.requeueDelay)
+		return nil
+	}
+
+	// don't modify items in the cache
+	pvr = pvr.DeepCopy()
+
+	pvr.Status.Phase = api.PodVolumeRestorePhaseInProgress
+	if pvr, err = c.podVolumeRestoreClient.PodVolumeRestores(pvr.Namespace).Update(pvr); err != nil {
+		return errors.Wrapf(err, "error updating PodVolumeRestore %s", key)
+	}
+
+	logContext.WithFields(logrus.Fields{
+		"pod":    pvr.Spec.Pod.Namespace + "/" + pvr.Spec.Pod.Name,
+		"volume": pvr.Spec.Volume,
+	}).Info("Restoring pod volume")
+
+	if err := c.restoreVolume(pvr, pod, logContext); err != nil {
+		logContext.WithError(err).Error("Error restoring pod volume")
+		pvr.Status.Phase = api.PodVolumeRestorePhaseFailed
+		pvr.Status.Message = err.Error()
+	} else {
+		pvr.Status.Phase = api.PodVolumeRestorePhaseCompleted
+	}
+
+	_, err = c.podVolumeRestoreClient.PodVolumeRestores(pvr.Namespace).Update(pvr)
+	return errors.Wrapf(err, "error updating PodVolumeRestore %s", key)
+}
+
+// restoreVolume restores the contents of pvr's volume, then writes the marker file that tells
+// the pod's restore helper that the volume is ready.
+func (c *podVolumeRestoreController) restoreVolume(pvr *api.PodVolumeRestore, pod *corev1api.Pod, log logrus.FieldLogger) error {
+	volumeDir, err := podvolume.GetVolumeDirectory(pod, pvr.Spec.Volume, c.pvcClient)
+	if err != nil {
+		return err
+	}
+
+	dir, err := podvolume.FindVolumePath(c.hostPodsDir, pod.UID, volumeDir)
+	if err != nil {
+		return err
+	}
+
+	location, err := c.storageLocations.Get(pvr.Spec.StorageLocation)
+	if err != nil {
+		return err
+	}
+
+	if err := c.newRepository(location, c.keyProvider, log).Restore(dir, pvr.Spec.BackupName, pvr.Spec.SnapshotID); err != nil {
+		return err
+	}
+
+	markerPath := filepath.Join(dir, podvolume.MarkerPath(pvr.Spec.RestoreUID))
+	if err := os.MkdirAll(filepath.Dir(markerPath), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(ioutil.WriteFile(markerPath, nil, 0644))
+}
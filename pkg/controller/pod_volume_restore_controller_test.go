@@ -0,0 +1,166 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	testlogger "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
+	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
+	"github.com/heptio/ark/pkg/podvolume"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestProcessPodVolumeRestore(t *testing.T) {
+	tests := []struct {
+		name             string
+		podNode          string
+		helperRunning    bool
+		phase            api.PodVolumeRestorePhase
+		repositoryErr    error
+		expectedPhase    api.PodVolumeRestorePhase
+		expectedMessage  string
+		expectedRequeue  bool
+		expectedRestored bool
+	}{
+		{
+			name:             "restore for pod running its restore helper on this node is restored",
+			podNode:          "node-1",
+			helperRunning:    true,
+			expectedPhase:    api.PodVolumeRestorePhaseCompleted,
+			expectedRestored: true,
+		},
+		{
+			name:            "restore for unscheduled pod is requeued",
+			expectedRequeue: true,
+		},
+		{
+			name:            "restore for pod whose restore helper isn't running yet is requeued",
+			podNode:         "node-1",
+			expectedRequeue: true,
+		},
+		{
+			name:          "restore for pod on another node is ignored",
+			podNode:       "node-2",
+			helperRunning: true,
+		},
+		{
+			name:          "restore that's already completed is ignored",
+			podNode:       "node-1",
+			helperRunning: true,
+			phase:         api.PodVolumeRestorePhaseCompleted,
+		},
+		{
+			name:            "repository error fails the restore",
+			podNode:         "node-1",
+			helperRunning:   true,
+			repositoryErr:   errors.New("snapshot not found"),
+			expectedPhase:   api.PodVolumeRestorePhaseFailed,
+			expectedMessage: "snapshot not found",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hostPodsDir, err := ioutil.TempDir("", "host-pods")
+			require.NoError(t, err)
+			defer os.RemoveAll(hostPodsDir)
+
+			pod, volumeDir := newTestVolumePod(t, hostPodsDir, test.podNode)
+			if test.helperRunning {
+				pod.Status.InitContainerStatuses = []corev1api.ContainerStatus{
+					{Name: "ark-restore-helper", State: corev1api.ContainerState{Running: &corev1api.ContainerStateRunning{}}},
+				}
+			}
+
+			pvr := &api.PodVolumeRestore{
+				ObjectMeta: metav1.ObjectMeta{Namespace: api.DefaultNamespace, Name: "pvr-1"},
+				Spec: api.PodVolumeRestoreSpec{
+					Pod:        corev1api.ObjectReference{Namespace: "ns-1", Name: "pod-1", UID: pod.UID},
+					Volume:     "data",
+					SnapshotID: "snapshot-1",
+					BackupName: "backup-1",
+					RestoreUID: "restore-uid",
+				},
+				Status: api.PodVolumeRestoreStatus{Phase: test.phase},
+			}
+
+			var (
+				client          = fake.NewSimpleClientset(pvr)
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+				repository      = &fakePodVolumeRepository{err: test.repositoryErr}
+				logger, _       = testlogger.NewNullLogger()
+			)
+
+			sharedInformers.Ark().V1().PodVolumeRestores().Informer().GetStore().Add(pvr)
+
+			c := NewPodVolumeRestoreController(
+				client.ArkV1(),
+				sharedInformers.Ark().V1().PodVolumeRestores(),
+				&fakePodGetter{pods: map[string]*corev1api.Pod{"pod-1": pod}},
+				nil,
+				newTestStorageLocations(&arktest.BackupService{}, "bucket", sharedInformers, nil),
+				nil,
+				"node-1",
+				hostPodsDir,
+				logger,
+			).(*podVolumeRestoreController)
+			c.newRepository = repository.newRepository
+			c.requeueDelay = 0
+			defer c.queue.ShutDown()
+
+			require.NoError(t, c.processPodVolumeRestore("heptio-ark/pvr-1"))
+
+			updated, err := client.ArkV1().PodVolumeRestores(api.DefaultNamespace).Get("pvr-1", metav1.GetOptions{})
+			require.NoError(t, err)
+
+			if test.expectedPhase == "" {
+				assert.Equal(t, test.phase, updated.Status.Phase)
+				assert.Empty(t, repository.restored)
+			} else {
+				assert.Equal(t, test.expectedPhase, updated.Status.Phase)
+				assert.Equal(t, test.expectedMessage, updated.Status.Message)
+			}
+
+			if test.expectedRequeue {
+				assert.Equal(t, 1, c.queue.Len())
+			} else {
+				assert.Equal(t, 0, c.queue.Len())
+			}
+
+			_, err = os.Stat(filepath.Join(volumeDir, podvolume.MarkerPath("restore-uid")))
+			if test.expectedRestored {
+				assert.Equal(t, []string{volumeDir}, repository.restored)
+				assert.NoError(t, err, "marker file should have been written")
+			} else {
+				assert.True(t, os.IsNotExist(err), "marker file should not have been written")
+			}
+		})
+	}
+}
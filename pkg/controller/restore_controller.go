@@ -17,6 +17,7 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
 	"encoding/json"
@@ -38,9 +39,12 @@ import (
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/encryption"
 	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions/ark/v1"
 	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+	"github.com/heptio/ark/pkg/metrics"
+	"github.com/heptio/ark/pkg/plugin"
 	"github.com/heptio/ark/pkg/restore"
 	"github.com/heptio/ark/pkg/util/collections"
 	kubeutil "github.com/heptio/ark/pkg/util/kube"
@@ -54,8 +58,8 @@ type restoreController struct {
 	restoreClient       arkv1client.RestoresGetter
 	backupClient        arkv1client.BackupsGetter
 	restorer            restore.Restorer
-	backupService       cloudprovider.BackupService
-	bucket              string
+	storageLocations    cloudprovider.StorageLocationResolver
+	keyProvider         encryption.KeyProvider
 	pvProviderExists    bool
 	backupLister        listers.BackupLister
 	backupListerSynced  cache.InformerSynced
@@ -64,6 +68,8 @@ type restoreController struct {
 	syncHandler         func(restoreName string) error
 	queue               workqueue.RateLimitingInterface
 	logger              *logrus.Logger
+	pluginManager       plugin.Manager
+	metrics             *metrics.ServerMetrics
 }
 
 func NewRestoreController(
@@ -71,18 +77,20 @@ func NewRestoreController(
 	restoreClient arkv1client.RestoresGetter,
 	backupClient arkv1client.BackupsGetter,
 	restorer restore.Restorer,
-	backupService cloudprovider.BackupService,
-	bucket string,
+	storageLocations cloudprovider.StorageLocationResolver,
+	keyProvider encryption.KeyProvider,
 	backupInformer informers.BackupInformer,
 	pvProviderExists bool,
 	logger *logrus.Logger,
+	pluginManager plugin.Manager,
+	metrics *metrics.ServerMetrics,
 ) Interface {
 	c := &restoreController{
 		restoreClient:       restoreClient,
 		backupClient:        backupClient,
 		restorer:            restorer,
-		backupService:       backupService,
-		bucket:              bucket,
+		storageLocations:    storageLocations,
+		keyProvider:         keyProvider,
 		pvProviderExists:    pvProviderExists,
 		backupLister:        backupInformer.Lister(),
 		backupListerSynced:  backupInformer.Informer().HasSynced,
@@ -90,6 +98,8 @@ func NewRestoreController(
 		restoreListerSynced: restoreInformer.Informer().HasSynced,
 		queue:               workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "restore"),
 		logger:              logger,
+		pluginManager:       pluginManager,
+		metrics:             metrics,
 	}
 
 	c.syncHandler = c.processRestore
@@ -250,13 +260,16 @@ func (controller *restoreController) processRestore(key string) error {
 	}
 	restore = updatedRestore
 
+	controller.metrics.RegisterRestoreAttempt()
+
 	if restore.Status.Phase == api.RestorePhaseFailedValidation {
+		controller.metrics.RegisterRestoreValidationFailed()
 		return nil
 	}
 
 	logContext.Debug("Running restore")
 	// execution & upload of restore
-	restoreWarnings, restoreErrors := controller.runRestore(restore, controller.bucket)
+	restoreWarnings, restoreErrors := controller.runRestore(restore)
 
 	restore.Status.Warnings = len(restoreWarnings.Ark) + len(restoreWarnings.Cluster)
 	for _, w := range restoreWarnings.Namespaces {
@@ -271,6 +284,12 @@ func (controller *restoreController) processRestore(key string) error {
 	logContext.Debug("restore completed")
 	restore.Status.Phase = api.RestorePhaseCompleted
 
+	if restore.Status.Errors > 0 {
+		controller.metrics.RegisterRestoreFailed()
+	} else {
+		controller.metrics.RegisterRestoreSuccess()
+	}
+
 	logContext.Debug("Updating Restore final status")
 	if _, err = controller.restoreClient.Restores(ns).Update(restore); err != nil {
 		logContext.WithError(errors.WithStack(err)).Info("Error updating Restore final status")
@@ -305,10 +324,16 @@ func (controller *restoreController) getValidationErrors(itm *api.Restore) []str
 		validationErrors = append(validationErrors, "Server is not configured for PV snapshot restores")
 	}
 
+	switch itm.Spec.ExistingResourcePolicy {
+	case "", api.ExistingResourcePolicyNone, api.ExistingResourcePolicyUpdate, api.ExistingResourcePolicyRecreate:
+	default:
+		validationErrors = append(validationErrors, fmt.Sprintf("Invalid existing resource policy %q", itm.Spec.ExistingResourcePolicy))
+	}
+
 	return validationErrors
 }
 
-func (controller *restoreController) fetchBackup(bucket, name string) (*api.Backup, error) {
+func (controller *restoreController) fetchBackup(name string) (*api.Backup, error) {
 	backup, err := controller.backupLister.Backups(api.DefaultNamespace).Get(name)
 	if err == nil {
 		return backup, nil
@@ -321,7 +346,7 @@ func (controller *restoreController) fetchBackup(bucket, name string) (*api.Back
 	logContext := controller.logger.WithField("backupName", name)
 
 	logContext.Debug("Backup not found in backupLister, checking object storage directly")
-	backup, err = controller.backupService.GetBackup(bucket, name)
+	backup, err = getBackupFromStorage(controller.storageLocations, name, logContext)
 	if err != nil {
 		return nil, err
 	}
@@ -339,45 +364,46 @@ func (controller *restoreController) fetchBackup(bucket, name string) (*api.Back
 	return backup, nil
 }
 
-func (controller *restoreController) runRestore(restore *api.Restore, bucket string) (restoreWarnings, restoreErrors api.RestoreResult) {
+func (controller *restoreController) runRestore(restore *api.Restore) (restoreWarnings, restoreErrors api.RestoreResult) {
 	logContext := controller.logger.WithFields(
 		logrus.Fields{
 			"restore": kubeutil.NamespaceAndName(restore),
 			"backup":  restore.Spec.BackupName,
 		})
 
-	backup, err := controller.fetchBackup(bucket, restore.Spec.BackupName)
+	backup, err := controller.fetchBackup(restore.Spec.BackupName)
 	if err != nil {
 		logContext.WithError(err).Error("Error getting backup")
 		restoreErrors.Ark = append(restoreErrors.Ark, err.Error())
 		return
 	}
 
-	var tempFiles []*os.File
-
-	backupFile, err := downloadToTempFile(restore.Spec.BackupName, controller.backupService, bucket, controller.logger)
+	location, err := controller.storageLocations.Get(backup.Spec.StorageLocation)
 	if err != nil {
-		logContext.WithError(err).Error("Error downloading backup")
+		logContext.WithError(err).Error("Error getting backup's storage location")
 		restoreErrors.Ark = append(restoreErrors.Ark, err.Error())
 		return
 	}
-	tempFiles = append(tempFiles, backupFile)
 
-	logFile, err := ioutil.TempFile("", "")
+	// the backup's tarball is streamed from object storage into the restorer, rather than
+	// being downloaded to a local file first
+	backupReader, err := openBackup(backup, location.BackupService, location.Bucket, controller.keyProvider)
 	if err != nil {
-		logContext.WithError(errors.WithStack(err)).Error("Error creating log temp file")
+		logContext.WithError(err).Error("Error downloading backup")
 		restoreErrors.Ark = append(restoreErrors.Ark, err.Error())
 		return
 	}
-	tempFiles = append(tempFiles, logFile)
+	defer backupReader.Close()
 
-	resultsFile, err := ioutil.TempFile("", "")
+	var tempFiles []*os.File
+
+	logFile, err := ioutil.TempFile("", "")
 	if err != nil {
-		logContext.WithError(errors.WithStack(err)).Error("Error creating results temp file")
+		logContext.WithError(errors.WithStack(err)).Error("Error creating log temp file")
 		restoreErrors.Ark = append(restoreErrors.Ark, err.Error())
 		return
 	}
-	tempFiles = append(tempFiles, resultsFile)
+	tempFiles = append(tempFiles, logFile)
 
 	defer func() {
 		for _, file := range tempFiles {
@@ -391,10 +417,29 @@ func (controller *restoreController) runRestore(restore *api.Restore, bucket str
 		}
 	}()
 
+	actions, err := controller.pluginManager.GetRestoreItemActions(restore.Name, controller.logger, controller.logger.Level)
+	if err != nil {
+		restoreErrors.Ark = append(restoreErrors.Ark, err.Error())
+		return
+	}
+	defer controller.pluginManager.CloseRestoreItemActions(restore.Name)
+
+	var logWriter io.WriteCloser = nopWriteCloser{logFile}
+	if controller.keyProvider != nil {
+		if logWriter, err = encryption.NewEncryptingWriter(logFile, controller.keyProvider); err != nil {
+			restoreErrors.Ark = append(restoreErrors.Ark, fmt.Sprintf("error encrypting log file: %v", err))
+			return
+		}
+	}
+
 	logContext.Info("starting restore")
-	restoreWarnings, restoreErrors = controller.restorer.Restore(restore, backup, backupFile, logFile)
+	restoreWarnings, restoreErrors, dryRunReport := controller.restorer.Restore(restore, backup, backupReader, logWriter, actions)
 	logContext.Info("restore completed")
 
+	if err := logWriter.Close(); err != nil {
+		restoreErrors.Ark = append(restoreErrors.Ark, fmt.Sprintf("error encrypting log file: %v", err))
+	}
+
 	// Try to upload the log file. This is best-effort. If we fail, we'll add to the ark errors.
 
 	// Reset the offset to 0 for reading
@@ -403,61 +448,94 @@ func (controller *restoreController) runRestore(restore *api.Restore, bucket str
 		return
 	}
 
-	if err := controller.backupService.UploadRestoreLog(bucket, restore.Spec.BackupName, restore.Name, logFile); err != nil {
+	if err := location.BackupService.UploadRestoreLog(location.Bucket, restore.Spec.BackupName, restore.Name, logFile); err != nil {
 		restoreErrors.Ark = append(restoreErrors.Ark, fmt.Sprintf("error uploading log file to object storage: %v", err))
 	}
 
+	if dryRunReport != nil {
+		if err := controller.uploadDryRunReport(location, restore, dryRunReport); err != nil {
+			restoreErrors.Ark = append(restoreErrors.Ark, fmt.Sprintf("error uploading dry-run report to object storage: %v", err))
+		}
+	}
+
 	m := map[string]api.RestoreResult{
 		"warnings": restoreWarnings,
 		"errors":   restoreErrors,
 	}
 
-	gzippedResultsFile := gzip.NewWriter(resultsFile)
+	err = encodeAndUpload(controller.keyProvider, m, func(r io.Reader) error {
+		return location.BackupService.UploadRestoreResults(location.Bucket, restore.Spec.BackupName, restore.Name, r)
+	})
+	if err != nil {
+		logContext.WithError(err).Error("Error uploading results files to object storage")
+	}
 
-	if err := json.NewEncoder(gzippedResultsFile).Encode(m); err != nil {
-		logContext.WithError(errors.WithStack(err)).Error("Error encoding restore results")
-		return
+	return
+}
+
+// uploadDryRunReport uploads a gzipped JSON file containing a dry-run restore's report, encrypted
+// if encryption is configured.
+func (controller *restoreController) uploadDryRunReport(location *cloudprovider.StorageLocation, restore *api.Restore, report *api.RestoreDryRunReport) error {
+	return encodeAndUpload(controller.keyProvider, report, func(r io.Reader) error {
+		return location.BackupService.UploadRestoreDryRunReport(location.Bucket, restore.Spec.BackupName, restore.Name, r)
+	})
+}
+
+// encodeAndUpload gzips the JSON encoding of v, encrypts it if keyProvider is non-nil, and passes
+// the result to upload.
+func encodeAndUpload(keyProvider encryption.KeyProvider, v interface{}, upload func(io.Reader) error) error {
+	buf := new(bytes.Buffer)
+
+	var w io.WriteCloser = nopWriteCloser{buf}
+	if keyProvider != nil {
+		var err error
+		if w, err = encryption.NewEncryptingWriter(buf, keyProvider); err != nil {
+			return errors.Wrap(err, "error encrypting")
+		}
 	}
-	gzippedResultsFile.Close()
 
-	if _, err = resultsFile.Seek(0, 0); err != nil {
-		logContext.WithError(errors.WithStack(err)).Error("Error resetting results file offset to 0")
-		return
+	gzw := gzip.NewWriter(w)
+	if err := json.NewEncoder(gzw).Encode(v); err != nil {
+		return errors.Wrap(err, "error encoding")
+	}
+	if err := gzw.Close(); err != nil {
+		return errors.WithStack(err)
 	}
-	if err := controller.backupService.UploadRestoreResults(bucket, restore.Spec.BackupName, restore.Name, resultsFile); err != nil {
-		logContext.WithError(errors.WithStack(err)).Error("Error uploading results files to object storage")
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "error encrypting")
 	}
 
-	return
+	return upload(buf)
 }
 
-func downloadToTempFile(backupName string, backupService cloudprovider.BackupService, bucket string, logger *logrus.Logger) (*os.File, error) {
-	readCloser, err := backupService.DownloadBackup(bucket, backupName)
+// openBackup opens a stream of the backup's tarball from object storage, decrypting it with
+// keyProvider. The tarball must be encrypted if the backup recorded an encryption key.
+func openBackup(backup *api.Backup, backupService cloudprovider.BackupService, bucket string, keyProvider encryption.KeyProvider) (io.ReadCloser, error) {
+	readCloser, err := backupService.DownloadBackup(bucket, backup.Name)
 	if err != nil {
 		return nil, err
 	}
-	defer readCloser.Close()
 
-	file, err := ioutil.TempFile("", backupName)
+	reader, err := encryption.NewDecryptingReaderForKeyID(readCloser, keyProvider, backup.Status.EncryptionKeyID)
 	if err != nil {
-		return nil, errors.Wrap(err, "error creating Backup temp file")
+		readCloser.Close()
+		return nil, errors.Wrap(err, "error decrypting Backup")
 	}
 
-	n, err := io.Copy(file, readCloser)
-	if err != nil {
-		return nil, errors.Wrap(err, "error copying Backup to temp file")
-	}
-
-	logContext := logger.WithField("backup", backupName)
-
-	logContext.WithFields(logrus.Fields{
-		"fileName": file.Name(),
-		"bytes":    n,
-	}).Debug("Copied Backup to file")
+	return &readCloserWrapper{Reader: reader, Closer: readCloser}, nil
+}
 
-	if _, err := file.Seek(0, 0); err != nil {
-		return nil, errors.Wrap(err, "error resetting Backup file offset")
-	}
+// readCloserWrapper is an io.ReadCloser that reads from a wrapper of the underlying
+// io.ReadCloser.
+type readCloserWrapper struct {
+	io.Reader
+	io.Closer
+}
 
-	return file, nil
+// nopWriteCloser is an io.WriteCloser whose Close does nothing, for writing directly to files
+// that are closed separately.
+type nopWriteCloser struct {
+	io.Writer
 }
+
+func (nopWriteCloser) Close() error { return nil }
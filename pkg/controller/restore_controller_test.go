@@ -18,6 +18,8 @@ package controller
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"errors"
 	"io"
 	"io/ioutil"
@@ -26,6 +28,7 @@ import (
 	testlogger "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -33,8 +36,11 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/encryption"
 	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
+	"github.com/heptio/ark/pkg/metrics"
+	"github.com/heptio/ark/pkg/restore"
 	. "github.com/heptio/ark/pkg/util/test"
 )
 
@@ -58,7 +64,7 @@ func TestFetchBackup(t *testing.T) {
 			name:                "backupSvc has backup",
 			backupName:          "backup-1",
 			backupServiceBackup: NewTestBackup().WithName("backup-1").Backup,
-			expectedRes:         NewTestBackup().WithName("backup-1").Backup,
+			expectedRes:         NewTestBackup().WithName("backup-1").WithStorageLocation(api.DefaultBackupStorageLocation).Backup,
 		},
 		{
 			name:               "no backup",
@@ -83,11 +89,13 @@ func TestFetchBackup(t *testing.T) {
 				client.ArkV1(),
 				client.ArkV1(),
 				restorer,
-				backupSvc,
-				"bucket",
+				newTestStorageLocations(backupSvc, "bucket", sharedInformers, nil),
+				nil,
 				sharedInformers.Ark().V1().Backups(),
 				false,
 				logger,
+				nil,
+				metrics.NewServerMetrics(),
 			).(*restoreController)
 
 			for _, itm := range test.informerBackups {
@@ -98,7 +106,7 @@ func TestFetchBackup(t *testing.T) {
 				backupSvc.On("GetBackup", "bucket", test.backupName).Return(test.backupServiceBackup, test.backupServiceError)
 			}
 
-			backup, err := c.fetchBackup("bucket", test.backupName)
+			backup, err := c.fetchBackup(test.backupName)
 
 			if assert.Equal(t, test.expectedErr, err != nil) {
 				assert.Equal(t, test.expectedRes, backup)
@@ -218,6 +226,17 @@ func TestProcessRestore(t *testing.T) {
 			},
 			expectedRestorerCall: NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseInProgress).Restore,
 		},
+		{
+			name:        "valid dry-run restore gets executed and uploads its report",
+			restore:     NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).WithDryRun(true).Restore,
+			backup:      NewTestBackup().WithName("backup-1").Backup,
+			expectedErr: false,
+			expectedRestoreUpdates: []*api.Restore{
+				NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseInProgress).WithDryRun(true).Restore,
+				NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseCompleted).WithDryRun(true).Restore,
+			},
+			expectedRestorerCall: NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseInProgress).WithDryRun(true).Restore,
+		},
 		{
 			name:                  "valid restore with RestorePVs=true gets executed when allowRestoreSnapshots=true",
 			restore:               NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).WithRestorePVs(true).Restore,
@@ -242,6 +261,18 @@ func TestProcessRestore(t *testing.T) {
 					Restore,
 			},
 		},
+		{
+			name:        "restore with invalid existing resource policy fails validation",
+			restore:     NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).WithExistingResourcePolicy("overwrite").Restore,
+			backup:      NewTestBackup().WithName("backup-1").Backup,
+			expectedErr: false,
+			expectedRestoreUpdates: []*api.Restore{
+				NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseFailedValidation).
+					WithExistingResourcePolicy("overwrite").
+					WithValidationError(`Invalid existing resource policy "overwrite"`).
+					Restore,
+			},
+		},
 		{
 			name:        "restoration of nodes is not supported",
 			restore:     NewRestore("foo", "bar", "backup-1", "ns-1", "nodes", api.RestorePhaseNew).Restore,
@@ -264,6 +295,7 @@ func TestProcessRestore(t *testing.T) {
 				sharedInformers = informers.NewSharedInformerFactory(client, 0)
 				backupSvc       = &BackupService{}
 				logger, _       = testlogger.NewNullLogger()
+				pluginManager   = &Manager{}
 			)
 
 			defer restorer.AssertExpectations(t)
@@ -274,11 +306,13 @@ func TestProcessRestore(t *testing.T) {
 				client.ArkV1(),
 				client.ArkV1(),
 				restorer,
-				backupSvc,
-				"bucket",
+				newTestStorageLocations(backupSvc, "bucket", sharedInformers, nil),
+				nil,
 				sharedInformers.Ark().V1().Backups(),
 				test.allowRestoreSnapshots,
 				logger,
+				pluginManager,
+				metrics.NewServerMetrics(),
 			).(*restoreController)
 
 			if test.restore != nil {
@@ -311,7 +345,15 @@ func TestProcessRestore(t *testing.T) {
 			if test.expectedRestorerCall != nil {
 				downloadedBackup := ioutil.NopCloser(bytes.NewReader([]byte("hello world")))
 				backupSvc.On("DownloadBackup", mock.Anything, mock.Anything).Return(downloadedBackup, nil)
-				restorer.On("Restore", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(warnings, errors)
+				var dryRunReport *api.RestoreDryRunReport
+				if test.restore.Spec.DryRun {
+					dryRunReport = &api.RestoreDryRunReport{}
+					backupSvc.On("UploadRestoreDryRunReport", "bucket", test.restore.Spec.BackupName, test.restore.Name, mock.Anything).Return(nil)
+				}
+				restorer.On("Restore", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(warnings, errors, dryRunReport)
+
+				pluginManager.On("GetRestoreItemActions", test.restore.Name, logger, logger.Level).Return(nil, nil)
+				pluginManager.On("CloseRestoreItemActions", test.restore.Name).Return(nil)
 				backupSvc.On("UploadRestoreLog", "bucket", test.restore.Spec.BackupName, test.restore.Name, mock.Anything).Return(test.uploadLogError)
 				backupSvc.On("UploadRestoreResults", "bucket", test.restore.Spec.BackupName, test.restore.Name, mock.Anything).Return(nil)
 			}
@@ -367,6 +409,95 @@ func TestProcessRestore(t *testing.T) {
 	}
 }
 
+func TestOpenBackup(t *testing.T) {
+	keyProvider, err := encryption.NewLocalKeyProviderForKey(bytes.Repeat([]byte("k"), 32))
+	require.NoError(t, err)
+
+	var encrypted bytes.Buffer
+	w, err := encryption.NewEncryptingWriter(&encrypted, keyProvider)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("tarball"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	tests := []struct {
+		name          string
+		keyID         string
+		stored        []byte
+		expectedError string
+	}{
+		{
+			name:   "encrypted backup is decrypted",
+			keyID:  keyProvider.KeyID(),
+			stored: encrypted.Bytes(),
+		},
+		{
+			name:          "plaintext tarball for an encrypted backup is rejected",
+			keyID:         keyProvider.KeyID(),
+			stored:        []byte("tarball"),
+			expectedError: "error decrypting Backup: file is not encrypted",
+		},
+		{
+			name:   "backup without a key ID may be plaintext",
+			stored: []byte("tarball"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backupSvc := &BackupService{}
+			backupSvc.On("DownloadBackup", "bucket", "backup-1").Return(ioutil.NopCloser(bytes.NewReader(test.stored)), nil)
+
+			backup := NewTestBackup().WithName("backup-1").Backup
+			backup.Status.EncryptionKeyID = test.keyID
+
+			rc, err := openBackup(backup, backupSvc, "bucket", keyProvider)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			defer rc.Close()
+
+			res, err := ioutil.ReadAll(rc)
+			require.NoError(t, err)
+			assert.Equal(t, "tarball", string(res))
+		})
+	}
+}
+
+func TestEncodeAndUpload(t *testing.T) {
+	keyProvider, err := encryption.NewLocalKeyProviderForKey(bytes.Repeat([]byte("k"), 32))
+	require.NoError(t, err)
+
+	for _, keyProvider := range []encryption.KeyProvider{nil, keyProvider} {
+		var uploaded []byte
+		err := encodeAndUpload(keyProvider, map[string]string{"key": "value"}, func(r io.Reader) error {
+			var err error
+			uploaded, err = ioutil.ReadAll(r)
+			return err
+		})
+		require.NoError(t, err)
+
+		encrypted, _, err := encryption.IsEncrypted(bytes.NewReader(uploaded))
+		require.NoError(t, err)
+		assert.Equal(t, keyProvider != nil, encrypted)
+
+		decrypted, err := encryption.NewDecryptingReaderIfEncrypted(bytes.NewReader(uploaded), keyProvider)
+		require.NoError(t, err)
+		gzr, err := gzip.NewReader(decrypted)
+		require.NoError(t, err)
+
+		var res map[string]string
+		require.NoError(t, json.NewDecoder(gzr).Decode(&res))
+		assert.Equal(t, map[string]string{"key": "value"}, res)
+	}
+
+	// upload errors are returned as they are
+	err = encodeAndUpload(nil, "value", func(io.Reader) error { return errors.New("upload failed") })
+	assert.EqualError(t, err, "upload failed")
+}
+
 func NewRestore(ns, name, backup, includeNS, includeResource string, phase api.RestorePhase) *TestRestore {
 	restore := NewTestRestore(ns, name, phase).WithBackup(backup)
 
@@ -390,10 +521,10 @@ type fakeRestorer struct {
 	calledWithArg api.Restore
 }
 
-func (r *fakeRestorer) Restore(restore *api.Restore, backup *api.Backup, backupReader io.Reader, logger io.Writer) (api.RestoreResult, api.RestoreResult) {
-	res := r.Called(restore, backup, backupReader, logger)
+func (r *fakeRestorer) Restore(restore *api.Restore, backup *api.Backup, backupReader io.Reader, logger io.Writer, actions []restore.ItemAction) (api.RestoreResult, api.RestoreResult, *api.RestoreDryRunReport) {
+	res := r.Called(restore, backup, backupReader, logger, actions)
 
 	r.calledWithArg = *restore
 
-	return res.Get(0).(api.RestoreResult), res.Get(1).(api.RestoreResult)
+	return res.Get(0).(api.RestoreResult), res.Get(1).(api.RestoreResult), res.Get(2).(*api.RestoreDryRunReport)
 }
@@ -19,6 +19,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/robfig/cron"
 	"github.com/sirupsen/logrus"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -38,6 +40,8 @@ import (
 	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions/ark/v1"
 	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+	"github.com/heptio/ark/pkg/metrics"
+	arkschedule "github.com/heptio/ark/pkg/schedule"
 	kubeutil "github.com/heptio/ark/pkg/util/kube"
 )
 
@@ -46,19 +50,24 @@ type scheduleController struct {
 	backupsClient         arkv1client.BackupsGetter
 	schedulesLister       listers.ScheduleLister
 	schedulesListerSynced cache.InformerSynced
+	backupsLister         listers.BackupLister
+	backupsListerSynced   cache.InformerSynced
 	syncHandler           func(scheduleName string) error
 	queue                 workqueue.RateLimitingInterface
 	syncPeriod            time.Duration
 	clock                 clock.Clock
 	logger                *logrus.Logger
+	metrics               *metrics.ServerMetrics
 }
 
 func NewScheduleController(
 	schedulesClient arkv1client.SchedulesGetter,
 	backupsClient arkv1client.BackupsGetter,
 	schedulesInformer informers.ScheduleInformer,
+	backupsInformer informers.BackupInformer,
 	syncPeriod time.Duration,
 	logger *logrus.Logger,
+	metrics *metrics.ServerMetrics,
 ) *scheduleController {
 	if syncPeriod < time.Minute {
 		logger.WithField("syncPeriod", syncPeriod).Info("Provided schedule sync period is too short. Setting to 1 minute")
@@ -70,10 +79,13 @@ func NewScheduleController(
 		backupsClient:         backupsClient,
 		schedulesLister:       schedulesInformer.Lister(),
 		schedulesListerSynced: schedulesInformer.Informer().HasSynced,
+		backupsLister:         backupsInformer.Lister(),
+		backupsListerSynced:   backupsInformer.Informer().HasSynced,
 		queue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "schedule"),
 		syncPeriod: syncPeriod,
 		clock:      clock.RealClock{},
 		logger:     logger,
+		metrics:    metrics,
 	}
 
 	c.syncHandler = c.processSchedule
@@ -83,6 +95,9 @@ func NewScheduleController(
 			AddFunc: func(obj interface{}) {
 				schedule := obj.(*api.Schedule)
 
+				// initialize the schedule's metrics so they're exported before its first backup
+				c.metrics.InitSchedule(schedule.Name)
+
 				switch schedule.Status.Phase {
 				case "", api.SchedulePhaseNew, api.SchedulePhaseEnabled:
 					// add to work queue
@@ -94,6 +109,21 @@ func NewScheduleController(
 					return
 				}
 
+				key, err := cache.MetaNamespaceKeyFunc(schedule)
+				if err != nil {
+					c.logger.WithError(errors.WithStack(err)).WithField("schedule", schedule).Error("Error creating queue key, item not added to queue")
+					return
+				}
+				c.queue.Add(key)
+			},
+			UpdateFunc: func(_, obj interface{}) {
+				schedule := obj.(*api.Schedule)
+
+				// triggered schedules are processed right away rather than at the next sync
+				if _, triggered := schedule.Annotations[api.ScheduleTriggerAnnotation]; !triggered || schedule.Status.Phase != api.SchedulePhaseEnabled {
+					return
+				}
+
 				key, err := cache.MetaNamespaceKeyFunc(schedule)
 				if err != nil {
 					c.logger.WithError(errors.WithStack(err)).WithField("schedule", schedule).Error("Error creating queue key, item not added to queue")
@@ -130,7 +160,7 @@ func (controller *scheduleController) Run(ctx context.Context, numWorkers int) e
 	defer controller.logger.Info("Shutting down ScheduleController")
 
 	controller.logger.Info("Waiting for caches to sync")
-	if !cache.WaitForCacheSync(ctx.Done(), controller.schedulesListerSynced) {
+	if !cache.WaitForCacheSync(ctx.Done(), controller.schedulesListerSynced, controller.backupsListerSynced) {
 		return errors.New("timed out waiting for caches to sync")
 	}
 	controller.logger.Info("Caches are synced")
@@ -237,7 +267,15 @@ func (controller *scheduleController) processSchedule(key string) error {
 	// so re-validate
 	currentPhase := schedule.Status.Phase
 
-	cronSchedule, errs := parseCronSchedule(schedule, controller.logger)
+	location, err := arkschedule.LoadLocation(schedule.Spec.TimeZone)
+	cronSchedule, errs := parseCronSchedule(schedule, location, controller.logger)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("invalid timeZone: %v", errors.Cause(err)))
+	}
+	blackoutWindows, blackoutErrs := arkschedule.ParseBlackoutWindows(schedule.Spec.BlackoutWindows, location)
+	errs = append(errs, blackoutErrs...)
+	errs = append(errs, arkschedule.ValidateRetention(schedule.Spec.Retention)...)
+	errs = append(errs, getRunPolicyValidationErrors(schedule.Spec)...)
 	if len(errs) > 0 {
 		schedule.Status.Phase = api.SchedulePhaseFailedValidation
 		schedule.Status.ValidationErrors = errs
@@ -259,14 +297,16 @@ func (controller *scheduleController) processSchedule(key string) error {
 	}
 
 	// check for the schedule being due to run, and submit a Backup if so
-	if err := controller.submitBackupIfDue(schedule, cronSchedule); err != nil {
+	if err := controller.submitBackupIfDue(schedule, cronSchedule, blackoutWindows); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func parseCronSchedule(itm *api.Schedule, logger *logrus.Logger) (cron.Schedule, []string) {
+// parseCronSchedule parses itm's cron expression, to be evaluated in location, or in the location
+// of the times it's evaluated from if location is nil.
+func parseCronSchedule(itm *api.Schedule, location *time.Location, logger *logrus.Logger) (cron.Schedule, []string) {
 	var validationErrors []string
 	var schedule cron.Schedule
 
@@ -303,65 +343,335 @@ func parseCronSchedule(itm *api.Schedule, logger *logrus.Logger) (cron.Schedule,
 		return nil, validationErrors
 	}
 
-	return schedule, nil
+	return arkschedule.InLocation(schedule, location), nil
 }
 
-func (controller *scheduleController) submitBackupIfDue(item *api.Schedule, cronSchedule cron.Schedule) error {
+// scheduleRunHistoryLimit is the number of a Schedule's most recent runs that are recorded in its
+// status.
+const scheduleRunHistoryLimit = 10
+
+func (controller *scheduleController) submitBackupIfDue(item *api.Schedule, cronSchedule cron.Schedule, blackoutWindows []arkschedule.BlackoutWindow) error {
 	var (
-		now                = controller.clock.Now()
-		isDue, nextRunTime = getNextRunTime(item, cronSchedule, now)
-		logContext         = controller.logger.WithField("schedule", kubeutil.NamespaceAndName(item))
+		now        = controller.clock.Now()
+		logContext = controller.logger.WithField("schedule", kubeutil.NamespaceAndName(item))
+		schedule   = item.DeepCopy()
 	)
 
+	backups, err := controller.backupsLister.Backups(item.Namespace).List(labels.SelectorFromSet(labels.Set{api.ScheduleNameLabel: item.Name}))
+	if err != nil {
+		return errors.Wrap(err, "error listing Schedule's Backups")
+	}
+
+	// the trigger annotation is removed before the triggered run's Backup is submitted, so if
+	// the Schedule's status can't be updated afterwards and it's processed again, the trigger
+	// isn't run twice. If the Backup can't be submitted, the trigger is dropped.
+	_, triggered := schedule.Annotations[api.ScheduleTriggerAnnotation]
+	if triggered {
+		delete(schedule.Annotations, api.ScheduleTriggerAnnotation)
+
+		updated, err := controller.schedulesClient.Schedules(schedule.Namespace).Update(schedule)
+		if err != nil {
+			return errors.Wrap(err, "error removing Schedule's trigger annotation")
+		}
+		item = updated
+		schedule = updated.DeepCopy()
+	}
+
+	submitted, err := controller.runIfDue(schedule, cronSchedule, blackoutWindows, backups, now, logContext)
+	if err != nil {
+		return err
+	}
+
+	// a Backup that was just submitted for a scheduled run satisfies the trigger too
+	if triggered && submitted == nil {
+		if err := controller.runTriggered(schedule, backups, now, logContext); err != nil {
+			return err
+		}
+	}
+
+	updateScheduleStatus(schedule, cronSchedule, blackoutWindows, backups)
+
+	if equality.Semantic.DeepEqual(item.Status, schedule.Status) && equality.Semantic.DeepEqual(item.Annotations, schedule.Annotations) {
+		return nil
+	}
+
+	if _, err := controller.schedulesClient.Schedules(schedule.Namespace).Update(schedule); err != nil {
+		return errors.Wrap(err, "error updating Schedule's status")
+	}
+
+	return nil
+}
+
+// runIfDue submits a Backup for schedule if it's due and its blackout windows, concurrency policy
+// and starting deadline allow it, and records the run in schedule's status. It returns the Backup,
+// or nil if none was submitted.
+func (controller *scheduleController) runIfDue(schedule *api.Schedule, cronSchedule cron.Schedule, blackoutWindows []arkschedule.BlackoutWindow, backups []*api.Backup, now time.Time, logContext logrus.FieldLogger) (*api.Backup, error) {
+	if schedule.Spec.Paused {
+		logContext.Debug("Schedule is paused, skipping")
+		return nil, nil
+	}
+
+	isDue, nextRunTime := getNextRunTime(schedule, cronSchedule, now)
 	if !isDue {
 		logContext.WithField("nextRunTime", nextRunTime).Info("Schedule is not due, skipping")
-		return nil
+		return nil, nil
 	}
 
-	// Don't attempt to "catch up" if there are any missed or failed runs - simply
-	// trigger a Backup if it's time.
-	//
-	// It might also make sense in the future to explicitly check for currently-running
-	// backups so that we don't overlap runs (for disk snapshots in particular, this can
-	// lead to performance issues).
-	logContext.WithField("nextRunTime", nextRunTime).Info("Schedule is due, submitting Backup")
-	backup := getBackup(item, now)
-	if _, err := controller.backupsClient.Backups(backup.Namespace).Create(backup); err != nil {
-		return errors.Wrap(err, "error creating Backup")
+	// Don't attempt to "catch up" if there are any missed runs - only the most recent
+	// one is run, and the others are recorded in its message.
+	scheduledTime, missed := getMissedRuns(schedule, cronSchedule, now)
+	logContext = logContext.WithField("scheduledTime", scheduledTime)
+
+	run := api.ScheduleRun{ScheduledTime: metav1.NewTime(scheduledTime)}
+	var messages []string
+	if missed > 0 {
+		logContext.WithField("missedRuns", missed).Warn("Schedule missed runs, only running the most recent")
+		messages = append(messages, fmt.Sprintf("%d earlier runs were missed", missed))
+	}
+
+	// a run that's deferred by a blackout window is due when the window ends
+	dueTime := scheduledTime
+	if blackout := arkschedule.GetBlackout(blackoutWindows, scheduledTime); blackout != nil {
+		logContext = logContext.WithField("blackoutWindow", blackout.Window)
+
+		if blackout.Skip {
+			logContext.Info("Schedule is in a blackout window, skipping")
+			run.Result = api.ScheduleRunResultSkipped
+			run.Message = strings.Join(append(messages, fmt.Sprintf("in blackout window %s", blackout.Window)), "; ")
+			recordScheduleRun(schedule, run)
+			return nil, nil
+		}
+
+		if now.Before(blackout.End) {
+			logContext.WithField("blackoutEnd", blackout.End).Info("Schedule is in a blackout window, deferring")
+			return nil, nil
+		}
+
+		messages = append(messages, fmt.Sprintf("deferred by blackout window %s", blackout.Window))
+		dueTime = blackout.End
+	}
+
+	if deadline := schedule.Spec.StartingDeadline; deadline != nil && now.Sub(dueTime) > deadline.Duration {
+		logContext.WithField("startingDeadline", deadline.Duration).Info("Schedule's starting deadline was exceeded, skipping")
+		run.Result = api.ScheduleRunResultMissed
+		run.Message = strings.Join(append(messages, fmt.Sprintf("starting deadline of %v exceeded", deadline.Duration)), "; ")
+		recordScheduleRun(schedule, run)
+		return nil, nil
 	}
 
-	schedule := item.DeepCopy()
+	logContext.WithField("nextRunTime", nextRunTime).Info("Schedule is due")
+	backup, err := controller.submitRun(schedule, run, messages, backups, now, logContext)
+	if err != nil || backup == nil {
+		return nil, err
+	}
 
 	schedule.Status.LastBackup = metav1.NewTime(now)
 
-	if _, err := controller.schedulesClient.Schedules(schedule.Namespace).Update(schedule); err != nil {
-		return errors.Wrapf(err, "error updating Schedule's LastBackup time to %v", schedule.Status.LastBackup)
+	return backup, nil
+}
+
+// runTriggered submits a Backup for schedule because it was triggered, if its concurrency policy
+// allows it, and records the run in schedule's status. Triggered runs aren't subject to the
+// schedule's cron expression, blackout windows or pause, and don't change its last backup time.
+func (controller *scheduleController) runTriggered(schedule *api.Schedule, backups []*api.Backup, now time.Time, logContext logrus.FieldLogger) error {
+	logContext.Info("Schedule was triggered")
+	run := api.ScheduleRun{ScheduledTime: metav1.NewTime(now), Triggered: true}
+	_, err := controller.submitRun(schedule, run, nil, backups, now, logContext)
+	return err
+}
+
+// submitRun applies schedule's concurrency policy to run, submits a Backup for it if the policy
+// allows, and records the run in schedule's status. It returns the Backup, or nil if the run was
+// skipped.
+func (controller *scheduleController) submitRun(schedule *api.Schedule, run api.ScheduleRun, messages []string, backups []*api.Backup, now time.Time, logContext logrus.FieldLogger) (*api.Backup, error) {
+	unfinished := getUnfinishedBackups(backups)
+
+	switch schedule.Spec.ConcurrencyPolicy {
+	case api.ScheduleConcurrencyPolicyForbid:
+		if len(unfinished) > 0 {
+			logContext.WithField("backup", unfinished[0].Name).Info("Schedule's previous backup hasn't finished, skipping")
+			run.Result = api.ScheduleRunResultSkipped
+			run.Message = strings.Join(append(messages, fmt.Sprintf("backup %s hasn't finished", unfinished[0].Name)), "; ")
+			recordScheduleRun(schedule, run)
+			return nil, nil
+		}
+	case api.ScheduleConcurrencyPolicyReplace:
+		for _, backup := range unfinished {
+			// the backup controller has no way to stop a backup that's running, so only
+			// backups that haven't started can be replaced
+			if backup.Status.Phase == api.BackupPhaseInProgress {
+				logContext.WithField("backup", backup.Name).Info("Schedule's previous backup is in progress and can't be replaced")
+				continue
+			}
+
+			// the backup controller may have started the backup since it was cached, so it's
+			// checked again before it's deleted
+			current, err := controller.backupsClient.Backups(backup.Namespace).Get(backup.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "error getting Backup %s", backup.Name)
+			}
+			if current.Status.Phase != "" && current.Status.Phase != api.BackupPhaseNew {
+				logContext.WithField("backup", backup.Name).Info("Schedule's previous backup has started and can't be replaced")
+				continue
+			}
+
+			logContext.WithField("backup", backup.Name).Info("Replacing schedule's previous backup")
+			err = controller.backupsClient.Backups(backup.Namespace).Delete(backup.Name, &metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{UID: &current.UID},
+			})
+			if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+				// the backup was deleted, or replaced by another with the same name
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "error deleting Backup %s", backup.Name)
+			}
+			messages = append(messages, fmt.Sprintf("replaced backup %s", backup.Name))
+		}
 	}
 
-	return nil
+	backup := arkschedule.NewBackup(schedule, now)
+	logContext.WithField("backup", backup.Name).Info("Submitting Backup")
+	if _, err := controller.backupsClient.Backups(backup.Namespace).Create(backup); err != nil {
+		return nil, errors.Wrap(err, "error creating Backup")
+	}
+
+	run.Result = api.ScheduleRunResultSubmitted
+	run.Backup = backup.Name
+	run.BackupPhase = api.BackupPhaseNew
+	run.Message = strings.Join(messages, "; ")
+	recordScheduleRun(schedule, run)
+
+	return backup, nil
+}
+
+// getUnfinishedBackups returns the backups that are new or in progress.
+func getUnfinishedBackups(backups []*api.Backup) []*api.Backup {
+	var unfinished []*api.Backup
+	for _, backup := range backups {
+		switch backup.Status.Phase {
+		case "", api.BackupPhaseNew, api.BackupPhaseInProgress:
+			unfinished = append(unfinished, backup)
+		}
+	}
+	return unfinished
+}
+
+// recordScheduleRun adds run to the start of schedule's history, dropping the oldest runs beyond
+// scheduleRunHistoryLimit.
+func recordScheduleRun(schedule *api.Schedule, run api.ScheduleRun) {
+	history := append([]api.ScheduleRun{run}, schedule.Status.History...)
+	if len(history) > scheduleRunHistoryLimit {
+		history = history[:scheduleRunHistoryLimit]
+	}
+	schedule.Status.History = history
+}
+
+// updateScheduleStatus updates schedule's next run time, taking its blackout windows into account,
+// and the parts of its status that reflect its backups: the phases of the backups in its history,
+// and its last successful and failed backups.
+func updateScheduleStatus(schedule *api.Schedule, cronSchedule cron.Schedule, blackoutWindows []arkschedule.BlackoutWindow, backups []*api.Backup) {
+	if lastRun := getLastRunTime(schedule); schedule.Spec.Paused || lastRun.IsZero() {
+		schedule.Status.NextBackup = metav1.Time{}
+	} else {
+		schedule.Status.NextBackup = metav1.NewTime(arkschedule.NextRunTime(cronSchedule, blackoutWindows, lastRun))
+	}
+
+	phases := make(map[string]api.BackupPhase, len(backups))
+	for _, backup := range backups {
+		phases[backup.Name] = backup.Status.Phase
+
+		var last **api.ScheduleBackupReference
+		switch backup.Status.Phase {
+		case api.BackupPhaseCompleted:
+			last = &schedule.Status.LastSuccessfulBackup
+		case api.BackupPhaseFailed, api.BackupPhaseFailedValidation, api.BackupPhasePartiallyFailed:
+			last = &schedule.Status.LastFailedBackup
+		default:
+			continue
+		}
+
+		// backups that failed validation never started, so they have no completion time
+		timestamp := backup.Status.CompletionTimestamp
+		if timestamp.IsZero() {
+			timestamp = backup.CreationTimestamp
+		}
+
+		// the previous value is kept if it's newer, since the backup may have been deleted
+		if *last == nil || timestamp.After((*last).Timestamp.Time) {
+			*last = &api.ScheduleBackupReference{Name: backup.Name, Timestamp: timestamp}
+		}
+	}
+
+	for i := range schedule.Status.History {
+		run := &schedule.Status.History[i]
+		if phase := phases[run.Backup]; run.Backup != "" && phase != "" {
+			run.BackupPhase = phase
+		}
+	}
+}
+
+// getLastRunTime returns the time of schedule's most recent scheduled run, whether or not a Backup
+// was created for it, or the zero time if it hasn't run yet. Triggered runs are ignored.
+func getLastRunTime(schedule *api.Schedule) time.Time {
+	lastRun := schedule.Status.LastBackup.Time
+	for _, run := range schedule.Status.History {
+		if run.Triggered {
+			continue
+		}
+		if run.ScheduledTime.After(lastRun) {
+			lastRun = run.ScheduledTime.Time
+		}
+		break
+	}
+	return lastRun
+}
+
+// getMissedRuns returns the most recent time that schedule was due as of asOf, and the number of
+// earlier times it was due since its last run.
+func getMissedRuns(schedule *api.Schedule, cronSchedule cron.Schedule, asOf time.Time) (time.Time, int) {
+	lastRun := getLastRunTime(schedule)
+	if lastRun.IsZero() {
+		// a schedule's first run is due as soon as it's created
+		return asOf, 0
+	}
+
+	scheduledTime := cronSchedule.Next(lastRun)
+	missed := 0
+	for next := cronSchedule.Next(scheduledTime); next.Before(asOf); next = cronSchedule.Next(next) {
+		scheduledTime = next
+		missed++
+	}
+
+	return scheduledTime, missed
 }
 
 func getNextRunTime(schedule *api.Schedule, cronSchedule cron.Schedule, asOf time.Time) (bool, time.Time) {
 	// get the latest run time (if the schedule hasn't run yet, this will be the zero value which will trigger
 	// an immediate backup)
-	lastBackupTime := schedule.Status.LastBackup.Time
+	lastRunTime := getLastRunTime(schedule)
 
-	nextRunTime := cronSchedule.Next(lastBackupTime)
+	nextRunTime := cronSchedule.Next(lastRunTime)
 
 	return asOf.After(nextRunTime), nextRunTime
 }
 
-func getBackup(item *api.Schedule, timestamp time.Time) *api.Backup {
-	backup := &api.Backup{
-		Spec: item.Spec.Template,
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: item.Namespace,
-			Name:      fmt.Sprintf("%s-%s", item.Name, timestamp.Format("20060102150405")),
-			Labels: map[string]string{
-				"ark-schedule": item.Name,
-			},
-		},
+// getRunPolicyValidationErrors validates schedule's concurrency policy and starting deadline.
+func getRunPolicyValidationErrors(spec api.ScheduleSpec) []string {
+	var validationErrors []string
+
+	switch spec.ConcurrencyPolicy {
+	case "", api.ScheduleConcurrencyPolicyAllow, api.ScheduleConcurrencyPolicyForbid, api.ScheduleConcurrencyPolicyReplace:
+	default:
+		validationErrors = append(validationErrors, fmt.Sprintf("invalid concurrencyPolicy %q, must be one of Allow, Forbid or Replace", spec.ConcurrencyPolicy))
+	}
+
+	if spec.StartingDeadline != nil && spec.StartingDeadline.Duration < 0 {
+		validationErrors = append(validationErrors, "startingDeadline must not be negative")
 	}
 
-	return backup
+	return validationErrors
 }
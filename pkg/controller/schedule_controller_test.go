@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/clock"
@@ -35,19 +36,26 @@ import (
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
+	"github.com/heptio/ark/pkg/metrics"
+	arkschedule "github.com/heptio/ark/pkg/schedule"
 	. "github.com/heptio/ark/pkg/util/test"
 )
 
 func TestProcessSchedule(t *testing.T) {
 	tests := []struct {
-		name                             string
-		scheduleKey                      string
-		schedule                         *api.Schedule
-		fakeClockTime                    string
-		expectedErr                      bool
-		expectedSchedulePhaseUpdate      *api.Schedule
-		expectedScheduleLastBackupUpdate *api.Schedule
-		expectedBackupCreate             *api.Backup
+		name                         string
+		scheduleKey                  string
+		schedule                     *api.Schedule
+		fakeClockTime                string
+		expectedErr                  bool
+		expectedSchedulePhaseUpdate  *api.Schedule
+		expectedTriggerRemoval       *api.Schedule
+		expectedScheduleStatusUpdate *api.Schedule
+		expectedBackupCreate         *api.Backup
+		backups                      []*api.Backup
+		apiBackups                   []*api.Backup
+		expectedBackupGets           []string
+		expectedBackupDeletes        []string
 	}{
 		{
 			name:        "invalid key returns error",
@@ -85,6 +93,24 @@ func TestProcessSchedule(t *testing.T) {
 			expectedSchedulePhaseUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseFailedValidation).
 				WithValidationError("Schedule must be a non-empty valid Cron expression").Schedule,
 		},
+		{
+			name: "schedule with phase New gets validated and failed if retention is invalid",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseNew).WithCronSchedule("@every 5m").
+				WithRetention(api.ScheduleRetention{KeepDaily: -1}).Schedule,
+			expectedErr: false,
+			expectedSchedulePhaseUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseFailedValidation).
+				WithCronSchedule("@every 5m").WithRetention(api.ScheduleRetention{KeepDaily: -1}).
+				WithValidationError("retention keepDaily must not be negative").Schedule,
+		},
+		{
+			name: "schedule with phase Enabled gets re-validated and failed if retention has no rules",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithRetention(api.ScheduleRetention{}).Schedule,
+			expectedErr: false,
+			expectedSchedulePhaseUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseFailedValidation).
+				WithCronSchedule("@every 5m").WithRetention(api.ScheduleRetention{}).
+				WithValidationError("retention must set at least one of keepLast, keepDaily, keepWeekly or keepMonthly").Schedule,
+		},
 		{
 			name:                        "schedule with phase New gets validated and triggers a backup",
 			schedule:                    NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseNew).WithCronSchedule("@every 5m").Schedule,
@@ -92,8 +118,9 @@ func TestProcessSchedule(t *testing.T) {
 			expectedErr:                 false,
 			expectedSchedulePhaseUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").Schedule,
 			expectedBackupCreate:        NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").Backup,
-			expectedScheduleLastBackupUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).
-				WithCronSchedule("@every 5m").WithLastBackupTime("2017-01-01 12:00:00").Schedule,
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).
+				WithCronSchedule("@every 5m").WithLastBackupTime("2017-01-01 12:00:00").WithNextBackupTime("2017-01-01 12:05:00").
+				WithRun("2017-01-01 12:00:00", api.ScheduleRunResultSubmitted, "name-20170101120000", api.BackupPhaseNew, "").Schedule,
 		},
 		{
 			name:                 "schedule with phase Enabled gets re-validated and triggers a backup if valid",
@@ -101,8 +128,9 @@ func TestProcessSchedule(t *testing.T) {
 			fakeClockTime:        "2017-01-01 12:00:00",
 			expectedErr:          false,
 			expectedBackupCreate: NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").Backup,
-			expectedScheduleLastBackupUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).
-				WithCronSchedule("@every 5m").WithLastBackupTime("2017-01-01 12:00:00").Schedule,
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).
+				WithCronSchedule("@every 5m").WithLastBackupTime("2017-01-01 12:00:00").WithNextBackupTime("2017-01-01 12:05:00").
+				WithRun("2017-01-01 12:00:00", api.ScheduleRunResultSubmitted, "name-20170101120000", api.BackupPhaseNew, "").Schedule,
 		},
 		{
 			name: "schedule that's already run gets LastBackup updated",
@@ -111,8 +139,198 @@ func TestProcessSchedule(t *testing.T) {
 			fakeClockTime:        "2017-01-01 12:00:00",
 			expectedErr:          false,
 			expectedBackupCreate: NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").Backup,
-			expectedScheduleLastBackupUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).
-				WithCronSchedule("@every 5m").WithLastBackupTime("2017-01-01 12:00:00").Schedule,
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).
+				WithCronSchedule("@every 5m").WithLastBackupTime("2017-01-01 12:00:00").WithNextBackupTime("2017-01-01 12:05:00").
+				WithRun("2017-01-01 11:55:00", api.ScheduleRunResultSubmitted, "name-20170101120000", api.BackupPhaseNew, "1788622 earlier runs were missed").Schedule,
+		},
+		{
+			name: "paused schedule that's due doesn't trigger a backup",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).
+				WithCronSchedule("@every 5m").WithLastBackupTime("2000-01-01 00:00:00").WithPaused(true).Schedule,
+			fakeClockTime: "2017-01-01 12:00:00",
+			expectedErr:   false,
+		}, {
+			name: "schedule with an invalid concurrency policy fails validation",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseNew).WithCronSchedule("@every 5m").
+				WithConcurrencyPolicy("Sometimes").Schedule,
+			expectedErr: false,
+			expectedSchedulePhaseUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseFailedValidation).
+				WithCronSchedule("@every 5m").WithConcurrencyPolicy("Sometimes").
+				WithValidationError(`invalid concurrencyPolicy "Sometimes", must be one of Allow, Forbid or Replace`).Schedule,
+		},
+		{
+			name: "schedule with concurrency policy Forbid skips the run while a backup is in progress",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:50:00").WithConcurrencyPolicy(api.ScheduleConcurrencyPolicyForbid).Schedule,
+			fakeClockTime: "2017-01-01 12:00:00",
+			backups: []*api.Backup{
+				NewTestBackup().WithNamespace("ns").WithName("name-20170101115000").WithLabel("ark-schedule", "name").WithPhase(api.BackupPhaseInProgress).Backup,
+			},
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:50:00").WithConcurrencyPolicy(api.ScheduleConcurrencyPolicyForbid).WithNextBackupTime("2017-01-01 12:00:00").
+				WithRun("2017-01-01 11:55:00", api.ScheduleRunResultSkipped, "", "", "backup name-20170101115000 hasn't finished").Schedule,
+		},
+		{
+			name: "schedule with concurrency policy Replace deletes backups that haven't started",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:50:00").WithConcurrencyPolicy(api.ScheduleConcurrencyPolicyReplace).Schedule,
+			fakeClockTime: "2017-01-01 12:00:00",
+			backups: []*api.Backup{
+				NewTestBackup().WithNamespace("ns").WithName("name-a").WithLabel("ark-schedule", "name").WithPhase(api.BackupPhaseNew).Backup,
+				NewTestBackup().WithNamespace("ns").WithName("name-b").WithLabel("ark-schedule", "name").WithPhase(api.BackupPhaseInProgress).Backup,
+			},
+			expectedBackupGets:    []string{"name-a"},
+			expectedBackupDeletes: []string{"name-a"},
+			expectedBackupCreate:  NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").Backup,
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 12:00:00").WithConcurrencyPolicy(api.ScheduleConcurrencyPolicyReplace).WithNextBackupTime("2017-01-01 12:05:00").
+				WithRun("2017-01-01 11:55:00", api.ScheduleRunResultSubmitted, "name-20170101120000", api.BackupPhaseNew, "replaced backup name-a").Schedule,
+		},
+		{
+			name: "schedule with concurrency policy Replace doesn't delete backups that started after they were cached",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:50:00").WithConcurrencyPolicy(api.ScheduleConcurrencyPolicyReplace).Schedule,
+			fakeClockTime: "2017-01-01 12:00:00",
+			backups: []*api.Backup{
+				NewTestBackup().WithNamespace("ns").WithName("name-a").WithLabel("ark-schedule", "name").WithPhase(api.BackupPhaseNew).Backup,
+			},
+			apiBackups: []*api.Backup{
+				NewTestBackup().WithNamespace("ns").WithName("name-a").WithLabel("ark-schedule", "name").WithPhase(api.BackupPhaseInProgress).Backup,
+			},
+			expectedBackupGets:   []string{"name-a"},
+			expectedBackupCreate: NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").Backup,
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 12:00:00").WithConcurrencyPolicy(api.ScheduleConcurrencyPolicyReplace).WithNextBackupTime("2017-01-01 12:05:00").
+				WithRun("2017-01-01 11:55:00", api.ScheduleRunResultSubmitted, "name-20170101120000", api.BackupPhaseNew, "").Schedule,
+		},
+		{
+			name: "schedule that missed its starting deadline records a missed run",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:00:00").WithStartingDeadline(time.Minute).Schedule,
+			fakeClockTime: "2017-01-01 12:00:00",
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:00:00").WithStartingDeadline(time.Minute).WithNextBackupTime("2017-01-01 12:00:00").
+				WithRun("2017-01-01 11:55:00", api.ScheduleRunResultMissed, "", "", "10 earlier runs were missed; starting deadline of 1m0s exceeded").Schedule,
+		},
+		{
+			name: "schedule that's not due gets the status of its backups updated",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:58:00").WithNextBackupTime("2017-01-01 12:03:00").
+				WithRun("2017-01-01 11:58:00", api.ScheduleRunResultSubmitted, "name-20170101115800", api.BackupPhaseNew, "").Schedule,
+			fakeClockTime: "2017-01-01 12:00:00",
+			backups: []*api.Backup{
+				NewTestBackup().WithNamespace("ns").WithName("name-20170101115800").WithLabel("ark-schedule", "name").
+					WithPhase(api.BackupPhaseCompleted).WithCompletionTimestamp(time.Date(2017, 1, 1, 11, 59, 0, 0, time.UTC)).Backup,
+				NewTestBackup().WithNamespace("ns").WithName("name-20170101110000").WithLabel("ark-schedule", "name").
+					WithPhase(api.BackupPhaseFailed).WithCompletionTimestamp(time.Date(2017, 1, 1, 11, 1, 0, 0, time.UTC)).Backup,
+				NewTestBackup().WithNamespace("ns").WithName("other").WithLabel("ark-schedule", "other").
+					WithPhase(api.BackupPhaseFailed).WithCompletionTimestamp(time.Date(2017, 1, 1, 11, 30, 0, 0, time.UTC)).Backup,
+			},
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:58:00").WithNextBackupTime("2017-01-01 12:03:00").
+				WithRun("2017-01-01 11:58:00", api.ScheduleRunResultSubmitted, "name-20170101115800", api.BackupPhaseCompleted, "").
+				WithLastSuccessfulBackup("name-20170101115800", "2017-01-01 11:59:00").
+				WithLastFailedBackup("name-20170101110000", "2017-01-01 11:01:00").Schedule,
+		}, {
+			name: "schedule with an invalid time zone fails validation",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseNew).WithCronSchedule("@every 5m").
+				WithTimeZone("Nowhere/Special").Schedule,
+			expectedErr: false,
+			expectedSchedulePhaseUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseFailedValidation).
+				WithCronSchedule("@every 5m").WithTimeZone("Nowhere/Special").
+				WithValidationError("invalid timeZone: unknown time zone Nowhere/Special").Schedule,
+		},
+		{
+			name: "schedule with a time zone runs in that time zone",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("0 2 * * *").
+				WithTimeZone("America/New_York").WithLastBackupTime("2017-01-01 07:00:00").Schedule,
+			fakeClockTime:        "2017-01-02 07:00:01",
+			expectedBackupCreate: NewTestBackup().WithNamespace("ns").WithName("name-20170102070001").WithLabel("ark-schedule", "name").Backup,
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("0 2 * * *").
+				WithTimeZone("America/New_York").WithLastBackupTime("2017-01-02 07:00:01").WithNextBackupTime("2017-01-03 07:00:00").
+				WithRun("2017-01-02 07:00:00", api.ScheduleRunResultSubmitted, "name-20170102070001", api.BackupPhaseNew, "").Schedule,
+		},
+		{
+			name: "schedule that's due during a blackout window is deferred until the window ends",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("0 * * * *").
+				WithBlackoutWindow("peak", "0 9 * * *", 8*time.Hour, "").WithLastBackupTime("2017-01-01 08:00:00").Schedule,
+			fakeClockTime: "2017-01-01 10:30:00",
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("0 * * * *").
+				WithBlackoutWindow("peak", "0 9 * * *", 8*time.Hour, "").WithLastBackupTime("2017-01-01 08:00:00").
+				WithNextBackupTime("2017-01-01 17:00:00").Schedule,
+		},
+		{
+			name: "deferred run starts when the blackout window ends",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("30 9 * * *").
+				WithBlackoutWindow("peak", "0 9 * * *", 8*time.Hour, api.ScheduleBlackoutActionDefer).WithStartingDeadline(5 * time.Minute).
+				WithLastBackupTime("2016-12-31 17:00:00").Schedule,
+			fakeClockTime:        "2017-01-01 17:00:30",
+			expectedBackupCreate: NewTestBackup().WithNamespace("ns").WithName("name-20170101170030").WithLabel("ark-schedule", "name").Backup,
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("30 9 * * *").
+				WithBlackoutWindow("peak", "0 9 * * *", 8*time.Hour, api.ScheduleBlackoutActionDefer).WithStartingDeadline(5*time.Minute).
+				WithLastBackupTime("2017-01-01 17:00:30").WithNextBackupTime("2017-01-02 17:00:00").
+				WithRun("2017-01-01 09:30:00", api.ScheduleRunResultSubmitted, "name-20170101170030", api.BackupPhaseNew, "deferred by blackout window peak").Schedule,
+		},
+		{
+			name: "schedule that's due during a blackout window that skips runs records a skipped run",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("0 * * * *").
+				WithBlackoutWindow("sunday", "0 0 * * 0", 24*time.Hour, api.ScheduleBlackoutActionSkip).WithLastBackupTime("2017-01-01 10:00:00").Schedule,
+			fakeClockTime: "2017-01-01 11:00:30",
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("0 * * * *").
+				WithBlackoutWindow("sunday", "0 0 * * 0", 24*time.Hour, api.ScheduleBlackoutActionSkip).WithLastBackupTime("2017-01-01 10:00:00").
+				WithNextBackupTime("2017-01-02 00:00:00").
+				WithRun("2017-01-01 11:00:00", api.ScheduleRunResultSkipped, "", "", "in blackout window sunday").Schedule,
+		},
+		{
+			name: "triggered schedule submits a backup even if it's paused, without changing its last backup time",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithPaused(true).WithLastBackupTime("2017-01-01 11:00:00").WithTrigger().Schedule,
+			fakeClockTime: "2017-01-01 12:00:00",
+			expectedTriggerRemoval: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithPaused(true).WithLastBackupTime("2017-01-01 11:00:00").WithTriggerRemoved().Schedule,
+			expectedBackupCreate: NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").Backup,
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithPaused(true).WithLastBackupTime("2017-01-01 11:00:00").WithTriggerRemoved().
+				WithTriggeredRun("2017-01-01 12:00:00", api.ScheduleRunResultSubmitted, "name-20170101120000", api.BackupPhaseNew, "").Schedule,
+		},
+		{
+			name: "triggered schedule with concurrency policy Forbid skips the run while a backup is in progress",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:58:00").WithConcurrencyPolicy(api.ScheduleConcurrencyPolicyForbid).WithTrigger().Schedule,
+			fakeClockTime: "2017-01-01 12:00:00",
+			expectedTriggerRemoval: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:58:00").WithConcurrencyPolicy(api.ScheduleConcurrencyPolicyForbid).WithTriggerRemoved().Schedule,
+			backups: []*api.Backup{
+				NewTestBackup().WithNamespace("ns").WithName("name-20170101115800").WithLabel("ark-schedule", "name").WithPhase(api.BackupPhaseInProgress).Backup,
+			},
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:58:00").WithConcurrencyPolicy(api.ScheduleConcurrencyPolicyForbid).WithTriggerRemoved().
+				WithNextBackupTime("2017-01-01 12:03:00").
+				WithTriggeredRun("2017-01-01 12:00:00", api.ScheduleRunResultSkipped, "", "", "backup name-20170101115800 hasn't finished").Schedule,
+		},
+		{
+			name: "triggered schedule that's also due submits a single backup",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:50:00").WithTrigger().Schedule,
+			fakeClockTime: "2017-01-01 12:00:00",
+			expectedTriggerRemoval: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:50:00").WithTriggerRemoved().Schedule,
+			expectedBackupCreate: NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").Backup,
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 12:00:00").WithNextBackupTime("2017-01-01 12:05:00").WithTriggerRemoved().
+				WithRun("2017-01-01 11:55:00", api.ScheduleRunResultSubmitted, "name-20170101120000", api.BackupPhaseNew, "").Schedule,
+		},
+		{
+			name: "triggered runs don't change when the schedule is next due",
+			schedule: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 11:50:00").
+				WithTriggeredRun("2017-01-01 11:59:00", api.ScheduleRunResultSubmitted, "name-20170101115900", api.BackupPhaseNew, "").Schedule,
+			fakeClockTime:        "2017-01-01 12:00:00",
+			expectedBackupCreate: NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").Backup,
+			expectedScheduleStatusUpdate: NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithLastBackupTime("2017-01-01 12:00:00").WithNextBackupTime("2017-01-01 12:05:00").
+				WithRun("2017-01-01 11:55:00", api.ScheduleRunResultSubmitted, "name-20170101120000", api.BackupPhaseNew, "").
+				WithTriggeredRun("2017-01-01 11:59:00", api.ScheduleRunResultSubmitted, "name-20170101115900", api.BackupPhaseNew, "").Schedule,
 		},
 	}
 
@@ -121,8 +339,18 @@ func TestProcessSchedule(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			// the API server has the cached backups unless the test says otherwise
+			apiBackups := test.apiBackups
+			if apiBackups == nil {
+				apiBackups = test.backups
+			}
+			var objects []runtime.Object
+			for _, backup := range apiBackups {
+				objects = append(objects, backup)
+			}
+
 			var (
-				client          = fake.NewSimpleClientset()
+				client          = fake.NewSimpleClientset(objects...)
 				sharedInformers = informers.NewSharedInformerFactory(client, 0)
 				logger, _       = testlogger.NewNullLogger()
 			)
@@ -131,8 +359,10 @@ func TestProcessSchedule(t *testing.T) {
 				client.ArkV1(),
 				client.ArkV1(),
 				sharedInformers.Ark().V1().Schedules(),
+				sharedInformers.Ark().V1().Backups(),
 				time.Duration(0),
 				logger,
+				metrics.NewServerMetrics(),
 			)
 
 			var (
@@ -161,6 +391,10 @@ func TestProcessSchedule(t *testing.T) {
 				})
 			}
 
+			for _, backup := range test.backups {
+				sharedInformers.Ark().V1().Backups().Informer().GetStore().Add(backup)
+			}
+
 			key := test.scheduleKey
 			if key == "" && test.schedule != nil {
 				key, err = cache.MetaNamespaceKeyFunc(test.schedule)
@@ -181,6 +415,30 @@ func TestProcessSchedule(t *testing.T) {
 				expectedActions = append(expectedActions, action)
 			}
 
+			if upd := test.expectedTriggerRemoval; upd != nil {
+				action := core.NewUpdateAction(
+					api.SchemeGroupVersion.WithResource("schedules"),
+					upd.Namespace,
+					upd)
+				expectedActions = append(expectedActions, action)
+			}
+
+			for _, name := range test.expectedBackupGets {
+				action := core.NewGetAction(
+					api.SchemeGroupVersion.WithResource("backups"),
+					"ns",
+					name)
+				expectedActions = append(expectedActions, action)
+			}
+
+			for _, name := range test.expectedBackupDeletes {
+				action := core.NewDeleteAction(
+					api.SchemeGroupVersion.WithResource("backups"),
+					"ns",
+					name)
+				expectedActions = append(expectedActions, action)
+			}
+
 			if created := test.expectedBackupCreate; created != nil {
 				action := core.NewCreateAction(
 					api.SchemeGroupVersion.WithResource("backups"),
@@ -189,7 +447,7 @@ func TestProcessSchedule(t *testing.T) {
 				expectedActions = append(expectedActions, action)
 			}
 
-			if upd := test.expectedScheduleLastBackupUpdate; upd != nil {
+			if upd := test.expectedScheduleStatusUpdate; upd != nil {
 				action := core.NewUpdateAction(
 					api.SchemeGroupVersion.WithResource("schedules"),
 					upd.Namespace,
@@ -202,6 +460,59 @@ func TestProcessSchedule(t *testing.T) {
 	}
 }
 
+func TestProcessScheduleDoesNotRunTriggerTwice(t *testing.T) {
+	var (
+		client          = fake.NewSimpleClientset()
+		sharedInformers = informers.NewSharedInformerFactory(client, 0)
+		logger, _       = testlogger.NewNullLogger()
+		schedule        = NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").
+				WithPaused(true).WithTrigger().Schedule
+	)
+
+	c := NewScheduleController(
+		client.ArkV1(),
+		client.ArkV1(),
+		sharedInformers.Ark().V1().Schedules(),
+		sharedInformers.Ark().V1().Backups(),
+		time.Duration(0),
+		logger,
+		metrics.NewServerMetrics(),
+	)
+
+	testTime, err := time.Parse("2006-01-02 15:04:05", "2017-01-01 12:00:00")
+	require.NoError(t, err)
+	c.clock = clock.NewFakeClock(testTime)
+
+	require.NoError(t, sharedInformers.Ark().V1().Schedules().Informer().GetStore().Add(schedule))
+
+	// removing the trigger annotation succeeds, and the informer sees it, but recording the
+	// triggered run in the schedule's status conflicts with another update
+	conflicted := false
+	client.PrependReactor("update", "schedules", func(action core.Action) (bool, runtime.Object, error) {
+		obj := action.(core.UpdateAction).GetObject().(*api.Schedule).DeepCopy()
+		if len(obj.Status.History) > 0 && !conflicted {
+			conflicted = true
+			return true, nil, apierrors.NewConflict(api.SchemeGroupVersion.WithResource("schedules").GroupResource(), schedule.Name, nil)
+		}
+
+		require.NoError(t, sharedInformers.Ark().V1().Schedules().Informer().GetStore().Update(obj))
+		return true, obj, nil
+	})
+
+	assert.Error(t, c.processSchedule("ns/name"))
+
+	// the requeued schedule is processed again, and doesn't submit another backup
+	assert.NoError(t, c.processSchedule("ns/name"))
+
+	var creates int
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "create" && action.GetResource().Resource == "backups" {
+			creates++
+		}
+	}
+	assert.Equal(t, 1, creates)
+}
+
 func TestGetNextRunTime(t *testing.T) {
 	tests := []struct {
 		name                      string
@@ -296,7 +607,7 @@ func TestParseCronSchedule(t *testing.T) {
 
 	logger, _ := testlogger.NewNullLogger()
 
-	c, errs := parseCronSchedule(s, logger)
+	c, errs := parseCronSchedule(s, nil, logger)
 	require.Empty(t, errs)
 
 	// make sure we're not due and next backup is tomorrow at 9am
@@ -414,7 +725,7 @@ func TestGetBackup(t *testing.T) {
 			testTime, err := time.Parse("2006-01-02 15:04:05", test.testClockTime)
 			require.NoError(t, err, "unable to parse test.testClockTime: %v", err)
 
-			backup := getBackup(test.schedule, clock.NewFakeClock(testTime).Now())
+			backup := arkschedule.NewBackup(test.schedule, clock.NewFakeClock(testTime).Now())
 
 			assert.Equal(t, test.expectedBackup.Namespace, backup.Namespace)
 			assert.Equal(t, test.expectedBackup.Name, backup.Name)
@@ -0,0 +1,48 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/cloudprovider"
+)
+
+// getBackupFromStorage looks for the named backup in each storage location in turn and returns
+// the first one found, with its StorageLocation set to the location it was found in.
+func getBackupFromStorage(storageLocations cloudprovider.StorageLocationResolver, name string, log logrus.FieldLogger) (*api.Backup, error) {
+	locations, err := storageLocations.List()
+	if err != nil {
+		// keep going with the locations that could be resolved
+		log.WithError(err).Error("Error resolving backup storage locations")
+	}
+
+	for _, location := range locations {
+		backup, err := location.BackupService.GetBackup(location.Bucket, name)
+		if err != nil {
+			log.WithError(err).WithField("storageLocation", location.Name).Debug("Backup not found in storage location")
+			continue
+		}
+
+		backup.Spec.StorageLocation = location.Name
+		return backup, nil
+	}
+
+	return nil, errors.Errorf("backup %s not found in object storage", name)
+}
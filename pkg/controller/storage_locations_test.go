@@ -0,0 +1,103 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	testlogger "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
+	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+// newTestStorageLocations returns a StorageLocationResolver whose default location is bucket,
+// accessed via backupService. Any other locations must be added to sharedInformers, and are
+// accessed via the entry for their name in locationServices.
+func newTestStorageLocations(
+	backupService cloudprovider.BackupService,
+	bucket string,
+	sharedInformers informers.SharedInformerFactory,
+	locationServices map[string]cloudprovider.BackupService,
+) cloudprovider.StorageLocationResolver {
+	return cloudprovider.NewStorageLocationResolver(
+		&cloudprovider.StorageLocation{
+			Name:          api.DefaultBackupStorageLocation,
+			Bucket:        bucket,
+			BackupService: backupService,
+		},
+		sharedInformers.Ark().V1().BackupStorageLocations().Lister(),
+		func(location *api.BackupStorageLocation) (cloudprovider.BackupService, error) {
+			backupService, ok := locationServices[location.Name]
+			if !ok {
+				return nil, errors.New("no backup service for location")
+			}
+			return backupService, nil
+		},
+	)
+}
+
+func newTestStorageLocation(name, bucket string) *api.BackupStorageLocation {
+	return &api.BackupStorageLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: api.DefaultNamespace,
+			Name:      name,
+		},
+		Spec: api.BackupStorageLocationSpec{
+			Provider: "provider",
+			Bucket:   bucket,
+		},
+	}
+}
+
+func TestGetBackupFromStorage(t *testing.T) {
+	var (
+		client          = fake.NewSimpleClientset()
+		sharedInformers = informers.NewSharedInformerFactory(client, 0)
+		defaultService  = &arktest.BackupService{}
+		otherService    = &arktest.BackupService{}
+		log, _          = testlogger.NewNullLogger()
+	)
+	defer defaultService.AssertExpectations(t)
+	defer otherService.AssertExpectations(t)
+
+	sharedInformers.Ark().V1().BackupStorageLocations().Informer().GetStore().Add(newTestStorageLocation("other", "other-bucket"))
+
+	storageLocations := newTestStorageLocations(defaultService, "bucket", sharedInformers, map[string]cloudprovider.BackupService{"other": otherService})
+
+	defaultService.On("GetBackup", "bucket", "backup-1").Return(nil, errors.New("not found"))
+	otherService.On("GetBackup", "other-bucket", "backup-1").Return(arktest.NewTestBackup().WithName("backup-1").Backup, nil)
+
+	backup, err := getBackupFromStorage(storageLocations, "backup-1", log)
+	require.NoError(t, err)
+	assert.Equal(t, "backup-1", backup.Name)
+	assert.Equal(t, "other", backup.Spec.StorageLocation)
+
+	defaultService.On("GetBackup", "bucket", "backup-2").Return(nil, errors.New("not found"))
+	otherService.On("GetBackup", "other-bucket", "backup-2").Return(nil, errors.New("not found"))
+
+	_, err = getBackupFromStorage(storageLocations, "backup-2", log)
+	assert.EqualError(t, err, "backup backup-2 not found in object storage")
+}
@@ -0,0 +1,377 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package encryption implements envelope encryption of the files Ark stores in object
+// storage. Each file is encrypted with its own randomly-generated data key, and the data
+// key is stored, encrypted by a KeyProvider, in the file's header.
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// KeyProvider encrypts and decrypts the data keys that files are encrypted with. A KeyProvider
+// may hold the key encryption key itself (like the local provider), or delegate to an external
+// key management service.
+type KeyProvider interface {
+	// KeyID returns the ID of the key that EncryptKey encrypts data keys with.
+	KeyID() string
+
+	// EncryptKey encrypts a data key with the key identified by KeyID.
+	EncryptKey(dataKey []byte) ([]byte, error)
+
+	// DecryptKey decrypts a data key that was encrypted with the key identified by keyID.
+	DecryptKey(keyID string, encryptedKey []byte) ([]byte, error)
+
+	// DeriveKey returns a 32-byte secret key for the given purpose, derived from the key
+	// identified by KeyID. The same key is returned every time for the same purpose, so it can
+	// be used to compute identifiers that mustn't reveal what they identify.
+	DeriveKey(purpose string) ([]byte, error)
+}
+
+const (
+	// magic identifies an encrypted file. It's followed by a one-byte format version.
+	magic         = "ARKENC"
+	formatVersion = 1
+
+	dataKeySize     = 32
+	noncePrefixSize = 4
+	// chunkSize is the amount of plaintext encrypted in each chunk of a file.
+	chunkSize = 64 * 1024
+
+	// finalChunkFlag is set in a chunk's length prefix to mark the last chunk of a file,
+	// so truncated files are detected.
+	finalChunkFlag = 1 << 31
+)
+
+// encryptingWriter encrypts everything written to it in chunks, writing the encrypted chunks to
+// the underlying writer.
+type encryptingWriter struct {
+	w           io.Writer
+	aead        cipher.AEAD
+	noncePrefix []byte
+	counter     uint64
+	buf         []byte
+	closed      bool
+}
+
+// NewEncryptingWriter returns a WriteCloser that encrypts everything written to it with a new
+// data key, encrypted by keyProvider, and writes the result to w. Close must be called to write
+// the final chunk; it does not close w.
+func NewEncryptingWriter(w io.Writer, keyProvider KeyProvider) (io.WriteCloser, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, errors.Wrap(err, "error generating data key")
+	}
+
+	encryptedKey, err := keyProvider.EncryptKey(dataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "error encrypting data key")
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	noncePrefix := make([]byte, noncePrefixSize)
+	if _, err := io.ReadFull(rand.Reader, noncePrefix); err != nil {
+		return nil, errors.Wrap(err, "error generating nonce")
+	}
+
+	header := new(bytes.Buffer)
+	header.WriteString(magic)
+	header.WriteByte(formatVersion)
+	if err := writeField(header, []byte(keyProvider.KeyID())); err != nil {
+		return nil, err
+	}
+	if err := writeField(header, encryptedKey); err != nil {
+		return nil, err
+	}
+	header.Write(noncePrefix)
+
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &encryptingWriter{
+		w:           w,
+		aead:        aead,
+		noncePrefix: noncePrefix,
+		buf:         make([]byte, 0, chunkSize),
+	}, nil
+}
+
+func (ew *encryptingWriter) Write(p []byte) (int, error) {
+	if ew.closed {
+		return 0, errors.New("write to closed encrypting writer")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := copy(ew.buf[len(ew.buf):chunkSize], p)
+		ew.buf = ew.buf[:len(ew.buf)+n]
+		p = p[n:]
+		written += n
+
+		if len(ew.buf) == chunkSize {
+			if err := ew.writeChunk(false); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	return written, nil
+}
+
+// Close encrypts any buffered data as the final chunk.
+func (ew *encryptingWriter) Close() error {
+	if ew.closed {
+		return nil
+	}
+	ew.closed = true
+
+	return ew.writeChunk(true)
+}
+
+func (ew *encryptingWriter) writeChunk(final bool) error {
+	sealed := ew.aead.Seal(nil, ew.nonce(), ew.buf, chunkAdditionalData(final))
+	ew.counter++
+	ew.buf = ew.buf[:0]
+
+	length := uint32(len(sealed))
+	if final {
+		length |= finalChunkFlag
+	}
+	if err := binary.Write(ew.w, binary.BigEndian, length); err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := ew.w.Write(sealed); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func (ew *encryptingWriter) nonce() []byte {
+	return chunkNonce(ew.noncePrefix, ew.counter, ew.aead.NonceSize())
+}
+
+// decryptingReader decrypts a file written by an encryptingWriter.
+type decryptingReader struct {
+	r           io.Reader
+	aead        cipher.AEAD
+	noncePrefix []byte
+	counter     uint64
+	buf         []byte
+	done        bool
+}
+
+// NewDecryptingReader returns a Reader that decrypts the encrypted file read from r, using
+// keyProvider to decrypt its data key.
+func NewDecryptingReader(r io.Reader, keyProvider KeyProvider) (io.Reader, error) {
+	header := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errors.Wrap(err, "error reading encryption header")
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, errors.New("file is not encrypted")
+	}
+	if header[len(magic)] != formatVersion {
+		return nil, errors.Errorf("unsupported encryption format version %d", header[len(magic)])
+	}
+
+	keyID, err := readField(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading key ID")
+	}
+	encryptedKey, err := readField(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading data key")
+	}
+	noncePrefix := make([]byte, noncePrefixSize)
+	if _, err := io.ReadFull(r, noncePrefix); err != nil {
+		return nil, errors.Wrap(err, "error reading nonce")
+	}
+
+	if keyProvider == nil {
+		return nil, errors.Errorf("file is encrypted with key %s, but no encryption key provider is configured", keyID)
+	}
+
+	dataKey, err := keyProvider.DecryptKey(string(keyID), encryptedKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error decrypting data key with key %s", keyID)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptingReader{
+		r:           r,
+		aead:        aead,
+		noncePrefix: noncePrefix,
+	}, nil
+}
+
+func (dr *decryptingReader) Read(p []byte) (int, error) {
+	for len(dr.buf) == 0 {
+		if dr.done {
+			return 0, io.EOF
+		}
+		if err := dr.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, dr.buf)
+	dr.buf = dr.buf[n:]
+	return n, nil
+}
+
+func (dr *decryptingReader) readChunk() error {
+	var length uint32
+	if err := binary.Read(dr.r, binary.BigEndian, &length); err != nil {
+		if err == io.EOF {
+			return errors.New("encrypted file is truncated")
+		}
+		return errors.WithStack(err)
+	}
+
+	final := length&finalChunkFlag != 0
+	length &^= finalChunkFlag
+	if length > chunkSize+uint32(dr.aead.Overhead()) {
+		return errors.Errorf("invalid encrypted chunk length %d", length)
+	}
+
+	sealed := make([]byte, length)
+	if _, err := io.ReadFull(dr.r, sealed); err != nil {
+		return errors.Wrap(err, "error reading encrypted chunk")
+	}
+
+	plaintext, err := dr.aead.Open(nil, chunkNonce(dr.noncePrefix, dr.counter, dr.aead.NonceSize()), sealed, chunkAdditionalData(final))
+	if err != nil {
+		return errors.Wrap(err, "error decrypting chunk")
+	}
+	dr.counter++
+
+	dr.buf = plaintext
+	dr.done = final
+	return nil
+}
+
+// IsEncrypted returns whether the data read from r is an encrypted file, along with a Reader
+// that returns all of the data from r, including what was read to check.
+func IsEncrypted(r io.Reader) (bool, io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	peeked, err := br.Peek(len(magic))
+	if err != nil && err != io.EOF {
+		return false, nil, errors.WithStack(err)
+	}
+
+	return string(peeked) == magic, br, nil
+}
+
+// NewDecryptingReaderIfEncrypted returns a Reader that decrypts the data read from r if it's an
+// encrypted file, or returns it as-is otherwise, so files stored before encryption was enabled
+// can still be read.
+func NewDecryptingReaderIfEncrypted(r io.Reader, keyProvider KeyProvider) (io.Reader, error) {
+	encrypted, r, err := IsEncrypted(r)
+	if err != nil {
+		return nil, err
+	}
+	if !encrypted {
+		return r, nil
+	}
+
+	return NewDecryptingReader(r, keyProvider)
+}
+
+// NewDecryptingReaderForKeyID returns a Reader that decrypts the data read from r, which was
+// recorded as being encrypted with the key identified by keyID. If keyID is set, the data must
+// be encrypted, so a plaintext file can't be substituted for an encrypted one; only data with
+// no recorded key ID is returned as-is when it isn't encrypted.
+func NewDecryptingReaderForKeyID(r io.Reader, keyProvider KeyProvider, keyID string) (io.Reader, error) {
+	if keyID == "" {
+		return NewDecryptingReaderIfEncrypted(r, keyProvider)
+	}
+
+	return NewDecryptingReader(r, keyProvider)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return aead, nil
+}
+
+func chunkNonce(prefix []byte, counter uint64, size int) []byte {
+	nonce := make([]byte, size)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint64(nonce[size-8:], counter)
+	return nonce
+}
+
+func chunkAdditionalData(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+func writeField(w io.Writer, field []byte) error {
+	if len(field) > 0xffff {
+		return errors.Errorf("encryption header field too long (%d bytes)", len(field))
+	}
+	if err := binary.Write(w, binary.BigEndian, uint16(len(field))); err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := w.Write(field); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func readField(r io.Reader) ([]byte, error) {
+	var length uint16
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	field := make([]byte, length)
+	if _, err := io.ReadFull(r, field); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return field, nil
+}
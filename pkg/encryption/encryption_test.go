@@ -0,0 +1,186 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyProvider(t *testing.T, key string) KeyProvider {
+	keyProvider, err := NewLocalKeyProviderForKey([]byte(strings.Repeat(key, 32)))
+	require.NoError(t, err)
+	return keyProvider
+}
+
+func encrypt(t *testing.T, keyProvider KeyProvider, plaintext []byte) []byte {
+	buf := new(bytes.Buffer)
+
+	w, err := NewEncryptingWriter(buf, keyProvider)
+	require.NoError(t, err)
+	_, err = w.Write(plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	keyProvider := newTestKeyProvider(t, "a")
+
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "empty", size: 0},
+		{name: "less than a chunk", size: 100},
+		{name: "exactly one chunk", size: chunkSize},
+		{name: "multiple chunks", size: 3*chunkSize + 17},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plaintext := bytes.Repeat([]byte("x"), test.size)
+
+			ciphertext := encrypt(t, keyProvider, plaintext)
+			if test.size > 0 {
+				assert.False(t, bytes.Contains(ciphertext, plaintext))
+			}
+
+			r, err := NewDecryptingReader(bytes.NewReader(ciphertext), keyProvider)
+			require.NoError(t, err)
+			res, err := ioutil.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, res)
+		})
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	keyProvider := newTestKeyProvider(t, "a")
+	ciphertext := encrypt(t, keyProvider, bytes.Repeat([]byte("x"), 2*chunkSize))
+
+	// wrong key
+	_, err := NewDecryptingReader(bytes.NewReader(ciphertext), newTestKeyProvider(t, "b"))
+	assert.Error(t, err)
+
+	// no key provider
+	_, err = NewDecryptingReader(bytes.NewReader(ciphertext), nil)
+	assert.Error(t, err)
+
+	// truncated by removing the (empty) final chunk, which is a length prefix and the GCM tag
+	r, err := NewDecryptingReader(bytes.NewReader(ciphertext[:len(ciphertext)-(4+16)]), keyProvider)
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(r)
+	assert.EqualError(t, err, "encrypted file is truncated")
+
+	// tampered
+	tampered := append([]byte{}, ciphertext...)
+	tampered[len(tampered)-1] ^= 1
+	r, err = NewDecryptingReader(bytes.NewReader(tampered), keyProvider)
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(r)
+	assert.Error(t, err)
+}
+
+func TestNewDecryptingReaderIfEncrypted(t *testing.T) {
+	keyProvider := newTestKeyProvider(t, "a")
+
+	for _, data := range [][]byte{
+		encrypt(t, keyProvider, []byte("some data")),
+		[]byte("some data"),
+	} {
+		r, err := NewDecryptingReaderIfEncrypted(bytes.NewReader(data), keyProvider)
+		require.NoError(t, err)
+		res, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "some data", string(res))
+	}
+
+	// short unencrypted data is returned as-is
+	r, err := NewDecryptingReaderIfEncrypted(bytes.NewReader([]byte("ab")), nil)
+	require.NoError(t, err)
+	res, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "ab", string(res))
+}
+
+func TestNewDecryptingReaderForKeyID(t *testing.T) {
+	keyProvider := newTestKeyProvider(t, "a")
+	ciphertext := encrypt(t, keyProvider, []byte("some data"))
+
+	// data recorded as encrypted must be encrypted
+	r, err := NewDecryptingReaderForKeyID(bytes.NewReader(ciphertext), keyProvider, "a")
+	require.NoError(t, err)
+	res, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "some data", string(res))
+
+	_, err = NewDecryptingReaderForKeyID(bytes.NewReader([]byte("some data")), keyProvider, "a")
+	assert.EqualError(t, err, "file is not encrypted")
+
+	// data with no recorded key ID may be either
+	for _, data := range [][]byte{ciphertext, []byte("some data")} {
+		r, err := NewDecryptingReaderForKeyID(bytes.NewReader(data), keyProvider, "")
+		require.NoError(t, err)
+		res, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "some data", string(res))
+	}
+}
+
+func TestLocalKeyProvider(t *testing.T) {
+	_, err := NewLocalKeyProviderForKey([]byte("too short"))
+	assert.Error(t, err)
+
+	a := newTestKeyProvider(t, "a")
+	b := newTestKeyProvider(t, "b")
+	assert.NotEqual(t, a.KeyID(), b.KeyID())
+	assert.True(t, strings.HasPrefix(a.KeyID(), LocalKeyProviderName+"/"))
+
+	encryptedKey, err := a.EncryptKey([]byte("data key"))
+	require.NoError(t, err)
+
+	dataKey, err := a.DecryptKey(a.KeyID(), encryptedKey)
+	require.NoError(t, err)
+	assert.Equal(t, "data key", string(dataKey))
+
+	_, err = b.DecryptKey(a.KeyID(), encryptedKey)
+	assert.Error(t, err)
+
+	// derived keys depend on both the key and the purpose
+	derived, err := a.DeriveKey("purpose")
+	require.NoError(t, err)
+	assert.Len(t, derived, 32)
+
+	again, err := a.DeriveKey("purpose")
+	require.NoError(t, err)
+	assert.Equal(t, derived, again)
+
+	other, err := a.DeriveKey("other purpose")
+	require.NoError(t, err)
+	assert.NotEqual(t, derived, other)
+
+	other, err = b.DeriveKey("purpose")
+	require.NoError(t, err)
+	assert.NotEqual(t, derived, other)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+const (
+	// LocalKeyProviderName is the name of the key provider that uses a key stored in a
+	// Kubernetes Secret.
+	LocalKeyProviderName = "local"
+
+	secretNameConfigKey = "secretName"
+	secretKeyConfigKey  = "secretKey"
+
+	defaultSecretName = "ark-encryption-key"
+	defaultSecretKey  = "key"
+
+	localKeySize = 32
+)
+
+// localKeyProvider encrypts data keys with an AES-256 key stored in a Kubernetes Secret.
+type localKeyProvider struct {
+	keyID string
+	key   []byte
+	aead  cipher.AEAD
+}
+
+// NewLocalKeyProvider returns a KeyProvider that uses the 32-byte key stored in a Secret in the
+// Ark namespace. The Secret's name and the key within its data are set by the "secretName" and
+// "secretKey" config keys, and default to "ark-encryption-key" and "key".
+func NewLocalKeyProvider(config map[string]string, secrets corev1.SecretsGetter) (KeyProvider, error) {
+	secretName := config[secretNameConfigKey]
+	if secretName == "" {
+		secretName = defaultSecretName
+	}
+	secretKey := config[secretKeyConfigKey]
+	if secretKey == "" {
+		secretKey = defaultSecretKey
+	}
+
+	secret, err := secrets.Secrets(api.DefaultNamespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting encryption key secret %s", secretName)
+	}
+
+	key, ok := secret.Data[secretKey]
+	if !ok {
+		return nil, errors.Errorf("encryption key secret %s has no key %q", secretName, secretKey)
+	}
+
+	return NewLocalKeyProviderForKey(key)
+}
+
+// NewLocalKeyProviderForKey returns a KeyProvider that uses the given 32-byte key.
+func NewLocalKeyProviderForKey(key []byte) (KeyProvider, error) {
+	if len(key) != localKeySize {
+		return nil, errors.Errorf("encryption key must be %d bytes, got %d", localKeySize, len(key))
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// identify the key by its fingerprint so backups encrypted with a different key are
+	// detected before trying to decrypt them
+	fingerprint := sha256.Sum256(key)
+
+	return &localKeyProvider{
+		keyID: LocalKeyProviderName + "/" + hex.EncodeToString(fingerprint[:8]),
+		key:   key,
+		aead:  aead,
+	}, nil
+}
+
+func (p *localKeyProvider) KeyID() string {
+	return p.keyID
+}
+
+func (p *localKeyProvider) EncryptKey(dataKey []byte) ([]byte, error) {
+	nonce := make([]byte, p.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return p.aead.Seal(nonce, nonce, dataKey, []byte(p.keyID)), nil
+}
+
+func (p *localKeyProvider) DecryptKey(keyID string, encryptedKey []byte) ([]byte, error) {
+	if keyID != p.keyID {
+		return nil, errors.Errorf("data key was encrypted with key %s, but the configured key is %s", keyID, p.keyID)
+	}
+
+	nonceSize := p.aead.NonceSize()
+	if len(encryptedKey) < nonceSize {
+		return nil, errors.New("encrypted data key is too short")
+	}
+
+	dataKey, err := p.aead.Open(nil, encryptedKey[:nonceSize], encryptedKey[nonceSize:], []byte(keyID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return dataKey, nil
+}
+
+// NewKeyProvider returns the KeyProvider described by config. Additional providers (e.g. for
+// cloud key management services) can be added here as they're implemented.
+func NewKeyProvider(config *api.EncryptionConfig, secrets corev1.SecretsGetter) (KeyProvider, error) {
+	switch config.Provider {
+	case LocalKeyProviderName:
+		return NewLocalKeyProvider(config.Config, secrets)
+	default:
+		return nil, errors.Errorf("unsupported encryption key provider %q", config.Provider)
+	}
+}
+
+// DeriveKey returns the HMAC-SHA256 of purpose, keyed with the local key.
+func (p *localKeyProvider) DeriveKey(purpose string) ([]byte, error) {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil), nil
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package export converts Ark backups into Kubernetes manifests that can be applied with
+// kubectl, or stored in a repository.
+package export
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/restore/restorers"
+)
+
+// Exporter writes the items in a backup to a directory as YAML manifests. Namespaced items are
+// written to namespaces/<namespace>/<resource>/<name>.yaml, and cluster-scoped items to
+// cluster/<resource>/<name>.yaml, under the output directory.
+type Exporter struct {
+	outputDir string
+	sanitize  bool
+	restorers map[string]restorers.ResourceRestorer
+	logger    *logrus.Logger
+}
+
+// NewExporter returns an Exporter that writes manifests to outputDir. If sanitize is true, items
+// are prepared the same way they are for a restore, by the restorers package, so that the
+// manifests can be applied to a cluster: the server-populated parts of their metadata and their
+// status are removed, along with fields such as services' cluster IPs and node ports, and items
+// owned by a controller are skipped, since the controller recreates them.
+func NewExporter(outputDir string, sanitize bool, logger *logrus.Logger) *Exporter {
+	return &Exporter{
+		outputDir: outputDir,
+		sanitize:  sanitize,
+		// snapshots are never restored for an export, so the PV restorer has no snapshot service
+		restorers: restorers.NewDefaultRestorers(nil, logger),
+		logger:    logger,
+	}
+}
+
+// Export writes the items in the gzipped backup tarball read from backupReader to the output
+// directory, returning the number of items written.
+func (e *Exporter) Export(backupReader io.Reader) (int, error) {
+	gzr, err := gzip.NewReader(backupReader)
+	if err != nil {
+		return 0, errors.Wrap(err, "error reading backup")
+	}
+	defer gzr.Close()
+
+	count := 0
+	tr := tar.NewReader(gzr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, errors.Wrap(err, "error reading backup")
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := validateItemPath(hdr.Name); err != nil {
+			return count, err
+		}
+
+		groupResource, outputPath, ok := itemOutputPath(hdr.Name)
+		if !ok {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return count, errors.Wrapf(err, "error reading %s from backup", hdr.Name)
+		}
+
+		written, err := e.exportItem(groupResource, data, filepath.Join(e.outputDir, outputPath))
+		if err != nil {
+			return count, errors.Wrapf(err, "error exporting %s", hdr.Name)
+		}
+		if written {
+			count++
+		}
+	}
+}
+
+// exportItem writes the item in data to path, returning false if it was skipped.
+func (e *Exporter) exportItem(groupResource schema.GroupResource, data []byte, path string) (bool, error) {
+	obj := new(unstructured.Unstructured)
+	if err := json.Unmarshal(data, &obj.Object); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	if e.sanitize {
+		if hasControllerOwner(obj) {
+			e.logger.Infof("Skipping %s %s because it has a controller owner", groupResource.String(), obj.GetName())
+			return false, nil
+		}
+
+		restorer := e.restorers[groupResource.Resource]
+		if restorer == nil {
+			restorer = restorers.NewBasicRestorer(true)
+		}
+
+		prepared, warning, err := restorer.Prepare(obj, &api.Restore{}, &api.Backup{})
+		if warning != nil {
+			e.logger.WithError(warning).Warnf("Warning sanitizing %s %s", groupResource.String(), obj.GetName())
+		}
+		if err != nil {
+			return false, errors.Wrap(err, "error sanitizing item")
+		}
+		obj.Object = prepared.UnstructuredContent()
+	}
+
+	manifest, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, errors.WithStack(err)
+	}
+	if err := ioutil.WriteFile(path, manifest, 0644); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return true, nil
+}
+
+// itemOutputPath returns the group-resource of the item in the backup's tarball at itemPath, and
+// the path relative to the output directory to write its manifest to. It returns false if
+// itemPath isn't an item.
+func itemOutputPath(itemPath string) (schema.GroupResource, string, bool) {
+	parts := strings.Split(itemPath, "/")
+	if len(parts) < 4 || parts[0] != api.ResourcesDir || filepath.Ext(itemPath) != ".json" {
+		return schema.GroupResource{}, "", false
+	}
+
+	resource := parts[1]
+	name := strings.TrimSuffix(parts[len(parts)-1], ".json") + ".yaml"
+
+	switch {
+	case len(parts) == 4 && parts[2] == api.ClusterScopedDir:
+		return schema.ParseGroupResource(resource), filepath.Join(api.ClusterScopedDir, resource, name), true
+	case len(parts) == 5 && parts[2] == api.NamespaceScopedDir:
+		return schema.ParseGroupResource(resource), filepath.Join(api.NamespaceScopedDir, parts[3], resource, name), true
+	default:
+		return schema.GroupResource{}, "", false
+	}
+}
+
+// validateItemPath returns an error if any component of the tarball path itemPath is empty, "." or "..", or
+// contains a path separator, so that no item can be written outside of the output directory.
+func validateItemPath(itemPath string) error {
+	for _, part := range strings.Split(itemPath, "/") {
+		if part == "" || part == "." || part == ".." || strings.ContainsRune(part, filepath.Separator) {
+			return errors.Errorf("invalid item path %q in backup", itemPath)
+		}
+	}
+	return nil
+}
+
+func hasControllerOwner(obj *unstructured.Unstructured) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.Controller != nil && *ref.Controller {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	testlogger "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBackupTarball(t *testing.T, files map[string]string) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+
+	for name, contents := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Size:     int64(len(contents)),
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+		}))
+		_, err := tw.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+	return buf
+}
+
+func TestExport(t *testing.T) {
+	files := map[string]string{
+		"metadata/ark-backup.json": `{"kind":"Backup"}`,
+		"resources/services/namespaces/ns-1/svc-1.json": `{
+			"apiVersion": "v1",
+			"kind": "Service",
+			"metadata": {"name": "svc-1", "namespace": "ns-1", "uid": "abc", "resourceVersion": "12"},
+			"spec": {"clusterIP": "10.0.0.1", "ports": [{"port": 80, "nodePort": 30000}]},
+			"status": {"loadBalancer": {}}
+		}`,
+		"resources/pods/namespaces/ns-1/pod-1.json": `{
+			"apiVersion": "v1",
+			"kind": "Pod",
+			"metadata": {"name": "pod-1", "namespace": "ns-1", "ownerReferences": [{"kind": "ReplicaSet", "name": "rs-1", "controller": true}]},
+			"spec": {}
+		}`,
+		"resources/namespaces/cluster/ns-1.json": `{
+			"apiVersion": "v1",
+			"kind": "Namespace",
+			"metadata": {"name": "ns-1", "uid": "def"},
+			"spec": {"finalizers": ["kubernetes"]},
+			"status": {"phase": "Active"}
+		}`,
+		"resources/deployments.apps/namespaces/ns-1/deploy-1.json": `{
+			"apiVersion": "apps/v1beta1",
+			"kind": "Deployment",
+			"metadata": {"name": "deploy-1", "namespace": "ns-1", "uid": "ghi"},
+			"spec": {"replicas": 1}
+		}`,
+	}
+
+	tests := []struct {
+		name          string
+		sanitize      bool
+		expectedCount int
+		expectedFiles map[string]string
+	}{
+		{
+			name:          "unsanitized",
+			sanitize:      false,
+			expectedCount: 4,
+			expectedFiles: map[string]string{
+				"namespaces/ns-1/services/svc-1.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: svc-1
+  namespace: ns-1
+  resourceVersion: "12"
+  uid: abc
+spec:
+  clusterIP: 10.0.0.1
+  ports:
+  - nodePort: 30000
+    port: 80
+status:
+  loadBalancer: {}
+`,
+				"namespaces/ns-1/pods/pod-1.yaml": `apiVersion: v1
+kind: Pod
+metadata:
+  name: pod-1
+  namespace: ns-1
+  ownerReferences:
+  - controller: true
+    kind: ReplicaSet
+    name: rs-1
+spec: {}
+`,
+				"cluster/namespaces/ns-1.yaml": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns-1
+  uid: def
+spec:
+  finalizers:
+  - kubernetes
+status:
+  phase: Active
+`,
+				"namespaces/ns-1/deployments.apps/deploy-1.yaml": `apiVersion: apps/v1beta1
+kind: Deployment
+metadata:
+  name: deploy-1
+  namespace: ns-1
+  uid: ghi
+spec:
+  replicas: 1
+`,
+			},
+		},
+		{
+			name:          "sanitized",
+			sanitize:      true,
+			expectedCount: 3,
+			expectedFiles: map[string]string{
+				"namespaces/ns-1/services/svc-1.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: svc-1
+  namespace: ns-1
+spec:
+  ports:
+  - port: 80
+`,
+				"cluster/namespaces/ns-1.yaml": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns-1
+spec:
+  finalizers:
+  - kubernetes
+`,
+				"namespaces/ns-1/deployments.apps/deploy-1.yaml": `apiVersion: apps/v1beta1
+kind: Deployment
+metadata:
+  name: deploy-1
+  namespace: ns-1
+spec:
+  replicas: 1
+`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "ark-export-test")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			logger, _ := testlogger.NewNullLogger()
+			exporter := NewExporter(dir, test.sanitize, logger)
+
+			count, err := exporter.Export(newBackupTarball(t, files))
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedCount, count)
+
+			actualFiles := make(map[string]string)
+			err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				contents, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				rel, err := filepath.Rel(dir, path)
+				if err != nil {
+					return err
+				}
+				actualFiles[rel] = string(contents)
+				return nil
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedFiles, actualFiles)
+		})
+	}
+}
+
+func TestExportInvalidBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ark-export-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	logger, _ := testlogger.NewNullLogger()
+	_, err = NewExporter(dir, false, logger).Export(bytes.NewReader([]byte("not a tarball")))
+	assert.Error(t, err)
+}
+
+func TestExportInvalidItemPath(t *testing.T) {
+	for _, itemPath := range []string{
+		"resources/configmaps/namespaces/../../../../escaped.json",
+		"resources/../namespaces/ns-1/cm-1.json",
+		"resources/./namespaces/ns-1/cm-1.json",
+		"resources/configmaps/namespaces//cm-1.json",
+	} {
+		t.Run(itemPath, func(t *testing.T) {
+			parent, err := ioutil.TempDir("", "ark-export-test")
+			require.NoError(t, err)
+			defer os.RemoveAll(parent)
+			dir := filepath.Join(parent, "output")
+
+			logger, _ := testlogger.NewNullLogger()
+			tarball := newBackupTarball(t, map[string]string{itemPath: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm-1"}}`})
+
+			count, err := NewExporter(dir, false, logger).Export(tarball)
+			assert.Error(t, err)
+			assert.Equal(t, 0, count)
+
+			// nothing is written inside or outside of the output directory
+			entries, err := ioutil.ReadDir(parent)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
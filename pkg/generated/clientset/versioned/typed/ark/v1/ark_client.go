@@ -25,8 +25,12 @@ import (
 type ArkV1Interface interface {
 	RESTClient() rest.Interface
 	BackupsGetter
+	BackupStorageLocationsGetter
 	ConfigsGetter
+	DeleteBackupRequestsGetter
 	DownloadRequestsGetter
+	PodVolumeBackupsGetter
+	PodVolumeRestoresGetter
 	RestoresGetter
 	SchedulesGetter
 }
@@ -40,14 +44,30 @@ func (c *ArkV1Client) Backups(namespace string) BackupInterface {
 	return newBackups(c, namespace)
 }
 
+func (c *ArkV1Client) BackupStorageLocations(namespace string) BackupStorageLocationInterface {
+	return newBackupStorageLocations(c, namespace)
+}
+
 func (c *ArkV1Client) Configs(namespace string) ConfigInterface {
 	return newConfigs(c, namespace)
 }
 
+func (c *ArkV1Client) DeleteBackupRequests(namespace string) DeleteBackupRequestInterface {
+	return newDeleteBackupRequests(c, namespace)
+}
+
 func (c *ArkV1Client) DownloadRequests(namespace string) DownloadRequestInterface {
 	return newDownloadRequests(c, namespace)
 }
 
+func (c *ArkV1Client) PodVolumeBackups(namespace string) PodVolumeBackupInterface {
+	return newPodVolumeBackups(c, namespace)
+}
+
+func (c *ArkV1Client) PodVolumeRestores(namespace string) PodVolumeRestoreInterface {
+	return newPodVolumeRestores(c, namespace)
+}
+
 func (c *ArkV1Client) Restores(namespace string) RestoreInterface {
 	return newRestores(c, namespace)
 }
@@ -29,14 +29,30 @@ func (c *FakeArkV1) Backups(namespace string) v1.BackupInterface {
 	return &FakeBackups{c, namespace}
 }
 
+func (c *FakeArkV1) BackupStorageLocations(namespace string) v1.BackupStorageLocationInterface {
+	return &FakeBackupStorageLocations{c, namespace}
+}
+
 func (c *FakeArkV1) Configs(namespace string) v1.ConfigInterface {
 	return &FakeConfigs{c, namespace}
 }
 
+func (c *FakeArkV1) DeleteBackupRequests(namespace string) v1.DeleteBackupRequestInterface {
+	return &FakeDeleteBackupRequests{c, namespace}
+}
+
 func (c *FakeArkV1) DownloadRequests(namespace string) v1.DownloadRequestInterface {
 	return &FakeDownloadRequests{c, namespace}
 }
 
+func (c *FakeArkV1) PodVolumeBackups(namespace string) v1.PodVolumeBackupInterface {
+	return &FakePodVolumeBackups{c, namespace}
+}
+
+func (c *FakeArkV1) PodVolumeRestores(namespace string) v1.PodVolumeRestoreInterface {
+	return &FakePodVolumeRestores{c, namespace}
+}
+
 func (c *FakeArkV1) Restores(namespace string) v1.RestoreInterface {
 	return &FakeRestores{c, namespace}
 }
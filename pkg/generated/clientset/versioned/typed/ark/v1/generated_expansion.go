@@ -17,10 +17,18 @@ package v1
 
 type BackupExpansion interface{}
 
+type BackupStorageLocationExpansion interface{}
+
 type ConfigExpansion interface{}
 
+type DeleteBackupRequestExpansion interface{}
+
 type DownloadRequestExpansion interface{}
 
+type PodVolumeBackupExpansion interface{}
+
+type PodVolumeRestoreExpansion interface{}
+
 type RestoreExpansion interface{}
 
 type ScheduleExpansion interface{}
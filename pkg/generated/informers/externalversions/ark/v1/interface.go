@@ -26,10 +26,18 @@ import (
 type Interface interface {
 	// Backups returns a BackupInformer.
 	Backups() BackupInformer
+	// BackupStorageLocations returns a BackupStorageLocationInformer.
+	BackupStorageLocations() BackupStorageLocationInformer
 	// Configs returns a ConfigInformer.
 	Configs() ConfigInformer
+	// DeleteBackupRequests returns a DeleteBackupRequestInformer.
+	DeleteBackupRequests() DeleteBackupRequestInformer
 	// DownloadRequests returns a DownloadRequestInformer.
 	DownloadRequests() DownloadRequestInformer
+	// PodVolumeBackups returns a PodVolumeBackupInformer.
+	PodVolumeBackups() PodVolumeBackupInformer
+	// PodVolumeRestores returns a PodVolumeRestoreInformer.
+	PodVolumeRestores() PodVolumeRestoreInformer
 	// Restores returns a RestoreInformer.
 	Restores() RestoreInformer
 	// Schedules returns a ScheduleInformer.
@@ -50,16 +58,36 @@ func (v *version) Backups() BackupInformer {
 	return &backupInformer{factory: v.SharedInformerFactory}
 }
 
+// BackupStorageLocations returns a BackupStorageLocationInformer.
+func (v *version) BackupStorageLocations() BackupStorageLocationInformer {
+	return &backupStorageLocationInformer{factory: v.SharedInformerFactory}
+}
+
 // Configs returns a ConfigInformer.
 func (v *version) Configs() ConfigInformer {
 	return &configInformer{factory: v.SharedInformerFactory}
 }
 
+// DeleteBackupRequests returns a DeleteBackupRequestInformer.
+func (v *version) DeleteBackupRequests() DeleteBackupRequestInformer {
+	return &deleteBackupRequestInformer{factory: v.SharedInformerFactory}
+}
+
 // DownloadRequests returns a DownloadRequestInformer.
 func (v *version) DownloadRequests() DownloadRequestInformer {
 	return &downloadRequestInformer{factory: v.SharedInformerFactory}
 }
 
+// PodVolumeBackups returns a PodVolumeBackupInformer.
+func (v *version) PodVolumeBackups() PodVolumeBackupInformer {
+	return &podVolumeBackupInformer{factory: v.SharedInformerFactory}
+}
+
+// PodVolumeRestores returns a PodVolumeRestoreInformer.
+func (v *version) PodVolumeRestores() PodVolumeRestoreInformer {
+	return &podVolumeRestoreInformer{factory: v.SharedInformerFactory}
+}
+
 // Restores returns a RestoreInformer.
 func (v *version) Restores() RestoreInformer {
 	return &restoreInformer{factory: v.SharedInformerFactory}
@@ -0,0 +1,289 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ServerMetrics contains Prometheus metrics for the Ark server.
+type ServerMetrics struct {
+	metrics map[string]prometheus.Collector
+}
+
+const (
+	metricNamespace = "ark"
+
+	backupTarballSizeBytesGauge    = "backup_tarball_size_bytes"
+	backupAttemptCount             = "backup_attempt_total"
+	backupSuccessCount             = "backup_success_total"
+	backupFailureCount             = "backup_failure_total"
+	backupPartialFailureCount      = "backup_partial_failure_total"
+	backupDurationSeconds          = "backup_duration_seconds"
+	backupLastSuccessfulTimestamp  = "backup_last_successful_timestamp"
+	restoreAttemptCount            = "restore_attempt_total"
+	restoreSuccessCount            = "restore_success_total"
+	restoreFailureCount            = "restore_failure_total"
+	restoreValidationFailedCount   = "restore_validation_failed_total"
+	volumeSnapshotAttemptCount     = "volume_snapshot_attempt_total"
+	volumeSnapshotFailureCount     = "volume_snapshot_failure_total"
+	gcBackupDeletionAttemptCount   = "gc_backup_deletion_attempt_total"
+	gcBackupDeletionFailureCount   = "gc_backup_deletion_failure_total"
+	scheduleLabel                  = "schedule"
+	defaultBackupDurationBucketMin = 10
+)
+
+// NewServerMetrics returns new ServerMetrics.
+func NewServerMetrics() *ServerMetrics {
+	return &ServerMetrics{
+		metrics: map[string]prometheus.Collector{
+			backupTarballSizeBytesGauge: prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: metricNamespace,
+					Name:      backupTarballSizeBytesGauge,
+					Help:      "Size, in bytes, of a backup",
+				},
+				[]string{scheduleLabel},
+			),
+			backupAttemptCount: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Namespace: metricNamespace,
+					Name:      backupAttemptCount,
+					Help:      "Total number of attempted backups",
+				},
+				[]string{scheduleLabel},
+			),
+			backupSuccessCount: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Namespace: metricNamespace,
+					Name:      backupSuccessCount,
+					Help:      "Total number of successful backups",
+				},
+				[]string{scheduleLabel},
+			),
+			backupFailureCount: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Namespace: metricNamespace,
+					Name:      backupFailureCount,
+					Help:      "Total number of failed backups",
+				},
+				[]string{scheduleLabel},
+			),
+			backupPartialFailureCount: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Namespace: metricNamespace,
+					Name:      backupPartialFailureCount,
+					Help:      "Total number of partially failed backups",
+				},
+				[]string{scheduleLabel},
+			),
+			backupDurationSeconds: prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Namespace: metricNamespace,
+					Name:      backupDurationSeconds,
+					Help:      "Time taken to complete a backup, in seconds",
+					// 10s, 20s, 40s, ... ~2.8h
+					Buckets: prometheus.ExponentialBuckets(defaultBackupDurationBucketMin, 2, 11),
+				},
+				[]string{scheduleLabel},
+			),
+			backupLastSuccessfulTimestamp: prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: metricNamespace,
+					Name:      backupLastSuccessfulTimestamp,
+					Help:      "Last time a backup ran successfully, Unix timestamp in seconds",
+				},
+				[]string{scheduleLabel},
+			),
+			restoreAttemptCount: prometheus.NewCounter(
+				prometheus.CounterOpts{
+					Namespace: metricNamespace,
+					Name:      restoreAttemptCount,
+					Help:      "Total number of attempted restores",
+				},
+			),
+			restoreSuccessCount: prometheus.NewCounter(
+				prometheus.CounterOpts{
+					Namespace: metricNamespace,
+					Name:      restoreSuccessCount,
+					Help:      "Total number of restores that completed without errors",
+				},
+			),
+			restoreFailureCount: prometheus.NewCounter(
+				prometheus.CounterOpts{
+					Namespace: metricNamespace,
+					Name:      restoreFailureCount,
+					Help:      "Total number of restores that completed with errors",
+				},
+			),
+			restoreValidationFailedCount: prometheus.NewCounter(
+				prometheus.CounterOpts{
+					Namespace: metricNamespace,
+					Name:      restoreValidationFailedCount,
+					Help:      "Total number of restores that failed validation",
+				},
+			),
+			volumeSnapshotAttemptCount: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Namespace: metricNamespace,
+					Name:      volumeSnapshotAttemptCount,
+					Help:      "Total number of attempted volume snapshots",
+				},
+				[]string{scheduleLabel},
+			),
+			volumeSnapshotFailureCount: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Namespace: metricNamespace,
+					Name:      volumeSnapshotFailureCount,
+					Help:      "Total number of failed volume snapshots",
+				},
+				[]string{scheduleLabel},
+			),
+			gcBackupDeletionAttemptCount: prometheus.NewCounter(
+				prometheus.CounterOpts{
+					Namespace: metricNamespace,
+					Name:      gcBackupDeletionAttemptCount,
+					Help:      "Total number of expired backups the garbage collector attempted to delete",
+				},
+			),
+			gcBackupDeletionFailureCount: prometheus.NewCounter(
+				prometheus.CounterOpts{
+					Namespace: metricNamespace,
+					Name:      gcBackupDeletionFailureCount,
+					Help:      "Total number of expired backups the garbage collector failed to delete",
+				},
+			),
+		},
+	}
+}
+
+// RegisterAllMetrics registers all of the metrics with the default Prometheus registry.
+func (m *ServerMetrics) RegisterAllMetrics() {
+	for _, pm := range m.metrics {
+		prometheus.MustRegister(pm)
+	}
+}
+
+// InitSchedule initializes the per-schedule metrics so they're exported, with a value of 0,
+// before the schedule's first backup runs.
+func (m *ServerMetrics) InitSchedule(scheduleName string) {
+	for _, name := range []string{backupAttemptCount, backupSuccessCount, backupFailureCount, backupPartialFailureCount, volumeSnapshotAttemptCount, volumeSnapshotFailureCount} {
+		if c, ok := m.metrics[name].(*prometheus.CounterVec); ok {
+			c.WithLabelValues(scheduleName).Add(0)
+		}
+	}
+	if g, ok := m.metrics[backupLastSuccessfulTimestamp].(*prometheus.GaugeVec); ok {
+		g.WithLabelValues(scheduleName).Add(0)
+	}
+}
+
+// SetBackupTarballSizeBytesGauge records the size, in bytes, of a backup tarball.
+func (m *ServerMetrics) SetBackupTarballSizeBytesGauge(backupSchedule string, size int64) {
+	if g, ok := m.metrics[backupTarballSizeBytesGauge].(*prometheus.GaugeVec); ok {
+		g.WithLabelValues(backupSchedule).Set(float64(size))
+	}
+}
+
+// SetBackupLastSuccessfulTimestamp records the last time a backup ran successfully.
+func (m *ServerMetrics) SetBackupLastSuccessfulTimestamp(backupSchedule string, t time.Time) {
+	if g, ok := m.metrics[backupLastSuccessfulTimestamp].(*prometheus.GaugeVec); ok {
+		g.WithLabelValues(backupSchedule).Set(float64(t.Unix()))
+	}
+}
+
+// RegisterBackupAttempt records a backup attempt.
+func (m *ServerMetrics) RegisterBackupAttempt(backupSchedule string) {
+	m.incCounterVec(backupAttemptCount, backupSchedule)
+}
+
+// RegisterBackupSuccess records a successful backup.
+func (m *ServerMetrics) RegisterBackupSuccess(backupSchedule string) {
+	m.incCounterVec(backupSuccessCount, backupSchedule)
+}
+
+// RegisterBackupFailed records a failed backup.
+func (m *ServerMetrics) RegisterBackupFailed(backupSchedule string) {
+	m.incCounterVec(backupFailureCount, backupSchedule)
+}
+
+// RegisterBackupPartialFailure records a backup that completed with errors backing up some items.
+func (m *ServerMetrics) RegisterBackupPartialFailure(backupSchedule string) {
+	m.incCounterVec(backupPartialFailureCount, backupSchedule)
+}
+
+// RegisterBackupDuration records the number of seconds a backup took.
+func (m *ServerMetrics) RegisterBackupDuration(backupSchedule string, seconds float64) {
+	if h, ok := m.metrics[backupDurationSeconds].(*prometheus.HistogramVec); ok {
+		h.WithLabelValues(backupSchedule).Observe(seconds)
+	}
+}
+
+// RegisterRestoreAttempt records a restore attempt.
+func (m *ServerMetrics) RegisterRestoreAttempt() {
+	m.incCounter(restoreAttemptCount)
+}
+
+// RegisterRestoreSuccess records a restore that completed without errors.
+func (m *ServerMetrics) RegisterRestoreSuccess() {
+	m.incCounter(restoreSuccessCount)
+}
+
+// RegisterRestoreFailed records a restore that completed with errors.
+func (m *ServerMetrics) RegisterRestoreFailed() {
+	m.incCounter(restoreFailureCount)
+}
+
+// RegisterRestoreValidationFailed records a restore that failed validation.
+func (m *ServerMetrics) RegisterRestoreValidationFailed() {
+	m.incCounter(restoreValidationFailedCount)
+}
+
+// RegisterVolumeSnapshotAttempt records a volume snapshot attempt.
+func (m *ServerMetrics) RegisterVolumeSnapshotAttempt(backupSchedule string) {
+	m.incCounterVec(volumeSnapshotAttemptCount, backupSchedule)
+}
+
+// RegisterVolumeSnapshotFailed records a failed volume snapshot.
+func (m *ServerMetrics) RegisterVolumeSnapshotFailed(backupSchedule string) {
+	m.incCounterVec(volumeSnapshotFailureCount, backupSchedule)
+}
+
+// RegisterGCBackupDeletionAttempt records an attempt by the garbage collector to delete an
+// expired backup.
+func (m *ServerMetrics) RegisterGCBackupDeletionAttempt() {
+	m.incCounter(gcBackupDeletionAttemptCount)
+}
+
+// RegisterGCBackupDeletionFailed records a failure by the garbage collector to delete an
+// expired backup.
+func (m *ServerMetrics) RegisterGCBackupDeletionFailed() {
+	m.incCounter(gcBackupDeletionFailureCount)
+}
+
+func (m *ServerMetrics) incCounter(name string) {
+	if c, ok := m.metrics[name].(prometheus.Counter); ok {
+		c.Inc()
+	}
+}
+
+func (m *ServerMetrics) incCounterVec(name, label string) {
+	if c, ok := m.metrics[name].(*prometheus.CounterVec); ok {
+		c.WithLabelValues(label).Inc()
+	}
+}
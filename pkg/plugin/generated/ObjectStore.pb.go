@@ -92,6 +92,7 @@ func (m *Bytes) GetData() []byte {
 type ListCommonPrefixesRequest struct {
 	Bucket    string `protobuf:"bytes,1,opt,name=bucket" json:"bucket,omitempty"`
 	Delimiter string `protobuf:"bytes,2,opt,name=delimiter" json:"delimiter,omitempty"`
+	Prefix    string `protobuf:"bytes,3,opt,name=prefix" json:"prefix,omitempty"`
 }
 
 func (m *ListCommonPrefixesRequest) Reset()                    { *m = ListCommonPrefixesRequest{} }
@@ -113,6 +114,13 @@ func (m *ListCommonPrefixesRequest) GetDelimiter() string {
 	return ""
 }
 
+func (m *ListCommonPrefixesRequest) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
 type ListCommonPrefixesResponse struct {
 	Prefixes []string `protobuf:"bytes,1,rep,name=prefixes" json:"prefixes,omitempty"`
 }
@@ -589,33 +597,33 @@ var _ObjectStore_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("ObjectStore.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 444 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x94, 0xdf, 0x8b, 0xd3, 0x40,
-	0x10, 0xc7, 0x89, 0xa9, 0xc5, 0xcc, 0x15, 0x8c, 0x73, 0x50, 0x6b, 0x4e, 0xa5, 0x2e, 0x0a, 0x15,
-	0xa1, 0x1c, 0xfa, 0xe2, 0xc3, 0x81, 0xe2, 0x9d, 0x88, 0x50, 0xb0, 0xa6, 0x0a, 0xbe, 0xa6, 0x97,
-	0xf1, 0x8c, 0xcd, 0x2f, 0x37, 0x13, 0x30, 0xff, 0x81, 0x7f, 0xb6, 0x64, 0xb3, 0xc6, 0x4d, 0x2e,
-	0x67, 0xf1, 0xde, 0x66, 0x67, 0xe7, 0xfb, 0x9d, 0xc9, 0xce, 0x87, 0xc0, 0x9d, 0x0f, 0xdb, 0xef,
-	0x74, 0xce, 0x1b, 0xce, 0x24, 0x2d, 0x73, 0x99, 0x71, 0x86, 0xce, 0x05, 0xa5, 0x24, 0x03, 0xa6,
-	0xd0, 0x9b, 0x6c, 0xbe, 0x05, 0x92, 0xc2, 0xe6, 0x42, 0xac, 0xc1, 0x5d, 0x97, 0xdc, 0x08, 0x7c,
-	0xfa, 0x51, 0x52, 0xc1, 0x38, 0x85, 0xf1, 0xb6, 0x3c, 0xdf, 0x11, 0xcf, 0xac, 0xb9, 0xb5, 0x70,
-	0x7c, 0x7d, 0x42, 0x17, 0xec, 0x1d, 0x55, 0xb3, 0x1b, 0x2a, 0x59, 0x87, 0x88, 0x30, 0xda, 0x66,
-	0x61, 0x35, 0xb3, 0xe7, 0xd6, 0x62, 0xe2, 0xab, 0x58, 0x9c, 0x80, 0xfb, 0x8e, 0xae, 0xeb, 0x28,
-	0x8e, 0xe0, 0xe6, 0x9b, 0x8a, 0xa9, 0xa8, 0xad, 0xc3, 0x80, 0x03, 0x25, 0x98, 0xf8, 0x2a, 0x16,
-	0x1f, 0xe1, 0xde, 0x2a, 0x2a, 0xf8, 0x34, 0x4b, 0x92, 0x2c, 0x5d, 0x4b, 0xfa, 0x1a, 0xfd, 0xa4,
-	0x62, 0x5f, 0x8f, 0xfb, 0xe0, 0x84, 0x14, 0x47, 0x49, 0xc4, 0x24, 0x75, 0xa7, 0xbf, 0x09, 0xf1,
-	0x12, 0xbc, 0x21, 0xcb, 0x22, 0xcf, 0xd2, 0x82, 0xd0, 0x83, 0x5b, 0xb9, 0xce, 0xcd, 0xac, 0xb9,
-	0xbd, 0x70, 0xfc, 0xf6, 0x2c, 0xce, 0x00, 0x6b, 0x65, 0xf3, 0xa1, 0x7b, 0xa7, 0x98, 0xc2, 0xb8,
-	0x51, 0xea, 0x11, 0xf4, 0x49, 0x3c, 0x85, 0xc3, 0x8e, 0x8b, 0x6e, 0x8c, 0x30, 0xda, 0x51, 0xf5,
-	0xa7, 0xa9, 0x8a, 0xc5, 0x2b, 0x38, 0x3c, 0xa3, 0x98, 0x98, 0xae, 0xfb, 0xb6, 0x9f, 0x60, 0x7a,
-	0x2a, 0x29, 0x60, 0xda, 0x44, 0x17, 0x29, 0x85, 0x9f, 0xfd, 0xd5, 0xff, 0x6f, 0xdc, 0x05, 0x9b,
-	0x39, 0x56, 0x0b, 0xb7, 0xfd, 0x3a, 0x14, 0xcf, 0xe0, 0xee, 0x25, 0x57, 0xfd, 0x15, 0x2e, 0xd8,
-	0xa5, 0x8c, 0xb5, 0x67, 0x1d, 0x3e, 0xff, 0x35, 0x82, 0x03, 0x83, 0x4e, 0x3c, 0x86, 0xd1, 0xfb,
-	0x34, 0x62, 0x9c, 0x2e, 0x5b, 0x40, 0x97, 0x75, 0x42, 0x0f, 0xe6, 0xb9, 0x46, 0xfe, 0x6d, 0x92,
-	0x73, 0x85, 0x27, 0xe0, 0xb4, 0xc0, 0xe2, 0x91, 0x71, 0xdd, 0xc7, 0xf8, 0xb2, 0x76, 0x61, 0xd5,
-	0xea, 0x16, 0xce, 0x8e, 0xba, 0x8f, 0x6c, 0x47, 0xad, 0x88, 0x3c, 0xb6, 0x30, 0x68, 0x56, 0xde,
-	0x85, 0x05, 0x1f, 0x1b, 0x95, 0x57, 0xe2, 0xe9, 0x3d, 0xd9, 0x53, 0xa5, 0x9f, 0x6c, 0x05, 0x07,
-	0x06, 0x0f, 0xf8, 0xa0, 0xa7, 0xea, 0xd2, 0xe6, 0x3d, 0xbc, 0xea, 0x5a, 0xbb, 0xbd, 0x86, 0x89,
-	0x89, 0x0c, 0x9a, 0xf5, 0x03, 0x2c, 0x0d, 0x3c, 0xf7, 0x17, 0xb8, 0xdd, 0xdb, 0x2e, 0x3e, 0x32,
-	0x8a, 0x86, 0x79, 0xf2, 0xc4, 0xbf, 0x4a, 0x9a, 0xd9, 0xb6, 0x63, 0xf5, 0x03, 0x7a, 0xf1, 0x3b,
-	0x00, 0x00, 0xff, 0xff, 0xf3, 0xb2, 0x85, 0x9a, 0xae, 0x04, 0x00, 0x00,
+	// 446 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x94, 0xd1, 0x8b, 0xd3, 0x40,
+	0x10, 0xc6, 0xc9, 0xa5, 0x1e, 0x66, 0xae, 0x60, 0x9c, 0x83, 0x5a, 0x73, 0x2a, 0x75, 0x51, 0xa8,
+	0x08, 0xe5, 0xd0, 0x17, 0x1f, 0x0e, 0x14, 0xef, 0xa4, 0x08, 0x05, 0xcb, 0x56, 0xc1, 0xd7, 0xf4,
+	0x32, 0x9e, 0xb1, 0x6d, 0x12, 0x37, 0x53, 0x30, 0xff, 0x81, 0x7f, 0xb6, 0xec, 0x66, 0xed, 0x6d,
+	0xdb, 0xdc, 0x95, 0xeb, 0xdb, 0xec, 0xec, 0x7c, 0xdf, 0xb7, 0x99, 0xfe, 0x28, 0x3c, 0xfc, 0x32,
+	0xfd, 0x45, 0x97, 0x3c, 0xe1, 0x5c, 0xd1, 0xa0, 0x50, 0x39, 0xe7, 0x18, 0x5c, 0x51, 0x46, 0x2a,
+	0x66, 0x4a, 0xa2, 0xf6, 0xe4, 0x67, 0xac, 0x28, 0xa9, 0x2f, 0xc4, 0x18, 0xc2, 0xf1, 0x92, 0x6b,
+	0x81, 0xa4, 0xdf, 0x4b, 0x2a, 0x19, 0x3b, 0x70, 0x38, 0x5d, 0x5e, 0xce, 0x88, 0xbb, 0x5e, 0xcf,
+	0xeb, 0x07, 0xd2, 0x9e, 0x30, 0x04, 0x7f, 0x46, 0x55, 0xf7, 0xc0, 0x34, 0x75, 0x89, 0x08, 0xad,
+	0x69, 0x9e, 0x54, 0x5d, 0xbf, 0xe7, 0xf5, 0xdb, 0xd2, 0xd4, 0xe2, 0x0c, 0xc2, 0x21, 0xed, 0xeb,
+	0x28, 0x4e, 0xe0, 0xde, 0xc7, 0x8a, 0xa9, 0xd4, 0xd6, 0x49, 0xcc, 0xb1, 0x11, 0xb4, 0xa5, 0xa9,
+	0x45, 0x0a, 0x8f, 0x47, 0x69, 0xc9, 0xe7, 0xf9, 0x62, 0x91, 0x67, 0x63, 0x45, 0x3f, 0xd2, 0x3f,
+	0x54, 0xee, 0xca, 0x78, 0x02, 0x41, 0x42, 0xf3, 0x74, 0x91, 0x32, 0x29, 0x9b, 0x74, 0xdd, 0xd0,
+	0xaa, 0xc2, 0x18, 0x99, 0x6f, 0x08, 0xa4, 0x3d, 0x89, 0x77, 0x10, 0x35, 0x45, 0x95, 0x45, 0x9e,
+	0x95, 0x84, 0x11, 0xdc, 0x2f, 0x6c, 0xaf, 0xeb, 0xf5, 0xfc, 0x7e, 0x20, 0x57, 0x67, 0x71, 0x01,
+	0xa8, 0x95, 0xf5, 0x02, 0x76, 0xbe, 0xee, 0x3a, 0xff, 0x60, 0x2d, 0xff, 0x15, 0x1c, 0xaf, 0xb9,
+	0xd8, 0x60, 0x84, 0xd6, 0x8c, 0xaa, 0xff, 0xa1, 0xa6, 0x16, 0xef, 0xe1, 0xf8, 0x82, 0xe6, 0xc4,
+	0xb4, 0xef, 0xce, 0xbf, 0x42, 0xe7, 0x5c, 0x51, 0xcc, 0x34, 0x49, 0xaf, 0x32, 0x4a, 0xbe, 0xc9,
+	0xd1, 0xdd, 0x49, 0x08, 0xc1, 0x67, 0x9e, 0x9b, 0x25, 0xfa, 0x52, 0x97, 0xe2, 0x35, 0x3c, 0xda,
+	0x72, 0xb5, 0x5f, 0x11, 0x82, 0xbf, 0x54, 0x73, 0xeb, 0xa9, 0xcb, 0x37, 0x7f, 0x5b, 0x70, 0xe4,
+	0x50, 0x8b, 0xa7, 0xd0, 0xfa, 0x9c, 0xa5, 0x8c, 0x9d, 0xc1, 0x0a, 0xdc, 0x81, 0x6e, 0xd8, 0x87,
+	0x45, 0xa1, 0xd3, 0xff, 0xb4, 0x28, 0xb8, 0xc2, 0x33, 0x08, 0x56, 0x20, 0xe3, 0x89, 0x73, 0xbd,
+	0x89, 0xf7, 0xb6, 0xb6, 0xef, 0x69, 0xf5, 0x90, 0x9a, 0xd4, 0x43, 0xba, 0x45, 0x6d, 0x48, 0x3d,
+	0xf5, 0x30, 0x06, 0xdc, 0x86, 0x05, 0x5f, 0x38, 0x93, 0x37, 0x62, 0x1b, 0xbd, 0xdc, 0x31, 0x65,
+	0x57, 0x36, 0x82, 0x23, 0x87, 0x07, 0x7c, 0xba, 0xa1, 0x5a, 0xa7, 0x2d, 0x7a, 0x76, 0xd3, 0xb5,
+	0x75, 0xfb, 0x00, 0x6d, 0x17, 0x19, 0x74, 0xe7, 0x1b, 0x58, 0x6a, 0x58, 0xf7, 0x77, 0x78, 0xb0,
+	0xf1, 0xeb, 0xe2, 0x73, 0x67, 0xa8, 0x99, 0xa7, 0x48, 0xdc, 0x36, 0x52, 0xbf, 0x6d, 0x7a, 0x68,
+	0xfe, 0x98, 0xde, 0xfe, 0x1b, 0x00, 0xba, 0x34, 0xb5, 0xeb, 0xc6, 0x04, 0x00, 0x00,
 }
@@ -18,7 +18,7 @@ type Empty struct {
 func (m *Empty) Reset()                    { *m = Empty{} }
 func (m *Empty) String() string            { return proto.CompactTextString(m) }
 func (*Empty) ProtoMessage()               {}
-func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{0} }
+func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{0} }
 
 type InitRequest struct {
 	Config map[string]string `protobuf:"bytes,1,rep,name=config" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
@@ -27,7 +27,7 @@ type InitRequest struct {
 func (m *InitRequest) Reset()                    { *m = InitRequest{} }
 func (m *InitRequest) String() string            { return proto.CompactTextString(m) }
 func (*InitRequest) ProtoMessage()               {}
-func (*InitRequest) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{1} }
+func (*InitRequest) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{1} }
 
 func (m *InitRequest) GetConfig() map[string]string {
 	if m != nil {
@@ -36,23 +36,78 @@ func (m *InitRequest) GetConfig() map[string]string {
 	return nil
 }
 
+type AppliesToResponse struct {
+	IncludedNamespaces []string `protobuf:"bytes,1,rep,name=includedNamespaces" json:"includedNamespaces,omitempty"`
+	ExcludedNamespaces []string `protobuf:"bytes,2,rep,name=excludedNamespaces" json:"excludedNamespaces,omitempty"`
+	IncludedResources  []string `protobuf:"bytes,3,rep,name=includedResources" json:"includedResources,omitempty"`
+	ExcludedResources  []string `protobuf:"bytes,4,rep,name=excludedResources" json:"excludedResources,omitempty"`
+	Selector           string   `protobuf:"bytes,5,opt,name=selector" json:"selector,omitempty"`
+}
+
+func (m *AppliesToResponse) Reset()                    { *m = AppliesToResponse{} }
+func (m *AppliesToResponse) String() string            { return proto.CompactTextString(m) }
+func (*AppliesToResponse) ProtoMessage()               {}
+func (*AppliesToResponse) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{2} }
+
+func (m *AppliesToResponse) GetIncludedNamespaces() []string {
+	if m != nil {
+		return m.IncludedNamespaces
+	}
+	return nil
+}
+
+func (m *AppliesToResponse) GetExcludedNamespaces() []string {
+	if m != nil {
+		return m.ExcludedNamespaces
+	}
+	return nil
+}
+
+func (m *AppliesToResponse) GetIncludedResources() []string {
+	if m != nil {
+		return m.IncludedResources
+	}
+	return nil
+}
+
+func (m *AppliesToResponse) GetExcludedResources() []string {
+	if m != nil {
+		return m.ExcludedResources
+	}
+	return nil
+}
+
+func (m *AppliesToResponse) GetSelector() string {
+	if m != nil {
+		return m.Selector
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Empty)(nil), "generated.Empty")
 	proto.RegisterType((*InitRequest)(nil), "generated.InitRequest")
+	proto.RegisterType((*AppliesToResponse)(nil), "generated.AppliesToResponse")
 }
 
-func init() { proto.RegisterFile("Shared.proto", fileDescriptor3) }
-
-var fileDescriptor3 = []byte{
-	// 156 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x09, 0xce, 0x48, 0x2c,
-	0x4a, 0x4d, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x4c, 0x4f, 0xcd, 0x4b, 0x2d, 0x4a,
-	0x2c, 0x49, 0x4d, 0x51, 0x62, 0xe7, 0x62, 0x75, 0xcd, 0x2d, 0x28, 0xa9, 0x54, 0x6a, 0x61, 0xe4,
-	0xe2, 0xf6, 0xcc, 0xcb, 0x2c, 0x09, 0x4a, 0x2d, 0x2c, 0x4d, 0x2d, 0x2e, 0x11, 0xb2, 0xe2, 0x62,
-	0x4b, 0xce, 0xcf, 0x4b, 0xcb, 0x4c, 0x97, 0x60, 0x54, 0x60, 0xd6, 0xe0, 0x36, 0x52, 0xd2, 0x83,
-	0x6b, 0xd2, 0x43, 0x52, 0xa7, 0xe7, 0x0c, 0x56, 0xe4, 0x9a, 0x57, 0x52, 0x54, 0x19, 0x04, 0xd5,
-	0x21, 0x65, 0xc9, 0xc5, 0x8d, 0x24, 0x2c, 0x24, 0xc0, 0xc5, 0x9c, 0x9d, 0x5a, 0x29, 0xc1, 0xa8,
-	0xc0, 0xa8, 0xc1, 0x19, 0x04, 0x62, 0x0a, 0x89, 0x70, 0xb1, 0x96, 0x25, 0xe6, 0x94, 0xa6, 0x4a,
-	0x30, 0x81, 0xc5, 0x20, 0x1c, 0x2b, 0x26, 0x0b, 0xc6, 0x24, 0x36, 0xb0, 0x0b, 0x8d, 0x01, 0x01,
-	0x00, 0x00, 0xff, 0xff, 0x85, 0xab, 0x54, 0x37, 0xb1, 0x00, 0x00, 0x00,
+func init() { proto.RegisterFile("Shared.proto", fileDescriptor4) }
+
+var fileDescriptor4 = []byte{
+	// 255 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0xd1, 0xb1, 0x4e, 0xc3, 0x30,
+	0x14, 0x05, 0x50, 0xb9, 0x21, 0x85, 0xbc, 0x30, 0x50, 0x8b, 0x21, 0xea, 0x54, 0x65, 0xea, 0x80,
+	0x32, 0xc0, 0x02, 0xdd, 0x10, 0xea, 0xc0, 0xc2, 0x60, 0xf8, 0x01, 0x93, 0x5c, 0x4a, 0x44, 0x6a,
+	0x1b, 0xdb, 0x41, 0xcd, 0xce, 0xdf, 0xf2, 0x13, 0x28, 0x0e, 0x54, 0x15, 0x61, 0xcb, 0xbb, 0xf7,
+	0xbc, 0xc8, 0x96, 0xe9, 0xf4, 0xf1, 0x55, 0x5a, 0x54, 0x85, 0xb1, 0xda, 0x6b, 0x9e, 0x6c, 0xa0,
+	0x60, 0xa5, 0x47, 0x95, 0x1f, 0x53, 0xbc, 0xde, 0x1a, 0xdf, 0xe5, 0x9f, 0x8c, 0xd2, 0x7b, 0x55,
+	0x7b, 0x81, 0xf7, 0x16, 0xce, 0xf3, 0x15, 0x4d, 0x4b, 0xad, 0x5e, 0xea, 0x4d, 0xc6, 0x16, 0xd1,
+	0x32, 0xbd, 0xcc, 0x8b, 0xfd, 0x52, 0x71, 0xe0, 0x8a, 0xbb, 0x80, 0xd6, 0xca, 0xdb, 0x4e, 0xfc,
+	0x6c, 0xcc, 0x6f, 0x28, 0x3d, 0x88, 0xf9, 0x19, 0x45, 0x6f, 0xe8, 0x32, 0xb6, 0x60, 0xcb, 0x44,
+	0xf4, 0x9f, 0xfc, 0x9c, 0xe2, 0x0f, 0xd9, 0xb4, 0xc8, 0x26, 0x21, 0x1b, 0x86, 0xd5, 0xe4, 0x9a,
+	0xe5, 0x5f, 0x8c, 0x66, 0xb7, 0xc6, 0x34, 0x35, 0xdc, 0x93, 0x16, 0x70, 0x46, 0x2b, 0x07, 0x5e,
+	0x10, 0xaf, 0x55, 0xd9, 0xb4, 0x15, 0xaa, 0x07, 0xb9, 0x85, 0x33, 0xb2, 0x84, 0x0b, 0x07, 0x4b,
+	0xc4, 0x3f, 0x4d, 0xef, 0xb1, 0x1b, 0xf9, 0xc9, 0xe0, 0xc7, 0x0d, 0xbf, 0xa0, 0xd9, 0xef, 0x5f,
+	0x04, 0x9c, 0x6e, 0x6d, 0xcf, 0xa3, 0xc0, 0xc7, 0x45, 0xaf, 0xb1, 0xfb, 0x13, 0x66, 0x47, 0x83,
+	0x1e, 0x15, 0x7c, 0x4e, 0x27, 0x0e, 0x0d, 0x4a, 0xaf, 0x6d, 0x16, 0x87, 0xeb, 0xee, 0xe7, 0xe7,
+	0x69, 0x78, 0x8f, 0xab, 0xef, 0x01, 0x00, 0x19, 0xd7, 0x88, 0x92, 0x9f, 0x01, 0x00, 0x00,
 }
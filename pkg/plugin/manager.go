@@ -22,6 +22,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/go-hclog"
 	plugin "github.com/hashicorp/go-plugin"
@@ -30,6 +31,7 @@ import (
 
 	"github.com/heptio/ark/pkg/backup"
 	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/restore"
 )
 
 // PluginKind is a type alias for a string that describes
@@ -71,6 +73,10 @@ const (
 	// a Backup ItemAction plugin.
 	PluginKindBackupItemAction PluginKind = "backupitemaction"
 
+	// PluginKindRestoreItemAction is the Kind string for
+	// a Restore ItemAction plugin.
+	PluginKindRestoreItemAction PluginKind = "restoreitemaction"
+
 	pluginDir = "/plugins"
 )
 
@@ -79,6 +85,7 @@ var AllPluginKinds = []PluginKind{
 	PluginKindBlockStore,
 	PluginKindCloudProvider,
 	PluginKindBackupItemAction,
+	PluginKindRestoreItemAction,
 }
 
 type pluginInfo struct {
@@ -95,6 +102,12 @@ type Manager interface {
 	// cloudprovider.ObjectStore interface with the specified name.
 	GetObjectStore(name string) (cloudprovider.ObjectStore, error)
 
+	// GetObjectStoreForLocation returns a separate instance of the
+	// cloudprovider.ObjectStore plugin with the specified name for the
+	// named backup storage location, so that it can be initialized with
+	// the location's own config.
+	GetObjectStoreForLocation(name, location string) (cloudprovider.ObjectStore, error)
+
 	// GetBlockStore returns the plugin implementation of the
 	// cloudprovider.BlockStore interface with the specified name.
 	GetBlockStore(name string) (cloudprovider.BlockStore, error)
@@ -109,12 +122,27 @@ type Manager interface {
 	// CloseBackupItemActions terminates the plugin sub-processes that
 	// are hosting BackupItemAction plugins for the given backup name.
 	CloseBackupItemActions(backupName string) error
+
+	// GetRestoreItemActions returns all restore.ItemAction plugins.
+	// These plugin instances should ONLY be used for a single restore
+	// (mainly because each one outputs to a per-restore log),
+	// and should be terminated upon completion of the restore with
+	// CloseRestoreItemActions().
+	GetRestoreItemActions(restoreName string, logger logrus.FieldLogger, level logrus.Level) ([]restore.ItemAction, error)
+
+	// CloseRestoreItemActions terminates the plugin sub-processes that
+	// are hosting RestoreItemAction plugins for the given restore name.
+	CloseRestoreItemActions(restoreName string) error
 }
 
 type manager struct {
 	logger         hclog.Logger
 	pluginRegistry *registry
 	clientStore    *clientStore
+	// clientLock makes getting or creating the plugin clients for a given
+	// kind/name/scope atomic, so concurrent callers (e.g. backups running in
+	// parallel) don't start duplicate plugin sub-processes.
+	clientLock sync.Mutex
 }
 
 // NewManager constructs a manager for getting plugin implementations.
@@ -159,10 +187,11 @@ func getPluginInstance(client *plugin.Client, kind PluginKind) (interface{}, err
 
 func (m *manager) registerPlugins() error {
 	// first, register internal plugins
-	for _, provider := range []string{"aws", "gcp", "azure"} {
+	for _, provider := range []string{"aws", "gcp", "azure", "filesystem"} {
 		m.pluginRegistry.register(provider, "/ark", []string{"plugin", "cloudprovider", provider}, PluginKindObjectStore, PluginKindBlockStore)
 	}
 	m.pluginRegistry.register("backup_pv", "/ark", []string{"plugin", string(PluginKindBackupItemAction), "backup_pv"}, PluginKindBackupItemAction)
+	m.pluginRegistry.register("backup_pod", "/ark", []string{"plugin", string(PluginKindBackupItemAction), "backup_pod"}, PluginKindBackupItemAction)
 
 	// second, register external plugins (these will override internal plugins, if applicable)
 	if _, err := os.Stat(pluginDir); err != nil {
@@ -206,7 +235,17 @@ func parse(filename string) (string, PluginKind, error) {
 // GetObjectStore returns the plugin implementation of the cloudprovider.ObjectStore
 // interface with the specified name.
 func (m *manager) GetObjectStore(name string) (cloudprovider.ObjectStore, error) {
-	pluginObj, err := m.getCloudProviderPlugin(name, PluginKindObjectStore)
+	return m.getObjectStore(name, "")
+}
+
+// GetObjectStoreForLocation returns a separate instance of the ObjectStore
+// plugin with the specified name for the named backup storage location.
+func (m *manager) GetObjectStoreForLocation(name, location string) (cloudprovider.ObjectStore, error) {
+	return m.getObjectStore(name, location)
+}
+
+func (m *manager) getObjectStore(name, scope string) (cloudprovider.ObjectStore, error) {
+	pluginObj, err := m.getCloudProviderPlugin(name, PluginKindObjectStore, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +261,7 @@ func (m *manager) GetObjectStore(name string) (cloudprovider.ObjectStore, error)
 // GetBlockStore returns the plugin implementation of the cloudprovider.BlockStore
 // interface with the specified name.
 func (m *manager) GetBlockStore(name string) (cloudprovider.BlockStore, error) {
-	pluginObj, err := m.getCloudProviderPlugin(name, PluginKindBlockStore)
+	pluginObj, err := m.getCloudProviderPlugin(name, PluginKindBlockStore, "")
 	if err != nil {
 		return nil, err
 	}
@@ -235,8 +274,29 @@ func (m *manager) GetBlockStore(name string) (cloudprovider.BlockStore, error) {
 	return blockStore, nil
 }
 
-func (m *manager) getCloudProviderPlugin(name string, kind PluginKind) (interface{}, error) {
-	client, err := m.clientStore.get(kind, name, "")
+// getCloudProviderPlugin returns an instance of the cloud provider plugin with the given name
+// and kind. Each scope gets its own plugin client.
+func (m *manager) getCloudProviderPlugin(name string, kind PluginKind, scope string) (interface{}, error) {
+	client, err := m.getCloudProviderClient(name, kind, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	pluginObj, err := getPluginInstance(client, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return pluginObj, nil
+}
+
+// getCloudProviderClient returns the plugin client for the cloud provider plugin with the
+// given name, kind and scope, creating it if it doesn't exist.
+func (m *manager) getCloudProviderClient(name string, kind PluginKind, scope string) (*plugin.Client, error) {
+	m.clientLock.Lock()
+	defer m.clientLock.Unlock()
+
+	client, err := m.clientStore.get(kind, name, scope)
 	if err != nil {
 		pluginInfo, err := m.pluginRegistry.get(kind, name)
 		if err != nil {
@@ -255,16 +315,11 @@ func (m *manager) getCloudProviderPlugin(name string, kind PluginKind) (interfac
 
 		// register the plugin client for the appropriate kinds
 		for _, kind := range pluginInfo.kinds {
-			m.clientStore.add(client, kind, name, "")
+			m.clientStore.add(client, kind, name, scope)
 		}
 	}
 
-	pluginObj, err := getPluginInstance(client, kind)
-	if err != nil {
-		return nil, err
-	}
-
-	return pluginObj, nil
+	return client, nil
 }
 
 // GetBackupActions returns all backup.BackupAction plugins.
@@ -273,6 +328,35 @@ func (m *manager) getCloudProviderPlugin(name string, kind PluginKind) (interfac
 // and should be terminated upon completion of the backup with
 // CloseBackupActions().
 func (m *manager) GetBackupItemActions(backupName string, logger logrus.FieldLogger, level logrus.Level) ([]backup.ItemAction, error) {
+	clients, err := m.getBackupItemActionClients(backupName, logger, level)
+	if err != nil {
+		return nil, err
+	}
+
+	var backupActions []backup.ItemAction
+	for _, client := range clients {
+		plugin, err := getPluginInstance(client, PluginKindBackupItemAction)
+		if err != nil {
+			return nil, err
+		}
+
+		backupAction, ok := plugin.(backup.ItemAction)
+		if !ok {
+			return nil, errors.New("could not convert gRPC client to backup.BackupAction")
+		}
+
+		backupActions = append(backupActions, backupAction)
+	}
+
+	return backupActions, nil
+}
+
+// getBackupItemActionClients returns the plugin clients hosting the BackupItemAction
+// plugins for the given backup name, creating them if they don't exist.
+func (m *manager) getBackupItemActionClients(backupName string, logger logrus.FieldLogger, level logrus.Level) ([]*plugin.Client, error) {
+	m.clientLock.Lock()
+	defer m.clientLock.Unlock()
+
 	clients, err := m.clientStore.list(PluginKindBackupItemAction, backupName)
 	if err != nil {
 		pluginInfo, err := m.pluginRegistry.list(PluginKindBackupItemAction)
@@ -296,28 +380,102 @@ func (m *manager) GetBackupItemActions(backupName string, logger logrus.FieldLog
 		}
 	}
 
-	var backupActions []backup.ItemAction
+	return clients, nil
+}
+
+// CloseBackupItemActions terminates the plugin sub-processes that
+// are hosting BackupItemAction plugins for the given backup name.
+func (m *manager) CloseBackupItemActions(backupName string) error {
+	m.clientLock.Lock()
+	defer m.clientLock.Unlock()
+
+	clients, err := m.clientStore.list(PluginKindBackupItemAction, backupName)
+	if err != nil {
+		return err
+	}
+
 	for _, client := range clients {
-		plugin, err := getPluginInstance(client, PluginKindBackupItemAction)
+		client.Kill()
+	}
+
+	m.clientStore.deleteAll(PluginKindBackupItemAction, backupName)
+
+	return nil
+}
+
+// GetRestoreItemActions returns all restore.ItemAction plugins.
+// These plugin instances should ONLY be used for a single restore
+// (mainly because each one outputs to a per-restore log),
+// and should be terminated upon completion of the restore with
+// CloseRestoreItemActions().
+func (m *manager) GetRestoreItemActions(restoreName string, logger logrus.FieldLogger, level logrus.Level) ([]restore.ItemAction, error) {
+	clients, err := m.getRestoreItemActionClients(restoreName, logger, level)
+	if err != nil {
+		return nil, err
+	}
+
+	var restoreActions []restore.ItemAction
+	for _, client := range clients {
+		plugin, err := getPluginInstance(client, PluginKindRestoreItemAction)
 		if err != nil {
 			return nil, err
 		}
 
-		backupAction, ok := plugin.(backup.ItemAction)
+		restoreAction, ok := plugin.(restore.ItemAction)
 		if !ok {
-			return nil, errors.New("could not convert gRPC client to backup.BackupAction")
+			return nil, errors.New("could not convert gRPC client to restore.ItemAction")
 		}
 
-		backupActions = append(backupActions, backupAction)
+		restoreActions = append(restoreActions, restoreAction)
 	}
 
-	return backupActions, nil
+	return restoreActions, nil
 }
 
-// CloseBackupItemActions terminates the plugin sub-processes that
-// are hosting BackupItemAction plugins for the given backup name.
-func (m *manager) CloseBackupItemActions(backupName string) error {
-	clients, err := m.clientStore.list(PluginKindBackupItemAction, backupName)
+// getRestoreItemActionClients returns the plugin clients hosting the RestoreItemAction
+// plugins for the given restore name, creating them if they don't exist.
+func (m *manager) getRestoreItemActionClients(restoreName string, logger logrus.FieldLogger, level logrus.Level) ([]*plugin.Client, error) {
+	m.clientLock.Lock()
+	defer m.clientLock.Unlock()
+
+	clients, err := m.clientStore.list(PluginKindRestoreItemAction, restoreName)
+	if err != nil {
+		pluginInfo, err := m.pluginRegistry.list(PluginKindRestoreItemAction)
+		if err == errPluginsNotFound {
+			// unlike backup item actions, there are no built-in restore item
+			// actions, so it's valid for none to be registered.
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// create clients for each, using the provided logger
+		log := &logrusAdapter{impl: logger, level: level}
+
+		for _, plugin := range pluginInfo {
+			client := newClientBuilder(baseConfig()).
+				withCommand(plugin.commandName, plugin.commandArgs...).
+				withPlugin(PluginKindRestoreItemAction, &RestoreItemActionPlugin{log: log}).
+				withLogger(log).
+				client()
+
+			m.clientStore.add(client, PluginKindRestoreItemAction, plugin.name, restoreName)
+
+			clients = append(clients, client)
+		}
+	}
+
+	return clients, nil
+}
+
+// CloseRestoreItemActions terminates the plugin sub-processes that
+// are hosting RestoreItemAction plugins for the given restore name.
+func (m *manager) CloseRestoreItemActions(restoreName string) error {
+	m.clientLock.Lock()
+	defer m.clientLock.Unlock()
+
+	clients, err := m.clientStore.list(PluginKindRestoreItemAction, restoreName)
 	if err != nil {
 		return err
 	}
@@ -326,7 +484,7 @@ func (m *manager) CloseBackupItemActions(backupName string) error {
 		client.Kill()
 	}
 
-	m.clientStore.deleteAll(PluginKindBackupItemAction, backupName)
+	m.clientStore.deleteAll(PluginKindRestoreItemAction, restoreName)
 
 	return nil
 }
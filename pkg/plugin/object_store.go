@@ -75,7 +75,13 @@ func (c *ObjectStoreGRPCClient) Init(config map[string]string) error {
 // PutObject creates a new object using the data in body within the specified
 // object storage bucket with the given key.
 func (c *ObjectStoreGRPCClient) PutObject(bucket, key string, body io.Reader) error {
-	stream, err := c.grpcClient.PutObject(context.Background())
+	// body may be a stream that's still being written (e.g. a backup tarball), so if reading
+	// from it fails, the stream is canceled rather than closed so the plugin doesn't store a
+	// truncated object.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := c.grpcClient.PutObject(ctx)
 	if err != nil {
 		return err
 	}
@@ -85,16 +91,16 @@ func (c *ObjectStoreGRPCClient) PutObject(bucket, key string, body io.Reader) er
 	chunk := make([]byte, byteChunkSize)
 	for {
 		n, err := body.Read(chunk)
+		if n > 0 {
+			if err := stream.Send(&proto.PutObjectRequest{Bucket: bucket, Key: key, Body: chunk[0:n]}); err != nil {
+				return err
+			}
+		}
 		if err == io.EOF {
 			_, resErr := stream.CloseAndRecv()
 			return resErr
 		}
 		if err != nil {
-			stream.CloseSend()
-			return err
-		}
-
-		if err := stream.Send(&proto.PutObjectRequest{Bucket: bucket, Key: key, Body: chunk[0:n]}); err != nil {
 			return err
 		}
 	}
@@ -124,11 +130,11 @@ func (c *ObjectStoreGRPCClient) GetObject(bucket, key string) (io.ReadCloser, er
 	return &StreamReadCloser{receive: receive, close: close}, nil
 }
 
-// ListCommonPrefixes gets a list of all object key prefixes that come
-// before the provided delimiter (this is often used to simulate a directory
-// hierarchy in object storage).
-func (c *ObjectStoreGRPCClient) ListCommonPrefixes(bucket, delimiter string) ([]string, error) {
-	res, err := c.grpcClient.ListCommonPrefixes(context.Background(), &proto.ListCommonPrefixesRequest{Bucket: bucket, Delimiter: delimiter})
+// ListCommonPrefixes gets a list of all object key prefixes that start
+// with prefix and come before the provided delimiter (this is often used
+// to simulate a directory hierarchy in object storage).
+func (c *ObjectStoreGRPCClient) ListCommonPrefixes(bucket, prefix, delimiter string) ([]string, error) {
+	res, err := c.grpcClient.ListCommonPrefixes(context.Background(), &proto.ListCommonPrefixesRequest{Bucket: bucket, Prefix: prefix, Delimiter: delimiter})
 	if err != nil {
 		return nil, err
 	}
@@ -247,11 +253,11 @@ func (s *ObjectStoreGRPCServer) GetObject(req *proto.GetObjectRequest, stream pr
 	}
 }
 
-// ListCommonPrefixes gets a list of all object key prefixes that come
-// before the provided delimiter (this is often used to simulate a directory
-// hierarchy in object storage).
+// ListCommonPrefixes gets a list of all object key prefixes that start
+// with prefix and come before the provided delimiter (this is often used
+// to simulate a directory hierarchy in object storage).
 func (s *ObjectStoreGRPCServer) ListCommonPrefixes(ctx context.Context, req *proto.ListCommonPrefixesRequest) (*proto.ListCommonPrefixesResponse, error) {
-	prefixes, err := s.impl.ListCommonPrefixes(req.Bucket, req.Delimiter)
+	prefixes, err := s.impl.ListCommonPrefixes(req.Bucket, req.Prefix, req.Delimiter)
 	if err != nil {
 		return nil, err
 	}
@@ -4,6 +4,10 @@ import (
 	"github.com/pkg/errors"
 )
 
+// errPluginsNotFound is returned by registry.list when no plugins are registered
+// for a PluginKind.
+var errPluginsNotFound = errors.New("plugins not found")
+
 // registry is a simple store of plugin binary information. If a binary
 // is registered as supporting multiple PluginKinds, it will be
 // gettable/listable for all of those kinds.
@@ -52,7 +56,7 @@ func (r *registry) list(kind PluginKind) ([]pluginInfo, error) {
 		return res, nil
 	}
 
-	return nil, errors.New("plugins not found")
+	return nil, errPluginsNotFound
 }
 
 // get returns info about a plugin with the given name and kind, or an
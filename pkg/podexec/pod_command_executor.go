@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package backup
+package podexec
 
 import (
 	"bytes"
@@ -31,11 +31,13 @@ import (
 	"k8s.io/client-go/tools/remotecommand"
 )
 
-// podCommandExecutor is capable of executing a command in a container in a pod.
-type podCommandExecutor interface {
-	// executePodCommand executes a command in a container in a pod. If the command takes longer than
+const defaultTimeout = 30 * time.Second
+
+// PodCommandExecutor is capable of executing a command in a container in a pod.
+type PodCommandExecutor interface {
+	// ExecutePodCommand executes a command in a container in a pod. If the command takes longer than
 	// the specified timeout, an error is returned.
-	executePodCommand(log *logrus.Entry, item map[string]interface{}, namespace, name, hookName string, hook *api.ExecHook) error
+	ExecutePodCommand(log *logrus.Entry, item map[string]interface{}, namespace, name, hookName string, hook *api.ExecHook) error
 }
 
 type poster interface {
@@ -49,8 +51,8 @@ type defaultPodCommandExecutor struct {
 	streamExecutorFactory streamExecutorFactory
 }
 
-// NewPodCommandExecutor creates a new podCommandExecutor.
-func NewPodCommandExecutor(restClientConfig *rest.Config, restClient poster) podCommandExecutor {
+// NewPodCommandExecutor creates a new PodCommandExecutor.
+func NewPodCommandExecutor(restClientConfig *rest.Config, restClient poster) PodCommandExecutor {
 	return &defaultPodCommandExecutor{
 		restClientConfig: restClientConfig,
 		restClient:       restClient,
@@ -59,11 +61,11 @@ func NewPodCommandExecutor(restClientConfig *rest.Config, restClient poster) pod
 	}
 }
 
-// executePodCommand uses the pod exec API to execute a command in a container in a pod. If the
+// ExecutePodCommand uses the pod exec API to execute a command in a container in a pod. If the
 // command takes longer than the specified timeout, an error is returned (NOTE: it is not currently
 // possible to ensure the command is terminated when the timeout occurs, so it may continue to run
 // in the background).
-func (e *defaultPodCommandExecutor) executePodCommand(log *logrus.Entry, item map[string]interface{}, namespace, name, hookName string, hook *api.ExecHook) error {
+func (e *defaultPodCommandExecutor) ExecutePodCommand(log *logrus.Entry, item map[string]interface{}, namespace, name, hookName string, hook *api.ExecHook) error {
 	if item == nil {
 		return errors.New("item is required")
 	}
@@ -80,6 +82,11 @@ func (e *defaultPodCommandExecutor) executePodCommand(log *logrus.Entry, item ma
 		return errors.New("hook is required")
 	}
 
+	// defaults are applied to a copy of the hook, since the caller's hook may be shared
+	// with other goroutines executing it in other pods.
+	hookCopy := *hook
+	hook = &hookCopy
+
 	if hook.Container == "" {
 		if err := setDefaultHookContainer(item, hook); err != nil {
 			return err
@@ -101,7 +108,7 @@ func (e *defaultPodCommandExecutor) executePodCommand(log *logrus.Entry, item ma
 	}
 
 	if hook.Timeout.Duration == 0 {
-		hook.Timeout.Duration = defaultHookTimeout
+		hook.Timeout.Duration = defaultTimeout
 	}
 
 	hookLog := log.WithFields(
@@ -140,11 +147,11 @@ func (e *defaultPodCommandExecutor) executePodCommand(log *logrus.Entry, item ma
 		Stderr: &stderr,
 	}
 
-	errCh := make(chan error)
+	// buffered so the goroutine doesn't block forever if the command times out
+	errCh := make(chan error, 1)
 
 	go func() {
-		err = executor.Stream(streamOptions)
-		errCh <- err
+		errCh <- executor.Stream(streamOptions)
 	}()
 
 	var timeoutCh <-chan time.Time
@@ -0,0 +1,214 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podvolume
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
+)
+
+// DefaultTimeout is how long to wait for the node agent to back up or restore a pod's volumes.
+const DefaultTimeout = 60 * time.Minute
+
+// pollInterval is how often PodVolumeBackups and PodVolumeRestores are checked while waiting
+// for them to be processed.
+var pollInterval = 2 * time.Second
+
+// Backupper backs up the contents of pods' volumes. It's safe for concurrent use.
+type Backupper interface {
+	// BackupPodVolumes backs up the volumes listed in pod's VolumesToBackupAnnotation for backup,
+	// records the outcome for each volume in the backup's status, and returns the IDs of the
+	// snapshots that were taken, keyed by volume name, along with any errors.
+	BackupPodVolumes(backup *api.Backup, pod *corev1api.Pod, log logrus.FieldLogger) (map[string]string, []error)
+}
+
+type backupper struct {
+	client  arkv1client.PodVolumeBackupsGetter
+	timeout time.Duration
+
+	// statusLock guards the PodVolumeBackups in backups' status, since the volumes of a
+	// backup's pods may be backed up concurrently.
+	statusLock sync.Mutex
+}
+
+// NewBackupper returns a Backupper that creates a PodVolumeBackup for each volume to back up,
+// and waits up to timeout for the node agent on the pod's node to process them.
+func NewBackupper(client arkv1client.PodVolumeBackupsGetter, timeout time.Duration) Backupper {
+	return &backupper{
+		client:  client,
+		timeout: timeout,
+	}
+}
+
+func (b *backupper) BackupPodVolumes(backup *api.Backup, pod *corev1api.Pod, log logrus.FieldLogger) (map[string]string, []error) {
+	volumes := GetVolumesToBackup(pod)
+	if len(volumes) == 0 {
+		return nil, nil
+	}
+
+	// volumes are only mounted on a node while the pod is running
+	if pod.Status.Phase != corev1api.PodRunning {
+		log.Warnf("Skipping backup of volumes %v because the pod is %s, not running", volumes, pod.Status.Phase)
+		return nil, nil
+	}
+
+	podVolumes := make(map[string]struct{}, len(pod.Spec.Volumes))
+	for _, volume := range pod.Spec.Volumes {
+		podVolumes[volume.Name] = struct{}{}
+	}
+
+	var (
+		pending []*api.PodVolumeBackup
+		errs    []error
+	)
+
+	for _, volume := range volumes {
+		if _, found := podVolumes[volume]; !found {
+			errs = append(errs, errors.Errorf("volume %s listed in annotation %s does not exist in the pod", volume, VolumesToBackupAnnotation))
+			continue
+		}
+
+		log.WithField("volume", volume).Info("Backing up pod volume")
+		created, err := b.client.PodVolumeBackups(api.DefaultNamespace).Create(newPodVolumeBackup(backup, pod, volume))
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "error creating PodVolumeBackup for volume %s", volume))
+			continue
+		}
+		pending = append(pending, created)
+	}
+
+	completed, err := b.waitForCompletion(pending)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	b.statusLock.Lock()
+	defer b.statusLock.Unlock()
+
+	if backup.Status.PodVolumeBackups == nil && len(pending) > 0 {
+		backup.Status.PodVolumeBackups = make(map[string]*api.PodVolumeBackupInfo)
+	}
+
+	snapshots := make(map[string]string)
+	for _, pvb := range pending {
+		if done, ok := completed[pvb.Name]; ok {
+			pvb = done
+		}
+
+		info := &api.PodVolumeBackupInfo{
+			Phase:      pvb.Status.Phase,
+			SnapshotID: pvb.Status.SnapshotID,
+			Message:    pvb.Status.Message,
+		}
+		backup.Status.PodVolumeBackups[BackupKey(pod, pvb.Spec.Volume)] = info
+
+		switch pvb.Status.Phase {
+		case api.PodVolumeBackupPhaseCompleted:
+			snapshots[pvb.Spec.Volume] = pvb.Status.SnapshotID
+		case api.PodVolumeBackupPhaseFailed:
+			errs = append(errs, errors.Errorf("backup of volume %s failed: %s", pvb.Spec.Volume, pvb.Status.Message))
+		default:
+			info.Phase = api.PodVolumeBackupPhaseFailed
+			info.Message = "timed out waiting for the node agent to back up the volume"
+			errs = append(errs, errors.Errorf("timed out waiting for backup of volume %s", pvb.Spec.Volume))
+		}
+	}
+
+	return snapshots, errs
+}
+
+// waitForCompletion polls the PodVolumeBackups until they've all completed or failed, or the
+// timeout expires, and returns the latest versions of the ones that finished, keyed by name.
+func (b *backupper) waitForCompletion(pvbs []*api.PodVolumeBackup) (map[string]*api.PodVolumeBackup, error) {
+	done := make(map[string]*api.PodVolumeBackup)
+	if len(pvbs) == 0 {
+		return done, nil
+	}
+
+	err := wait.PollImmediate(pollInterval, b.timeout, func() (bool, error) {
+		for _, pvb := range pvbs {
+			if _, ok := done[pvb.Name]; ok {
+				continue
+			}
+
+			current, err := b.client.PodVolumeBackups(pvb.Namespace).Get(pvb.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, errors.Wrapf(err, "error getting PodVolumeBackup %s", pvb.Name)
+			}
+
+			switch current.Status.Phase {
+			case api.PodVolumeBackupPhaseCompleted, api.PodVolumeBackupPhaseFailed:
+				done[pvb.Name] = current
+			}
+		}
+
+		return len(done) == len(pvbs), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		// the ones that didn't finish are reported individually
+		err = nil
+	}
+
+	return done, err
+}
+
+func newPodVolumeBackup(backup *api.Backup, pod *corev1api.Pod, volume string) *api.PodVolumeBackup {
+	return &api.PodVolumeBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    api.DefaultNamespace,
+			GenerateName: backup.Name + "-",
+			Labels: map[string]string{
+				api.BackupNameLabel: backup.Name,
+				PodUIDLabel:         string(pod.UID),
+			},
+			// PodVolumeBackups are deleted along with their backup
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: api.SchemeGroupVersion.String(),
+					Kind:       "Backup",
+					Name:       backup.Name,
+					UID:        backup.UID,
+				},
+			},
+		},
+		Spec: api.PodVolumeBackupSpec{
+			Node: pod.Spec.NodeName,
+			Pod: corev1api.ObjectReference{
+				Kind:      "Pod",
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				UID:       pod.UID,
+			},
+			Volume:          volume,
+			BackupName:      backup.Name,
+			StorageLocation: backup.Spec.StorageLocation,
+		},
+		Status: api.PodVolumeBackupStatus{
+			Phase: api.PodVolumeBackupPhaseNew,
+		},
+	}
+}
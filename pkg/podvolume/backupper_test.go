@@ -0,0 +1,168 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podvolume
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func newTestPod(phase corev1api.PodPhase, annotations map[string]string, volumes ...string) *corev1api.Pod {
+	pod := &corev1api.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns-1",
+			Name:        "pod-1",
+			UID:         "pod-uid",
+			Annotations: annotations,
+		},
+		Spec: corev1api.PodSpec{
+			NodeName: "node-1",
+		},
+		Status: corev1api.PodStatus{
+			Phase: phase,
+		},
+	}
+
+	for _, volume := range volumes {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1api.Volume{Name: volume})
+	}
+
+	return pod
+}
+
+func TestBackupPodVolumes(t *testing.T) {
+	pollInterval = time.Millisecond
+
+	tests := []struct {
+		name              string
+		pod               *corev1api.Pod
+		timeout           time.Duration
+		expectedSnapshots map[string]string
+		expectedStatus    map[string]*api.PodVolumeBackupInfo
+		expectedErrs      []string
+	}{
+		{
+			name: "pod without annotation",
+			pod:  newTestPod(corev1api.PodRunning, nil, "vol-1"),
+		},
+		{
+			name: "pod that isn't running is skipped",
+			pod:  newTestPod(corev1api.PodSucceeded, map[string]string{VolumesToBackupAnnotation: "vol-1"}, "vol-1"),
+		},
+		{
+			name:              "completed and failed volumes are recorded",
+			pod:               newTestPod(corev1api.PodRunning, map[string]string{VolumesToBackupAnnotation: "vol-1, failed,missing"}, "vol-1", "failed"),
+			timeout:           time.Minute,
+			expectedSnapshots: map[string]string{"vol-1": "snapshot-vol-1"},
+			expectedStatus: map[string]*api.PodVolumeBackupInfo{
+				"ns-1/pod-1/vol-1":  {Phase: api.PodVolumeBackupPhaseCompleted, SnapshotID: "snapshot-vol-1"},
+				"ns-1/pod-1/failed": {Phase: api.PodVolumeBackupPhaseFailed, Message: "disk on fire"},
+			},
+			expectedErrs: []string{
+				"volume missing listed in annotation backup.ark.heptio.com/backup-volumes does not exist in the pod",
+				"backup of volume failed failed: disk on fire",
+			},
+		},
+		{
+			name:              "volumes that aren't processed in time fail",
+			pod:               newTestPod(corev1api.PodRunning, map[string]string{VolumesToBackupAnnotation: "slow"}, "slow"),
+			timeout:           10 * time.Millisecond,
+			expectedSnapshots: map[string]string{},
+			expectedStatus: map[string]*api.PodVolumeBackupInfo{
+				"ns-1/pod-1/slow": {Phase: api.PodVolumeBackupPhaseFailed, Message: "timed out waiting for the node agent to back up the volume"},
+			},
+			expectedErrs: []string{"timed out waiting for backup of volume slow"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+
+			// the fake clientset doesn't generate names, and there's no node agent to process
+			// the PodVolumeBackups, so do both when they're created
+			client.PrependReactor("create", "podvolumebackups", func(action core.Action) (bool, runtime.Object, error) {
+				pvb := action.(core.CreateAction).GetObject().(*api.PodVolumeBackup)
+				pvb.Name = pvb.GenerateName + pvb.Spec.Volume
+
+				switch pvb.Spec.Volume {
+				case "failed":
+					pvb.Status = api.PodVolumeBackupStatus{Phase: api.PodVolumeBackupPhaseFailed, Message: "disk on fire"}
+				case "slow":
+				default:
+					pvb.Status = api.PodVolumeBackupStatus{Phase: api.PodVolumeBackupPhaseCompleted, SnapshotID: "snapshot-" + pvb.Spec.Volume}
+				}
+
+				return false, nil, nil
+			})
+
+			backup := arktest.NewTestBackup().WithName("backup-1").Backup
+			snapshots, errs := NewBackupper(client.ArkV1(), test.timeout).BackupPodVolumes(backup, test.pod, arktest.NewLogger())
+
+			assert.Equal(t, test.expectedSnapshots, snapshots)
+			assert.Equal(t, test.expectedStatus, backup.Status.PodVolumeBackups)
+
+			var errStrings []string
+			for _, err := range errs {
+				errStrings = append(errStrings, err.Error())
+			}
+			assert.Equal(t, test.expectedErrs, errStrings)
+
+			// PodVolumeBackups are labeled so they can be found by backup and pod
+			if len(test.expectedStatus) > 0 {
+				pvbs, err := client.ArkV1().PodVolumeBackups(api.DefaultNamespace).List(metav1.ListOptions{})
+				require.NoError(t, err)
+				for _, pvb := range pvbs.Items {
+					assert.Equal(t, "backup-1", pvb.Labels[api.BackupNameLabel])
+					assert.Equal(t, "pod-uid", pvb.Labels[PodUIDLabel])
+					assert.Equal(t, "node-1", pvb.Spec.Node)
+				}
+			}
+		})
+	}
+}
+
+func TestGetVolumesToBackup(t *testing.T) {
+	pod := newTestPod(corev1api.PodRunning, nil)
+	assert.Nil(t, GetVolumesToBackup(pod))
+
+	pod.Annotations = map[string]string{VolumesToBackupAnnotation: " a,b ,,c"}
+	assert.Equal(t, []string{"a", "b", "c"}, GetVolumesToBackup(pod))
+}
+
+func TestSnapshotAnnotations(t *testing.T) {
+	pod := newTestPod(corev1api.PodRunning, nil)
+	assert.Nil(t, GetSnapshotAnnotations(pod))
+
+	SetSnapshotAnnotation(pod, "vol-1", "snapshot-1")
+	SetSnapshotAnnotation(pod, "vol-2", "snapshot-2")
+	pod.Annotations["other"] = "value"
+
+	assert.Equal(t, map[string]string{"vol-1": "snapshot-1", "vol-2": "snapshot-2"}, GetSnapshotAnnotations(pod))
+}
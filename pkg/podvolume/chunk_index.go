@@ -0,0 +1,131 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podvolume
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/heptio/ark/pkg/cloudprovider"
+)
+
+// ChunkIndex caches the IDs of the chunks in each chunk store while a backup is being taken,
+// so the store is listed once per backup rather than once for every volume. Only the most
+// recent backup's index is kept for each store. Each index holds a backup lock on its store, so
+// the chunks it lists aren't pruned while it's in use. It's safe for concurrent use.
+type ChunkIndex struct {
+	lock   sync.Mutex
+	stores map[chunkStore]*chunkSet
+}
+
+// chunkStore identifies the chunk store of a bucket.
+type chunkStore struct {
+	backupService cloudprovider.BackupService
+	bucket        string
+}
+
+// chunkSet is the set of chunks known to exist in a chunk store while backing up a backup.
+type chunkSet struct {
+	store      chunkStore
+	backupName string
+
+	lock sync.Mutex
+	ids  map[string]struct{}
+	// storeLock is the backup lock that's been held on the store since it was listed. It's
+	// not released once the backup's done, since the set may still be used for more of the
+	// backup's volumes, so it lasts until it expires.
+	storeLock *storeLock
+}
+
+// NewChunkIndex returns an empty ChunkIndex.
+func NewChunkIndex() *ChunkIndex {
+	return &ChunkIndex{stores: make(map[chunkStore]*chunkSet)}
+}
+
+// get returns the chunks that exist in bucket for the named backup, locking and listing the
+// bucket's chunk store if it hasn't been listed for that backup yet, or if the lock it was listed
+// under has expired. Chunks may be deleted once no backup references them, so an index built for
+// one backup is never reused for another.
+func (i *ChunkIndex) get(backupService cloudprovider.BackupService, bucket, backupName string, clock clock.Clock) (*chunkSet, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	store := chunkStore{backupService: backupService, bucket: bucket}
+	if chunks, ok := i.stores[store]; ok && chunks.backupName == backupName {
+		// if the lock has expired, chunks may have been pruned since the store was listed
+		if err := chunks.renew(clock); err == nil {
+			return chunks, nil
+		}
+	}
+
+	lock, err := lockForBackup(backupService, bucket, clock)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := backupService.ListPodVolumeChunks(bucket)
+	if err != nil {
+		releaseStoreLock(backupService, bucket, lock)
+		return nil, errors.Wrap(err, "error listing existing chunks")
+	}
+
+	chunks := &chunkSet{
+		store:      store,
+		backupName: backupName,
+		ids:        make(map[string]struct{}, len(ids)),
+		storeLock:  lock,
+	}
+	for _, id := range ids {
+		chunks.ids[id] = struct{}{}
+	}
+	i.stores[store] = chunks
+
+	return chunks, nil
+}
+
+func (s *chunkSet) has(id string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, ok := s.ids[id]
+	return ok
+}
+
+func (s *chunkSet) add(id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.ids[id] = struct{}{}
+}
+
+// renew renews the backup lock the set's store was listed under. It returns an error if the lock
+// has already expired, since the set's chunks may have been pruned since.
+func (s *chunkSet) renew(clock clock.Clock) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	lock, err := renewStoreLock(s.store.backupService, s.store.bucket, s.storeLock, clock)
+	if err != nil {
+		return err
+	}
+	s.storeLock = lock
+
+	return nil
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podvolume
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/heptio/ark/pkg/cloudprovider"
+)
+
+// Backups and prunes of a chunk store are coordinated through lock objects in the store, so a
+// prune never deletes a chunk that a backup has seen in the store and is relying on, even if the
+// backup is being taken by another cluster that shares the bucket. Backups and prunes each write
+// their lock before listing the store's other locks, so whenever one overlaps another, at least
+// one of them sees the other's lock and backs off: a backup waits for the prune to finish, and a
+// prune gives up until the next time it's run.
+//
+// A lock's name holds its expiry, and its holder renews it while it's working, so the locks of
+// processes that have gone away stop counting once they expire. This relies on the object store
+// listing objects as soon as they've been written, and on the clocks of everything sharing the
+// bucket being within lockDuration/2 of each other.
+
+type lockKind string
+
+const (
+	lockKindBackup lockKind = "backup"
+	lockKindPrune  lockKind = "prune"
+)
+
+var (
+	// lockDuration is how long a lock is held for without being renewed. Locks are renewed
+	// once less than half of their duration remains.
+	lockDuration = 10 * time.Minute
+
+	// lockPollInterval is how often a backup checks whether a prune that holds a lock on the
+	// chunk store has finished.
+	lockPollInterval = 10 * time.Second
+
+	// lockWaitTimeout is how long a backup waits for a prune to finish.
+	lockWaitTimeout = 30 * time.Minute
+)
+
+// storeLock is a lock on a chunk store. Its object is named <kind>-<expiry>-<random>, with the
+// expiry in Unix seconds, so locks can be checked without downloading them.
+type storeLock struct {
+	kind    lockKind
+	name    string
+	expires time.Time
+}
+
+func newStoreLock(kind lockKind, now time.Time) (*storeLock, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	expires := now.Add(lockDuration)
+	return &storeLock{
+		kind:    kind,
+		name:    fmt.Sprintf("%s-%d-%s", kind, expires.Unix(), hex.EncodeToString(suffix)),
+		expires: time.Unix(expires.Unix(), 0),
+	}, nil
+}
+
+// parseStoreLock parses a lock object's name. It returns false if name isn't a lock's.
+func parseStoreLock(name string) (*storeLock, bool) {
+	parts := strings.Split(name, "-")
+	if len(parts) != 3 {
+		return nil, false
+	}
+
+	kind := lockKind(parts[0])
+	if kind != lockKindBackup && kind != lockKindPrune {
+		return nil, false
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+
+	return &storeLock{kind: kind, name: name, expires: time.Unix(expires, 0)}, true
+}
+
+// acquireStoreLock writes a lock of the given kind to the chunk store in bucket, and returns it
+// along with the store's other unexpired locks. The caller must release the lock if any of the
+// others conflict with it.
+func acquireStoreLock(backupService cloudprovider.BackupService, bucket string, kind lockKind, clock clock.Clock) (*storeLock, []*storeLock, error) {
+	lock, err := newStoreLock(kind, clock.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := backupService.UploadPodVolumeLock(bucket, lock.name); err != nil {
+		return nil, nil, errors.Wrap(err, "error uploading chunk store lock")
+	}
+
+	names, err := backupService.ListPodVolumeLocks(bucket)
+	if err != nil {
+		releaseStoreLock(backupService, bucket, lock)
+		return nil, nil, errors.Wrap(err, "error listing chunk store locks")
+	}
+
+	now := clock.Now()
+	var others []*storeLock
+	for _, name := range names {
+		other, ok := parseStoreLock(name)
+		if !ok || other.name == lock.name || !other.expires.After(now) {
+			continue
+		}
+		others = append(others, other)
+	}
+
+	return lock, others, nil
+}
+
+// releaseStoreLock deletes lock's object. A lock that can't be deleted stops counting once it
+// expires, so errors are ignored.
+func releaseStoreLock(backupService cloudprovider.BackupService, bucket string, lock *storeLock) {
+	backupService.DeletePodVolumeLock(bucket, lock.name)
+}
+
+// renewStoreLock returns lock, or a new lock of the same kind that replaces it if less than half
+// of its duration remains. It returns an error if lock has already expired, since the work it
+// protected can no longer be relied on.
+func renewStoreLock(backupService cloudprovider.BackupService, bucket string, lock *storeLock, clock clock.Clock) (*storeLock, error) {
+	now := clock.Now()
+	if !lock.expires.After(now) {
+		return nil, errors.Errorf("chunk store lock %s expired before it was renewed", lock.name)
+	}
+	if lock.expires.Sub(now) > lockDuration/2 {
+		return lock, nil
+	}
+
+	renewed, err := newStoreLock(lock.kind, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := backupService.UploadPodVolumeLock(bucket, renewed.name); err != nil {
+		return nil, errors.Wrap(err, "error renewing chunk store lock")
+	}
+	releaseStoreLock(backupService, bucket, lock)
+
+	return renewed, nil
+}
+
+// lockForBackup acquires a backup lock on the chunk store in bucket, waiting for any prune that
+// holds a lock on it to finish.
+func lockForBackup(backupService cloudprovider.BackupService, bucket string, clock clock.Clock) (*storeLock, error) {
+	deadline := clock.Now().Add(lockWaitTimeout)
+
+	for {
+		lock, others, err := acquireStoreLock(backupService, bucket, lockKindBackup, clock)
+		if err != nil {
+			return nil, err
+		}
+
+		pruning := false
+		for _, other := range others {
+			if other.kind == lockKindPrune {
+				pruning = true
+				break
+			}
+		}
+		if !pruning {
+			return lock, nil
+		}
+
+		releaseStoreLock(backupService, bucket, lock)
+
+		if clock.Now().After(deadline) {
+			return nil, errors.New("timed out waiting for unreferenced chunks to be pruned")
+		}
+		clock.Sleep(lockPollInterval)
+	}
+}
+
+// deleteExpiredStoreLocks deletes the locks in bucket's chunk store that expired more than
+// lockDuration ago, so the locks of backups and prunes that didn't release theirs don't pile up.
+func deleteExpiredStoreLocks(backupService cloudprovider.BackupService, bucket string, clock clock.Clock) error {
+	names, err := backupService.ListPodVolumeLocks(bucket)
+	if err != nil {
+		return errors.Wrap(err, "error listing chunk store locks")
+	}
+
+	cutoff := clock.Now().Add(-lockDuration)
+	for _, name := range names {
+		lock, ok := parseStoreLock(name)
+		if !ok || lock.expires.After(cutoff) {
+			continue
+		}
+		if err := backupService.DeletePodVolumeLock(bucket, name); err != nil {
+			return errors.Wrapf(err, "error deleting expired chunk store lock %s", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package podvolume implements file-level backup and restore of the contents of pod volumes.
+// Pods opt in by listing the volumes to back up in an annotation. For each of those volumes,
+// the Ark server creates a PodVolumeBackup, which the node agent running on the pod's node
+// processes by reading the mounted volume's files and uploading them, split into
+// content-addressed chunks so that unchanged data is only stored once, to the backup's
+// storage location. On restore, the restored pod gets an init container that waits while the
+// node agent repopulates its volumes, so the pod's own containers start with the data in place.
+package podvolume
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const (
+	// VolumesToBackupAnnotation is the annotation on a pod that lists the names of the pod's
+	// volumes whose contents should be backed up, separated by commas.
+	VolumesToBackupAnnotation = "backup.ark.heptio.com/backup-volumes"
+
+	// snapshotAnnotationPrefix is the prefix of the annotations added to backed-up pods to
+	// record the snapshot ID of each of their backed-up volumes. The rest of the key is the
+	// volume's name.
+	snapshotAnnotationPrefix = "snapshot.ark.heptio.com/"
+
+	// PodUIDLabel is the label on PodVolumeBackups and PodVolumeRestores that identifies
+	// the pod they're for.
+	PodUIDLabel = "ark.heptio.com/pod-uid"
+)
+
+// GetVolumesToBackup returns the names of the volumes listed in obj's VolumesToBackupAnnotation.
+func GetVolumesToBackup(obj metav1.Object) []string {
+	annotation := obj.GetAnnotations()[VolumesToBackupAnnotation]
+	if annotation == "" {
+		return nil
+	}
+
+	var volumes []string
+	for _, volume := range strings.Split(annotation, ",") {
+		if volume = strings.TrimSpace(volume); volume != "" {
+			volumes = append(volumes, volume)
+		}
+	}
+
+	return volumes
+}
+
+// SetSnapshotAnnotation records in obj's annotations that its volume was backed up to the
+// snapshot with the given ID.
+func SetSnapshotAnnotation(obj metav1.Object, volume, snapshotID string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[snapshotAnnotationPrefix+volume] = snapshotID
+	obj.SetAnnotations(annotations)
+}
+
+// GetSnapshotAnnotations returns a map of volume name to snapshot ID for each of obj's volumes
+// that were backed up.
+func GetSnapshotAnnotations(obj metav1.Object) map[string]string {
+	var res map[string]string
+
+	for key, value := range obj.GetAnnotations() {
+		if !strings.HasPrefix(key, snapshotAnnotationPrefix) {
+			continue
+		}
+		if res == nil {
+			res = make(map[string]string)
+		}
+		res[strings.TrimPrefix(key, snapshotAnnotationPrefix)] = value
+	}
+
+	return res
+}
+
+// BackupKey returns the key of a pod volume in a Backup's status' PodVolumeBackups.
+func BackupKey(pod *corev1api.Pod, volume string) string {
+	return pod.Namespace + "/" + pod.Name + "/" + volume
+}
+
+// GetVolumeDirectory returns the name of the directory that the kubelet mounts the named volume
+// of pod in. For volumes backed by a PersistentVolumeClaim, that's the name of the bound
+// PersistentVolume; for all other volumes, it's the volume's name.
+func GetVolumeDirectory(pod *corev1api.Pod, volumeName string, pvcGetter corev1client.PersistentVolumeClaimsGetter) (string, error) {
+	var volume *corev1api.Volume
+	for i := range pod.Spec.Volumes {
+		if pod.Spec.Volumes[i].Name == volumeName {
+			volume = &pod.Spec.Volumes[i]
+			break
+		}
+	}
+	if volume == nil {
+		return "", errors.Errorf("pod %s/%s has no volume %s", pod.Namespace, pod.Name, volumeName)
+	}
+
+	if volume.PersistentVolumeClaim == nil {
+		return volume.Name, nil
+	}
+
+	pvc, err := pvcGetter.PersistentVolumeClaims(pod.Namespace).Get(volume.PersistentVolumeClaim.ClaimName, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if pvc.Spec.VolumeName == "" {
+		return "", errors.Errorf("persistent volume claim %s/%s is not bound", pvc.Namespace, pvc.Name)
+	}
+
+	return pvc.Spec.VolumeName, nil
+}
+
+// FindVolumePath returns the path of the pod's mounted volume directory under hostPodsDir,
+// which is where the kubelet's pods directory (usually /var/lib/kubelet/pods) is mounted.
+func FindVolumePath(hostPodsDir string, podUID types.UID, volumeDir string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(hostPodsDir, string(podUID), "volumes", "*", volumeDir))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", errors.Errorf("volume directory %s not found for pod %s", volumeDir, podUID)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", errors.Errorf("found %d volume directories named %s for pod %s", len(matches), volumeDir, podUID)
+	}
+}
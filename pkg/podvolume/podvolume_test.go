@@ -0,0 +1,100 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podvolume
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+type fakePVCGetter struct {
+	corev1client.PersistentVolumeClaimInterface
+	pvcs map[string]*corev1api.PersistentVolumeClaim
+}
+
+func (g *fakePVCGetter) PersistentVolumeClaims(namespace string) corev1client.PersistentVolumeClaimInterface {
+	return g
+}
+
+func (g *fakePVCGetter) Get(name string, options metav1.GetOptions) (*corev1api.PersistentVolumeClaim, error) {
+	pvc, ok := g.pvcs[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return pvc, nil
+}
+
+func TestGetVolumeDirectory(t *testing.T) {
+	pod := &corev1api.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "pod-1"},
+		Spec: corev1api.PodSpec{
+			Volumes: []corev1api.Volume{
+				{Name: "scratch", VolumeSource: corev1api.VolumeSource{EmptyDir: &corev1api.EmptyDirVolumeSource{}}},
+				{Name: "data", VolumeSource: corev1api.VolumeSource{PersistentVolumeClaim: &corev1api.PersistentVolumeClaimVolumeSource{ClaimName: "claim-1"}}},
+				{Name: "pending", VolumeSource: corev1api.VolumeSource{PersistentVolumeClaim: &corev1api.PersistentVolumeClaimVolumeSource{ClaimName: "claim-2"}}},
+			},
+		},
+	}
+	pvcGetter := &fakePVCGetter{pvcs: map[string]*corev1api.PersistentVolumeClaim{
+		"claim-1": {Spec: corev1api.PersistentVolumeClaimSpec{VolumeName: "pv-1"}},
+		"claim-2": {ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "claim-2"}},
+	}}
+
+	dir, err := GetVolumeDirectory(pod, "scratch", pvcGetter)
+	require.NoError(t, err)
+	assert.Equal(t, "scratch", dir)
+
+	dir, err = GetVolumeDirectory(pod, "data", pvcGetter)
+	require.NoError(t, err)
+	assert.Equal(t, "pv-1", dir)
+
+	_, err = GetVolumeDirectory(pod, "pending", pvcGetter)
+	assert.EqualError(t, err, "persistent volume claim ns-1/claim-2 is not bound")
+
+	_, err = GetVolumeDirectory(pod, "missing", pvcGetter)
+	assert.EqualError(t, err, "pod ns-1/pod-1 has no volume missing")
+}
+
+func TestFindVolumePath(t *testing.T) {
+	hostPodsDir, err := ioutil.TempDir("", "host-pods")
+	require.NoError(t, err)
+	defer os.RemoveAll(hostPodsDir)
+
+	expected := filepath.Join(hostPodsDir, "uid-1", "volumes", "kubernetes.io~empty-dir", "scratch")
+	require.NoError(t, os.MkdirAll(expected, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(hostPodsDir, "uid-1", "volumes", "kubernetes.io~nfs", "shared"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(hostPodsDir, "uid-1", "volumes", "kubernetes.io~local-volume", "shared"), 0755))
+
+	path, err := FindVolumePath(hostPodsDir, "uid-1", "scratch")
+	require.NoError(t, err)
+	assert.Equal(t, expected, path)
+
+	_, err = FindVolumePath(hostPodsDir, "uid-2", "scratch")
+	assert.EqualError(t, err, "volume directory scratch not found for pod uid-2")
+
+	_, err = FindVolumePath(hostPodsDir, "uid-1", "shared")
+	assert.EqualError(t, err, "found 2 volume directories named shared for pod uid-1")
+}
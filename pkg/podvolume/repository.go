@@ -0,0 +1,598 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podvolume
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/encryption"
+)
+
+const (
+	// DefaultChunkSize is the maximum amount of file data stored in each chunk.
+	DefaultChunkSize = 8 * 1024 * 1024
+
+	// chunkIDKeyPurpose is the purpose the key that encrypted repositories' chunk IDs are
+	// computed with is derived for.
+	chunkIDKeyPurpose = "ark-pod-volume-chunk-ids"
+
+	// markerDir is the directory, at the root of a restored volume, that the node agent writes
+	// a marker file to once it has finished restoring the volume. It's excluded from backups.
+	markerDir = ".ark"
+)
+
+type entryType string
+
+const (
+	entryTypeDir     entryType = "dir"
+	entryTypeFile    entryType = "file"
+	entryTypeSymlink entryType = "symlink"
+)
+
+// manifest lists the contents of a backed-up volume. Entries are in the order they were
+// walked, so every directory comes before its contents.
+type manifest struct {
+	Entries []manifestEntry `json:"entries"`
+
+	// KeyedChunkIDs is set if the chunk IDs are HMACs of the chunks' contents rather than
+	// plain hashes.
+	KeyedChunkIDs bool `json:"keyedChunkIDs,omitempty"`
+}
+
+type manifestEntry struct {
+	// Path is the entry's path relative to the root of the volume.
+	Path    string      `json:"path"`
+	Type    entryType   `json:"type"`
+	Mode    os.FileMode `json:"mode"`
+	ModTime time.Time   `json:"modTime"`
+	UID     int         `json:"uid"`
+	GID     int         `json:"gid"`
+
+	// Size and Chunks are set for files. Chunks are the IDs of the chunks that make up the
+	// file's data, in order.
+	Size   int64    `json:"size,omitempty"`
+	Chunks []string `json:"chunks,omitempty"`
+
+	// Target is set for symlinks.
+	Target string `json:"target,omitempty"`
+}
+
+// Repository stores the contents of pod volumes in a storage location. File data is split into
+// chunks that are identified by the hash of their contents, so data that hasn't changed between
+// backups, or that's duplicated within or between volumes, is only stored once. If chunks are
+// encrypted, they're identified by an HMAC keyed with a key derived from the encryption key
+// instead, so their IDs don't reveal their contents. Each backed-up volume is described by a
+// manifest that's stored with the backup it was taken for.
+//
+// Chunks are shared between backups, so they're not deleted along with them; Prune deletes
+// the ones that no backup references any more. Backups and prunes lock the chunk store so they
+// don't overlap.
+type Repository struct {
+	backupService cloudprovider.BackupService
+	bucket        string
+	keyProvider   encryption.KeyProvider
+	index         *ChunkIndex
+	chunkSize     int
+	clock         clock.Clock
+	log           logrus.FieldLogger
+}
+
+// NewRepository returns a Repository that stores volume contents in bucket using backupService.
+// If keyProvider is non-nil, chunks and manifests are encrypted. index may be shared between
+// repositories so the chunk store is only listed once per backup; if it's nil, the repository
+// gets its own.
+func NewRepository(backupService cloudprovider.BackupService, bucket string, keyProvider encryption.KeyProvider, index *ChunkIndex, log logrus.FieldLogger) *Repository {
+	if index == nil {
+		index = NewChunkIndex()
+	}
+
+	return &Repository{
+		backupService: backupService,
+		bucket:        bucket,
+		keyProvider:   keyProvider,
+		index:         index,
+		chunkSize:     DefaultChunkSize,
+		clock:         clock.RealClock{},
+		log:           log,
+	}
+}
+
+// Backup stores the contents of dir for the named backup, and returns the snapshot ID that
+// identifies them.
+func (r *Repository) Backup(dir, backupName string) (string, error) {
+	idKey, err := r.chunkIDKey(r.keyProvider != nil)
+	if err != nil {
+		return "", err
+	}
+
+	existingChunks, err := r.index.get(r.backupService, r.bucket, backupName, r.clock)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		m   = manifest{KeyedChunkIDs: idKey != nil}
+		buf = make([]byte, r.chunkSize)
+	)
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if relPath == markerDir {
+			return filepath.SkipDir
+		}
+
+		uid, gid := fileOwner(info)
+		entry := manifestEntry{
+			Path:    relPath,
+			Mode:    info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky),
+			ModTime: info.ModTime(),
+			UID:     uid,
+			GID:     gid,
+		}
+
+		switch {
+		case info.IsDir():
+			entry.Type = entryTypeDir
+		case info.Mode()&os.ModeSymlink != 0:
+			entry.Type = entryTypeSymlink
+			if entry.Target, err = os.Readlink(path); err != nil {
+				return errors.WithStack(err)
+			}
+		case info.Mode().IsRegular():
+			entry.Type = entryTypeFile
+			entry.Size = info.Size()
+			if entry.Chunks, err = r.backupFile(path, buf, idKey, existingChunks); err != nil {
+				return errors.Wrapf(err, "error backing up file %s", relPath)
+			}
+		default:
+			r.log.WithField("path", relPath).Warn("Skipping file because it's not a regular file, directory or symlink")
+			return nil
+		}
+
+		m.Entries = append(m.Entries, entry)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	manifestBytes, err := json.Marshal(m)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	hash := sha256.Sum256(manifestBytes)
+	snapshotID := hex.EncodeToString(hash[:])
+
+	encoded, err := r.encode(manifestBytes)
+	if err != nil {
+		return "", err
+	}
+
+	// the chunks the manifest references mustn't have been pruned before it's uploaded
+	if err := existingChunks.renew(r.clock); err != nil {
+		return "", err
+	}
+
+	if err := r.backupService.UploadPodVolumeManifest(r.bucket, backupName, snapshotID, encoded); err != nil {
+		return "", errors.Wrap(err, "error uploading manifest")
+	}
+
+	return snapshotID, nil
+}
+
+// backupFile uploads the chunks of the file at path that aren't already stored, and returns the
+// IDs of all of the file's chunks.
+func (r *Repository) backupFile(path string, buf, idKey []byte, existingChunks *chunkSet) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	var chunks []string
+	for {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 {
+			id := chunkID(idKey, buf[:n])
+
+			if err := existingChunks.renew(r.clock); err != nil {
+				return nil, err
+			}
+
+			if !existingChunks.has(id) {
+				encoded, err := r.encode(buf[:n])
+				if err != nil {
+					return nil, err
+				}
+				if err := r.backupService.UploadPodVolumeChunk(r.bucket, id, encoded); err != nil {
+					return nil, errors.Wrapf(err, "error uploading chunk %s", id)
+				}
+				existingChunks.add(id)
+			}
+
+			chunks = append(chunks, id)
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return chunks, nil
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+}
+
+// Restore writes the contents of the snapshot with the given ID, taken for the named backup,
+// to dir. Existing files with the same paths are overwritten.
+func (r *Repository) Restore(dir, backupName, snapshotID string) error {
+	m, err := r.getManifest(backupName, snapshotID)
+	if err != nil {
+		return err
+	}
+
+	idKey, err := r.chunkIDKey(m.KeyedChunkIDs)
+	if err != nil {
+		return err
+	}
+
+	// directories' metadata is set once their contents have been written, deepest first, so
+	// that writing their contents doesn't change their modification times, and read-only
+	// directories can still be written to
+	var dirs []manifestEntry
+
+	for _, entry := range m.Entries {
+		path, err := entryPath(dir, entry.Path)
+		if err != nil {
+			return err
+		}
+
+		switch entry.Type {
+		case entryTypeDir:
+			if err := os.MkdirAll(path, 0700); err != nil {
+				return errors.WithStack(err)
+			}
+			dirs = append(dirs, entry)
+			continue
+		case entryTypeFile:
+			if err := r.restoreFile(path, entry, idKey); err != nil {
+				return errors.Wrapf(err, "error restoring file %s", entry.Path)
+			}
+		case entryTypeSymlink:
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return errors.WithStack(err)
+			}
+			if err := os.Symlink(entry.Target, path); err != nil {
+				return errors.WithStack(err)
+			}
+		default:
+			return errors.Errorf("unsupported manifest entry type %q for %s", entry.Type, entry.Path)
+		}
+
+		if err := setMetadata(path, entry); err != nil {
+			return err
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		path, err := entryPath(dir, dirs[i].Path)
+		if err != nil {
+			return err
+		}
+		if err := setMetadata(path, dirs[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) restoreFile(path string, entry manifestEntry, idKey []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	var written int64
+	for _, id := range entry.Chunks {
+		n, err := r.restoreChunk(f, id, idKey)
+		if err != nil {
+			return errors.Wrapf(err, "error restoring chunk %s", id)
+		}
+		written += n
+	}
+
+	if written != entry.Size {
+		return errors.Errorf("restored %d bytes, expected %d", written, entry.Size)
+	}
+
+	return errors.WithStack(f.Close())
+}
+
+func (r *Repository) restoreChunk(w io.Writer, id string, idKey []byte) (int64, error) {
+	rc, err := r.backupService.DownloadPodVolumeChunk(r.bucket, id)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	data, err := r.decode(rc)
+	if err != nil {
+		return 0, err
+	}
+
+	if chunkID(idKey, data) != id {
+		return 0, errors.New("chunk contents don't match its ID")
+	}
+
+	n, err := w.Write(data)
+	return int64(n), errors.WithStack(err)
+}
+
+// Prune deletes the chunks that aren't referenced by the manifest of any backup in the bucket,
+// and returns the number of chunks deleted. Nothing is deleted if any manifest can't be read.
+// Chunks are uploaded before the manifest that references them, so nothing is deleted while a
+// backup or another prune holds a lock on the chunk store; backups that start while Prune is
+// running wait for it to finish.
+func (r *Repository) Prune() (int, error) {
+	lock, others, err := acquireStoreLock(r.backupService, r.bucket, lockKindPrune, r.clock)
+	if err != nil {
+		return 0, err
+	}
+	if len(others) > 0 {
+		releaseStoreLock(r.backupService, r.bucket, lock)
+		r.log.WithField("lock", others[0].name).Info("Chunk store is locked by a backup or another prune, skipping pruning")
+		return 0, nil
+	}
+	defer func() {
+		releaseStoreLock(r.backupService, r.bucket, lock)
+	}()
+
+	if err := deleteExpiredStoreLocks(r.backupService, r.bucket, r.clock); err != nil {
+		r.log.WithError(err).Warn("Error deleting expired chunk store locks")
+	}
+
+	ids, err := r.backupService.ListPodVolumeChunks(r.bucket)
+	if err != nil {
+		return 0, errors.Wrap(err, "error listing chunks")
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	manifests, err := r.backupService.ListPodVolumeManifests(r.bucket)
+	if err != nil {
+		return 0, errors.Wrap(err, "error listing manifests")
+	}
+
+	referenced := make(map[string]struct{})
+	for backupName, snapshotIDs := range manifests {
+		for _, snapshotID := range snapshotIDs {
+			m, err := r.getManifest(backupName, snapshotID)
+			if err != nil {
+				return 0, errors.WithMessage(err, "backup "+backupName)
+			}
+
+			for _, entry := range m.Entries {
+				for _, id := range entry.Chunks {
+					referenced[id] = struct{}{}
+				}
+			}
+		}
+	}
+
+	var (
+		deleted int
+		errs    []error
+	)
+	for _, id := range ids {
+		if _, ok := referenced[id]; ok {
+			continue
+		}
+
+		// backups only wait for the prune while its lock hasn't expired
+		renewed, err := renewStoreLock(r.backupService, r.bucket, lock, r.clock)
+		if err != nil {
+			return deleted, kerrors.NewAggregate(append(errs, err))
+		}
+		lock = renewed
+
+		r.log.WithField("chunk", id).Debug("Deleting unreferenced chunk")
+		if err := r.backupService.DeletePodVolumeChunk(r.bucket, id); err != nil {
+			errs = append(errs, errors.Wrapf(err, "error deleting chunk %s", id))
+			continue
+		}
+		deleted++
+	}
+
+	return deleted, kerrors.NewAggregate(errs)
+}
+
+// chunkIDKey returns the key that chunk IDs are computed with if keyed is true, or nil if
+// they're plain hashes.
+func (r *Repository) chunkIDKey(keyed bool) ([]byte, error) {
+	if !keyed {
+		return nil, nil
+	}
+	if r.keyProvider == nil {
+		return nil, errors.New("chunk IDs are keyed, but encryption is not configured")
+	}
+
+	key, err := r.keyProvider.DeriveKey(chunkIDKeyPurpose)
+	if err != nil {
+		return nil, errors.Wrap(err, "error deriving chunk ID key")
+	}
+	return key, nil
+}
+
+// chunkID returns the ID of the chunk holding data: the SHA-256 hash of data, or its
+// HMAC-SHA256 if key is non-nil.
+func chunkID(key, data []byte) string {
+	if key == nil {
+		hash := sha256.Sum256(data)
+		return hex.EncodeToString(hash[:])
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (r *Repository) getManifest(backupName, snapshotID string) (*manifest, error) {
+	rc, err := r.backupService.DownloadPodVolumeManifest(r.bucket, backupName, snapshotID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error downloading manifest for snapshot %s", snapshotID)
+	}
+	defer rc.Close()
+
+	data, err := r.decode(rc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading manifest for snapshot %s", snapshotID)
+	}
+
+	m := new(manifest)
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, errors.Wrapf(err, "error decoding manifest for snapshot %s", snapshotID)
+	}
+
+	return m, nil
+}
+
+// encode compresses data, and encrypts it if the repository has a key provider.
+func (r *Repository) encode(data []byte) (io.Reader, error) {
+	buf := new(bytes.Buffer)
+
+	var w io.WriteCloser = nopWriteCloser{buf}
+	if r.keyProvider != nil {
+		var err error
+		if w, err = encryption.NewEncryptingWriter(buf, r.keyProvider); err != nil {
+			return nil, err
+		}
+	}
+
+	gzw := gzip.NewWriter(w)
+	if _, err := gzw.Write(data); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := gzw.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// decode reverses encode.
+func (r *Repository) decode(rc io.Reader) ([]byte, error) {
+	decrypted, err := encryption.NewDecryptingReaderIfEncrypted(rc, r.keyProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	gzr, err := gzip.NewReader(decrypted)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer gzr.Close()
+
+	data, err := ioutil.ReadAll(gzr)
+	return data, errors.WithStack(err)
+}
+
+// entryPath returns the path within dir of a manifest entry, making sure it doesn't escape dir.
+// The entry's parent directories must already exist.
+func entryPath(dir, relPath string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(relPath))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("invalid path %q in manifest", relPath)
+	}
+
+	// make sure nothing is written through a symlink, which could point outside of dir
+	parent := dir
+	for _, part := range strings.Split(filepath.Dir(cleaned), string(filepath.Separator)) {
+		if part == "." {
+			continue
+		}
+		parent = filepath.Join(parent, part)
+
+		info, err := os.Lstat(parent)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return "", errors.Errorf("invalid path %q in manifest: %s is a symlink", relPath, part)
+		}
+	}
+
+	return filepath.Join(dir, cleaned), nil
+}
+
+// setMetadata sets the ownership, permissions and modification time of the restored entry at path.
+func setMetadata(path string, entry manifestEntry) error {
+	if entry.UID >= 0 && os.Geteuid() == 0 {
+		if err := os.Lchown(path, entry.UID, entry.GID); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	// permissions and times can't be set on symlinks themselves
+	if entry.Type == entryTypeSymlink {
+		return nil
+	}
+
+	if err := os.Chmod(path, entry.Mode); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(os.Chtimes(path, entry.ModTime, entry.ModTime))
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,489 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podvolume
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/encryption"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+// memObjectStore is an in-memory cloudprovider.ObjectStore.
+type memObjectStore struct {
+	lock       sync.Mutex
+	objects    map[string][]byte
+	puts       int
+	chunkLists int
+
+	// onDelete, if set, is called before each object is deleted.
+	onDelete func(key string)
+}
+
+func newMemObjectStore() *memObjectStore {
+	return &memObjectStore{objects: make(map[string][]byte)}
+}
+
+func (s *memObjectStore) Init(config map[string]string) error { return nil }
+
+func (s *memObjectStore) PutObject(bucket, key string, body io.Reader) error {
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.objects[key] = data
+	s.puts++
+	return nil
+}
+
+func (s *memObjectStore) GetObject(bucket, key string) (io.ReadCloser, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memObjectStore) ListCommonPrefixes(bucket, prefix, delimiter string) ([]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	seen := make(map[string]bool)
+	var prefixes []string
+	for key := range s.objects {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(key, prefix)
+		if i := strings.Index(rest, delimiter); i >= 0 && !seen[rest[:i]] {
+			seen[rest[:i]] = true
+			prefixes = append(prefixes, prefix+rest[:i])
+		}
+	}
+	sort.Strings(prefixes)
+	return prefixes, nil
+}
+
+func (s *memObjectStore) ListObjects(bucket, prefix string) ([]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if strings.HasSuffix(prefix, "/chunks/") {
+		s.chunkLists++
+	}
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *memObjectStore) DeleteObject(bucket, key string) error {
+	if s.onDelete != nil {
+		s.onDelete(key)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memObjectStore) CreateSignedURL(bucket, key string, ttl time.Duration) (string, error) {
+	return "", nil
+}
+
+func newTestRepository(store *memObjectStore, keyProvider encryption.KeyProvider) *Repository {
+	log := arktest.NewLogger()
+	repo := NewRepository(cloudprovider.NewBackupService(store, "", log.Logger), "bucket", keyProvider, nil, log)
+	repo.chunkSize = 4
+	return repo
+}
+
+func writeFile(t *testing.T, path, contents string, mode os.FileMode) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), mode))
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "podvolume")
+	require.NoError(t, err)
+	return dir
+}
+
+func TestRepositoryBackupRestore(t *testing.T) {
+	keyProvider, err := encryption.NewLocalKeyProviderForKey([]byte(strings.Repeat("k", 32)))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		keyProvider encryption.KeyProvider
+	}{
+		{name: "unencrypted"},
+		{name: "encrypted", keyProvider: keyProvider},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			src := tempDir(t)
+			defer os.RemoveAll(src)
+			dst := tempDir(t)
+			defer os.RemoveAll(dst)
+
+			writeFile(t, filepath.Join(src, "a.txt"), "hello world", 0644)
+			writeFile(t, filepath.Join(src, "sub", "b.txt"), "hello world", 0600)
+			writeFile(t, filepath.Join(src, "sub", "empty"), "", 0644)
+			writeFile(t, filepath.Join(src, markerDir, "some-restore"), "", 0644)
+			require.NoError(t, os.Symlink("sub/b.txt", filepath.Join(src, "link")))
+			require.NoError(t, os.Chmod(filepath.Join(src, "sub"), 0750))
+
+			modTime := time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC)
+			require.NoError(t, os.Chtimes(filepath.Join(src, "a.txt"), modTime, modTime))
+
+			store := newMemObjectStore()
+			repo := newTestRepository(store, test.keyProvider)
+
+			snapshotID, err := repo.Backup(src, "backup-1")
+			require.NoError(t, err)
+			assert.NotEmpty(t, snapshotID)
+
+			// "hello world" is split into "hell", "o wo" and "rld", and both files share them
+			chunks, err := repo.backupService.ListPodVolumeChunks("bucket")
+			require.NoError(t, err)
+			assert.Len(t, chunks, 3)
+
+			if test.keyProvider != nil {
+				for key, data := range store.objects {
+					if strings.HasPrefix(key, "podvolumes/locks/") {
+						continue
+					}
+					encrypted, _, err := encryption.IsEncrypted(bytes.NewReader(data))
+					require.NoError(t, err)
+					assert.True(t, encrypted, "%s is not encrypted", key)
+				}
+			}
+
+			// backing up again uploads only the manifest and a lock on the chunk store
+			puts := store.puts
+			_, err = repo.Backup(src, "backup-2")
+			require.NoError(t, err)
+			assert.Equal(t, puts+2, store.puts)
+
+			require.NoError(t, repo.Restore(dst, "backup-1", snapshotID))
+
+			data, err := ioutil.ReadFile(filepath.Join(dst, "sub", "b.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, "hello world", string(data))
+
+			info, err := os.Stat(filepath.Join(dst, "sub", "b.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode())
+
+			info, err = os.Stat(filepath.Join(dst, "sub"))
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+
+			info, err = os.Stat(filepath.Join(dst, "a.txt"))
+			require.NoError(t, err)
+			assert.True(t, modTime.Equal(info.ModTime()))
+
+			data, err = ioutil.ReadFile(filepath.Join(dst, "sub", "empty"))
+			require.NoError(t, err)
+			assert.Empty(t, data)
+
+			target, err := os.Readlink(filepath.Join(dst, "link"))
+			require.NoError(t, err)
+			assert.Equal(t, "sub/b.txt", target)
+
+			_, err = os.Stat(filepath.Join(dst, markerDir))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestRepositoryChunkIndex(t *testing.T) {
+	src := tempDir(t)
+	defer os.RemoveAll(src)
+
+	writeFile(t, filepath.Join(src, "a.txt"), "hello world", 0644)
+
+	store := newMemObjectStore()
+	repo := newTestRepository(store, nil)
+
+	// the chunk store is listed once per backup, not once per volume
+	for _, backup := range []string{"backup-1", "backup-1", "backup-2"} {
+		_, err := repo.Backup(src, backup)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, store.chunkLists)
+}
+
+func TestRepositoryPrune(t *testing.T) {
+	keyProvider, err := encryption.NewLocalKeyProviderForKey([]byte(strings.Repeat("k", 32)))
+	require.NoError(t, err)
+
+	src := tempDir(t)
+	defer os.RemoveAll(src)
+
+	store := newMemObjectStore()
+	repo := newTestRepository(store, keyProvider)
+	fakeClock := clock.NewFakeClock(time.Now())
+	repo.clock = fakeClock
+
+	writeFile(t, filepath.Join(src, "a.txt"), "hello world", 0644)
+	_, err = repo.Backup(src, "backup-1")
+	require.NoError(t, err)
+
+	writeFile(t, filepath.Join(src, "a.txt"), "hello there", 0644)
+	snapshotID, err := repo.Backup(src, "backup-2")
+	require.NoError(t, err)
+
+	// "hell", "o wo", "rld", "o th" and "ere"
+	chunks, err := repo.backupService.ListPodVolumeChunks("bucket")
+	require.NoError(t, err)
+	require.Len(t, chunks, 5)
+
+	require.NoError(t, repo.backupService.DeleteBackupDir("bucket", "backup-1"))
+
+	// nothing is deleted while the backups' locks on the chunk store haven't expired
+	deleted, err := repo.Prune()
+	require.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+
+	locks, err := repo.backupService.ListPodVolumeLocks("bucket")
+	require.NoError(t, err)
+	assert.Len(t, locks, 2)
+
+	// once they have, the unreferenced chunks are deleted, and so are the expired locks
+	fakeClock.Step(2*lockDuration + time.Second)
+
+	deleted, err = repo.Prune()
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	locks, err = repo.backupService.ListPodVolumeLocks("bucket")
+	require.NoError(t, err)
+	assert.Empty(t, locks)
+
+	// nothing is deleted while every chunk is referenced
+	deleted, err = repo.Prune()
+	require.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+
+	dst := tempDir(t)
+	defer os.RemoveAll(dst)
+	require.NoError(t, repo.Restore(dst, "backup-2", snapshotID))
+
+	data, err := ioutil.ReadFile(filepath.Join(dst, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello there", string(data))
+
+	// a manifest that can't be read stops the prune before anything's deleted
+	require.NoError(t, store.PutObject("bucket", "backup-3/podvolumes/snapshot.gz", strings.NewReader("garbage")))
+	require.NoError(t, repo.backupService.DeleteBackupDir("bucket", "backup-2"))
+
+	_, err = repo.Prune()
+	assert.Error(t, err)
+
+	chunks, err = repo.backupService.ListPodVolumeChunks("bucket")
+	require.NoError(t, err)
+	assert.Len(t, chunks, 3)
+}
+
+func TestRepositoryChunkIDs(t *testing.T) {
+	keyProvider, err := encryption.NewLocalKeyProviderForKey([]byte(strings.Repeat("k", 32)))
+	require.NoError(t, err)
+
+	src := tempDir(t)
+	defer os.RemoveAll(src)
+	writeFile(t, filepath.Join(src, "a.txt"), "hell", 0644)
+
+	store := newMemObjectStore()
+	plainRepo := newTestRepository(store, nil)
+	encryptedRepo := newTestRepository(store, keyProvider)
+
+	plainSnapshot, err := plainRepo.Backup(src, "backup-1")
+	require.NoError(t, err)
+	encryptedSnapshot, err := encryptedRepo.Backup(src, "backup-2")
+	require.NoError(t, err)
+
+	// the unencrypted chunk is identified by its hash, and the encrypted one by an HMAC that
+	// doesn't reveal its contents
+	hash := sha256.Sum256([]byte("hell"))
+	chunks, err := store.ListObjects("bucket", "podvolumes/chunks/")
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Contains(t, chunks, "podvolumes/chunks/"+hex.EncodeToString(hash[:]))
+
+	// snapshots taken with either kind of ID can be restored, including ones taken before
+	// encryption was enabled
+	for backup, snapshotID := range map[string]string{"backup-1": plainSnapshot, "backup-2": encryptedSnapshot} {
+		dst := tempDir(t)
+		defer os.RemoveAll(dst)
+		require.NoError(t, encryptedRepo.Restore(dst, backup, snapshotID))
+
+		data, err := ioutil.ReadFile(filepath.Join(dst, "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "hell", string(data))
+	}
+
+	// a chunk that doesn't match its keyed ID is rejected
+	for _, chunk := range chunks {
+		if chunk != "podvolumes/chunks/"+hex.EncodeToString(hash[:]) {
+			encoded, err := encryptedRepo.encode([]byte("help"))
+			require.NoError(t, err)
+			require.NoError(t, store.PutObject("bucket", chunk, encoded))
+		}
+	}
+	dst := tempDir(t)
+	defer os.RemoveAll(dst)
+	assert.Error(t, encryptedRepo.Restore(dst, "backup-2", encryptedSnapshot))
+}
+
+func TestRepositoryBackupDuringPrune(t *testing.T) {
+	defer func(interval time.Duration) { lockPollInterval = interval }(lockPollInterval)
+	lockPollInterval = 10 * time.Millisecond
+
+	src := tempDir(t)
+	defer os.RemoveAll(src)
+	writeFile(t, filepath.Join(src, "a.txt"), "hello world", 0644)
+
+	store := newMemObjectStore()
+	repo := newTestRepository(store, nil)
+
+	_, err := repo.Backup(src, "backup-1")
+	require.NoError(t, err)
+
+	// backup-1 is deleted, and its lock has gone, so all of its chunks can be pruned
+	require.NoError(t, repo.backupService.DeleteBackupDir("bucket", "backup-1"))
+	locks, err := repo.backupService.ListPodVolumeLocks("bucket")
+	require.NoError(t, err)
+	for _, lock := range locks {
+		require.NoError(t, repo.backupService.DeletePodVolumeLock("bucket", lock))
+	}
+
+	// the prune is held up deleting its first chunk until a backup has tried to start and
+	// backed off
+	var (
+		pruning       = make(chan struct{})
+		backedOff     = make(chan struct{})
+		pruningOnce   sync.Once
+		backedOffOnce sync.Once
+	)
+	store.onDelete = func(key string) {
+		switch {
+		case strings.HasPrefix(key, "podvolumes/chunks/"):
+			pruningOnce.Do(func() {
+				close(pruning)
+				<-backedOff
+			})
+		case strings.HasPrefix(key, "podvolumes/locks/backup-"):
+			backedOffOnce.Do(func() { close(backedOff) })
+		}
+	}
+
+	type pruneResult struct {
+		deleted int
+		err     error
+	}
+	pruneResults := make(chan pruneResult)
+	go func() {
+		deleted, err := repo.Prune()
+		pruneResults <- pruneResult{deleted, err}
+	}()
+	<-pruning
+
+	snapshotID, err := repo.Backup(src, "backup-2")
+	require.NoError(t, err)
+
+	pruned := <-pruneResults
+	require.NoError(t, pruned.err)
+	assert.Equal(t, 3, pruned.deleted)
+
+	// the backup waited for the prune to finish, so it uploaded the chunks again
+	chunks, err := repo.backupService.ListPodVolumeChunks("bucket")
+	require.NoError(t, err)
+	assert.Len(t, chunks, 3)
+
+	dst := tempDir(t)
+	defer os.RemoveAll(dst)
+	require.NoError(t, repo.Restore(dst, "backup-2", snapshotID))
+
+	data, err := ioutil.ReadFile(filepath.Join(dst, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(data))
+}
+
+func TestRepositoryRestoreMissingSnapshot(t *testing.T) {
+	dst := tempDir(t)
+	defer os.RemoveAll(dst)
+
+	repo := newTestRepository(newMemObjectStore(), nil)
+	assert.Error(t, repo.Restore(dst, "backup-1", "missing"))
+}
+
+func TestEntryPath(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, os.Symlink("/etc", filepath.Join(dir, "link")))
+
+	path, err := entryPath(dir, "sub/file")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "sub", "file"), path)
+
+	path, err = entryPath(dir, ".")
+	require.NoError(t, err)
+	assert.Equal(t, dir, path)
+
+	// the symlink itself can be replaced, but not written through
+	_, err = entryPath(dir, "link")
+	assert.NoError(t, err)
+
+	for _, invalid := range []string{"../file", "sub/../../file", "/etc/passwd", "link/passwd"} {
+		_, err := entryPath(dir, invalid)
+		assert.Error(t, err, invalid)
+	}
+}
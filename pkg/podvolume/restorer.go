@@ -0,0 +1,242 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podvolume
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
+)
+
+const (
+	// DefaultRestoreHelperImage is the image used for the init container that's added to
+	// restored pods to wait for their volumes to be restored.
+	DefaultRestoreHelperImage = "gcr.io/heptio-images/ark:latest"
+
+	// restoreHelperContainerName is the name of the init container added to restored pods.
+	restoreHelperContainerName = "ark-restore-helper"
+
+	// restoreHelperMountDir is the directory that the restore helper mounts volumes under.
+	restoreHelperMountDir = "/restores"
+)
+
+// Restorer restores the contents of pods' volumes.
+type Restorer interface {
+	// RestorePodVolumes creates a PodVolumeRestore for each of the restored pod's volumes that
+	// has a snapshot, and waits for the node agent to restore them.
+	RestorePodVolumes(restore *api.Restore, backup *api.Backup, pod *corev1api.Pod, log logrus.FieldLogger) []error
+}
+
+type restorer struct {
+	client  arkv1client.PodVolumeRestoresGetter
+	timeout time.Duration
+}
+
+// NewRestorer returns a Restorer that creates a PodVolumeRestore for each volume to restore,
+// and waits up to timeout for the node agent on the pod's node to process them.
+func NewRestorer(client arkv1client.PodVolumeRestoresGetter, timeout time.Duration) Restorer {
+	return &restorer{
+		client:  client,
+		timeout: timeout,
+	}
+}
+
+func (r *restorer) RestorePodVolumes(restore *api.Restore, backup *api.Backup, pod *corev1api.Pod, log logrus.FieldLogger) []error {
+	snapshots := GetSnapshotAnnotations(pod)
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	var (
+		pending []*api.PodVolumeRestore
+		errs    []error
+	)
+
+	for _, volume := range sortedKeys(snapshots) {
+		log.WithField("volume", volume).Info("Restoring pod volume")
+		created, err := r.client.PodVolumeRestores(api.DefaultNamespace).Create(newPodVolumeRestore(restore, backup, pod, volume, snapshots[volume]))
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "error creating PodVolumeRestore for volume %s", volume))
+			continue
+		}
+		pending = append(pending, created)
+	}
+
+	if len(pending) == 0 {
+		return errs
+	}
+
+	done := make(map[string]*api.PodVolumeRestore)
+	err := wait.PollImmediate(pollInterval, r.timeout, func() (bool, error) {
+		for _, pvr := range pending {
+			if _, ok := done[pvr.Name]; ok {
+				continue
+			}
+
+			current, err := r.client.PodVolumeRestores(pvr.Namespace).Get(pvr.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, errors.Wrapf(err, "error getting PodVolumeRestore %s", pvr.Name)
+			}
+
+			switch current.Status.Phase {
+			case api.PodVolumeRestorePhaseCompleted, api.PodVolumeRestorePhaseFailed:
+				done[pvr.Name] = current
+			}
+		}
+
+		return len(done) == len(pending), nil
+	})
+	if err != nil && err != wait.ErrWaitTimeout {
+		errs = append(errs, err)
+	}
+
+	for _, pvr := range pending {
+		current, ok := done[pvr.Name]
+		switch {
+		case !ok:
+			errs = append(errs, errors.Errorf("timed out waiting for restore of volume %s", pvr.Spec.Volume))
+		case current.Status.Phase == api.PodVolumeRestorePhaseFailed:
+			errs = append(errs, errors.Errorf("restore of volume %s failed: %s", pvr.Spec.Volume, current.Status.Message))
+		}
+	}
+
+	return errs
+}
+
+func newPodVolumeRestore(restore *api.Restore, backup *api.Backup, pod *corev1api.Pod, volume, snapshotID string) *api.PodVolumeRestore {
+	return &api.PodVolumeRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    api.DefaultNamespace,
+			GenerateName: restore.Name + "-",
+			Labels: map[string]string{
+				PodUIDLabel: string(pod.UID),
+			},
+			// PodVolumeRestores are deleted along with their restore
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: api.SchemeGroupVersion.String(),
+					Kind:       "Restore",
+					Name:       restore.Name,
+					UID:        restore.UID,
+				},
+			},
+		},
+		Spec: api.PodVolumeRestoreSpec{
+			Pod: corev1api.ObjectReference{
+				Kind:      "Pod",
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				UID:       pod.UID,
+			},
+			Volume:          volume,
+			SnapshotID:      snapshotID,
+			BackupName:      backup.Name,
+			StorageLocation: backup.Spec.StorageLocation,
+			RestoreUID:      string(restore.UID),
+		},
+		Status: api.PodVolumeRestoreStatus{
+			Phase: api.PodVolumeRestorePhaseNew,
+		},
+	}
+}
+
+// AddRestoreHelperInitContainer adds an init container to pod, ahead of any others, that waits
+// until each of pod's volumes that have snapshots have been restored. It returns whether the
+// container was added.
+func AddRestoreHelperInitContainer(pod *corev1api.Pod, restoreUID, image string) bool {
+	snapshots := GetSnapshotAnnotations(pod)
+
+	podVolumes := make(map[string]struct{}, len(pod.Spec.Volumes))
+	for _, volume := range pod.Spec.Volumes {
+		podVolumes[volume.Name] = struct{}{}
+	}
+
+	var (
+		mounts  []corev1api.VolumeMount
+		markers []string
+	)
+	for _, volume := range sortedKeys(snapshots) {
+		if _, found := podVolumes[volume]; !found {
+			continue
+		}
+
+		mountPath := path.Join(restoreHelperMountDir, volume)
+		mounts = append(mounts, corev1api.VolumeMount{Name: volume, MountPath: mountPath})
+		markers = append(markers, path.Join(mountPath, markerDir, restoreUID))
+	}
+
+	if len(mounts) == 0 {
+		return false
+	}
+
+	container := corev1api.Container{
+		Name:         restoreHelperContainerName,
+		Image:        image,
+		Command:      []string{"/bin/sh", "-c", waitForMarkersScript(markers)},
+		VolumeMounts: mounts,
+	}
+
+	pod.Spec.InitContainers = append([]corev1api.Container{container}, pod.Spec.InitContainers...)
+	return true
+}
+
+// IsRestoreHelperRunning returns whether pod's restore helper init container is running, which
+// means its volumes are mounted and ready to be restored into.
+func IsRestoreHelperRunning(pod *corev1api.Pod) bool {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Name == restoreHelperContainerName {
+			return status.State.Running != nil
+		}
+	}
+	return false
+}
+
+// MarkerPath returns the path, relative to the root of a volume, of the file that's written
+// once the volume has been restored for the restore with the given UID.
+func MarkerPath(restoreUID string) string {
+	return path.Join(markerDir, restoreUID)
+}
+
+func waitForMarkersScript(markers []string) string {
+	var checks []string
+	for _, marker := range markers {
+		checks = append(checks, fmt.Sprintf("[ -e %s ]", marker))
+	}
+
+	return fmt.Sprintf("until %s; do sleep 1; done", strings.Join(checks, " && "))
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podvolume
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestRestorePodVolumes(t *testing.T) {
+	pollInterval = time.Millisecond
+
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "podvolumerestores", func(action core.Action) (bool, runtime.Object, error) {
+		pvr := action.(core.CreateAction).GetObject().(*api.PodVolumeRestore)
+		pvr.Name = pvr.GenerateName + pvr.Spec.Volume
+
+		if pvr.Spec.Volume == "failed" {
+			pvr.Status = api.PodVolumeRestoreStatus{Phase: api.PodVolumeRestorePhaseFailed, Message: "disk on fire"}
+		} else {
+			pvr.Status = api.PodVolumeRestoreStatus{Phase: api.PodVolumeRestorePhaseCompleted}
+		}
+
+		return false, nil, nil
+	})
+
+	restore := arktest.NewTestRestore(api.DefaultNamespace, "restore-1", api.RestorePhaseInProgress).Restore
+	restore.UID = "restore-uid"
+	backup := arktest.NewTestBackup().WithName("backup-1").Backup
+	backup.Spec.StorageLocation = "loc-1"
+
+	pod := newTestPod(corev1api.PodPending, nil, "vol-1", "failed")
+	SetSnapshotAnnotation(pod, "vol-1", "snapshot-1")
+	SetSnapshotAnnotation(pod, "failed", "snapshot-2")
+
+	errs := NewRestorer(client.ArkV1(), time.Minute).RestorePodVolumes(restore, backup, pod, arktest.NewLogger())
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "restore of volume failed failed: disk on fire")
+
+	pvr, err := client.ArkV1().PodVolumeRestores(api.DefaultNamespace).Get("restore-1-vol-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, api.PodVolumeRestoreSpec{
+		Pod:             corev1api.ObjectReference{Kind: "Pod", Namespace: "ns-1", Name: "pod-1", UID: "pod-uid"},
+		Volume:          "vol-1",
+		SnapshotID:      "snapshot-1",
+		BackupName:      "backup-1",
+		StorageLocation: "loc-1",
+		RestoreUID:      "restore-uid",
+	}, pvr.Spec)
+	assert.Equal(t, "pod-uid", pvr.Labels[PodUIDLabel])
+
+	// pods without snapshots have nothing to restore
+	assert.Empty(t, NewRestorer(client.ArkV1(), time.Minute).RestorePodVolumes(restore, backup, newTestPod(corev1api.PodPending, nil), arktest.NewLogger()))
+}
+
+func TestAddRestoreHelperInitContainer(t *testing.T) {
+	pod := newTestPod(corev1api.PodPending, nil, "vol-1", "vol-2")
+	assert.False(t, AddRestoreHelperInitContainer(pod, "restore-uid", "image"))
+	assert.Empty(t, pod.Spec.InitContainers)
+
+	pod.Spec.InitContainers = []corev1api.Container{{Name: "existing"}}
+	SetSnapshotAnnotation(pod, "vol-2", "snapshot-2")
+	SetSnapshotAnnotation(pod, "vol-1", "snapshot-1")
+	SetSnapshotAnnotation(pod, "removed", "snapshot-3")
+
+	assert.True(t, AddRestoreHelperInitContainer(pod, "restore-uid", "image"))
+	require.Len(t, pod.Spec.InitContainers, 2)
+	assert.Equal(t, "existing", pod.Spec.InitContainers[1].Name)
+
+	helper := pod.Spec.InitContainers[0]
+	assert.Equal(t, "image", helper.Image)
+	assert.Equal(t, []corev1api.VolumeMount{
+		{Name: "vol-1", MountPath: "/restores/vol-1"},
+		{Name: "vol-2", MountPath: "/restores/vol-2"},
+	}, helper.VolumeMounts)
+	assert.Equal(t, []string{
+		"/bin/sh", "-c",
+		"until [ -e /restores/vol-1/.ark/restore-uid ] && [ -e /restores/vol-2/.ark/restore-uid ]; do sleep 1; done",
+	}, helper.Command)
+
+	assert.False(t, IsRestoreHelperRunning(pod))
+	pod.Status.InitContainerStatuses = []corev1api.ContainerStatus{
+		{Name: restoreHelperContainerName, State: corev1api.ContainerState{Running: &corev1api.ContainerStateRunning{}}},
+	}
+	assert.True(t, IsRestoreHelperRunning(pod))
+}
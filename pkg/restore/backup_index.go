@@ -0,0 +1,179 @@
+/*
+Copyright 2017 Heptio Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// defaultMemoryLimit is the amount of item data a backupIndex holds in memory before it starts
+// appending item data to a spool file on local disk.
+const defaultMemoryLimit = 128 * 1024 * 1024
+
+// backupIndex indexes the items in a backup tarball by resource and namespace. It's built while
+// reading the tarball stream once, and restores read items through it in priority order rather
+// than the order they appear in the tarball. Since the stream can't be read at random, the data
+// of each indexed item is kept: in memory until the index's memory limit is reached, and appended
+// to a single spool file after that. Once built, an index is safe for concurrent reads.
+type backupIndex struct {
+	memoryLimit int64
+	memoryUsed  int64
+
+	// spool is created the first time an item's data doesn't fit in memory.
+	spool     *os.File
+	spoolSize int64
+
+	// hasResources is whether the tarball has a top-level resources directory.
+	hasResources bool
+	// items holds the indexed items by resource and namespace. Cluster-scoped items are
+	// indexed under an empty namespace.
+	items map[string]map[string][]*indexEntry
+}
+
+// indexEntry is an item in a backupIndex.
+type indexEntry struct {
+	// path is the item's path within the backup tarball.
+	path string
+	// data is the item's data, if it's held in memory.
+	data []byte
+	// offset and size locate the item's data in the spool file otherwise.
+	offset int64
+	size   int64
+}
+
+func newBackupIndex(memoryLimit int64) *backupIndex {
+	return &backupIndex{
+		memoryLimit: memoryLimit,
+		items:       make(map[string]map[string][]*indexEntry),
+	}
+}
+
+// itemPathParts splits a path within a backup tarball into its parts. Items are at
+// resources/<resource>/cluster/<name>.json or
+// resources/<resource>/namespaces/<namespace>/<name>.json.
+func itemPathParts(path string) []string {
+	return strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+}
+
+// add indexes the item at path within the backup tarball, reading its data from r. Files that
+// aren't items are ignored.
+func (idx *backupIndex) add(path string, r io.Reader) error {
+	parts := itemPathParts(path)
+	if parts[0] != api.ResourcesDir {
+		return nil
+	}
+	idx.hasResources = true
+
+	var resource, namespace string
+	switch {
+	case len(parts) == 4 && parts[2] == api.ClusterScopedDir:
+		resource = parts[1]
+	case len(parts) == 5 && parts[2] == api.NamespaceScopedDir:
+		resource, namespace = parts[1], parts[3]
+	default:
+		return nil
+	}
+
+	entry := &indexEntry{path: path}
+
+	if idx.memoryUsed < idx.memoryLimit {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		entry.data = data
+		idx.memoryUsed += int64(len(data))
+	} else {
+		if idx.spool == nil {
+			spool, err := ioutil.TempFile("", "ark-restore-")
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			idx.spool = spool
+		}
+
+		n, err := io.Copy(idx.spool, r)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		entry.offset, entry.size = idx.spoolSize, n
+		idx.spoolSize += n
+	}
+
+	if idx.items[resource] == nil {
+		idx.items[resource] = make(map[string][]*indexEntry)
+	}
+	idx.items[resource][namespace] = append(idx.items[resource][namespace], entry)
+
+	return nil
+}
+
+// hasResource returns whether any items of resource are indexed.
+func (idx *backupIndex) hasResource(resource string) bool {
+	return len(idx.items[resource]) > 0
+}
+
+// namespaces returns the namespaces that resource has items indexed in, sorted by name.
+func (idx *backupIndex) namespaces(resource string) []string {
+	var namespaces []string
+	for namespace := range idx.items[resource] {
+		if namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+
+	return namespaces
+}
+
+// itemsIn returns the items of resource indexed in namespace, in the order they appear in the
+// tarball. Cluster-scoped items are returned for an empty namespace.
+func (idx *backupIndex) itemsIn(resource, namespace string) []*indexEntry {
+	return idx.items[resource][namespace]
+}
+
+// read returns entry's data.
+func (idx *backupIndex) read(entry *indexEntry) ([]byte, error) {
+	if entry.data != nil {
+		return entry.data, nil
+	}
+
+	data := make([]byte, entry.size)
+	if _, err := idx.spool.ReadAt(data, entry.offset); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return data, nil
+}
+
+// close removes the index's spool file, if it has one.
+func (idx *backupIndex) close() error {
+	if idx.spool == nil {
+		return nil
+	}
+
+	idx.spool.Close()
+	return errors.WithStack(os.Remove(idx.spool.Name()))
+}
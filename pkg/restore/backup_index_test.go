@@ -0,0 +1,140 @@
+/*
+Copyright 2017 Heptio Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"strings"
+	"testing"
+
+	testlogger "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+func TestBackupIndex(t *testing.T) {
+	index := newBackupIndex(10)
+
+	add := func(path, data string) {
+		require.NoError(t, index.add(path, strings.NewReader(data)))
+	}
+
+	// the first item fits in memory, and the rest are spooled to disk once the limit's reached
+	add("resources/pods/namespaces/ns-2/pod-1.json", "0123456789")
+	assert.Nil(t, index.spool)
+	add("resources/pods/namespaces/ns-1/pod-2.json", "abc")
+	add("resources/pods/namespaces/ns-1/pod-1.json", "def")
+	add("resources/persistentvolumes/cluster/pv-1.json", "ghi")
+	require.NotNil(t, index.spool)
+	assert.Equal(t, int64(10), index.memoryUsed)
+	assert.Equal(t, int64(9), index.spoolSize)
+
+	// files that aren't items aren't indexed
+	add("metadata/ark-backup.json", "{}")
+	add("resources/pods/namespaces/ns-1", "")
+
+	assert.True(t, index.hasResources)
+	assert.True(t, index.hasResource("pods"))
+	assert.False(t, index.hasResource("configmaps"))
+	assert.Equal(t, []string{"ns-1", "ns-2"}, index.namespaces("pods"))
+	assert.Empty(t, index.namespaces("persistentvolumes"))
+	assert.Empty(t, index.itemsIn("pods", ""))
+
+	read := func(entries []*indexEntry) []string {
+		var res []string
+		for _, entry := range entries {
+			data, err := index.read(entry)
+			require.NoError(t, err)
+			res = append(res, entry.path+"="+string(data))
+		}
+		return res
+	}
+
+	assert.Equal(t, []string{"resources/pods/namespaces/ns-2/pod-1.json=0123456789"}, read(index.itemsIn("pods", "ns-2")))
+	assert.Equal(t, []string{"resources/pods/namespaces/ns-1/pod-2.json=abc", "resources/pods/namespaces/ns-1/pod-1.json=def"}, read(index.itemsIn("pods", "ns-1")))
+	assert.Equal(t, []string{"resources/persistentvolumes/cluster/pv-1.json=ghi"}, read(index.itemsIn("persistentvolumes", "")))
+
+	// closing the index removes its spool file
+	spool := index.spool.Name()
+	require.NoError(t, index.close())
+	_, err := os.Stat(spool)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReadBackup(t *testing.T) {
+	buf := new(bytes.Buffer)
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+
+	for _, file := range []struct {
+		name     string
+		contents string
+	}{
+		{"metadata/ark-backup.json", `{"kind":"Backup"}`},
+		{"resources/configmaps/namespaces/ns-1/cm-1.json", "cm-1"},
+		{"resources/configmaps/namespaces/ns-2/cm-1.json", "cm-1 in ns-2"},
+		{"resources/persistentvolumes/cluster/pv-1.json", "pv-1"},
+		{"resources/secrets/namespaces/ns-1/secret-1.json", "secret-1"},
+	} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     file.name,
+			Size:     int64(len(file.contents)),
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+		}))
+		_, err := tw.Write([]byte(file.contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+
+	log, _ := testlogger.NewNullLogger()
+	ctx := &context{
+		resourceTiers: [][]schema.GroupResource{{{Resource: "configmaps"}, {Resource: "persistentvolumes"}}},
+		restore:       &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"ns-1"}}},
+		memoryLimit:   defaultMemoryLimit,
+		logger:        log,
+	}
+
+	index, err := ctx.readBackup(buf)
+	require.NoError(t, err)
+	defer index.close()
+
+	// only the items that could be restored are indexed
+	assert.True(t, index.hasResources)
+	assert.False(t, index.hasResource("secrets"))
+	assert.Equal(t, []string{"ns-1"}, index.namespaces("configmaps"))
+
+	items := index.itemsIn("configmaps", "ns-1")
+	require.Len(t, items, 1)
+	data, err := index.read(items[0])
+	require.NoError(t, err)
+	assert.Equal(t, "cm-1", string(data))
+
+	items = index.itemsIn("persistentvolumes", "")
+	require.Len(t, items, 1)
+	data, err = index.read(items[0])
+	require.NoError(t, err)
+	assert.Equal(t, "pv-1", string(data))
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+)
+
+// addDryRunItem adds obj to the dry-run report, instead of restoring it, as an item that would be
+// created or that already exists. Existing items are compared with the item in the cluster.
+func (ctx *context) addDryRunItem(resourceClient client.Dynamic, groupResource schema.GroupResource, obj *unstructured.Unstructured) error {
+	item := api.RestoreDryRunItem{
+		Resource:  groupResource.String(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}
+
+	live, err := resourceClient.Get(obj.GetName(), metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		item.Action = api.RestoreDryRunActionCreate
+	case err != nil:
+		return errors.WithStack(err)
+	default:
+		item.Action = api.RestoreDryRunActionExists
+		item.Message = ctx.existingItemMessage(groupResource)
+
+		// updating the item removes the fields that are only set in the cluster, apart from
+		// the ones it keeps, so they're compared with the item the update would leave
+		if ctx.restore.Spec.ExistingResourcePolicy == api.ExistingResourcePolicyUpdate {
+			updated, err := updatedItem(groupResource, obj, live)
+			if err != nil {
+				return err
+			}
+			item.Diff = diffFields("", updated.UnstructuredContent(), live.UnstructuredContent(), true)
+		} else {
+			item.Diff = diffFields("", obj.UnstructuredContent(), live.UnstructuredContent(), false)
+		}
+	}
+
+	ctx.dryRunReport.Items = append(ctx.dryRunReport.Items, item)
+	return nil
+}
+
+// addDryRunSkip adds obj to the dry-run report as an item that wouldn't be restored.
+func (ctx *context) addDryRunSkip(groupResource schema.GroupResource, namespace string, obj *unstructured.Unstructured, reason string) {
+	ctx.dryRunReport.Items = append(ctx.dryRunReport.Items, api.RestoreDryRunItem{
+		Resource:  groupResource.String(),
+		Namespace: namespace,
+		Name:      obj.GetName(),
+		Action:    api.RestoreDryRunActionSkip,
+		Message:   reason,
+	})
+}
+
+// existingItemMessage describes what the restore's ExistingResourcePolicy would do with an
+// existing item of the given resource.
+func (ctx *context) existingItemMessage(groupResource schema.GroupResource) string {
+	switch ctx.restore.Spec.ExistingResourcePolicy {
+	case api.ExistingResourcePolicyUpdate:
+		return "already exists and would be updated to match the backup"
+	case api.ExistingResourcePolicyRecreate:
+		if neverRecreatedResources[groupResource] {
+			return "already exists and would not be recreated, to avoid deleting its data"
+		}
+		return "already exists and would be deleted and recreated from the backup"
+	default:
+		return "already exists and would not be changed"
+	}
+}
+
+// diffFields returns the fields set in backup whose values differ from those in live, sorted by
+// path. Fields only set in live, such as those defaulted by the server, are only reported, with
+// no backup value, if liveOnly is true. The items' status isn't compared, since it isn't restored.
+func diffFields(path string, backup, live map[string]interface{}, liveOnly bool) []api.RestoreDryRunFieldDiff {
+	keys := make([]string, 0, len(backup))
+	for key := range backup {
+		keys = append(keys, key)
+	}
+	if liveOnly {
+		for key := range live {
+			if _, ok := backup[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	var diffs []api.RestoreDryRunFieldDiff
+	for _, key := range keys {
+		if path == "" && key == "status" {
+			continue
+		}
+
+		backupValue, backupSet := backup[key]
+		if !backupSet {
+			diffs = append(diffs, api.RestoreDryRunFieldDiff{Path: joinPath(path, key), Live: encodeValue(live[key])})
+			continue
+		}
+
+		liveValue, ok := live[key]
+		diffs = append(diffs, diffValues(joinPath(path, key), backupValue, liveValue, ok, liveOnly)...)
+	}
+
+	return diffs
+}
+
+func diffValues(path string, backup, live interface{}, liveSet, liveOnly bool) []api.RestoreDryRunFieldDiff {
+	if liveSet {
+		if backupMap, ok := backup.(map[string]interface{}); ok {
+			if liveMap, ok := live.(map[string]interface{}); ok {
+				return diffFields(path, backupMap, liveMap, liveOnly)
+			}
+		}
+
+		// lists of the same length are compared element by element, so a change to one
+		// container, for example, is reported as a change to that container
+		if backupList, ok := backup.([]interface{}); ok {
+			if liveList, ok := live.([]interface{}); ok && len(backupList) == len(liveList) {
+				var diffs []api.RestoreDryRunFieldDiff
+				for i := range backupList {
+					diffs = append(diffs, diffValues(fmt.Sprintf("%s[%d]", path, i), backupList[i], liveList[i], true, liveOnly)...)
+				}
+				return diffs
+			}
+		}
+
+		if reflect.DeepEqual(backup, live) {
+			return nil
+		}
+	}
+
+	diff := api.RestoreDryRunFieldDiff{
+		Path:   path,
+		Backup: encodeValue(backup),
+	}
+	if liveSet {
+		diff.Live = encodeValue(live)
+	}
+
+	return []api.RestoreDryRunFieldDiff{diff}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func encodeValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
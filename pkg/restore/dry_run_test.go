@@ -0,0 +1,184 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	testlogger "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestRestoreResourceDryRun(t *testing.T) {
+	resourceClient := &arktest.FakeDynamicClient{}
+	defer resourceClient.AssertExpectations(t)
+
+	// no Create calls are expected, so the mock fails the test if anything is created
+	live := toUnstructured(newNamedTestConfigMap("cm-2").ConfigMap)[0]
+	live.Object["data"] = map[string]interface{}{"foo": "baz", "extra": "value"}
+	live.SetUID("uid-1")
+	live.SetResourceVersion("42")
+	resourceClient.On("Get", "cm-1", metav1.GetOptions{}).Return((*unstructured.Unstructured)(nil), apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "cm-1"))
+	resourceClient.On("Get", "cm-2", metav1.GetOptions{}).Return(&live, nil)
+
+	dynamicFactory := &arktest.FakeDynamicFactory{}
+	resource := metav1.APIResource{Name: "configmaps", Namespaced: true}
+	dynamicFactory.On("ClientForGroupVersionResource", schema.GroupVersion{Version: "v1"}, resource, "ns-1").Return(resourceClient, nil)
+
+	log, _ := testlogger.NewNullLogger()
+
+	ctx := &context{
+		dynamicFactory: dynamicFactory,
+		index: newTestBackupIndex().
+			WithItem("resources/configmaps/namespaces/ns-1/cm-1.json", newNamedTestConfigMap("cm-1").ToJSON()).
+			WithItem("resources/configmaps/namespaces/ns-1/cm-2.json", newNamedTestConfigMap("cm-2").ToJSON()).
+			WithItem("resources/configmaps/namespaces/ns-1/cm-3.json", newNamedTestConfigMap("cm-3").WithControllerOwner().ToJSON()).
+			backupIndex,
+		selector: labels.NewSelector(),
+		restore: &api.Restore{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: api.DefaultNamespace,
+				Name:      "my-restore",
+			},
+			Spec: api.RestoreSpec{
+				DryRun:                 true,
+				ExistingResourcePolicy: api.ExistingResourcePolicyUpdate,
+			},
+		},
+		backup:       &api.Backup{},
+		logger:       log,
+		dryRunReport: &api.RestoreDryRunReport{},
+	}
+
+	warnings, errs := ctx.restoreResource("configmaps", "ns-1", ctx.index.itemsIn("configmaps", "ns-1"))
+	assert.Equal(t, api.RestoreResult{}, warnings)
+	assert.Equal(t, api.RestoreResult{}, errs)
+
+	expected := []api.RestoreDryRunItem{
+		{
+			Resource:  "configmaps",
+			Namespace: "ns-1",
+			Name:      "cm-1",
+			Action:    api.RestoreDryRunActionCreate,
+		},
+		{
+			Resource:  "configmaps",
+			Namespace: "ns-1",
+			Name:      "cm-2",
+			Action:    api.RestoreDryRunActionExists,
+			Message:   "already exists and would be updated to match the backup",
+			// the update would remove the field only set in the cluster, but would keep the
+			// server-managed metadata
+			Diff: []api.RestoreDryRunFieldDiff{
+				{Path: "data.extra", Live: `"value"`},
+				{Path: "data.foo", Backup: `"bar"`, Live: `"baz"`},
+			},
+		},
+		{
+			Resource:  "configmaps",
+			Namespace: "ns-1",
+			Name:      "cm-3",
+			Action:    api.RestoreDryRunActionSkip,
+			Message:   "has a controller owner, which would recreate it",
+		},
+	}
+	assert.Equal(t, expected, ctx.dryRunReport.Items)
+}
+
+func TestDiffFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		backup   map[string]interface{}
+		live     map[string]interface{}
+		liveOnly bool
+		expected []api.RestoreDryRunFieldDiff
+	}{
+		{
+			name:   "equal items have no differences",
+			backup: map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(1)}},
+			live:   map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(1)}},
+		},
+		{
+			name:     "changed values are reported by path",
+			backup:   map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(1)}},
+			live:     map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(3)}},
+			expected: []api.RestoreDryRunFieldDiff{{Path: "spec.replicas", Backup: "1", Live: "3"}},
+		},
+		{
+			name:     "fields missing from the live item have no live value",
+			backup:   map[string]interface{}{"spec": map[string]interface{}{"paused": true}},
+			live:     map[string]interface{}{"spec": map[string]interface{}{}},
+			expected: []api.RestoreDryRunFieldDiff{{Path: "spec.paused", Backup: "true"}},
+		},
+		{
+			name:   "fields only set in the live item and status are ignored",
+			backup: map[string]interface{}{"spec": map[string]interface{}{}, "status": map[string]interface{}{"phase": "a"}},
+			live:   map[string]interface{}{"spec": map[string]interface{}{"clusterIP": "10.0.0.1"}, "status": map[string]interface{}{"phase": "b"}},
+		},
+		{
+			name:     "fields only set in the live item are reported with no backup value if asked for",
+			backup:   map[string]interface{}{"spec": map[string]interface{}{"type": "a"}, "status": map[string]interface{}{"phase": "a"}},
+			live:     map[string]interface{}{"spec": map[string]interface{}{"type": "a", "clusterIP": "10.0.0.1"}, "extra": true, "status": map[string]interface{}{"phase": "b", "ready": true}},
+			liveOnly: true,
+			expected: []api.RestoreDryRunFieldDiff{{Path: "extra", Live: "true"}, {Path: "spec.clusterIP", Live: `"10.0.0.1"`}},
+		},
+		{
+			name: "fields only set in live list elements are reported if asked for",
+			backup: map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "a"},
+			}},
+			live: map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "a", "image": "a:1"},
+			}},
+			liveOnly: true,
+			expected: []api.RestoreDryRunFieldDiff{{Path: "containers[0].image", Live: `"a:1"`}},
+		},
+		{
+			name: "lists of the same length are compared by element",
+			backup: map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "a", "image": "a:1"},
+				map[string]interface{}{"name": "b", "image": "b:1"},
+			}},
+			live: map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "a", "image": "a:1"},
+				map[string]interface{}{"name": "b", "image": "b:2"},
+			}},
+			expected: []api.RestoreDryRunFieldDiff{{Path: "containers[1].image", Backup: `"b:1"`, Live: `"b:2"`}},
+		},
+		{
+			name:     "lists of different lengths are compared as a whole",
+			backup:   map[string]interface{}{"args": []interface{}{"a"}},
+			live:     map[string]interface{}{"args": []interface{}{"a", "b"}},
+			expected: []api.RestoreDryRunFieldDiff{{Path: "args", Backup: `["a"]`, Live: `["a","b"]`}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, diffFields("", test.backup, test.live, test.liveOnly))
+		})
+	}
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+// neverRecreatedResources are the resources that the recreate policy leaves in place, since
+// deleting them can delete the data in their volumes.
+var neverRecreatedResources = map[schema.GroupResource]bool{
+	{Resource: "persistentvolumes"}:      true,
+	{Resource: "persistentvolumeclaims"}: true,
+}
+
+// clusterAssignedFields are the fields, by resource, that the restorers remove from backed-up
+// items because the cluster assigns them. Updating an existing item keeps its values, since
+// clearing them would be rejected or would detach the item from what it's bound to.
+var clusterAssignedFields = map[schema.GroupResource][]string{
+	{Resource: "services"}:          {"spec.clusterIP"},
+	{Resource: "persistentvolumes"}: {"spec.claimRef", "spec.storageClassName"},
+	{Resource: "pods"}:              {"spec.nodeName"},
+}
+
+var (
+	// recreateDeleteTimeout is how long to wait for an existing item to be deleted before
+	// recreating it.
+	recreateDeleteTimeout = time.Minute
+	// recreatePollInterval is how often an existing item is checked while waiting for it to be
+	// deleted.
+	recreatePollInterval = time.Second
+)
+
+// restoreExistingItem applies the restore's ExistingResourcePolicy to obj, which couldn't be
+// created because it already exists. It returns the new item if the existing one was recreated,
+// or nil if it was left in place or updated, along with a warning describing the outcome.
+func (ctx *context) restoreExistingItem(resourceClient client.Dynamic, groupResource schema.GroupResource, obj *unstructured.Unstructured, existsErr error) (*unstructured.Unstructured, error, error) {
+	switch ctx.restore.Spec.ExistingResourcePolicy {
+	case api.ExistingResourcePolicyUpdate:
+		if err := updateExistingItem(resourceClient, groupResource, obj); err != nil {
+			return nil, nil, errors.Wrapf(err, "error updating existing %s %s", groupResource.String(), obj.GetName())
+		}
+		ctx.infof("Updated existing %s %s", groupResource.String(), obj.GetName())
+		return nil, errors.Errorf("%s %s already existed and was updated to match the backup", groupResource.String(), obj.GetName()), nil
+
+	case api.ExistingResourcePolicyRecreate:
+		if neverRecreatedResources[groupResource] {
+			return nil, errors.Errorf("%s %s already exists and was not recreated, to avoid deleting its data", groupResource.String(), obj.GetName()), nil
+		}
+
+		created, err := recreateExistingItem(resourceClient, obj)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error recreating existing %s %s", groupResource.String(), obj.GetName())
+		}
+		ctx.infof("Recreated existing %s %s", groupResource.String(), obj.GetName())
+		return created, errors.Errorf("%s %s already existed and was deleted and recreated from the backup", groupResource.String(), obj.GetName()), nil
+
+	default:
+		return nil, existsErr, nil
+	}
+}
+
+// updateExistingItem patches the existing item to match obj. Fields that were added to the
+// existing item since it was backed up are removed; only its server-managed metadata, its
+// status and any cluster-assigned fields, including a service's node ports, are left as they
+// are. The patch fails if the existing item changes while it's being built.
+func updateExistingItem(resourceClient client.Dynamic, groupResource schema.GroupResource, obj *unstructured.Unstructured) error {
+	existing, err := resourceClient.Get(obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	desired, err := updatedItem(groupResource, obj, existing)
+	if err != nil {
+		return err
+	}
+
+	patch := createMergePatch(existing.Object, desired.Object)
+
+	if resourceVersion := existing.GetResourceVersion(); resourceVersion != "" {
+		metadata, ok := patch["metadata"].(map[string]interface{})
+		if !ok {
+			metadata = make(map[string]interface{})
+			patch["metadata"] = metadata
+		}
+		metadata["resourceVersion"] = resourceVersion
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = resourceClient.Patch(obj.GetName(), types.MergePatchType, data)
+	return errors.WithStack(err)
+}
+
+// updatedItem returns the item that updating existing to match obj would leave: obj, with the
+// server-managed metadata, status and cluster-assigned fields of existing.
+func updatedItem(groupResource schema.GroupResource, obj, existing *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	desired := obj.DeepCopy()
+	if err := keepClusterManagedFields(desired.Object, existing.Object, clusterAssignedFields[groupResource]); err != nil {
+		return nil, err
+	}
+	if groupResource == (schema.GroupResource{Resource: "services"}) {
+		keepNodePorts(desired.Object, existing.Object)
+	}
+
+	return desired, nil
+}
+
+// keepClusterManagedFields copies the metadata that the server and controllers manage, the
+// status, and the given cluster-assigned fields from existing to desired. The metadata that
+// restorers keep from the backup - name, namespace, labels and annotations - comes from desired.
+func keepClusterManagedFields(desired, existing map[string]interface{}, assignedFields []string) error {
+	existingMetadata, err := collections.GetMap(existing, "metadata")
+	if err != nil {
+		return err
+	}
+	desiredMetadata, err := collections.GetMap(desired, "metadata")
+	if err != nil {
+		return err
+	}
+	for key, value := range existingMetadata {
+		switch key {
+		case "name", "namespace", "labels", "annotations":
+		default:
+			desiredMetadata[key] = value
+		}
+	}
+
+	if status, ok := existing["status"]; ok {
+		desired["status"] = status
+	} else {
+		delete(desired, "status")
+	}
+
+	for _, field := range assignedFields {
+		value, err := collections.GetValue(existing, field)
+		if err != nil {
+			// the existing item doesn't have a value to keep
+			continue
+		}
+
+		parentPath, key := "", field
+		if i := strings.LastIndex(field, "."); i >= 0 {
+			parentPath, key = field[:i], field[i+1:]
+		}
+
+		parent := desired
+		if parentPath != "" {
+			if parent, err = collections.GetMap(desired, parentPath); err != nil {
+				continue
+			}
+		}
+		parent[key] = value
+	}
+
+	return nil
+}
+
+// keepNodePorts copies the node ports allocated to the existing service's ports to the matching
+// ports of desired, which the service restorer removed them from. Since the merge patch replaces
+// the ports list as a whole, new node ports would be allocated otherwise. Ports are matched by
+// name, or by port number and protocol if they're unnamed.
+func keepNodePorts(desired, existing map[string]interface{}) {
+	// only these types of service have node ports, and setting them on others is rejected
+	if serviceType, _ := collections.GetString(desired, "spec.type"); serviceType != "NodePort" && serviceType != "LoadBalancer" {
+		return
+	}
+
+	existingPorts, err := collections.GetSlice(existing, "spec.ports")
+	if err != nil {
+		return
+	}
+	desiredPorts, err := collections.GetSlice(desired, "spec.ports")
+	if err != nil {
+		return
+	}
+
+	nodePorts := make(map[string]interface{})
+	for _, port := range existingPorts {
+		if p, ok := port.(map[string]interface{}); ok && p["nodePort"] != nil {
+			nodePorts[servicePortKey(p)] = p["nodePort"]
+		}
+	}
+
+	for _, port := range desiredPorts {
+		p, ok := port.(map[string]interface{})
+		if !ok || p["nodePort"] != nil {
+			continue
+		}
+		if nodePort, ok := nodePorts[servicePortKey(p)]; ok {
+			p["nodePort"] = nodePort
+		}
+	}
+}
+
+// servicePortKey returns the key that a service port is matched on when keeping node ports.
+func servicePortKey(port map[string]interface{}) string {
+	if name, _ := port["name"].(string); name != "" {
+		return "name/" + name
+	}
+
+	protocol, _ := port["protocol"].(string)
+	if protocol == "" {
+		protocol = "TCP"
+	}
+	return fmt.Sprintf("port/%v/%s", port["port"], protocol)
+}
+
+// createMergePatch returns the JSON merge patch (RFC 7386) that turns original into modified.
+// Keys that modified doesn't have are set to null, so they're removed. Lists are replaced as a
+// whole.
+func createMergePatch(original, modified map[string]interface{}) map[string]interface{} {
+	patch := make(map[string]interface{})
+
+	for key := range original {
+		if _, ok := modified[key]; !ok {
+			patch[key] = nil
+		}
+	}
+
+	for key, modifiedValue := range modified {
+		originalValue, ok := original[key]
+		if !ok {
+			patch[key] = modifiedValue
+			continue
+		}
+
+		originalMap, originalIsMap := originalValue.(map[string]interface{})
+		modifiedMap, modifiedIsMap := modifiedValue.(map[string]interface{})
+		if originalIsMap && modifiedIsMap {
+			if nested := createMergePatch(originalMap, modifiedMap); len(nested) > 0 {
+				patch[key] = nested
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(originalValue, modifiedValue) {
+			patch[key] = modifiedValue
+		}
+	}
+
+	return patch
+}
+
+// recreateExistingItem deletes the existing item, waits for it to be gone, and creates obj.
+func recreateExistingItem(resourceClient client.Dynamic, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if err := resourceClient.Delete(obj.GetName(), &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.WithStack(err)
+	}
+
+	err := wait.PollImmediate(recreatePollInterval, recreateDeleteTimeout, func() (bool, error) {
+		_, err := resourceClient.Get(obj.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, errors.WithStack(err)
+	})
+	if err == wait.ErrWaitTimeout {
+		return nil, errors.New("timed out waiting for the existing item to be deleted")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := resourceClient.Create(obj)
+	return created, errors.WithStack(err)
+}
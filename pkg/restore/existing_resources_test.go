@@ -0,0 +1,236 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	testlogger "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func newExistingResourcesContext(policy api.ExistingResourcePolicy) *context {
+	log, _ := testlogger.NewNullLogger()
+
+	return &context{
+		restore: &api.Restore{Spec: api.RestoreSpec{ExistingResourcePolicy: policy}},
+		logger:  log,
+	}
+}
+
+func TestRestoreExistingItem(t *testing.T) {
+	var (
+		configMaps = schema.GroupResource{Resource: "configmaps"}
+		existsErr  = apierrors.NewAlreadyExists(configMaps, "cm-1")
+		notFound   = apierrors.NewNotFound(configMaps, "cm-1")
+	)
+
+	newObj := func() *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"namespace": "ns-1", "name": "cm-1"},
+			"data":       map[string]interface{}{"key": "backed-up"},
+		}}
+	}
+
+	t.Run("none leaves the existing item and warns", func(t *testing.T) {
+		resourceClient := &arktest.FakeDynamicClient{}
+
+		created, warning, err := newExistingResourcesContext("").restoreExistingItem(resourceClient, configMaps, newObj(), existsErr)
+		require.NoError(t, err)
+		assert.Nil(t, created)
+		assert.Equal(t, existsErr, warning)
+		resourceClient.AssertExpectations(t)
+	})
+
+	t.Run("update patches the existing item", func(t *testing.T) {
+		resourceClient := &arktest.FakeDynamicClient{}
+		obj := newObj()
+		obj.Object["status"] = map[string]interface{}{"phase": "Backed-up"}
+
+		existing := newObj()
+		existing.Object["metadata"] = map[string]interface{}{
+			"namespace":       "ns-1",
+			"name":            "cm-1",
+			"uid":             "uid-1",
+			"resourceVersion": "42",
+			"labels":          map[string]interface{}{"added": "later"},
+		}
+		existing.Object["data"] = map[string]interface{}{"key": "changed", "extra": "added later"}
+		existing.Object["status"] = map[string]interface{}{"phase": "Active"}
+		resourceClient.On("Get", "cm-1", metav1.GetOptions{}).Return(existing, nil)
+
+		var patch map[string]interface{}
+		resourceClient.On("Patch", "cm-1", types.MergePatchType, mock.Anything).Run(func(args mock.Arguments) {
+			require.NoError(t, json.Unmarshal(args.Get(2).([]byte), &patch))
+		}).Return(newObj(), nil)
+
+		created, warning, err := newExistingResourcesContext(api.ExistingResourcePolicyUpdate).restoreExistingItem(resourceClient, configMaps, obj, existsErr)
+		require.NoError(t, err)
+		assert.Nil(t, created)
+		assert.EqualError(t, warning, "configmaps cm-1 already existed and was updated to match the backup")
+
+		// fields added since the backup are removed, while the server-managed metadata and
+		// status are left as they are
+		expected := map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": nil, "resourceVersion": "42"},
+			"data":     map[string]interface{}{"key": "backed-up", "extra": nil},
+		}
+		assert.Equal(t, expected, patch)
+		resourceClient.AssertExpectations(t)
+	})
+
+	t.Run("update keeps cluster-assigned fields", func(t *testing.T) {
+		resourceClient := &arktest.FakeDynamicClient{}
+		services := schema.GroupResource{Resource: "services"}
+
+		obj := newObj()
+		obj.Object["spec"] = map[string]interface{}{"type": "ClusterIP"}
+
+		existing := newObj()
+		existing.Object["spec"] = map[string]interface{}{"type": "ClusterIP", "clusterIP": "10.0.0.1", "sessionAffinity": "ClientIP"}
+		resourceClient.On("Get", "cm-1", metav1.GetOptions{}).Return(existing, nil)
+
+		var patch map[string]interface{}
+		resourceClient.On("Patch", "cm-1", types.MergePatchType, mock.Anything).Run(func(args mock.Arguments) {
+			require.NoError(t, json.Unmarshal(args.Get(2).([]byte), &patch))
+		}).Return(newObj(), nil)
+
+		_, _, err := newExistingResourcesContext(api.ExistingResourcePolicyUpdate).restoreExistingItem(resourceClient, services, obj, existsErr)
+		require.NoError(t, err)
+
+		expected := map[string]interface{}{
+			"spec": map[string]interface{}{"sessionAffinity": nil},
+		}
+		assert.Equal(t, expected, patch)
+		resourceClient.AssertExpectations(t)
+	})
+
+	t.Run("update keeps services' node ports", func(t *testing.T) {
+		resourceClient := &arktest.FakeDynamicClient{}
+		services := schema.GroupResource{Resource: "services"}
+
+		// the service restorer has removed the backed-up node ports
+		obj := newObj()
+		obj.Object["spec"] = map[string]interface{}{
+			"type": "NodePort",
+			"ports": []interface{}{
+				map[string]interface{}{"name": "http", "port": int64(80)},
+				map[string]interface{}{"port": int64(53), "protocol": "UDP"},
+				map[string]interface{}{"name": "metrics", "port": int64(9090)},
+			},
+		}
+
+		existing := newObj()
+		existing.Object["spec"] = map[string]interface{}{
+			"type":      "NodePort",
+			"clusterIP": "10.0.0.1",
+			"ports": []interface{}{
+				map[string]interface{}{"port": int64(53), "protocol": "UDP", "nodePort": int64(30053)},
+				map[string]interface{}{"name": "http", "port": int64(8080), "nodePort": int64(30080)},
+			},
+		}
+		resourceClient.On("Get", "cm-1", metav1.GetOptions{}).Return(existing, nil)
+
+		var patch map[string]interface{}
+		resourceClient.On("Patch", "cm-1", types.MergePatchType, mock.Anything).Run(func(args mock.Arguments) {
+			require.NoError(t, json.Unmarshal(args.Get(2).([]byte), &patch))
+		}).Return(newObj(), nil)
+
+		_, _, err := newExistingResourcesContext(api.ExistingResourcePolicyUpdate).restoreExistingItem(resourceClient, services, obj, existsErr)
+		require.NoError(t, err)
+
+		// ports are matched by name, or by port and protocol if they're unnamed, and ports that
+		// don't match any existing ones get new node ports
+		expected := map[string]interface{}{
+			"spec": map[string]interface{}{
+				"ports": []interface{}{
+					map[string]interface{}{"name": "http", "port": float64(80), "nodePort": float64(30080)},
+					map[string]interface{}{"port": float64(53), "protocol": "UDP", "nodePort": float64(30053)},
+					map[string]interface{}{"name": "metrics", "port": float64(9090)},
+				},
+			},
+		}
+		assert.Equal(t, expected, patch)
+		resourceClient.AssertExpectations(t)
+	})
+
+	t.Run("update error is returned", func(t *testing.T) {
+		resourceClient := &arktest.FakeDynamicClient{}
+		resourceClient.On("Get", "cm-1", metav1.GetOptions{}).Return(newObj(), nil)
+		resourceClient.On("Patch", "cm-1", types.MergePatchType, mock.Anything).Return((*unstructured.Unstructured)(nil), errors.New("field is immutable"))
+
+		_, warning, err := newExistingResourcesContext(api.ExistingResourcePolicyUpdate).restoreExistingItem(resourceClient, configMaps, newObj(), existsErr)
+		assert.NoError(t, warning)
+		assert.EqualError(t, err, "error updating existing configmaps cm-1: field is immutable")
+	})
+
+	t.Run("recreate deletes the existing item and creates it again", func(t *testing.T) {
+		resourceClient := &arktest.FakeDynamicClient{}
+		obj := newObj()
+
+		resourceClient.On("Delete", "cm-1", &metav1.DeleteOptions{}).Return(nil)
+		resourceClient.On("Get", "cm-1", metav1.GetOptions{}).Return((*unstructured.Unstructured)(nil), notFound)
+		resourceClient.On("Create", obj).Return(obj, nil)
+
+		created, warning, err := newExistingResourcesContext(api.ExistingResourcePolicyRecreate).restoreExistingItem(resourceClient, configMaps, obj, existsErr)
+		require.NoError(t, err)
+		assert.Equal(t, obj, created)
+		assert.EqualError(t, warning, "configmaps cm-1 already existed and was deleted and recreated from the backup")
+		resourceClient.AssertExpectations(t)
+	})
+
+	t.Run("recreate times out if the existing item isn't deleted", func(t *testing.T) {
+		defer func(timeout time.Duration, interval time.Duration) {
+			recreateDeleteTimeout, recreatePollInterval = timeout, interval
+		}(recreateDeleteTimeout, recreatePollInterval)
+		recreateDeleteTimeout, recreatePollInterval = 10*time.Millisecond, time.Millisecond
+
+		resourceClient := &arktest.FakeDynamicClient{}
+		resourceClient.On("Delete", "cm-1", &metav1.DeleteOptions{}).Return(nil)
+		resourceClient.On("Get", "cm-1", metav1.GetOptions{}).Return(newObj(), nil)
+
+		_, _, err := newExistingResourcesContext(api.ExistingResourcePolicyRecreate).restoreExistingItem(resourceClient, configMaps, newObj(), existsErr)
+		assert.EqualError(t, err, "error recreating existing configmaps cm-1: timed out waiting for the existing item to be deleted")
+	})
+
+	t.Run("recreate leaves persistent volume claims in place", func(t *testing.T) {
+		resourceClient := &arktest.FakeDynamicClient{}
+		pvcs := schema.GroupResource{Resource: "persistentvolumeclaims"}
+
+		created, warning, err := newExistingResourcesContext(api.ExistingResourcePolicyRecreate).restoreExistingItem(resourceClient, pvcs, newObj(), existsErr)
+		require.NoError(t, err)
+		assert.Nil(t, created)
+		assert.EqualError(t, warning, "persistentvolumeclaims cm-1 already exists and was not recreated, to avoid deleting its data")
+		resourceClient.AssertExpectations(t)
+	})
+}
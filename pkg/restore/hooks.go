@@ -0,0 +1,247 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	unstructuredconverter "k8s.io/apimachinery/pkg/conversion/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+var podsGroupResource = schema.GroupResource{Group: "", Resource: "pods"}
+
+const (
+	// defaultHookWaitTimeout is how long to wait for a restored pod to be running before
+	// giving up on its exec hooks, if the hook doesn't specify a WaitTimeout.
+	defaultHookWaitTimeout = 5 * time.Minute
+	// defaultHookExecTimeout is how long to wait for an exec hook to complete if the hook
+	// doesn't specify an ExecTimeout.
+	defaultHookExecTimeout = 30 * time.Second
+)
+
+// podRunningPollInterval is how often a restored pod is checked while waiting for it to be running.
+var podRunningPollInterval = 2 * time.Second
+
+// restoreHook is a RestoreResourceHookSpec with its namespaces and label selector resolved.
+type restoreHook struct {
+	name          string
+	namespaces    *collections.IncludesExcludes
+	labelSelector labels.Selector
+	hooks         []api.RestoreResourceHook
+}
+
+// applicableTo returns whether the hook applies to a pod in the given (restored) namespace with
+// the given labels.
+func (h restoreHook) applicableTo(namespace string, podLabels labels.Set) bool {
+	if h.namespaces != nil && !h.namespaces.ShouldInclude(namespace) {
+		return false
+	}
+	if h.labelSelector != nil && !h.labelSelector.Matches(podLabels) {
+		return false
+	}
+	return true
+}
+
+// getRestoreHooks resolves the hook specs from a restore.
+func getRestoreHooks(hookSpecs []api.RestoreResourceHookSpec) ([]restoreHook, error) {
+	restoreHooks := make([]restoreHook, 0, len(hookSpecs))
+
+	for _, spec := range hookSpecs {
+		h := restoreHook{
+			name:       spec.Name,
+			namespaces: collections.NewIncludesExcludes().Includes(spec.IncludedNamespaces...).Excludes(spec.ExcludedNamespaces...),
+			hooks:      spec.PostHooks,
+		}
+
+		if spec.LabelSelector != nil {
+			labelSelector, err := metav1.LabelSelectorAsSelector(spec.LabelSelector)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error parsing label selector for hook %s", spec.Name)
+			}
+			h.labelSelector = labelSelector
+		}
+
+		restoreHooks = append(restoreHooks, h)
+	}
+
+	return restoreHooks, nil
+}
+
+// pendingExecHook is an exec hook for a restored pod that's run once the pod is running.
+type pendingExecHook struct {
+	hookName  string
+	namespace string
+	podName   string
+	pod       map[string]interface{}
+	hook      *api.ExecRestoreHook
+}
+
+// applyInitHooks adds the init containers from any applicable init hooks to the pod, ahead of
+// the pod's existing init containers, and returns the applicable exec hooks. The exec hooks
+// are returned in order so they can be run once the pod has been created and is running.
+func (ctx *context) applyInitHooks(pod *unstructured.Unstructured) (*unstructured.Unstructured, []pendingExecHook, error) {
+	var (
+		initContainers []v1.Container
+		execHooks      []pendingExecHook
+	)
+
+	for _, restoreHook := range ctx.restoreHooks {
+		if !restoreHook.applicableTo(pod.GetNamespace(), labels.Set(pod.GetLabels())) {
+			continue
+		}
+
+		for _, hook := range restoreHook.hooks {
+			if hook.Init != nil {
+				ctx.infof("Adding %d init container(s) from hook %s to pod %s/%s", len(hook.Init.InitContainers), restoreHook.name, pod.GetNamespace(), pod.GetName())
+				initContainers = append(initContainers, hook.Init.InitContainers...)
+			}
+			if hook.Exec != nil {
+				execHooks = append(execHooks, pendingExecHook{
+					hookName:  restoreHook.name,
+					namespace: pod.GetNamespace(),
+					podName:   pod.GetName(),
+					hook:      hook.Exec,
+				})
+			}
+		}
+	}
+
+	if len(initContainers) > 0 {
+		typedPod := new(v1.Pod)
+		if err := unstructuredconverter.DefaultConverter.FromUnstructured(pod.UnstructuredContent(), typedPod); err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+
+		typedPod.Spec.InitContainers = append(initContainers, typedPod.Spec.InitContainers...)
+
+		content, err := unstructuredconverter.DefaultConverter.ToUnstructured(typedPod)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		pod = &unstructured.Unstructured{Object: content}
+	}
+
+	for i := range execHooks {
+		execHooks[i].pod = pod.UnstructuredContent()
+	}
+
+	return pod, execHooks, nil
+}
+
+// executePendingHooks waits for each restored pod with exec hooks to be running, then executes
+// its hooks in order. Hook failures are recorded as errors if the hook's OnError mode is Fail
+// (the default), or warnings otherwise. Once a hook fails in Fail mode, the pod's remaining
+// hooks are skipped.
+func (ctx *context) executePendingHooks(warnings, errs *api.RestoreResult) {
+	if len(ctx.pendingExecHooks) == 0 {
+		return
+	}
+
+	status := &api.HookStatus{}
+	failedPods := make(map[string]bool)
+
+	for _, pending := range ctx.pendingExecHooks {
+		podKey := pending.namespace + "/" + pending.podName
+		if failedPods[podKey] {
+			continue
+		}
+
+		log := ctx.logger.WithFields(logrus.Fields{
+			"hookName":  pending.hookName,
+			"hookType":  "exec",
+			"namespace": pending.namespace,
+			"name":      pending.podName,
+		})
+
+		status.HooksAttempted++
+
+		err := ctx.executeHook(log, pending)
+		if err == nil {
+			log.Info("Hook executed successfully")
+			continue
+		}
+
+		status.HooksFailed++
+		log.WithError(err).Error("Error executing hook")
+
+		err = fmt.Errorf("error executing hook %s in pod %s: %v", pending.hookName, podKey, err)
+		if pending.hook.OnError == api.HookErrorModeContinue {
+			addToResult(warnings, pending.namespace, err)
+			continue
+		}
+
+		addToResult(errs, pending.namespace, err)
+		failedPods[podKey] = true
+	}
+
+	ctx.infof("Hooks attempted: %d, hooks failed: %d", status.HooksAttempted, status.HooksFailed)
+	ctx.restore.Status.HookStatus = status
+}
+
+// executeHook waits for the hook's pod to be running, then runs the hook in it.
+func (ctx *context) executeHook(log *logrus.Entry, pending pendingExecHook) error {
+	waitTimeout := pending.hook.WaitTimeout.Duration
+	if waitTimeout == 0 {
+		waitTimeout = defaultHookWaitTimeout
+	}
+
+	err := wait.PollImmediate(podRunningPollInterval, waitTimeout, func() (bool, error) {
+		pod, err := ctx.podClient.Pods(pending.namespace).Get(pending.podName, metav1.GetOptions{})
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+
+		switch pod.Status.Phase {
+		case v1.PodRunning:
+			return true, nil
+		case v1.PodSucceeded, v1.PodFailed:
+			return false, errors.Errorf("pod is in phase %s", pod.Status.Phase)
+		}
+
+		return false, nil
+	})
+	if err != nil {
+		return errors.WithMessage(err, "error waiting for pod to be running")
+	}
+
+	execTimeout := pending.hook.ExecTimeout.Duration
+	if execTimeout == 0 {
+		execTimeout = defaultHookExecTimeout
+	}
+
+	hook := &api.ExecHook{
+		Container: pending.hook.Container,
+		Command:   pending.hook.Command,
+		OnError:   pending.hook.OnError,
+		Timeout:   metav1.Duration{Duration: execTimeout},
+	}
+
+	return ctx.podCommandExecutor.ExecutePodCommand(log, pending.pod, pending.namespace, pending.podName, pending.hookName, hook)
+}
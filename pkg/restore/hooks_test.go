@@ -0,0 +1,214 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	testlogger "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/util/collections"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestGetRestoreHooks(t *testing.T) {
+	hooks, err := getRestoreHooks([]api.RestoreResourceHookSpec{
+		{
+			Name:               "hook-1",
+			IncludedNamespaces: []string{"ns-1"},
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "db"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+
+	assert.True(t, hooks[0].applicableTo("ns-1", map[string]string{"app": "db"}))
+	assert.False(t, hooks[0].applicableTo("ns-2", map[string]string{"app": "db"}))
+	assert.False(t, hooks[0].applicableTo("ns-1", map[string]string{"app": "web"}))
+
+	_, err = getRestoreHooks([]api.RestoreResourceHookSpec{
+		{
+			Name: "bad-selector",
+			LabelSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "bad"}},
+			},
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestApplyInitHooks(t *testing.T) {
+	execHook := &api.ExecRestoreHook{Command: []string{"/bin/restore-done"}}
+
+	log, _ := testlogger.NewNullLogger()
+	ctx := &context{
+		logger: log,
+		restoreHooks: []restoreHook{
+			{
+				name: "init",
+				hooks: []api.RestoreResourceHook{
+					{Init: &api.InitRestoreHook{InitContainers: []v1.Container{{Name: "restore-init", Image: "busybox"}}}},
+					{Exec: execHook},
+				},
+			},
+			{
+				name:       "other-namespace",
+				namespaces: collections.NewIncludesExcludes().Includes("ns-2"),
+				hooks: []api.RestoreResourceHook{
+					{Init: &api.InitRestoreHook{InitContainers: []v1.Container{{Name: "not-added"}}}},
+				},
+			},
+		},
+	}
+
+	pod := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"namespace": "ns-1",
+				"name":      "pod-1",
+			},
+			"spec": map[string]interface{}{
+				"initContainers": []interface{}{
+					map[string]interface{}{"name": "existing-init"},
+				},
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app"},
+				},
+			},
+		},
+	}
+
+	res, execHooks, err := ctx.applyInitHooks(pod)
+	require.NoError(t, err)
+
+	initContainers, err := collections.GetSlice(res.UnstructuredContent(), "spec.initContainers")
+	require.NoError(t, err)
+	require.Len(t, initContainers, 2)
+	assert.Equal(t, "restore-init", initContainers[0].(map[string]interface{})["name"])
+	assert.Equal(t, "existing-init", initContainers[1].(map[string]interface{})["name"])
+
+	require.Len(t, execHooks, 1)
+	assert.Equal(t, "init", execHooks[0].hookName)
+	assert.Equal(t, "ns-1", execHooks[0].namespace)
+	assert.Equal(t, "pod-1", execHooks[0].podName)
+	assert.Equal(t, execHook, execHooks[0].hook)
+	assert.Equal(t, res.UnstructuredContent(), execHooks[0].pod)
+}
+
+type fakePodsGetter struct {
+	pods map[string]*v1.Pod
+}
+
+func (g *fakePodsGetter) Pods(namespace string) corev1.PodInterface {
+	return &fakePodClient{namespace: namespace, pods: g.pods}
+}
+
+type fakePodClient struct {
+	corev1.PodInterface
+	namespace string
+	pods      map[string]*v1.Pod
+}
+
+func (c *fakePodClient) Get(name string, opts metav1.GetOptions) (*v1.Pod, error) {
+	pod, ok := c.pods[c.namespace+"/"+name]
+	if !ok {
+		return nil, errors.New("pod not found")
+	}
+	return pod, nil
+}
+
+func TestExecutePendingHooks(t *testing.T) {
+	podRunningPollInterval = time.Millisecond
+
+	podInPhase := func(phase v1.PodPhase) *v1.Pod {
+		return &v1.Pod{Status: v1.PodStatus{Phase: phase}}
+	}
+
+	podContent := map[string]interface{}{"kind": "Pod"}
+	newHook := func(pod string, onError api.HookErrorMode, command ...string) pendingExecHook {
+		return pendingExecHook{
+			hookName:  "hook",
+			namespace: "ns-1",
+			podName:   pod,
+			pod:       podContent,
+			hook: &api.ExecRestoreHook{
+				Command:     command,
+				OnError:     onError,
+				WaitTimeout: metav1.Duration{Duration: 10 * time.Millisecond},
+			},
+		}
+	}
+
+	podCommandExecutor := &arktest.MockPodCommandExecutor{}
+	defer podCommandExecutor.AssertExpectations(t)
+
+	podCommandExecutor.On("ExecutePodCommand", mock.Anything, podContent, "ns-1", "running", "hook", mock.MatchedBy(commandIs("ok"))).Return(nil)
+	podCommandExecutor.On("ExecutePodCommand", mock.Anything, podContent, "ns-1", "running", "hook", mock.MatchedBy(commandIs("fail-continue"))).Return(errors.New("exit 1"))
+	podCommandExecutor.On("ExecutePodCommand", mock.Anything, podContent, "ns-1", "running", "hook", mock.MatchedBy(commandIs("fail"))).Return(errors.New("exit 2"))
+
+	restore := &api.Restore{}
+	log, _ := testlogger.NewNullLogger()
+	ctx := &context{
+		logger:             log,
+		restore:            restore,
+		podCommandExecutor: podCommandExecutor,
+		podClient: &fakePodsGetter{
+			pods: map[string]*v1.Pod{
+				"ns-1/running": podInPhase(v1.PodRunning),
+				"ns-1/pending": podInPhase(v1.PodPending),
+			},
+		},
+		pendingExecHooks: []pendingExecHook{
+			newHook("running", "", "ok"),
+			newHook("running", api.HookErrorModeContinue, "fail-continue"),
+			newHook("running", api.HookErrorModeFail, "fail"),
+			// skipped because a previous hook for the pod failed in Fail mode
+			newHook("running", "", "skipped"),
+			newHook("pending", "", "never-runs"),
+		},
+	}
+
+	warnings, errs := api.RestoreResult{}, api.RestoreResult{}
+	ctx.executePendingHooks(&warnings, &errs)
+
+	assert.Equal(t, &api.HookStatus{HooksAttempted: 4, HooksFailed: 3}, restore.Status.HookStatus)
+	assert.Equal(t, []string{"error executing hook hook in pod ns-1/running: exit 1"}, warnings.Namespaces["ns-1"])
+	require.Len(t, errs.Namespaces["ns-1"], 2)
+	assert.Equal(t, "error executing hook hook in pod ns-1/running: exit 2", errs.Namespaces["ns-1"][0])
+	assert.Contains(t, errs.Namespaces["ns-1"][1], "error executing hook hook in pod ns-1/pending: error waiting for pod to be running")
+}
+
+func commandIs(command string) func(*api.ExecHook) bool {
+	return func(h *api.ExecHook) bool {
+		return len(h.Command) == 1 && h.Command[0] == command && h.Timeout.Duration == defaultHookExecTimeout
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/restore/restorers"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+// crdsGroupResource is the resource of CustomResourceDefinitions, which are restored before
+// any other resources, since the other resources may be defined by them.
+var crdsGroupResource = schema.GroupResource{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}
+
+// resourceReadiness is how a restore waits for the items of a resource it creates to become
+// ready.
+type resourceReadiness struct {
+	conditions []string
+	timeout    time.Duration
+}
+
+// defaultResourceReadiness is how restores wait for items of resources that need to be ready
+// before the rest of the restore can continue, unless they're configured otherwise.
+var defaultResourceReadiness = map[schema.GroupResource]resourceReadiness{
+	// custom resources can't be created until their CRD is established
+	crdsGroupResource: {conditions: []string{"Established"}, timeout: time.Minute},
+}
+
+// resolveResourceReadiness returns the readiness of each resource that's waited for, combining
+// the defaults with the configured readiness, which takes precedence.
+func resolveResourceReadiness(config []api.ResourceReadiness) map[schema.GroupResource]resourceReadiness {
+	ret := make(map[schema.GroupResource]resourceReadiness)
+
+	for gr, readiness := range defaultResourceReadiness {
+		ret[gr] = readiness
+	}
+
+	for _, readiness := range config {
+		timeout := readiness.Timeout.Duration
+		if timeout <= 0 {
+			timeout = objectCreateWaitTimeout
+		}
+
+		ret[schema.ParseGroupResource(readiness.Resource)] = resourceReadiness{
+			conditions: readiness.Conditions,
+			timeout:    timeout,
+		}
+	}
+
+	return ret
+}
+
+// readyFunc returns a function that determines whether a restored item is ready, according to
+// both its resource's restorer, if it waits for items, and its resource's readiness conditions.
+func readyFunc(restorer restorers.ResourceRestorer, readiness resourceReadiness) func(runtime.Unstructured) bool {
+	return func(obj runtime.Unstructured) bool {
+		if restorer.Wait() && !restorer.Ready(obj) {
+			return false
+		}
+
+		for _, condition := range readiness.conditions {
+			if !hasTrueCondition(obj, condition) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// hasTrueCondition returns whether the item has a status condition of the given type whose
+// status is "True".
+func hasTrueCondition(obj runtime.Unstructured, conditionType string) bool {
+	conditions, err := collections.GetSlice(obj.UnstructuredContent(), "status.conditions")
+	if err != nil {
+		return false
+	}
+
+	for _, condition := range conditions {
+		fields, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if fields["type"] == conditionType && fields["status"] == "True" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/restore/restorers"
+)
+
+func TestResolveResourceReadiness(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   []api.ResourceReadiness
+		expected map[schema.GroupResource]resourceReadiness
+	}{
+		{
+			name: "CRDs are waited for by default",
+			expected: map[schema.GroupResource]resourceReadiness{
+				crdsGroupResource: {conditions: []string{"Established"}, timeout: time.Minute},
+			},
+		},
+		{
+			name: "configured resources are added, with a default timeout",
+			config: []api.ResourceReadiness{
+				{Resource: "foos.example.com", Conditions: []string{"Ready"}},
+				{Resource: "services", Timeout: metav1.Duration{Duration: time.Second}},
+			},
+			expected: map[schema.GroupResource]resourceReadiness{
+				crdsGroupResource:                        {conditions: []string{"Established"}, timeout: time.Minute},
+				{Group: "example.com", Resource: "foos"}: {conditions: []string{"Ready"}, timeout: objectCreateWaitTimeout},
+				{Resource: "services"}:                   {timeout: time.Second},
+			},
+		},
+		{
+			name: "configured CRD readiness replaces the default",
+			config: []api.ResourceReadiness{
+				{Resource: "customresourcedefinitions.apiextensions.k8s.io", Conditions: []string{"Established", "NamesAccepted"}, Timeout: metav1.Duration{Duration: 5 * time.Minute}},
+			},
+			expected: map[schema.GroupResource]resourceReadiness{
+				crdsGroupResource: {conditions: []string{"Established", "NamesAccepted"}, timeout: 5 * time.Minute},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, resolveResourceReadiness(test.config))
+		})
+	}
+}
+
+type fakeWaitingRestorer struct {
+	restorers.ResourceRestorer
+	wait  bool
+	ready bool
+}
+
+func (r *fakeWaitingRestorer) Wait() bool { return r.wait }
+
+func (r *fakeWaitingRestorer) Ready(obj runtime.Unstructured) bool { return r.ready }
+
+func TestReadyFunc(t *testing.T) {
+	established := unstructuredOrDie(`{"status":{"conditions":[{"type":"NamesAccepted","status":"True"},{"type":"Established","status":"True"}]}}`)
+	notEstablished := unstructuredOrDie(`{"status":{"conditions":[{"type":"Established","status":"False"}]}}`)
+	noStatus := unstructuredOrDie(`{}`)
+
+	tests := []struct {
+		name       string
+		restorer   *fakeWaitingRestorer
+		conditions []string
+		obj        runtime.Unstructured
+		expected   bool
+	}{
+		{
+			name:     "no conditions and a restorer that doesn't wait",
+			restorer: &fakeWaitingRestorer{},
+			obj:      noStatus,
+			expected: true,
+		},
+		{
+			name:     "restorer that waits and isn't ready",
+			restorer: &fakeWaitingRestorer{wait: true},
+			obj:      established,
+			expected: false,
+		},
+		{
+			name:       "conditions are true",
+			restorer:   &fakeWaitingRestorer{},
+			conditions: []string{"Established", "NamesAccepted"},
+			obj:        established,
+			expected:   true,
+		},
+		{
+			name:       "condition is false",
+			restorer:   &fakeWaitingRestorer{},
+			conditions: []string{"Established"},
+			obj:        notEstablished,
+			expected:   false,
+		},
+		{
+			name:       "condition is missing",
+			restorer:   &fakeWaitingRestorer{},
+			conditions: []string{"Established"},
+			obj:        noStatus,
+			expected:   false,
+		},
+		{
+			name:       "conditions are true but restorer isn't ready",
+			restorer:   &fakeWaitingRestorer{wait: true},
+			conditions: []string{"Established"},
+			obj:        established,
+			expected:   false,
+		},
+		{
+			name:       "conditions are true and restorer is ready",
+			restorer:   &fakeWaitingRestorer{wait: true, ready: true},
+			conditions: []string{"Established"},
+			obj:        established,
+			expected:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ready := readyFunc(test.restorer, resourceReadiness{conditions: test.conditions})
+			assert.Equal(t, test.expected, ready(test.obj))
+		})
+	}
+}
+
+func unstructuredOrDie(data string) *unstructured.Unstructured {
+	o := new(unstructured.Unstructured)
+	if err := json.Unmarshal([]byte(data), &o.Object); err != nil {
+		panic(err)
+	}
+	return o
+}
@@ -28,7 +28,7 @@ import (
 )
 
 // how long should we wait for certain objects (e.g. PVs, PVCs) to reach
-// their specified conditions before continuing on.
+// their specified conditions before continuing on, by default.
 const objectCreateWaitTimeout = 30 * time.Second
 
 // resourceWaiter knows how to wait for a set of registered items to become "ready" (according
@@ -39,13 +39,15 @@ type resourceWaiter struct {
 	watchChan <-chan watch.Event
 	items     sets.String
 	readyFunc func(runtime.Unstructured) bool
+	timeout   time.Duration
 }
 
-func newResourceWaiter(watchChan <-chan watch.Event, readyFunc func(runtime.Unstructured) bool) *resourceWaiter {
+func newResourceWaiter(watchChan <-chan watch.Event, readyFunc func(runtime.Unstructured) bool, timeout time.Duration) *resourceWaiter {
 	return &resourceWaiter{
 		watchChan: watchChan,
 		items:     sets.NewString(),
 		readyFunc: readyFunc,
+		timeout:   timeout,
 	}
 }
 
@@ -63,7 +65,7 @@ func (rw *resourceWaiter) Wait() error {
 			return nil
 		}
 
-		timeout := time.NewTimer(objectCreateWaitTimeout)
+		timeout := time.NewTimer(rw.timeout)
 
 		select {
 		case event := <-rw.watchChan:
@@ -23,9 +23,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 
@@ -33,7 +33,9 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	unstructuredconverter "k8s.io/apimachinery/pkg/conversion/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -43,6 +45,8 @@ import (
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/discovery"
 	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
+	"github.com/heptio/ark/pkg/podexec"
+	"github.com/heptio/ark/pkg/podvolume"
 	"github.com/heptio/ark/pkg/restore/restorers"
 	"github.com/heptio/ark/pkg/util/collections"
 	"github.com/heptio/ark/pkg/util/kube"
@@ -51,8 +55,10 @@ import (
 
 // Restorer knows how to restore a backup.
 type Restorer interface {
-	// Restore restores the backup data from backupReader, returning warnings and errors.
-	Restore(restore *api.Restore, backup *api.Backup, backupReader io.Reader, logFile io.Writer) (api.RestoreResult, api.RestoreResult)
+	// Restore restores the backup data from backupReader, returning warnings and errors. For a
+	// dry-run restore, nothing is restored, and a report of what would have been done is also
+	// returned.
+	Restore(restore *api.Restore, backup *api.Backup, backupReader io.Reader, logFile io.Writer, actions []ItemAction) (api.RestoreResult, api.RestoreResult, *api.RestoreDryRunReport)
 }
 
 var _ Restorer = &kubernetesRestorer{}
@@ -68,15 +74,39 @@ type kubernetesRestorer struct {
 	backupService      cloudprovider.BackupService
 	backupClient       arkv1client.BackupsGetter
 	namespaceClient    corev1.NamespaceInterface
+	podClient          corev1.PodsGetter
+	podCommandExecutor podexec.PodCommandExecutor
+	podVolumeRestorer  podvolume.Restorer
+	restoreHelperImage string
 	resourcePriorities []string
-	fileSystem         FileSystem
+	resourceReadiness  map[schema.GroupResource]resourceReadiness
+	workers            int
+	memoryLimit        int64
 	logger             *logrus.Logger
 }
 
-// prioritizeResources returns an ordered, fully-resolved list of resources to restore based on
-// the provided discovery helper, resource priorities, and included/excluded resources.
-func prioritizeResources(helper discovery.Helper, priorities []string, includedResources *collections.IncludesExcludes, logger *logrus.Logger) ([]schema.GroupResource, error) {
-	var ret []schema.GroupResource
+type resolvedAction struct {
+	ItemAction
+
+	resourceIncludesExcludes  *collections.IncludesExcludes
+	namespaceIncludesExcludes *collections.IncludesExcludes
+	selector                  labels.Selector
+}
+
+// logSetter is an interface for a type that allows a FieldLogger
+// to be set on it.
+type logSetter interface {
+	SetLog(logrus.FieldLogger)
+}
+
+// prioritizeResources returns an ordered, fully-resolved list of tiers of resources to restore
+// based on the provided discovery helper, resource priorities, and included/excluded resources.
+// CustomResourceDefinitions, if they're included, are in the first tier, since other resources
+// may be defined by them. Each of the prioritized resources is in a tier of its own, in priority
+// order, followed by a single tier of all of the other resources, sorted by name. The tiers are
+// restored one after another, but the resources within a tier can be restored at the same time.
+func prioritizeResources(helper discovery.Helper, priorities []string, includedResources *collections.IncludesExcludes, logger *logrus.Logger) ([][]schema.GroupResource, error) {
+	var ret [][]schema.GroupResource
 
 	// set keeps track of resolved GroupResource names
 	set := sets.NewString()
@@ -89,16 +119,24 @@ func prioritizeResources(helper discovery.Helper, priorities []string, includedR
 		}
 		gr := gvr.GroupResource()
 
+		// CRDs are always restored first
+		if gr == crdsGroupResource {
+			continue
+		}
+
 		if !includedResources.ShouldInclude(gr.String()) {
 			logger.WithField("groupResource", gr).Info("Not including resource")
 			continue
 		}
-		ret = append(ret, gr)
+		ret = append(ret, []schema.GroupResource{gr})
 		set.Insert(gr.String())
 	}
 
 	// go through everything we got from discovery and add anything not in "set" to byName
-	var byName []schema.GroupResource
+	var (
+		byName      []schema.GroupResource
+		includeCRDs bool
+	)
 	for _, resourceGroup := range helper.Resources() {
 		// will be something like storage.k8s.io/v1
 		groupVersion, err := schema.ParseGroupVersion(resourceGroup.GroupVersion)
@@ -114,6 +152,11 @@ func prioritizeResources(helper discovery.Helper, priorities []string, includedR
 				continue
 			}
 
+			if gr == crdsGroupResource {
+				includeCRDs = true
+				continue
+			}
+
 			if !set.Has(gr.String()) {
 				byName = append(byName, gr)
 			}
@@ -126,12 +169,40 @@ func prioritizeResources(helper discovery.Helper, priorities []string, includedR
 	})
 
 	// combine prioritized with by-name
-	ret = append(ret, byName...)
+	if len(byName) > 0 {
+		ret = append(ret, byName)
+	}
+
+	if includeCRDs {
+		ret = append([][]schema.GroupResource{{crdsGroupResource}}, ret...)
+	}
+
+	return ret, nil
+}
+
+// discoveredResources returns the names of all of the resources that the discovery helper knows
+// about.
+func discoveredResources(helper discovery.Helper) (sets.String, error) {
+	ret := sets.NewString()
+
+	for _, resourceGroup := range helper.Resources() {
+		groupVersion, err := schema.ParseGroupVersion(resourceGroup.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resource := range resourceGroup.APIResources {
+			gr := groupVersion.WithResource(resource.Name).GroupResource()
+			ret.Insert(gr.String())
+		}
+	}
 
 	return ret, nil
 }
 
-// NewKubernetesRestorer creates a new kubernetesRestorer.
+// NewKubernetesRestorer creates a new kubernetesRestorer. Up to workers items of each resource
+// are created at once, and up to workers resources that aren't prioritized are restored at once,
+// so up to workers² items can be created at once.
 func NewKubernetesRestorer(
 	discoveryHelper discovery.Helper,
 	dynamicFactory client.DynamicFactory,
@@ -140,6 +211,12 @@ func NewKubernetesRestorer(
 	resourcePriorities []string,
 	backupClient arkv1client.BackupsGetter,
 	namespaceClient corev1.NamespaceInterface,
+	podClient corev1.PodsGetter,
+	podCommandExecutor podexec.PodCommandExecutor,
+	podVolumeRestorer podvolume.Restorer,
+	restoreHelperImage string,
+	workers int,
+	resourceReadiness []api.ResourceReadiness,
 	logger *logrus.Logger,
 ) (Restorer, error) {
 	r := make(map[schema.GroupResource]restorers.ResourceRestorer)
@@ -158,16 +235,23 @@ func NewKubernetesRestorer(
 		backupService:      backupService,
 		backupClient:       backupClient,
 		namespaceClient:    namespaceClient,
+		podClient:          podClient,
+		podCommandExecutor: podCommandExecutor,
+		podVolumeRestorer:  podVolumeRestorer,
+		restoreHelperImage: restoreHelperImage,
 		resourcePriorities: resourcePriorities,
-		fileSystem:         &osFileSystem{},
+		resourceReadiness:  resolveResourceReadiness(resourceReadiness),
+		workers:            workers,
+		memoryLimit:        defaultMemoryLimit,
 		logger:             logger,
 	}, nil
 }
 
 // Restore executes a restore into the target Kubernetes cluster according to the restore spec
 // and using data from the provided backup/backup reader. Returns a warnings and errors RestoreResult,
-// respectively, summarizing info about the restore.
-func (kr *kubernetesRestorer) Restore(restore *api.Restore, backup *api.Backup, backupReader io.Reader, logFile io.Writer) (api.RestoreResult, api.RestoreResult) {
+// respectively, summarizing info about the restore, and the dry-run report if the restore is a
+// dry run.
+func (kr *kubernetesRestorer) Restore(restore *api.Restore, backup *api.Backup, backupReader io.Reader, logFile io.Writer, actions []ItemAction) (api.RestoreResult, api.RestoreResult, *api.RestoreDryRunReport) {
 	// metav1.LabelSelectorAsSelector converts a nil LabelSelector to a
 	// Nothing Selector, i.e. a selector that matches nothing. We want
 	// a selector that matches everything. This can be accomplished by
@@ -179,13 +263,92 @@ func (kr *kubernetesRestorer) Restore(restore *api.Restore, backup *api.Backup,
 
 	selector, err := metav1.LabelSelectorAsSelector(ls)
 	if err != nil {
-		return api.RestoreResult{}, api.RestoreResult{Ark: []string{err.Error()}}
+		return api.RestoreResult{}, api.RestoreResult{Ark: []string{err.Error()}}, nil
+	}
+
+	resourceTiers, err := kr.resourceTiers(restore)
+	if err != nil {
+		return api.RestoreResult{}, api.RestoreResult{Ark: []string{err.Error()}}, nil
+	}
+
+	knownResources, err := discoveredResources(kr.discoveryHelper)
+	if err != nil {
+		return api.RestoreResult{}, api.RestoreResult{Ark: []string{err.Error()}}, nil
 	}
 
-	// get resource includes-excludes
-	resourceIncludesExcludes := collections.GenerateIncludesExcludes(
-		restore.Spec.IncludedResources,
-		restore.Spec.ExcludedResources,
+	resolvedActions, err := kr.resolveActions(actions)
+	if err != nil {
+		return api.RestoreResult{}, api.RestoreResult{Ark: []string{err.Error()}}, nil
+	}
+
+	restoreHooks, err := getRestoreHooks(restore.Spec.Hooks.Resources)
+	if err != nil {
+		return api.RestoreResult{}, api.RestoreResult{Ark: []string{err.Error()}}, nil
+	}
+
+	gzippedLog := gzip.NewWriter(logFile)
+	defer gzippedLog.Close()
+
+	log := logrus.New()
+	log.Out = gzippedLog
+	log.Hooks.Add(&logging.ErrorLocationHook{})
+	log.Hooks.Add(&logging.LogLocationHook{})
+
+	ctx := &context{
+		backup:             backup,
+		backupReader:       backupReader,
+		restore:            restore,
+		resourceTiers:      resourceTiers,
+		knownResources:     knownResources,
+		resourceReadiness:  kr.resourceReadiness,
+		workers:            kr.workers,
+		selector:           selector,
+		logger:             log,
+		dynamicFactory:     kr.dynamicFactory,
+		memoryLimit:        kr.memoryLimit,
+		namespaceClient:    kr.namespaceClient,
+		restorers:          kr.restorers,
+		actions:            resolvedActions,
+		restoreHooks:       restoreHooks,
+		podClient:          kr.podClient,
+		podCommandExecutor: kr.podCommandExecutor,
+		podVolumeRestorer:  kr.podVolumeRestorer,
+		restoreHelperImage: kr.restoreHelperImage,
+		refreshResourceTiers: func() ([][]schema.GroupResource, error) {
+			if err := kr.discoveryHelper.Refresh(); err != nil {
+				return nil, fmt.Errorf("error refreshing discovery: %v", err)
+			}
+			return kr.resourceTiers(restore)
+		},
+	}
+
+	if restore.Spec.DryRun {
+		ctx.dryRunReport = &api.RestoreDryRunReport{Items: []api.RestoreDryRunItem{}}
+		// dry runs don't create anything, and restoring one resource at a time keeps
+		// the report's items in order
+		ctx.workers = 1
+	}
+
+	warnings, errs := ctx.execute()
+
+	return warnings, errs, ctx.dryRunReport
+}
+
+// resourceTiers returns the tiers of resources to restore for the restore, based on the current
+// discovery information.
+func (kr *kubernetesRestorer) resourceTiers(restore *api.Restore) ([][]schema.GroupResource, error) {
+	resourceIncludesExcludes := kr.getResourceIncludesExcludes(restore.Spec.IncludedResources, restore.Spec.ExcludedResources)
+
+	return prioritizeResources(kr.discoveryHelper, kr.resourcePriorities, resourceIncludesExcludes, kr.logger)
+}
+
+// getResourceIncludesExcludes takes the lists of resources to include and exclude, uses the
+// discovery helper to resolve them to fully-qualified group-resource names, and returns an
+// IncludesExcludes list.
+func (kr *kubernetesRestorer) getResourceIncludesExcludes(includes, excludes []string) *collections.IncludesExcludes {
+	return collections.GenerateIncludesExcludes(
+		includes,
+		excludes,
 		func(item string) string {
 			gvr, _, err := kr.discoveryHelper.ResourceFor(schema.ParseGroupResource(item).WithVersion(""))
 			if err != nil {
@@ -197,47 +360,69 @@ func (kr *kubernetesRestorer) Restore(restore *api.Restore, backup *api.Backup,
 			return gr.String()
 		},
 	)
+}
 
-	prioritizedResources, err := prioritizeResources(kr.discoveryHelper, kr.resourcePriorities, resourceIncludesExcludes, kr.logger)
-	if err != nil {
-		return api.RestoreResult{}, api.RestoreResult{Ark: []string{err.Error()}}
-	}
+// resolveActions gets the resource selector for each of the provided actions and resolves
+// it into includes/excludes lists and a label selector.
+func (kr *kubernetesRestorer) resolveActions(actions []ItemAction) ([]resolvedAction, error) {
+	var resolved []resolvedAction
 
-	gzippedLog := gzip.NewWriter(logFile)
-	defer gzippedLog.Close()
+	for _, action := range actions {
+		resourceSelector, err := action.AppliesTo()
+		if err != nil {
+			return nil, err
+		}
 
-	log := logrus.New()
-	log.Out = gzippedLog
-	log.Hooks.Add(&logging.ErrorLocationHook{})
-	log.Hooks.Add(&logging.LogLocationHook{})
+		resources := kr.getResourceIncludesExcludes(resourceSelector.IncludedResources, resourceSelector.ExcludedResources)
+		namespaces := collections.NewIncludesExcludes().Includes(resourceSelector.IncludedNamespaces...).Excludes(resourceSelector.ExcludedNamespaces...)
 
-	ctx := &context{
-		backup:               backup,
-		backupReader:         backupReader,
-		restore:              restore,
-		prioritizedResources: prioritizedResources,
-		selector:             selector,
-		logger:               log,
-		dynamicFactory:       kr.dynamicFactory,
-		fileSystem:           kr.fileSystem,
-		namespaceClient:      kr.namespaceClient,
-		restorers:            kr.restorers,
+		selector := labels.Everything()
+		if resourceSelector.LabelSelector != "" {
+			if selector, err = labels.Parse(resourceSelector.LabelSelector); err != nil {
+				return nil, err
+			}
+		}
+
+		resolved = append(resolved, resolvedAction{
+			ItemAction:                action,
+			resourceIncludesExcludes:  resources,
+			namespaceIncludesExcludes: namespaces,
+			selector:                  selector,
+		})
 	}
 
-	return ctx.execute()
+	return resolved, nil
 }
 
 type context struct {
 	backup               *api.Backup
 	backupReader         io.Reader
 	restore              *api.Restore
-	prioritizedResources []schema.GroupResource
+	resourceTiers        [][]schema.GroupResource
+	knownResources       sets.String
+	refreshResourceTiers func() ([][]schema.GroupResource, error)
+	resourceReadiness    map[schema.GroupResource]resourceReadiness
+	workers              int
 	selector             labels.Selector
 	logger               *logrus.Logger
 	dynamicFactory       client.DynamicFactory
-	fileSystem           FileSystem
+	memoryLimit          int64
+	index                *backupIndex
 	namespaceClient      corev1.NamespaceInterface
 	restorers            map[schema.GroupResource]restorers.ResourceRestorer
+	actions              []resolvedAction
+	actionLock           sync.Mutex
+	restoreHooks         []restoreHook
+	podClient            corev1.PodsGetter
+	podCommandExecutor   podexec.PodCommandExecutor
+	pendingExecHooksLock sync.Mutex
+	pendingExecHooks     []pendingExecHook
+	podVolumeRestorer    podvolume.Restorer
+	restoreHelperImage   string
+	podVolumeWaitGroup   sync.WaitGroup
+	podVolumeErrorsLock  sync.Mutex
+	podVolumeErrors      api.RestoreResult
+	dryRunReport         *api.RestoreDryRunReport
 }
 
 func (ctx *context) infof(msg string, args ...interface{}) {
@@ -247,103 +432,163 @@ func (ctx *context) infof(msg string, args ...interface{}) {
 func (ctx *context) execute() (api.RestoreResult, api.RestoreResult) {
 	ctx.infof("Starting restore of backup %s", kube.NamespaceAndName(ctx.backup))
 
-	dir, err := ctx.unzipAndExtractBackup(ctx.backupReader)
+	index, err := ctx.readBackup(ctx.backupReader)
 	if err != nil {
-		ctx.infof("error unzipping and extracting: %v", err)
+		ctx.infof("error reading backup: %v", err)
 		return api.RestoreResult{}, api.RestoreResult{Ark: []string{err.Error()}}
 	}
-	defer ctx.fileSystem.RemoveAll(dir)
+	defer index.close()
+	ctx.index = index
+
+	warnings, errs := ctx.restoreFromIndex()
+
+	// pod volumes are restored in the background while the rest of the items are restored,
+	// since the pods can't start until the items they depend on exist
+	ctx.podVolumeWaitGroup.Wait()
+	merge(&errs, &ctx.podVolumeErrors)
 
-	return ctx.restoreFromDir(dir)
+	// exec hooks are run once all items have been restored, since the restored pods may
+	// depend on other items (e.g. volumes) in order to start running.
+	ctx.executePendingHooks(&warnings, &errs)
+
+	return warnings, errs
 }
 
-// restoreFromDir executes a restore based on backup data contained within a local
-// directory.
-func (ctx *context) restoreFromDir(dir string) (api.RestoreResult, api.RestoreResult) {
+// restoreFromIndex executes a restore based on the backup data in the context's
+// backup index.
+func (ctx *context) restoreFromIndex() (api.RestoreResult, api.RestoreResult) {
 	warnings, errs := api.RestoreResult{}, api.RestoreResult{}
 
-	namespaceFilter := collections.NewIncludesExcludes().Includes(ctx.restore.Spec.IncludedNamespaces...).Excludes(ctx.restore.Spec.ExcludedNamespaces...)
+	namespaceFilter := ctx.namespaceIncludesExcludes()
 
 	// Make sure the top level "resources" dir exists:
-	resourcesDir := filepath.Join(dir, api.ResourcesDir)
-	rde, err := ctx.fileSystem.DirExists(resourcesDir)
-	if err != nil {
-		addArkError(&errs, err)
-		return warnings, errs
-	}
-	if !rde {
+	if !ctx.index.hasResources {
 		addArkError(&errs, errors.New("backup does not contain top level resources directory"))
-	}
-
-	resourceDirs, err := ctx.fileSystem.ReadDir(resourcesDir)
-	if err != nil {
-		addArkError(&errs, err)
 		return warnings, errs
 	}
 
-	resourceDirsMap := make(map[string]os.FileInfo)
+	var (
+		tiers    = ctx.resourceTiers
+		restored = sets.NewString()
+	)
 
-	for _, rscDir := range resourceDirs {
-		rscName := rscDir.Name()
-		resourceDirsMap[rscName] = rscDir
-	}
+	for i := 0; i < len(tiers); i++ {
+		w, e := ctx.restoreTier(tiers[i], namespaceFilter)
+		merge(&warnings, &w)
+		merge(&errs, &e)
 
-	for _, resource := range ctx.prioritizedResources {
-		rscDir := resourceDirsMap[resource.String()]
-		if rscDir == nil {
-			continue
+		restoredCRDs := false
+		for _, resource := range tiers[i] {
+			restored.Insert(resource.String())
+			restoredCRDs = restoredCRDs || (resource == crdsGroupResource && ctx.index.hasResource(resource.String()))
 		}
 
-		resourcePath := filepath.Join(resourcesDir, rscDir.Name())
+		// resources defined by the restored CRDs aren't known to discovery until now, so
+		// the remaining tiers are worked out again. Dry runs don't restore the CRDs.
+		if restoredCRDs && ctx.dryRunReport == nil {
+			ctx.infof("Refreshing discovery after restoring CustomResourceDefinitions")
 
-		clusterSubDir := filepath.Join(resourcePath, api.ClusterScopedDir)
-		clusterSubDirExists, err := ctx.fileSystem.DirExists(clusterSubDir)
-		if err != nil {
-			addArkError(&errs, err)
-			return warnings, errs
+			refreshed, err := ctx.refreshResourceTiers()
+			if err != nil {
+				addArkError(&errs, err)
+				return warnings, errs
+			}
+
+			tiers = append(tiers[:i+1:i+1], unrestoredTiers(refreshed, restored)...)
 		}
-		if clusterSubDirExists {
-			w, e := ctx.restoreResource(resource.String(), "", clusterSubDir)
-			merge(&warnings, &w)
-			merge(&errs, &e)
-			continue
+	}
+
+	return warnings, errs
+}
+
+// unrestoredTiers returns the tiers without the resources that have already been restored,
+// leaving out any tiers that are left empty.
+func unrestoredTiers(tiers [][]schema.GroupResource, restored sets.String) [][]schema.GroupResource {
+	var ret [][]schema.GroupResource
+
+	for _, tier := range tiers {
+		var unrestored []schema.GroupResource
+		for _, resource := range tier {
+			if !restored.Has(resource.String()) {
+				unrestored = append(unrestored, resource)
+			}
 		}
 
-		nsSubDir := filepath.Join(resourcePath, api.NamespaceScopedDir)
-		nsSubDirExists, err := ctx.fileSystem.DirExists(nsSubDir)
-		if err != nil {
-			addArkError(&errs, err)
-			return warnings, errs
+		if len(unrestored) > 0 {
+			ret = append(ret, unrestored)
 		}
-		if !nsSubDirExists {
+	}
+
+	return ret
+}
+
+// tierResult is the result of restoring one of the resources in a tier.
+type tierResult struct {
+	warnings api.RestoreResult
+	errs     api.RestoreResult
+}
+
+// restoreTier restores the resources in a tier, up to ctx.workers of them at once, and returns
+// their combined results in the tier's order.
+func (ctx *context) restoreTier(tier []schema.GroupResource, namespaceFilter *collections.IncludesExcludes) (api.RestoreResult, api.RestoreResult) {
+	var (
+		results = make([]tierResult, len(tier))
+		limit   = make(chan struct{}, workerCount(ctx.workers))
+		wg      sync.WaitGroup
+	)
+
+	for i, resource := range tier {
+		if !ctx.index.hasResource(resource.String()) {
 			continue
 		}
 
-		nsDirs, err := ctx.fileSystem.ReadDir(nsSubDir)
-		if err != nil {
-			addArkError(&errs, err)
-			return warnings, errs
-		}
+		limit <- struct{}{}
+		wg.Add(1)
 
-		for _, nsDir := range nsDirs {
-			if !nsDir.IsDir() {
-				continue
-			}
-			nsName := nsDir.Name()
-			nsPath := filepath.Join(nsSubDir, nsName)
+		go func(result *tierResult, resource schema.GroupResource) {
+			defer wg.Done()
+			defer func() { <-limit }()
 
-			if !namespaceFilter.ShouldInclude(nsName) {
-				ctx.infof("Skipping namespace %s", nsName)
-				continue
-			}
+			result.warnings, result.errs = ctx.restoreResourceItems(resource, namespaceFilter)
+		}(&results[i], resource)
+	}
 
-			// fetch mapped NS name
-			mappedNsName := nsName
-			if target, ok := ctx.restore.Spec.NamespaceMapping[nsName]; ok {
-				mappedNsName = target
-			}
+	wg.Wait()
+
+	warnings, errs := api.RestoreResult{}, api.RestoreResult{}
+	for _, result := range results {
+		merge(&warnings, &result.warnings)
+		merge(&errs, &result.errs)
+	}
+
+	return warnings, errs
+}
+
+// restoreResourceItems restores the items of a resource in the backup.
+func (ctx *context) restoreResourceItems(resource schema.GroupResource, namespaceFilter *collections.IncludesExcludes) (api.RestoreResult, api.RestoreResult) {
+	warnings, errs := api.RestoreResult{}, api.RestoreResult{}
+
+	if items := ctx.index.itemsIn(resource.String(), ""); len(items) > 0 {
+		w, e := ctx.restoreResource(resource.String(), "", items)
+		merge(&warnings, &w)
+		merge(&errs, &e)
+		return warnings, errs
+	}
 
-			// ensure namespace exists
+	for _, nsName := range ctx.index.namespaces(resource.String()) {
+		if !namespaceFilter.ShouldInclude(nsName) {
+			ctx.infof("Skipping namespace %s", nsName)
+			continue
+		}
+
+		// fetch mapped NS name
+		mappedNsName := nsName
+		if target, ok := ctx.restore.Spec.NamespaceMapping[nsName]; ok {
+			mappedNsName = target
+		}
+
+		// ensure namespace exists, unless this is a dry run, which doesn't create anything
+		if ctx.dryRunReport == nil {
 			ns := &v1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: mappedNsName,
@@ -353,16 +598,24 @@ func (ctx *context) restoreFromDir(dir string) (api.RestoreResult, api.RestoreRe
 				addArkError(&errs, err)
 				continue
 			}
-
-			w, e := ctx.restoreResource(resource.String(), mappedNsName, nsPath)
-			merge(&warnings, &w)
-			merge(&errs, &e)
 		}
+
+		w, e := ctx.restoreResource(resource.String(), mappedNsName, ctx.index.itemsIn(resource.String(), nsName))
+		merge(&warnings, &w)
+		merge(&errs, &e)
 	}
 
 	return warnings, errs
 }
 
+// workerCount returns the number of workers to use, which is at least one.
+func workerCount(workers int) int {
+	if workers < 1 {
+		return 1
+	}
+	return workers
+}
+
 // merge combines two RestoreResult objects into one
 // by appending the corresponding lists to one another.
 func merge(a, b *api.RestoreResult) {
@@ -395,9 +648,10 @@ func addToResult(r *api.RestoreResult, ns string, e error) {
 	}
 }
 
-// restoreResource restores the specified cluster or namespace scoped resource. If namespace is
-// empty we are restoring a cluster level resource, otherwise into the specified namespace.
-func (ctx *context) restoreResource(resource, namespace, resourcePath string) (api.RestoreResult, api.RestoreResult) {
+// restoreResource restores the specified items of a cluster or namespace scoped resource. If
+// namespace is empty we are restoring a cluster level resource, otherwise into the specified
+// namespace.
+func (ctx *context) restoreResource(resource, namespace string, entries []*indexEntry) (api.RestoreResult, api.RestoreResult) {
 	warnings, errs := api.RestoreResult{}, api.RestoreResult{}
 
 	if ctx.restore.Spec.IncludeClusterResources != nil && !*ctx.restore.Spec.IncludeClusterResources && namespace == "" {
@@ -406,17 +660,12 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 	}
 
 	if namespace != "" {
-		ctx.infof("Restoring resource '%s' into namespace '%s' from: %s", resource, namespace, resourcePath)
+		ctx.infof("Restoring resource '%s' into namespace '%s'", resource, namespace)
 	} else {
-		ctx.infof("Restoring cluster level resource '%s' from: %s", resource, resourcePath)
+		ctx.infof("Restoring cluster level resource '%s'", resource)
 	}
 
-	files, err := ctx.fileSystem.ReadDir(resourcePath)
-	if err != nil {
-		addToResult(&errs, namespace, fmt.Errorf("error reading %q resource directory: %v", resource, err))
-		return warnings, errs
-	}
-	if len(files) == 0 {
+	if len(entries) == 0 {
 		return warnings, errs
 	}
 
@@ -425,11 +674,12 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 		restorer       restorers.ResourceRestorer
 		waiter         *resourceWaiter
 		groupResource  = schema.ParseGroupResource(resource)
+		items          []*itemToCreate
 	)
 
-	for _, file := range files {
-		fullPath := filepath.Join(resourcePath, file.Name())
-		obj, err := ctx.unmarshal(fullPath)
+	for _, entry := range entries {
+		fullPath := entry.path
+		obj, err := ctx.unmarshal(entry)
 		if err != nil {
 			addToResult(&errs, namespace, fmt.Errorf("error decoding %q: %v", fullPath, err))
 			continue
@@ -464,7 +714,8 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 				ctx.infof("Using custom restorer for %v", &groupResource)
 			}
 
-			if restorer.Wait() {
+			readiness, waitForReadiness := ctx.resourceReadiness[groupResource]
+			if (restorer.Wait() || waitForReadiness) && ctx.dryRunReport == nil {
 				itmWatch, err := resourceClient.Watch(metav1.ListOptions{})
 				if err != nil {
 					addArkError(&errs, fmt.Errorf("error watching for namespace %q, resource %q: %v", namespace, &groupResource, err))
@@ -473,17 +724,37 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 				watchChan := itmWatch.ResultChan()
 				defer itmWatch.Stop()
 
-				waiter = newResourceWaiter(watchChan, restorer.Ready)
+				timeout := readiness.timeout
+				if timeout <= 0 {
+					timeout = objectCreateWaitTimeout
+				}
+
+				waiter = newResourceWaiter(watchChan, readyFunc(restorer, readiness), timeout)
 			}
 		}
 
 		if !restorer.Handles(obj, ctx.restore) {
+			if ctx.dryRunReport != nil {
+				ctx.addDryRunSkip(groupResource, namespace, obj, "not restored by the resource's restorer")
+			}
 			continue
 		}
 
+		restorePodVolumes := groupResource == podsGroupResource && ctx.podVolumeRestorer != nil && len(podvolume.GetSnapshotAnnotations(obj)) > 0
+
 		if hasControllerOwner(obj.GetOwnerReferences()) {
-			ctx.infof("%s/%s has a controller owner - skipping", obj.GetNamespace(), obj.GetName())
-			continue
+			if !restorePodVolumes {
+				ctx.infof("%s/%s has a controller owner - skipping", obj.GetNamespace(), obj.GetName())
+				if ctx.dryRunReport != nil {
+					ctx.addDryRunSkip(groupResource, namespace, obj, "has a controller owner, which would recreate it")
+				}
+				continue
+			}
+
+			// the pod's controller would create new pods without the backed-up volume
+			// data, so the pod is restored without its owner, for its controller to adopt
+			ctx.infof("%s/%s has a controller owner, but has volume backups - restoring without its owner", obj.GetNamespace(), obj.GetName())
+			obj.SetOwnerReferences(nil)
 		}
 
 		preparedObj, warning, err := restorer.Prepare(obj, ctx.restore, ctx.backup)
@@ -504,24 +775,68 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 		// necessary because we may have remapped the namespace
 		unstructuredObj.SetNamespace(namespace)
 
-		// add an ark-restore label to each resource for easy ID
-		addLabel(unstructuredObj, api.RestoreLabelKey, ctx.restore.Name)
+		unstructuredObj, warning, err = ctx.executeActions(groupResource, unstructuredObj)
+		if warning != nil {
+			addToResult(&warnings, namespace, fmt.Errorf("warning executing restore item actions for %s: %v", fullPath, warning))
+		}
+		if err != nil {
+			addToResult(&errs, namespace, fmt.Errorf("error executing restore item actions for %s: %v", fullPath, err))
+			continue
+		}
+
+		var execHooks []pendingExecHook
+		if groupResource == podsGroupResource && len(ctx.restoreHooks) > 0 {
+			unstructuredObj, execHooks, err = ctx.applyInitHooks(unstructuredObj)
+			if err != nil {
+				addToResult(&errs, namespace, fmt.Errorf("error applying restore hooks for %s: %v", fullPath, err))
+				continue
+			}
+		}
+
+		if restorePodVolumes {
+			unstructuredObj, err = ctx.addRestoreHelper(unstructuredObj)
+			if err != nil {
+				addToResult(&errs, namespace, fmt.Errorf("error adding restore helper to %s: %v", fullPath, err))
+				continue
+			}
+		}
 
-		ctx.infof("Restoring %s: %v", obj.GroupVersionKind().Kind, unstructuredObj.GetName())
-		_, err = resourceClient.Create(unstructuredObj)
-		if apierrors.IsAlreadyExists(err) {
-			addToResult(&warnings, namespace, err)
+		if ctx.dryRunReport != nil {
+			if err := ctx.addDryRunItem(resourceClient, groupResource, unstructuredObj); err != nil {
+				addToResult(&errs, namespace, fmt.Errorf("error getting existing item for %s: %v", fullPath, err))
+			}
 			continue
 		}
-		if err != nil {
-			ctx.infof("error restoring %s: %v", unstructuredObj.GetName(), err)
-			addToResult(&errs, namespace, fmt.Errorf("error restoring %s: %v", fullPath, err))
+
+		// add an ark-restore label to each resource for easy ID
+		addLabel(unstructuredObj, api.RestoreLabelKey, ctx.restore.Name)
+
+		items = append(items, &itemToCreate{
+			fullPath:          fullPath,
+			kind:              obj.GroupVersionKind().Kind,
+			obj:               unstructuredObj,
+			execHooks:         execHooks,
+			restorePodVolumes: restorePodVolumes,
+		})
+	}
+
+	ctx.createItems(resourceClient, groupResource, namespace, items)
+
+	for _, item := range items {
+		merge(&warnings, &item.warnings)
+		merge(&errs, &item.errs)
+
+		if !item.created {
 			continue
 		}
 
 		if waiter != nil {
-			waiter.RegisterItem(unstructuredObj.GetName())
+			waiter.RegisterItem(item.obj.GetName())
 		}
+
+		ctx.pendingExecHooksLock.Lock()
+		ctx.pendingExecHooks = append(ctx.pendingExecHooks, item.execHooks...)
+		ctx.pendingExecHooksLock.Unlock()
 	}
 
 	if waiter != nil {
@@ -533,6 +848,187 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 	return warnings, errs
 }
 
+// itemToCreate is an item of a resource that's been prepared for restoring, and the result of
+// creating it.
+type itemToCreate struct {
+	fullPath          string
+	kind              string
+	obj               *unstructured.Unstructured
+	execHooks         []pendingExecHook
+	restorePodVolumes bool
+
+	created  bool
+	warnings api.RestoreResult
+	errs     api.RestoreResult
+}
+
+// createItems creates the prepared items of a resource, up to ctx.workers of them at once, and
+// records the result of creating each of them in the item.
+func (ctx *context) createItems(resourceClient client.Dynamic, groupResource schema.GroupResource, namespace string, items []*itemToCreate) {
+	var (
+		limit = make(chan struct{}, workerCount(ctx.workers))
+		wg    sync.WaitGroup
+	)
+
+	for _, item := range items {
+		limit <- struct{}{}
+		wg.Add(1)
+
+		go func(item *itemToCreate) {
+			defer wg.Done()
+			defer func() { <-limit }()
+
+			ctx.createItem(resourceClient, groupResource, namespace, item)
+		}(item)
+	}
+
+	wg.Wait()
+}
+
+// createItem creates a prepared item, or restores it over an existing item according to the
+// restore's existing resource policy, and starts restoring its pod volumes if it has any.
+func (ctx *context) createItem(resourceClient client.Dynamic, groupResource schema.GroupResource, namespace string, item *itemToCreate) {
+	ctx.infof("Restoring %s: %v", item.kind, item.obj.GetName())
+	createdObj, err := resourceClient.Create(item.obj)
+	if apierrors.IsAlreadyExists(err) {
+		var warning error
+		createdObj, warning, err = ctx.restoreExistingItem(resourceClient, groupResource, item.obj, err)
+		if warning != nil {
+			addToResult(&item.warnings, namespace, warning)
+		}
+		if err != nil {
+			addToResult(&item.errs, namespace, err)
+			return
+		}
+		if createdObj == nil {
+			return
+		}
+	}
+	if err != nil {
+		ctx.infof("error restoring %s: %v", item.obj.GetName(), err)
+		addToResult(&item.errs, namespace, fmt.Errorf("error restoring %s: %v", item.fullPath, err))
+		return
+	}
+
+	item.created = true
+
+	if item.restorePodVolumes {
+		if err := ctx.restorePodVolumes(createdObj); err != nil {
+			addToResult(&item.errs, namespace, fmt.Errorf("error restoring volumes for %s: %v", item.fullPath, err))
+		}
+	}
+}
+
+// addRestoreHelper adds an init container to the pod that waits for its volumes to be restored
+// before any of its other containers start.
+func (ctx *context) addRestoreHelper(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	pod := new(v1.Pod)
+	if err := unstructuredconverter.DefaultConverter.FromUnstructured(obj.UnstructuredContent(), pod); err != nil {
+		return nil, err
+	}
+
+	if !podvolume.AddRestoreHelperInitContainer(pod, string(ctx.restore.UID), ctx.restoreHelperImage) {
+		return obj, nil
+	}
+
+	content, err := unstructuredconverter.DefaultConverter.ToUnstructured(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// restorePodVolumes starts restoring the volumes of the restored pod in the background. Any
+// errors are added to the restore's errors once it's finished.
+func (ctx *context) restorePodVolumes(obj *unstructured.Unstructured) error {
+	pod := new(v1.Pod)
+	if err := unstructuredconverter.DefaultConverter.FromUnstructured(obj.UnstructuredContent(), pod); err != nil {
+		return err
+	}
+
+	ctx.podVolumeWaitGroup.Add(1)
+	go func() {
+		defer ctx.podVolumeWaitGroup.Done()
+
+		log := ctx.logger.WithFields(logrus.Fields{"namespace": pod.Namespace, "name": pod.Name})
+		errs := ctx.podVolumeRestorer.RestorePodVolumes(ctx.restore, ctx.backup, pod, log)
+
+		ctx.podVolumeErrorsLock.Lock()
+		defer ctx.podVolumeErrorsLock.Unlock()
+		for _, err := range errs {
+			addToResult(&ctx.podVolumeErrors, pod.Namespace, fmt.Errorf("error restoring volumes for pod %s/%s: %v", pod.Namespace, pod.Name, err))
+		}
+	}()
+
+	return nil
+}
+
+// executeActions runs each of the restore item actions that apply to obj, in order, passing
+// the output of one action as the input to the next. Warnings from all actions are combined;
+// the first error stops processing of the item.
+func (ctx *context) executeActions(groupResource schema.GroupResource, obj *unstructured.Unstructured) (*unstructured.Unstructured, error, error) {
+	var warnings []string
+
+	for _, action := range ctx.actions {
+		if !action.resourceIncludesExcludes.ShouldInclude(groupResource.String()) {
+			continue
+		}
+
+		if obj.GetNamespace() != "" && !action.namespaceIncludesExcludes.ShouldInclude(obj.GetNamespace()) {
+			continue
+		}
+
+		if !action.selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+
+		ctx.infof("Executing item action for %v", &groupResource)
+
+		res, warning, err := ctx.executeAction(action, obj)
+		if warning != nil {
+			warnings = append(warnings, warning.Error())
+		}
+		if err != nil {
+			return nil, combineWarnings(warnings), err
+		}
+
+		updated, ok := res.(*unstructured.Unstructured)
+		if !ok {
+			return nil, combineWarnings(warnings), fmt.Errorf("unexpected type %T", res)
+		}
+		obj = updated
+	}
+
+	return obj, combineWarnings(warnings), nil
+}
+
+// executeAction executes a restore item action. Actions can be shared by resources that are
+// restored at the same time, so an action that logs is only executed for one item at a time,
+// to keep its log pointing at the restore's.
+func (ctx *context) executeAction(action resolvedAction, obj *unstructured.Unstructured) (runtime.Unstructured, error, error) {
+	setter, ok := action.ItemAction.(logSetter)
+	if !ok {
+		return action.Execute(obj, ctx.restore)
+	}
+
+	ctx.actionLock.Lock()
+	defer ctx.actionLock.Unlock()
+
+	setter.SetLog(ctx.logger)
+	return action.Execute(obj, ctx.restore)
+}
+
+// combineWarnings returns a single error containing all of the provided warnings, or nil
+// if there are none.
+func combineWarnings(warnings []string) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	return errors.New(strings.Join(warnings, "; "))
+}
+
 // addLabel applies the specified key/value to an object as a label.
 func addLabel(obj *unstructured.Unstructured, key string, val string) {
 	labels := obj.GetLabels()
@@ -558,12 +1054,12 @@ func hasControllerOwner(refs []metav1.OwnerReference) bool {
 	return false
 }
 
-// unmarshal reads the specified file, unmarshals the JSON contained within it
-// and returns an Unstructured object.
-func (ctx *context) unmarshal(filePath string) (*unstructured.Unstructured, error) {
+// unmarshal reads the specified entry from the backup index, unmarshals the JSON
+// contained within it and returns an Unstructured object.
+func (ctx *context) unmarshal(entry *indexEntry) (*unstructured.Unstructured, error) {
 	var obj unstructured.Unstructured
 
-	bytes, err := ctx.fileSystem.ReadFile(filePath)
+	bytes, err := ctx.index.read(entry)
 	if err != nil {
 		return nil, err
 	}
@@ -576,26 +1072,67 @@ func (ctx *context) unmarshal(filePath string) (*unstructured.Unstructured, erro
 	return &obj, nil
 }
 
-// unzipAndExtractBackup extracts a reader on a gzipped tarball to a local temp directory
-func (ctx *context) unzipAndExtractBackup(src io.Reader) (string, error) {
-	gzr, err := gzip.NewReader(src)
-	if err != nil {
-		ctx.infof("error creating gzip reader: %v", err)
-		return "", err
+// namespaceIncludesExcludes returns the restore's included and excluded namespaces.
+func (ctx *context) namespaceIncludesExcludes() *collections.IncludesExcludes {
+	return collections.NewIncludesExcludes().Includes(ctx.restore.Spec.IncludedNamespaces...).Excludes(ctx.restore.Spec.ExcludedNamespaces...)
+}
+
+// shouldIndex returns whether the item at path within the backup tarball could be restored.
+// Resources and namespaces that aren't being restored are skipped when reading the backup, so
+// their items don't need to be held.
+func (ctx *context) shouldIndex(path string, namespaceFilter *collections.IncludesExcludes) bool {
+	parts := itemPathParts(path)
+	if len(parts) < 2 || parts[0] != api.ResourcesDir {
+		return true
+	}
+
+	included := false
+	for _, tier := range ctx.resourceTiers {
+		for _, resource := range tier {
+			if resource.String() == parts[1] {
+				included = true
+				break
+			}
+		}
+	}
+
+	// resources that aren't known yet may be defined by CRDs in the backup, so they're kept
+	// if CRDs are being restored, to be restored once discovery knows about them
+	if !included && !ctx.knownResources.Has(parts[1]) && ctx.restoresCRDs() {
+		included = true
 	}
-	defer gzr.Close()
 
-	return ctx.readBackup(tar.NewReader(gzr))
+	if !included {
+		return false
+	}
+
+	if len(parts) >= 4 && parts[2] == api.NamespaceScopedDir {
+		return namespaceFilter.ShouldInclude(parts[3])
+	}
+
+	return true
 }
 
-// readBackup extracts a tar reader to a local directory/file tree within a
-// temp directory.
-func (ctx *context) readBackup(tarRdr *tar.Reader) (string, error) {
-	dir, err := ctx.fileSystem.TempDir("", "")
+// restoresCRDs returns whether CustomResourceDefinitions are being restored.
+func (ctx *context) restoresCRDs() bool {
+	return len(ctx.resourceTiers) > 0 && len(ctx.resourceTiers[0]) > 0 && ctx.resourceTiers[0][0] == crdsGroupResource
+}
+
+// readBackup reads a gzipped backup tarball from src into a backup index, skipping anything
+// that won't be restored.
+func (ctx *context) readBackup(src io.Reader) (*backupIndex, error) {
+	gzr, err := gzip.NewReader(src)
 	if err != nil {
-		ctx.infof("error creating temp dir: %v", err)
-		return "", err
+		ctx.infof("error creating gzip reader: %v", err)
+		return nil, err
 	}
+	defer gzr.Close()
+
+	var (
+		tarRdr          = tar.NewReader(gzr)
+		index           = newBackupIndex(ctx.memoryLimit)
+		namespaceFilter = ctx.namespaceIncludesExcludes()
+	)
 
 	for {
 		header, err := tarRdr.Next()
@@ -605,40 +1142,25 @@ func (ctx *context) readBackup(tarRdr *tar.Reader) (string, error) {
 		}
 		if err != nil {
 			ctx.infof("error reading tar: %v", err)
-			return "", err
+			index.close()
+			return nil, err
 		}
 
-		target := filepath.Join(dir, header.Name)
-
-		switch header.Typeflag {
-		case tar.TypeDir:
-			err := ctx.fileSystem.MkdirAll(target, header.FileInfo().Mode())
-			if err != nil {
-				ctx.infof("mkdirall error: %v", err)
-				return "", err
-			}
-
-		case tar.TypeReg:
-			// make sure we have the directory created
-			err := ctx.fileSystem.MkdirAll(filepath.Dir(target), header.FileInfo().Mode())
-			if err != nil {
-				ctx.infof("mkdirall error: %v", err)
-				return "", err
-			}
+		// the resources directory is noted even if none of its items will be restored
+		if itemPathParts(header.Name)[0] == api.ResourcesDir {
+			index.hasResources = true
+		}
 
-			// create the file
-			file, err := ctx.fileSystem.Create(target)
-			if err != nil {
-				return "", err
-			}
-			defer file.Close()
+		if header.Typeflag != tar.TypeReg || !ctx.shouldIndex(header.Name, namespaceFilter) {
+			continue
+		}
 
-			if _, err := io.Copy(file, tarRdr); err != nil {
-				ctx.infof("error copying: %v", err)
-				return "", err
-			}
+		if err := index.add(header.Name, tarRdr); err != nil {
+			ctx.infof("error indexing %s: %v", header.Name, err)
+			index.close()
+			return nil, err
 		}
 	}
 
-	return dir, nil
+	return index, nil
 }
@@ -17,15 +17,22 @@ limitations under the License.
 package restore
 
 import (
+	"bytes"
 	"encoding/json"
-	"io"
-	"os"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	testlogger "github.com/sirupsen/logrus/hooks/test"
-	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"k8s.io/api/core/v1"
@@ -34,9 +41,11 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/podvolume"
 	"github.com/heptio/ark/pkg/restore/restorers"
 	"github.com/heptio/ark/pkg/util/collections"
 	. "github.com/heptio/ark/pkg/util/test"
@@ -49,7 +58,7 @@ func TestPrioritizeResources(t *testing.T) {
 		priorities   []string
 		includes     []string
 		excludes     []string
-		expected     []string
+		expected     [][]string
 	}{
 		{
 			name: "priorities & ordering are correctly applied",
@@ -58,7 +67,7 @@ func TestPrioritizeResources(t *testing.T) {
 			},
 			priorities: []string{"namespaces", "configmaps", "pods"},
 			includes:   []string{"*"},
-			expected:   []string{"namespaces", "configmaps", "pods", "aaa", "bbb", "ddd", "ooo", "sss"},
+			expected:   [][]string{{"namespaces"}, {"configmaps"}, {"pods"}, {"aaa", "bbb", "ddd", "ooo", "sss"}},
 		},
 		{
 			name: "includes are correctly applied",
@@ -67,7 +76,7 @@ func TestPrioritizeResources(t *testing.T) {
 			},
 			priorities: []string{"namespaces", "configmaps", "pods"},
 			includes:   []string{"namespaces", "aaa", "sss"},
-			expected:   []string{"namespaces", "aaa", "sss"},
+			expected:   [][]string{{"namespaces"}, {"aaa", "sss"}},
 		},
 		{
 			name: "excludes are correctly applied",
@@ -77,7 +86,28 @@ func TestPrioritizeResources(t *testing.T) {
 			priorities: []string{"namespaces", "configmaps", "pods"},
 			includes:   []string{"*"},
 			excludes:   []string{"ooo", "pods"},
-			expected:   []string{"namespaces", "configmaps", "aaa", "bbb", "ddd", "sss"},
+			expected:   [][]string{{"namespaces"}, {"configmaps"}, {"aaa", "bbb", "ddd", "sss"}},
+		},
+		{
+			name: "CRDs are restored first",
+			apiResources: map[string][]string{
+				"v1":                           {"aaa", "configmaps", "namespaces"},
+				"apiextensions.k8s.io/v1beta1": {"customresourcedefinitions"},
+			},
+			priorities: []string{"namespaces", "customresourcedefinitions.apiextensions.k8s.io", "configmaps"},
+			includes:   []string{"*"},
+			expected:   [][]string{{"customresourcedefinitions"}, {"namespaces"}, {"configmaps"}, {"aaa"}},
+		},
+		{
+			name: "excluded CRDs aren't restored",
+			apiResources: map[string][]string{
+				"v1":                           {"aaa", "configmaps", "namespaces"},
+				"apiextensions.k8s.io/v1beta1": {"customresourcedefinitions"},
+			},
+			priorities: []string{"namespaces", "configmaps"},
+			includes:   []string{"*"},
+			excludes:   []string{"customresourcedefinitions.apiextensions.k8s.io"},
+			expected:   [][]string{{"namespaces"}, {"configmaps"}, {"aaa"}},
 		},
 	}
 
@@ -108,8 +138,12 @@ func TestPrioritizeResources(t *testing.T) {
 			require.Equal(t, len(test.expected), len(result))
 
 			for i := range result {
-				if e, a := test.expected[i], result[i].Resource; e != a {
-					t.Errorf("index %d, expected %s, got %s", i, e, a)
+				require.Equal(t, len(test.expected[i]), len(result[i]), "tier %d", i)
+
+				for j := range result[i] {
+					if e, a := test.expected[i][j], result[i][j].Resource; e != a {
+						t.Errorf("tier %d, index %d, expected %s, got %s", i, j, e, a)
+					}
 				}
 			}
 		})
@@ -117,58 +151,72 @@ func TestPrioritizeResources(t *testing.T) {
 }
 
 func TestRestoreNamespaceFiltering(t *testing.T) {
+	index := newTestBackupIndex().WithItems(
+		"resources/nodes/cluster/node-1.json",
+		"resources/secrets/namespaces/a/secret-1.json",
+		"resources/secrets/namespaces/b/secret-1.json",
+		"resources/secrets/namespaces/c/secret-1.json",
+	)
+
 	tests := []struct {
 		name                 string
-		fileSystem           *fakeFileSystem
-		baseDir              string
 		restore              *api.Restore
-		expectedReadDirs     []string
+		expectedRestores     []string
 		prioritizedResources []schema.GroupResource
 	}{
 		{
-			name:             "namespacesToRestore having * restores all namespaces",
-			fileSystem:       newFakeFileSystem().WithDirectories("bak/resources/nodes/cluster", "bak/resources/secrets/namespaces/a", "bak/resources/secrets/namespaces/b", "bak/resources/secrets/namespaces/c"),
-			baseDir:          "bak",
-			restore:          &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
-			expectedReadDirs: []string{"bak/resources", "bak/resources/nodes/cluster", "bak/resources/secrets/namespaces", "bak/resources/secrets/namespaces/a", "bak/resources/secrets/namespaces/b", "bak/resources/secrets/namespaces/c"},
+			name:    "namespacesToRestore having * restores all namespaces",
+			restore: &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
+			expectedRestores: []string{
+				"Restoring cluster level resource 'nodes'",
+				"Restoring resource 'secrets' into namespace 'a'",
+				"Restoring resource 'secrets' into namespace 'b'",
+				"Restoring resource 'secrets' into namespace 'c'",
+			},
 			prioritizedResources: []schema.GroupResource{
 				{Resource: "nodes"},
 				{Resource: "secrets"},
 			},
 		},
 		{
-			name:             "namespacesToRestore properly filters",
-			fileSystem:       newFakeFileSystem().WithDirectories("bak/resources/nodes/cluster", "bak/resources/secrets/namespaces/a", "bak/resources/secrets/namespaces/b", "bak/resources/secrets/namespaces/c"),
-			baseDir:          "bak",
-			restore:          &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"b", "c"}}},
-			expectedReadDirs: []string{"bak/resources", "bak/resources/nodes/cluster", "bak/resources/secrets/namespaces", "bak/resources/secrets/namespaces/b", "bak/resources/secrets/namespaces/c"},
+			name:    "namespacesToRestore properly filters",
+			restore: &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"b", "c"}}},
+			expectedRestores: []string{
+				"Restoring cluster level resource 'nodes'",
+				"Restoring resource 'secrets' into namespace 'b'",
+				"Restoring resource 'secrets' into namespace 'c'",
+			},
 			prioritizedResources: []schema.GroupResource{
 				{Resource: "nodes"},
 				{Resource: "secrets"},
 			},
 		},
 		{
-			name:             "namespacesToRestore properly filters with exclusion filter",
-			fileSystem:       newFakeFileSystem().WithDirectories("bak/resources/nodes/cluster", "bak/resources/secrets/namespaces/a", "bak/resources/secrets/namespaces/b", "bak/resources/secrets/namespaces/c"),
-			baseDir:          "bak",
-			restore:          &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}, ExcludedNamespaces: []string{"a"}}},
-			expectedReadDirs: []string{"bak/resources", "bak/resources/nodes/cluster", "bak/resources/secrets/namespaces", "bak/resources/secrets/namespaces/b", "bak/resources/secrets/namespaces/c"},
+			name:    "namespacesToRestore properly filters with exclusion filter",
+			restore: &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}, ExcludedNamespaces: []string{"a"}}},
+			expectedRestores: []string{
+				"Restoring cluster level resource 'nodes'",
+				"Restoring resource 'secrets' into namespace 'b'",
+				"Restoring resource 'secrets' into namespace 'c'",
+			},
 			prioritizedResources: []schema.GroupResource{
 				{Resource: "nodes"},
 				{Resource: "secrets"},
 			},
 		},
 		{
-			name:       "namespacesToRestore properly filters with inclusion & exclusion filters",
-			fileSystem: newFakeFileSystem().WithDirectories("bak/resources/nodes/cluster", "bak/resources/secrets/namespaces/a", "bak/resources/secrets/namespaces/b", "bak/resources/secrets/namespaces/c"),
-			baseDir:    "bak",
+			name: "namespacesToRestore properly filters with inclusion & exclusion filters",
 			restore: &api.Restore{
 				Spec: api.RestoreSpec{
 					IncludedNamespaces: []string{"a", "b", "c"},
 					ExcludedNamespaces: []string{"b"},
 				},
 			},
-			expectedReadDirs: []string{"bak/resources", "bak/resources/nodes/cluster", "bak/resources/secrets/namespaces", "bak/resources/secrets/namespaces/a", "bak/resources/secrets/namespaces/c"},
+			expectedRestores: []string{
+				"Restoring cluster level resource 'nodes'",
+				"Restoring resource 'secrets' into namespace 'a'",
+				"Restoring resource 'secrets' into namespace 'c'",
+			},
 			prioritizedResources: []schema.GroupResource{
 				{Resource: "nodes"},
 				{Resource: "secrets"},
@@ -178,17 +226,18 @@ func TestRestoreNamespaceFiltering(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			log, _ := testlogger.NewNullLogger()
+			log, hook := testlogger.NewNullLogger()
 
 			ctx := &context{
-				restore:              test.restore,
-				namespaceClient:      &fakeNamespaceClient{},
-				fileSystem:           test.fileSystem,
-				logger:               log,
-				prioritizedResources: test.prioritizedResources,
+				restore:         test.restore,
+				namespaceClient: &fakeNamespaceClient{},
+				index:           index.backupIndex,
+				selector:        labels.Nothing(),
+				logger:          log,
+				resourceTiers:   [][]schema.GroupResource{test.prioritizedResources},
 			}
 
-			warnings, errors := ctx.restoreFromDir(test.baseDir)
+			warnings, errors := ctx.restoreFromIndex()
 
 			assert.Empty(t, warnings.Ark)
 			assert.Empty(t, warnings.Cluster)
@@ -196,7 +245,7 @@ func TestRestoreNamespaceFiltering(t *testing.T) {
 			assert.Empty(t, errors.Ark)
 			assert.Empty(t, errors.Cluster)
 			assert.Empty(t, errors.Namespaces)
-			assert.Equal(t, test.expectedReadDirs, test.fileSystem.readDirCalls)
+			assert.Equal(t, test.expectedRestores, restoreMessages(hook))
 		})
 	}
 }
@@ -204,56 +253,51 @@ func TestRestoreNamespaceFiltering(t *testing.T) {
 func TestRestorePriority(t *testing.T) {
 	tests := []struct {
 		name                 string
-		fileSystem           *fakeFileSystem
+		index                *testBackupIndex
 		restore              *api.Restore
-		baseDir              string
 		prioritizedResources []schema.GroupResource
 		expectedErrors       api.RestoreResult
-		expectedReadDirs     []string
+		expectedRestores     []string
 	}{
 		{
-			name:       "cluster test",
-			fileSystem: newFakeFileSystem().WithDirectory("bak/resources/a/cluster").WithDirectory("bak/resources/c/cluster"),
-			baseDir:    "bak",
-			restore:    &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
+			name:    "cluster test",
+			index:   newTestBackupIndex().WithItems("resources/a/cluster/a-1.json", "resources/c/cluster/c-1.json"),
+			restore: &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
 			prioritizedResources: []schema.GroupResource{
 				{Resource: "a"},
 				{Resource: "b"},
 				{Resource: "c"},
 			},
-			expectedReadDirs: []string{"bak/resources", "bak/resources/a/cluster", "bak/resources/c/cluster"},
+			expectedRestores: []string{"Restoring cluster level resource 'a'", "Restoring cluster level resource 'c'"},
 		},
 		{
-			name:       "resource priorities are applied",
-			fileSystem: newFakeFileSystem().WithDirectory("bak/resources/a/cluster").WithDirectory("bak/resources/c/cluster"),
-			restore:    &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
-			baseDir:    "bak",
+			name:    "resource priorities are applied",
+			index:   newTestBackupIndex().WithItems("resources/a/cluster/a-1.json", "resources/c/cluster/c-1.json"),
+			restore: &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
 			prioritizedResources: []schema.GroupResource{
 				{Resource: "c"},
 				{Resource: "b"},
 				{Resource: "a"},
 			},
-			expectedReadDirs: []string{"bak/resources", "bak/resources/c/cluster", "bak/resources/a/cluster"},
+			expectedRestores: []string{"Restoring cluster level resource 'c'", "Restoring cluster level resource 'a'"},
 		},
 		{
-			name:       "basic namespace",
-			fileSystem: newFakeFileSystem().WithDirectory("bak/resources/a/namespaces/ns-1").WithDirectory("bak/resources/c/namespaces/ns-1"),
-			restore:    &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
-			baseDir:    "bak",
+			name:    "basic namespace",
+			index:   newTestBackupIndex().WithItems("resources/a/namespaces/ns-1/a-1.json", "resources/c/namespaces/ns-1/c-1.json"),
+			restore: &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
 			prioritizedResources: []schema.GroupResource{
 				{Resource: "a"},
 				{Resource: "b"},
 				{Resource: "c"},
 			},
-			expectedReadDirs: []string{"bak/resources", "bak/resources/a/namespaces", "bak/resources/a/namespaces/ns-1", "bak/resources/c/namespaces", "bak/resources/c/namespaces/ns-1"},
+			expectedRestores: []string{"Restoring resource 'a' into namespace 'ns-1'", "Restoring resource 'c' into namespace 'ns-1'"},
 		},
 		{
 			name: "error in a single resource doesn't terminate restore immediately, but is returned",
-			fileSystem: newFakeFileSystem().
-				WithFile("bak/resources/a/namespaces/ns-1/invalid-json.json", []byte("invalid json")).
-				WithDirectory("bak/resources/c/namespaces/ns-1"),
+			index: newTestBackupIndex().
+				WithItem("resources/a/namespaces/ns-1/invalid-json.json", []byte("invalid json")).
+				WithItems("resources/c/namespaces/ns-1/c-1.json"),
 			restore: &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
-			baseDir: "bak",
 			prioritizedResources: []schema.GroupResource{
 				{Resource: "a"},
 				{Resource: "b"},
@@ -261,44 +305,144 @@ func TestRestorePriority(t *testing.T) {
 			},
 			expectedErrors: api.RestoreResult{
 				Namespaces: map[string][]string{
-					"ns-1": {"error decoding \"bak/resources/a/namespaces/ns-1/invalid-json.json\": invalid character 'i' looking for beginning of value"},
+					"ns-1": {"error decoding \"resources/a/namespaces/ns-1/invalid-json.json\": invalid character 'i' looking for beginning of value"},
 				},
 			},
-			expectedReadDirs: []string{"bak/resources", "bak/resources/a/namespaces", "bak/resources/a/namespaces/ns-1", "bak/resources/c/namespaces", "bak/resources/c/namespaces/ns-1"},
+			expectedRestores: []string{"Restoring resource 'a' into namespace 'ns-1'", "Restoring resource 'c' into namespace 'ns-1'"},
+		},
+		{
+			name:    "backup without a resources directory is an error",
+			index:   newTestBackupIndex(),
+			restore: &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
+			prioritizedResources: []schema.GroupResource{
+				{Resource: "a"},
+			},
+			expectedErrors: api.RestoreResult{
+				Ark: []string{"backup does not contain top level resources directory"},
+			},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			log, _ := testlogger.NewNullLogger()
+			log, hook := testlogger.NewNullLogger()
 
 			ctx := &context{
-				restore:              test.restore,
-				namespaceClient:      &fakeNamespaceClient{},
-				fileSystem:           test.fileSystem,
-				prioritizedResources: test.prioritizedResources,
-				logger:               log,
+				restore:         test.restore,
+				namespaceClient: &fakeNamespaceClient{},
+				index:           test.index.backupIndex,
+				selector:        labels.Nothing(),
+				resourceTiers:   [][]schema.GroupResource{test.prioritizedResources},
+				logger:          log,
 			}
 
-			warnings, errors := ctx.restoreFromDir(test.baseDir)
+			warnings, errors := ctx.restoreFromIndex()
 
 			assert.Empty(t, warnings.Ark)
 			assert.Empty(t, warnings.Cluster)
 			assert.Empty(t, warnings.Namespaces)
 			assert.Equal(t, test.expectedErrors, errors)
 
-			assert.Equal(t, test.expectedReadDirs, test.fileSystem.readDirCalls)
+			assert.Equal(t, test.expectedRestores, restoreMessages(hook))
 		})
 	}
 }
 
+// restoreMessages returns the messages logged when starting to restore each resource's items
+// in a namespace or the cluster.
+func restoreMessages(hook *testlogger.Hook) []string {
+	var messages []string
+	for _, entry := range hook.Entries {
+		if strings.HasPrefix(entry.Message, "Restoring ") {
+			messages = append(messages, entry.Message)
+		}
+	}
+	return messages
+}
+
+type fakePodVolumeRestorer struct {
+	restored []string
+	errs     []error
+}
+
+func (r *fakePodVolumeRestorer) RestorePodVolumes(restore *api.Restore, backup *api.Backup, pod *v1.Pod, log logrus.FieldLogger) []error {
+	r.restored = append(r.restored, pod.Namespace+"/"+pod.Name)
+	return r.errs
+}
+
+func TestRestorePodWithVolumeBackups(t *testing.T) {
+	isController := true
+	pod := &v1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-1",
+			Name:      "pod-1",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1beta1", Kind: "ReplicaSet", Name: "rs-1", Controller: &isController},
+			},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "app", Image: "app"}},
+			Volumes:    []v1.Volume{{Name: "data"}},
+		},
+	}
+	podvolume.SetSnapshotAnnotation(pod, "data", "snapshot-1")
+
+	podJSON, err := json.Marshal(pod)
+	require.NoError(t, err)
+
+	var created *unstructured.Unstructured
+	resourceClient := &FakeDynamicClient{}
+	resourceClient.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(0).(*unstructured.Unstructured)
+	}).Return(&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "Pod", "metadata": map[string]interface{}{"namespace": "ns-1", "name": "pod-1"}}}, nil)
+
+	dynamicFactory := &FakeDynamicFactory{}
+	resource := metav1.APIResource{Name: "pods", Namespaced: true}
+	gv := schema.GroupVersion{Group: "", Version: "v1"}
+	dynamicFactory.On("ClientForGroupVersionResource", gv, resource, "ns-1").Return(resourceClient, nil)
+
+	podVolumeRestorer := &fakePodVolumeRestorer{errs: []error{errors.New("node agent failed")}}
+	log, _ := testlogger.NewNullLogger()
+
+	ctx := &context{
+		dynamicFactory:     dynamicFactory,
+		index:              newTestBackupIndex().WithItem("resources/pods/namespaces/ns-1/pod-1.json", podJSON).backupIndex,
+		selector:           labels.NewSelector(),
+		namespaceClient:    &fakeNamespaceClient{},
+		resourceTiers:      [][]schema.GroupResource{{{Resource: "pods"}}},
+		restore:            &api.Restore{ObjectMeta: metav1.ObjectMeta{Name: "restore-1", UID: "uid-1"}, Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
+		backup:             &api.Backup{},
+		logger:             log,
+		podVolumeRestorer:  podVolumeRestorer,
+		restoreHelperImage: "helper-image",
+	}
+
+	warnings, errs := ctx.restoreFromIndex()
+	ctx.podVolumeWaitGroup.Wait()
+
+	assert.Empty(t, warnings.Namespaces)
+	assert.Empty(t, errs.Namespaces)
+	require.NotNil(t, created)
+
+	// the pod is restored without its controller, and with the helper waiting for its volumes
+	assert.Empty(t, created.GetOwnerReferences())
+	initContainers := created.Object["spec"].(map[string]interface{})["initContainers"].([]interface{})
+	require.Len(t, initContainers, 1)
+	assert.Equal(t, "ark-restore-helper", initContainers[0].(map[string]interface{})["name"])
+	assert.Equal(t, "helper-image", initContainers[0].(map[string]interface{})["image"])
+
+	assert.Equal(t, []string{"ns-1/pod-1"}, podVolumeRestorer.restored)
+	require.Len(t, ctx.podVolumeErrors.Namespaces["ns-1"], 1)
+	assert.Contains(t, ctx.podVolumeErrors.Namespaces["ns-1"][0], "node agent failed")
+}
+
 func TestNamespaceRemapping(t *testing.T) {
 	var (
-		baseDir              = "bak"
 		restore              = &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}, NamespaceMapping: map[string]string{"ns-1": "ns-2"}}}
 		prioritizedResources = []schema.GroupResource{{Resource: "configmaps"}}
 		labelSelector        = labels.NewSelector()
-		fileSystem           = newFakeFileSystem().WithFile("bak/resources/configmaps/namespaces/ns-1/cm-1.json", newTestConfigMap().WithNamespace("ns-1").ToJSON())
+		index                = newTestBackupIndex().WithItem("resources/configmaps/namespaces/ns-1/cm-1.json", newTestConfigMap().WithNamespace("ns-1").ToJSON())
 		expectedNS           = "ns-2"
 		expectedObjs         = toUnstructured(newTestConfigMap().WithNamespace("ns-2").WithArkLabel("").ConfigMap)
 	)
@@ -316,17 +460,17 @@ func TestNamespaceRemapping(t *testing.T) {
 	log, _ := testlogger.NewNullLogger()
 
 	ctx := &context{
-		dynamicFactory:       dynamicFactory,
-		fileSystem:           fileSystem,
-		selector:             labelSelector,
-		namespaceClient:      &fakeNamespaceClient{},
-		prioritizedResources: prioritizedResources,
-		restore:              restore,
-		backup:               &api.Backup{},
-		logger:               log,
+		dynamicFactory:  dynamicFactory,
+		index:           index.backupIndex,
+		selector:        labelSelector,
+		namespaceClient: &fakeNamespaceClient{},
+		resourceTiers:   [][]schema.GroupResource{prioritizedResources},
+		restore:         restore,
+		backup:          &api.Backup{},
+		logger:          log,
 	}
 
-	warnings, errors := ctx.restoreFromDir(baseDir)
+	warnings, errors := ctx.restoreFromIndex()
 
 	assert.Empty(t, warnings.Ark)
 	assert.Empty(t, warnings.Cluster)
@@ -339,6 +483,223 @@ func TestNamespaceRemapping(t *testing.T) {
 	resourceClient.AssertExpectations(t)
 }
 
+func TestRestoreFromIndexRestoresConcurrentlyWithinTiers(t *testing.T) {
+	index := newTestBackupIndex().
+		WithItem("resources/configmaps/namespaces/ns-1/cm-1.json", newNamedTestConfigMap("cm-1").ToJSON()).
+		WithItem("resources/configmaps/namespaces/ns-1/cm-2.json", newNamedTestConfigMap("cm-2").ToJSON()).
+		WithItem("resources/configmaps/namespaces/ns-1/cm-3.json", newNamedTestConfigMap("cm-3").ToJSON()).
+		WithItem("resources/secrets/namespaces/ns-1/secret-1.json", []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"namespace":"ns-1","name":"secret-1"}}`)).
+		WithItem("resources/serviceaccounts/namespaces/ns-1/sa-1.json", []byte(`{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"namespace":"ns-1","name":"sa-1"}}`))
+
+	var (
+		createdLock sync.Mutex
+		created     []string
+	)
+
+	// the config maps are only created once all three are being created at once, and the
+	// secret and service account once both of them are
+	waits := map[string]func(){
+		"configmaps":      waitForCalls(t, 3),
+		"secrets":         waitForCalls(t, 2),
+		"serviceaccounts": nil,
+	}
+	waits["serviceaccounts"] = waits["secrets"]
+
+	dynamicFactory := &FakeDynamicFactory{}
+	defer dynamicFactory.AssertExpectations(t)
+
+	for resource, wait := range waits {
+		wait := wait
+
+		resourceClient := &FakeDynamicClient{}
+		defer resourceClient.AssertExpectations(t)
+
+		resourceClient.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+			wait()
+
+			createdLock.Lock()
+			defer createdLock.Unlock()
+			created = append(created, args.Get(0).(*unstructured.Unstructured).GetName())
+		}).Return(&unstructured.Unstructured{}, nil)
+
+		apiResource := metav1.APIResource{Name: resource, Namespaced: true}
+		dynamicFactory.On("ClientForGroupVersionResource", schema.GroupVersion{Version: "v1"}, apiResource, "ns-1").Return(resourceClient, nil)
+	}
+
+	log, _ := testlogger.NewNullLogger()
+
+	ctx := &context{
+		dynamicFactory:  dynamicFactory,
+		index:           index.backupIndex,
+		selector:        labels.NewSelector(),
+		namespaceClient: &fakeNamespaceClient{},
+		resourceTiers: [][]schema.GroupResource{
+			{{Resource: "configmaps"}},
+			{{Resource: "secrets"}, {Resource: "serviceaccounts"}},
+		},
+		workers: 3,
+		restore: &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
+		backup:  &api.Backup{},
+		logger:  log,
+	}
+
+	warnings, errs := ctx.restoreFromIndex()
+
+	assert.Empty(t, warnings.Namespaces)
+	assert.Empty(t, errs.Namespaces)
+	assert.Empty(t, errs.Ark)
+
+	// the second tier isn't started until the first is done
+	require.Len(t, created, 5)
+	sort.Strings(created[:3])
+	sort.Strings(created[3:])
+	assert.Equal(t, []string{"cm-1", "cm-2", "cm-3", "sa-1", "secret-1"}, created)
+}
+
+// waitForCalls returns a function that blocks until it's been called n times, failing the test
+// if that doesn't happen soon.
+func waitForCalls(t *testing.T, n int) func() {
+	var calls sync.WaitGroup
+	calls.Add(n)
+
+	return func() {
+		calls.Done()
+
+		done := make(chan struct{})
+		go func() {
+			calls.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Error("timed out waiting for concurrent calls")
+		}
+	}
+}
+
+func TestShouldIndex(t *testing.T) {
+	ctx := &context{
+		resourceTiers: [][]schema.GroupResource{{{Resource: "configmaps"}, {Resource: "persistentvolumes"}}},
+		restore:       &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"ns-1"}}},
+	}
+	namespaceFilter := ctx.namespaceIncludesExcludes()
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "metadata/ark-backup.json", expected: true},
+		{path: "resources", expected: true},
+		{path: "resources/configmaps", expected: true},
+		{path: "resources/configmaps/namespaces/ns-1/cm-1.json", expected: true},
+		{path: "resources/configmaps/namespaces/ns-2/cm-1.json", expected: false},
+		{path: "resources/persistentvolumes/cluster/pv-1.json", expected: true},
+		{path: "resources/secrets/namespaces/ns-1/secret-1.json", expected: false},
+		{path: "resources/secrets", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			assert.Equal(t, test.expected, ctx.shouldIndex(test.path, namespaceFilter))
+		})
+	}
+}
+
+func TestShouldIndexUnknownResourcesWhenRestoringCRDs(t *testing.T) {
+	ctx := &context{
+		resourceTiers:  [][]schema.GroupResource{{crdsGroupResource}, {{Resource: "configmaps"}}},
+		knownResources: sets.NewString("customresourcedefinitions.apiextensions.k8s.io", "configmaps", "secrets"),
+		restore:        &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
+	}
+	namespaceFilter := ctx.namespaceIncludesExcludes()
+
+	// known resources that aren't being restored are still skipped
+	assert.False(t, ctx.shouldIndex("resources/secrets/namespaces/ns-1/secret-1.json", namespaceFilter))
+	assert.True(t, ctx.shouldIndex("resources/foos.example.com/namespaces/ns-1/foo-1.json", namespaceFilter))
+
+	// without CRDs, unknown resources can't become known during the restore
+	ctx.resourceTiers = ctx.resourceTiers[1:]
+	assert.False(t, ctx.shouldIndex("resources/foos.example.com/namespaces/ns-1/foo-1.json", namespaceFilter))
+}
+
+func TestRestoreFromIndexRefreshesResourcesAfterRestoringCRDs(t *testing.T) {
+	var (
+		fooResource = schema.GroupResource{Group: "example.com", Resource: "foos"}
+		index       = newTestBackupIndex().
+				WithItem("resources/customresourcedefinitions.apiextensions.k8s.io/cluster/foos.example.com.json", []byte(`{"apiVersion":"apiextensions.k8s.io/v1beta1","kind":"CustomResourceDefinition","metadata":{"name":"foos.example.com"}}`)).
+				WithItem("resources/configmaps/namespaces/ns-1/cm-1.json", newTestConfigMap().ToJSON()).
+				WithItem("resources/foos.example.com/namespaces/ns-1/foo-1.json", []byte(`{"apiVersion":"example.com/v1","kind":"Foo","metadata":{"namespace":"ns-1","name":"foo-1"}}`))
+		created []string
+	)
+
+	dynamicFactory := &FakeDynamicFactory{}
+	defer dynamicFactory.AssertExpectations(t)
+
+	for _, test := range []struct {
+		gv        schema.GroupVersion
+		resource  metav1.APIResource
+		namespace string
+	}{
+		{schema.GroupVersion{Group: "apiextensions.k8s.io", Version: "v1beta1"}, metav1.APIResource{Name: "customresourcedefinitions"}, ""},
+		{schema.GroupVersion{Version: "v1"}, metav1.APIResource{Name: "configmaps", Namespaced: true}, "ns-1"},
+		{schema.GroupVersion{Group: "example.com", Version: "v1"}, metav1.APIResource{Name: "foos", Namespaced: true}, "ns-1"},
+	} {
+		resourceClient := &FakeDynamicClient{}
+		defer resourceClient.AssertExpectations(t)
+
+		resourceClient.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+			created = append(created, args.Get(0).(*unstructured.Unstructured).GetName())
+		}).Return(&unstructured.Unstructured{}, nil)
+
+		dynamicFactory.On("ClientForGroupVersionResource", test.gv, test.resource, test.namespace).Return(resourceClient, nil)
+	}
+
+	log, _ := testlogger.NewNullLogger()
+
+	ctx := &context{
+		dynamicFactory:  dynamicFactory,
+		index:           index.backupIndex,
+		selector:        labels.NewSelector(),
+		namespaceClient: &fakeNamespaceClient{},
+		resourceTiers:   [][]schema.GroupResource{{crdsGroupResource}, {{Resource: "configmaps"}}},
+		restore:         &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
+		backup:          &api.Backup{},
+		logger:          log,
+	}
+
+	// once the CRD's been restored, discovery knows about the resource it defines
+	refreshes := 0
+	ctx.refreshResourceTiers = func() ([][]schema.GroupResource, error) {
+		refreshes++
+		return [][]schema.GroupResource{{crdsGroupResource}, {{Resource: "configmaps"}}, {fooResource}}, nil
+	}
+
+	warnings, errs := ctx.restoreFromIndex()
+
+	assert.Empty(t, warnings.Namespaces)
+	assert.Empty(t, errs.Ark)
+	assert.Empty(t, errs.Cluster)
+	assert.Empty(t, errs.Namespaces)
+
+	assert.Equal(t, 1, refreshes)
+	assert.Equal(t, []string{"foos.example.com", "cm-1", "foo-1"}, created)
+}
+
+func TestUnrestoredTiers(t *testing.T) {
+	tiers := [][]schema.GroupResource{
+		{crdsGroupResource},
+		{{Resource: "namespaces"}},
+		{{Resource: "configmaps"}, {Group: "example.com", Resource: "foos"}},
+	}
+
+	assert.Equal(t,
+		[][]schema.GroupResource{{{Group: "example.com", Resource: "foos"}}},
+		unrestoredTiers(tiers, sets.NewString("customresourcedefinitions.apiextensions.k8s.io", "namespaces", "configmaps")),
+	)
+}
+
 func TestRestoreResourceForNamespace(t *testing.T) {
 	var (
 		trueVal  = true
@@ -353,8 +714,9 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 		resourcePath            string
 		labelSelector           labels.Selector
 		includeClusterResources *bool
-		fileSystem              *fakeFileSystem
+		items                   []*indexEntry
 		restorers               map[schema.GroupResource]restorers.ResourceRestorer
+		actions                 []resolvedAction
 		expectedErrors          api.RestoreResult
 		expectedObjs            []unstructured.Unstructured
 	}{
@@ -363,39 +725,29 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			namespace:     "ns-1",
 			resourcePath:  "configmaps",
 			labelSelector: labels.NewSelector(),
-			fileSystem: newFakeFileSystem().
-				WithFile("configmaps/cm-1.json", newNamedTestConfigMap("cm-1").ToJSON()).
-				WithFile("configmaps/cm-2.json", newNamedTestConfigMap("cm-2").ToJSON()),
+			items: []*indexEntry{
+				newTestIndexEntry("configmaps/cm-1.json", newNamedTestConfigMap("cm-1").ToJSON()),
+				newTestIndexEntry("configmaps/cm-2.json", newNamedTestConfigMap("cm-2").ToJSON()),
+			},
 			expectedObjs: toUnstructured(
 				newNamedTestConfigMap("cm-1").WithArkLabel("my-restore").ConfigMap,
 				newNamedTestConfigMap("cm-2").WithArkLabel("my-restore").ConfigMap,
 			),
 		},
 		{
-			name:         "no such directory causes error",
+			name:         "no items is no-op",
 			namespace:    "ns-1",
 			resourcePath: "configmaps",
-			fileSystem:   newFakeFileSystem(),
-			expectedErrors: api.RestoreResult{
-				Namespaces: map[string][]string{
-					"ns-1": {"error reading \"configmaps\" resource directory: open configmaps: file does not exist"},
-				},
-			},
-		},
-		{
-			name:         "empty directory is no-op",
-			namespace:    "ns-1",
-			resourcePath: "configmaps",
-			fileSystem:   newFakeFileSystem().WithDirectory("configmaps"),
 		},
 		{
 			name:          "unmarshall failure does not cause immediate return",
 			namespace:     "ns-1",
 			resourcePath:  "configmaps",
 			labelSelector: labels.NewSelector(),
-			fileSystem: newFakeFileSystem().
-				WithFile("configmaps/cm-1-invalid.json", []byte("this is not valid json")).
-				WithFile("configmaps/cm-2.json", newNamedTestConfigMap("cm-2").ToJSON()),
+			items: []*indexEntry{
+				newTestIndexEntry("configmaps/cm-1-invalid.json", []byte("this is not valid json")),
+				newTestIndexEntry("configmaps/cm-2.json", newNamedTestConfigMap("cm-2").ToJSON()),
+			},
 			expectedErrors: api.RestoreResult{
 				Namespaces: map[string][]string{
 					"ns-1": {"error decoding \"configmaps/cm-1-invalid.json\": invalid character 'h' in literal true (expecting 'r')"},
@@ -408,7 +760,7 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			namespace:     "ns-1",
 			resourcePath:  "configmaps",
 			labelSelector: labels.SelectorFromSet(labels.Set(map[string]string{"foo": "bar"})),
-			fileSystem:    newFakeFileSystem().WithFile("configmaps/cm-1.json", newTestConfigMap().WithLabels(map[string]string{"foo": "bar"}).ToJSON()),
+			items:         []*indexEntry{newTestIndexEntry("configmaps/cm-1.json", newTestConfigMap().WithLabels(map[string]string{"foo": "bar"}).ToJSON())},
 			expectedObjs:  toUnstructured(newTestConfigMap().WithLabels(map[string]string{"foo": "bar"}).WithArkLabel("my-restore").ConfigMap),
 		},
 		{
@@ -416,16 +768,17 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			namespace:     "ns-1",
 			resourcePath:  "configmaps",
 			labelSelector: labels.SelectorFromSet(labels.Set(map[string]string{"foo": "not-bar"})),
-			fileSystem:    newFakeFileSystem().WithFile("configmaps/cm-1.json", newTestConfigMap().WithLabels(map[string]string{"foo": "bar"}).ToJSON()),
+			items:         []*indexEntry{newTestIndexEntry("configmaps/cm-1.json", newTestConfigMap().WithLabels(map[string]string{"foo": "bar"}).ToJSON())},
 		},
 		{
 			name:          "items with controller owner are skipped",
 			namespace:     "ns-1",
 			resourcePath:  "configmaps",
 			labelSelector: labels.NewSelector(),
-			fileSystem: newFakeFileSystem().
-				WithFile("configmaps/cm-1.json", newTestConfigMap().WithControllerOwner().ToJSON()).
-				WithFile("configmaps/cm-2.json", newNamedTestConfigMap("cm-2").ToJSON()),
+			items: []*indexEntry{
+				newTestIndexEntry("configmaps/cm-1.json", newTestConfigMap().WithControllerOwner().ToJSON()),
+				newTestIndexEntry("configmaps/cm-2.json", newNamedTestConfigMap("cm-2").ToJSON()),
+			},
 			expectedObjs: toUnstructured(newNamedTestConfigMap("cm-2").WithArkLabel("my-restore").ConfigMap),
 		},
 		{
@@ -433,7 +786,7 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			namespace:     "ns-2",
 			resourcePath:  "configmaps",
 			labelSelector: labels.NewSelector(),
-			fileSystem:    newFakeFileSystem().WithFile("configmaps/cm-1.json", newTestConfigMap().WithNamespace("ns-1").ToJSON()),
+			items:         []*indexEntry{newTestIndexEntry("configmaps/cm-1.json", newTestConfigMap().WithNamespace("ns-1").ToJSON())},
 			expectedObjs:  toUnstructured(newTestConfigMap().WithNamespace("ns-2").WithArkLabel("my-restore").ConfigMap),
 		},
 		{
@@ -441,7 +794,7 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			namespace:     "ns-1",
 			resourcePath:  "configmaps",
 			labelSelector: labels.NewSelector(),
-			fileSystem:    newFakeFileSystem().WithFile("configmaps/cm-1.json", newTestConfigMap().ToJSON()),
+			items:         []*indexEntry{newTestIndexEntry("configmaps/cm-1.json", newTestConfigMap().ToJSON())},
 			restorers:     map[schema.GroupResource]restorers.ResourceRestorer{{Resource: "configmaps"}: newFakeCustomRestorer()},
 			expectedObjs:  toUnstructured(newTestConfigMap().WithLabels(map[string]string{"fake-restorer": "foo"}).WithArkLabel("my-restore").ConfigMap),
 		},
@@ -450,7 +803,7 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			namespace:     "ns-1",
 			resourcePath:  "configmaps",
 			labelSelector: labels.NewSelector(),
-			fileSystem:    newFakeFileSystem().WithFile("configmaps/cm-1.json", newTestConfigMap().ToJSON()),
+			items:         []*indexEntry{newTestIndexEntry("configmaps/cm-1.json", newTestConfigMap().ToJSON())},
 			restorers:     map[schema.GroupResource]restorers.ResourceRestorer{{Resource: "foo-resource"}: newFakeCustomRestorer()},
 			expectedObjs:  toUnstructured(newTestConfigMap().WithArkLabel("my-restore").ConfigMap),
 		},
@@ -460,7 +813,7 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			resourcePath:            "persistentvolumes",
 			labelSelector:           labels.NewSelector(),
 			includeClusterResources: falsePtr,
-			fileSystem:              newFakeFileSystem().WithFile("persistentvolumes/pv-1.json", newTestPV().ToJSON()),
+			items:                   []*indexEntry{newTestIndexEntry("persistentvolumes/pv-1.json", newTestPV().ToJSON())},
 		},
 		{
 			name:                    "namespaced resources are not skipped when IncludeClusterResources=false",
@@ -468,7 +821,7 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			resourcePath:            "configmaps",
 			labelSelector:           labels.NewSelector(),
 			includeClusterResources: falsePtr,
-			fileSystem:              newFakeFileSystem().WithFile("configmaps/cm-1.json", newTestConfigMap().ToJSON()),
+			items:                   []*indexEntry{newTestIndexEntry("configmaps/cm-1.json", newTestConfigMap().ToJSON())},
 			expectedObjs:            toUnstructured(newTestConfigMap().WithArkLabel("my-restore").ConfigMap),
 		},
 		{
@@ -477,7 +830,7 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			resourcePath:            "persistentvolumes",
 			labelSelector:           labels.NewSelector(),
 			includeClusterResources: truePtr,
-			fileSystem:              newFakeFileSystem().WithFile("persistentvolumes/pv-1.json", newTestPV().ToJSON()),
+			items:                   []*indexEntry{newTestIndexEntry("persistentvolumes/pv-1.json", newTestPV().ToJSON())},
 			expectedObjs:            toUnstructured(newTestPV().WithArkLabel("my-restore").PersistentVolume),
 		},
 		{
@@ -486,7 +839,7 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			resourcePath:            "configmaps",
 			labelSelector:           labels.NewSelector(),
 			includeClusterResources: truePtr,
-			fileSystem:              newFakeFileSystem().WithFile("configmaps/cm-1.json", newTestConfigMap().ToJSON()),
+			items:                   []*indexEntry{newTestIndexEntry("configmaps/cm-1.json", newTestConfigMap().ToJSON())},
 			expectedObjs:            toUnstructured(newTestConfigMap().WithArkLabel("my-restore").ConfigMap),
 		},
 		{
@@ -495,7 +848,7 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			resourcePath:            "persistentvolumes",
 			labelSelector:           labels.NewSelector(),
 			includeClusterResources: nil,
-			fileSystem:              newFakeFileSystem().WithFile("persistentvolumes/pv-1.json", newTestPV().ToJSON()),
+			items:                   []*indexEntry{newTestIndexEntry("persistentvolumes/pv-1.json", newTestPV().ToJSON())},
 			expectedObjs:            toUnstructured(newTestPV().WithArkLabel("my-restore").PersistentVolume),
 		},
 		{
@@ -504,9 +857,44 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			resourcePath:            "configmaps",
 			labelSelector:           labels.NewSelector(),
 			includeClusterResources: nil,
-			fileSystem:              newFakeFileSystem().WithFile("configmaps/cm-1.json", newTestConfigMap().ToJSON()),
+			items:                   []*indexEntry{newTestIndexEntry("configmaps/cm-1.json", newTestConfigMap().ToJSON())},
 			expectedObjs:            toUnstructured(newTestConfigMap().WithArkLabel("my-restore").ConfigMap),
 		},
+		{
+			name:          "applicable restore item action is run",
+			namespace:     "ns-1",
+			resourcePath:  "configmaps",
+			labelSelector: labels.NewSelector(),
+			items:         []*indexEntry{newTestIndexEntry("configmaps/cm-1.json", newTestConfigMap().ToJSON())},
+			actions:       []resolvedAction{newFakeResolvedAction(&fakeRestoreItemAction{}, "configmaps")},
+			expectedObjs:  toUnstructured(newTestConfigMap().WithLabels(map[string]string{"fake-action": "foo"}).WithArkLabel("my-restore").ConfigMap),
+		},
+		{
+			name:          "restore item action for different resource is not run",
+			namespace:     "ns-1",
+			resourcePath:  "configmaps",
+			labelSelector: labels.NewSelector(),
+			items:         []*indexEntry{newTestIndexEntry("configmaps/cm-1.json", newTestConfigMap().ToJSON())},
+			actions:       []resolvedAction{newFakeResolvedAction(&fakeRestoreItemAction{}, "secrets")},
+			expectedObjs:  toUnstructured(newTestConfigMap().WithArkLabel("my-restore").ConfigMap),
+		},
+		{
+			name:          "restore item action error prevents item from being restored",
+			namespace:     "ns-1",
+			resourcePath:  "configmaps",
+			labelSelector: labels.NewSelector(),
+			items: []*indexEntry{
+				newTestIndexEntry("configmaps/cm-1.json", newTestConfigMap().ToJSON()),
+				newTestIndexEntry("configmaps/cm-2.json", newNamedTestConfigMap("cm-2").ToJSON()),
+			},
+			actions: []resolvedAction{newFakeResolvedAction(&fakeRestoreItemAction{errorFor: "cm-1"}, "configmaps")},
+			expectedErrors: api.RestoreResult{
+				Namespaces: map[string][]string{
+					"ns-1": {"error executing restore item actions for configmaps/cm-1.json: fake error"},
+				},
+			},
+			expectedObjs: toUnstructured(newNamedTestConfigMap("cm-2").WithLabels(map[string]string{"fake-action": "foo"}).WithArkLabel("my-restore").ConfigMap),
+		},
 	}
 
 	for _, test := range tests {
@@ -529,7 +917,8 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			ctx := &context{
 				dynamicFactory: dynamicFactory,
 				restorers:      test.restorers,
-				fileSystem:     test.fileSystem,
+				actions:        test.actions,
+				index:          newBackupIndex(defaultMemoryLimit),
 				selector:       test.labelSelector,
 				restore: &api.Restore{
 					ObjectMeta: metav1.ObjectMeta{
@@ -544,7 +933,7 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 				logger: log,
 			}
 
-			warnings, errors := ctx.restoreResource(test.resourcePath, test.namespace, test.resourcePath)
+			warnings, errors := ctx.restoreResource(test.resourcePath, test.namespace, test.items)
 
 			assert.Empty(t, warnings.Ark)
 			assert.Empty(t, warnings.Cluster)
@@ -740,66 +1129,36 @@ func (cm *testConfigMap) ToJSON() []byte {
 	return bytes
 }
 
-type fakeFileSystem struct {
-	fs afero.Fs
-
-	readDirCalls []string
+type testBackupIndex struct {
+	*backupIndex
 }
 
-func newFakeFileSystem() *fakeFileSystem {
-	return &fakeFileSystem{
-		fs: afero.NewMemMapFs(),
+func newTestBackupIndex() *testBackupIndex {
+	return &testBackupIndex{
+		backupIndex: newBackupIndex(defaultMemoryLimit),
 	}
 }
 
-func (fs *fakeFileSystem) WithFile(path string, data []byte) *fakeFileSystem {
-	file, _ := fs.fs.Create(path)
-	file.Write(data)
-	file.Close()
-
-	return fs
-}
-
-func (fs *fakeFileSystem) WithDirectory(path string) *fakeFileSystem {
-	fs.fs.MkdirAll(path, 0755)
-	return fs
+func (idx *testBackupIndex) WithItem(path string, data []byte) *testBackupIndex {
+	idx.add(path, bytes.NewReader(data))
+	return idx
 }
 
-func (fs *fakeFileSystem) WithDirectories(path ...string) *fakeFileSystem {
-	for _, dir := range path {
-		fs = fs.WithDirectory(dir)
+// WithItems indexes a minimal object named after the file at each path.
+func (idx *testBackupIndex) WithItems(paths ...string) *testBackupIndex {
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		idx = idx.WithItem(path, []byte(fmt.Sprintf(`{"apiVersion":"v1","kind":"Item","metadata":{"name":%q}}`, name)))
 	}
 
-	return fs
-}
-
-func (fs *fakeFileSystem) TempDir(dir, prefix string) (string, error) {
-	return afero.TempDir(fs.fs, dir, prefix)
-}
-
-func (fs *fakeFileSystem) MkdirAll(path string, perm os.FileMode) error {
-	return fs.fs.MkdirAll(path, perm)
-}
-
-func (fs *fakeFileSystem) Create(name string) (io.WriteCloser, error) {
-	return fs.fs.Create(name)
-}
-
-func (fs *fakeFileSystem) RemoveAll(path string) error {
-	return fs.fs.RemoveAll(path)
-}
-
-func (fs *fakeFileSystem) ReadDir(dirname string) ([]os.FileInfo, error) {
-	fs.readDirCalls = append(fs.readDirCalls, dirname)
-	return afero.ReadDir(fs.fs, dirname)
-}
-
-func (fs *fakeFileSystem) ReadFile(filename string) ([]byte, error) {
-	return afero.ReadFile(fs.fs, filename)
+	return idx
 }
 
-func (fs *fakeFileSystem) DirExists(path string) (bool, error) {
-	return afero.DirExists(fs.fs, path)
+func newTestIndexEntry(path string, data []byte) *indexEntry {
+	return &indexEntry{
+		path: path,
+		data: data,
+	}
 }
 
 type fakeCustomRestorer struct {
@@ -828,6 +1187,42 @@ func (r *fakeCustomRestorer) Prepare(obj runtime.Unstructured, restore *api.Rest
 	return r.ResourceRestorer.Prepare(obj, restore, backup)
 }
 
+type fakeRestoreItemAction struct {
+	errorFor string
+}
+
+func (a *fakeRestoreItemAction) AppliesTo() (ResourceSelector, error) {
+	return ResourceSelector{}, nil
+}
+
+func (a *fakeRestoreItemAction) Execute(obj runtime.Unstructured, restore *api.Restore) (runtime.Unstructured, error, error) {
+	metadata, err := collections.GetMap(obj.UnstructuredContent(), "metadata")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if metadata["name"] == a.errorFor {
+		return nil, nil, errors.New("fake error")
+	}
+
+	if _, found := metadata["labels"]; !found {
+		metadata["labels"] = make(map[string]interface{})
+	}
+
+	metadata["labels"].(map[string]interface{})["fake-action"] = "foo"
+
+	return obj, nil, nil
+}
+
+func newFakeResolvedAction(action ItemAction, resources ...string) resolvedAction {
+	return resolvedAction{
+		ItemAction:                action,
+		resourceIncludesExcludes:  collections.NewIncludesExcludes().Includes(resources...),
+		namespaceIncludesExcludes: collections.NewIncludesExcludes(),
+		selector:                  labels.Everything(),
+	}
+}
+
 type fakeNamespaceClient struct {
 	corev1.NamespaceInterface
 }
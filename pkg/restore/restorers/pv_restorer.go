@@ -24,7 +24,6 @@ import (
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/util/collections"
-	kubeutil "github.com/heptio/ark/pkg/util/kube"
 )
 
 type persistentVolumeRestorer struct {
@@ -61,19 +60,31 @@ func (sr *persistentVolumeRestorer) Prepare(obj runtime.Unstructured, restore *a
 		return nil, nil, err
 	}
 
+	// without a snapshot service, there's no block store to tell which volume types are
+	// supported, so PVs that were snapshotted can't be restored from their snapshots
+	if sr.snapshotService == nil {
+		if backup.Status.VolumeBackups[pvName] == nil {
+			return obj, nil, nil
+		}
+
+		// when RestorePVs = yes, it's an error if we don't have a snapshot service
+		if restore.Spec.RestorePVs != nil && *restore.Spec.RestorePVs {
+			return nil, nil, errors.New("PV restorer is not configured for PV snapshot restores")
+		}
+
+		return obj, errors.New("unable to restore PV snapshots: Ark server is not configured with a PersistentVolumeProvider"), nil
+	}
+
 	// if it's an unsupported volume type for snapshot restores, we're done
-	if sourceType, _ := kubeutil.GetPVSource(spec); sourceType == "" {
+	if volumeID, err := sr.snapshotService.GetVolumeID(obj); err != nil {
+		return nil, nil, err
+	} else if volumeID == "" {
 		return obj, nil, nil
 	}
 
 	restoreFromSnapshot := false
 
 	if restore.Spec.RestorePVs != nil && *restore.Spec.RestorePVs {
-		// when RestorePVs = yes, it's an error if we don't have a snapshot service
-		if sr.snapshotService == nil {
-			return nil, nil, errors.New("PV restorer is not configured for PV snapshot restores")
-		}
-
 		// if there are no snapshots in the backup, return without error
 		if backup.Status.VolumeBackups == nil {
 			return obj, nil, nil
@@ -87,7 +98,7 @@ func (sr *persistentVolumeRestorer) Prepare(obj runtime.Unstructured, restore *a
 
 		restoreFromSnapshot = true
 	}
-	if restore.Spec.RestorePVs == nil && sr.snapshotService != nil {
+	if restore.Spec.RestorePVs == nil {
 		// when RestorePVs = Auto, don't error if the backup doesn't have snapshots
 		if backup.Status.VolumeBackups == nil || backup.Status.VolumeBackups[pvName] == nil {
 			return obj, nil, nil
@@ -96,7 +107,8 @@ func (sr *persistentVolumeRestorer) Prepare(obj runtime.Unstructured, restore *a
 		restoreFromSnapshot = true
 	}
 
-	if restoreFromSnapshot {
+	// a dry-run restore doesn't create volumes, so the PV keeps its original volume ID
+	if restoreFromSnapshot && !restore.Spec.DryRun {
 		backupInfo := backup.Status.VolumeBackups[pvName]
 
 		volumeID, err := sr.snapshotService.CreateVolumeFromSnapshot(backupInfo.SnapshotID, backupInfo.Type, backupInfo.AvailabilityZone, backupInfo.Iops)
@@ -104,18 +116,12 @@ func (sr *persistentVolumeRestorer) Prepare(obj runtime.Unstructured, restore *a
 			return nil, nil, err
 		}
 
-		if err := kubeutil.SetVolumeID(spec, volumeID); err != nil {
+		if obj, err = sr.snapshotService.SetVolumeID(obj, volumeID); err != nil {
 			return nil, nil, err
 		}
 	}
 
-	var warning error
-
-	if sr.snapshotService == nil && len(backup.Status.VolumeBackups) > 0 {
-		warning = errors.New("unable to restore PV snapshots: Ark server is not configured with a PersistentVolumeProvider")
-	}
-
-	return obj, warning, nil
+	return obj, nil, nil
 }
 
 func (sr *persistentVolumeRestorer) Wait() bool {
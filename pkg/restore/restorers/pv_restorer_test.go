@@ -33,15 +33,17 @@ func TestPVRestorerPrepare(t *testing.T) {
 	iops := int64(1000)
 
 	tests := []struct {
-		name              string
-		obj               runtime.Unstructured
-		restore           *api.Restore
-		backup            *api.Backup
-		volumeMap         map[api.VolumeBackupInfo]string
-		noSnapshotService bool
-		expectedWarn      bool
-		expectedErr       bool
-		expectedRes       runtime.Unstructured
+		name                string
+		obj                 runtime.Unstructured
+		restore             *api.Restore
+		backup              *api.Backup
+		volumeMap           map[api.VolumeBackupInfo]string
+		volumeID            string
+		noSnapshotService   bool
+		expectedWarn        bool
+		expectedErr         bool
+		expectedRes         runtime.Unstructured
+		expectedVolumeIDSet string
 	}{
 		{
 			name:        "no name should error",
@@ -68,6 +70,7 @@ func TestPVRestorerPrepare(t *testing.T) {
 			obj:         NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", make(map[string]interface{})).Unstructured,
 			restore:     NewDefaultTestRestore().WithRestorePVs(true).Restore,
 			backup:      &api.Backup{Status: api.BackupStatus{}},
+			volumeID:    "volume-0",
 			expectedErr: false,
 			expectedRes: NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", make(map[string]interface{})).Unstructured,
 		},
@@ -76,6 +79,7 @@ func TestPVRestorerPrepare(t *testing.T) {
 			obj:         NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", make(map[string]interface{})).Unstructured,
 			restore:     NewDefaultTestRestore().WithRestorePVs(true).Restore,
 			backup:      &api.Backup{Status: api.BackupStatus{VolumeBackups: map[string]*api.VolumeBackupInfo{"another-pv": {}}}},
+			volumeID:    "volume-0",
 			expectedErr: true,
 		},
 		{
@@ -94,31 +98,24 @@ func TestPVRestorerPrepare(t *testing.T) {
 				Unstructured,
 		},
 		{
-			name:        "when RestorePVs=true, AWS volume ID should be set correctly",
-			obj:         NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", make(map[string]interface{})).Unstructured,
-			restore:     NewDefaultTestRestore().WithRestorePVs(true).Restore,
-			backup:      &api.Backup{Status: api.BackupStatus{VolumeBackups: map[string]*api.VolumeBackupInfo{"pv-1": {SnapshotID: "snap-1"}}}},
-			volumeMap:   map[api.VolumeBackupInfo]string{{SnapshotID: "snap-1"}: "volume-1"},
-			expectedErr: false,
-			expectedRes: NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", map[string]interface{}{"volumeID": "volume-1"}).Unstructured,
+			name:                "when RestorePVs=true, volume ID should be set correctly",
+			obj:                 NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", map[string]interface{}{"volumeID": "volume-0"}).Unstructured,
+			restore:             NewDefaultTestRestore().WithRestorePVs(true).Restore,
+			backup:              &api.Backup{Status: api.BackupStatus{VolumeBackups: map[string]*api.VolumeBackupInfo{"pv-1": {SnapshotID: "snap-1"}}}},
+			volumeMap:           map[api.VolumeBackupInfo]string{{SnapshotID: "snap-1"}: "volume-1"},
+			volumeID:            "volume-0",
+			expectedErr:         false,
+			expectedRes:         NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", map[string]interface{}{"volumeID": "volume-0"}).Unstructured,
+			expectedVolumeIDSet: "volume-1",
 		},
 		{
-			name:        "when RestorePVs=true, GCE pdName should be set correctly",
-			obj:         NewTestUnstructured().WithName("pv-1").WithSpecField("gcePersistentDisk", make(map[string]interface{})).Unstructured,
-			restore:     NewDefaultTestRestore().WithRestorePVs(true).Restore,
+			name:        "when RestorePVs=true, dry-run restores should not restore snapshots",
+			obj:         NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", map[string]interface{}{"volumeID": "volume-0"}).Unstructured,
+			restore:     NewDefaultTestRestore().WithRestorePVs(true).WithDryRun(true).Restore,
 			backup:      &api.Backup{Status: api.BackupStatus{VolumeBackups: map[string]*api.VolumeBackupInfo{"pv-1": {SnapshotID: "snap-1"}}}},
-			volumeMap:   map[api.VolumeBackupInfo]string{{SnapshotID: "snap-1"}: "volume-1"},
+			volumeID:    "volume-0",
 			expectedErr: false,
-			expectedRes: NewTestUnstructured().WithName("pv-1").WithSpecField("gcePersistentDisk", map[string]interface{}{"pdName": "volume-1"}).Unstructured,
-		},
-		{
-			name:        "when RestorePVs=true, Azure pdName should be set correctly",
-			obj:         NewTestUnstructured().WithName("pv-1").WithSpecField("azureDisk", make(map[string]interface{})).Unstructured,
-			restore:     NewDefaultTestRestore().WithRestorePVs(true).Restore,
-			backup:      &api.Backup{Status: api.BackupStatus{VolumeBackups: map[string]*api.VolumeBackupInfo{"pv-1": {SnapshotID: "snap-1"}}}},
-			volumeMap:   map[api.VolumeBackupInfo]string{{SnapshotID: "snap-1"}: "volume-1"},
-			expectedErr: false,
-			expectedRes: NewTestUnstructured().WithName("pv-1").WithSpecField("azureDisk", map[string]interface{}{"diskName": "volume-1"}).Unstructured,
+			expectedRes: NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", map[string]interface{}{"volumeID": "volume-0"}).Unstructured,
 		},
 		{
 			name:        "when RestorePVs=true, unsupported PV source should not get snapshot restored",
@@ -130,13 +127,15 @@ func TestPVRestorerPrepare(t *testing.T) {
 			expectedRes: NewTestUnstructured().WithName("pv-1").WithSpecField("unsupportedPVSource", make(map[string]interface{})).Unstructured,
 		},
 		{
-			name:        "volume type and IOPS are correctly passed to CreateVolume",
-			obj:         NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", make(map[string]interface{})).Unstructured,
-			restore:     NewDefaultTestRestore().WithRestorePVs(true).Restore,
-			backup:      &api.Backup{Status: api.BackupStatus{VolumeBackups: map[string]*api.VolumeBackupInfo{"pv-1": {SnapshotID: "snap-1", Type: "gp", Iops: &iops}}}},
-			volumeMap:   map[api.VolumeBackupInfo]string{{SnapshotID: "snap-1", Type: "gp", Iops: &iops}: "volume-1"},
-			expectedErr: false,
-			expectedRes: NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", map[string]interface{}{"volumeID": "volume-1"}).Unstructured,
+			name:                "volume type and IOPS are correctly passed to CreateVolume",
+			obj:                 NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", map[string]interface{}{"volumeID": "volume-0"}).Unstructured,
+			restore:             NewDefaultTestRestore().WithRestorePVs(true).Restore,
+			backup:              &api.Backup{Status: api.BackupStatus{VolumeBackups: map[string]*api.VolumeBackupInfo{"pv-1": {SnapshotID: "snap-1", Type: "gp", Iops: &iops}}}},
+			volumeMap:           map[api.VolumeBackupInfo]string{{SnapshotID: "snap-1", Type: "gp", Iops: &iops}: "volume-1"},
+			volumeID:            "volume-0",
+			expectedErr:         false,
+			expectedRes:         NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", map[string]interface{}{"volumeID": "volume-0"}).Unstructured,
+			expectedVolumeIDSet: "volume-1",
 		},
 		{
 			name:              "When no SnapshotService, warn if backup has snapshots that will not be restored",
@@ -149,13 +148,26 @@ func TestPVRestorerPrepare(t *testing.T) {
 			expectedWarn:      true,
 			expectedRes:       NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", make(map[string]interface{})).Unstructured,
 		},
+		{
+			name:              "When no SnapshotService, don't warn if the backup has no snapshot of this PV",
+			obj:               NewTestUnstructured().WithName("pv-1").WithSpecField("nfs", make(map[string]interface{})).Unstructured,
+			restore:           NewDefaultTestRestore().Restore,
+			backup:            &api.Backup{Status: api.BackupStatus{VolumeBackups: map[string]*api.VolumeBackupInfo{"another-pv": {SnapshotID: "snap-1"}}}},
+			noSnapshotService: true,
+			expectedErr:       false,
+			expectedWarn:      false,
+			expectedRes:       NewTestUnstructured().WithName("pv-1").WithSpecField("nfs", make(map[string]interface{})).Unstructured,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			var snapshotService cloudprovider.SnapshotService
+			var (
+				snapshotService     cloudprovider.SnapshotService
+				fakeSnapshotService = &FakeSnapshotService{RestorableVolumes: test.volumeMap, VolumeID: test.volumeID}
+			)
 			if !test.noSnapshotService {
-				snapshotService = &FakeSnapshotService{RestorableVolumes: test.volumeMap}
+				snapshotService = fakeSnapshotService
 			}
 			restorer := NewPersistentVolumeRestorer(snapshotService)
 
@@ -166,6 +178,7 @@ func TestPVRestorerPrepare(t *testing.T) {
 			if assert.Equal(t, test.expectedErr, err != nil) {
 				assert.Equal(t, test.expectedRes, res)
 			}
+			assert.Equal(t, test.expectedVolumeIDSet, fakeSnapshotService.VolumeIDSet)
 		})
 	}
 }
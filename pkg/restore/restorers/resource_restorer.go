@@ -17,9 +17,12 @@ limitations under the License.
 package restorers
 
 import (
+	"github.com/sirupsen/logrus"
+
 	"k8s.io/apimachinery/pkg/runtime"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/util/collections"
 )
 
@@ -83,3 +86,17 @@ func (br *basicRestorer) Ready(obj runtime.Unstructured) bool {
 func NewBasicRestorer(saveAnnotations bool) ResourceRestorer {
 	return &basicRestorer{saveAnnotations: saveAnnotations}
 }
+
+// NewDefaultRestorers returns the restorers for the resources that need more than their metadata
+// and status reset to be restored, keyed by resource name. Other resources are restored with a
+// basic restorer. snapshotService may be nil if PV snapshots can't be restored.
+func NewDefaultRestorers(snapshotService cloudprovider.SnapshotService, logger *logrus.Logger) map[string]ResourceRestorer {
+	return map[string]ResourceRestorer{
+		"persistentvolumes":      NewPersistentVolumeRestorer(snapshotService),
+		"persistentvolumeclaims": NewPersistentVolumeClaimRestorer(),
+		"services":               NewServiceRestorer(),
+		"namespaces":             NewNamespaceRestorer(),
+		"pods":                   NewPodRestorer(logger),
+		"jobs":                   NewJobRestorer(logger),
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// NewBackup returns a Backup of schedule's Template, named for schedule and timestamp, and labeled
+// with the schedule's name so it's subject to the schedule's retention policy. It's used both for
+// the schedule's regular runs and for runs triggered on demand.
+func NewBackup(schedule *api.Schedule, timestamp time.Time) *api.Backup {
+	return &api.Backup{
+		Spec: schedule.Spec.Template,
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: schedule.Namespace,
+			Name:      fmt.Sprintf("%s-%s", schedule.Name, timestamp.Format("20060102150405")),
+			Labels: map[string]string{
+				api.ScheduleNameLabel: schedule.Name,
+			},
+		},
+	}
+}
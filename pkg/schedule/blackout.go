@@ -0,0 +1,216 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// maxBlackoutIterations bounds the search for the end of a series of overlapping blackout windows,
+// and for the next run that isn't skipped, so windows that cover every run can't loop forever.
+const maxBlackoutIterations = 1000
+
+// LoadLocation returns the location for a Schedule's TimeZone, or nil if it isn't set, in which
+// case times are evaluated in the location they're given in (the Ark server's local time zone).
+func LoadLocation(timeZone string) (*time.Location, error) {
+	if timeZone == "" {
+		return nil, nil
+	}
+
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return location, nil
+}
+
+// InLocation returns a cron.Schedule that evaluates schedule in location, and returns times in the
+// location they're given in. If location is nil, schedule is returned as-is.
+func InLocation(schedule cron.Schedule, location *time.Location) cron.Schedule {
+	if location == nil {
+		return schedule
+	}
+	return &locationSchedule{schedule: schedule, location: location}
+}
+
+type locationSchedule struct {
+	schedule cron.Schedule
+	location *time.Location
+}
+
+func (s *locationSchedule) Next(t time.Time) time.Time {
+	return s.schedule.Next(t.In(s.location)).In(t.Location())
+}
+
+// BlackoutWindow is a parsed api.ScheduleBlackoutWindow.
+type BlackoutWindow struct {
+	// Name is the window's name, or its start expression if it doesn't have one.
+	Name string
+	// Skip is whether runs that are due during the window are skipped, rather than deferred.
+	Skip bool
+
+	start    cron.Schedule
+	duration time.Duration
+}
+
+// activeAt returns whether t is during one of the window's occurrences, and if so, when that
+// occurrence ends.
+func (w *BlackoutWindow) activeAt(t time.Time) (time.Time, bool) {
+	// the only occurrence that can include t is the first to start after t - duration
+	start := w.start.Next(t.Add(-w.duration))
+	if start.IsZero() || start.After(t) {
+		return time.Time{}, false
+	}
+	return start.Add(w.duration), true
+}
+
+// ParseBlackoutWindows parses a Schedule's blackout windows, evaluating their start expressions in
+// location. It returns validation errors for any windows that are invalid.
+func ParseBlackoutWindows(windows []api.ScheduleBlackoutWindow, location *time.Location) ([]BlackoutWindow, []string) {
+	var (
+		res              []BlackoutWindow
+		validationErrors []string
+	)
+
+	for i, window := range windows {
+		var errs []string
+
+		// cron.ParseStandard panics on an empty string
+		var start cron.Schedule
+		if window.Start == "" {
+			errs = append(errs, fmt.Sprintf("blackoutWindows[%d].start must be a non-empty valid Cron expression", i))
+		} else if s, err := parseStandard(window.Start); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid blackoutWindows[%d].start: %v", i, err))
+		} else if _, ok := s.(cron.ConstantDelaySchedule); ok {
+			// @every schedules are relative to the time they're evaluated from, so they
+			// don't define recurring windows
+			errs = append(errs, fmt.Sprintf("blackoutWindows[%d].start must be a Cron expression, not @every", i))
+		} else {
+			start = InLocation(s, location)
+		}
+
+		if window.Duration.Duration <= 0 {
+			errs = append(errs, fmt.Sprintf("blackoutWindows[%d].duration must be positive", i))
+		}
+
+		switch window.Action {
+		case "", api.ScheduleBlackoutActionDefer, api.ScheduleBlackoutActionSkip:
+		default:
+			errs = append(errs, fmt.Sprintf("invalid blackoutWindows[%d].action %q, must be Defer or Skip", i, window.Action))
+		}
+
+		if len(errs) > 0 {
+			validationErrors = append(validationErrors, errs...)
+			continue
+		}
+
+		name := window.Name
+		if name == "" {
+			name = window.Start
+		}
+
+		res = append(res, BlackoutWindow{
+			Name:     name,
+			Skip:     window.Action == api.ScheduleBlackoutActionSkip,
+			start:    start,
+			duration: window.Duration.Duration,
+		})
+	}
+
+	return res, validationErrors
+}
+
+func parseStandard(spec string) (schedule cron.Schedule, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("%v", r)
+		}
+	}()
+
+	return cron.ParseStandard(spec)
+}
+
+// Blackout describes the blackout windows that a run falls in.
+type Blackout struct {
+	// Window is the name of the window the run falls in.
+	Window string
+	// Skip is whether the run is skipped, rather than deferred.
+	Skip bool
+	// End is when the blackout ends. For deferred runs, it's the end of any windows that
+	// overlap or immediately follow the one the run falls in.
+	End time.Time
+}
+
+// GetBlackout returns the blackout that a run due at t falls in, or nil if it isn't in any of
+// windows. Windows that skip runs take precedence over windows that defer them.
+func GetBlackout(windows []BlackoutWindow, t time.Time) *Blackout {
+	for i := range windows {
+		if end, active := windows[i].activeAt(t); active && windows[i].Skip {
+			return &Blackout{Window: windows[i].Name, Skip: true, End: end}
+		}
+	}
+
+	var blackout *Blackout
+	end := t
+	for i := 0; i < maxBlackoutIterations; i++ {
+		extended := false
+		for j := range windows {
+			if windows[j].Skip {
+				continue
+			}
+			if windowEnd, active := windows[j].activeAt(end); active {
+				if blackout == nil {
+					blackout = &Blackout{Window: windows[j].Name}
+				}
+				end = windowEnd
+				extended = true
+			}
+		}
+		if !extended {
+			break
+		}
+	}
+
+	if blackout != nil {
+		blackout.End = end
+	}
+	return blackout
+}
+
+// NextRunTime returns when the first run of cronSchedule after t starts, taking windows into
+// account: runs that are due during windows that skip them are skipped, and runs that are due
+// during windows that defer them start when the windows end.
+func NextRunTime(cronSchedule cron.Schedule, windows []BlackoutWindow, t time.Time) time.Time {
+	next := cronSchedule.Next(t)
+	for i := 0; i < maxBlackoutIterations; i++ {
+		blackout := GetBlackout(windows, next)
+		if blackout == nil {
+			return next
+		}
+		if !blackout.Skip {
+			return blackout.End
+		}
+		next = cronSchedule.Next(next)
+	}
+	return next
+}
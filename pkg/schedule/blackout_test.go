@@ -0,0 +1,161 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+func parseTime(t *testing.T, s string) time.Time {
+	res, err := time.Parse("2006-01-02 15:04", s)
+	require.NoError(t, err)
+	return res
+}
+
+func window(name, start string, duration time.Duration, action api.ScheduleBlackoutAction) api.ScheduleBlackoutWindow {
+	return api.ScheduleBlackoutWindow{Name: name, Start: start, Duration: metav1.Duration{Duration: duration}, Action: action}
+}
+
+func TestInLocation(t *testing.T) {
+	newYork, err := LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	cronSchedule, err := cron.ParseStandard("0 2 * * *")
+	require.NoError(t, err)
+
+	// 02:00 in New York is 07:00 UTC in winter, and 06:00 UTC in summer
+	assert.Equal(t, parseTime(t, "2017-01-02 07:00"), InLocation(cronSchedule, newYork).Next(parseTime(t, "2017-01-01 12:00")))
+	assert.Equal(t, parseTime(t, "2017-07-02 06:00"), InLocation(cronSchedule, newYork).Next(parseTime(t, "2017-07-01 12:00")))
+
+	// without a location, the schedule's evaluated in the location of the time it's given
+	assert.Equal(t, parseTime(t, "2017-01-02 02:00"), InLocation(cronSchedule, nil).Next(parseTime(t, "2017-01-01 12:00")))
+
+	location, err := LoadLocation("")
+	assert.NoError(t, err)
+	assert.Nil(t, location)
+
+	_, err = LoadLocation("Nowhere/Special")
+	assert.Error(t, err)
+}
+
+func TestParseBlackoutWindows(t *testing.T) {
+	windows, errs := ParseBlackoutWindows([]api.ScheduleBlackoutWindow{
+		window("", "0 9 * * 1-5", 8*time.Hour, ""),
+		window("freeze", "0 0 20 12 *", 14*24*time.Hour, api.ScheduleBlackoutActionSkip),
+	}, nil)
+	assert.Empty(t, errs)
+	require.Len(t, windows, 2)
+	assert.Equal(t, "0 9 * * 1-5", windows[0].Name)
+	assert.False(t, windows[0].Skip)
+	assert.Equal(t, "freeze", windows[1].Name)
+	assert.True(t, windows[1].Skip)
+
+	windows, errs = ParseBlackoutWindows([]api.ScheduleBlackoutWindow{
+		window("", "", time.Hour, ""),
+		window("", "not cron", time.Hour, ""),
+		window("", "@every 1h", time.Hour, ""),
+		window("", "0 9 * * *", 0, "Sometimes"),
+	}, nil)
+	assert.Empty(t, windows)
+	assert.Equal(t, []string{
+		"blackoutWindows[0].start must be a non-empty valid Cron expression",
+		"invalid blackoutWindows[1].start: Expected exactly 5 fields, found 2: not cron",
+		"blackoutWindows[2].start must be a Cron expression, not @every",
+		"blackoutWindows[3].duration must be positive",
+		`invalid blackoutWindows[3].action "Sometimes", must be Defer or Skip`,
+	}, errs)
+}
+
+func TestGetBlackout(t *testing.T) {
+	windows, errs := ParseBlackoutWindows([]api.ScheduleBlackoutWindow{
+		// weekdays from 09:00 to 17:00, followed by maintenance from 17:00 to 18:00
+		window("peak", "0 9 * * 1-5", 8*time.Hour, api.ScheduleBlackoutActionDefer),
+		window("maintenance", "0 17 * * *", time.Hour, api.ScheduleBlackoutActionDefer),
+		window("freeze", "0 0 20 12 *", 14*24*time.Hour, api.ScheduleBlackoutActionSkip),
+	}, nil)
+	require.Empty(t, errs)
+
+	tests := []struct {
+		name     string
+		time     string
+		expected *Blackout
+	}{
+		{
+			name: "before any window",
+			// Monday
+			time: "2017-10-02 08:59",
+		},
+		{
+			name:     "start of a window, extended by a window that follows it",
+			time:     "2017-10-02 09:00",
+			expected: &Blackout{Window: "peak", End: parseTime(t, "2017-10-02 18:00")},
+		},
+		{
+			name:     "in a window that doesn't overlap any others",
+			time:     "2017-10-07 17:30",
+			expected: &Blackout{Window: "maintenance", End: parseTime(t, "2017-10-07 18:00")},
+		},
+		{
+			name: "end of a window",
+			time: "2017-10-02 18:00",
+		},
+		{
+			name: "weekend",
+			time: "2017-10-07 12:00",
+		},
+		{
+			name:     "a window that skips runs takes precedence",
+			time:     "2017-12-27 12:00",
+			expected: &Blackout{Window: "freeze", Skip: true, End: parseTime(t, "2018-01-03 00:00")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, GetBlackout(windows, parseTime(t, test.time)))
+		})
+	}
+}
+
+func TestNextRunTime(t *testing.T) {
+	windows, errs := ParseBlackoutWindows([]api.ScheduleBlackoutWindow{
+		window("peak", "0 9 * * 1-5", 8*time.Hour, api.ScheduleBlackoutActionDefer),
+		window("weekend", "0 0 * * 6", 48*time.Hour, api.ScheduleBlackoutActionSkip),
+	}, nil)
+	require.Empty(t, errs)
+
+	hourly, err := cron.ParseStandard("0 * * * *")
+	require.NoError(t, err)
+
+	// not in a window
+	assert.Equal(t, parseTime(t, "2017-10-02 08:00"), NextRunTime(hourly, windows, parseTime(t, "2017-10-02 07:30")))
+	// deferred to the end of the window
+	assert.Equal(t, parseTime(t, "2017-10-02 17:00"), NextRunTime(hourly, windows, parseTime(t, "2017-10-02 08:30")))
+	// runs on the weekend are skipped
+	assert.Equal(t, parseTime(t, "2017-10-09 00:00"), NextRunTime(hourly, windows, parseTime(t, "2017-10-06 23:30")))
+	// no windows
+	assert.Equal(t, parseTime(t, "2017-10-02 09:00"), NextRunTime(hourly, nil, parseTime(t, "2017-10-02 08:30")))
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schedule implements the policies that apply to a Schedule's Backups.
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// RetentionDecision is whether a Backup is kept or pruned by a Schedule's retention policy, and
+// why.
+type RetentionDecision struct {
+	Backup *api.Backup
+	Keep   bool
+	// Reasons are the rules that keep the Backup, or the reason it's pruned.
+	Reasons []string
+}
+
+// retentionRule keeps the last Backup in each of the last count periods, where periods are
+// identified by the keys returned by period for the index and time of each Backup.
+type retentionRule struct {
+	name   string
+	count  int
+	period func(i int, t time.Time) string
+	seen   map[string]bool
+}
+
+func (r *retentionRule) keeps(i int, t time.Time) bool {
+	if r.count <= 0 {
+		return false
+	}
+
+	period := r.period(i, t)
+	if r.seen[period] || len(r.seen) >= r.count {
+		return false
+	}
+
+	r.seen[period] = true
+	return true
+}
+
+// ApplyRetention returns the decision for each of a Schedule's backups under retention, ordered
+// from newest to oldest. Backups are ordered by the time they were started, and days, weeks
+// (ISO 8601 weeks, starting on Monday) and months are in UTC.
+//
+// Completed and PartiallyFailed backups are kept if any of the retention rules select them, and
+// the newest Completed backup is always kept. Failed backups are always pruned, and backups that
+// haven't finished are always kept.
+func ApplyRetention(retention *api.ScheduleRetention, backups []*api.Backup) []RetentionDecision {
+	sorted := make([]*api.Backup, len(backups))
+	copy(sorted, backups)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return BackupTime(sorted[i]).After(BackupTime(sorted[j]))
+	})
+
+	// each backup is in a period of its own for the last rule
+	rules := []*retentionRule{
+		{name: "last", count: retention.KeepLast, period: func(i int, t time.Time) string { return strconv.Itoa(i) }},
+		{name: "daily", count: retention.KeepDaily, period: func(i int, t time.Time) string { return t.Format("2006-01-02") }},
+		{name: "weekly", count: retention.KeepWeekly, period: func(i int, t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-%d", year, week)
+		}},
+		{name: "monthly", count: retention.KeepMonthly, period: func(i int, t time.Time) string { return t.Format("2006-01") }},
+	}
+	for _, rule := range rules {
+		rule.seen = make(map[string]bool)
+	}
+
+	var (
+		decisions     = make([]RetentionDecision, 0, len(sorted))
+		keptCompleted = false
+	)
+
+	for i, backup := range sorted {
+		decision := RetentionDecision{Backup: backup}
+
+		switch backup.Status.Phase {
+		case api.BackupPhaseCompleted, api.BackupPhasePartiallyFailed:
+			t := BackupTime(backup).UTC()
+			for _, rule := range rules {
+				if rule.keeps(i, t) {
+					decision.Reasons = append(decision.Reasons, rule.name)
+				}
+			}
+
+			if backup.Status.Phase == api.BackupPhaseCompleted && !keptCompleted {
+				keptCompleted = true
+				if len(decision.Reasons) == 0 {
+					decision.Reasons = append(decision.Reasons, "newest completed")
+				}
+			}
+
+			decision.Keep = len(decision.Reasons) > 0
+			if !decision.Keep {
+				decision.Reasons = []string{"not selected by any rule"}
+			}
+		case api.BackupPhaseFailed, api.BackupPhaseFailedValidation:
+			decision.Reasons = []string{"failed"}
+		default:
+			decision.Keep = true
+			decision.Reasons = []string{"not finished"}
+		}
+
+		decisions = append(decisions, decision)
+	}
+
+	return decisions
+}
+
+// ValidateRetention returns a list of the problems with retention, if any. A retention policy
+// without any rules is invalid, since it would prune all but the newest completed backup.
+func ValidateRetention(retention *api.ScheduleRetention) []string {
+	if retention == nil {
+		return nil
+	}
+	if *retention == (api.ScheduleRetention{}) {
+		return []string{"retention must set at least one of keepLast, keepDaily, keepWeekly or keepMonthly"}
+	}
+
+	var errs []string
+	for name, count := range map[string]int{
+		"keepLast":    retention.KeepLast,
+		"keepDaily":   retention.KeepDaily,
+		"keepWeekly":  retention.KeepWeekly,
+		"keepMonthly": retention.KeepMonthly,
+	} {
+		if count < 0 {
+			errs = append(errs, fmt.Sprintf("retention %s must not be negative", name))
+		}
+	}
+	sort.Strings(errs)
+
+	return errs
+}
+
+// BackupTime returns the time backup was started, or the time it was created if it hasn't
+// started. The start time is preserved when backups are synced from object storage, unlike
+// the creation time.
+func BackupTime(backup *api.Backup) time.Time {
+	if !backup.Status.StartTimestamp.IsZero() {
+		return backup.Status.StartTimestamp.Time
+	}
+	return backup.CreationTimestamp.Time
+}
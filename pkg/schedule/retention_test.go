@@ -0,0 +1,167 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func newBackup(name string, phase api.BackupPhase, startTime string) *api.Backup {
+	t, _ := time.Parse("2006-01-02 15:04", startTime)
+	return arktest.NewTestBackup().WithName(name).WithPhase(phase).WithStartTimestamp(t).Backup
+}
+
+func TestApplyRetention(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention api.ScheduleRetention
+		backups   []*api.Backup
+		expected  map[string][]string
+		pruned    []string
+	}{
+		{
+			name:      "keep last",
+			retention: api.ScheduleRetention{KeepLast: 2},
+			backups: []*api.Backup{
+				newBackup("b1", api.BackupPhaseCompleted, "2017-10-01 00:00"),
+				newBackup("b3", api.BackupPhaseCompleted, "2017-10-03 00:00"),
+				newBackup("b2", api.BackupPhaseCompleted, "2017-10-02 00:00"),
+			},
+			expected: map[string][]string{
+				"b3": {"last"},
+				"b2": {"last"},
+				"b1": {"not selected by any rule"},
+			},
+			pruned: []string{"b1"},
+		},
+		{
+			name:      "daily keeps the last backup of each day",
+			retention: api.ScheduleRetention{KeepDaily: 2},
+			backups: []*api.Backup{
+				newBackup("day1-a", api.BackupPhaseCompleted, "2017-10-01 01:00"),
+				newBackup("day1-b", api.BackupPhaseCompleted, "2017-10-01 13:00"),
+				newBackup("day2-a", api.BackupPhaseCompleted, "2017-10-02 01:00"),
+				newBackup("day2-b", api.BackupPhaseCompleted, "2017-10-02 13:00"),
+				newBackup("day3-a", api.BackupPhaseCompleted, "2017-10-03 01:00"),
+			},
+			expected: map[string][]string{
+				"day3-a": {"daily"},
+				"day2-b": {"daily"},
+				"day2-a": {"not selected by any rule"},
+				"day1-b": {"not selected by any rule"},
+				"day1-a": {"not selected by any rule"},
+			},
+			pruned: []string{"day2-a", "day1-b", "day1-a"},
+		},
+		{
+			name:      "grandfather-father-son",
+			retention: api.ScheduleRetention{KeepDaily: 2, KeepWeekly: 2, KeepMonthly: 2},
+			backups: []*api.Backup{
+				// Sunday 2017-10-01 is in ISO week 39, Monday 2017-10-02 in week 40
+				newBackup("sep-15", api.BackupPhaseCompleted, "2017-09-15 00:00"),
+				newBackup("sep-30", api.BackupPhaseCompleted, "2017-09-30 00:00"),
+				newBackup("oct-01", api.BackupPhaseCompleted, "2017-10-01 00:00"),
+				newBackup("oct-02", api.BackupPhaseCompleted, "2017-10-02 00:00"),
+				newBackup("oct-03", api.BackupPhaseCompleted, "2017-10-03 00:00"),
+			},
+			expected: map[string][]string{
+				"oct-03": {"daily", "weekly", "monthly"},
+				"oct-02": {"daily"},
+				"oct-01": {"weekly"},
+				"sep-30": {"monthly"},
+				"sep-15": {"not selected by any rule"},
+			},
+			pruned: []string{"sep-15"},
+		},
+		{
+			name:      "failed and unfinished backups",
+			retention: api.ScheduleRetention{KeepLast: 1},
+			backups: []*api.Backup{
+				newBackup("completed", api.BackupPhaseCompleted, "2017-10-01 00:00"),
+				newBackup("partial", api.BackupPhasePartiallyFailed, "2017-10-02 00:00"),
+				newBackup("failed", api.BackupPhaseFailed, "2017-10-03 00:00"),
+				newBackup("in-progress", api.BackupPhaseInProgress, "2017-10-04 00:00"),
+			},
+			expected: map[string][]string{
+				"in-progress": {"not finished"},
+				"failed":      {"failed"},
+				"partial":     {"last"},
+				"completed":   {"newest completed"},
+			},
+			pruned: []string{"failed"},
+		},
+		{
+			name:      "the newest completed backup is kept even if no rules are set",
+			retention: api.ScheduleRetention{},
+			backups: []*api.Backup{
+				newBackup("b1", api.BackupPhaseCompleted, "2017-10-01 00:00"),
+				newBackup("b2", api.BackupPhaseCompleted, "2017-10-02 00:00"),
+			},
+			expected: map[string][]string{
+				"b2": {"newest completed"},
+				"b1": {"not selected by any rule"},
+			},
+			pruned: []string{"b1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decisions := ApplyRetention(&test.retention, test.backups)
+
+			var (
+				reasons = make(map[string][]string)
+				pruned  []string
+				times   []time.Time
+			)
+			for _, decision := range decisions {
+				reasons[decision.Backup.Name] = decision.Reasons
+				if !decision.Keep {
+					pruned = append(pruned, decision.Backup.Name)
+				}
+				times = append(times, BackupTime(decision.Backup))
+			}
+
+			assert.Equal(t, test.expected, reasons)
+			assert.Equal(t, test.pruned, pruned)
+
+			// decisions are ordered from newest to oldest
+			for i := 1; i < len(times); i++ {
+				assert.False(t, times[i].After(times[i-1]))
+			}
+		})
+	}
+}
+
+func TestValidateRetention(t *testing.T) {
+	assert.Empty(t, ValidateRetention(nil))
+	assert.Empty(t, ValidateRetention(&api.ScheduleRetention{KeepLast: 1}))
+	assert.Equal(t,
+		[]string{"retention must set at least one of keepLast, keepDaily, keepWeekly or keepMonthly"},
+		ValidateRetention(&api.ScheduleRetention{}),
+	)
+	assert.Equal(t,
+		[]string{"retention keepDaily must not be negative", "retention keepLast must not be negative"},
+		ValidateRetention(&api.ScheduleRetention{KeepLast: -1, KeepDaily: -2}),
+	)
+}
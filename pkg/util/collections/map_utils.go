@@ -48,6 +48,12 @@ func GetValue(root map[string]interface{}, path string) (interface{}, error) {
 	return GetValue(subMap, strings.Join(pathParts[1:], "."))
 }
 
+// Exists returns true if root[path] exists, or false otherwise.
+func Exists(root map[string]interface{}, path string) bool {
+	_, err := GetValue(root, path)
+	return err == nil
+}
+
 // GetString returns the string at root[path], where path is a dot separated string.
 func GetString(root map[string]interface{}, path string) (string, error) {
 	obj, err := GetValue(root, path)
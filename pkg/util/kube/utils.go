@@ -17,6 +17,7 @@ limitations under the License.
 package kube
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -26,6 +27,7 @@ import (
 	"k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	"github.com/heptio/ark/pkg/util/collections"
@@ -59,19 +61,39 @@ var supportedVolumeTypes = map[string]string{
 	"awsElasticBlockStore": "volumeID",
 	"gcePersistentDisk":    "pdName",
 	"azureDisk":            "diskName",
+	"hostPath":             "path",
+	"local":                "path",
 }
 
-// GetVolumeID looks for a supported PV source within the provided PV unstructured
-// data. It returns the appropriate volume ID field if found. If the PV source
-// is supported but a volume ID cannot be found, an error is returned; if the PV
-// source is not supported, zero values are returned.
-func GetVolumeID(pv map[string]interface{}) (string, error) {
+// hostVolumeTypes are the supported PV sources whose volumes are directories on a node.
+// Only the filesystem persistent volume provider can snapshot them.
+var hostVolumeTypes = sets.NewString("hostPath", "local")
+
+// filesystemProvider is the name of the persistent volume provider that snapshots
+// hostPath and local PVs.
+const filesystemProvider = "filesystem"
+
+// supportsVolumeType returns true if the named persistent volume provider can snapshot
+// volumes of the given PV source type.
+func supportsVolumeType(provider, volumeType string) bool {
+	return !hostVolumeTypes.Has(volumeType) || provider == filesystemProvider
+}
+
+// GetVolumeID looks for a PV source supported by the named persistent volume provider
+// within the provided PV unstructured data. It returns the appropriate volume ID field
+// if found. If the PV source is supported but a volume ID cannot be found, an error is
+// returned; if the PV source is not supported, zero values are returned.
+func GetVolumeID(pv map[string]interface{}, provider string) (string, error) {
 	spec, err := collections.GetMap(pv, "spec")
 	if err != nil {
 		return "", err
 	}
 
 	for volumeType, volumeIDKey := range supportedVolumeTypes {
+		if !supportsVolumeType(provider, volumeType) {
+			continue
+		}
+
 		if pvSource, err := collections.GetMap(spec, volumeType); err == nil {
 			volumeID, err := collections.GetString(pvSource, volumeIDKey)
 			if err != nil {
@@ -89,11 +111,15 @@ func GetVolumeID(pv map[string]interface{}) (string, error) {
 	return "", nil
 }
 
-// GetPVSource looks for a supported PV source within the provided PV spec data.
-// It returns the name of the PV source type and the unstructured source data if
-// one is found, or zero values otherwise.
-func GetPVSource(spec map[string]interface{}) (string, map[string]interface{}) {
+// GetPVSource looks for a PV source supported by the named persistent volume provider
+// within the provided PV spec data. It returns the name of the PV source type and the
+// unstructured source data if one is found, or zero values otherwise.
+func GetPVSource(spec map[string]interface{}, provider string) (string, map[string]interface{}) {
 	for volumeType := range supportedVolumeTypes {
+		if !supportsVolumeType(provider, volumeType) {
+			continue
+		}
+
 		if pvSource, found := spec[volumeType]; found {
 			return volumeType, pvSource.(map[string]interface{})
 		}
@@ -102,11 +128,11 @@ func GetPVSource(spec map[string]interface{}) (string, map[string]interface{}) {
 	return "", nil
 }
 
-// SetVolumeID looks for a supported PV source within the provided PV spec data.
-// If sets the appropriate ID field(s) within the source if found, and returns an
-// error if a supported PV source is not found.
-func SetVolumeID(spec map[string]interface{}, volumeID string) error {
-	sourceType, source := GetPVSource(spec)
+// SetVolumeID looks for a PV source supported by the named persistent volume provider
+// within the provided PV spec data. If sets the appropriate ID field(s) within the source
+// if found, and returns an error if a supported PV source is not found.
+func SetVolumeID(spec map[string]interface{}, volumeID, provider string) error {
+	sourceType, source := GetPVSource(spec, provider)
 	if sourceType == "" {
 		return errors.New("persistent volume source is not compatible")
 	}
@@ -129,3 +155,73 @@ func SetVolumeID(spec map[string]interface{}, volumeID string) error {
 
 	return nil
 }
+
+// hostnameLabel is the node label that hostPath and local PVs' node affinity selects
+// their node by.
+const hostnameLabel = "kubernetes.io/hostname"
+
+// GetHostVolumeNode returns the name of the node that a hostPath or local PV is on, as
+// selected by its node affinity, and true. The node affinity is read from the PV's
+// spec.nodeAffinity, or from its alpha node affinity annotation. An empty node name is
+// returned if the node affinity doesn't select a single node by name, and false is
+// returned if the PV isn't a hostPath or local PV.
+func GetHostVolumeNode(pv map[string]interface{}) (string, bool, error) {
+	spec, err := collections.GetMap(pv, "spec")
+	if err != nil {
+		return "", false, err
+	}
+
+	isHostVolume := false
+	for volumeType := range hostVolumeTypes {
+		if _, found := spec[volumeType]; found {
+			isHostVolume = true
+			break
+		}
+	}
+	if !isHostVolume {
+		return "", false, nil
+	}
+
+	var selector *v1.NodeSelector
+
+	if required, err := collections.GetMap(spec, "nodeAffinity.required"); err == nil {
+		data, err := json.Marshal(required)
+		if err != nil {
+			return "", true, errors.WithStack(err)
+		}
+		selector = new(v1.NodeSelector)
+		if err := json.Unmarshal(data, selector); err != nil {
+			return "", true, errors.Wrap(err, "error reading PersistentVolume's node affinity")
+		}
+	} else if annotation := getAnnotation(pv, v1.AlphaStorageNodeAffinityAnnotation); annotation != "" {
+		affinity := new(v1.NodeAffinity)
+		if err := json.Unmarshal([]byte(annotation), affinity); err != nil {
+			return "", true, errors.Wrap(err, "error reading PersistentVolume's node affinity annotation")
+		}
+		selector = affinity.RequiredDuringSchedulingIgnoredDuringExecution
+	}
+
+	if selector == nil || len(selector.NodeSelectorTerms) != 1 {
+		return "", true, nil
+	}
+
+	for _, expr := range selector.NodeSelectorTerms[0].MatchExpressions {
+		if expr.Key == hostnameLabel && expr.Operator == v1.NodeSelectorOpIn && len(expr.Values) == 1 {
+			return expr.Values[0], true, nil
+		}
+	}
+
+	return "", true, nil
+}
+
+// getAnnotation returns the value of the named annotation of obj, or an empty string if it
+// isn't set.
+func getAnnotation(obj map[string]interface{}, name string) string {
+	annotations, err := collections.GetMap(obj, "metadata.annotations")
+	if err != nil {
+		return ""
+	}
+
+	value, _ := annotations[name].(string)
+	return value
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package kube
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,6 +31,7 @@ func TestSetVolumeID(t *testing.T) {
 		name                  string
 		spec                  map[string]interface{}
 		volumeID              string
+		provider              string
 		expectedErr           error
 		specFieldExpectations map[string]string
 	}{
@@ -67,6 +69,32 @@ func TestSetVolumeID(t *testing.T) {
 				"azureDisk.diskURI": "some-nonsense/new-disk",
 			},
 		},
+		{
+			name: "hostPath normal case",
+			spec: map[string]interface{}{
+				"hostPath": map[string]interface{}{
+					"path": "/var/lib/volumes/old",
+					"type": "Directory",
+				},
+			},
+			volumeID:    "/var/lib/ark/volumes/vol-new",
+			provider:    "filesystem",
+			expectedErr: nil,
+			specFieldExpectations: map[string]string{
+				"hostPath.type": "Directory",
+			},
+		},
+		{
+			name: "local normal case",
+			spec: map[string]interface{}{
+				"local": map[string]interface{}{
+					"path": "/mnt/disks/old",
+				},
+			},
+			volumeID:    "/var/lib/ark/volumes/vol-new",
+			provider:    "filesystem",
+			expectedErr: nil,
+		},
 		{
 			name: "azureDisk with no diskURI",
 			spec: map[string]interface{}{
@@ -81,7 +109,12 @@ func TestSetVolumeID(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := SetVolumeID(test.spec, test.volumeID)
+			provider := test.provider
+			if provider == "" {
+				provider = "aws"
+			}
+
+			err := SetVolumeID(test.spec, test.volumeID, provider)
 
 			require.Equal(t, test.expectedErr, err)
 
@@ -93,7 +126,7 @@ func TestSetVolumeID(t *testing.T) {
 				"spec": test.spec,
 			}
 
-			volumeID, err := GetVolumeID(pv)
+			volumeID, err := GetVolumeID(pv, provider)
 			require.Nil(t, err)
 
 			assert.Equal(t, test.volumeID, volumeID)
@@ -106,3 +139,78 @@ func TestSetVolumeID(t *testing.T) {
 		})
 	}
 }
+
+func TestHostVolumesOnlySupportedByFilesystemProvider(t *testing.T) {
+	for _, volumeType := range []string{"hostPath", "local"} {
+		t.Run(volumeType, func(t *testing.T) {
+			spec := map[string]interface{}{
+				volumeType: map[string]interface{}{
+					"path": "/mnt/disks/old",
+				},
+			}
+			pv := map[string]interface{}{
+				"spec": spec,
+			}
+
+			for _, provider := range []string{"aws", "gcp", "azure"} {
+				volumeID, err := GetVolumeID(pv, provider)
+				require.NoError(t, err)
+				assert.Equal(t, "", volumeID)
+
+				assert.Error(t, SetVolumeID(spec, "/var/lib/ark/volumes/vol-new", provider))
+			}
+
+			volumeID, err := GetVolumeID(pv, "filesystem")
+			require.NoError(t, err)
+			assert.Equal(t, "/mnt/disks/old", volumeID)
+		})
+	}
+}
+
+func TestGetHostVolumeNode(t *testing.T) {
+	tests := []struct {
+		name                 string
+		pv                   string
+		expectedNode         string
+		expectedIsHostVolume bool
+	}{
+		{
+			name: "not a host volume",
+			pv:   `{"spec": {"awsElasticBlockStore": {"volumeID": "vol-abc123"}, "nodeAffinity": {"required": {"nodeSelectorTerms": [{"matchExpressions": [{"key": "kubernetes.io/hostname", "operator": "In", "values": ["node-1"]}]}]}}}}`,
+		},
+		{
+			name:                 "hostPath without node affinity",
+			pv:                   `{"spec": {"hostPath": {"path": "/data/pv-1"}}}`,
+			expectedIsHostVolume: true,
+		},
+		{
+			name:                 "local with node affinity",
+			pv:                   `{"spec": {"local": {"path": "/mnt/disks/ssd1"}, "nodeAffinity": {"required": {"nodeSelectorTerms": [{"matchExpressions": [{"key": "kubernetes.io/hostname", "operator": "In", "values": ["node-1"]}]}]}}}}`,
+			expectedNode:         "node-1",
+			expectedIsHostVolume: true,
+		},
+		{
+			name:                 "local with node affinity annotation",
+			pv:                   `{"metadata": {"annotations": {"volume.alpha.kubernetes.io/node-affinity": "{\"requiredDuringSchedulingIgnoredDuringExecution\": {\"nodeSelectorTerms\": [{\"matchExpressions\": [{\"key\": \"kubernetes.io/hostname\", \"operator\": \"In\", \"values\": [\"node-1\"]}]}]}}"}}, "spec": {"local": {"path": "/mnt/disks/ssd1"}}}`,
+			expectedNode:         "node-1",
+			expectedIsHostVolume: true,
+		},
+		{
+			name:                 "local with node affinity selecting several nodes",
+			pv:                   `{"spec": {"local": {"path": "/mnt/disks/ssd1"}, "nodeAffinity": {"required": {"nodeSelectorTerms": [{"matchExpressions": [{"key": "kubernetes.io/hostname", "operator": "In", "values": ["node-1", "node-2"]}]}]}}}}`,
+			expectedIsHostVolume: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var pv map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(test.pv), &pv))
+
+			node, isHostVolume, err := GetHostVolumeNode(pv)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedNode, node)
+			assert.Equal(t, test.expectedIsHostVolume, isHostVolume)
+		})
+	}
+}
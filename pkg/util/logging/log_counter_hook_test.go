@@ -0,0 +1,43 @@
+/*
+Copyright 2017 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogCounterHook(t *testing.T) {
+	hook := NewLogCounterHook()
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	logger.Hooks.Add(hook)
+
+	logger.Info("info")
+	logger.Warn("warning 1")
+	logger.WithField("foo", "bar").Warn("warning 2")
+	logger.Error("error")
+
+	assert.Equal(t, 1, hook.GetCount(logrus.InfoLevel))
+	assert.Equal(t, 2, hook.GetCount(logrus.WarnLevel))
+	assert.Equal(t, 1, hook.GetCount(logrus.ErrorLevel))
+	assert.Equal(t, 0, hook.GetCount(logrus.DebugLevel))
+}
@@ -131,13 +131,69 @@ func (_m *BackupService) GetBackup(bucket string, name string) (*v1.Backup, erro
 	return r0, r1
 }
 
-// UploadBackup provides a mock function with given fields: bucket, name, metadata, backup, log
-func (_m *BackupService) UploadBackup(bucket string, name string, metadata io.Reader, backup io.Reader, log io.Reader) error {
-	ret := _m.Called(bucket, name, metadata, backup, log)
+// UploadBackupContents provides a mock function with given fields: bucket, name, contents
+func (_m *BackupService) UploadBackupContents(bucket string, name string, contents io.Reader) error {
+	ret := _m.Called(bucket, name, contents)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, io.Reader, io.Reader, io.Reader) error); ok {
-		r0 = rf(bucket, name, metadata, backup, log)
+	if rf, ok := ret.Get(0).(func(string, string, io.Reader) error); ok {
+		r0 = rf(bucket, name, contents)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UploadBackupLog provides a mock function with given fields: bucket, name, log
+func (_m *BackupService) UploadBackupLog(bucket string, name string, log io.Reader) error {
+	ret := _m.Called(bucket, name, log)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, io.Reader) error); ok {
+		r0 = rf(bucket, name, log)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UploadBackupResults provides a mock function with given fields: bucket, name, results
+func (_m *BackupService) UploadBackupResults(bucket string, name string, results io.Reader) error {
+	ret := _m.Called(bucket, name, results)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, io.Reader) error); ok {
+		r0 = rf(bucket, name, results)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UploadBackupContentsIndex provides a mock function with given fields: bucket, name, index
+func (_m *BackupService) UploadBackupContentsIndex(bucket string, name string, index io.Reader) error {
+	ret := _m.Called(bucket, name, index)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, io.Reader) error); ok {
+		r0 = rf(bucket, name, index)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UploadBackupMetadata provides a mock function with given fields: bucket, name, metadata
+func (_m *BackupService) UploadBackupMetadata(bucket string, name string, metadata io.Reader) error {
+	ret := _m.Called(bucket, name, metadata)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, io.Reader) error); ok {
+		r0 = rf(bucket, name, metadata)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -172,3 +228,202 @@ func (_m *BackupService) UploadRestoreResults(bucket string, backup string, rest
 
 	return r0
 }
+
+// UploadRestoreDryRunReport provides a mock function with given fields: bucket, backup, restore, report
+func (_m *BackupService) UploadRestoreDryRunReport(bucket string, backup string, restore string, report io.Reader) error {
+	ret := _m.Called(bucket, backup, restore, report)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string, io.Reader) error); ok {
+		r0 = rf(bucket, backup, restore, report)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UploadPodVolumeChunk provides a mock function with given fields: bucket, id, data
+func (_m *BackupService) UploadPodVolumeChunk(bucket string, id string, data io.Reader) error {
+	ret := _m.Called(bucket, id, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, io.Reader) error); ok {
+		r0 = rf(bucket, id, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DownloadPodVolumeChunk provides a mock function with given fields: bucket, id
+func (_m *BackupService) DownloadPodVolumeChunk(bucket string, id string) (io.ReadCloser, error) {
+	ret := _m.Called(bucket, id)
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(string, string) io.ReadCloser); ok {
+		r0 = rf(bucket, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(bucket, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPodVolumeChunks provides a mock function with given fields: bucket
+func (_m *BackupService) ListPodVolumeChunks(bucket string) ([]string, error) {
+	ret := _m.Called(bucket)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(bucket)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(bucket)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePodVolumeChunk provides a mock function with given fields: bucket, id
+func (_m *BackupService) DeletePodVolumeChunk(bucket string, id string) error {
+	ret := _m.Called(bucket, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(bucket, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UploadPodVolumeLock provides a mock function with given fields: bucket, name
+func (_m *BackupService) UploadPodVolumeLock(bucket string, name string) error {
+	ret := _m.Called(bucket, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(bucket, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListPodVolumeLocks provides a mock function with given fields: bucket
+func (_m *BackupService) ListPodVolumeLocks(bucket string) ([]string, error) {
+	ret := _m.Called(bucket)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(bucket)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(bucket)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePodVolumeLock provides a mock function with given fields: bucket, name
+func (_m *BackupService) DeletePodVolumeLock(bucket string, name string) error {
+	ret := _m.Called(bucket, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(bucket, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UploadPodVolumeManifest provides a mock function with given fields: bucket, backup, snapshotID, manifest
+func (_m *BackupService) UploadPodVolumeManifest(bucket string, backup string, snapshotID string, manifest io.Reader) error {
+	ret := _m.Called(bucket, backup, snapshotID, manifest)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string, io.Reader) error); ok {
+		r0 = rf(bucket, backup, snapshotID, manifest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DownloadPodVolumeManifest provides a mock function with given fields: bucket, backup, snapshotID
+func (_m *BackupService) DownloadPodVolumeManifest(bucket string, backup string, snapshotID string) (io.ReadCloser, error) {
+	ret := _m.Called(bucket, backup, snapshotID)
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(string, string, string) io.ReadCloser); ok {
+		r0 = rf(bucket, backup, snapshotID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(bucket, backup, snapshotID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPodVolumeManifests provides a mock function with given fields: bucket
+func (_m *BackupService) ListPodVolumeManifests(bucket string) (map[string][]string, error) {
+	ret := _m.Called(bucket)
+
+	var r0 map[string][]string
+	if rf, ok := ret.Get(0).(func(string) map[string][]string); ok {
+		r0 = rf(bucket)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string][]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(bucket)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 
 	"github.com/heptio/ark/pkg/client"
@@ -64,3 +65,13 @@ func (c *FakeDynamicClient) Get(name string, opts metav1.GetOptions) (*unstructu
 	args := c.Called(name, opts)
 	return args.Get(0).(*unstructured.Unstructured), args.Error(1)
 }
+
+func (c *FakeDynamicClient) Patch(name string, pt types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	args := c.Called(name, pt, data)
+	return args.Get(0).(*unstructured.Unstructured), args.Error(1)
+}
+
+func (c *FakeDynamicClient) Delete(name string, opts *metav1.DeleteOptions) error {
+	args := c.Called(name, opts)
+	return args.Error(0)
+}
@@ -19,6 +19,7 @@ package test
 import (
 	"errors"
 
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
@@ -33,6 +34,13 @@ type FakeSnapshotService struct {
 
 	// VolumeBackupInfo -> VolumeID
 	RestorableVolumes map[api.VolumeBackupInfo]string
+
+	// VolumeID and VolumeIDErr are returned by GetVolumeID for every PV
+	VolumeID    string
+	VolumeIDErr error
+
+	// VolumeIDSet is the volume ID most recently passed to SetVolumeID
+	VolumeIDSet string
 }
 
 func (s *FakeSnapshotService) GetAllSnapshots() ([]string, error) {
@@ -80,3 +88,12 @@ func (s *FakeSnapshotService) GetVolumeInfo(volumeID, volumeAZ string) (string,
 		return volumeInfo.Type, volumeInfo.Iops, nil
 	}
 }
+
+func (s *FakeSnapshotService) GetVolumeID(pv runtime.Unstructured) (string, error) {
+	return s.VolumeID, s.VolumeIDErr
+}
+
+func (s *FakeSnapshotService) SetVolumeID(pv runtime.Unstructured, volumeID string) (runtime.Unstructured, error) {
+	s.VolumeIDSet = volumeID
+	return pv, nil
+}
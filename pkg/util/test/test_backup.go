@@ -92,6 +92,16 @@ func (b *TestBackup) WithExpiration(expiration time.Time) *TestBackup {
 	return b
 }
 
+func (b *TestBackup) WithStartTimestamp(startTime time.Time) *TestBackup {
+	b.Status.StartTimestamp = metav1.Time{Time: startTime}
+	return b
+}
+
+func (b *TestBackup) WithCompletionTimestamp(completionTime time.Time) *TestBackup {
+	b.Status.CompletionTimestamp = metav1.Time{Time: completionTime}
+	return b
+}
+
 func (b *TestBackup) WithVersion(version int) *TestBackup {
 	b.Status.Version = version
 	return b
@@ -114,3 +124,8 @@ func (b *TestBackup) WithSnapshotVolumesPointer(value *bool) *TestBackup {
 	b.Spec.SnapshotVolumes = value
 	return b
 }
+
+func (b *TestBackup) WithStorageLocation(name string) *TestBackup {
+	b.Spec.StorageLocation = name
+	return b
+}
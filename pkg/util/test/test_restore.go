@@ -92,3 +92,13 @@ func (r *TestRestore) WithExcludedResource(resource string) *TestRestore {
 	r.Spec.ExcludedResources = append(r.Spec.ExcludedResources, resource)
 	return r
 }
+
+func (r *TestRestore) WithExistingResourcePolicy(policy api.ExistingResourcePolicy) *TestRestore {
+	r.Spec.ExistingResourcePolicy = policy
+	return r
+}
+
+func (r *TestRestore) WithDryRun(value bool) *TestRestore {
+	r.Spec.DryRun = value
+	return r
+}
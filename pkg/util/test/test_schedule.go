@@ -59,3 +59,88 @@ func (s *TestSchedule) WithLastBackupTime(timeString string) *TestSchedule {
 	s.Status.LastBackup = metav1.Time{Time: t}
 	return s
 }
+
+func (s *TestSchedule) WithNextBackupTime(timeString string) *TestSchedule {
+	t, _ := time.Parse("2006-01-02 15:04:05", timeString)
+	s.Status.NextBackup = metav1.Time{Time: t}
+	return s
+}
+
+func (s *TestSchedule) WithRun(scheduledTime string, result api.ScheduleRunResult, backup string, phase api.BackupPhase, message string) *TestSchedule {
+	t, _ := time.Parse("2006-01-02 15:04:05", scheduledTime)
+	s.Status.History = append(s.Status.History, api.ScheduleRun{
+		ScheduledTime: metav1.Time{Time: t},
+		Result:        result,
+		Backup:        backup,
+		BackupPhase:   phase,
+		Message:       message,
+	})
+	return s
+}
+
+func (s *TestSchedule) WithTriggeredRun(scheduledTime string, result api.ScheduleRunResult, backup string, phase api.BackupPhase, message string) *TestSchedule {
+	s.WithRun(scheduledTime, result, backup, phase, message)
+	s.Status.History[len(s.Status.History)-1].Triggered = true
+	return s
+}
+
+func (s *TestSchedule) WithTrigger() *TestSchedule {
+	if s.Annotations == nil {
+		s.Annotations = make(map[string]string)
+	}
+	s.Annotations[api.ScheduleTriggerAnnotation] = "true"
+	return s
+}
+
+func (s *TestSchedule) WithTriggerRemoved() *TestSchedule {
+	s.WithTrigger()
+	delete(s.Annotations, api.ScheduleTriggerAnnotation)
+	return s
+}
+
+func (s *TestSchedule) WithLastSuccessfulBackup(name, timeString string) *TestSchedule {
+	t, _ := time.Parse("2006-01-02 15:04:05", timeString)
+	s.Status.LastSuccessfulBackup = &api.ScheduleBackupReference{Name: name, Timestamp: metav1.Time{Time: t}}
+	return s
+}
+
+func (s *TestSchedule) WithLastFailedBackup(name, timeString string) *TestSchedule {
+	t, _ := time.Parse("2006-01-02 15:04:05", timeString)
+	s.Status.LastFailedBackup = &api.ScheduleBackupReference{Name: name, Timestamp: metav1.Time{Time: t}}
+	return s
+}
+
+func (s *TestSchedule) WithConcurrencyPolicy(policy api.ScheduleConcurrencyPolicy) *TestSchedule {
+	s.Spec.ConcurrencyPolicy = policy
+	return s
+}
+
+func (s *TestSchedule) WithStartingDeadline(deadline time.Duration) *TestSchedule {
+	s.Spec.StartingDeadline = &metav1.Duration{Duration: deadline}
+	return s
+}
+
+func (s *TestSchedule) WithTimeZone(timeZone string) *TestSchedule {
+	s.Spec.TimeZone = timeZone
+	return s
+}
+
+func (s *TestSchedule) WithBlackoutWindow(name, start string, duration time.Duration, action api.ScheduleBlackoutAction) *TestSchedule {
+	s.Spec.BlackoutWindows = append(s.Spec.BlackoutWindows, api.ScheduleBlackoutWindow{
+		Name:     name,
+		Start:    start,
+		Duration: metav1.Duration{Duration: duration},
+		Action:   action,
+	})
+	return s
+}
+
+func (s *TestSchedule) WithPaused(paused bool) *TestSchedule {
+	s.Spec.Paused = paused
+	return s
+}
+
+func (s *TestSchedule) WithRetention(retention api.ScheduleRetention) *TestSchedule {
+	s.Spec.Retention = &retention
+	return s
+}